@@ -16,5 +16,6 @@ echo "Git commit: ${GIT_COMMIT}${GIT_DIRTY}"
 echo "Build time: ${BUILD_TIME}"
 
 go build -ldflags "$LDFLAGS" -o katago-mcp ./cmd/katago-mcp
+go build -o katago-mock ./cmd/katago-mock
 
-echo "Build complete: ./katago-mcp"
\ No newline at end of file
+echo "Build complete: ./katago-mcp ./katago-mock"
\ No newline at end of file
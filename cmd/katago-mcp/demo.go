@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+// mockBinaryName is the stand-in engine built from cmd/katago-mock.
+const mockBinaryName = "katago-mock"
+
+// demoSetup points every engine at katago-mock instead of a real KataGo
+// install, so the server runs without a model or GPU. Analysis results are
+// canned and only useful for trying out clients and tools.
+func demoSetup(cfg *config.Config) (*katago.DetectedSetup, error) {
+	binary, err := findMockBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.KataGo.BinaryPath = binary
+	cfg.KataGo.ModelPath = ""
+	cfg.KataGo.ConfigPath = ""
+	cfg.KataGo.HumanModelPath = ""
+	for i := range cfg.Engines {
+		cfg.Engines[i].BinaryPath = binary
+		cfg.Engines[i].ModelPath = ""
+		cfg.Engines[i].ConfigPath = ""
+		cfg.Engines[i].HumanModelPath = ""
+	}
+	return &katago.DetectedSetup{BinaryPath: binary, Backend: "mock"}, nil
+}
+
+// findMockBinary looks for katago-mock next to the server binary, then in
+// PATH.
+func findMockBinary() (string, error) {
+	name := mockBinaryName
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if exe, err := os.Executable(); err == nil {
+		candidate := filepath.Join(filepath.Dir(exe), name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+	return "", fmt.Errorf("%s not found next to the server or in PATH; build it with: go build -o %s ./cmd/katago-mock", name, name)
+}
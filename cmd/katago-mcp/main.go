@@ -10,16 +10,28 @@ import (
 
 	"github.com/dmmcquay/katago-mcp/internal/cache"
 	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/gtp"
 	"github.com/dmmcquay/katago-mcp/internal/health"
 	"github.com/dmmcquay/katago-mcp/internal/katago"
 	"github.com/dmmcquay/katago-mcp/internal/logging"
 	mcptools "github.com/dmmcquay/katago-mcp/internal/mcp"
 	"github.com/dmmcquay/katago-mcp/internal/metrics"
+	"github.com/dmmcquay/katago-mcp/internal/monitor"
+	"github.com/dmmcquay/katago-mcp/internal/prewarm"
+	"github.com/dmmcquay/katago-mcp/internal/provenance"
+	"github.com/dmmcquay/katago-mcp/internal/quota"
 	"github.com/dmmcquay/katago-mcp/internal/ratelimit"
 	httpserver "github.com/dmmcquay/katago-mcp/internal/server"
+	"github.com/dmmcquay/katago-mcp/internal/session"
 	"github.com/dmmcquay/katago-mcp/internal/shutdown"
+	"github.com/dmmcquay/katago-mcp/internal/slack"
+	"github.com/dmmcquay/katago-mcp/internal/tracing"
+	"github.com/dmmcquay/katago-mcp/internal/watch"
+	"github.com/dmmcquay/katago-mcp/internal/watchdog"
+	"github.com/dmmcquay/katago-mcp/internal/webhook"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -29,9 +41,18 @@ var (
 )
 
 func main() {
+	// Subcommands run instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "review" {
+		os.Exit(runReview(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		os.Exit(runVerify(os.Args[2:]))
+	}
+
 	// Parse command line flags
-	var showVersion bool
+	var showVersion, demo bool
 	flag.BoolVar(&showVersion, "version", false, "Show version information")
+	flag.BoolVar(&demo, "demo", false, "Run against the katago-mock engine instead of KataGo")
 	flag.Parse()
 
 	// Handle version flag
@@ -74,14 +95,35 @@ func main() {
 		})
 	}
 
-	// Detect KataGo installation
-	logger.Info("Detecting KataGo installation...")
-	detection, err := katago.DetectKataGo()
+	// Set up tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.Tracing, cfg.Server.Name, cfg.Server.Version)
 	if err != nil {
-		logger.Error("KataGo detection failed: %v", err)
-		logger.Info("\n%s", katago.GetInstallationInstructions())
+		logger.Error("Failed to set up tracing", "error", err)
 		os.Exit(1)
 	}
+	shutdownManager.Register("tracing", shutdownTracing)
+	if cfg.Tracing.Enabled {
+		logger.Info("Tracing enabled", "endpoint", cfg.Tracing.Endpoint, "sampleRatio", cfg.Tracing.SampleRatio)
+	}
+
+	// Detect KataGo installation, or use the mock engine in demo mode
+	var detection *katago.DetectedSetup
+	if demo {
+		detection, err = demoSetup(cfg)
+		if err != nil {
+			logger.Error("Demo mode setup failed: %v", err)
+			os.Exit(1)
+		}
+		logger.Warn("Demo mode: analysis comes from katago-mock and is not real KataGo output")
+	} else {
+		logger.Info("Detecting KataGo installation...")
+		detection, err = katago.DetectKataGo()
+		if err != nil {
+			logger.Error("KataGo detection failed: %v", err)
+			logger.Info("\n%s", katago.GetInstallationInstructions())
+			os.Exit(1)
+		}
+	}
 
 	// Log detection results
 	if detection.BinaryPath != "" {
@@ -89,6 +131,15 @@ func main() {
 		if detection.Version != "" {
 			logger.Info("KataGo version: %s", detection.Version)
 		}
+		if detection.Backend != "" {
+			logger.Info("KataGo backend: %s", detection.Backend)
+		}
+	}
+	if detection.InContainer {
+		logger.Info("Running in a container")
+	}
+	for _, warning := range detection.Warnings {
+		logger.Warn(warning)
 	}
 	if detection.ModelPath != "" {
 		logger.Info("Found model: %s", detection.ModelPath)
@@ -123,7 +174,11 @@ func main() {
 		}
 	}
 
-	// Override with config values if specified
+	// Override with config values if specified, unless detection replaced
+	// the configured binary with one whose backend suits this host
+	if detection.ReplacedBinaryPath != "" && cfg.KataGo.BinaryPath == detection.ReplacedBinaryPath {
+		cfg.KataGo.BinaryPath = detection.BinaryPath
+	}
 	if cfg.KataGo.BinaryPath != "" && cfg.KataGo.BinaryPath != "katago" {
 		detection.BinaryPath = cfg.KataGo.BinaryPath
 	}
@@ -167,10 +222,13 @@ func main() {
 			"filePath":    cfg.Logging.File.Path,
 		},
 		"cache", map[string]interface{}{
-			"enabled":      cfg.Cache.Enabled,
-			"maxItems":     cfg.Cache.MaxItems,
-			"maxSizeBytes": cfg.Cache.MaxSizeBytes,
-			"ttlSeconds":   cfg.Cache.TTLSeconds,
+			"enabled":            cfg.Cache.Enabled,
+			"maxItems":           cfg.Cache.MaxItems,
+			"maxSizeBytes":       cfg.Cache.MaxSizeBytes,
+			"ttlSeconds":         cfg.Cache.TTLSeconds,
+			"maxEntryBytes":      cfg.Cache.MaxEntryBytes,
+			"compressAboveBytes": cfg.Cache.CompressAboveBytes,
+			"redisAddr":          cfg.Cache.Redis.Addr,
 		},
 		"rateLimit", map[string]interface{}{
 			"enabled":        cfg.RateLimit.Enabled,
@@ -180,24 +238,51 @@ func main() {
 		},
 	)
 
-	// Create cache manager
+	// Create cache manager, sharing results through Redis when configured
 	cacheManager := cache.NewManager(&cfg.Cache, logger)
+	cacheManager.SetCodec(katago.ResponseCodec)
+	if cfg.Cache.Enabled && cfg.Cache.Redis.Addr != "" {
+		cacheManager.SetBackend(cache.NewRedisBackend(&cfg.Cache.Redis), katago.ResponseCodec)
+		shutdownManager.Register("cache-backend", func(ctx context.Context) error {
+			return cacheManager.Close()
+		})
+		logger.Info("Shared cache enabled", "backend", "redis", "addr", cfg.Cache.Redis.Addr,
+			"compression", cfg.Cache.Redis.Compression)
+	}
 
-	// Create KataGo supervisor with auto-restart
-	supervisor := katago.NewSupervisor(&cfg.KataGo, logger, cacheManager)
+	// Record Prometheus metrics in the default registry, alongside the Go
+	// runtime's
+	promCollector := metrics.NewPrometheusCollector(prometheus.DefaultRegisterer)
+	promCollector.SetClients(cfg.MetricClients())
+
+	// Create a supervisor with auto-restart for each configured engine
+	enginePool := katago.NewPool(cfg.EngineConfigs(), logger, cacheManager)
+	enginePool.SetPrometheus(promCollector)
+
+	// Run the configured analyzer plugins in every engine's game reviews
+	analyzers := make([]katago.Analyzer, 0, len(cfg.Review.Analyzers))
+	for _, analyzerCfg := range cfg.Review.Analyzers {
+		plugin := katago.NewProcessAnalyzer(analyzerCfg, logger)
+		analyzers = append(analyzers, plugin)
+		shutdownManager.Register("analyzer-"+analyzerCfg.Name, func(ctx context.Context) error {
+			return plugin.Close()
+		})
+	}
+	enginePool.SetAnalyzers(analyzers)
 
-	// Start the supervisor
-	if err := supervisor.Start(context.Background()); err != nil {
+	// Start the supervisors
+	if err := enginePool.Start(context.Background()); err != nil {
 		logger.Error("Failed to start KataGo supervisor", "error", err)
 		os.Exit(1)
 	}
 
-	// Get the engine from supervisor
-	engine := supervisor.GetEngine()
+	// Get the default engine from the pool
+	engine := enginePool.Default()
 
-	// Register KataGo supervisor shutdown
+	// Register KataGo supervisor shutdown, letting outstanding queries
+	// finish so rolling restarts do not fail requests
 	shutdownManager.Register("katago-supervisor", func(ctx context.Context) error {
-		return supervisor.Stop()
+		return enginePool.Drain(ctx)
 	})
 
 	// Create metrics collector
@@ -206,14 +291,151 @@ func main() {
 	// Create rate limiter
 	rateLimiter := ratelimit.NewLimiter(&cfg.RateLimit, logger)
 
+	// Track client visit quotas, saving usage across restarts
+	quotaTracker, err := quota.NewTracker(&cfg.Quota, logger)
+	if err != nil {
+		logger.Error("Failed to load quota usage", "error", err)
+		os.Exit(1)
+	}
+	stopQuotaSaver := quotaTracker.StartSaver(time.Minute)
+	shutdownManager.Register("quota-saver", func(ctx context.Context) error {
+		stopQuotaSaver()
+		return nil
+	})
+
 	// Set up health checker
 	healthChecker := health.NewChecker(logger, cfg.Server.Version, GitCommit)
 
-	// Register KataGo health check
-	healthChecker.RegisterCheck("katago", func(ctx context.Context) error {
-		return engine.Ping(ctx)
+	// Register KataGo readiness checks, which fail while an engine is
+	// restarting or overloaded
+	for _, name := range enginePool.Names() {
+		checkName := "katago"
+		if name != config.DefaultEngineName {
+			checkName = "katago-" + name
+		}
+		healthChecker.RegisterCheck(checkName, enginePool.Ready(name, &cfg.Readiness))
+	}
+	healthChecker.RegisterStats("engines", enginePool.GetStatus)
+	healthChecker.RegisterStats("quota", quotaTracker.GetStatus)
+
+	// Surface cache health and statistics in health responses and Prometheus
+	healthChecker.RegisterCheck("cache", func(ctx context.Context) error {
+		if err := cacheManager.Check(ctx); err != nil {
+			return fmt.Errorf("%w: %v", health.ErrDegraded, err)
+		}
+		return nil
+	})
+	healthChecker.RegisterStats("cache", cacheManager.GetStatus)
+	stopCacheReporter := cacheManager.StartStatsReporter(15*time.Second, func(stats cache.Stats) {
+		promCollector.SetCacheStats(float64(stats.Items), float64(stats.Size))
+		promCollector.SetCacheBackendBytes(float64(stats.BackendRawBytes), float64(stats.BackendStoredBytes))
+	})
+	shutdownManager.Register("cache-stats-reporter", func(ctx context.Context) error {
+		stopCacheReporter()
+		return nil
+	})
+
+	// Monitor KataGo process resources
+	resourceMonitor := monitor.New(&cfg.Monitor, logger, func() int {
+		return engine.ProcessID()
+	})
+	healthChecker.RegisterCheck("resources", resourceMonitor.Check)
+	healthChecker.RegisterStats("resources", resourceMonitor.GetStatus)
+	resourceMonitor.Start(func(sample monitor.Sample) {
+		promCollector.SetEngineResources(sample.CPUPercent,
+			float64(sample.RSSBytes), sample.GPUPercent, float64(sample.GPUMemoryBytes))
+	})
+	shutdownManager.Register("resource-monitor", func(ctx context.Context) error {
+		resourceMonitor.Stop()
+		return nil
 	})
 
+	// Review games dropped into the watch directory with the engine that
+	// handles findMistakes
+	watchEngine := engine
+	if name, ok := cfg.EngineRouting["findMistakes"]; ok {
+		watchEngine, _ = enginePool.Engine(name)
+	}
+	watcher := watch.New(&cfg.Watch, watchEngine, logger)
+	watcher.SetReviewConcurrency(cfg.Review.Concurrency)
+	if len(cfg.Webhooks) > 0 {
+		notifier := webhook.New(cfg.Webhooks, logger)
+		watcher.SetNotifier(notifier)
+		healthChecker.RegisterStats("webhooks", notifier.GetStatus)
+		shutdownManager.Register("webhooks", notifier.Close)
+	}
+	if err := watcher.Start(); err != nil {
+		logger.Error("Failed to start directory watcher", "error", err)
+		os.Exit(1)
+	}
+	if watcher.Enabled() {
+		logger.Info("Watching for games to review", "dir", cfg.Watch.Dir, "intervalSeconds", cfg.Watch.IntervalSeconds)
+		if cfg.Watch.Deep.Enabled {
+			logger.Info("Deepening reviewed games nightly", "start", cfg.Watch.Deep.Start, "end", cfg.Watch.Deep.End, "maxVisits", cfg.Watch.Deep.MaxVisits)
+		}
+		healthChecker.RegisterStats("watcher", watcher.GetStatus)
+		shutdownManager.Register("watcher", func(ctx context.Context) error {
+			watcher.Stop()
+			return nil
+		})
+	}
+
+	// Review games posted in Slack channels with the same engine
+	slackBridge := slack.New(&cfg.Slack, watchEngine, logger)
+	if err := slackBridge.Start(); err != nil {
+		logger.Error("Failed to start Slack bridge", "error", err)
+		os.Exit(1)
+	}
+	if slackBridge.Enabled() {
+		logger.Info("Slack bridge started", "addr", slackBridge.Addr().String(), "path", slack.EventsPath)
+		healthChecker.RegisterStats("slack", slackBridge.GetStatus)
+		shutdownManager.Register("slack", slackBridge.Stop)
+	}
+
+	// Analyze common openings while the engine that handles analyzePosition
+	// is idle, so queries about them are cache hits
+	prewarmEngine := engine
+	if name, ok := cfg.EngineRouting["analyzePosition"]; ok {
+		prewarmEngine, _ = enginePool.Engine(name)
+	}
+	switch {
+	case cfg.Prewarm.Enabled && !cfg.Cache.Enabled:
+		logger.Warn("Opening prewarm needs the cache; enable cache.enabled to use it")
+	case cfg.Prewarm.Enabled && cfg.Namespaces.Strict():
+		logger.Warn("Opening prewarm is disabled under strict namespace isolation, where no namespace could use its results")
+	default:
+		prewarmer := prewarm.New(&cfg.Prewarm, prewarmEngine, logger)
+		if err := prewarmer.Start(); err != nil {
+			logger.Error("Failed to start opening prewarm", "error", err)
+			os.Exit(1)
+		}
+		if prewarmer.Enabled() {
+			logger.Info("Prewarming opening positions", "idleSeconds", cfg.Prewarm.IdleSeconds)
+			healthChecker.RegisterStats("prewarm", prewarmer.GetStatus)
+			shutdownManager.Register("prewarm", func(ctx context.Context) error {
+				prewarmer.Stop()
+				return nil
+			})
+		}
+	}
+
+	// Serve GTP to Go GUIs with the engine that handles analyzePosition
+	gtpServer := gtp.New(&cfg.GTP, prewarmEngine, logger)
+	gtpServer.SetLimiter(rateLimiter)
+	gtpServer.SetNamespaces(&cfg.Namespaces)
+	if err := gtpServer.Start(); err != nil {
+		logger.Error("Failed to start GTP listener", "error", err)
+		os.Exit(1)
+	}
+	if gtpServer.Enabled() {
+		logger.Info("GTP listener started", "addr", gtpServer.Addr().String())
+		healthChecker.RegisterStats("gtp", gtpServer.GetStatus)
+		shutdownManager.Register("gtp", func(ctx context.Context) error {
+			gtpServer.Stop()
+			return nil
+		})
+	}
+
 	// Start HTTP health check server
 	healthAddr := os.Getenv("KATAGO_HEALTH_ADDR")
 	if healthAddr == "" {
@@ -222,12 +444,13 @@ func main() {
 	if healthAddr == "" {
 		healthAddr = ":8080" // Default health check port
 	}
-	httpServer := httpserver.NewHTTPServer(healthAddr, logger, healthChecker)
+	httpServer := httpserver.NewHTTPServerWithMetrics(healthAddr, logger, healthChecker, &cfg.Metrics, promCollector)
 	if err := httpServer.Start(); err != nil {
 		logger.Error("Failed to start health check server", "error", err)
 		os.Exit(1)
 	}
-	logger.Info("Health check server started", "addr", healthAddr)
+	logger.Info("Health check server started", "addr", healthAddr,
+		"metricsEnabled", cfg.Metrics.Enabled, "metricsPath", cfg.Metrics.Path)
 
 	// Register HTTP server shutdown
 	shutdownManager.Register("http-server", func(ctx context.Context) error {
@@ -247,10 +470,43 @@ func main() {
 
 	// Create middleware
 	middleware := mcptools.NewMiddleware(logger, metricsCollector, rateLimiter)
+	middleware.SetPrometheus(promCollector)
+	middleware.SetTimeouts(&cfg.Timeouts)
+	middleware.SetClientID(cfg.Server.ClientID)
+	middleware.SetTrustClientIDArgument(cfg.Server.TrustClientIDArgument)
+	middleware.SetNamespaces(&cfg.Namespaces)
+	middleware.SetQuota(quotaTracker)
+	middleware.SetLanes(cfg.ToolLanes)
+	signer, err := provenance.NewSigner(&cfg.Provenance)
+	if err != nil {
+		logger.Error("Failed to set up result signing", "error", err)
+		os.Exit(1)
+	}
 
 	// Create and register tools
 	toolsHandler := mcptools.NewToolsHandler(engine, logger)
+	toolsHandler.SetEngines(enginePool.Engines(), cfg.EngineRouting)
 	toolsHandler.SetMiddleware(middleware)
+	toolsHandler.SetCache(cacheManager)
+	toolsHandler.SetOutput(&cfg.Output)
+	toolsHandler.SetConfig(cfg)
+	toolsHandler.SetNamespaces(&cfg.Namespaces)
+	toolsHandler.SetMonitor(resourceMonitor)
+	toolsHandler.SetQuota(quotaTracker)
+	middleware.SetBoardSizes(toolsHandler.BoardSize)
+	if signer != nil {
+		middleware.SetSigner(signer, toolsHandler.AnalysisSettings)
+		logger.Info("Signing analysis results")
+	}
+	sessionManager := session.NewManager(&cfg.Sessions, logger)
+	sessionManager.SetPrometheus(promCollector)
+	toolsHandler.SetSessions(sessionManager)
+	healthChecker.RegisterStats("sessions", sessionManager.GetStatus)
+	stopSessionExpiry := sessionManager.StartExpiry(time.Minute)
+	shutdownManager.Register("session-expiry", func(ctx context.Context) error {
+		stopSessionExpiry()
+		return nil
+	})
 	toolsHandler.RegisterTools(mcpServer)
 
 	// Register health check tool
@@ -268,6 +524,9 @@ func main() {
 		if detection.Version != "" {
 			status += fmt.Sprintf("  Version: %s\n", detection.Version)
 		}
+		if detection.Backend != "" {
+			status += fmt.Sprintf("  Backend: %s\n", detection.Backend)
+		}
 		status += fmt.Sprintf("  Model: %s\n", cfg.KataGo.ModelPath)
 		status += fmt.Sprintf("  Config: %s\n", cfg.KataGo.ConfigPath)
 		status += "\nEngine Status: "
@@ -277,6 +536,17 @@ func main() {
 			status += "stopped\n"
 		}
 
+		// Add additional engines
+		for _, engineCfg := range cfg.Engines {
+			namedEngine, _ := enginePool.Engine(engineCfg.Name)
+			state := "stopped"
+			if namedEngine.IsRunning() {
+				state = "running"
+			}
+			status += fmt.Sprintf("\nEngine %s: %s\n", engineCfg.Name, state)
+			status += fmt.Sprintf("  Model: %s\n", engineCfg.ModelPath)
+		}
+
 		// Add rate limit status
 		if rateLimiter != nil {
 			rlStatus := rateLimiter.GetStatus()
@@ -289,6 +559,18 @@ func main() {
 			}
 		}
 
+		// Add cache status
+		cacheStatus := cacheManager.GetStatus()
+		status += "\nCache:\n"
+		status += fmt.Sprintf("  Enabled: %v\n", cacheStatus["enabled"])
+		if enabled, ok := cacheStatus["enabled"].(bool); ok && enabled {
+			status += fmt.Sprintf("  Items: %d\n", cacheStatus["items"])
+			status += fmt.Sprintf("  Size: %d bytes\n", cacheStatus["sizeBytes"])
+			if hitRate, ok := cacheStatus["hitRate"].(float64); ok {
+				status += fmt.Sprintf("  Hit rate: %.1f%%\n", hitRate*100)
+			}
+		}
+
 		return mcp.NewToolResultText(status), nil
 	})
 
@@ -309,6 +591,19 @@ func main() {
 		}
 	})
 
+	// Shut down when the MCP client goes away rather than keep KataGo
+	// holding the GPU
+	clientWatchdog := watchdog.New(&cfg.Watchdog, logger, func(reason string) {
+		logger.Info("MCP client gone, shutting down", "reason", reason)
+		go shutdownManager.Shutdown(30 * time.Second)
+	})
+	clientWatchdog.Start()
+	healthChecker.RegisterStats("watchdog", clientWatchdog.GetStatus)
+	shutdownManager.Register("watchdog", func(ctx context.Context) error {
+		clientWatchdog.Stop()
+		return nil
+	})
+
 	// Serve MCP
 	go func() {
 		mcpDone <- server.ServeStdio(mcpServer)
@@ -321,6 +616,8 @@ func main() {
 		if err != nil {
 			logger.Error("MCP server error", "error", err)
 			shutdownManager.Shutdown(30 * time.Second)
+		} else if !clientWatchdog.StdioClosed() {
+			logger.Info("Stdin closed; serving the other listeners until signaled")
 		}
 	case <-shutdownManager.Done():
 		// Graceful shutdown initiated
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/report"
+)
+
+const reviewUsage = `Usage: katago-mcp review [flags] game.sgf
+
+Reviews a game with KataGo and writes a Markdown or HTML report with board
+diagrams, a winrate graph and a mistake table, or an SGF with the review and
+KataGo's analysis of every position for Lizzie, LizzieYzy or Sabaki.
+
+Flags:
+`
+
+// runReview implements the review subcommand and returns the exit code.
+func runReview(args []string) int {
+	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), reviewUsage)
+		fs.PrintDefaults()
+	}
+	var formatName, output string
+	var visits int
+	var demo bool
+	fs.StringVar(&formatName, "format", "", "Report format: markdown, html or lizzie (default: from the -o extension, else markdown)")
+	fs.StringVar(&output, "o", "", "Report file (default: standard output)")
+	fs.IntVar(&visits, "visits", 0, "Visits per move (default: 50)")
+	fs.BoolVar(&demo, "demo", false, "Review with the katago-mock engine instead of KataGo")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	if formatName == "" {
+		formatName = report.FormatMarkdown
+		switch filepath.Ext(output) {
+		case ".html", ".htm":
+			formatName = report.FormatHTML
+		case ".sgf":
+			formatName = report.FormatLizzie
+		}
+	}
+	format, err := report.ParseFormat(formatName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read game: %v\n", err)
+		return 1
+	}
+	sgf := string(data)
+	game, err := katago.NewSGFParser(sgf).Parse()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to parse game: %v\n", err)
+		return 1
+	}
+
+	cfg, err := config.Load(config.GetConfigPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	logger, logCloser := logging.NewLoggerFromConfig(&logging.Config{
+		Level:   cfg.Logging.Level,
+		Format:  logging.LogFormat(os.Getenv("KATAGO_LOG_FORMAT")),
+		Service: cfg.Server.Name,
+		Version: cfg.Server.Version,
+		Prefix:  cfg.Logging.Prefix,
+		File:    &cfg.Logging,
+	})
+	if logCloser != nil {
+		defer func() { _ = logCloser.Close() }()
+	}
+
+	if err := setupReviewEngine(cfg, demo); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	engine := katago.NewEngine(&cfg.KataGo, logger, nil)
+	analyzers := make([]katago.Analyzer, 0, len(cfg.Review.Analyzers))
+	for _, analyzerCfg := range cfg.Review.Analyzers {
+		plugin := katago.NewProcessAnalyzer(analyzerCfg, logger)
+		defer func() { _ = plugin.Close() }()
+		analyzers = append(analyzers, plugin)
+	}
+	engine.SetAnalyzers(analyzers)
+	if err := engine.Start(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start KataGo: %v\n", err)
+		return 1
+	}
+	defer func() { _ = engine.Stop() }()
+
+	thresholds := katago.DefaultMistakeThresholds()
+	thresholds.Concurrency = cfg.Review.Concurrency
+	if visits > 0 {
+		thresholds.MinimumVisits = visits
+	}
+	review, err := engine.ReviewGame(ctx, sgf, thresholds)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to review game: %v\n", err)
+		return 1
+	}
+	if review.Summary.Partial {
+		fmt.Fprintf(os.Stderr, "Review interrupted after %d of %d moves\n",
+			review.Summary.AnalyzedMoves, review.Summary.TotalMoves)
+	}
+
+	content, err := report.Generate(format, sgf, game, review)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if output == "" {
+		fmt.Print(content)
+		return 0
+	}
+	if err := os.WriteFile(output, []byte(content), 0o644); err != nil { // #nosec G306 -- reports are meant to be shared
+		fmt.Fprintf(os.Stderr, "Failed to write report: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Report written to %s\n", output)
+	return 0
+}
+
+// setupReviewEngine fills in the KataGo paths the configuration leaves
+// unset from detection, or points the engine at katago-mock in demo mode.
+func setupReviewEngine(cfg *config.Config, demo bool) error {
+	if demo {
+		_, err := demoSetup(cfg)
+		return err
+	}
+	detection, err := katago.DetectKataGo()
+	if err != nil {
+		return fmt.Errorf("KataGo detection failed: %w", err)
+	}
+	if cfg.KataGo.BinaryPath == "katago" ||
+		(detection.ReplacedBinaryPath != "" && cfg.KataGo.BinaryPath == detection.ReplacedBinaryPath) {
+		cfg.KataGo.BinaryPath = detection.BinaryPath
+	}
+	if cfg.KataGo.ModelPath == "" {
+		cfg.KataGo.ModelPath = detection.ModelPath
+	}
+	if cfg.KataGo.ConfigPath == "" {
+		cfg.KataGo.ConfigPath = detection.ConfigPath
+	}
+	return nil
+}
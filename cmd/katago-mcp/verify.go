@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/provenance"
+)
+
+const verifyUsage = `Usage: katago-mcp verify [flags] result.json
+
+Checks the signed provenance of a tool result, saved as the JSON of the
+result or of the JSON-RPC response carrying it. The key is the server's
+provenance key, from the configuration unless -key-file is given.
+
+Flags:
+`
+
+// signedResult is a tool result as it is sent to MCP clients.
+type signedResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Meta struct {
+		Provenance *provenance.Record `json:"provenance"`
+	} `json:"_meta"`
+}
+
+// runVerify implements the verify subcommand and returns the exit code.
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), verifyUsage)
+		fs.PrintDefaults()
+	}
+	var keyFile string
+	fs.StringVar(&keyFile, "key-file", "", "File holding the provenance key")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := config.Load(config.GetConfigPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	keyCfg := cfg.Provenance
+	keyCfg.Enabled = true
+	if keyFile != "" {
+		keyCfg.Key, keyCfg.KeyFile = "", keyFile
+	}
+	signer, err := provenance.NewSigner(&keyCfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read result: %v\n", err)
+		return 1
+	}
+	var response struct {
+		Result *signedResult `json:"result"`
+	}
+	var result signedResult
+	if err := json.Unmarshal(data, &response); err == nil && response.Result != nil {
+		result = *response.Result
+	} else if err := json.Unmarshal(data, &result); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to parse result: %v\n", err)
+		return 1
+	}
+	record := result.Meta.Provenance
+	if record == nil {
+		fmt.Fprintln(os.Stderr, "The result has no provenance record")
+		return 1
+	}
+
+	var texts []string
+	for _, content := range result.Content {
+		if content.Type == "text" {
+			texts = append(texts, content.Text)
+		}
+	}
+	if err := signer.Verify(record, texts); err != nil {
+		fmt.Fprintf(os.Stderr, "Verification failed: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Verified: %s on engine %s (KataGo %s, model %s), %d visits, at %s\n",
+		record.Tool, record.Engine, record.KataGoVersion, record.Model, record.VisitsUsed, record.Timestamp)
+	return 0
+}
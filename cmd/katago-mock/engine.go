@@ -0,0 +1,618 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+const (
+	// mockVersion is the KataGo release whose protocol the mock speaks.
+	mockVersion = "1.14.1"
+
+	defaultVisits     = 100
+	defaultPVLen      = 5
+	maxCandidates     = 10
+	maxBoardSize      = 19
+	passPrior         = 0.001
+	leadPerCandidate  = 0.7 // Points lost by each candidate below the best
+	tempoLead         = 7.0 // Value of the first move, roughly fair komi
+	ownershipRadius   = 4
+	priorTemperature  = 0.3
+	maxQueryLineBytes = 16 * 1024 * 1024
+)
+
+// engine answers queries as a given KataGo release would, leaving out
+// response fields that release does not report.
+type engine struct {
+	version string
+	caps    katago.Capabilities
+
+	// replayer answers the queries of a recorded session, when replaying
+	replayer *katago.Replayer
+	misses   io.Writer // Told of queries missing from the recording
+}
+
+// newEngine creates an engine emulating a KataGo release, such as "1.14.1".
+func newEngine(version string) (*engine, error) {
+	caps, err := katago.CapabilitiesFor(version)
+	if err != nil {
+		return nil, err
+	}
+	return &engine{version: version, caps: caps}, nil
+}
+
+// query is an analysis engine request. Fields the mock does not need, such
+// as rules and maxTime, are accepted and ignored.
+type query struct {
+	ID                    string                 `json:"id"`
+	Action                string                 `json:"action"`
+	TerminateID           string                 `json:"terminateId"`
+	Moves                 [][]string             `json:"moves"`
+	InitialStones         [][]string             `json:"initialStones"`
+	InitialPlayer         string                 `json:"initialPlayer"`
+	Komi                  *float64               `json:"komi"`
+	BoardXSize            int                    `json:"boardXSize"`
+	BoardYSize            int                    `json:"boardYSize"`
+	AnalyzeTurns          []int                  `json:"analyzeTurns"`
+	MaxVisits             int                    `json:"maxVisits"`
+	IncludeOwnership      bool                   `json:"includeOwnership"`
+	IncludeOwnershipStdev bool                   `json:"includeOwnershipStdev"`
+	IncludeMovesOwnership bool                   `json:"includeMovesOwnership"`
+	IncludePolicy         bool                   `json:"includePolicy"`
+	IncludePVVisits       bool                   `json:"includePVVisits"`
+	AvoidMoves            []moveRestriction      `json:"avoidMoves"`
+	AllowMoves            []moveRestriction      `json:"allowMoves"`
+	OverrideSettings      map[string]interface{} `json:"overrideSettings"`
+}
+
+// moveRestriction is an avoidMoves or allowMoves entry.
+type moveRestriction struct {
+	Player string   `json:"player"`
+	Moves  []string `json:"moves"`
+}
+
+// response is an analysis result for one turn.
+type response struct {
+	ID             string     `json:"id"`
+	TurnNumber     int        `json:"turnNumber"`
+	IsDuringSearch bool       `json:"isDuringSearch"`
+	MoveInfos      []moveInfo `json:"moveInfos"`
+	RootInfo       rootInfo   `json:"rootInfo"`
+	Ownership      []float64  `json:"ownership,omitempty"`
+	OwnershipStdev []float64  `json:"ownershipStdev,omitempty"`
+	Policy         []float64  `json:"policy,omitempty"`
+}
+
+type moveInfo struct {
+	Move       string    `json:"move"`
+	Visits     int       `json:"visits"`
+	Winrate    float64   `json:"winrate"`
+	ScoreLead  *float64  `json:"scoreLead,omitempty"`
+	ScoreMean  float64   `json:"scoreMean"`
+	ScoreStdev float64   `json:"scoreStdev"`
+	Prior      float64   `json:"prior"`
+	Utility    float64   `json:"utility"`
+	LCB        float64   `json:"lcb"`
+	Order      int       `json:"order"`
+	PV         []string  `json:"pv"`
+	PVVisits   []int     `json:"pvVisits,omitempty"`
+	Ownership  []float64 `json:"ownership,omitempty"`
+}
+
+type rootInfo struct {
+	CurrentPlayer   string   `json:"currentPlayer"`
+	Visits          int      `json:"visits"`
+	Winrate         float64  `json:"winrate"`
+	ScoreLead       *float64 `json:"scoreLead,omitempty"`
+	ScoreMean       float64  `json:"scoreMean"`
+	ScoreStdev      float64  `json:"scoreStdev"`
+	Utility         float64  `json:"utility"`
+	RawWinrate      float64  `json:"rawWinrate"`
+	RawLead         float64  `json:"rawLead"`
+	RawStWrError    *float64 `json:"rawStWrError,omitempty"`
+	RawStScoreError *float64 `json:"rawStScoreError,omitempty"`
+	RawVarTimeLeft  *float64 `json:"rawVarTimeLeft,omitempty"`
+}
+
+// errorResponse reports a query KataGo would reject.
+type errorResponse struct {
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
+}
+
+// serve answers queries read line by line from r until it is closed.
+func (e *engine) serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxQueryLineBytes)
+	out := bufio.NewWriter(w)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		for _, result := range e.handle([]byte(line)) {
+			data, err := json.Marshal(result)
+			if err != nil {
+				return err
+			}
+			if _, err := out.Write(append(data, '\n')); err != nil {
+				return err
+			}
+		}
+		// Flush after each query so clients are not left waiting
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle answers a single query line.
+func (e *engine) handle(line []byte) []interface{} {
+	var q query
+	if err := json.Unmarshal(line, &q); err != nil {
+		return []interface{}{errorResponse{Error: "Could not parse json: " + err.Error()}}
+	}
+	if q.ID == "" {
+		return []interface{}{errorResponse{Error: "Request must have a string field 'id'", Field: "id"}}
+	}
+	if e.replayer != nil {
+		if responses, ok := e.replayer.Respond(line); ok {
+			results := make([]interface{}, len(responses))
+			for i, resp := range responses {
+				results[i] = json.RawMessage(resp)
+			}
+			return results
+		}
+		if e.misses != nil {
+			fmt.Fprintf(e.misses, "katago-mock: query %s is not in the recording, answering with mock analysis\n", q.ID)
+		}
+	}
+
+	switch q.Action {
+	case "":
+		return e.analyze(&q)
+	case "query_version":
+		return []interface{}{map[string]interface{}{
+			"id": q.ID, "action": q.Action, "version": e.version, "git_hash": "mock",
+		}}
+	case "clear_cache":
+		return []interface{}{map[string]interface{}{"id": q.ID, "action": q.Action}}
+	case "terminate":
+		// Queries are answered as they arrive, so there is never a search
+		// left to stop
+		return []interface{}{map[string]interface{}{
+			"id": q.ID, "action": q.Action, "terminateId": q.TerminateID, "turnNumbers": []int{},
+		}}
+	case "terminate_all":
+		return []interface{}{map[string]interface{}{"id": q.ID, "action": q.Action, "turnNumbers": []int{}}}
+	}
+	return []interface{}{errorResponse{ID: q.ID, Error: "unknown action: " + q.Action, Field: "action"}}
+}
+
+// analyze answers an analysis query with one response per analyzed turn.
+func (e *engine) analyze(q *query) []interface{} {
+	if q.BoardXSize < 2 || q.BoardXSize > maxBoardSize {
+		return []interface{}{errorResponse{ID: q.ID, Error: fmt.Sprintf("boardXSize must be from 2 to %d", maxBoardSize), Field: "boardXSize"}}
+	}
+	if q.BoardYSize < 2 || q.BoardYSize > maxBoardSize {
+		return []interface{}{errorResponse{ID: q.ID, Error: fmt.Sprintf("boardYSize must be from 2 to %d", maxBoardSize), Field: "boardYSize"}}
+	}
+
+	position := &katago.Position{BoardXSize: q.BoardXSize, BoardYSize: q.BoardYSize}
+	for _, stone := range q.InitialStones {
+		if len(stone) != 2 {
+			return []interface{}{errorResponse{ID: q.ID, Error: "initial stones must be [color, location] pairs", Field: "initialStones"}}
+		}
+		position.InitialStones = append(position.InitialStones, katago.Stone{Color: stone[0], Location: stone[1]})
+	}
+	for _, move := range q.Moves {
+		if len(move) != 2 {
+			return []interface{}{errorResponse{ID: q.ID, Error: "moves must be [color, location] pairs", Field: "moves"}}
+		}
+		position.Moves = append(position.Moves, katago.Move{Color: move[0], Location: move[1]})
+	}
+	// Reject the query up front if any move is illegal, as KataGo does
+	if _, err := katago.BoardFromPosition(position); err != nil {
+		return []interface{}{errorResponse{ID: q.ID, Error: err.Error(), Field: "moves"}}
+	}
+
+	turns := q.AnalyzeTurns
+	if len(turns) == 0 {
+		turns = []int{len(q.Moves)}
+	}
+	results := make([]interface{}, 0, len(turns))
+	for _, turn := range turns {
+		if turn < 0 || turn > len(q.Moves) {
+			return []interface{}{errorResponse{ID: q.ID, Error: fmt.Sprintf("Invalid turn number %d", turn), Field: "analyzeTurns"}}
+		}
+		results = append(results, e.analyzeTurn(q, position, turn))
+	}
+	return results
+}
+
+// analyzeTurn produces a deterministic evaluation of the position after
+// turn moves. Candidates favour the third and fourth lines and points near
+// the last move, and each is a fixed number of points worse than the one
+// before, so results look plausible without a neural network.
+func (e *engine) analyzeTurn(q *query, position *katago.Position, turn int) *response {
+	prefix := *position
+	prefix.Moves = position.Moves[:turn]
+	board, _ := katago.BoardFromPosition(&prefix)
+	seed := positionSeed(q, turn)
+	komi := 7.5
+	if q.Komi != nil {
+		komi = *q.Komi
+	}
+
+	toMove := strings.ToUpper(q.InitialPlayer)
+	if turn > 0 {
+		toMove = "B"
+		if strings.EqualFold(prefix.Moves[turn-1].Color, "b") {
+			toMove = "W"
+		}
+	} else if toMove != "W" {
+		toMove = "B"
+	}
+	sign := 1.0
+	if toMove == "W" {
+		sign = -1
+	}
+
+	// Score every legal point and turn the scores into a policy
+	xSize, ySize := q.BoardXSize, q.BoardYSize
+	lastX, lastY, hasLast := -1, -1, false
+	if last := board.LastMove(); last != "" {
+		lastX, lastY = pointOf(last, ySize)
+		hasLast = true
+	}
+	scores := make([]float64, xSize*ySize)
+	legal := make([]bool, xSize*ySize)
+	total := 0.0
+	for y := 0; y < ySize; y++ {
+		for x := 0; x < xSize; x++ {
+			point := y*xSize + x
+			loc := location(x, y, ySize)
+			if !board.IsLegal(toMove, loc) {
+				continue
+			}
+			legal[point] = true
+			score := lineScore(x, y, xSize, ySize) + 0.3*noise(seed, loc)
+			if hasLast && abs(x-lastX)+abs(y-lastY) <= 2 {
+				score += 0.4
+			}
+			scores[point] = score
+			total += math.Exp(score / priorTemperature)
+		}
+	}
+	priors := make([]float64, xSize*ySize+1)
+	for point := range scores {
+		priors[point] = -1
+		if legal[point] {
+			priors[point] = (1 - passPrior) * math.Exp(scores[point]/priorTemperature) / total
+		}
+	}
+	priors[xSize*ySize] = passPrior
+	if total == 0 {
+		priors[xSize*ySize] = 1
+	}
+
+	// Pick the best candidates the move restrictions allow
+	var candidates []int
+	for point := range scores {
+		if legal[point] && restrictionsAllow(q, toMove, location(point%xSize, point/xSize, ySize)) {
+			candidates = append(candidates, point)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return scores[candidates[i]] > scores[candidates[j]] })
+	if len(candidates) > maxCandidates {
+		candidates = candidates[:maxCandidates]
+	}
+	moves := make([]string, len(candidates))
+	for i, point := range candidates {
+		moves[i] = location(point%xSize, point/xSize, ySize)
+	}
+	if len(moves) == 0 {
+		moves = []string{"pass"}
+		candidates = []int{xSize * ySize}
+	}
+
+	// Black's lead counts stones on the board, komi and the tempo of the
+	// player to move, plus a little seeded noise
+	stones := 0
+	for y := 0; y < ySize; y++ {
+		for x := 0; x < xSize; x++ {
+			switch board.Stone(location(x, y, ySize)) {
+			case "b":
+				stones++
+			case "w":
+				stones--
+			}
+		}
+	}
+	rootLead := float64(stones) - komi + tempoLead + sign*0.5 + noise(seed, "lead") - 0.5
+	scoreStdev := math.Max(3, 15-0.05*float64(turn))
+
+	visits := q.MaxVisits
+	if visits < 1 {
+		visits = defaultVisits
+	}
+	pvLen := defaultPVLen
+	if v, ok := q.OverrideSettings["analysisPVLen"].(float64); ok && v >= 1 {
+		pvLen = int(v)
+	}
+
+	includePVVisits := q.IncludePVVisits && e.caps.Supports(katago.FeaturePVVisits)
+	includeMovesOwnership := q.IncludeMovesOwnership && e.caps.Supports(katago.FeatureMovesOwnership)
+	var ownership []float64
+	if q.IncludeOwnership || includeMovesOwnership {
+		ownership = estimateOwnership(board, xSize, ySize)
+	}
+
+	infos := make([]moveInfo, len(moves))
+	shares := visitShares(visits-1, len(moves))
+	for i, move := range moves {
+		lead := rootLead - sign*leadPerCandidate*float64(i)
+		winrate := leadWinrate(lead)
+		info := moveInfo{
+			Move:       move,
+			Visits:     shares[i],
+			Winrate:    round(winrate),
+			ScoreLead:  e.scoreLead(lead),
+			ScoreMean:  round(lead),
+			ScoreStdev: round(scoreStdev),
+			Prior:      round(priors[candidates[i]]),
+			Utility:    round(2*winrate - 1),
+			LCB:        round(winrate - sign*0.02),
+			Order:      i,
+			PV:         principalVariation(moves, i, pvLen),
+		}
+		if includePVVisits {
+			info.PVVisits = make([]int, len(info.PV))
+			for j := range info.PVVisits {
+				info.PVVisits[j] = max(1, shares[i]>>j)
+			}
+		}
+		if includeMovesOwnership {
+			// Re-estimate with the move played, so its effect shows up
+			info.Ownership = ownership
+			after := board.Clone()
+			if move != "pass" && after.Play(toMove, move) == nil {
+				info.Ownership = estimateOwnership(after, xSize, ySize)
+			}
+		}
+		infos[i] = info
+	}
+
+	rootWinrate := leadWinrate(rootLead)
+	resp := &response{
+		ID:         q.ID,
+		TurnNumber: turn,
+		MoveInfos:  infos,
+		RootInfo: rootInfo{
+			CurrentPlayer: toMove,
+			Visits:        visits,
+			Winrate:       round(rootWinrate),
+			ScoreLead:     e.scoreLead(rootLead),
+			ScoreMean:     round(rootLead),
+			ScoreStdev:    round(scoreStdev),
+			Utility:       round(2*rootWinrate - 1),
+			RawWinrate:    round(rootWinrate),
+			RawLead:       round(rootLead),
+		},
+	}
+	if e.caps.Supports(katago.FeatureRawErrors) {
+		stWrError, stScoreError, varTimeLeft := round(0.05+0.1*noise(seed, "wr")), round(scoreStdev/4), round(math.Max(1, 100-float64(turn)/4))
+		resp.RootInfo.RawStWrError = &stWrError
+		resp.RootInfo.RawStScoreError = &stScoreError
+		resp.RootInfo.RawVarTimeLeft = &varTimeLeft
+	}
+	if q.IncludeOwnership {
+		resp.Ownership = ownership
+		if q.IncludeOwnershipStdev {
+			// Points owned by neither side are the ones the search is split on
+			resp.OwnershipStdev = make([]float64, len(ownership))
+			for i, o := range ownership {
+				resp.OwnershipStdev[i] = round(math.Sqrt(1 - o*o))
+			}
+		}
+	}
+	if q.IncludePolicy {
+		for i := range priors {
+			priors[i] = round(priors[i])
+		}
+		resp.Policy = priors
+	}
+	return resp
+}
+
+// scoreLead returns the lead for releases that report it separately from
+// scoreMean.
+func (e *engine) scoreLead(lead float64) *float64 {
+	if !e.caps.Supports(katago.FeatureScoreLead) {
+		return nil
+	}
+	lead = round(lead)
+	return &lead
+}
+
+// restrictionsAllow reports whether avoidMoves and allowMoves permit the
+// player to move at loc.
+func restrictionsAllow(q *query, player, loc string) bool {
+	for _, r := range q.AvoidMoves {
+		if strings.EqualFold(r.Player, player) && containsMove(r.Moves, loc) {
+			return false
+		}
+	}
+	for _, r := range q.AllowMoves {
+		if strings.EqualFold(r.Player, player) && !containsMove(r.Moves, loc) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsMove(moves []string, loc string) bool {
+	for _, move := range moves {
+		if strings.EqualFold(move, loc) {
+			return true
+		}
+	}
+	return false
+}
+
+// principalVariation starts with the candidate and follows it with the
+// other candidates in order.
+func principalVariation(moves []string, index, length int) []string {
+	pv := []string{moves[index]}
+	for i, move := range moves {
+		if len(pv) >= length {
+			break
+		}
+		if i != index {
+			pv = append(pv, move)
+		}
+	}
+	return pv
+}
+
+// visitShares splits visits among candidates, halving for each one. Every
+// candidate gets at least one visit.
+func visitShares(visits, n int) []int {
+	shares := make([]int, n)
+	extra := max(visits-n, 0)
+	for i := range shares {
+		share := extra / 2
+		if i == n-1 {
+			share = extra
+		}
+		shares[i] = 1 + share
+		extra -= share
+	}
+	return shares
+}
+
+// estimateOwnership gives stones strong ownership and spreads their
+// influence over nearby empty points, from Black's perspective.
+func estimateOwnership(board *katago.Board, xSize, ySize int) []float64 {
+	colors := make([]float64, xSize*ySize)
+	for y := 0; y < ySize; y++ {
+		for x := 0; x < xSize; x++ {
+			switch board.Stone(location(x, y, ySize)) {
+			case "b":
+				colors[y*xSize+x] = 1
+			case "w":
+				colors[y*xSize+x] = -1
+			}
+		}
+	}
+
+	ownership := make([]float64, xSize*ySize)
+	for point, color := range colors {
+		if color != 0 {
+			ownership[point] = 0.95 * color
+			continue
+		}
+		x, y := point%xSize, point/xSize
+		influence := 0.0
+		for other, c := range colors {
+			if c == 0 {
+				continue
+			}
+			if d := abs(x-other%xSize) + abs(y-other/xSize); d <= ownershipRadius {
+				influence += c / float64(d*d)
+			}
+		}
+		ownership[point] = round(math.Tanh(influence))
+	}
+	return ownership
+}
+
+// lineScore prefers the third and fourth lines, as in the opening.
+func lineScore(x, y, xSize, ySize int) float64 {
+	line := min(x, y, xSize-1-x, ySize-1-y)
+	switch line {
+	case 0:
+		return 0.1
+	case 1:
+		return 0.4
+	case 2:
+		return 1.0
+	case 3:
+		return 0.9
+	}
+	return 0.6
+}
+
+// leadWinrate converts Black's score lead to Black's winning chance.
+func leadWinrate(lead float64) float64 {
+	return 1 / (1 + math.Exp(-lead/5))
+}
+
+// positionSeed hashes everything that defines the analyzed position, so
+// identical queries get identical answers whatever their IDs.
+func positionSeed(q *query, turn int) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%dx%d|%s|", q.BoardXSize, q.BoardYSize, strings.ToUpper(q.InitialPlayer))
+	if q.Komi != nil {
+		fmt.Fprintf(h, "%g", *q.Komi)
+	}
+	fmt.Fprint(h, "|")
+	for _, stone := range q.InitialStones {
+		fmt.Fprintf(h, "%s;", strings.ToUpper(strings.Join(stone, ":")))
+	}
+	fmt.Fprint(h, "|")
+	for _, move := range q.Moves[:turn] {
+		fmt.Fprintf(h, "%s;", strings.ToUpper(strings.Join(move, ":")))
+	}
+	return h.Sum64()
+}
+
+// noise returns a value in [0, 1) derived from the seed and a key.
+func noise(seed uint64, key string) float64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d|%s", seed, key)
+	return float64(h.Sum64()%10000) / 10000
+}
+
+// location returns the GTP coordinate of a point, with row 0 at the top.
+func location(x, y, ySize int) string {
+	col := byte('A' + x)
+	if col >= 'I' {
+		col++
+	}
+	return fmt.Sprintf("%c%d", col, ySize-y)
+}
+
+// pointOf converts a GTP coordinate back to 0-based indices.
+func pointOf(loc string, ySize int) (x, y int) {
+	col := loc[0]
+	x = int(col - 'A')
+	if col > 'I' {
+		x--
+	}
+	var row int
+	_, _ = fmt.Sscanf(loc[1:], "%d", &row)
+	return x, ySize - row
+}
+
+// round keeps output compact and stable across platforms.
+func round(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
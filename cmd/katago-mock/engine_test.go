@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+// decode round-trips a handled query through JSON, as a client sees it.
+func decode(t *testing.T, line string) []map[string]interface{} {
+	t.Helper()
+	return decodeAs(t, mockVersion, line)
+}
+
+// decodeAs handles a query as the given KataGo release would.
+func decodeAs(t *testing.T, version, line string) []map[string]interface{} {
+	t.Helper()
+	e, err := newEngine(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []map[string]interface{}
+	for _, result := range e.handle([]byte(line)) {
+		data, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("Failed to marshal result: %v", err)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(data, &m); err != nil {
+			t.Fatalf("Failed to unmarshal result: %v", err)
+		}
+		results = append(results, m)
+	}
+	return results
+}
+
+func TestAnalyzeIsDeterministic(t *testing.T) {
+	query := `"boardXSize":19,"boardYSize":19,"komi":7.5,"moves":[["B","Q16"],["W","D4"]]`
+	first := decode(t, `{"id":"first",`+query+`}`)
+	second := decode(t, `{"id":"second",`+query+`}`)
+	if len(first) != 1 || len(second) != 1 {
+		t.Fatalf("Expected one response each, got %d and %d", len(first), len(second))
+	}
+	delete(first[0], "id")
+	delete(second[0], "id")
+	a, _ := json.Marshal(first[0])
+	b, _ := json.Marshal(second[0])
+	if !bytes.Equal(a, b) {
+		t.Errorf("Expected identical analysis for identical positions:\n%s\n%s", a, b)
+	}
+
+	other := decode(t, `{"id":"other","boardXSize":19,"boardYSize":19,"komi":7.5,"moves":[["B","Q16"],["W","D16"]]}`)
+	c, _ := json.Marshal(other[0]["rootInfo"])
+	if bytes.Equal(c, mustMarshal(t, first[0]["rootInfo"])) {
+		t.Error("Expected different positions to get different evaluations")
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestAnalyzeResponse(t *testing.T) {
+	results := decode(t, `{"id":"q","boardXSize":9,"boardYSize":9,"maxVisits":50,
+		"moves":[["B","E5"],["W","C3"]],"includePolicy":true,"includeOwnership":true,"includePVVisits":true}`)
+	resp := results[0]
+
+	root := resp["rootInfo"].(map[string]interface{})
+	if root["currentPlayer"] != "B" || root["visits"] != float64(50) {
+		t.Errorf("Unexpected root info: %v", root)
+	}
+
+	infos := resp["moveInfos"].([]interface{})
+	if len(infos) != maxCandidates {
+		t.Fatalf("Expected %d candidates, got %d", maxCandidates, len(infos))
+	}
+	visits := 0.0
+	prevWinrate := 2.0
+	for i, item := range infos {
+		info := item.(map[string]interface{})
+		move := info["move"].(string)
+		if move == "E5" || move == "C3" {
+			t.Errorf("Candidate %d is an occupied point: %s", i, move)
+		}
+		if info["order"] != float64(i) {
+			t.Errorf("Expected order %d, got %v", i, info["order"])
+		}
+		winrate := info["winrate"].(float64)
+		if winrate >= prevWinrate {
+			t.Errorf("Expected candidates in decreasing winrate order, got %v after %v", winrate, prevWinrate)
+		}
+		prevWinrate = winrate
+		pv := info["pv"].([]interface{})
+		if pv[0] != move || len(pv) != len(info["pvVisits"].([]interface{})) {
+			t.Errorf("Unexpected PV for %s: %v", move, info)
+		}
+		visits += info["visits"].(float64)
+	}
+	if visits != 49 {
+		t.Errorf("Expected candidate visits to sum to 49, got %v", visits)
+	}
+
+	policy := resp["policy"].([]interface{})
+	if len(policy) != 82 {
+		t.Fatalf("Expected 82 policy values, got %d", len(policy))
+	}
+	// E5 is row 5 from the top of a 9x9 board, column 4
+	if policy[4*9+4] != float64(-1) {
+		t.Errorf("Expected occupied point to have policy -1, got %v", policy[4*9+4])
+	}
+	sum := 0.0
+	for _, p := range policy {
+		if p.(float64) > 0 {
+			sum += p.(float64)
+		}
+	}
+	if sum < 0.99 || sum > 1.01 {
+		t.Errorf("Expected policy to sum to 1, got %v", sum)
+	}
+
+	ownership := resp["ownership"].([]interface{})
+	if len(ownership) != 81 || ownership[4*9+4].(float64) <= 0.9 || ownership[6*9+2].(float64) >= -0.9 {
+		t.Errorf("Expected stones to own their points, got E5=%v C3=%v", ownership[4*9+4], ownership[6*9+2])
+	}
+}
+
+func TestAnalyzeMoveRestrictions(t *testing.T) {
+	results := decode(t, `{"id":"q","boardXSize":9,"boardYSize":9,"moves":[],
+		"allowMoves":[{"player":"B","moves":["A1","J9"],"untilDepth":1}]}`)
+	infos := results[0]["moveInfos"].([]interface{})
+	if len(infos) != 2 {
+		t.Fatalf("Expected only allowed moves, got %v", infos)
+	}
+
+	results = decode(t, `{"id":"q","boardXSize":9,"boardYSize":9,"moves":[],
+		"avoidMoves":[{"player":"B","moves":["C3","C7","G3","G7"],"untilDepth":1}]}`)
+	for _, item := range results[0]["moveInfos"].([]interface{}) {
+		move := item.(map[string]interface{})["move"]
+		if move == "C3" || move == "C7" || move == "G3" || move == "G7" {
+			t.Errorf("Expected avoided move %v to be excluded", move)
+		}
+	}
+
+	// A player with no legal moves passes
+	results = decode(t, `{"id":"q","boardXSize":9,"boardYSize":9,"moves":[],
+		"allowMoves":[{"player":"W","moves":["A1"],"untilDepth":1}],"initialPlayer":"W",
+		"initialStones":[]}`)
+	infos = results[0]["moveInfos"].([]interface{})
+	if len(infos) != 1 || infos[0].(map[string]interface{})["move"] != "A1" {
+		t.Errorf("Expected white to be restricted to A1, got %v", infos)
+	}
+}
+
+func TestAnalyzeTurns(t *testing.T) {
+	results := decode(t, `{"id":"game","boardXSize":9,"boardYSize":9,
+		"moves":[["B","E5"],["W","C3"],["B","G7"]],"analyzeTurns":[0,1,3]}`)
+	if len(results) != 3 {
+		t.Fatalf("Expected three responses, got %d", len(results))
+	}
+	for i, turn := range []float64{0, 1, 3} {
+		if results[i]["turnNumber"] != turn || results[i]["id"] != "game" {
+			t.Errorf("Response %d: expected turn %v, got %v", i, turn, results[i]["turnNumber"])
+		}
+	}
+	if player := results[1]["rootInfo"].(map[string]interface{})["currentPlayer"]; player != "W" {
+		t.Errorf("Expected white to move after one move, got %v", player)
+	}
+}
+
+func TestHandleErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		field string
+	}{
+		{"invalid json", `{"id":`, ""},
+		{"missing id", `{"boardXSize":9,"boardYSize":9,"moves":[]}`, "id"},
+		{"board too large", `{"id":"q","boardXSize":25,"boardYSize":25,"moves":[]}`, "boardXSize"},
+		{"illegal move", `{"id":"q","boardXSize":9,"boardYSize":9,"moves":[["B","E5"],["W","E5"]]}`, "moves"},
+		{"bad coordinate", `{"id":"q","boardXSize":9,"boardYSize":9,"moves":[["B","Z20"]]}`, "moves"},
+		{"bad turn", `{"id":"q","boardXSize":9,"boardYSize":9,"moves":[],"analyzeTurns":[2]}`, "analyzeTurns"},
+		{"unknown action", `{"id":"q","action":"explode"}`, "action"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := decode(t, tt.line)
+			if len(results) != 1 || results[0]["error"] == nil {
+				t.Fatalf("Expected an error response, got %v", results)
+			}
+			if field, _ := results[0]["field"].(string); field != tt.field {
+				t.Errorf("Expected field %q, got %q", tt.field, field)
+			}
+		})
+	}
+}
+
+func TestHandleActions(t *testing.T) {
+	version := decode(t, `{"id":"v","action":"query_version"}`)[0]
+	if version["version"] != mockVersion || version["id"] != "v" {
+		t.Errorf("Unexpected version response: %v", version)
+	}
+	terminate := decode(t, `{"id":"t","action":"terminate","terminateId":"q1"}`)[0]
+	if terminate["terminateId"] != "q1" || terminate["action"] != "terminate" {
+		t.Errorf("Unexpected terminate response: %v", terminate)
+	}
+	if cleared := decode(t, `{"id":"c","action":"clear_cache"}`)[0]; cleared["error"] != nil {
+		t.Errorf("Unexpected clear_cache response: %v", cleared)
+	}
+}
+
+func TestEmulatedVersions(t *testing.T) {
+	line := `{"id":"q","boardXSize":9,"boardYSize":9,"moves":[["B","E5"]],"includePVVisits":true,"includeMovesOwnership":true}`
+
+	current := decodeAs(t, "1.15.3", line)[0]
+	root := current["rootInfo"].(map[string]interface{})
+	info := current["moveInfos"].([]interface{})[0].(map[string]interface{})
+	for _, field := range []string{"scoreLead", "rawStWrError", "rawVarTimeLeft"} {
+		if _, ok := root[field]; !ok {
+			t.Errorf("Expected %s from 1.15.3", field)
+		}
+	}
+	if info["pvVisits"] == nil || info["ownership"] == nil {
+		t.Errorf("Expected pvVisits and per-move ownership from 1.15.3, got %v", info)
+	}
+
+	old := decodeAs(t, "1.3.5", line)[0]
+	root = old["rootInfo"].(map[string]interface{})
+	info = old["moveInfos"].([]interface{})[0].(map[string]interface{})
+	for _, field := range []string{"scoreLead", "rawStWrError"} {
+		if _, ok := root[field]; ok {
+			t.Errorf("Expected no %s from 1.3.5", field)
+		}
+	}
+	if info["scoreLead"] != nil || info["pvVisits"] != nil || info["ownership"] != nil {
+		t.Errorf("Expected only 1.3.5 fields, got %v", info)
+	}
+
+	version := decodeAs(t, "1.10.0", `{"id":"v","action":"query_version"}`)[0]
+	if version["version"] != "1.10.0" {
+		t.Errorf("Expected emulated version to be reported, got %v", version)
+	}
+	if _, err := newEngine("latest"); err == nil {
+		t.Error("Expected error for a version without a number")
+	}
+}
+
+func TestServe(t *testing.T) {
+	input := strings.Join([]string{
+		`{"id":"v","action":"query_version"}`,
+		``,
+		`{"id":"q","boardXSize":9,"boardYSize":9,"moves":[],"analyzeTurns":[0]}`,
+	}, "\n")
+	e, err := newEngine(mockVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := e.serve(strings.NewReader(input), &out); err != nil {
+		t.Fatalf("serve failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected two response lines, got %d: %s", len(lines), out.String())
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("Invalid JSON line: %s", line)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	lines, err := katago.ReadRecording(strings.NewReader(
+		`{"direction":"query","data":{"id":"q1","boardXSize":9,"boardYSize":9,"moves":[]}}
+{"direction":"response","data":{"id":"q1","turnNumber":0,"moveInfos":[],"rootInfo":{"winrate":0.123}}}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := katago.NewReplayer(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := newEngine(mockVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var misses bytes.Buffer
+	e.replayer, e.misses = replayer, &misses
+
+	results := e.handle([]byte(`{"id":"r1","boardXSize":9,"boardYSize":9,"moves":[]}`))
+	if len(results) != 1 || !strings.Contains(string(mustMarshal(t, results[0])), `"id":"r1"`) ||
+		!strings.Contains(string(mustMarshal(t, results[0])), `"winrate":0.123`) {
+		t.Errorf("Expected the recorded response under the new ID, got %s", mustMarshal(t, results))
+	}
+	if misses.Len() != 0 {
+		t.Errorf("Expected no misses, got %q", misses.String())
+	}
+
+	// Queries missing from the recording get mock analysis
+	results = e.handle([]byte(`{"id":"r2","boardXSize":9,"boardYSize":9,"moves":[["B","E5"]]}`))
+	if len(results) != 1 || !strings.Contains(misses.String(), "query r2 is not in the recording") {
+		t.Errorf("Expected mock analysis and a miss, got %d results and %q", len(results), misses.String())
+	}
+}
@@ -0,0 +1,125 @@
+// Command katago-mock is a stand-in for KataGo that speaks the analysis
+// engine's JSON protocol and answers every query with deterministic,
+// plausible-looking results. It needs no model or GPU, so tests, CI and
+// demo mode can run the whole server without a real KataGo install.
+//
+// Set KATAGO_MOCK_VERSION to emulate an older KataGo release; responses
+// then leave out the fields that release does not report. Set
+// KATAGO_MOCK_STARTUP_DELAY to a duration such as "30s" to emulate a slow
+// model load, during which queries wait unread like they do with KataGo.
+//
+// With -replay, or KATAGO_MOCK_REPLAY when the mock is started by the
+// server, queries found in a recording made by the server's engine flight
+// recorder are answered with the responses KataGo gave, so a bug report can
+// be reproduced without the reporter's KataGo or GPU. Other queries get the
+// usual mock analysis.
+//
+// Usage:
+//
+//	katago-mock version
+//	katago-mock analysis [-config FILE] [-model FILE] [-human-model FILE] [-replay FILE]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+const (
+	// versionEnv selects the KataGo release the mock emulates.
+	versionEnv = "KATAGO_MOCK_VERSION"
+	// replayEnv names a recording to replay when -replay is not given.
+	replayEnv = "KATAGO_MOCK_REPLAY"
+	// startupDelayEnv delays reading queries, like loading a model does.
+	startupDelayEnv = "KATAGO_MOCK_STARTUP_DELAY"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	version := mockVersion
+	if v := os.Getenv(versionEnv); v != "" {
+		version = v
+	}
+	engine, err := newEngine(version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "katago-mock: invalid %s: %v\n", versionEnv, err)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "version":
+		fmt.Printf("KataGo v%s\n", version)
+		fmt.Println("Git revision: mock")
+		fmt.Println("Using Mock backend")
+	case "analysis":
+		// KataGo's flags are accepted so the mock can be dropped in as
+		// the configured binary, but their files are never read
+		flags := flag.NewFlagSet("analysis", flag.ExitOnError)
+		configPath := flags.String("config", "", "Analysis config file (ignored)")
+		modelPath := flags.String("model", "", "Neural net model file (ignored)")
+		flags.String("human-model", "", "Human SL model file (ignored)")
+		flags.String("override-config", "", "Config overrides (ignored)")
+		replayPath := flags.String("replay", os.Getenv(replayEnv), "Recording whose responses answer the queries it contains")
+		_ = flags.Parse(os.Args[2:])
+
+		if *replayPath != "" {
+			replayer, err := loadReplayer(*replayPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "katago-mock: %v\n", err)
+				os.Exit(1)
+			}
+			engine.replayer = replayer
+			engine.misses = os.Stderr
+			fmt.Fprintf(os.Stderr, "Replaying %s\n", *replayPath)
+		}
+
+		if *configPath != "" {
+			fmt.Fprintf(os.Stderr, "Loaded config %s\n", *configPath)
+		}
+		if *modelPath != "" {
+			fmt.Fprintf(os.Stderr, "Loaded model %s\n", *modelPath)
+		}
+		if v := os.Getenv(startupDelayEnv); v != "" {
+			delay, err := time.ParseDuration(v)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "katago-mock: invalid %s: %v\n", startupDelayEnv, err)
+				os.Exit(2)
+			}
+			time.Sleep(delay)
+		}
+		fmt.Fprintln(os.Stderr, "Started, ready to begin handling requests")
+		if err := engine.serve(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "katago-mock: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: katago-mock version | analysis [-config FILE] [-model FILE] [-replay FILE]")
+}
+
+// loadReplayer reads a recording to replay.
+func loadReplayer(path string) (*katago.Replayer, error) {
+	file, err := os.Open(path) // #nosec G304 -- path is a command-line argument
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+	lines, err := katago.ReadRecording(file)
+	if err != nil {
+		return nil, err
+	}
+	return katago.NewReplayer(lines)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultMix is the tool mix used without -mix, weighted toward the
+// single-position analyses clients make most.
+const defaultMix = "analyzePosition=6,evaluateTerritory=2,estimateScoreDistribution=1,findMistakes=1"
+
+// maxErrorMessage is the length errors are cut to before being grouped.
+const maxErrorMessage = 120
+
+// toolArgs describes how the tools a mix can contain are called: whether
+// they take an SGF, a move number and a visit budget.
+var toolArgs = map[string]struct{ sgf, moveNumber, visits bool }{
+	"analyzePosition":           {sgf: true, moveNumber: true, visits: true},
+	"evaluateTerritory":         {sgf: true},
+	"estimateScoreDistribution": {sgf: true, moveNumber: true, visits: true},
+	"findMistakes":              {sgf: true, visits: true},
+	"keyMoments":                {sgf: true, visits: true},
+	"validateSGF":               {sgf: true},
+	"getEngineStatus":           {},
+}
+
+// weightedTool is a tool of the mix and its share of the calls.
+type weightedTool struct {
+	name   string
+	weight int
+}
+
+// game is an SGF positions are drawn from.
+type game struct {
+	sgf   string
+	moves int
+}
+
+// options controls a load test.
+type options struct {
+	concurrency int
+	duration    time.Duration
+	requests    int // 0 = until duration
+	tools       []weightedTool
+	games       []game
+	visits      int
+	clients     int
+	seed        int64
+}
+
+// caller makes tool calls, as an MCP client does.
+type caller interface {
+	CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+}
+
+// parseMix parses a mix such as "analyzePosition=3,findMistakes=1". A tool
+// without a weight has weight 1.
+func parseMix(mix string) ([]weightedTool, error) {
+	var tools []weightedTool
+	for _, part := range strings.Split(mix, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weightText, hasWeight := strings.Cut(part, "=")
+		weight := 1
+		if hasWeight {
+			var err error
+			if weight, err = strconv.Atoi(weightText); err != nil || weight < 0 {
+				return nil, fmt.Errorf("invalid weight in mix: %q", part)
+			}
+		}
+		if _, ok := toolArgs[name]; !ok {
+			supported := make([]string, 0, len(toolArgs))
+			for tool := range toolArgs {
+				supported = append(supported, tool)
+			}
+			sort.Strings(supported)
+			return nil, fmt.Errorf("unsupported tool %q in mix, expected one of %s", name, strings.Join(supported, ", "))
+		}
+		if weight > 0 {
+			tools = append(tools, weightedTool{name: name, weight: weight})
+		}
+	}
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("the mix has no tools")
+	}
+	return tools, nil
+}
+
+// loadGames reads the SGF files in a comma-separated list of files and
+// directories, or returns the built-in games for an empty list.
+func loadGames(paths string) ([]game, error) {
+	var sgfs []string
+	if paths == "" {
+		sgfs = cannedSGFs
+	}
+	for _, path := range strings.Split(paths, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		files := []string{path}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			if files, err = filepath.Glob(filepath.Join(path, "*.sgf")); err != nil {
+				return nil, err
+			}
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file) // #nosec G304 -- path is a command-line argument
+			if err != nil {
+				return nil, fmt.Errorf("failed to read SGF: %w", err)
+			}
+			sgfs = append(sgfs, string(data))
+		}
+	}
+
+	games := make([]game, 0, len(sgfs))
+	for i, sgf := range sgfs {
+		position, err := katago.NewSGFParser(sgf).Parse()
+		if err != nil {
+			return nil, fmt.Errorf("invalid SGF %d: %w", i+1, err)
+		}
+		games = append(games, game{sgf: sgf, moves: len(position.Moves)})
+	}
+	if len(games) == 0 {
+		return nil, fmt.Errorf("no SGF files found in %s", paths)
+	}
+	return games, nil
+}
+
+// run issues tool calls with the given concurrency until the duration has
+// passed, the requests are made or ctx is done, and reports on them. Calls
+// in flight when it stops are waited for.
+func run(ctx context.Context, c caller, opts *options) *Report {
+	totalWeight := 0
+	for _, tool := range opts.tools {
+		totalWeight += tool.weight
+	}
+	deadline := time.Now().Add(opts.duration)
+	var issued atomic.Int64
+	collector := newCollector()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for worker := 0; worker < opts.concurrency; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(opts.seed + int64(worker))) // #nosec G404 -- load is not security sensitive
+			clientID := fmt.Sprintf("loadtest-%d", worker%opts.clients)
+			for ctx.Err() == nil && time.Now().Before(deadline) {
+				if opts.requests > 0 && issued.Add(1) > int64(opts.requests) {
+					return
+				}
+				tool := pickTool(rng, opts.tools, totalWeight)
+				request := buildRequest(rng, tool, opts.games[rng.Intn(len(opts.games))], opts.visits, clientID)
+
+				callStart := time.Now()
+				result, err := c.CallTool(context.WithoutCancel(ctx), request)
+				collector.record(tool, time.Since(callStart), result, err)
+			}
+		}(worker)
+	}
+	wg.Wait()
+	return collector.report(time.Since(start), opts.concurrency)
+}
+
+// pickTool draws a tool from the mix by weight.
+func pickTool(rng *rand.Rand, tools []weightedTool, totalWeight int) string {
+	n := rng.Intn(totalWeight)
+	for _, tool := range tools {
+		if n < tool.weight {
+			return tool.name
+		}
+		n -= tool.weight
+	}
+	return tools[len(tools)-1].name
+}
+
+// buildRequest builds a call of a tool on a random position of a game.
+func buildRequest(rng *rand.Rand, tool string, g game, visits int, clientID string) mcp.CallToolRequest {
+	args := map[string]interface{}{"clientID": clientID}
+	spec := toolArgs[tool]
+	if spec.sgf {
+		args["sgf"] = g.sgf
+	}
+	if spec.moveNumber {
+		args["moveNumber"] = rng.Intn(g.moves + 1)
+	}
+	if spec.visits && visits > 0 {
+		args["maxVisits"] = visits
+	}
+	request := mcp.CallToolRequest{}
+	request.Params.Name = tool
+	request.Params.Arguments = args
+	return request
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// fakeServer answers analyzePosition from the cache every other call and
+// rate limits findMistakes.
+type fakeServer struct {
+	mu    sync.Mutex
+	calls map[string]int
+	args  []map[string]interface{}
+}
+
+func (f *fakeServer) CallTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	time.Sleep(time.Millisecond)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[request.Params.Name]++
+	f.args = append(f.args, request.Params.Arguments.(map[string]interface{}))
+	switch request.Params.Name {
+	case "findMistakes":
+		return nil, errors.New("[RATE_LIMITED] rate limit exceeded for tool findMistakes")
+	case "evaluateTerritory":
+		return mcp.NewToolResultError("failed to parse SGF"), nil
+	}
+	hits := float64(f.calls["analyzePosition"] % 2)
+	result := mcp.NewToolResultText("ok")
+	result.Meta = map[string]any{"resources": map[string]interface{}{"cacheHits": hits, "cacheMisses": 1 - hits}}
+	return result, nil
+}
+
+func TestParseMix(t *testing.T) {
+	tools, err := parseMix("analyzePosition=3, findMistakes ,validateSGF=0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(tools) != 2 || tools[0] != (weightedTool{"analyzePosition", 3}) || tools[1] != (weightedTool{"findMistakes", 1}) {
+		t.Errorf("Unexpected tools: %+v", tools)
+	}
+	for _, mix := range []string{"playMove=1", "analyzePosition=x", "validateSGF=0", ""} {
+		if _, err := parseMix(mix); err == nil {
+			t.Errorf("Expected %q to be rejected", mix)
+		}
+	}
+	if _, err := parseMix(defaultMix); err != nil {
+		t.Errorf("Expected the default mix to parse, got %v", err)
+	}
+}
+
+func TestCannedSGFsAreLegal(t *testing.T) {
+	games, err := loadGames("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, g := range games {
+		position, _ := katago.NewSGFParser(g.sgf).Parse()
+		if _, err := katago.BoardFromPosition(position); err != nil {
+			t.Errorf("Canned game %d has an illegal move: %v", i+1, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	games, err := loadGames("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeServer{calls: map[string]int{}}
+	report := run(context.Background(), server, &options{
+		concurrency: 3,
+		duration:    time.Minute,
+		requests:    40,
+		tools:       []weightedTool{{"analyzePosition", 2}, {"findMistakes", 1}, {"evaluateTerritory", 1}},
+		games:       games,
+		visits:      25,
+		clients:     2,
+		seed:        7,
+	})
+
+	if report.Calls != 40 || report.Overall.Calls != 40 {
+		t.Fatalf("Expected 40 calls, got %+v", report)
+	}
+	analyzed := server.calls["analyzePosition"]
+	if report.Errors != 40-analyzed {
+		t.Errorf("Expected every call but analyzePosition to fail, got %d errors of %d", report.Errors, report.Calls)
+	}
+	if report.CacheHits+report.CacheMisses != analyzed || report.HitRate <= 0 || report.HitRate >= 1 {
+		t.Errorf("Expected a lookup per analysis, got %d hits and %d misses", report.CacheHits, report.CacheMisses)
+	}
+	if len(report.TopErrors) != 2 || !strings.Contains(report.TopErrors[0].Message+report.TopErrors[1].Message, "RATE_LIMITED") {
+		t.Errorf("Expected the rate limit and SGF errors, got %+v", report.TopErrors)
+	}
+	if report.Overall.P50Ms > report.Overall.P99Ms || report.Overall.P99Ms > report.Overall.MaxMs {
+		t.Errorf("Expected ordered percentiles, got %+v", report.Overall)
+	}
+
+	clients := map[interface{}]bool{}
+	for _, args := range server.args {
+		clients[args["clientID"]] = true
+		if visits, ok := args["maxVisits"]; (ok && visits != 25) || args["sgf"] == nil {
+			t.Errorf("Unexpected arguments: %v", args)
+		}
+	}
+	if len(clients) != 2 {
+		t.Errorf("Expected calls from 2 clients, got %v", clients)
+	}
+	if text := report.String(); !strings.Contains(text, "40 calls in") || !strings.Contains(text, "hit rate") {
+		t.Errorf("Unexpected report:\n%s", text)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	if p := percentile(latencies, 50); p != 50 {
+		t.Errorf("Expected p50 of 50ms, got %v", p)
+	}
+	if p := percentile(latencies, 99); p != 99 {
+		t.Errorf("Expected p99 of 99ms, got %v", p)
+	}
+	if p := percentile(latencies[:1], 90); p != 1 {
+		t.Errorf("Expected the only latency, got %v", p)
+	}
+}
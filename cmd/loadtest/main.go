@@ -0,0 +1,134 @@
+// Command loadtest drives a katago-mcp server with concurrent tool calls
+// and reports throughput, latency percentiles, cache hit rates and errors,
+// so that claims about the server's behavior under load, such as its rate
+// limiting, can be checked the same way by everyone.
+//
+// The server is started over stdio, or reached over streamable HTTP with
+// -url. Tool calls are drawn from a weighted mix and use canned SGFs, or
+// the games in -sgf, at random move numbers.
+//
+// Usage:
+//
+//	loadtest [-server CMD] [-server-args ARGS] [-url URL] [-concurrency N]
+//	         [-duration D] [-requests N] [-mix TOOL=WEIGHT,...] [-sgf PATHS]
+//	         [-visits N] [-clients N] [-seed N] [-json]
+//
+// For example, against the mock engine:
+//
+//	loadtest -server ./katago-mcp -server-args=--demo -concurrency 8 -duration 1m
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// version is reported to the server as the client's version.
+const version = "1.0.0"
+
+func main() {
+	serverCmd := flag.String("server", "katago-mcp", "Command starting the server over stdio")
+	serverArgs := flag.String("server-args", "", "Arguments of the server command, separated by spaces")
+	url := flag.String("url", "", "MCP endpoint of a server reached over streamable HTTP instead of -server")
+	concurrency := flag.Int("concurrency", 4, "Tool calls in flight at once")
+	duration := flag.Duration("duration", 30*time.Second, "How long to issue calls for")
+	requests := flag.Int("requests", 0, "Calls to issue before stopping (0 = until -duration)")
+	mix := flag.String("mix", defaultMix, "Tools to call and their weights")
+	sgfPaths := flag.String("sgf", "", "SGF files or directories to draw positions from, separated by commas (default: built-in games)")
+	visits := flag.Int("visits", 50, "maxVisits of the calls that search (0 = the server's default)")
+	clients := flag.Int("clients", 1, "Client IDs the calls are spread over, for per-client rate limits when the server trusts the clientID argument")
+	seed := flag.Int64("seed", 1, "Seed of the random choice of tools and positions")
+	asJSON := flag.Bool("json", false, "Print the report as JSON")
+	flag.Parse()
+
+	tools, err := parseMix(*mix)
+	if err != nil {
+		fail(err)
+	}
+	games, err := loadGames(*sgfPaths)
+	if err != nil {
+		fail(err)
+	}
+	if *concurrency <= 0 || *clients <= 0 {
+		fail(fmt.Errorf("-concurrency and -clients must be positive"))
+	}
+
+	// Stop issuing calls on an interrupt, reporting what was measured
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c, err := connect(ctx, *serverCmd, strings.Fields(*serverArgs), *url)
+	if err != nil {
+		fail(err)
+	}
+	defer c.Close()
+
+	report := run(ctx, c, &options{
+		concurrency: *concurrency,
+		duration:    *duration,
+		requests:    *requests,
+		tools:       tools,
+		games:       games,
+		visits:      *visits,
+		clients:     *clients,
+		seed:        *seed,
+	})
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fail(err)
+		}
+		return
+	}
+	fmt.Print(report.String())
+}
+
+// connect starts the server over stdio, or connects to it over HTTP, and
+// initializes the MCP session.
+func connect(ctx context.Context, command string, args []string, url string) (*client.Client, error) {
+	var c *client.Client
+	var err error
+	if url != "" {
+		if c, err = client.NewStreamableHttpClient(url); err != nil {
+			return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+		}
+		if err := c.Start(ctx); err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", url, err)
+		}
+	} else {
+		if c, err = client.NewStdioMCPClient(command, os.Environ(), args...); err != nil {
+			return nil, fmt.Errorf("failed to start %s: %w", command, err)
+		}
+		// The server logs to stderr, which must be drained for it not to
+		// block
+		if stderr, ok := client.GetStderr(c); ok {
+			go func() { _, _ = io.Copy(io.Discard, stderr) }()
+		}
+	}
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcp.Implementation{Name: "katago-mcp-loadtest", Version: version}
+	if _, err := c.Initialize(ctx, request); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("failed to initialize MCP session: %w", err)
+	}
+	return c, nil
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "loadtest: %v\n", err)
+	os.Exit(2)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxReportedErrors is the number of distinct errors a report lists.
+const maxReportedErrors = 10
+
+// Report sums up a load test.
+type Report struct {
+	Concurrency int          `json:"concurrency"`
+	ElapsedSecs float64      `json:"elapsedSeconds"`
+	Calls       int          `json:"calls"`
+	Errors      int          `json:"errors"`
+	Throughput  float64      `json:"callsPerSecond"`
+	Overall     ToolStats    `json:"overall"`
+	Tools       []ToolStats  `json:"tools"`
+	CacheHits   int          `json:"cacheHits"`
+	CacheMisses int          `json:"cacheMisses"`
+	HitRate     float64      `json:"cacheHitRate"` // Of the lookups, 0-1
+	TopErrors   []ErrorCount `json:"topErrors,omitempty"`
+}
+
+// ToolStats are the latencies of a tool's calls, in milliseconds.
+type ToolStats struct {
+	Tool   string  `json:"tool"`
+	Calls  int     `json:"calls"`
+	Errors int     `json:"errors"`
+	P50Ms  float64 `json:"p50Ms"`
+	P90Ms  float64 `json:"p90Ms"`
+	P99Ms  float64 `json:"p99Ms"`
+	MaxMs  float64 `json:"maxMs"`
+}
+
+// ErrorCount is an error message and how many calls failed with it.
+type ErrorCount struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// collector accumulates the outcomes of calls made concurrently.
+type collector struct {
+	mu          sync.Mutex
+	latencies   map[string][]time.Duration
+	errors      map[string]int
+	messages    map[string]int
+	cacheHits   int
+	cacheMisses int
+}
+
+func newCollector() *collector {
+	return &collector{
+		latencies: map[string][]time.Duration{},
+		errors:    map[string]int{},
+		messages:  map[string]int{},
+	}
+}
+
+// record adds the outcome of a call: an error from the transport, an error
+// result or a result whose resource accounting counts cache lookups.
+func (c *collector) record(tool string, latency time.Duration, result *mcp.CallToolResult, err error) {
+	message := ""
+	switch {
+	case err != nil:
+		message = err.Error()
+	case result == nil:
+		message = "empty result"
+	case result.IsError:
+		message = "tool error"
+		for _, content := range result.Content {
+			if text, ok := content.(mcp.TextContent); ok {
+				message = text.Text
+				break
+			}
+		}
+	}
+	hits, misses := cacheLookups(result)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latencies[tool] = append(c.latencies[tool], latency)
+	if message != "" {
+		c.errors[tool]++
+		if len(message) > maxErrorMessage {
+			message = message[:maxErrorMessage] + "..."
+		}
+		c.messages[message]++
+	}
+	c.cacheHits += hits
+	c.cacheMisses += misses
+}
+
+// cacheLookups reads the cache hits and misses the server accounted to a
+// call in its result's resources metadata.
+func cacheLookups(result *mcp.CallToolResult) (hits, misses int) {
+	if result == nil {
+		return 0, 0
+	}
+	resources, ok := result.Meta["resources"].(map[string]interface{})
+	if !ok {
+		return 0, 0
+	}
+	h, _ := resources["cacheHits"].(float64)
+	m, _ := resources["cacheMisses"].(float64)
+	return int(h), int(m)
+}
+
+// report sums up the calls recorded.
+func (c *collector) report(elapsed time.Duration, concurrency int) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := &Report{
+		Concurrency: concurrency,
+		ElapsedSecs: elapsed.Seconds(),
+		CacheHits:   c.cacheHits,
+		CacheMisses: c.cacheMisses,
+		Tools:       []ToolStats{},
+	}
+	var all []time.Duration
+	for tool, latencies := range c.latencies {
+		report.Tools = append(report.Tools, toolStats(tool, latencies, c.errors[tool]))
+		report.Calls += len(latencies)
+		report.Errors += c.errors[tool]
+		all = append(all, latencies...)
+	}
+	sort.Slice(report.Tools, func(i, j int) bool {
+		return report.Tools[i].Calls > report.Tools[j].Calls ||
+			report.Tools[i].Calls == report.Tools[j].Calls && report.Tools[i].Tool < report.Tools[j].Tool
+	})
+	report.Overall = toolStats("all", all, report.Errors)
+	if elapsed > 0 {
+		report.Throughput = float64(report.Calls) / elapsed.Seconds()
+	}
+	if lookups := c.cacheHits + c.cacheMisses; lookups > 0 {
+		report.HitRate = float64(c.cacheHits) / float64(lookups)
+	}
+
+	for message, count := range c.messages {
+		report.TopErrors = append(report.TopErrors, ErrorCount{Message: message, Count: count})
+	}
+	sort.Slice(report.TopErrors, func(i, j int) bool {
+		a, b := report.TopErrors[i], report.TopErrors[j]
+		return a.Count > b.Count || a.Count == b.Count && a.Message < b.Message
+	})
+	if len(report.TopErrors) > maxReportedErrors {
+		report.TopErrors = report.TopErrors[:maxReportedErrors]
+	}
+	return report
+}
+
+// toolStats computes the latency percentiles of a tool's calls.
+func toolStats(tool string, latencies []time.Duration, errors int) ToolStats {
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	stats := ToolStats{Tool: tool, Calls: len(sorted), Errors: errors}
+	if len(sorted) == 0 {
+		return stats
+	}
+	stats.P50Ms = percentile(sorted, 50)
+	stats.P90Ms = percentile(sorted, 90)
+	stats.P99Ms = percentile(sorted, 99)
+	stats.MaxMs = milliseconds(sorted[len(sorted)-1])
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies, in
+// milliseconds.
+func percentile(sorted []time.Duration, p int) float64 {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return milliseconds(sorted[rank-1])
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// String formats the report as text.
+func (r *Report) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d calls in %.1fs (%.1f calls/s) with %d in flight, %d errors\n\n",
+		r.Calls, r.ElapsedSecs, r.Throughput, r.Concurrency, r.Errors)
+	fmt.Fprintf(&sb, "%-26s %7s %7s %9s %9s %9s %9s\n", "Tool", "Calls", "Errors", "p50 ms", "p90 ms", "p99 ms", "max ms")
+	for _, stats := range append(r.Tools, r.Overall) {
+		fmt.Fprintf(&sb, "%-26s %7d %7d %9.1f %9.1f %9.1f %9.1f\n",
+			stats.Tool, stats.Calls, stats.Errors, stats.P50Ms, stats.P90Ms, stats.P99Ms, stats.MaxMs)
+	}
+	fmt.Fprintf(&sb, "\nCache: %d hits, %d misses (%.1f%% hit rate)\n", r.CacheHits, r.CacheMisses, r.HitRate*100)
+	if len(r.TopErrors) > 0 {
+		sb.WriteString("\nErrors:\n")
+		for _, e := range r.TopErrors {
+			fmt.Fprintf(&sb, "%7d  %s\n", e.Count, e.Message)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,18 @@
+package main
+
+// cannedSGFs are the games positions are drawn from without -sgf: an
+// opening, a middle game and a small-board endgame.
+var cannedSGFs = []string{
+	`(;GM[1]FF[4]SZ[19]KM[6.5]RU[Chinese]
+;B[pd];W[dp];B[pp];W[dd];B[fc];W[cf];B[jd];W[qj];B[qh];W[qm]
+;B[nq];W[pk];B[oh];W[jp];B[cc];W[cd];B[dc];W[ed];B[eb];W[gd])`,
+	`(;GM[1]FF[4]SZ[19]KM[6.5]RU[Japanese]
+;B[qd];W[dd];B[pq];W[dp];B[oc];W[qo];B[qp];W[po];B[np];W[qj]
+;B[fq];W[eq];B[fp];W[dn];B[jp];W[cj];B[qf];W[nd];B[nc];W[md]
+;B[pe];W[lc];B[mc];W[ld];B[fc];W[cf];B[id];W[nj];B[kj];W[lh]
+;B[jh];W[lk];B[ll];W[ml];B[lm];W[mm];B[ln];W[mn];B[mo];W[kl])`,
+	`(;GM[1]FF[4]SZ[9]KM[7]RU[Chinese]
+;B[ee];W[ec];B[ce];W[gc];B[eg];W[gg];B[cc];W[dc];B[cd];W[cb]
+;B[bb];W[db];B[ba];W[ca];B[ac];W[gd];B[ge];W[he];B[gf];W[hf]
+;B[fe];W[hh];B[fg];W[gh];B[fh];W[fi];B[ei];W[gi];B[dh])`,
+}
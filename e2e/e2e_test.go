@@ -548,7 +548,7 @@ func TestExplainMoveE2E(t *testing.T) {
 
 	for _, move := range moves {
 		t.Run("explain_"+move, func(t *testing.T) {
-			explanation, err := engine.ExplainMove(ctx, position, move)
+			explanation, err := engine.ExplainMove(ctx, position, move, nil)
 			if err != nil {
 				// Some moves might not be in KataGo's analysis
 				t.Logf("Could not explain %s: %v", move, err)
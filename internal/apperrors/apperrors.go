@@ -0,0 +1,141 @@
+// Package apperrors defines machine-readable error codes for tool errors.
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Code identifies a class of error reported to MCP clients.
+type Code string
+
+const (
+	// CodeInvalidArgument indicates missing or malformed tool arguments.
+	CodeInvalidArgument Code = "INVALID_ARGUMENT"
+	// CodeInvalidSGF indicates SGF content that could not be parsed.
+	CodeInvalidSGF Code = "INVALID_SGF"
+	// CodeBadCoordinate indicates a move or stone location that is not valid GTP.
+	CodeBadCoordinate Code = "BAD_COORDINATE"
+	// CodeEngineUnavailable indicates the KataGo engine is not running or not responding.
+	CodeEngineUnavailable Code = "ENGINE_UNAVAILABLE"
+	// CodeTimeout indicates an analysis exceeded its time limit.
+	CodeTimeout Code = "TIMEOUT"
+	// CodeRateLimited indicates the client exceeded its request rate.
+	CodeRateLimited Code = "RATE_LIMITED"
+	// CodeQuotaExceeded indicates the client used up its visit quota for the
+	// day or month.
+	CodeQuotaExceeded Code = "QUOTA_EXCEEDED"
+	// CodePermissionDenied indicates a tool reserved for admin clients was
+	// called by another client.
+	CodePermissionDenied Code = "PERMISSION_DENIED"
+	// CodeCanceled indicates the request was canceled by the client.
+	CodeCanceled Code = "CANCELED"
+	// CodeInternal indicates an unexpected server-side error.
+	CodeInternal Code = "INTERNAL"
+)
+
+// Retryable reports whether a request failing with this code may succeed
+// if retried later.
+func (c Code) Retryable() bool {
+	switch c {
+	case CodeEngineUnavailable, CodeTimeout, CodeRateLimited:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTransient reports whether err is a temporary engine failure, such as
+// an engine restart or query timeout, that an immediate retry may fix.
+// Cancellation and expiry of the caller's own context are not transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	switch CodeOf(err) {
+	case CodeEngineUnavailable, CodeTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// Error is an error with a machine-readable code.
+type Error struct {
+	Code    Code
+	Message string
+	Err     error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New creates a coded error with a formatted message.
+func New(code Code, format string, args ...interface{}) error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap creates a coded error with a formatted message and an underlying cause.
+func Wrap(code Code, err error, format string, args ...interface{}) error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// CodeOf returns the code of the outermost coded error in err's chain.
+// Uncoded context errors map to CodeTimeout and CodeCanceled; anything
+// else is CodeInternal. It returns an empty code for a nil error.
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+
+	var coded *Error
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return CodeTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return CodeCanceled
+	}
+	return CodeInternal
+}
+
+// clientError prefixes an error's message with its code.
+type clientError struct {
+	code Code
+	err  error
+}
+
+func (e *clientError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.code, e.err.Error())
+}
+
+func (e *clientError) Unwrap() error {
+	return e.err
+}
+
+// ForClient returns err with its code prepended to the message, as in
+// "[ENGINE_UNAVAILABLE] engine not running", so clients can read the code
+// from the MCP error payload. The original error remains in the chain.
+func ForClient(err error) error {
+	if err == nil {
+		return nil
+	}
+	var existing *clientError
+	if errors.As(err, &existing) {
+		return err
+	}
+	return &clientError{code: CodeOf(err), err: err}
+}
@@ -0,0 +1,101 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"nil", nil, ""},
+		{"coded", New(CodeInvalidSGF, "bad sgf"), CodeInvalidSGF},
+		{"wrapped coded", fmt.Errorf("analysis failed: %w", New(CodeEngineUnavailable, "engine not running")), CodeEngineUnavailable},
+		{"outermost code wins", Wrap(CodeInvalidArgument, New(CodeBadCoordinate, "bad move"), "invalid position"), CodeInvalidArgument},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), CodeTimeout},
+		{"canceled", context.Canceled, CodeCanceled},
+		{"plain", errors.New("boom"), CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	cause := errors.New("unexpected token")
+	err := Wrap(CodeInvalidSGF, cause, "failed to parse SGF")
+
+	if err.Error() != "failed to parse SGF: unexpected token" {
+		t.Errorf("Unexpected message: %q", err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected cause in error chain")
+	}
+}
+
+func TestForClient(t *testing.T) {
+	if ForClient(nil) != nil {
+		t.Error("Expected nil for nil error")
+	}
+
+	cause := New(CodeTimeout, "query timeout after 20.0 seconds")
+	err := ForClient(fmt.Errorf("analysis failed: %w", cause))
+
+	want := "[TIMEOUT] analysis failed: query timeout after 20.0 seconds"
+	if err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected original error in chain")
+	}
+	if ForClient(err).Error() != want {
+		t.Error("Expected ForClient to be idempotent")
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	for _, code := range []Code{CodeEngineUnavailable, CodeTimeout, CodeRateLimited} {
+		if !code.Retryable() {
+			t.Errorf("Expected %s to be retryable", code)
+		}
+	}
+	for _, code := range []Code{CodeInvalidArgument, CodeInvalidSGF, CodeBadCoordinate, CodeInternal, CodeCanceled, CodeQuotaExceeded, CodePermissionDenied} {
+		if code.Retryable() {
+			t.Errorf("Expected %s not to be retryable", code)
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"engine unavailable", New(CodeEngineUnavailable, "engine not running"), true},
+		{"engine timeout", fmt.Errorf("analysis failed: %w", New(CodeTimeout, "query timeout")), true},
+		{"rate limited", New(CodeRateLimited, "slow down"), false},
+		{"invalid sgf", New(CodeInvalidSGF, "bad sgf"), false},
+		{"caller deadline", context.DeadlineExceeded, false},
+		{"caller canceled", Wrap(CodeEngineUnavailable, context.Canceled, "engine restarting"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
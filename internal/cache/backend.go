@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Backend is a shared store behind the in-memory cache, such as Redis,
+// that lets several server replicas reuse each other's analysis results.
+type Backend interface {
+	// Name identifies the backend in status output, e.g. "redis"
+	Name() string
+
+	// Get returns the stored value, or false if the key is not present
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set stores a value; a zero ttl keeps it until it is evicted
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Clear removes every entry stored by this server whose key starts
+	// with prefix; an empty prefix removes them all
+	Clear(ctx context.Context, prefix string) error
+
+	// Ping checks that the backend is reachable
+	Ping(ctx context.Context) error
+
+	// Close releases the backend's connections
+	Close() error
+}
+
+// Codec converts cached values to and from the bytes stored in a Backend.
+type Codec struct {
+	Encode func(value interface{}) ([]byte, error)
+	Decode func(data []byte) (interface{}, error)
+}
+
+// backendEntry is the stored form of a result and its visit count.
+type backendEntry struct {
+	Visits int             `json:"visits"`
+	Value  json.RawMessage `json:"value"`
+}
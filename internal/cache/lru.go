@@ -149,6 +149,22 @@ func (c *LRU) Delete(key string) bool {
 	return false
 }
 
+// DeleteFunc removes the keys for which match returns true and returns how
+// many were removed.
+func (c *LRU) DeleteFunc(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, elem := range c.items {
+		if match(key) {
+			c.removeElement(elem)
+			removed++
+		}
+	}
+	return removed
+}
+
 // Clear removes all entries from the cache.
 func (c *LRU) Clear() {
 	c.mu.Lock()
@@ -190,6 +206,10 @@ type Stats struct {
 	Misses    int64
 	Evictions int64
 	HitRate   float64
+	// Bytes of the entries read from and written to the shared backend,
+	// before compression and as transferred
+	BackendRawBytes    int64
+	BackendStoredBytes int64
 }
 
 // Stats returns current cache statistics.
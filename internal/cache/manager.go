@@ -1,22 +1,55 @@
 package cache
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dmmcquay/katago-mcp/internal/config"
 	"github.com/dmmcquay/katago-mcp/internal/logging"
 )
 
+// Thresholds at which the cache is considered to be thrashing.
+const (
+	thrashMinLookups = 100
+	thrashMaxHitRate = 0.1
+)
+
 // Manager handles caching of KataGo analysis results.
 type Manager struct {
-	cache   *LRU
-	logger  logging.ContextLogger
-	enabled bool
-	ttl     time.Duration
+	cache        *LRU
+	logger       logging.ContextLogger
+	enabled      bool
+	ttl          time.Duration
+	asyncRefresh bool
+
+	// Guards against very large results, such as ownership after every
+	// candidate move
+	maxEntryBytes      int64
+	compressAboveBytes int64
+	oversized          atomic.Int64
+	compressed         atomic.Int64
+
+	// Optional shared store consulted on local misses
+	backend       Backend
+	codec         Codec
+	backendGzip   bool
+	backendHits   atomic.Int64
+	backendMisses atomic.Int64
+	backendErrors atomic.Int64
+	// Bytes of the entries read from and written to the backend, before
+	// compression and as transferred
+	backendRawBytes    atomic.Int64
+	backendStoredBytes atomic.Int64
 }
 
 // NewManager creates a new cache manager.
@@ -31,11 +64,36 @@ func NewManager(cfg *config.CacheConfig, logger logging.ContextLogger) *Manager
 	cache := NewLRU(cfg.MaxItems, cfg.MaxSizeBytes)
 
 	return &Manager{
-		cache:   cache,
-		logger:  logger,
-		enabled: cfg.Enabled,
-		ttl:     time.Duration(cfg.TTLSeconds) * time.Second,
+		cache:              cache,
+		logger:             logger,
+		enabled:            cfg.Enabled,
+		ttl:                time.Duration(cfg.TTLSeconds) * time.Second,
+		asyncRefresh:       cfg.AsyncRefresh,
+		maxEntryBytes:      cfg.MaxEntryBytes,
+		compressAboveBytes: cfg.CompressAboveBytes,
+		backendGzip:        cfg.Redis.Compression == "gzip",
+	}
+}
+
+// SetCodec sets how cached values are serialized, which compressing large
+// entries requires. SetBackend sets it too.
+func (m *Manager) SetCodec(codec Codec) {
+	m.codec = codec
+}
+
+// SetBackend adds a shared store behind the in-memory cache. Results stored
+// with PutForVisits are written through to the backend, gzipped when the
+// cache's Redis compression is "gzip", and local misses in Lookup are
+// filled from it. Entries are read whether or not they are gzipped, which
+// each entry's first bytes tell. Backend failures are logged and treated as
+// misses, so analysis keeps working when the backend is down. It is a no-op
+// when caching is disabled.
+func (m *Manager) SetBackend(backend Backend, codec Codec) {
+	if !m.enabled {
+		return
 	}
+	m.backend = backend
+	m.codec = codec
 }
 
 // CacheKey generates a cache key for an analysis query.
@@ -43,13 +101,14 @@ func (m *Manager) CacheKey(query map[string]interface{}) (string, error) {
 	// Extract relevant fields for cache key
 	// We only cache based on position and analysis parameters
 	keyData := map[string]interface{}{
-		"rules":         query["rules"],
-		"boardXSize":    query["boardXSize"],
-		"boardYSize":    query["boardYSize"],
-		"moves":         query["moves"],
-		"initialStones": query["initialStones"],
-		"maxVisits":     query["maxVisits"],
-		"analyzeTurns":  query["analyzeTurns"],
+		"rules":            query["rules"],
+		"boardXSize":       query["boardXSize"],
+		"boardYSize":       query["boardYSize"],
+		"moves":            query["moves"],
+		"initialStones":    query["initialStones"],
+		"maxVisits":        query["maxVisits"],
+		"analyzeTurns":     query["analyzeTurns"],
+		"overrideSettings": query["overrideSettings"],
 	}
 
 	// Convert to JSON for consistent ordering
@@ -63,6 +122,261 @@ func (m *Manager) CacheKey(query map[string]interface{}) (string, error) {
 	return hex.EncodeToString(hash[:]), nil
 }
 
+// PositionKey generates a cache key for an analysis query that ignores the
+// visit budget, so results of different depths for the same position share
+// a single entry. Use with GetForVisits/PutForVisits.
+func (m *Manager) PositionKey(query map[string]interface{}) (string, error) {
+	keyData := map[string]interface{}{
+		"rules":                 query["rules"],
+		"komi":                  query["komi"],
+		"boardXSize":            query["boardXSize"],
+		"boardYSize":            query["boardYSize"],
+		"moves":                 query["moves"],
+		"initialStones":         query["initialStones"],
+		"initialPlayer":         query["initialPlayer"],
+		"analyzeTurns":          query["analyzeTurns"],
+		"includePolicy":         query["includePolicy"],
+		"includeOwnership":      query["includeOwnership"],
+		"includeMovesOwnership": query["includeMovesOwnership"],
+		"includePVVisits":       query["includePVVisits"],
+		"avoidMoves":            query["avoidMoves"],
+		"allowMoves":            query["allowMoves"],
+		"overrideSettings":      query["overrideSettings"],
+	}
+	// Added only when set, so keys of existing entries stay valid
+	if query["includeOwnershipStdev"] == true {
+		keyData["includeOwnershipStdev"] = true
+	}
+
+	data, err := json.Marshal(keyData)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal position key: %w", err)
+	}
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// Lookup retrieves a cached result stored with PutForVisits along with the
+// number of visits it was computed with.
+func (m *Manager) Lookup(key string) (value interface{}, visits int, ok bool) {
+	val, ok := m.Get(key)
+	if !ok {
+		return m.lookupBackend(key)
+	}
+
+	if entry, ok := val.(*visitEntry); ok {
+		if c, ok := entry.value.(*compressedEntry); ok {
+			value, err := m.decompress(c)
+			if err != nil {
+				m.logger.Warn("Failed to decompress cache entry", "key", key, "error", err)
+				m.cache.Delete(key)
+				return nil, 0, false
+			}
+			return value, entry.visits, true
+		}
+		return entry.value, entry.visits, true
+	}
+
+	// Entries stored without visit information cannot satisfy any depth
+	return val, 0, true
+}
+
+// GetForVisits retrieves a cached result only if it was computed with at
+// least the requested number of visits.
+func (m *Manager) GetForVisits(key string, visits int) (interface{}, bool) {
+	value, cachedVisits, ok := m.Lookup(key)
+	if !ok || cachedVisits < visits {
+		return nil, false
+	}
+	return value, true
+}
+
+// PutForVisits stores a result computed with the given number of visits.
+// An existing entry with more visits is kept, so the cache always holds the
+// deepest known analysis of a position. A value larger than the configured
+// maximum entry size is not stored. Returns whether the value was stored.
+func (m *Manager) PutForVisits(key string, value interface{}, visits int, size int64) bool {
+	if !m.enabled || m.cache == nil {
+		return false
+	}
+
+	if _, cachedVisits, ok := m.Lookup(key); ok && cachedVisits > visits {
+		m.logger.Debug("Keeping deeper cached analysis", "key", key,
+			"cachedVisits", cachedVisits, "visits", visits)
+		return false
+	}
+
+	if !m.store(key, value, visits, size) {
+		return false
+	}
+	m.storeBackend(key, value, visits)
+	return true
+}
+
+// store keeps a result in memory, compressed if it is large enough, and
+// reports whether it fit within the maximum entry size.
+func (m *Manager) store(key string, value interface{}, visits int, size int64) bool {
+	stored := value
+	if m.compressAboveBytes > 0 && size > m.compressAboveBytes && m.codec.Encode != nil {
+		c, err := m.compress(value)
+		if err != nil {
+			m.logger.Warn("Failed to compress cache entry", "key", key, "error", err)
+		} else {
+			m.compressed.Add(1)
+			stored, size = c, c.size()
+		}
+	}
+	if m.maxEntryBytes > 0 && size > m.maxEntryBytes {
+		m.oversized.Add(1)
+		m.logger.Debug("Not caching oversized analysis result", "key", key,
+			"size", size, "maxEntryBytes", m.maxEntryBytes)
+		return false
+	}
+
+	m.Put(key, &visitEntry{value: stored, visits: visits}, size)
+	return true
+}
+
+// compress serializes a value with the codec and gzips it.
+func (m *Manager) compress(value interface{}) (*compressedEntry, error) {
+	data, err := m.codec.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	zipped, err := gzipBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return &compressedEntry{data: zipped}, nil
+}
+
+// decompress restores a value stored by compress.
+func (m *Manager) decompress(c *compressedEntry) (interface{}, error) {
+	data, err := gunzipBytes(c.data)
+	if err != nil {
+		return nil, err
+	}
+	return m.codec.Decode(data)
+}
+
+// gzipBytes compresses data with gzip, favoring speed.
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gunzipBytes restores data compressed by gzipBytes.
+func gunzipBytes(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// isGzipped reports whether data starts with the gzip magic number, which
+// a JSON backend entry never does.
+func isGzipped(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// lookupBackend fetches an entry missing from memory from the backend and
+// keeps a local copy.
+func (m *Manager) lookupBackend(key string) (value interface{}, visits int, ok bool) {
+	if m.backend == nil {
+		return nil, 0, false
+	}
+
+	data, found, err := m.backend.Get(context.Background(), key)
+	if err != nil {
+		m.backendErrors.Add(1)
+		m.logger.Warn("Cache backend lookup failed", "backend", m.backend.Name(), "key", key, "error", err)
+		return nil, 0, false
+	}
+	if !found {
+		m.backendMisses.Add(1)
+		return nil, 0, false
+	}
+	m.backendStoredBytes.Add(int64(len(data)))
+	if isGzipped(data) {
+		if data, err = gunzipBytes(data); err != nil {
+			m.backendErrors.Add(1)
+			m.logger.Warn("Failed to decompress cache backend entry", "key", key, "error", err)
+			return nil, 0, false
+		}
+	}
+	m.backendRawBytes.Add(int64(len(data)))
+
+	var entry backendEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		m.backendErrors.Add(1)
+		m.logger.Warn("Failed to decode cache backend entry", "key", key, "error", err)
+		return nil, 0, false
+	}
+	value, err = m.codec.Decode(entry.Value)
+	if err != nil {
+		m.backendErrors.Add(1)
+		m.logger.Warn("Failed to decode cache backend entry", "key", key, "error", err)
+		return nil, 0, false
+	}
+	m.backendHits.Add(1)
+
+	m.store(key, value, entry.Visits, EstimateSize(value))
+	return value, entry.Visits, true
+}
+
+// storeBackend writes a result through to the backend.
+func (m *Manager) storeBackend(key string, value interface{}, visits int) {
+	if m.backend == nil {
+		return
+	}
+
+	encoded, err := m.codec.Encode(value)
+	if err != nil {
+		m.backendErrors.Add(1)
+		m.logger.Warn("Failed to encode cache backend entry", "key", key, "error", err)
+		return
+	}
+	data, err := json.Marshal(backendEntry{Visits: visits, Value: encoded})
+	if err != nil {
+		m.backendErrors.Add(1)
+		m.logger.Warn("Failed to encode cache backend entry", "key", key, "error", err)
+		return
+	}
+	raw := len(data)
+	if m.backendGzip {
+		if data, err = gzipBytes(data); err != nil {
+			m.backendErrors.Add(1)
+			m.logger.Warn("Failed to compress cache backend entry", "key", key, "error", err)
+			return
+		}
+	}
+	m.backendRawBytes.Add(int64(raw))
+	m.backendStoredBytes.Add(int64(len(data)))
+	if err := m.backend.Set(context.Background(), key, data, m.ttl); err != nil {
+		m.backendErrors.Add(1)
+		m.logger.Warn("Cache backend store failed", "backend", m.backend.Name(), "key", key, "error", err)
+	}
+}
+
+// AsyncRefreshEnabled returns whether shallower results may be served while
+// a deeper analysis is computed in the background.
+func (m *Manager) AsyncRefreshEnabled() bool {
+	return m.enabled && m.asyncRefresh
+}
+
 // Get retrieves a cached analysis result.
 func (m *Manager) Get(key string) (interface{}, bool) {
 	if !m.enabled || m.cache == nil {
@@ -119,14 +433,153 @@ func (m *Manager) Stats() Stats {
 	if !m.enabled || m.cache == nil {
 		return Stats{}
 	}
-	return m.cache.Stats()
+	stats := m.cache.Stats()
+	stats.BackendRawBytes = m.backendRawBytes.Load()
+	stats.BackendStoredBytes = m.backendStoredBytes.Load()
+	return stats
+}
+
+// GetStatus returns the current cache status for monitoring.
+func (m *Manager) GetStatus() map[string]interface{} {
+	if !m.enabled || m.cache == nil {
+		return map[string]interface{}{
+			"enabled": false,
+		}
+	}
+
+	stats := m.cache.Stats()
+	status := map[string]interface{}{
+		"enabled":    true,
+		"items":      stats.Items,
+		"sizeBytes":  stats.Size,
+		"hits":       stats.Hits,
+		"misses":     stats.Misses,
+		"evictions":  stats.Evictions,
+		"hitRate":    stats.HitRate,
+		"oversized":  m.oversized.Load(),
+		"compressed": m.compressed.Load(),
+	}
+	if m.backend != nil {
+		compression := "none"
+		if m.backendGzip {
+			compression = "gzip"
+		}
+		status["backend"] = map[string]interface{}{
+			"name":        m.backend.Name(),
+			"hits":        m.backendHits.Load(),
+			"misses":      m.backendMisses.Load(),
+			"errors":      m.backendErrors.Load(),
+			"compression": compression,
+			"rawBytes":    m.backendRawBytes.Load(),
+			"storedBytes": m.backendStoredBytes.Load(),
+		}
+	}
+	return status
+}
+
+// Check reports an error when the shared backend is unreachable, or when
+// the cache is thrashing: it is evicting entries while almost no lookups
+// hit, so it costs memory without saving engine work. A disabled cache is
+// always healthy.
+func (m *Manager) Check(ctx context.Context) error {
+	if !m.enabled || m.cache == nil {
+		return nil
+	}
+	if m.backend != nil {
+		if err := m.backend.Ping(ctx); err != nil {
+			return fmt.Errorf("%s cache backend unreachable: %w", m.backend.Name(), err)
+		}
+	}
+	stats := m.cache.Stats()
+	if stats.Evictions > 0 && stats.Hits+stats.Misses >= thrashMinLookups && stats.HitRate < thrashMaxHitRate {
+		return fmt.Errorf("cache is thrashing: %.1f%% hit rate with %d evictions", stats.HitRate*100, stats.Evictions)
+	}
+	return nil
 }
 
-// Clear clears the cache.
-func (m *Manager) Clear() {
+// StartStatsReporter periodically passes cache statistics to report until
+// the returned stop function is called. It is a no-op when caching is disabled.
+func (m *Manager) StartStatsReporter(interval time.Duration, report func(Stats)) (stop func()) {
+	if !m.enabled || m.cache == nil || interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		report(m.Stats())
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				report(m.Stats())
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// Clear clears the cache, including entries this server stored in the
+// shared backend.
+func (m *Manager) Clear() error {
 	if m.cache != nil {
 		m.cache.Clear()
 	}
+	if m.backend != nil {
+		if err := m.backend.Clear(context.Background(), ""); err != nil {
+			return fmt.Errorf("failed to clear %s cache backend: %w", m.backend.Name(), err)
+		}
+	}
+	return nil
+}
+
+// ClearPrefix removes the entries whose keys start with prefix, in memory
+// and in the shared backend, and returns how many were removed from memory.
+// It clears one namespace's results without touching anyone else's.
+func (m *Manager) ClearPrefix(prefix string) (int, error) {
+	removed := 0
+	if m.cache != nil {
+		removed = m.cache.DeleteFunc(func(key string) bool {
+			return strings.HasPrefix(key, prefix)
+		})
+	}
+	if m.backend != nil {
+		if err := m.backend.Clear(context.Background(), prefix); err != nil {
+			return removed, fmt.Errorf("failed to clear %s cache backend: %w", m.backend.Name(), err)
+		}
+	}
+	return removed, nil
+}
+
+// Invalidate removes the in-memory entries whose keys match and returns how
+// many were removed. Entries in the shared backend are left alone, since
+// other replicas may still use them; callers that key entries by what they
+// depend on never look them up again, and they expire with the TTL.
+func (m *Manager) Invalidate(match func(key string) bool) int {
+	if !m.enabled || m.cache == nil {
+		return 0
+	}
+	removed := m.cache.DeleteFunc(match)
+	if removed > 0 {
+		m.logger.Debug("Invalidated cache entries", "entries", removed)
+	}
+	return removed
+}
+
+// Close releases the backend's connections.
+func (m *Manager) Close() error {
+	if m.backend == nil {
+		return nil
+	}
+	return m.backend.Close()
 }
 
 // IsEnabled returns whether caching is enabled.
@@ -140,8 +593,36 @@ type timedEntry struct {
 	timestamp time.Time
 }
 
-// EstimateSize estimates the size of an analysis response in bytes.
+// visitEntry wraps a value with the number of visits used to compute it.
+type visitEntry struct {
+	value  interface{}
+	visits int
+}
+
+// compressedEntry is a value serialized with the codec and gzipped.
+type compressedEntry struct {
+	data []byte
+}
+
+// size is the memory the entry takes, including its slice header.
+func (c *compressedEntry) size() int64 {
+	return int64(len(c.data)) + 24
+}
+
+// Sizer is implemented by values that can tell how much memory they take,
+// which is usually far more than their JSON encoding for values holding
+// decoded JSON.
+type Sizer interface {
+	SizeBytes() int64
+}
+
+// EstimateSize estimates the size of an analysis response in bytes: its own
+// SizeBytes if it is a Sizer, and otherwise the length of its JSON encoding.
 func EstimateSize(response interface{}) int64 {
+	if s, ok := response.(Sizer); ok {
+		return s.SizeBytes()
+	}
+
 	// Simple estimation based on JSON encoding
 	data, err := json.Marshal(response)
 	if err != nil {
@@ -1,6 +1,10 @@
 package cache
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -185,6 +189,17 @@ func TestManager_Clear(t *testing.T) {
 	stats = manager.Stats()
 	assert.Equal(t, 0, stats.Items)
 	assert.Equal(t, int64(0), stats.Size)
+
+	// Clearing a prefix leaves other keys alone
+	manager.Put("ns/a:key1", "value1", 50)
+	manager.Put("ns/a:key2", "value2", 50)
+	manager.Put("ns/b:key1", "value3", 50)
+	removed, err := manager.ClearPrefix("ns/a:")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	_, ok := manager.Get("ns/b:key1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, manager.Stats().Items)
 }
 
 func TestEstimateSize(t *testing.T) {
@@ -227,6 +242,54 @@ func TestEstimateSize(t *testing.T) {
 			assert.Greater(t, size, tc.minSize)
 		})
 	}
+
+	// Values that know their size are not encoded
+	assert.Equal(t, int64(12345), EstimateSize(sizedValue(12345)))
+}
+
+type sizedValue int64
+
+func (v sizedValue) SizeBytes() int64 { return int64(v) }
+
+func TestManager_EntryLimits(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	codec := Codec{
+		Encode: func(value interface{}) ([]byte, error) { return json.Marshal(value) },
+		Decode: func(data []byte) (interface{}, error) {
+			var s string
+			err := json.Unmarshal(data, &s)
+			return s, err
+		},
+	}
+	manager := NewManager(&config.CacheConfig{
+		Enabled:            true,
+		MaxItems:           10,
+		MaxEntryBytes:      1000,
+		CompressAboveBytes: 500,
+	}, logger)
+	manager.SetCodec(codec)
+
+	// Small results are stored as they are
+	assert.True(t, manager.PutForVisits("small", "value", 100, 100))
+	assert.Equal(t, int64(100), manager.Stats().Size)
+
+	// Large ones are gzipped, which brings this one under the limit
+	large := strings.Repeat("ownership ", 500)
+	assert.True(t, manager.PutForVisits("large", large, 100, int64(len(large))))
+	value, visits, ok := manager.Lookup("large")
+	require.True(t, ok)
+	assert.Equal(t, large, value)
+	assert.Equal(t, 100, visits)
+	assert.Less(t, manager.Stats().Size, int64(200+100))
+
+	assert.Equal(t, int64(1), manager.GetStatus()["compressed"])
+
+	// Without compression, results over the limit are not cached
+	manager = NewManager(&config.CacheConfig{Enabled: true, MaxItems: 10, MaxEntryBytes: 1000}, logger)
+	assert.False(t, manager.PutForVisits("large", large, 100, int64(len(large))))
+	_, _, ok = manager.Lookup("large")
+	assert.False(t, ok)
+	assert.Equal(t, int64(1), manager.GetStatus()["oversized"])
 }
 
 // TestManager_Integration tests the manager with realistic KataGo responses
@@ -319,3 +382,163 @@ func TestManager_Integration(t *testing.T) {
 	_, ok = manager.Get(differentKey)
 	assert.False(t, ok)
 }
+
+func TestManager_GetStatus(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+
+	// Disabled cache reports only its state
+	disabled := NewManager(&config.CacheConfig{Enabled: false}, logger)
+	assert.Equal(t, map[string]interface{}{"enabled": false}, disabled.GetStatus())
+
+	manager := NewManager(&config.CacheConfig{
+		Enabled:      true,
+		MaxItems:     10,
+		MaxSizeBytes: 1024,
+	}, logger)
+	manager.Put("key1", "value1", 100)
+	_, _ = manager.Get("key1")
+	_, _ = manager.Get("missing")
+
+	status := manager.GetStatus()
+	assert.Equal(t, true, status["enabled"])
+	assert.Equal(t, 1, status["items"])
+	assert.Equal(t, int64(100), status["sizeBytes"])
+	assert.Equal(t, int64(1), status["hits"])
+	assert.Equal(t, int64(1), status["misses"])
+	assert.InDelta(t, 0.5, status["hitRate"], 0.001)
+}
+
+func TestManager_StartStatsReporter(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	manager := NewManager(&config.CacheConfig{
+		Enabled:  true,
+		MaxItems: 10,
+	}, logger)
+	manager.Put("key1", "value1", 100)
+
+	reports := make(chan Stats, 10)
+	stop := manager.StartStatsReporter(10*time.Millisecond, func(stats Stats) {
+		select {
+		case reports <- stats:
+		default:
+		}
+	})
+	defer stop()
+
+	select {
+	case stats := <-reports:
+		assert.Equal(t, 1, stats.Items)
+		assert.Equal(t, int64(100), stats.Size)
+	case <-time.After(time.Second):
+		t.Fatal("Expected stats to be reported")
+	}
+
+	// Stopping twice must be safe
+	stop()
+	stop()
+}
+
+func TestManager_PositionKeyIgnoresVisits(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	manager := NewManager(&config.CacheConfig{Enabled: true, MaxItems: 10}, logger)
+
+	query := map[string]interface{}{
+		"rules":      "chinese",
+		"boardXSize": 19,
+		"boardYSize": 19,
+		"moves":      [][]interface{}{{"b", "D4"}},
+		"maxVisits":  50,
+	}
+	quickKey, err := manager.PositionKey(query)
+	require.NoError(t, err)
+
+	query["maxVisits"] = 5000
+	deepKey, err := manager.PositionKey(query)
+	require.NoError(t, err)
+	assert.Equal(t, quickKey, deepKey)
+
+	// Requesting ownership changes the response shape and must change the key
+	query["includeOwnership"] = true
+	ownershipKey, err := manager.PositionKey(query)
+	require.NoError(t, err)
+	assert.NotEqual(t, quickKey, ownershipKey)
+}
+
+func TestManager_VisitTiers(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	manager := NewManager(&config.CacheConfig{Enabled: true, MaxItems: 10, TTLSeconds: 60}, logger)
+
+	// A quick result only satisfies requests up to its depth
+	assert.True(t, manager.PutForVisits("pos", "quick", 50, 10))
+	val, ok := manager.GetForVisits("pos", 50)
+	assert.True(t, ok)
+	assert.Equal(t, "quick", val)
+	_, ok = manager.GetForVisits("pos", 5000)
+	assert.False(t, ok)
+
+	// A deeper result replaces it and serves shallower requests too
+	assert.True(t, manager.PutForVisits("pos", "deep", 5000, 10))
+	val, ok = manager.GetForVisits("pos", 50)
+	assert.True(t, ok)
+	assert.Equal(t, "deep", val)
+
+	// A later shallow result must not overwrite the deep one
+	assert.False(t, manager.PutForVisits("pos", "quick again", 50, 10))
+	val, visits, ok := manager.Lookup("pos")
+	assert.True(t, ok)
+	assert.Equal(t, "deep", val)
+	assert.Equal(t, 5000, visits)
+}
+
+func TestManager_Check(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	ctx := context.Background()
+
+	disabled := NewManager(&config.CacheConfig{Enabled: false}, logger)
+	assert.NoError(t, disabled.Check(ctx))
+
+	manager := NewManager(&config.CacheConfig{
+		Enabled:      true,
+		MaxItems:     2,
+		MaxSizeBytes: 1024,
+	}, logger)
+	assert.NoError(t, manager.Check(ctx))
+
+	// Every lookup misses an entry that has already been evicted
+	for i := 0; i < thrashMinLookups; i++ {
+		key := fmt.Sprintf("key%d", i)
+		manager.Put(key, "value", 10)
+		if i >= 2 {
+			_, _ = manager.Get(fmt.Sprintf("key%d", i-2))
+		}
+	}
+	assert.NoError(t, manager.Check(ctx), "too few lookups to judge")
+	_, _ = manager.Get("key0")
+	_, _ = manager.Get("key1")
+	assert.ErrorContains(t, manager.Check(ctx), "thrashing")
+
+	// Enough hits make the cache worthwhile again
+	for i := 0; i < 20; i++ {
+		_, _ = manager.Get(fmt.Sprintf("key%d", thrashMinLookups-1))
+	}
+	assert.NoError(t, manager.Check(ctx))
+}
+
+func TestManager_Invalidate(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	manager := NewManager(&config.CacheConfig{Enabled: true, MaxItems: 10}, logger)
+
+	manager.PutForVisits("old:a", "a", 100, 10)
+	manager.PutForVisits("old:b", "b", 100, 10)
+	manager.PutForVisits("new:a", "a", 100, 10)
+
+	removed := manager.Invalidate(func(key string) bool { return strings.HasPrefix(key, "old:") })
+	assert.Equal(t, 2, removed)
+	_, _, ok := manager.Lookup("old:a")
+	assert.False(t, ok)
+	_, _, ok = manager.Lookup("new:a")
+	assert.True(t, ok)
+
+	disabled := NewManager(&config.CacheConfig{Enabled: false}, logger)
+	assert.Equal(t, 0, disabled.Invalidate(func(string) bool { return true }))
+}
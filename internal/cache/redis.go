@@ -0,0 +1,305 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+)
+
+const (
+	defaultRedisKeyPrefix = "katago-mcp:"
+	defaultRedisTimeout   = 200 * time.Millisecond
+	defaultRedisPoolSize  = 8
+	// redisScanCount is the number of keys requested per SCAN when clearing.
+	redisScanCount = 500
+)
+
+// RedisBackend stores cache entries in Redis so that replicas share
+// analysis results and the cache survives restarts. It speaks the RESP
+// protocol directly and keeps a small pool of idle connections.
+type RedisBackend struct {
+	addr     string
+	password string
+	db       int
+	prefix   string
+	timeout  time.Duration
+	idle     chan *redisConn
+}
+
+// redisConn is a connection to Redis with a buffered reader.
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// redisError is an error reply from Redis.
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// NewRedisBackend creates a Redis backend. Connections are opened lazily,
+// so an unreachable server is reported by Ping rather than here.
+func NewRedisBackend(cfg *config.RedisConfig) *RedisBackend {
+	b := &RedisBackend{
+		addr:     cfg.Addr,
+		password: cfg.Password,
+		db:       cfg.DB,
+		prefix:   cfg.KeyPrefix,
+		timeout:  time.Duration(cfg.TimeoutMs) * time.Millisecond,
+	}
+	if b.prefix == "" {
+		b.prefix = defaultRedisKeyPrefix
+	}
+	if b.timeout <= 0 {
+		b.timeout = defaultRedisTimeout
+	}
+	poolSize := cfg.PoolSize
+	if poolSize <= 0 {
+		poolSize = defaultRedisPoolSize
+	}
+	b.idle = make(chan *redisConn, poolSize)
+	return b
+}
+
+// Name implements Backend.
+func (b *RedisBackend) Name() string {
+	return "redis"
+}
+
+// Get implements Backend.
+func (b *RedisBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := b.do(ctx, "GET", b.prefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	data, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return data, true, nil
+}
+
+// Set implements Backend.
+func (b *RedisBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", b.prefix + key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	_, err := b.do(ctx, args...)
+	return err
+}
+
+// Clear implements Backend. Only keys under this backend's prefix are
+// deleted, so other applications sharing the Redis database are unaffected.
+func (b *RedisBackend) Clear(ctx context.Context, prefix string) error {
+	pattern := escapeRedisPattern(b.prefix+prefix) + "*"
+	cursor := "0"
+	for {
+		reply, err := b.do(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", strconv.Itoa(redisScanCount))
+		if err != nil {
+			return err
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 2 {
+			return fmt.Errorf("redis: unexpected SCAN reply %T", reply)
+		}
+		next, _ := parts[0].([]byte)
+		keys, _ := parts[1].([]interface{})
+		if len(keys) > 0 {
+			args := make([]string, 0, len(keys)+1)
+			args = append(args, "DEL")
+			for _, key := range keys {
+				if k, ok := key.([]byte); ok {
+					args = append(args, string(k))
+				}
+			}
+			if _, err := b.do(ctx, args...); err != nil {
+				return err
+			}
+		}
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return nil
+		}
+	}
+}
+
+// Ping implements Backend.
+func (b *RedisBackend) Ping(ctx context.Context) error {
+	_, err := b.do(ctx, "PING")
+	return err
+}
+
+// Close implements Backend.
+func (b *RedisBackend) Close() error {
+	for {
+		select {
+		case c := <-b.idle:
+			_ = c.conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do sends a command and reads its reply. Connections that fail are
+// discarded; a Redis error reply leaves the connection usable.
+func (b *RedisBackend) do(ctx context.Context, args ...string) (interface{}, error) {
+	c, err := b.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := c.roundTrip(b.deadline(ctx), args)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		_ = c.conn.Close()
+		return nil, err
+	}
+	b.release(c)
+	return reply, err
+}
+
+// conn returns an idle connection or dials a new one.
+func (b *RedisBackend) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-b.idle:
+		return c, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: b.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", b.addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: failed to connect to %s: %w", b.addr, err)
+	}
+	c := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	deadline := b.deadline(ctx)
+	if b.password != "" {
+		if _, err := c.roundTrip(deadline, []string{"AUTH", b.password}); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("redis: authentication failed: %w", err)
+		}
+	}
+	if b.db != 0 {
+		if _, err := c.roundTrip(deadline, []string{"SELECT", strconv.Itoa(b.db)}); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("redis: failed to select database %d: %w", b.db, err)
+		}
+	}
+	return c, nil
+}
+
+// release returns a connection to the idle pool, closing it if the pool
+// is full.
+func (b *RedisBackend) release(c *redisConn) {
+	select {
+	case b.idle <- c:
+	default:
+		_ = c.conn.Close()
+	}
+}
+
+// deadline returns the earlier of the context deadline and the backend
+// timeout.
+func (b *RedisBackend) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(b.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		return d
+	}
+	return deadline
+}
+
+// roundTrip writes a command as a RESP array of bulk strings and reads
+// the reply.
+func (c *redisConn) roundTrip(deadline time.Time, args []string) (interface{}, error) {
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&sb, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, sb.String()); err != nil {
+		return nil, err
+	}
+	return readRedisReply(c.reader)
+}
+
+// readRedisReply reads one RESP reply. Bulk strings are returned as
+// []byte, integers as int64, arrays as []interface{} and nil replies as nil.
+func readRedisReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readRedisReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
+
+// escapeRedisPattern escapes glob characters so a key prefix matches
+// literally in SCAN patterns.
+func escapeRedisPattern(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
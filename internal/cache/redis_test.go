@@ -0,0 +1,311 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis is an in-memory server speaking enough RESP for RedisBackend.
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu   sync.Mutex
+	data map[string]string
+	ttls map[string]string
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f := &fakeRedis{
+		listener: listener,
+		password: password,
+		data:     make(map[string]string),
+		ttls:     make(map[string]string),
+	}
+	go f.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+	return f
+}
+
+func (f *fakeRedis) addr() string {
+	return f.listener.Addr().String()
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authed := f.password == ""
+	for {
+		reply, err := readRedisReply(reader)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			b, _ := item.([]byte)
+			args[i] = string(b)
+		}
+		if len(args) == 0 {
+			return
+		}
+
+		cmd := strings.ToUpper(args[0])
+		if !authed && cmd != "AUTH" {
+			fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+			continue
+		}
+		f.mu.Lock()
+		switch cmd {
+		case "AUTH":
+			if args[1] == f.password {
+				authed = true
+				fmt.Fprint(conn, "+OK\r\n")
+			} else {
+				fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+			}
+		case "PING":
+			fmt.Fprint(conn, "+PONG\r\n")
+		case "GET":
+			if value, ok := f.data[args[1]]; ok {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(value), value)
+			} else {
+				fmt.Fprint(conn, "$-1\r\n")
+			}
+		case "SET":
+			f.data[args[1]] = args[2]
+			if len(args) == 5 {
+				f.ttls[args[1]] = args[4]
+			}
+			fmt.Fprint(conn, "+OK\r\n")
+		case "DEL":
+			for _, key := range args[1:] {
+				delete(f.data, key)
+			}
+			fmt.Fprintf(conn, ":%d\r\n", len(args)-1)
+		case "SCAN":
+			prefix := strings.TrimSuffix(strings.ReplaceAll(args[3], `\`, ""), "*")
+			var keys []string
+			for key := range f.data {
+				if strings.HasPrefix(key, prefix) {
+					keys = append(keys, key)
+				}
+			}
+			fmt.Fprintf(conn, "*2\r\n$1\r\n0\r\n*%d\r\n", len(keys))
+			for _, key := range keys {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(key), key)
+			}
+		default:
+			fmt.Fprintf(conn, "-ERR unknown command '%s'\r\n", cmd)
+		}
+		f.mu.Unlock()
+	}
+}
+
+func (f *fakeRedis) set(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data[key] = value
+}
+
+func (f *fakeRedis) ttl(key string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.ttls[key]
+}
+
+func (f *fakeRedis) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.data))
+	for key := range f.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestRedisBackend(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	backend := NewRedisBackend(&config.RedisConfig{Addr: server.addr(), Password: "secret", KeyPrefix: "test:"})
+	defer backend.Close()
+	ctx := context.Background()
+
+	require.NoError(t, backend.Ping(ctx))
+
+	_, found, err := backend.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	value := []byte("line one\r\nline two")
+	require.NoError(t, backend.Set(ctx, "key", value, 90*time.Second))
+	got, found, err := backend.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, value, got)
+	assert.Equal(t, "90000", server.ttl("test:key"))
+
+	// Keys outside the prefix survive a clear
+	server.set("other:key", "kept")
+	require.NoError(t, backend.Set(ctx, "ns/a:key", value, 0))
+	require.NoError(t, backend.Clear(ctx, "ns/a:"))
+	_, found, err = backend.Get(ctx, "ns/a:key")
+	require.NoError(t, err)
+	assert.False(t, found, "entries under the cleared prefix are removed")
+	_, found, err = backend.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, found, "entries outside the cleared prefix survive")
+	require.NoError(t, backend.Clear(ctx, ""))
+	assert.Equal(t, []string{"other:key"}, server.keys())
+
+	wrongPassword := NewRedisBackend(&config.RedisConfig{Addr: server.addr(), Password: "wrong"})
+	assert.ErrorContains(t, wrongPassword.Ping(ctx), "authentication failed")
+
+	// A command error leaves the connection usable
+	_, err = backend.do(ctx, "BOGUS")
+	assert.ErrorContains(t, err, "unknown command")
+	assert.NoError(t, backend.Ping(ctx))
+}
+
+func TestRedisBackendUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	backend := NewRedisBackend(&config.RedisConfig{Addr: addr, TimeoutMs: 50})
+	assert.Error(t, backend.Ping(context.Background()))
+}
+
+func TestManager_Backend(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	server := newFakeRedis(t, "")
+	codec := Codec{
+		Encode: func(value interface{}) ([]byte, error) {
+			return []byte(fmt.Sprintf("%q", value)), nil
+		},
+		Decode: func(data []byte) (interface{}, error) {
+			var s string
+			_, err := fmt.Sscanf(string(data), "%q", &s)
+			return s, err
+		},
+	}
+	cfg := &config.CacheConfig{Enabled: true, MaxItems: 10, TTLSeconds: 60}
+
+	// Two replicas sharing one Redis server
+	replica1 := NewManager(cfg, logger)
+	replica1.SetBackend(NewRedisBackend(&config.RedisConfig{Addr: server.addr()}), codec)
+	replica2 := NewManager(cfg, logger)
+	replica2.SetBackend(NewRedisBackend(&config.RedisConfig{Addr: server.addr()}), codec)
+	defer replica1.Close()
+	defer replica2.Close()
+
+	assert.True(t, replica1.PutForVisits("position", "analysis", 200, 100))
+	assert.Equal(t, "60000", server.ttl(defaultRedisKeyPrefix+"position"))
+
+	value, visits, ok := replica2.Lookup("position")
+	require.True(t, ok)
+	assert.Equal(t, "analysis", value)
+	assert.Equal(t, 200, visits)
+	assert.Equal(t, 1, replica2.Stats().Items, "shared result kept locally")
+
+	// A deeper result on one replica is not overwritten by a shallower one
+	assert.True(t, replica2.PutForVisits("deep", "deeper analysis", 1000, 100))
+	assert.False(t, replica1.PutForVisits("deep", "shallow analysis", 100, 100))
+
+	backendStatus := replica2.GetStatus()["backend"].(map[string]interface{})
+	assert.Equal(t, "redis", backendStatus["name"])
+	assert.Equal(t, int64(1), backendStatus["hits"])
+	assert.NoError(t, replica2.Check(context.Background()))
+
+	// Clearing one replica clears the shared entries
+	require.NoError(t, replica1.Clear())
+	assert.Empty(t, server.keys())
+
+	// An unreachable backend degrades to local caching
+	_ = server.listener.Close()
+	require.NoError(t, replica1.Close())
+	assert.True(t, replica1.PutForVisits("local", "analysis", 100, 100))
+	_, _, ok = replica1.Lookup("local")
+	assert.True(t, ok)
+	assert.Error(t, replica1.Check(context.Background()))
+	assert.Positive(t, replica1.GetStatus()["backend"].(map[string]interface{})["errors"])
+}
+
+func TestManager_BackendCompression(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	server := newFakeRedis(t, "")
+	codec := Codec{
+		Encode: func(value interface{}) ([]byte, error) {
+			return json.Marshal(value)
+		},
+		Decode: func(data []byte) (interface{}, error) {
+			var s string
+			err := json.Unmarshal(data, &s)
+			return s, err
+		},
+	}
+	redisCfg := config.RedisConfig{Addr: server.addr(), Compression: "gzip"}
+
+	// A replica writing gzipped entries and one writing plain ones read
+	// each other's
+	gzipped := NewManager(&config.CacheConfig{Enabled: true, MaxItems: 10, Redis: redisCfg}, logger)
+	gzipped.SetBackend(NewRedisBackend(&redisCfg), codec)
+	plain := NewManager(&config.CacheConfig{Enabled: true, MaxItems: 10}, logger)
+	plain.SetBackend(NewRedisBackend(&config.RedisConfig{Addr: server.addr()}), codec)
+	defer gzipped.Close()
+	defer plain.Close()
+
+	ownership := strings.Repeat("0.95,", 400)
+	require.True(t, gzipped.PutForVisits("zipped", ownership, 100, 100))
+	require.True(t, plain.PutForVisits("plain", "analysis", 100, 100))
+
+	value, _, ok := plain.Lookup("zipped")
+	require.True(t, ok)
+	assert.Equal(t, ownership, value)
+	value, _, ok = gzipped.Lookup("plain")
+	require.True(t, ok)
+	assert.Equal(t, "analysis", value)
+
+	status := gzipped.GetStatus()["backend"].(map[string]interface{})
+	assert.Equal(t, "gzip", status["compression"])
+	raw, stored := status["rawBytes"].(int64), status["storedBytes"].(int64)
+	assert.Less(t, stored*4, raw, "ownership compresses well")
+	stats := gzipped.Stats()
+	assert.Equal(t, raw, stats.BackendRawBytes)
+	assert.Equal(t, stored, stats.BackendStoredBytes)
+	assert.Equal(t, "none", plain.GetStatus()["backend"].(map[string]interface{})["compression"])
+}
+
+func TestManager_BackendDisabled(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	manager := NewManager(&config.CacheConfig{Enabled: false}, logger)
+	manager.SetBackend(NewRedisBackend(&config.RedisConfig{Addr: "127.0.0.1:1"}), Codec{})
+	assert.False(t, manager.PutForVisits("key", "value", 100, 10))
+	assert.NoError(t, manager.Check(context.Background()))
+	assert.NoError(t, manager.Clear())
+}
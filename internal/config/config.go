@@ -3,9 +3,14 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
 )
 
 type Config struct {
@@ -21,8 +26,71 @@ type Config struct {
 	// Rate limiting configuration
 	RateLimit RateLimitConfig `json:"rateLimit"`
 
+	// Per-client visit quotas
+	Quota QuotaConfig `json:"quota"`
+
+	// Tenants of a shared server and how far they are kept apart
+	Namespaces NamespaceConfig `json:"namespaces"`
+
+	// Signing of analysis results
+	Provenance ProvenanceConfig `json:"provenance"`
+
 	// Cache configuration
 	Cache CacheConfig `json:"cache"`
+
+	// Metrics endpoint configuration
+	Metrics MetricsConfig `json:"metrics"`
+
+	// Tracing configuration
+	Tracing TracingConfig `json:"tracing"`
+
+	// Engine resource monitoring configuration
+	Monitor MonitorConfig `json:"monitor"`
+
+	// Per-tool deadlines
+	Timeouts TimeoutConfig `json:"timeouts"`
+
+	// Additional named engines, e.g. a small fast network for interactive tools
+	Engines []EngineConfig `json:"engines"`
+
+	// Tool name to engine name; unlisted tools use the default engine
+	EngineRouting map[string]string `json:"engineRouting"`
+
+	// Tool name to scheduling lane; unlisted tools are interactive
+	ToolLanes map[string]string `json:"toolLanes"`
+
+	// Tool name to the search it runs when a request sets none
+	Tools map[string]ToolConfig `json:"tools"`
+
+	// Analysis output formatting
+	Output OutputConfig `json:"output"`
+
+	// Interactive study sessions
+	Sessions SessionConfig `json:"sessions"`
+
+	// Load limits above which the readiness probe reports unready
+	Readiness ReadinessConfig `json:"readiness"`
+
+	// Directory of SGF files to review automatically
+	Watch WatchConfig `json:"watch"`
+
+	// Whole-game review settings
+	Review ReviewConfig `json:"review"`
+
+	// URLs notified when background reviews finish or fail
+	Webhooks []WebhookConfig `json:"webhooks"`
+
+	// Background analysis of common openings while the engine is idle
+	Prewarm PrewarmConfig `json:"prewarm"`
+
+	// GTP listener for Go GUIs
+	GTP GTPConfig `json:"gtp"`
+
+	// Slack bridge that reviews games posted in channels
+	Slack SlackConfig `json:"slack"`
+
+	// Shutting down when the MCP client goes away
+	Watchdog WatchdogConfig `json:"watchdog"`
 }
 
 type KataGoConfig struct {
@@ -32,6 +100,213 @@ type KataGoConfig struct {
 	NumThreads int     `json:"numThreads"`
 	MaxVisits  int     `json:"maxVisits"`
 	MaxTime    float64 `json:"maxTime"`
+
+	// Human SL model for human-style move suggestions
+	HumanModelPath string `json:"humanModelPath"`
+	HumanProfile   string `json:"humanProfile"` // Default humanSLProfile, e.g. "rank_5k"
+
+	// Search settings sent with every query unless a request overrides them
+	Search SearchConfig `json:"search"`
+
+	// Flight recorder of the raw queries and responses exchanged with KataGo
+	Recorder RecorderConfig `json:"recorder"`
+
+	// Log of the queries that take longer than a threshold
+	SlowQueries SlowQueryConfig `json:"slowQueries"`
+
+	// DiagnosticsDir is where a diagnostics bundle is written, in a
+	// timestamped directory, each time KataGo crashes or stops responding;
+	// empty keeps only the latest bundle in memory
+	DiagnosticsDir string `json:"diagnosticsDir"`
+
+	// DrainTimeoutSeconds bounds how long a stopping engine waits for its
+	// outstanding queries before quitting KataGo; 0 stops at once.
+	DrainTimeoutSeconds int `json:"drainTimeoutSeconds"`
+
+	// IdleSuspendMinutes stops KataGo, freeing its GPU memory, once it has
+	// had no queries for this long; the next request starts it again
+	// (0 = never).
+	IdleSuspendMinutes int `json:"idleSuspendMinutes"`
+
+	// How queries from the scheduling lanes share KataGo
+	Lanes LaneConfig `json:"lanes"`
+
+	// How the supervisor checks that KataGo still answers queries
+	HealthCheck HealthCheckConfig `json:"healthCheck"`
+}
+
+// Scheduling lanes, from most to least latency-sensitive.
+const (
+	LaneInteractive = "interactive" // A person is waiting, e.g. explainMove
+	LaneBatch       = "batch"       // Whole-game work, e.g. findMistakes
+	LaneBackground  = "background"  // Nobody is waiting, e.g. the watch directory
+)
+
+// Lanes lists the scheduling lanes in priority order.
+var Lanes = []string{LaneInteractive, LaneBatch, LaneBackground}
+
+// LaneConfig controls how an engine shares KataGo between lanes. Queries
+// beyond MaxInFlight wait in their lane, and each free slot goes to a
+// waiting lane in proportion to its weight, so a lane with weight 8 gets
+// eight queries in for each one from a lane with weight 1.
+type LaneConfig struct {
+	// MaxInFlight is the number of queries sent to KataGo at once. It
+	// should match KataGo's numAnalysisThreads, which it defaults to
+	// (0 = numAnalysisThreads from KataGo's config file, else 2).
+	MaxInFlight int            `json:"maxInFlight"`
+	Weights     map[string]int `json:"weights"` // Lane to weight (default: 1)
+}
+
+// RecorderConfig controls the engine flight recorder, which keeps the raw
+// protocol lines exchanged with KataGo so that a session can be replayed
+// with katago-mock. It is not inherited by additional engines.
+type RecorderConfig struct {
+	Enabled bool   `json:"enabled"`
+	Size    int    `json:"size"` // Lines kept in memory (default: 1000)
+	Path    string `json:"path"` // JSON Lines file every line is appended to; empty keeps them in memory only
+}
+
+// SlowQueryConfig controls the slow query log, which keeps each query to
+// KataGo slower than a threshold with its position, settings and timing.
+type SlowQueryConfig struct {
+	ThresholdSeconds float64 `json:"thresholdSeconds"` // 0 = disabled
+	Size             int     `json:"size"`             // Queries kept (default: 100)
+}
+
+// Validate checks that the threshold and size are not negative.
+func (s SlowQueryConfig) Validate() error {
+	if s.ThresholdSeconds < 0 {
+		return fmt.Errorf("thresholdSeconds must not be negative: %g", s.ThresholdSeconds)
+	}
+	if s.Size < 0 {
+		return fmt.Errorf("size must not be negative: %d", s.Size)
+	}
+	return nil
+}
+
+// Health check defaults.
+const (
+	DefaultHealthCheckTimeout  = 5 * time.Second
+	DefaultHealthCheckFailures = 3
+)
+
+// HealthCheckConfig controls the engine health check, which sends KataGo a
+// tiny analysis query and restarts it when it repeatedly fails to answer.
+type HealthCheckConfig struct {
+	TimeoutSeconds float64 `json:"timeoutSeconds"` // Time KataGo has to answer (default: 5)
+	MaxFailures    int     `json:"maxFailures"`    // Consecutive failures before a restart (default: 3)
+}
+
+// Timeout returns the time KataGo has to answer a health check query.
+func (h HealthCheckConfig) Timeout() time.Duration {
+	if h.TimeoutSeconds <= 0 {
+		return DefaultHealthCheckTimeout
+	}
+	return time.Duration(h.TimeoutSeconds * float64(time.Second))
+}
+
+// Failures returns the number of consecutive failed health checks after
+// which the engine is restarted.
+func (h HealthCheckConfig) Failures() int {
+	if h.MaxFailures <= 0 {
+		return DefaultHealthCheckFailures
+	}
+	return h.MaxFailures
+}
+
+// Validate checks that the timeout and failure count are not negative.
+func (h HealthCheckConfig) Validate() error {
+	if h.TimeoutSeconds < 0 {
+		return fmt.Errorf("timeoutSeconds must not be negative: %g", h.TimeoutSeconds)
+	}
+	if h.MaxFailures < 0 {
+		return fmt.Errorf("maxFailures must not be negative: %d", h.MaxFailures)
+	}
+	return nil
+}
+
+// Ranges accepted for search settings.
+const (
+	MaxWideRootNoise         = 1.0
+	MinRootPolicyTemperature = 0.01
+	MaxRootPolicyTemperature = 100.0
+	MaxAnalysisPVLen         = 1000
+)
+
+// SearchConfig holds KataGo search settings that trade sharpness for
+// diversity of suggested moves. Unset fields keep the values from KataGo's
+// own config file.
+type SearchConfig struct {
+	WideRootNoise         *float64 `json:"wideRootNoise,omitempty"`         // Extra exploration of unlikely root moves (0-1)
+	RootPolicyTemperature *float64 `json:"rootPolicyTemperature,omitempty"` // Above 1 flattens the root policy, below 1 sharpens it (0.01-100)
+	AnalysisPVLen         *int     `json:"analysisPVLen,omitempty"`         // Maximum moves in each principal variation (1-1000)
+}
+
+// Validate checks that the set fields are within KataGo's accepted ranges.
+func (s SearchConfig) Validate() error {
+	if s.WideRootNoise != nil && (*s.WideRootNoise < 0 || *s.WideRootNoise > MaxWideRootNoise) {
+		return fmt.Errorf("wideRootNoise must be between 0 and %g: %g", MaxWideRootNoise, *s.WideRootNoise)
+	}
+	if s.RootPolicyTemperature != nil &&
+		(*s.RootPolicyTemperature < MinRootPolicyTemperature || *s.RootPolicyTemperature > MaxRootPolicyTemperature) {
+		return fmt.Errorf("rootPolicyTemperature must be between %g and %g: %g",
+			MinRootPolicyTemperature, MaxRootPolicyTemperature, *s.RootPolicyTemperature)
+	}
+	if s.AnalysisPVLen != nil && (*s.AnalysisPVLen < 1 || *s.AnalysisPVLen > MaxAnalysisPVLen) {
+		return fmt.Errorf("analysisPVLen must be between 1 and %d: %d", MaxAnalysisPVLen, *s.AnalysisPVLen)
+	}
+	return nil
+}
+
+// Merge returns the settings with unset fields taken from base.
+func (s SearchConfig) Merge(base SearchConfig) SearchConfig {
+	if s.WideRootNoise == nil {
+		s.WideRootNoise = base.WideRootNoise
+	}
+	if s.RootPolicyTemperature == nil {
+		s.RootPolicyTemperature = base.RootPolicyTemperature
+	}
+	if s.AnalysisPVLen == nil {
+		s.AnalysisPVLen = base.AnalysisPVLen
+	}
+	return s
+}
+
+// DefaultEngineName is the name of the engine configured by the katago block.
+const DefaultEngineName = "default"
+
+// EngineConfig configures an additional named engine. Unset KataGo fields
+// are inherited from the default engine.
+type EngineConfig struct {
+	Name string `json:"name"`
+	KataGoConfig
+}
+
+// EngineConfigs returns the KataGo configuration of every engine keyed by
+// name, including the default engine.
+func (c *Config) EngineConfigs() map[string]*KataGoConfig {
+	engines := map[string]*KataGoConfig{DefaultEngineName: &c.KataGo}
+	for i := range c.Engines {
+		engines[c.Engines[i].Name] = &c.Engines[i].KataGoConfig
+	}
+	return engines
+}
+
+// MetricClients returns the client IDs labeled by name in per-client
+// metrics: those listed in the metrics settings and those the server,
+// quota and namespace settings name.
+func (c *Config) MetricClients() []string {
+	clients := append([]string(nil), c.Metrics.Clients...)
+	if c.Server.ClientID != "" {
+		clients = append(clients, c.Server.ClientID)
+	}
+	for client := range c.Quota.PerClient {
+		clients = append(clients, client)
+	}
+	for client := range c.Namespaces.Clients {
+		clients = append(clients, client)
+	}
+	return clients
 }
 
 type ServerConfig struct {
@@ -39,6 +314,14 @@ type ServerConfig struct {
 	Version     string `json:"version"`
 	Description string `json:"description"`
 	HealthAddr  string `json:"healthAddr"` // Address for health check endpoints
+	// ClientID identifies every request, e.g. a key naming the deployment,
+	// for rate limits, logs and metrics. When empty it is derived from the
+	// client's MCP session.
+	ClientID string `json:"clientId"`
+	// TrustClientIDArgument identifies each call by its clientID argument,
+	// for servers behind a gateway that sets it. Otherwise the argument is
+	// ignored, since any caller could claim another client's identity.
+	TrustClientIDArgument bool `json:"trustClientIdArgument"`
 }
 
 type LoggingConfig struct {
@@ -63,11 +346,350 @@ type RateLimitConfig struct {
 	PerToolLimits  map[string]int `json:"perToolLimits"`
 }
 
+type QuotaConfig struct {
+	Enabled   bool                   `json:"enabled"`
+	StatePath string                 `json:"statePath"` // File usage is saved to across restarts (empty = memory only)
+	PerClient map[string]QuotaLimits `json:"perClient"` // Overrides of the default limits for named clients
+	QuotaLimits
+}
+
+// QuotaLimits are the KataGo visits a client may use in a period. Periods
+// are calendar days and months in UTC.
+type QuotaLimits struct {
+	DailyVisits   int64 `json:"dailyVisits"`   // Visits per day (0 = unlimited)
+	MonthlyVisits int64 `json:"monthlyVisits"` // Visits per month (0 = unlimited)
+}
+
+// Limits returns the quota limits of a client.
+func (c *QuotaConfig) Limits(clientID string) QuotaLimits {
+	if limits, ok := c.PerClient[clientID]; ok {
+		return limits
+	}
+	return c.QuotaLimits
+}
+
+// Namespace isolation levels.
+const (
+	IsolationShared = "shared" // Namespaces share cached results
+	IsolationStrict = "strict" // Each namespace has its own cached results
+)
+
+// NamespaceConfig separates the tenants of a server shared by several
+// clients or teams. Every client belongs to a namespace, its own client ID
+// unless Clients maps it to another, such as the name of its team. Study
+// sessions always belong to a single client.
+type NamespaceConfig struct {
+	// Isolation is IsolationShared (default), where one namespace's cached
+	// results answer another's queries, or IsolationStrict, where neither
+	// cached results nor in-flight queries are shared across namespaces so
+	// no tenant can probe what another has analyzed
+	Isolation string            `json:"isolation"`
+	Clients   map[string]string `json:"clients"` // Client ID to namespace
+	// Admins are the client IDs allowed to use the admin tools, which clear
+	// every namespace's cached results or show the engine's raw queries.
+	// When none are listed and isolation is shared, every client may
+	Admins []string `json:"admins"`
+}
+
+// Namespace returns the namespace of a client.
+func (c *NamespaceConfig) Namespace(clientID string) string {
+	if namespace, ok := c.Clients[clientID]; ok {
+		return namespace
+	}
+	return clientID
+}
+
+// Admin reports whether a client may use the admin tools.
+func (c *NamespaceConfig) Admin(clientID string) bool {
+	for _, admin := range c.Admins {
+		if admin == clientID {
+			return true
+		}
+	}
+	return len(c.Admins) == 0 && !c.Strict()
+}
+
+// Strict reports whether namespaces have their own cached results.
+func (c *NamespaceConfig) Strict() bool {
+	return c.Isolation == IsolationStrict
+}
+
+// ProvenanceConfig controls the signing of tool results with the settings
+// they were analyzed with, so that a platform holding the key can check a
+// review came from this server as claimed. The key is a shared secret; set
+// it with KATAGO_MCP_PROVENANCE_KEY or keyFile rather than in a file that
+// is checked in.
+type ProvenanceConfig struct {
+	Enabled bool   `json:"enabled"`
+	Key     string `json:"key"`     // HMAC key, at least 16 bytes
+	KeyFile string `json:"keyFile"` // File holding the key, used when Key is empty
+}
+
 type CacheConfig struct {
 	Enabled      bool  `json:"enabled"`
 	MaxItems     int   `json:"maxItems"`
 	MaxSizeBytes int64 `json:"maxSizeBytes"`
 	TTLSeconds   int   `json:"ttlSeconds"`
+	// AsyncRefresh serves a shallower cached result immediately when more
+	// visits are requested, and deepens the cache entry in the background.
+	AsyncRefresh bool `json:"asyncRefresh"`
+	// MaxEntryBytes keeps results that would take more memory than this out
+	// of the cache, such as ownership after many candidate moves (0 = no
+	// limit). CompressAboveBytes stores results larger than this gzipped,
+	// trading some CPU on each hit for memory (0 = never compress).
+	MaxEntryBytes      int64 `json:"maxEntryBytes"`
+	CompressAboveBytes int64 `json:"compressAboveBytes"`
+
+	// Optional Redis server shared by all replicas
+	Redis RedisConfig `json:"redis"`
+}
+
+type RedisConfig struct {
+	Addr      string `json:"addr"`      // host:port; empty disables the shared cache
+	Password  string `json:"password"`  // Sent with AUTH when set
+	DB        int    `json:"db"`        // Database number
+	KeyPrefix string `json:"keyPrefix"` // Namespace for this server's keys (default: "katago-mcp:")
+	TimeoutMs int    `json:"timeoutMs"` // Per-command timeout (default: 200)
+	PoolSize  int    `json:"poolSize"`  // Idle connections kept open (default: 8)
+	// Compression of the entries written to Redis: "gzip", or "none" (the
+	// default). Entries are read whichever way they were written, so
+	// replicas can switch one at a time
+	Compression string `json:"compression"`
+}
+
+type MetricsConfig struct {
+	Enabled bool   `json:"enabled"`
+	Path    string `json:"path"`
+
+	// Optional basic auth protecting the metrics endpoint
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// Clients are the client IDs labeled by name in per-client metrics, in
+	// addition to those named in the server, quota and namespace settings.
+	// Any other client is labeled "other", so callers cannot grow the
+	// metrics without bound.
+	Clients []string `json:"clients"`
+}
+
+type TracingConfig struct {
+	Enabled     bool    `json:"enabled"`
+	Endpoint    string  `json:"endpoint"`    // OTLP/HTTP collector host:port
+	Insecure    bool    `json:"insecure"`    // Use plain HTTP instead of HTTPS
+	SampleRatio float64 `json:"sampleRatio"` // Fraction of traces to sample (0-1)
+}
+
+type MonitorConfig struct {
+	Enabled         bool `json:"enabled"`
+	IntervalSeconds int  `json:"intervalSeconds"` // Time between resource samples
+	GPU             bool `json:"gpu"`             // Sample GPU usage via nvidia-smi when available
+
+	// Thresholds that mark the engine degraded (0 disables a threshold)
+	MaxCPUPercent     float64 `json:"maxCpuPercent"`
+	MaxRSSBytes       int64   `json:"maxRssBytes"`
+	MaxGPUPercent     float64 `json:"maxGpuPercent"`
+	MaxGPUMemoryBytes int64   `json:"maxGpuMemoryBytes"`
+}
+
+type ReadinessConfig struct {
+	MaxPendingQueries int `json:"maxPendingQueries"` // Queries awaiting a KataGo response (0 = unlimited)
+	MaxQueuedQueries  int `json:"maxQueuedQueries"`  // Queries waiting to be sent to KataGo (0 = unlimited)
+}
+
+type WatchConfig struct {
+	Dir             string `json:"dir"`             // Directory to watch; empty disables the watcher
+	IntervalSeconds int    `json:"intervalSeconds"` // Time between directory scans
+	MaxVisits       int    `json:"maxVisits"`       // Visits per move (0 = review default)
+	// Deep re-reviews the directory's games at more visits during off-hours
+	Deep DeepReviewConfig `json:"deep"`
+}
+
+// DeepReviewConfig schedules deep re-reviews of games the watcher has
+// reviewed. During a daily window, each game is reviewed again at more
+// visits and its review is replaced, recording the moves whose verdict
+// changed. A game is deepened once, and again when it changes or maxVisits
+// is raised.
+type DeepReviewConfig struct {
+	Enabled   bool   `json:"enabled"`
+	MaxVisits int    `json:"maxVisits"` // Visits per move (default: 1000)
+	Start     string `json:"start"`     // Start of the window, local time as "HH:MM" (default: "01:00")
+	End       string `json:"end"`       // End of the window, before start to span midnight (default: "06:00")
+}
+
+// Window returns the start and end of the window as minutes after local
+// midnight.
+func (c *DeepReviewConfig) Window() (start, end int, err error) {
+	if start, err = minuteOfDay(c.Start); err != nil {
+		return 0, 0, fmt.Errorf("watch deep start: %w", err)
+	}
+	if end, err = minuteOfDay(c.End); err != nil {
+		return 0, 0, fmt.Errorf("watch deep end: %w", err)
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("watch deep window is empty: start and end are both %s", c.Start)
+	}
+	return start, end, nil
+}
+
+// minuteOfDay parses a time of day as "HH:MM".
+func minuteOfDay(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+type ReviewConfig struct {
+	// Concurrency is the number of positions of a game analyzed at once
+	// (default: 4, at least 1). Queries beyond lanes.maxInFlight wait for
+	// KataGo, so higher values only help when KataGo has the threads to
+	// search them in parallel.
+	Concurrency int `json:"concurrency"`
+	// Analyzers are plugins run on every judged move alongside the mistake
+	// detector, whose findings are added to reviews
+	Analyzers []AnalyzerConfig `json:"analyzers"`
+}
+
+// AnalyzerConfig runs a plugin analyzer as a subprocess. The process is
+// started on the first move it is asked about and kept running; it reads
+// one JSON object per line on stdin, describing a move and KataGo's
+// analysis of it, and answers each with one line of JSON on stdout:
+// {"findings": [...]}.
+type AnalyzerConfig struct {
+	Name           string   `json:"name"`           // Name findings are credited to
+	Command        string   `json:"command"`        // Executable to run
+	Args           []string `json:"args"`           // Arguments to the command
+	TimeoutSeconds int      `json:"timeoutSeconds"` // Time to answer each move before the process is restarted (default: 10)
+}
+
+// Webhook events, sent when a background review of a game ends.
+const (
+	EventReviewCompleted = "review.completed"
+	EventReviewFailed    = "review.failed"
+)
+
+// WebhookEvents lists the events webhooks can subscribe to.
+var WebhookEvents = []string{EventReviewCompleted, EventReviewFailed}
+
+// WebhookConfig is a URL notified of background reviews, so chat bots and
+// CI systems can react without polling. Each event is POSTed as JSON; with
+// a secret, the X-KataGo-Signature header holds "sha256=" and the hex
+// HMAC-SHA256 of the body.
+type WebhookConfig struct {
+	URL            string   `json:"url"`
+	Secret         string   `json:"secret"`         // HMAC key (optional)
+	Events         []string `json:"events"`         // Events to send (default: all)
+	TimeoutSeconds int      `json:"timeoutSeconds"` // Time each delivery attempt may take (default: 10)
+}
+
+// Wants reports whether the webhook subscribes to an event.
+func (c *WebhookConfig) Wants(event string) bool {
+	if len(c.Events) == 0 {
+		return true
+	}
+	for _, e := range c.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// PrewarmConfig controls the prewarmer, which analyzes the positions of a
+// library of common openings while the engine is idle, so interactive
+// queries about them are answered from the cache. It has no effect unless
+// the cache is enabled.
+type PrewarmConfig struct {
+	Enabled bool `json:"enabled"`
+	// Openings are move sequences in KataGo coordinates, alternating from
+	// Black, such as "Q16 D4 Q3 D16"; every position along each is
+	// analyzed (default: a built-in library of popular fuseki)
+	Openings        []string `json:"openings"`
+	Rules           string   `json:"rules"`           // Rules the positions are analyzed under (default: "chinese")
+	Komi            float64  `json:"komi"`            // Komi the positions are analyzed with (default: 7.5)
+	MaxVisits       int      `json:"maxVisits"`       // Visits per position (0 = the engine's maxVisits)
+	IdleSeconds     int      `json:"idleSeconds"`     // Time the engine must be idle before each query (default: 5)
+	IntervalMinutes int      `json:"intervalMinutes"` // Time between passes over the library (default: 60)
+}
+
+// GTPConfig controls the GTP listener, which lets Go GUIs such as Sabaki
+// and GoGui use the server as an engine, answering genmove, kata-analyze
+// and final_status_list from the cache or the analysis engine. Each
+// connection plays its own game and is rate limited as a client of its own.
+type GTPConfig struct {
+	Addr      string  `json:"addr"`      // Address to listen on, such as "127.0.0.1:6970"; empty disables the listener
+	Rules     string  `json:"rules"`     // Rules games are analyzed under (default: "chinese")
+	Komi      float64 `json:"komi"`      // Komi until a client sets another (default: 7.5)
+	MaxVisits int     `json:"maxVisits"` // Visits per command (0 = the engine's maxVisits)
+}
+
+// SlackConfig controls the Slack bridge, which reviews the games posted in
+// Slack channels, as SGF files or links to games on OGS, and answers each
+// in its thread with a summary and the winrate graph. It serves Slack's
+// Events API at /slack/events. The token and signing secret are secrets;
+// set them with KATAGO_MCP_SLACK_BOT_TOKEN and
+// KATAGO_MCP_SLACK_SIGNING_SECRET rather than in a file that is checked in.
+type SlackConfig struct {
+	Addr          string   `json:"addr"`          // Address to receive events on, such as ":3000"; empty disables the bridge
+	BotToken      string   `json:"botToken"`      // Bot token (xoxb-...) with channels:history, files:read, files:write and chat:write
+	SigningSecret string   `json:"signingSecret"` // Slack app's signing secret, to verify events
+	Channels      []string `json:"channels"`      // Channel IDs to review games in (default: every channel the bot is in)
+	MaxVisits     int      `json:"maxVisits"`     // Visits per move (0 = review default)
+}
+
+// WatchdogConfig controls shutting down when the MCP client that started
+// the server goes away, by closing stdin or exiting, so neither the server
+// nor KataGo keeps holding the GPU. Deployments where stdin is not an MCP
+// client, such as containers serving only GTP, Slack or a watched
+// directory, should disable it.
+type WatchdogConfig struct {
+	Enabled         bool `json:"enabled"`         // Shut down when stdin closes or the parent process exits (default: true)
+	IntervalSeconds int  `json:"intervalSeconds"` // Time between checks that the parent process is alive (default: 2)
+}
+
+type OutputConfig struct {
+	SortMovesBy string `json:"sortMovesBy"` // Candidate move order: "visits" or "lcb"
+	Language    string `json:"language"`    // Default language for explanations, e.g. "en" or "ja"
+}
+
+type SessionConfig struct {
+	MaxSessions          int `json:"maxSessions"`          // Open sessions before the least recently used is closed (0 = unlimited)
+	MaxSessionsPerClient int `json:"maxSessionsPerClient"` // Open sessions per client before its least recently used is closed (0 = unlimited)
+	IdleTimeoutSeconds   int `json:"idleTimeoutSeconds"`   // Idle time before a session expires (0 = never)
+}
+
+// ToolConfig sets the search a tool runs when a request does not set its
+// own, in place of the tool's built-in default or, for tools without one,
+// the engine's maxVisits and maxTime.
+type ToolConfig struct {
+	MaxVisits int     `json:"maxVisits"` // Visits per analysis (0 = the tool's default)
+	MaxTime   float64 `json:"maxTime"`   // Seconds per analysis, for tools in TimedTools (0 = the engine's maxTime)
+}
+
+// SearchTools are the tools whose default visits can be configured.
+var SearchTools = []string{
+	"analyzePosition", "analyzeHere", "explainMove", "estimateScoreDistribution", "countingQuiz",
+	"findMistakes", "reviewArchive", "keyMoments", "territoryTimeline", "estimateRank",
+	"expandVariation", "sweepKomi", "whatIf", "solveTsumego", "generateProblems", "openingBook",
+}
+
+// TimedTools are the tools, each a single analysis, whose default time
+// limit can be configured as well.
+var TimedTools = []string{"analyzePosition", "analyzeHere", "explainMove", "estimateScoreDistribution", "countingQuiz"}
+
+type TimeoutConfig struct {
+	DefaultSeconds int            `json:"defaultSeconds"` // Deadline for tools without an override (0 disables)
+	PerToolSeconds map[string]int `json:"perToolSeconds"` // Per-tool overrides (0 disables)
+}
+
+// ToolTimeout returns the deadline for a tool, or 0 if it has none.
+func (c *TimeoutConfig) ToolTimeout(tool string) time.Duration {
+	seconds, ok := c.PerToolSeconds[tool]
+	if !ok {
+		seconds = c.DefaultSeconds
+	}
+	return time.Duration(seconds) * time.Second
 }
 
 func Load(configPath string) (*Config, error) {
@@ -78,6 +700,26 @@ func Load(configPath string) (*Config, error) {
 			NumThreads: 4,
 			MaxVisits:  1000,
 			MaxTime:    10.0,
+
+			DrainTimeoutSeconds: 20,
+
+			Lanes: LaneConfig{
+				Weights: map[string]int{
+					LaneInteractive: 8,
+					LaneBatch:       3,
+					LaneBackground:  1,
+				},
+			},
+		},
+		ToolLanes: map[string]string{
+			"findMistakes":      LaneBatch,
+			"keyMoments":        LaneBatch,
+			"estimateRank":      LaneBatch,
+			"generateProblems":  LaneBatch,
+			"openingBook":       LaneBatch,
+			"reviewArchive":     LaneBatch,
+			"sweepKomi":         LaneBatch,
+			"territoryTimeline": LaneBatch,
 		},
 		Server: ServerConfig{
 			Name:        "katago-mcp",
@@ -110,10 +752,76 @@ func Load(configPath string) (*Config, error) {
 			PerToolLimits:  make(map[string]int),
 		},
 		Cache: CacheConfig{
-			Enabled:      true,
-			MaxItems:     1000,
-			MaxSizeBytes: 100 * 1024 * 1024, // 100MB
-			TTLSeconds:   3600,              // 1 hour
+			Enabled:       true,
+			MaxItems:      1000,
+			MaxSizeBytes:  100 * 1024 * 1024, // 100MB
+			TTLSeconds:    3600,              // 1 hour
+			MaxEntryBytes: 16 * 1024 * 1024,  // 16MB
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Tracing: TracingConfig{
+			Enabled:     false,
+			Endpoint:    "localhost:4318",
+			SampleRatio: 1.0,
+		},
+		Monitor: MonitorConfig{
+			Enabled:         true,
+			IntervalSeconds: 15,
+			GPU:             true,
+		},
+		Timeouts: TimeoutConfig{
+			DefaultSeconds: 60,
+			PerToolSeconds: map[string]int{
+				"findMistakes":     1800, // Whole-game reviews
+				"estimateRank":     1800,
+				"generateProblems": 1800,
+				"openingBook":      1800,
+				"reviewArchive":    3600, // Up to 200 games
+			},
+		},
+		Output: OutputConfig{
+			SortMovesBy: "visits",
+			Language:    i18n.DefaultLanguage,
+		},
+		Sessions: SessionConfig{
+			MaxSessions:          100,
+			MaxSessionsPerClient: 10,
+			IdleTimeoutSeconds:   3600, // 1 hour
+		},
+		Readiness: ReadinessConfig{
+			MaxPendingQueries: 64,
+			MaxQueuedQueries:  16,
+		},
+		Watch: WatchConfig{
+			IntervalSeconds: 5,
+			Deep: DeepReviewConfig{
+				MaxVisits: 1000,
+				Start:     "01:00",
+				End:       "06:00",
+			},
+		},
+		Review: ReviewConfig{
+			Concurrency: 4,
+		},
+		Namespaces: NamespaceConfig{
+			Isolation: IsolationShared,
+		},
+		Prewarm: PrewarmConfig{
+			Rules:           "chinese",
+			Komi:            7.5,
+			IdleSeconds:     5,
+			IntervalMinutes: 60,
+		},
+		GTP: GTPConfig{
+			Rules: "chinese",
+			Komi:  7.5,
+		},
+		Watchdog: WatchdogConfig{
+			Enabled:         true,
+			IntervalSeconds: 2,
 		},
 	}
 
@@ -151,6 +859,31 @@ func (c *Config) applyEnvOverrides() {
 	if v := os.Getenv("KATAGO_CONFIG_PATH"); v != "" {
 		c.KataGo.ConfigPath = v
 	}
+	if v := os.Getenv("KATAGO_HUMAN_MODEL_PATH"); v != "" {
+		c.KataGo.HumanModelPath = v
+	}
+	if v := os.Getenv("KATAGO_MCP_RECORDER_ENABLED"); v != "" {
+		c.KataGo.Recorder.Enabled = strings.EqualFold(v, "true")
+	}
+	if v := os.Getenv("KATAGO_MCP_RECORDER_PATH"); v != "" {
+		c.KataGo.Recorder.Path = v
+	}
+	if v := os.Getenv("KATAGO_MCP_SLOW_QUERY_SECONDS"); v != "" {
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			c.KataGo.SlowQueries.ThresholdSeconds = seconds
+		}
+	}
+	if v := os.Getenv("KATAGO_MCP_DIAGNOSTICS_DIR"); v != "" {
+		c.KataGo.DiagnosticsDir = v
+	}
+
+	// Server settings
+	if v := os.Getenv("KATAGO_MCP_CLIENT_ID"); v != "" {
+		c.Server.ClientID = v
+	}
+	if v := os.Getenv("KATAGO_MCP_TRUST_CLIENT_ID_ARGUMENT"); v != "" {
+		c.Server.TrustClientIDArgument = strings.EqualFold(v, "true")
+	}
 
 	// Logging settings
 	if v := os.Getenv("KATAGO_MCP_LOG_LEVEL"); v != "" {
@@ -168,10 +901,106 @@ func (c *Config) applyEnvOverrides() {
 		c.RateLimit.Enabled = strings.EqualFold(v, "true")
 	}
 
+	// Quota settings
+	if v := os.Getenv("KATAGO_MCP_QUOTA_ENABLED"); v != "" {
+		c.Quota.Enabled = strings.EqualFold(v, "true")
+	}
+	if v := os.Getenv("KATAGO_MCP_QUOTA_STATE_PATH"); v != "" {
+		c.Quota.StatePath = v
+	}
+
+	// Namespace settings
+	if v := os.Getenv("KATAGO_MCP_NAMESPACE_ISOLATION"); v != "" {
+		c.Namespaces.Isolation = v
+	}
+
+	// Provenance settings
+	if v := os.Getenv("KATAGO_MCP_PROVENANCE_ENABLED"); v != "" {
+		c.Provenance.Enabled = strings.EqualFold(v, "true")
+	}
+	if v := os.Getenv("KATAGO_MCP_PROVENANCE_KEY"); v != "" {
+		c.Provenance.Key = v
+	}
+
 	// Cache settings
 	if v := os.Getenv("KATAGO_MCP_CACHE_ENABLED"); v != "" {
 		c.Cache.Enabled = strings.EqualFold(v, "true")
 	}
+	if v := os.Getenv("KATAGO_MCP_CACHE_REDIS_ADDR"); v != "" {
+		c.Cache.Redis.Addr = v
+	}
+	if v := os.Getenv("KATAGO_MCP_CACHE_REDIS_PASSWORD"); v != "" {
+		c.Cache.Redis.Password = v
+	}
+
+	// Metrics settings
+	if v := os.Getenv("KATAGO_MCP_METRICS_ENABLED"); v != "" {
+		c.Metrics.Enabled = strings.EqualFold(v, "true")
+	}
+	if v := os.Getenv("KATAGO_MCP_METRICS_USERNAME"); v != "" {
+		c.Metrics.Username = v
+	}
+	if v := os.Getenv("KATAGO_MCP_METRICS_PASSWORD"); v != "" {
+		c.Metrics.Password = v
+	}
+
+	// Tracing settings
+	if v := os.Getenv("KATAGO_MCP_TRACING_ENABLED"); v != "" {
+		c.Tracing.Enabled = strings.EqualFold(v, "true")
+	}
+	if v := os.Getenv("KATAGO_MCP_TRACING_ENDPOINT"); v != "" {
+		c.Tracing.Endpoint = v
+	}
+	if v := os.Getenv("KATAGO_MCP_TRACING_INSECURE"); v != "" {
+		c.Tracing.Insecure = strings.EqualFold(v, "true")
+	}
+
+	// Output settings
+	if v := os.Getenv("KATAGO_MCP_SORT_MOVES_BY"); v != "" {
+		c.Output.SortMovesBy = strings.ToLower(v)
+	}
+	if v := os.Getenv("KATAGO_MCP_LANGUAGE"); v != "" {
+		c.Output.Language = v
+	}
+
+	// Watcher settings
+	if v := os.Getenv("KATAGO_MCP_WATCH_DIR"); v != "" {
+		c.Watch.Dir = v
+	}
+
+	// Prewarm settings
+	if v := os.Getenv("KATAGO_MCP_PREWARM_ENABLED"); v != "" {
+		c.Prewarm.Enabled = strings.EqualFold(v, "true")
+	}
+
+	// GTP settings
+	if v := os.Getenv("KATAGO_MCP_GTP_ADDR"); v != "" {
+		c.GTP.Addr = v
+	}
+
+	// Slack settings
+	if v := os.Getenv("KATAGO_MCP_SLACK_ADDR"); v != "" {
+		c.Slack.Addr = v
+	}
+	if v := os.Getenv("KATAGO_MCP_SLACK_BOT_TOKEN"); v != "" {
+		c.Slack.BotToken = v
+	}
+	if v := os.Getenv("KATAGO_MCP_SLACK_SIGNING_SECRET"); v != "" {
+		c.Slack.SigningSecret = v
+	}
+
+	// Watchdog settings
+	if v := os.Getenv("KATAGO_MCP_WATCHDOG_ENABLED"); v != "" {
+		c.Watchdog.Enabled = strings.EqualFold(v, "true")
+	}
+
+	// Monitor settings
+	if v := os.Getenv("KATAGO_MCP_MONITOR_ENABLED"); v != "" {
+		c.Monitor.Enabled = strings.EqualFold(v, "true")
+	}
+	if v := os.Getenv("KATAGO_MCP_MONITOR_GPU"); v != "" {
+		c.Monitor.GPU = strings.EqualFold(v, "true")
+	}
 }
 
 func (c *Config) validate() error {
@@ -189,6 +1018,12 @@ func (c *Config) validate() error {
 		}
 	}
 
+	if os.Getenv("GO_TEST") != "1" && c.KataGo.HumanModelPath != "" && filepath.IsAbs(c.KataGo.HumanModelPath) {
+		if _, err := os.Stat(c.KataGo.HumanModelPath); err != nil {
+			return fmt.Errorf("katago human model not found at %s", c.KataGo.HumanModelPath)
+		}
+	}
+
 	// Validate numeric ranges
 	if c.KataGo.NumThreads < 1 {
 		c.KataGo.NumThreads = 1
@@ -199,6 +1034,248 @@ func (c *Config) validate() error {
 	if c.KataGo.MaxTime < 0.1 {
 		c.KataGo.MaxTime = 0.1
 	}
+	if err := c.KataGo.Search.Validate(); err != nil {
+		return fmt.Errorf("katago search settings: %w", err)
+	}
+	if c.KataGo.Recorder.Size < 0 {
+		return fmt.Errorf("katago recorder size must not be negative: %d", c.KataGo.Recorder.Size)
+	}
+	if err := c.KataGo.SlowQueries.Validate(); err != nil {
+		return fmt.Errorf("katago slowQueries: %w", err)
+	}
+	if err := c.KataGo.HealthCheck.Validate(); err != nil {
+		return fmt.Errorf("katago healthCheck: %w", err)
+	}
+	if c.KataGo.DrainTimeoutSeconds < 0 {
+		return fmt.Errorf("katago drainTimeoutSeconds must not be negative: %d", c.KataGo.DrainTimeoutSeconds)
+	}
+	if c.KataGo.IdleSuspendMinutes < 0 {
+		return fmt.Errorf("katago idleSuspendMinutes must not be negative: %d", c.KataGo.IdleSuspendMinutes)
+	}
+
+	// Validate shared cache settings
+	if c.Cache.Redis.DB < 0 || c.Cache.Redis.TimeoutMs < 0 || c.Cache.Redis.PoolSize < 0 {
+		return fmt.Errorf("cache redis settings must not be negative")
+	}
+	switch c.Cache.Redis.Compression {
+	case "", "none", "gzip":
+	default:
+		return fmt.Errorf("cache redis compression must be none or gzip: %q", c.Cache.Redis.Compression)
+	}
+	if c.Cache.MaxEntryBytes < 0 || c.Cache.CompressAboveBytes < 0 {
+		return fmt.Errorf("cache entry sizes must not be negative")
+	}
+
+	// Validate metrics endpoint
+	if c.Metrics.Path == "" {
+		c.Metrics.Path = "/metrics"
+	}
+	if !strings.HasPrefix(c.Metrics.Path, "/") {
+		return fmt.Errorf("metrics path must start with '/': %s", c.Metrics.Path)
+	}
+	if (c.Metrics.Username == "") != (c.Metrics.Password == "") {
+		return fmt.Errorf("metrics basic auth requires both username and password")
+	}
+
+	// Validate tracing sample ratio
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing sampleRatio must be between 0 and 1: %v", c.Tracing.SampleRatio)
+	}
+
+	// Validate monitor settings
+	if c.Monitor.IntervalSeconds < 1 {
+		c.Monitor.IntervalSeconds = 1
+	}
+	if c.Monitor.MaxCPUPercent < 0 || c.Monitor.MaxRSSBytes < 0 ||
+		c.Monitor.MaxGPUPercent < 0 || c.Monitor.MaxGPUMemoryBytes < 0 {
+		return fmt.Errorf("monitor thresholds must not be negative")
+	}
+
+	// Validate tool timeouts
+	if c.Timeouts.DefaultSeconds < 0 {
+		return fmt.Errorf("default tool timeout must not be negative: %d", c.Timeouts.DefaultSeconds)
+	}
+	for tool, seconds := range c.Timeouts.PerToolSeconds {
+		if seconds < 0 {
+			return fmt.Errorf("timeout for tool %s must not be negative: %d", tool, seconds)
+		}
+	}
+
+	// Validate tool search defaults
+	for tool, defaults := range c.Tools {
+		if !isListed(SearchTools, tool) {
+			return fmt.Errorf("tools.%s: tool has no search to configure", tool)
+		}
+		if defaults.MaxVisits < 0 {
+			return fmt.Errorf("tools.%s maxVisits must not be negative: %d", tool, defaults.MaxVisits)
+		}
+		if defaults.MaxTime < 0 {
+			return fmt.Errorf("tools.%s maxTime must not be negative: %g", tool, defaults.MaxTime)
+		}
+		if defaults.MaxTime > 0 && !isListed(TimedTools, tool) {
+			return fmt.Errorf("tools.%s: maxTime is only supported for %s", tool, strings.Join(TimedTools, ", "))
+		}
+	}
+
+	// Validate output settings
+	switch c.Output.SortMovesBy {
+	case "":
+		c.Output.SortMovesBy = "visits"
+	case "visits", "lcb":
+	default:
+		return fmt.Errorf("output sortMovesBy must be 'visits' or 'lcb': %s", c.Output.SortMovesBy)
+	}
+	language, err := i18n.Normalize(c.Output.Language)
+	if err != nil {
+		return fmt.Errorf("output language: %w", err)
+	}
+	c.Output.Language = language
+
+	// Validate session settings
+	if c.Sessions.MaxSessions < 0 || c.Sessions.MaxSessionsPerClient < 0 || c.Sessions.IdleTimeoutSeconds < 0 {
+		return fmt.Errorf("session limits must not be negative")
+	}
+
+	// Validate readiness limits
+	if c.Readiness.MaxPendingQueries < 0 || c.Readiness.MaxQueuedQueries < 0 {
+		return fmt.Errorf("readiness limits must not be negative")
+	}
+
+	// Validate watcher settings
+	if c.Watch.IntervalSeconds < 1 {
+		c.Watch.IntervalSeconds = 1
+	}
+	if c.Watch.MaxVisits < 0 {
+		return fmt.Errorf("watch maxVisits must not be negative: %d", c.Watch.MaxVisits)
+	}
+	if c.Watch.Deep.Enabled {
+		if c.Watch.Deep.MaxVisits < 1 {
+			return fmt.Errorf("watch deep maxVisits must be at least 1: %d", c.Watch.Deep.MaxVisits)
+		}
+		if _, _, err := c.Watch.Deep.Window(); err != nil {
+			return err
+		}
+	}
+
+	// Validate review settings
+	if c.Review.Concurrency < 1 {
+		c.Review.Concurrency = 1
+	}
+	analyzers := map[string]bool{}
+	for i := range c.Review.Analyzers {
+		analyzer := &c.Review.Analyzers[i]
+		if analyzer.Name == "" {
+			return fmt.Errorf("review analyzer %d has no name", i)
+		}
+		if analyzers[analyzer.Name] {
+			return fmt.Errorf("duplicate review analyzer name: %s", analyzer.Name)
+		}
+		analyzers[analyzer.Name] = true
+		if analyzer.Command == "" {
+			return fmt.Errorf("review analyzer %s has no command", analyzer.Name)
+		}
+		if analyzer.TimeoutSeconds < 0 {
+			return fmt.Errorf("review analyzer %s timeoutSeconds must not be negative: %d", analyzer.Name, analyzer.TimeoutSeconds)
+		}
+		if analyzer.TimeoutSeconds == 0 {
+			analyzer.TimeoutSeconds = 10
+		}
+	}
+
+	// Validate webhooks
+	for i := range c.Webhooks {
+		hook := &c.Webhooks[i]
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook %d needs an http or https url: %q", i, hook.URL)
+		}
+		for _, event := range hook.Events {
+			if !isWebhookEvent(event) {
+				return fmt.Errorf("webhook %s subscribes to unknown event: %s", u.Host, event)
+			}
+		}
+		if hook.TimeoutSeconds < 0 {
+			return fmt.Errorf("webhook %s timeoutSeconds must not be negative: %d", u.Host, hook.TimeoutSeconds)
+		}
+		if hook.TimeoutSeconds == 0 {
+			hook.TimeoutSeconds = 10
+		}
+	}
+
+	// Validate prewarm settings
+	if c.Prewarm.MaxVisits < 0 {
+		return fmt.Errorf("prewarm maxVisits must not be negative: %d", c.Prewarm.MaxVisits)
+	}
+	if c.Prewarm.IdleSeconds < 0 {
+		return fmt.Errorf("prewarm idleSeconds must not be negative: %d", c.Prewarm.IdleSeconds)
+	}
+	if c.Prewarm.IntervalMinutes < 1 {
+		c.Prewarm.IntervalMinutes = 1
+	}
+	if c.GTP.MaxVisits < 0 {
+		return fmt.Errorf("gtp maxVisits must not be negative: %d", c.GTP.MaxVisits)
+	}
+	if c.Slack.Addr != "" && (c.Slack.BotToken == "" || c.Slack.SigningSecret == "") {
+		return fmt.Errorf("slack bridge needs a botToken and signingSecret")
+	}
+	if c.Slack.MaxVisits < 0 {
+		return fmt.Errorf("slack maxVisits must not be negative: %d", c.Slack.MaxVisits)
+	}
+	if c.Watchdog.IntervalSeconds < 1 {
+		c.Watchdog.IntervalSeconds = 1
+	}
+
+	// Validate additional engines
+	names := map[string]bool{DefaultEngineName: true}
+	for i := range c.Engines {
+		engine := &c.Engines[i]
+		if engine.Name == "" {
+			return fmt.Errorf("engine %d has no name", i)
+		}
+		if names[engine.Name] {
+			return fmt.Errorf("duplicate engine name: %s", engine.Name)
+		}
+		names[engine.Name] = true
+		engine.inherit(&c.KataGo)
+		if err := engine.Search.Validate(); err != nil {
+			return fmt.Errorf("engine %s search settings: %w", engine.Name, err)
+		}
+		if engine.Recorder.Size < 0 {
+			return fmt.Errorf("engine %s recorder size must not be negative: %d", engine.Name, engine.Recorder.Size)
+		}
+		if err := engine.SlowQueries.Validate(); err != nil {
+			return fmt.Errorf("engine %s slowQueries: %w", engine.Name, err)
+		}
+		if err := engine.HealthCheck.Validate(); err != nil {
+			return fmt.Errorf("engine %s healthCheck: %w", engine.Name, err)
+		}
+		if engine.DrainTimeoutSeconds < 0 {
+			return fmt.Errorf("engine %s drainTimeoutSeconds must not be negative: %d", engine.Name, engine.DrainTimeoutSeconds)
+		}
+		if engine.IdleSuspendMinutes < 0 {
+			return fmt.Errorf("engine %s idleSuspendMinutes must not be negative: %d", engine.Name, engine.IdleSuspendMinutes)
+		}
+	}
+	for tool, name := range c.EngineRouting {
+		if !names[name] {
+			return fmt.Errorf("tool %s is routed to unknown engine: %s", tool, name)
+		}
+	}
+
+	// Validate scheduling lanes
+	if err := c.KataGo.Lanes.validate(); err != nil {
+		return err
+	}
+	for i := range c.Engines {
+		if err := c.Engines[i].Lanes.validate(); err != nil {
+			return fmt.Errorf("engine %s: %w", c.Engines[i].Name, err)
+		}
+	}
+	for tool, lane := range c.ToolLanes {
+		if !isLane(lane) {
+			return fmt.Errorf("tool %s is assigned to unknown lane: %s", tool, lane)
+		}
+	}
 
 	// Validate rate limits
 	if c.RateLimit.Enabled {
@@ -210,9 +1287,137 @@ func (c *Config) validate() error {
 		}
 	}
 
+	// Validate quotas
+	if c.Quota.DailyVisits < 0 || c.Quota.MonthlyVisits < 0 {
+		return fmt.Errorf("quota visits must not be negative")
+	}
+	for client, limits := range c.Quota.PerClient {
+		if limits.DailyVisits < 0 || limits.MonthlyVisits < 0 {
+			return fmt.Errorf("quota visits for client %s must not be negative", client)
+		}
+	}
+
+	// Validate namespaces
+	switch c.Namespaces.Isolation {
+	case "":
+		c.Namespaces.Isolation = IsolationShared
+	case IsolationShared, IsolationStrict:
+	default:
+		return fmt.Errorf("unknown namespace isolation: %s", c.Namespaces.Isolation)
+	}
+	for client, namespace := range c.Namespaces.Clients {
+		if namespace == "" {
+			return fmt.Errorf("namespace of client %s must not be empty", client)
+		}
+	}
+
+	// Validate provenance
+	if c.Provenance.Enabled && c.Provenance.Key == "" && c.Provenance.KeyFile == "" {
+		return fmt.Errorf("provenance signing needs a key or keyFile")
+	}
+
 	return nil
 }
 
+// validate checks the lane settings.
+func (c *LaneConfig) validate() error {
+	if c.MaxInFlight < 0 {
+		return fmt.Errorf("lanes maxInFlight must not be negative: %d", c.MaxInFlight)
+	}
+	for lane, weight := range c.Weights {
+		if !isLane(lane) {
+			return fmt.Errorf("unknown lane: %s", lane)
+		}
+		if weight < 1 {
+			return fmt.Errorf("weight of lane %s must be at least 1: %d", lane, weight)
+		}
+	}
+	return nil
+}
+
+// isWebhookEvent reports whether name is a webhook event.
+func isWebhookEvent(name string) bool {
+	for _, event := range WebhookEvents {
+		if event == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isListed reports whether a tool is one of tools.
+func isListed(tools []string, tool string) bool {
+	for _, t := range tools {
+		if t == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// isLane reports whether a lane name is known.
+func isLane(name string) bool {
+	for _, lane := range Lanes {
+		if lane == name {
+			return true
+		}
+	}
+	return false
+}
+
+// inherit fills unset fields from the default engine.
+func (e *EngineConfig) inherit(base *KataGoConfig) {
+	if e.BinaryPath == "" {
+		e.BinaryPath = base.BinaryPath
+	}
+	if e.ModelPath == "" {
+		e.ModelPath = base.ModelPath
+	}
+	if e.ConfigPath == "" {
+		e.ConfigPath = base.ConfigPath
+	}
+	if e.HumanModelPath == "" {
+		e.HumanModelPath = base.HumanModelPath
+	}
+	if e.HumanProfile == "" {
+		e.HumanProfile = base.HumanProfile
+	}
+	if e.DiagnosticsDir == "" {
+		e.DiagnosticsDir = base.DiagnosticsDir
+	}
+	if e.NumThreads < 1 {
+		e.NumThreads = base.NumThreads
+	}
+	if e.MaxVisits < 1 {
+		e.MaxVisits = base.MaxVisits
+	}
+	if e.MaxTime <= 0 {
+		e.MaxTime = base.MaxTime
+	}
+	if e.DrainTimeoutSeconds == 0 {
+		e.DrainTimeoutSeconds = base.DrainTimeoutSeconds
+	}
+	if e.IdleSuspendMinutes == 0 {
+		e.IdleSuspendMinutes = base.IdleSuspendMinutes
+	}
+	if e.Lanes.MaxInFlight == 0 {
+		e.Lanes.MaxInFlight = base.Lanes.MaxInFlight
+	}
+	if e.SlowQueries.ThresholdSeconds == 0 {
+		e.SlowQueries = base.SlowQueries
+	}
+	if e.HealthCheck.TimeoutSeconds == 0 {
+		e.HealthCheck.TimeoutSeconds = base.HealthCheck.TimeoutSeconds
+	}
+	if e.HealthCheck.MaxFailures == 0 {
+		e.HealthCheck.MaxFailures = base.HealthCheck.MaxFailures
+	}
+	if e.Lanes.Weights == nil {
+		e.Lanes.Weights = base.Lanes.Weights
+	}
+	e.Search = e.Search.Merge(base.Search)
+}
+
 func (c *Config) GetKataGoHomeDir() string {
 	if home := os.Getenv("KATAGO_HOME"); home != "" {
 		return home
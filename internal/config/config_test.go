@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadDefaultConfig(t *testing.T) {
@@ -218,3 +221,702 @@ func TestGetConfigPath(t *testing.T) {
 	// This could be empty or a found config file, both are valid
 	t.Logf("Config path without env var: %s", path)
 }
+
+func TestMetricsConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if !cfg.Metrics.Enabled {
+		t.Error("Expected metrics to be enabled by default")
+	}
+	if cfg.Metrics.Path != "/metrics" {
+		t.Errorf("Expected default metrics path '/metrics', got %s", cfg.Metrics.Path)
+	}
+
+	os.Setenv("KATAGO_MCP_METRICS_ENABLED", "false")
+	defer os.Unsetenv("KATAGO_MCP_METRICS_ENABLED")
+	cfg, err = Load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Metrics.Enabled {
+		t.Error("Expected env override to disable metrics")
+	}
+
+	// Basic auth requires both credentials
+	os.Setenv("KATAGO_MCP_METRICS_USERNAME", "prometheus")
+	defer os.Unsetenv("KATAGO_MCP_METRICS_USERNAME")
+	if _, err := Load(""); err == nil {
+		t.Error("Expected error when metrics password is missing")
+	}
+
+	os.Setenv("KATAGO_MCP_METRICS_PASSWORD", "secret")
+	defer os.Unsetenv("KATAGO_MCP_METRICS_PASSWORD")
+	if _, err := Load(""); err != nil {
+		t.Errorf("Unexpected error with full basic auth credentials: %v", err)
+	}
+
+	// Clients named anywhere in the configuration are labeled in metrics
+	cfg = &Config{
+		Metrics:    MetricsConfig{Clients: []string{"dashboard"}},
+		Server:     ServerConfig{ClientID: "deployment"},
+		Quota:      QuotaConfig{PerClient: map[string]QuotaLimits{"team-a": {}}},
+		Namespaces: NamespaceConfig{Clients: map[string]string{"team-b": "b"}},
+	}
+	clients := cfg.MetricClients()
+	sort.Strings(clients)
+	if !reflect.DeepEqual(clients, []string{"dashboard", "deployment", "team-a", "team-b"}) {
+		t.Errorf("Expected every configured client, got %v", clients)
+	}
+}
+
+func TestToolTimeouts(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if got := cfg.Timeouts.ToolTimeout("analyzePosition"); got != 60*time.Second {
+		t.Errorf("Expected 60s default timeout, got %v", got)
+	}
+	if got := cfg.Timeouts.ToolTimeout("findMistakes"); got != 30*time.Minute {
+		t.Errorf("Expected 30m findMistakes timeout, got %v", got)
+	}
+
+	// File overrides merge with the defaults
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	data := `{"timeouts": {"perToolSeconds": {"explainMove": 0}}}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err = Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if got := cfg.Timeouts.ToolTimeout("explainMove"); got != 0 {
+		t.Errorf("Expected explainMove timeout disabled, got %v", got)
+	}
+	if got := cfg.Timeouts.ToolTimeout("findMistakes"); got != 30*time.Minute {
+		t.Errorf("Expected default findMistakes timeout to remain, got %v", got)
+	}
+
+	cfg.Timeouts.DefaultSeconds = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative timeout")
+	}
+}
+
+func TestEngines(t *testing.T) {
+	t.Setenv("GO_TEST", "1")
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.json")
+	data := `{
+		"katago": {"modelPath": "/models/big.bin.gz", "maxVisits": 2000},
+		"engines": [{"name": "fast", "modelPath": "/models/small.bin.gz", "maxVisits": 200}],
+		"engineRouting": {"explainMove": "fast", "findMistakes": "default"}
+	}`
+	if err := os.WriteFile(configPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	engines := cfg.EngineConfigs()
+	if len(engines) != 2 {
+		t.Fatalf("Expected 2 engines, got %d", len(engines))
+	}
+	fast := engines["fast"]
+	if fast.ModelPath != "/models/small.bin.gz" || fast.MaxVisits != 200 {
+		t.Errorf("Unexpected fast engine config: %+v", fast)
+	}
+	// Unset fields are inherited from the default engine
+	if fast.BinaryPath != "katago" || fast.NumThreads != 4 || fast.MaxTime != 10.0 {
+		t.Errorf("Expected fast engine to inherit defaults, got %+v", fast)
+	}
+	if engines[DefaultEngineName].MaxVisits != 2000 {
+		t.Errorf("Expected default engine maxVisits 2000, got %d", engines[DefaultEngineName].MaxVisits)
+	}
+
+	cfg.EngineRouting["analyzePosition"] = "missing"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for routing to unknown engine")
+	}
+	delete(cfg.EngineRouting, "analyzePosition")
+
+	cfg.Engines = append(cfg.Engines, EngineConfig{Name: "fast"})
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for duplicate engine name")
+	}
+
+	cfg.Engines = []EngineConfig{{Name: DefaultEngineName}}
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for engine named default")
+	}
+}
+
+func TestOutputConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if cfg.Output.SortMovesBy != "visits" {
+		t.Errorf("Expected moves sorted by visits by default, got %q", cfg.Output.SortMovesBy)
+	}
+
+	t.Setenv("KATAGO_MCP_SORT_MOVES_BY", "LCB")
+	cfg, err = Load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Output.SortMovesBy != "lcb" {
+		t.Errorf("Expected env override to lcb, got %q", cfg.Output.SortMovesBy)
+	}
+
+	cfg.Output.SortMovesBy = "prior"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for unknown sort order")
+	}
+}
+
+func TestOutputLanguage(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if cfg.Output.Language != "en" {
+		t.Errorf("Expected English by default, got %q", cfg.Output.Language)
+	}
+
+	t.Setenv("KATAGO_MCP_LANGUAGE", "ja-JP")
+	cfg, err = Load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Output.Language != "ja" {
+		t.Errorf("Expected env override normalized to ja, got %q", cfg.Output.Language)
+	}
+
+	cfg.Output.Language = "xx"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for unsupported language")
+	}
+}
+
+func TestSearchConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if cfg.KataGo.Search.WideRootNoise != nil || cfg.KataGo.Search.AnalysisPVLen != nil {
+		t.Errorf("Expected search settings to be unset by default: %+v", cfg.KataGo.Search)
+	}
+
+	noise, pvLen := 0.1, 20
+	cfg.KataGo.Search = SearchConfig{WideRootNoise: &noise}
+	cfg.Engines = []EngineConfig{{Name: "fast", KataGoConfig: KataGoConfig{Search: SearchConfig{AnalysisPVLen: &pvLen}}}}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	fast := cfg.Engines[0].Search
+	if fast.WideRootNoise == nil || *fast.WideRootNoise != 0.1 || *fast.AnalysisPVLen != 20 {
+		t.Errorf("Expected engine to inherit wideRootNoise, got %+v", fast)
+	}
+
+	tests := []struct {
+		name   string
+		search SearchConfig
+	}{
+		{"negative noise", SearchConfig{WideRootNoise: floatPtr(-0.1)}},
+		{"noise too high", SearchConfig{WideRootNoise: floatPtr(2)}},
+		{"zero temperature", SearchConfig{RootPolicyTemperature: floatPtr(0)}},
+		{"zero pv length", SearchConfig{AnalysisPVLen: intPtr(0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.search.Validate(); err == nil {
+				t.Error("Expected validation error")
+			}
+			cfg.KataGo.Search = tt.search
+			cfg.Engines = nil
+			if err := cfg.validate(); err == nil {
+				t.Error("Expected config validation error")
+			}
+		})
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestRecorderConfig(t *testing.T) {
+	t.Setenv("KATAGO_MCP_RECORDER_ENABLED", "true")
+	t.Setenv("KATAGO_MCP_RECORDER_PATH", "/tmp/katago.jsonl")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !cfg.KataGo.Recorder.Enabled || cfg.KataGo.Recorder.Path != "/tmp/katago.jsonl" {
+		t.Errorf("Expected recording enabled from the environment, got %+v", cfg.KataGo.Recorder)
+	}
+
+	// Engines do not share the default engine's recording
+	cfg.Engines = []EngineConfig{{Name: "fast"}}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Engines[0].Recorder.Enabled {
+		t.Error("Expected the recorder not to be inherited")
+	}
+
+	cfg.Engines[0].Recorder.Size = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for a negative recorder size")
+	}
+}
+
+func TestSlowQueryConfig(t *testing.T) {
+	t.Setenv("KATAGO_MCP_SLOW_QUERY_SECONDS", "2.5")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.KataGo.SlowQueries.ThresholdSeconds != 2.5 {
+		t.Errorf("Expected a 2.5s threshold from the environment, got %+v", cfg.KataGo.SlowQueries)
+	}
+
+	// Engines share the default engine's threshold unless they set their own
+	cfg.Engines = []EngineConfig{{Name: "fast"}, {Name: "deep", KataGoConfig: KataGoConfig{SlowQueries: SlowQueryConfig{ThresholdSeconds: 30}}}}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Engines[0].SlowQueries.ThresholdSeconds != 2.5 || cfg.Engines[1].SlowQueries.ThresholdSeconds != 30 {
+		t.Errorf("Unexpected engine thresholds: %+v, %+v", cfg.Engines[0].SlowQueries, cfg.Engines[1].SlowQueries)
+	}
+
+	cfg.Engines[1].SlowQueries.Size = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for a negative slow query log size")
+	}
+
+	t.Setenv("KATAGO_MCP_SLOW_QUERY_SECONDS", "-1")
+	if _, err := Load(""); err == nil {
+		t.Error("Expected error for a negative threshold")
+	}
+}
+
+func TestHealthCheckConfig(t *testing.T) {
+	var check HealthCheckConfig
+	if check.Timeout() != DefaultHealthCheckTimeout || check.Failures() != DefaultHealthCheckFailures {
+		t.Errorf("Expected the defaults, got %v and %d", check.Timeout(), check.Failures())
+	}
+	check = HealthCheckConfig{TimeoutSeconds: 0.5, MaxFailures: 5}
+	if check.Timeout() != 500*time.Millisecond || check.Failures() != 5 {
+		t.Errorf("Expected 500ms and 5 failures, got %v and %d", check.Timeout(), check.Failures())
+	}
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.KataGo.HealthCheck = HealthCheckConfig{TimeoutSeconds: 10, MaxFailures: 2}
+	cfg.Engines = []EngineConfig{{Name: "fast", KataGoConfig: KataGoConfig{HealthCheck: HealthCheckConfig{MaxFailures: 5}}}}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := cfg.Engines[0].HealthCheck; got.TimeoutSeconds != 10 || got.MaxFailures != 5 {
+		t.Errorf("Expected the default engine's timeout and the engine's own failures, got %+v", got)
+	}
+
+	cfg.Engines[0].HealthCheck.MaxFailures = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative maxFailures")
+	}
+}
+
+func TestDrainTimeoutConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.KataGo.DrainTimeoutSeconds != 20 {
+		t.Errorf("Expected a 20 second drain by default, got %d", cfg.KataGo.DrainTimeoutSeconds)
+	}
+
+	cfg.Engines = []EngineConfig{{Name: "fast"}}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Engines[0].DrainTimeoutSeconds != 20 {
+		t.Errorf("Expected the drain timeout inherited, got %d", cfg.Engines[0].DrainTimeoutSeconds)
+	}
+
+	cfg.KataGo.DrainTimeoutSeconds = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for a negative drain timeout")
+	}
+}
+
+func TestIdleSuspendConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.KataGo.IdleSuspendMinutes != 0 {
+		t.Errorf("Expected engines never suspended by default, got %d", cfg.KataGo.IdleSuspendMinutes)
+	}
+
+	cfg.KataGo.IdleSuspendMinutes = 30
+	cfg.Engines = []EngineConfig{{Name: "fast"}}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Engines[0].IdleSuspendMinutes != 30 {
+		t.Errorf("Expected the idle suspension inherited, got %d", cfg.Engines[0].IdleSuspendMinutes)
+	}
+
+	cfg.Engines[0].IdleSuspendMinutes = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for a negative idle suspension")
+	}
+}
+
+func TestNamespaceConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Namespaces.Strict() {
+		t.Error("Expected namespaces to share the cache by default")
+	}
+
+	cfg.Namespaces.Clients = map[string]string{"alice": "go-club"}
+	if ns := cfg.Namespaces.Namespace("alice"); ns != "go-club" {
+		t.Errorf("Expected alice in the go-club namespace, got %q", ns)
+	}
+	if ns := cfg.Namespaces.Namespace("bob"); ns != "bob" {
+		t.Errorf("Expected an unmapped client in its own namespace, got %q", ns)
+	}
+
+	cfg.Namespaces.Isolation = ""
+	if err := cfg.validate(); err != nil || cfg.Namespaces.Isolation != IsolationShared {
+		t.Errorf("Expected shared isolation by default, got %q, %v", cfg.Namespaces.Isolation, err)
+	}
+	cfg.Namespaces.Isolation = "private"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for an unknown isolation")
+	}
+	cfg.Namespaces.Isolation = IsolationStrict
+	cfg.Namespaces.Clients["bob"] = ""
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for an empty namespace")
+	}
+
+	// Admin tools are open to everyone only in a shared server with no
+	// admins listed
+	admins := NamespaceConfig{Isolation: IsolationShared}
+	if !admins.Admin("alice") {
+		t.Error("Expected every client to be an admin with no admins listed")
+	}
+	admins.Isolation = IsolationStrict
+	if admins.Admin("alice") {
+		t.Error("Expected no admins under strict isolation with none listed")
+	}
+	admins.Admins = []string{"ops"}
+	if !admins.Admin("ops") || admins.Admin("alice") {
+		t.Error("Expected only the listed client to be an admin")
+	}
+	admins.Isolation = IsolationShared
+	if !admins.Admin("ops") || admins.Admin("alice") {
+		t.Error("Expected only the listed client to be an admin when shared")
+	}
+}
+
+func TestProvenanceConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.Provenance.Enabled = true
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for signing without a key")
+	}
+	cfg.Provenance.KeyFile = "/etc/katago-mcp/provenance.key"
+	if err := cfg.validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestSessionConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if cfg.Sessions.MaxSessions != 100 || cfg.Sessions.MaxSessionsPerClient != 10 || cfg.Sessions.IdleTimeoutSeconds != 3600 {
+		t.Errorf("Unexpected session defaults: %+v", cfg.Sessions)
+	}
+
+	cfg.Sessions.IdleTimeoutSeconds = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative idle timeout")
+	}
+
+	cfg.Sessions.IdleTimeoutSeconds = 3600
+	cfg.Sessions.MaxSessionsPerClient = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative per-client session limit")
+	}
+}
+
+func TestQuotaConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if cfg.Quota.Enabled {
+		t.Error("Expected quotas to be disabled by default")
+	}
+
+	var parsed Config
+	data := `{"quota": {"enabled": true, "dailyVisits": 100000, "perClient": {"club": {"dailyVisits": 500000, "monthlyVisits": 5000000}}}}`
+	if err := json.Unmarshal([]byte(data), &parsed); err != nil {
+		t.Fatalf("Failed to parse quota config: %v", err)
+	}
+	if got := parsed.Quota.Limits("someone"); got != (QuotaLimits{DailyVisits: 100000}) {
+		t.Errorf("Unexpected default limits: %+v", got)
+	}
+	if got := parsed.Quota.Limits("club"); got != (QuotaLimits{DailyVisits: 500000, MonthlyVisits: 5000000}) {
+		t.Errorf("Unexpected per-client limits: %+v", got)
+	}
+
+	cfg.Quota.PerClient = map[string]QuotaLimits{"club": {MonthlyVisits: -1}}
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for a negative per-client quota")
+	}
+}
+
+func TestLaneConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if cfg.KataGo.Lanes.Weights[LaneInteractive] <= cfg.KataGo.Lanes.Weights[LaneBatch] ||
+		cfg.KataGo.Lanes.Weights[LaneBatch] <= cfg.KataGo.Lanes.Weights[LaneBackground] {
+		t.Errorf("Expected lane weights to fall with priority, got %v", cfg.KataGo.Lanes.Weights)
+	}
+	if cfg.ToolLanes["findMistakes"] != LaneBatch {
+		t.Errorf("Expected findMistakes in the batch lane, got %q", cfg.ToolLanes["findMistakes"])
+	}
+
+	cfg.ToolLanes["explainMove"] = "urgent"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for an unknown lane")
+	}
+	delete(cfg.ToolLanes, "explainMove")
+
+	cfg.KataGo.Lanes.Weights[LaneBatch] = 0
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for a zero lane weight")
+	}
+	cfg.KataGo.Lanes.Weights[LaneBatch] = 3
+
+	cfg.Engines = []EngineConfig{{Name: "fast"}}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Engines[0].Lanes.Weights[LaneInteractive] != cfg.KataGo.Lanes.Weights[LaneInteractive] {
+		t.Errorf("Expected engines to inherit lane weights, got %v", cfg.Engines[0].Lanes.Weights)
+	}
+}
+
+func TestReadinessConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if cfg.Readiness.MaxPendingQueries != 64 || cfg.Readiness.MaxQueuedQueries != 16 {
+		t.Errorf("Unexpected readiness defaults: %+v", cfg.Readiness)
+	}
+
+	cfg.Readiness.MaxQueuedQueries = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative queued query limit")
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	t.Setenv("KATAGO_MCP_WATCH_DIR", "/srv/games")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if cfg.Watch.Dir != "/srv/games" || cfg.Watch.IntervalSeconds != 5 {
+		t.Errorf("Unexpected watch config: %+v", cfg.Watch)
+	}
+
+	cfg.Watch.MaxVisits = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative watch visits")
+	}
+	cfg.Watch.MaxVisits = 0
+
+	if start, end, err := cfg.Watch.Deep.Window(); err != nil || start != 60 || end != 360 {
+		t.Errorf("Expected a default window of 01:00-06:00, got %d-%d (%v)", start, end, err)
+	}
+	cfg.Watch.Deep.Enabled = true
+	for _, window := range [][2]string{{"1am", "06:00"}, {"22:00", "24:30"}, {"03:00", "03:00"}} {
+		cfg.Watch.Deep.Start, cfg.Watch.Deep.End = window[0], window[1]
+		if err := cfg.validate(); err == nil {
+			t.Errorf("Expected error for deep review window %s-%s", window[0], window[1])
+		}
+	}
+}
+
+func TestReviewConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if cfg.Review.Concurrency != 4 {
+		t.Errorf("Expected 4 positions analyzed at once by default, got %d", cfg.Review.Concurrency)
+	}
+
+	cfg.Review.Concurrency = 0
+	if err := cfg.validate(); err != nil || cfg.Review.Concurrency != 1 {
+		t.Errorf("Expected concurrency raised to 1, got %d (%v)", cfg.Review.Concurrency, err)
+	}
+
+	cfg.Review.Analyzers = []AnalyzerConfig{{Name: "shapes", Command: "shapes.py"}}
+	if err := cfg.validate(); err != nil || cfg.Review.Analyzers[0].TimeoutSeconds != 10 {
+		t.Errorf("Expected the analyzer's timeout defaulted to 10s, got %+v (%v)", cfg.Review.Analyzers[0], err)
+	}
+	cfg.Review.Analyzers = append(cfg.Review.Analyzers, AnalyzerConfig{Name: "shapes", Command: "other"})
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected an error for duplicate analyzer names")
+	}
+	cfg.Review.Analyzers = []AnalyzerConfig{{Name: "shapes"}}
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected an error for an analyzer without a command")
+	}
+}
+
+func TestWebhookConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	cfg.Webhooks = []WebhookConfig{{URL: "https://bot.example.com/hook", Events: []string{EventReviewFailed}}}
+	if err := cfg.validate(); err != nil || cfg.Webhooks[0].TimeoutSeconds != 10 {
+		t.Errorf("Expected the webhook's timeout defaulted to 10s, got %+v (%v)", cfg.Webhooks[0], err)
+	}
+	if cfg.Webhooks[0].Wants(EventReviewCompleted) || !cfg.Webhooks[0].Wants(EventReviewFailed) {
+		t.Errorf("Expected only the subscribed event wanted")
+	}
+	if !(&WebhookConfig{}).Wants(EventReviewCompleted) {
+		t.Errorf("Expected every event wanted by default")
+	}
+
+	for _, hook := range []WebhookConfig{
+		{URL: "bot.example.com/hook"},
+		{URL: "ftp://bot.example.com/hook"},
+		{URL: "https://bot.example.com/hook", Events: []string{"review.started"}},
+		{URL: "https://bot.example.com/hook", TimeoutSeconds: -1},
+	} {
+		cfg.Webhooks = []WebhookConfig{hook}
+		if err := cfg.validate(); err == nil {
+			t.Errorf("Expected an error for %+v", hook)
+		}
+	}
+}
+
+func TestPrewarmConfig(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if cfg.Prewarm.Enabled || cfg.Prewarm.Komi != 7.5 || cfg.Prewarm.Rules != "chinese" || cfg.Prewarm.IntervalMinutes != 60 {
+		t.Errorf("Unexpected prewarm defaults: %+v", cfg.Prewarm)
+	}
+
+	cfg.Prewarm.IntervalMinutes = 0
+	if err := cfg.validate(); err != nil || cfg.Prewarm.IntervalMinutes != 1 {
+		t.Errorf("Expected interval raised to 1 minute, got %d (%v)", cfg.Prewarm.IntervalMinutes, err)
+	}
+	cfg.Prewarm.IdleSeconds = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected a negative idle time to be rejected")
+	}
+}
+
+func TestGTPConfig(t *testing.T) {
+	t.Setenv("KATAGO_MCP_GTP_ADDR", "127.0.0.1:6970")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load default config: %v", err)
+	}
+	if cfg.GTP.Addr != "127.0.0.1:6970" || cfg.GTP.Rules != "chinese" || cfg.GTP.Komi != 7.5 {
+		t.Errorf("Unexpected GTP config: %+v", cfg.GTP)
+	}
+
+	cfg.GTP.MaxVisits = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative GTP visits")
+	}
+}
+
+func TestToolSearchDefaults(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		tools map[string]ToolConfig
+		valid bool
+	}{
+		{"visits and time", map[string]ToolConfig{"explainMove": {MaxVisits: 400, MaxTime: 2}}, true},
+		{"visits only", map[string]ToolConfig{"sweepKomi": {MaxVisits: 100}}, true},
+		{"unknown tool", map[string]ToolConfig{"validateSGF": {MaxVisits: 100}}, false},
+		{"negative visits", map[string]ToolConfig{"keyMoments": {MaxVisits: -1}}, false},
+		{"negative time", map[string]ToolConfig{"analyzePosition": {MaxTime: -1}}, false},
+		{"time for a multi-position tool", map[string]ToolConfig{"findMistakes": {MaxTime: 5}}, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load("")
+			if err != nil {
+				t.Fatalf("Failed to load default config: %v", err)
+			}
+			cfg.Tools = tt.tools
+			if err := cfg.validate(); (err == nil) != tt.valid {
+				t.Errorf("Expected valid=%v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestSlackConfig(t *testing.T) {
+	t.Setenv("KATAGO_MCP_SLACK_ADDR", "127.0.0.1:3000")
+	if _, err := Load(""); err == nil {
+		t.Fatal("Expected error for a Slack address without credentials")
+	}
+
+	t.Setenv("KATAGO_MCP_SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("KATAGO_MCP_SLACK_SIGNING_SECRET", "secret")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Slack.Addr != "127.0.0.1:3000" || cfg.Slack.BotToken != "xoxb-test" || cfg.Slack.SigningSecret != "secret" {
+		t.Errorf("Unexpected Slack config: %+v", cfg.Slack)
+	}
+
+	cfg.Slack.MaxVisits = -1
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for negative Slack visits")
+	}
+}
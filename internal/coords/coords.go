@@ -0,0 +1,216 @@
+// Package coords converts board coordinates between the systems clients
+// use: GTP vertices such as "D4", which KataGo and the server speak
+// internally, SGF points such as "dd", and numeric "(x,y)" pairs.
+package coords
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// System is a coordinate system.
+type System string
+
+// Supported coordinate systems.
+const (
+	// GTP counts columns A-T without I from the left and rows from 1 at
+	// the bottom, as in "D4".
+	GTP System = "gtp"
+	// SGF uses two letters, column then row, from "a" at the top left, as
+	// in "dd". An empty point or "tt" on boards up to 19x19 is a pass.
+	SGF System = "sgf"
+	// XY is the zero-based column and row from the top left, as in "(3,15)",
+	// the numeric form KataGo accepts.
+	XY System = "xy"
+)
+
+// Systems lists the supported systems.
+func Systems() []string {
+	return []string{string(GTP), string(SGF), string(XY)}
+}
+
+// Parse returns the named system; an empty name is GTP.
+func Parse(name string) (System, error) {
+	switch System(strings.ToLower(strings.TrimSpace(name))) {
+	case "", GTP:
+		return GTP, nil
+	case SGF:
+		return SGF, nil
+	case XY:
+		return XY, nil
+	}
+	return "", apperrors.New(apperrors.CodeInvalidArgument, "unknown coordinate system %q: use %s", name, strings.Join(Systems(), ", "))
+}
+
+// Point parses a point in a system on a size x size board, returning its
+// zero-based column and row from the top left. Pass is reported as ok
+// false with no error.
+func (s System) Point(coord string, size int) (x, y int, ok bool, err error) {
+	coord = strings.TrimSpace(coord)
+	if strings.EqualFold(coord, "pass") || (s == SGF && (coord == "" || (coord == "tt" && size <= 19))) {
+		return 0, 0, false, nil
+	}
+	switch s {
+	case SGF:
+		if len(coord) == 2 {
+			x, y = sgfIndex(coord[0]), sgfIndex(coord[1])
+		} else {
+			x = -1
+		}
+	case XY:
+		x, y, err = parseXY(coord)
+		if err != nil {
+			return 0, 0, false, err
+		}
+	default:
+		x, y = gtpPoint(strings.ToUpper(coord), size)
+	}
+	if x < 0 || y < 0 || x >= size || y >= size {
+		return 0, 0, false, apperrors.New(apperrors.CodeBadCoordinate, "invalid %s coordinate %q on a %dx%d board", s, coord, size, size)
+	}
+	return x, y, true, nil
+}
+
+// Format names a point, given by its zero-based column and row from the
+// top left, in a system.
+func (s System) Format(x, y, size int) string {
+	switch s {
+	case SGF:
+		return string(sgfLetter(x)) + string(sgfLetter(y))
+	case XY:
+		return fmt.Sprintf("(%d,%d)", x, y)
+	}
+	return fmt.Sprintf("%c%d", gtpColumn(x), size-y)
+}
+
+// ToGTP converts a point or "pass" in a system to GTP.
+func (s System) ToGTP(coord string, size int) (string, error) {
+	if s == GTP || s == "" {
+		return coord, nil
+	}
+	x, y, ok, err := s.Point(coord, size)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "pass", nil
+	}
+	return GTP.Format(x, y, size), nil
+}
+
+// FromGTP converts a GTP vertex or "pass" to a system. Anything that is not
+// a vertex on the board is returned as it is.
+func (s System) FromGTP(vertex string, size int) string {
+	if s == GTP || s == "" {
+		return vertex
+	}
+	x, y, ok, err := GTP.Point(vertex, size)
+	if err != nil || !ok {
+		return vertex
+	}
+	return s.Format(x, y, size)
+}
+
+// RegionToGTP converts a move region in a system to GTP: a point, or a
+// rectangle given by two corners joined by "-". Anything else, such as the
+// named area "top-left", is returned as it is for the region parser to
+// read or reject.
+func (s System) RegionToGTP(region string, size int) string {
+	if s == GTP || s == "" {
+		return region
+	}
+	if from, to, found := strings.Cut(region, "-"); found {
+		fromGTP, fromErr := s.ToGTP(from, size)
+		toGTP, toErr := s.ToGTP(to, size)
+		if fromErr == nil && toErr == nil {
+			return fromGTP + "-" + toGTP
+		}
+		return region
+	}
+	if vertex, err := s.ToGTP(region, size); err == nil {
+		return vertex
+	}
+	return region
+}
+
+// gtpVertex matches what may be a GTP vertex in text.
+var gtpVertex = regexp.MustCompile(`\b[A-HJ-Z][1-9][0-9]?\b`)
+
+// ConvertText rewrites the GTP vertices on a size x size board in text,
+// such as a tool's response, to a system.
+func (s System) ConvertText(text string, size int) string {
+	if s == GTP || s == "" {
+		return text
+	}
+	return gtpVertex.ReplaceAllStringFunc(text, func(vertex string) string {
+		return s.FromGTP(vertex, size)
+	})
+}
+
+// gtpPoint parses an upper case GTP vertex, returning -1 for a column that
+// is not a letter.
+func gtpPoint(vertex string, size int) (x, y int) {
+	if len(vertex) < 2 {
+		return -1, -1
+	}
+	c := vertex[0]
+	switch {
+	case c < 'A' || c > 'Z' || c == 'I':
+		return -1, -1
+	case c > 'I':
+		x = int(c - 'A' - 1)
+	default:
+		x = int(c - 'A')
+	}
+	row, err := strconv.Atoi(vertex[1:])
+	if err != nil || vertex[1] < '1' || vertex[1] > '9' {
+		return -1, -1
+	}
+	return x, size - row
+}
+
+// gtpColumn returns the GTP letter of a zero-based column.
+func gtpColumn(x int) byte {
+	if x >= 8 {
+		return byte('A' + x + 1)
+	}
+	return byte('A' + x)
+}
+
+// sgfIndex returns the index of an SGF point letter: a-z, then A-Z.
+func sgfIndex(c byte) int {
+	switch {
+	case c >= 'a' && c <= 'z':
+		return int(c - 'a')
+	case c >= 'A' && c <= 'Z':
+		return int(c-'A') + 26
+	}
+	return -1
+}
+
+// sgfLetter returns the SGF letter of an index.
+func sgfLetter(i int) byte {
+	if i >= 26 {
+		return byte('A' + i - 26)
+	}
+	return byte('a' + i)
+}
+
+// parseXY parses "(x,y)", with or without the parentheses.
+func parseXY(coord string) (x, y int, err error) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(coord, "("), ")")
+	xs, ys, found := strings.Cut(inner, ",")
+	if !found {
+		return 0, 0, apperrors.New(apperrors.CodeBadCoordinate, "invalid xy coordinate %q: expected (x,y)", coord)
+	}
+	x, xErr := strconv.Atoi(strings.TrimSpace(xs))
+	y, yErr := strconv.Atoi(strings.TrimSpace(ys))
+	if xErr != nil || yErr != nil {
+		return 0, 0, apperrors.New(apperrors.CodeBadCoordinate, "invalid xy coordinate %q: expected (x,y)", coord)
+	}
+	return x, y, nil
+}
@@ -0,0 +1,108 @@
+package coords
+
+import (
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		system System
+		coord  string
+		size   int
+		gtp    string
+	}{
+		{SGF, "dd", 19, "D16"},
+		{SGF, "pd", 19, "Q16"},
+		{SGF, "aa", 9, "A9"},
+		{SGF, "ii", 9, "J1"},
+		{SGF, "tt", 19, "pass"},
+		{SGF, "", 19, "pass"},
+		{XY, "(3,15)", 19, "D4"},
+		{XY, "8, 0", 9, "J9"},
+		{XY, "pass", 19, "pass"},
+		{GTP, "d4", 19, "d4"},
+	}
+	for _, tt := range tests {
+		got, err := tt.system.ToGTP(tt.coord, tt.size)
+		if err != nil || got != tt.gtp {
+			t.Errorf("%s %q to GTP: expected %q, got %q, %v", tt.system, tt.coord, tt.gtp, got, err)
+		}
+		if tt.gtp == "pass" || tt.system == GTP {
+			continue
+		}
+		if back := tt.system.FromGTP(tt.gtp, tt.size); back != tt.system.Format(mustPoint(t, tt.system, tt.coord, tt.size)) {
+			t.Errorf("%s: %q did not convert back, got %q", tt.system, tt.gtp, back)
+		}
+	}
+
+	for _, tt := range []struct {
+		system System
+		coord  string
+		size   int
+	}{
+		{SGF, "zz", 19},
+		{SGF, "jj", 9},
+		{XY, "(19,0)", 19},
+		{XY, "3-4", 19},
+		{GTP, "I5", 19},
+		{GTP, "A20", 19},
+	} {
+		if _, err := tt.system.ToGTP(tt.coord, tt.size); tt.system != GTP && apperrors.CodeOf(err) != apperrors.CodeBadCoordinate {
+			t.Errorf("%s %q: expected %s, got %v", tt.system, tt.coord, apperrors.CodeBadCoordinate, err)
+		}
+		if _, _, _, err := tt.system.Point(tt.coord, tt.size); err == nil {
+			t.Errorf("%s %q: expected an error", tt.system, tt.coord)
+		}
+	}
+}
+
+func mustPoint(t *testing.T, s System, coord string, size int) (int, int, int) {
+	t.Helper()
+	x, y, _, err := s.Point(coord, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x, y, size
+}
+
+func TestRegionToGTP(t *testing.T) {
+	for region, want := range map[string]string{
+		"cc-ff":    "C17-F14",
+		"dd":       "D16",
+		"top-left": "top-left",
+		"center":   "center",
+	} {
+		if got := SGF.RegionToGTP(region, 19); got != want {
+			t.Errorf("%q: expected %q, got %q", region, want, got)
+		}
+	}
+	if got := XY.RegionToGTP("(0,0)-(4,4)", 9); got != "A9-E5" {
+		t.Errorf("Unexpected xy region: %q", got)
+	}
+}
+
+func TestConvertText(t *testing.T) {
+	text := `Best move D4 (W+2.5), then Q16 and pass; T20 and I5 are not vertices, nor is 2025-01-15T10:30. {"move": "K10"}`
+	if got := SGF.ConvertText(text, 19); got != `Best move dp (W+2.5), then pd and pass; T20 and I5 are not vertices, nor is 2025-01-15T10:30. {"move": "jj"}` {
+		t.Errorf("Unexpected SGF text: %s", got)
+	}
+	if got := XY.ConvertText("D4 and J9", 9); got != "(3,5) and (8,0)" {
+		t.Errorf("Unexpected xy text: %s", got)
+	}
+	if got := GTP.ConvertText(text, 19); got != text {
+		t.Errorf("Expected GTP text to be unchanged, got %s", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for name, want := range map[string]System{"": GTP, "GTP": GTP, "sgf": SGF, " xy ": XY} {
+		if got, err := Parse(name); err != nil || got != want {
+			t.Errorf("%q: expected %s, got %s, %v", name, want, got, err)
+		}
+	}
+	if _, err := Parse("ascii"); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for an unknown system, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
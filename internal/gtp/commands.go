@@ -0,0 +1,415 @@
+package gtp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+// commands are the commands the server answers, in list_commands order.
+var commands = []string{
+	"protocol_version", "name", "version", "known_command", "list_commands", "quit",
+	"boardsize", "clear_board", "komi", "play", "undo", "genmove", "showboard",
+	"final_score", "final_status_list", "kata-analyze", "time_settings", "time_left",
+}
+
+// maxBoardSize is the largest board whose points KataGo coordinates name.
+const maxBoardSize = 19
+
+// game is the position of a connection, built up by its commands.
+type game struct {
+	rules string
+	komi  float64
+	size  int
+	moves []katago.Move
+	board *katago.Board
+}
+
+// newGame creates an empty 19x19 game with the configured rules and komi.
+func newGame(cfg *config.GTPConfig) *game {
+	g := &game{rules: cfg.Rules, komi: cfg.Komi}
+	g.reset(19)
+	return g
+}
+
+// reset clears the board and sets its size.
+func (g *game) reset(size int) {
+	g.size = size
+	g.moves = nil
+	g.board = katago.NewBoard(size, size)
+}
+
+// play plays a move for color ("b" or "w") at a GTP vertex or "pass".
+func (g *game) play(color, vertex string) error {
+	location := strings.ToUpper(vertex)
+	if location == "PASS" {
+		location = ""
+	}
+	if err := g.board.Play(color, location); err != nil {
+		return err
+	}
+	g.moves = append(g.moves, katago.Move{Color: color, Location: location})
+	return nil
+}
+
+// undo takes back the last move.
+func (g *game) undo() error {
+	if len(g.moves) == 0 {
+		return errors.New("cannot undo")
+	}
+	g.moves = g.moves[:len(g.moves)-1]
+	board, err := katago.BoardFromPosition(&katago.Position{BoardXSize: g.size, BoardYSize: g.size, Moves: g.moves})
+	if err != nil {
+		return err
+	}
+	g.board = board
+	return nil
+}
+
+// toMove returns the color to play next, Black on an empty board.
+func (g *game) toMove() string {
+	if len(g.moves) > 0 && g.moves[len(g.moves)-1].Color == "b" {
+		return "w"
+	}
+	return "b"
+}
+
+// position returns the game as a position with color to move, adding a
+// pass by the other player if color played last.
+func (g *game) position(color string) *katago.Position {
+	position := &katago.Position{
+		Rules:         g.rules,
+		BoardXSize:    g.size,
+		BoardYSize:    g.size,
+		Komi:          g.komi,
+		Moves:         append([]katago.Move{}, g.moves...),
+		InitialPlayer: color,
+	}
+	if len(g.moves) > 0 {
+		position.InitialPlayer = g.moves[0].Color
+		if g.toMove() != color {
+			position.Moves = append(position.Moves, katago.Move{Color: opponent(color)})
+		}
+	}
+	return position
+}
+
+// connection is the state of one GTP client.
+type connection struct {
+	server   *Server
+	clientID string
+	game     *game
+}
+
+// execute answers every command but kata-analyze.
+func (c *connection) execute(ctx context.Context, name string, args []string) (string, error) {
+	switch name {
+	case "protocol_version":
+		return "2", nil
+	case "name":
+		return "katago-mcp", nil
+	case "version":
+		return c.server.engine.Capabilities().Version, nil
+	case "known_command":
+		if len(args) != 1 {
+			return "", errors.New("syntax error")
+		}
+		for _, command := range commands {
+			if command == args[0] {
+				return "true", nil
+			}
+		}
+		return "false", nil
+	case "list_commands":
+		return strings.Join(commands, "\n"), nil
+	case "quit":
+		return "", nil
+	case "time_settings", "time_left":
+		// Searches are bounded by visits rather than the clock
+		return "", nil
+	case "boardsize":
+		if len(args) != 1 {
+			return "", errors.New("syntax error")
+		}
+		size, err := strconv.Atoi(args[0])
+		if err != nil {
+			return "", errors.New("syntax error")
+		}
+		if size < 2 || size > maxBoardSize {
+			return "", errors.New("unacceptable size")
+		}
+		c.game.reset(size)
+		return "", nil
+	case "clear_board":
+		c.game.reset(c.game.size)
+		return "", nil
+	case "komi":
+		if len(args) != 1 {
+			return "", errors.New("syntax error")
+		}
+		komi, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return "", errors.New("syntax error")
+		}
+		c.game.komi = komi
+		return "", nil
+	case "play":
+		if len(args) != 2 {
+			return "", errors.New("syntax error")
+		}
+		color, err := parseColor(args[0])
+		if err != nil {
+			return "", err
+		}
+		return "", c.game.play(color, args[1])
+	case "undo":
+		return "", c.game.undo()
+	case "genmove":
+		return c.genmove(ctx, args)
+	case "showboard":
+		return "\n" + strings.TrimSuffix(c.game.board.String(), "\n"), nil
+	case "final_score":
+		return c.finalScore(ctx)
+	case "final_status_list":
+		return c.finalStatusList(ctx, args)
+	}
+	return "", errors.New("unknown command")
+}
+
+// query analyzes the game with color to move, if the rate limits allow
+// command.
+func (c *connection) query(ctx context.Context, command, color string, ownership bool) (*katago.AnalysisResult, error) {
+	if allowed, err := c.server.limiter.Allow(c.clientID, command); !allowed {
+		return nil, err
+	}
+	engine := c.server.engine
+	if !engine.IsRunning() {
+		if err := engine.Start(ctx); err != nil {
+			return nil, fmt.Errorf("failed to start engine: %w", err)
+		}
+	}
+	req := &katago.AnalysisRequest{
+		Position:         c.game.position(color),
+		IncludeOwnership: ownership,
+	}
+	if c.server.config.MaxVisits > 0 {
+		req.MaxVisits = &c.server.config.MaxVisits
+	}
+	return engine.Analyze(ctx, req)
+}
+
+// genmove plays and returns KataGo's best move for a color.
+func (c *connection) genmove(ctx context.Context, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("syntax error")
+	}
+	color, err := parseColor(args[0])
+	if err != nil {
+		return "", err
+	}
+	result, err := c.query(ctx, "genmove", color, false)
+	if err != nil {
+		return "", err
+	}
+
+	move := "pass"
+	if infos := sortedMoves(result); len(infos) > 0 {
+		move = infos[0].Move
+	}
+	if err := c.game.play(color, move); err != nil {
+		return "", err
+	}
+	return move, nil
+}
+
+// analyze answers kata-analyze with a single info line holding the
+// finished search, followed by its ownership when the arguments include
+// "ownership true". Win rates, scores and ownership are for the player to
+// move, as KataGo reports them by default. The interval argument is
+// ignored, since the search is not streamed.
+func (c *connection) analyze(ctx context.Context, args []string) (string, error) {
+	color := c.game.toMove()
+	if len(args) > 0 {
+		if parsed, err := parseColor(args[0]); err == nil {
+			color, args = parsed, args[1:]
+		}
+	}
+	ownership := false
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "ownership" && args[i+1] == "true" {
+			ownership = true
+		}
+	}
+	result, err := c.query(ctx, "kata-analyze", color, ownership)
+	if err != nil {
+		return "", err
+	}
+
+	sign := 1.0
+	if color == "w" {
+		sign = -1
+	}
+	forMover := func(winrate float64) float64 {
+		if color == "w" {
+			return 1 - winrate
+		}
+		return winrate
+	}
+	var entries []string
+	for _, mi := range sortedMoves(result) {
+		entries = append(entries, fmt.Sprintf("info move %s visits %d utility %.6f winrate %.6f scoreMean %.6f scoreStdev %.6f scoreLead %.6f prior %.6f lcb %.6f order %d pv %s",
+			mi.Move, mi.Visits, sign*mi.Utility, forMover(mi.Winrate), sign*mi.ScoreMean, mi.ScoreStdev,
+			sign*mi.ScoreLead, mi.Prior, forMover(mi.LCB), mi.Order, strings.Join(mi.PV, " ")))
+	}
+	if ownership && len(result.Ownership) > 0 {
+		values := make([]string, len(result.Ownership))
+		for i, v := range result.Ownership {
+			values[i] = strconv.FormatFloat(sign*v, 'f', 6, 64)
+		}
+		entries = append(entries, "ownership "+strings.Join(values, " "))
+	}
+	return strings.Join(entries, " "), nil
+}
+
+// finalScore returns KataGo's score lead for the game, as in "B+3.5".
+func (c *connection) finalScore(ctx context.Context) (string, error) {
+	result, err := c.query(ctx, "final_score", c.game.toMove(), false)
+	if err != nil {
+		return "", err
+	}
+	lead := result.RootInfo.ScoreLead
+	switch {
+	case lead >= 0.05:
+		return fmt.Sprintf("B+%.1f", lead), nil
+	case lead <= -0.05:
+		return fmt.Sprintf("W+%.1f", -lead), nil
+	}
+	return "0", nil
+}
+
+// finalStatusList lists the dead, alive or seki strings of stones, one per
+// line. Pass-alive strings are alive and strings in the other player's
+// pass-alive territory dead; otherwise a string is dead when KataGo expects
+// the other player to own its points on average. Strings in seki, sharing
+// a liberty neither player can fill, are neither dead nor alive.
+func (c *connection) finalStatusList(ctx context.Context, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("syntax error")
+	}
+	status := args[0]
+	switch status {
+	case "dead", "alive", "seki":
+	default:
+		return "", errors.New("syntax error")
+	}
+	result, err := c.query(ctx, "final_status_list", c.game.toMove(), true)
+	if err != nil {
+		return "", err
+	}
+	size := c.game.size
+	if len(result.Ownership) != size*size {
+		return "", errors.New("no ownership data returned")
+	}
+
+	passAlive := c.game.board.PassAlive()
+	seki := c.game.board.Seki(result.Ownership)
+	var lines []string
+	visited := make([]bool, size*size)
+	for point := range visited {
+		color := c.game.board.Stone(vertex(point, size))
+		if color == "" || visited[point] {
+			continue
+		}
+		stones := stringAt(c.game.board, point, size, visited)
+		var dead bool
+		switch {
+		case seki[point] || passAlive[point] == color:
+		case passAlive[point] != "":
+			dead = true
+		default:
+			owned := 0.0
+			for _, p := range stones {
+				owned += result.Ownership[p]
+			}
+			if color == "w" {
+				owned = -owned
+			}
+			dead = owned < 0
+		}
+		if seki[point] != (status == "seki") || status != "seki" && dead != (status == "dead") {
+			continue
+		}
+		vertices := make([]string, len(stones))
+		for i, p := range stones {
+			vertices[i] = vertex(p, size)
+		}
+		lines = append(lines, strings.Join(vertices, " "))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// stringAt returns the points of the string of stones at point, marking
+// them visited.
+func stringAt(board *katago.Board, point, size int, visited []bool) []int {
+	color := board.Stone(vertex(point, size))
+	stones := []int{point}
+	visited[point] = true
+	for i := 0; i < len(stones); i++ {
+		x, y := stones[i]%size, stones[i]/size
+		for _, n := range [][2]int{{x - 1, y}, {x + 1, y}, {x, y - 1}, {x, y + 1}} {
+			if n[0] < 0 || n[0] >= size || n[1] < 0 || n[1] >= size {
+				continue
+			}
+			next := n[1]*size + n[0]
+			if !visited[next] && board.Stone(vertex(next, size)) == color {
+				visited[next] = true
+				stones = append(stones, next)
+			}
+		}
+	}
+	sort.Ints(stones)
+	return stones
+}
+
+// sortedMoves returns a result's candidate moves, best first.
+func sortedMoves(result *katago.AnalysisResult) []katago.MoveInfo {
+	infos := append([]katago.MoveInfo(nil), result.MoveInfos...)
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Order < infos[j].Order
+	})
+	return infos
+}
+
+// vertex returns the GTP vertex of a point, numbered from the top left.
+func vertex(point, size int) string {
+	column := byte('A' + point%size)
+	if column >= 'I' {
+		column++
+	}
+	return fmt.Sprintf("%c%d", column, size-point/size)
+}
+
+// parseColor converts a GTP color to "b" or "w".
+func parseColor(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "b", "black":
+		return "b", nil
+	case "w", "white":
+		return "w", nil
+	}
+	return "", errors.New("syntax error")
+}
+
+// opponent returns the other color.
+func opponent(color string) string {
+	if color == "b" {
+		return "w"
+	}
+	return "b"
+}
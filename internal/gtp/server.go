@@ -0,0 +1,250 @@
+// Package gtp serves the Go Text Protocol, so Go GUIs such as Sabaki and
+// GoGui can use the server as an engine. Commands that need KataGo are
+// answered through the analysis engine, sharing its cache, rate limits and
+// supervisor with MCP clients.
+package gtp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/ratelimit"
+)
+
+// Server accepts GTP connections, each playing its own game.
+type Server struct {
+	config     *config.GTPConfig
+	engine     katago.EngineInterface
+	logger     logging.ContextLogger
+	limiter    *ratelimit.Limiter
+	namespaces *config.NamespaceConfig
+
+	listener net.Listener
+
+	mu          sync.Mutex
+	conns       map[net.Conn]struct{}
+	connections int // Connections accepted since Start
+	commands    int
+	errors      int
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// New creates a GTP server that analyzes positions with engine.
+func New(cfg *config.GTPConfig, engine katago.EngineInterface, logger logging.ContextLogger) *Server {
+	return &Server{
+		config: cfg,
+		engine: engine,
+		logger: logger,
+		conns:  make(map[net.Conn]struct{}),
+	}
+}
+
+// SetLimiter rate limits the commands that query the engine, counting
+// each connection as a client. Must be called before Start.
+func (s *Server) SetLimiter(limiter *ratelimit.Limiter) {
+	s.limiter = limiter
+}
+
+// SetNamespaces places connections in the namespaces of their client IDs,
+// "gtp:" followed by the remote host. Must be called before Start.
+func (s *Server) SetNamespaces(cfg *config.NamespaceConfig) {
+	s.namespaces = cfg
+}
+
+// Enabled reports whether a listen address is configured.
+func (s *Server) Enabled() bool {
+	return s.config.Addr != ""
+}
+
+// Start listens on the configured address and serves connections until
+// Stop is called. It fails if the configured rules or komi are not
+// accepted, and is a no-op when no address is configured.
+func (s *Server) Start() error {
+	if !s.Enabled() {
+		return nil
+	}
+	if err := katago.ValidatePosition(newGame(s.config).position("b")); err != nil {
+		return fmt.Errorf("gtp: %w", err)
+	}
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return fmt.Errorf("gtp listener: %w", err)
+	}
+	s.listener = listener
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			if ctx.Err() != nil {
+				// Stop has already closed the other connections
+				s.mu.Unlock()
+				conn.Close()
+				return
+			}
+			s.conns[conn] = struct{}{}
+			s.connections++
+			s.mu.Unlock()
+
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(ctx, conn)
+			}()
+		}
+	}()
+	return nil
+}
+
+// Addr returns the address the server listens on, or nil before Start.
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Stop closes the listener and every connection, cancelling commands in
+// progress, and waits for them to finish. It is safe to call more than
+// once.
+func (s *Server) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.once.Do(func() {
+		s.listener.Close()
+		s.mu.Lock()
+		s.cancel()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+	})
+	s.wg.Wait()
+}
+
+// GetStatus returns connection and command counts for health responses.
+func (s *Server) GetStatus() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := map[string]interface{}{
+		"enabled":     s.Enabled(),
+		"connections": len(s.conns),
+		"accepted":    s.connections,
+		"commands":    s.commands,
+		"failed":      s.errors,
+	}
+	if addr := s.Addr(); addr != nil {
+		status["addr"] = addr.String()
+	}
+	return status
+}
+
+// serve answers the commands of one connection until it quits or closes.
+func (s *Server) serve(ctx context.Context, conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		host = conn.RemoteAddr().String()
+	}
+	clientID := "gtp:" + host
+	if s.namespaces != nil && s.namespaces.Strict() {
+		ctx = katago.WithCacheNamespace(ctx, s.namespaces.Namespace(clientID))
+	}
+	s.logger.Info("GTP client connected", "client", clientID)
+	defer s.logger.Info("GTP client disconnected", "client", clientID)
+
+	c := &connection{server: s, clientID: clientID, game: newGame(s.config)}
+	scanner := bufio.NewScanner(conn)
+	w := bufio.NewWriter(conn)
+	analyzing := false
+	for scanner.Scan() {
+		id, name, args := parseCommand(scanner.Text())
+		if name == "" {
+			continue
+		}
+		// A command ends the output of kata-analyze
+		if analyzing {
+			w.WriteString("\n")
+			analyzing = false
+		}
+
+		var response string
+		if name == "kata-analyze" {
+			response, err = c.analyze(ctx, args)
+			analyzing = err == nil
+		} else {
+			response, err = c.execute(ctx, name, args)
+		}
+		s.mu.Lock()
+		s.commands++
+		if err != nil {
+			s.errors++
+		}
+		s.mu.Unlock()
+
+		switch {
+		case err != nil:
+			s.logger.Debug("GTP command failed", "client", clientID, "command", name, "error", err)
+			fmt.Fprintf(w, "?%s %s\n\n", id, strings.ReplaceAll(err.Error(), "\n", " "))
+		case analyzing:
+			fmt.Fprintf(w, "=%s\n%s\n", id, response)
+		default:
+			fmt.Fprintf(w, "=%s %s\n\n", id, response)
+		}
+		if err := w.Flush(); err != nil || name == "quit" {
+			return
+		}
+	}
+}
+
+// parseCommand splits a line into its optional ID, command name and
+// arguments, dropping control characters and comments.
+func parseCommand(line string) (id, name string, args []string) {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	line = strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return ' '
+		case r < ' ' || r == 127:
+			return -1
+		}
+		return r
+	}, line)
+	fields := strings.Fields(line)
+	if len(fields) > 0 {
+		if _, err := strconv.Atoi(fields[0]); err == nil {
+			id, fields = fields[0], fields[1:]
+		}
+	}
+	if len(fields) == 0 {
+		return id, "", nil
+	}
+	return id, fields[0], fields[1:]
+}
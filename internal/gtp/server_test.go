@@ -0,0 +1,167 @@
+package gtp
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/ratelimit"
+)
+
+// client sends commands to a test server and reads its responses.
+type client struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newTestServer(t *testing.T, result *katago.AnalysisResult, limiter *ratelimit.Limiter) (*client, *katago.MockEngine) {
+	t.Helper()
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(result, nil)
+	server := New(&config.GTPConfig{Addr: "127.0.0.1:0", Rules: "chinese", Komi: 7.5}, engine, logger)
+	server.SetLimiter(limiter)
+	if err := server.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(server.Stop)
+
+	conn, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	return &client{t: t, conn: conn, reader: bufio.NewReader(conn)}, engine
+}
+
+// send sends a command and returns its response up to the blank line that
+// ends it.
+func (c *client) send(command string) string {
+	c.t.Helper()
+	c.write(command)
+	var lines []string
+	for {
+		line := c.readLine()
+		if line == "\n" && len(lines) > 0 {
+			return strings.Join(lines, "")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func (c *client) write(command string) {
+	c.t.Helper()
+	if _, err := c.conn.Write([]byte(command + "\n")); err != nil {
+		c.t.Fatalf("Failed to send %q: %v", command, err)
+	}
+}
+
+func (c *client) readLine() string {
+	c.t.Helper()
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		c.t.Fatalf("Failed to read response: %v", err)
+	}
+	return line
+}
+
+func TestCommands(t *testing.T) {
+	c, engine := newTestServer(t, &katago.AnalysisResult{
+		RootInfo: katago.RootInfo{Visits: 100, Winrate: 0.4, ScoreLead: -2.5},
+		MoveInfos: []katago.MoveInfo{
+			{Move: "C3", Visits: 20, Winrate: 0.35, LCB: 0.3, Prior: 0.1, Order: 1, ScoreLead: -3, PV: []string{"C3"}},
+			{Move: "D4", Visits: 80, Winrate: 0.4, LCB: 0.38, Prior: 0.6, Order: 0, ScoreLead: -2.5, ScoreMean: -2.4, Utility: -0.1, PV: []string{"D4", "F6"}},
+		},
+	}, nil)
+
+	// In order, since the moves depend on the board size
+	for _, tt := range []struct{ command, want string }{
+		{"protocol_version", "= 2\n"},
+		{"7 name", "=7 katago-mcp\n"},
+		{"known_command genmove", "= true\n"},
+		{"known_command lz-genmove_analyze", "= false\n"},
+		{"boardsize 9 # a comment", "= \n"},
+		{"boardsize 25", "? unacceptable size\n"},
+		{"komi 6.5", "= \n"},
+		{"play black E5", "= \n"},
+		{"play w E5", "? illegal move E5: point is occupied\n"},
+		{"foo", "? unknown command\n"},
+	} {
+		if got := c.send(tt.command); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.command, tt.want, got)
+		}
+	}
+
+	if got := c.send("genmove b"); got != "= D4\n" {
+		t.Errorf("Expected genmove to play the best move, got %q", got)
+	}
+	req := engine.GetLastAnalyzeRequest()
+	if pos := req.Position; pos.BoardXSize != 9 || pos.Komi != 6.5 || len(pos.Moves) != 2 || pos.Moves[1].Location != "" {
+		t.Errorf("Expected a pass by White before Black's genmove, got %+v", pos)
+	}
+	if got := c.send("genmove b"); got != "? illegal move D4: point is occupied\n" {
+		t.Errorf("Expected D4 to have been played, got %q", got)
+	}
+
+	// White to move, so win rates and scores are turned around
+	c.write("kata-analyze 50")
+	if got := c.readLine(); got != "=\n" {
+		t.Errorf("Expected kata-analyze to succeed, got %q", got)
+	}
+	if got := c.readLine(); !strings.HasPrefix(got, "info move D4 visits 80 utility 0.100000 winrate 0.600000 scoreMean 2.400000 scoreStdev 0.000000 "+
+		"scoreLead 2.500000 prior 0.600000 lcb 0.620000 order 0 pv D4 F6 info move C3 ") {
+		t.Errorf("Unexpected kata-analyze output: %q", got)
+	}
+	if got := c.send("undo"); got != "\n= \n" {
+		t.Errorf("Expected a blank line to end kata-analyze output at the next command, got %q", got)
+	}
+	if got := c.send("final_score"); got != "= W+2.5\n" {
+		t.Errorf("Unexpected final score: %q", got)
+	}
+}
+
+func TestFinalStatusList(t *testing.T) {
+	// A 5x5 board that KataGo expects Black to own, with White stones on C3 and D5
+	ownership := make([]float64, 25)
+	for i := range ownership {
+		ownership[i] = 0.9
+	}
+	c, _ := newTestServer(t, &katago.AnalysisResult{Ownership: ownership}, nil)
+	for _, command := range []string{"boardsize 5", "play b B3", "play w C3", "play b C4", "play w D5", "play b D3"} {
+		c.send(command)
+	}
+	if got := c.send("final_status_list dead"); got != "= D5\nC3\n" {
+		t.Errorf("Unexpected dead stones: %q", got)
+	}
+	if got := c.send("final_status_list alive"); got != "= C4\nB3\nD3\n" {
+		t.Errorf("Unexpected alive stones: %q", got)
+	}
+	if got := c.send("final_status_list seki"); got != "= \n" {
+		t.Errorf("Expected no seki, got %q", got)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	limiter := ratelimit.NewLimiter(&config.RateLimitConfig{Enabled: true, RequestsPerMin: 60, BurstSize: 1}, logger)
+	c, _ := newTestServer(t, &katago.AnalysisResult{}, limiter)
+
+	if got := c.send("genmove b"); got != "= pass\n" {
+		t.Errorf("Expected a pass without candidates, got %q", got)
+	}
+	if got := c.send("genmove w"); !strings.HasPrefix(got, "? ") || !strings.Contains(got, "rate limit") {
+		t.Errorf("Expected the second genmove to be rate limited, got %q", got)
+	}
+	if got := c.send("play w D4"); got != "= \n" {
+		t.Errorf("Expected commands without a search to be allowed, got %q", got)
+	}
+}
@@ -3,6 +3,7 @@ package health
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"sync"
 	"time"
@@ -22,9 +23,16 @@ const (
 	StatusDegraded Status = "degraded"
 )
 
+// ErrDegraded marks a check failure that leaves the component usable.
+// Checks wrap it to report StatusDegraded instead of StatusUnhealthy.
+var ErrDegraded = errors.New("degraded")
+
 // Check represents a health check function.
 type Check func(ctx context.Context) error
 
+// StatsProvider returns runtime statistics to include in health responses.
+type StatsProvider func() map[string]interface{}
+
 // Component represents a system component with health status.
 type Component struct {
 	Name        string                 `json:"name"`
@@ -36,17 +44,19 @@ type Component struct {
 
 // Response represents the health check response.
 type Response struct {
-	Status     Status      `json:"status"`
-	Timestamp  time.Time   `json:"timestamp"`
-	Components []Component `json:"components,omitempty"`
-	Version    string      `json:"version,omitempty"`
-	GitCommit  string      `json:"git_commit,omitempty"`
+	Status     Status                 `json:"status"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Components []Component            `json:"components,omitempty"`
+	Version    string                 `json:"version,omitempty"`
+	GitCommit  string                 `json:"git_commit,omitempty"`
+	Stats      map[string]interface{} `json:"stats,omitempty"`
 }
 
 // Checker manages health checks for the application.
 type Checker struct {
 	logger    logging.ContextLogger
 	checks    map[string]Check
+	stats     map[string]StatsProvider
 	mu        sync.RWMutex
 	version   string
 	gitCommit string
@@ -57,6 +67,7 @@ func NewChecker(logger logging.ContextLogger, version, gitCommit string) *Checke
 	return &Checker{
 		logger:    logger,
 		checks:    make(map[string]Check),
+		stats:     make(map[string]StatsProvider),
 		version:   version,
 		gitCommit: gitCommit,
 	}
@@ -69,6 +80,28 @@ func (c *Checker) RegisterCheck(name string, check Check) {
 	c.checks[name] = check
 }
 
+// RegisterStats registers a provider of runtime statistics that is
+// included in liveness and readiness responses under the given name.
+func (c *Checker) RegisterStats(name string, provider StatsProvider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats[name] = provider
+}
+
+// collectStats gathers statistics from all registered providers.
+// Must be called with at least a read lock held.
+func (c *Checker) collectStats() map[string]interface{} {
+	if len(c.stats) == 0 {
+		return nil
+	}
+
+	stats := make(map[string]interface{}, len(c.stats))
+	for name, provider := range c.stats {
+		stats[name] = provider()
+	}
+	return stats
+}
+
 // CheckHealth performs all registered health checks.
 func (c *Checker) CheckHealth(ctx context.Context) Response {
 	c.mu.RLock()
@@ -80,6 +113,7 @@ func (c *Checker) CheckHealth(ctx context.Context) Response {
 		Version:    c.version,
 		GitCommit:  c.gitCommit,
 		Components: make([]Component, 0, len(c.checks)),
+		Stats:      c.collectStats(),
 	}
 
 	// If no checks registered, consider it healthy
@@ -112,9 +146,14 @@ func (c *Checker) CheckHealth(ctx context.Context) Response {
 			defer cancel()
 
 			if err := check(checkCtx); err != nil {
-				component.Status = StatusUnhealthy
 				component.Message = err.Error()
-				c.logger.WithField("component", name).Error("Health check failed", "error", err)
+				if errors.Is(err, ErrDegraded) {
+					component.Status = StatusDegraded
+					c.logger.WithField("component", name).Warn("Component degraded", "error", err)
+				} else {
+					component.Status = StatusUnhealthy
+					c.logger.WithField("component", name).Error("Health check failed", "error", err)
+				}
 			}
 
 			results <- result{name: name, component: component}
@@ -127,15 +166,21 @@ func (c *Checker) CheckHealth(ctx context.Context) Response {
 
 	// Collect results and determine overall status
 	hasUnhealthy := false
+	hasDegraded := false
 	for res := range results {
 		response.Components = append(response.Components, res.component)
-		if res.component.Status == StatusUnhealthy {
+		switch res.component.Status {
+		case StatusUnhealthy:
 			hasUnhealthy = true
+		case StatusDegraded:
+			hasDegraded = true
 		}
 	}
 
 	if hasUnhealthy {
 		response.Status = StatusUnhealthy
+	} else if hasDegraded {
+		response.Status = StatusDegraded
 	}
 
 	return response
@@ -148,11 +193,16 @@ func (c *Checker) LivenessHandler() http.HandlerFunc {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 
+		c.mu.RLock()
+		stats := c.collectStats()
+		c.mu.RUnlock()
+
 		response := Response{
 			Status:    StatusHealthy,
 			Timestamp: time.Now().UTC(),
 			Version:   c.version,
 			GitCommit: c.gitCommit,
+			Stats:     stats,
 		}
 
 		if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -176,9 +226,9 @@ func (c *Checker) ReadinessHandler() http.HandlerFunc {
 
 		w.Header().Set("Content-Type", "application/json")
 
-		// Set appropriate status code
+		// Set appropriate status code; a degraded server can still serve
 		statusCode := http.StatusOK
-		if response.Status != StatusHealthy {
+		if response.Status == StatusUnhealthy {
 			statusCode = http.StatusServiceUnavailable
 		}
 
@@ -119,3 +119,56 @@ func TestHealthCheckCallsEngine(t *testing.T) {
 		t.Errorf("Expected 2 ping calls, got %d", mockEngine.GetPingCallCount())
 	}
 }
+
+// TestHealthResponseIncludesStats verifies registered stats providers appear in responses.
+func TestHealthResponseIncludesStats(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test", "debug"))
+	checker := NewChecker(logger, "1.0.0", "abc123")
+
+	checker.RegisterStats("cache", func() map[string]interface{} {
+		return map[string]interface{}{
+			"items":   3,
+			"hitRate": 0.5,
+		}
+	})
+
+	response := checker.CheckHealth(context.Background())
+
+	cacheStats, ok := response.Stats["cache"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected cache stats in response, got %v", response.Stats)
+	}
+	if cacheStats["items"] != 3 {
+		t.Errorf("Expected 3 items, got %v", cacheStats["items"])
+	}
+	if cacheStats["hitRate"] != 0.5 {
+		t.Errorf("Expected hit rate 0.5, got %v", cacheStats["hitRate"])
+	}
+}
+
+// TestHealthCheckDegraded verifies that checks wrapping ErrDegraded report a degraded status.
+func TestHealthCheckDegraded(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test", "debug"))
+	checker := NewChecker(logger, "1.0.0", "abc123")
+
+	checker.RegisterCheck("resources", func(ctx context.Context) error {
+		return fmt.Errorf("%w: CPU usage too high", ErrDegraded)
+	})
+
+	response := checker.CheckHealth(context.Background())
+	if response.Status != StatusDegraded {
+		t.Errorf("Expected status %s, got %s", StatusDegraded, response.Status)
+	}
+	if len(response.Components) != 1 || response.Components[0].Status != StatusDegraded {
+		t.Errorf("Expected degraded component, got %+v", response.Components)
+	}
+
+	// An unhealthy component takes precedence over a degraded one
+	checker.RegisterCheck("katago", func(ctx context.Context) error {
+		return fmt.Errorf("engine not running")
+	})
+	response = checker.CheckHealth(context.Background())
+	if response.Status != StatusUnhealthy {
+		t.Errorf("Expected status %s, got %s", StatusUnhealthy, response.Status)
+	}
+}
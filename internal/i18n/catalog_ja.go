@@ -0,0 +1,193 @@
+package i18n
+
+// japanese translates messages into Japanese.
+var japanese = map[string]string{
+	// Explanations
+	"%s is KataGo's top choice (%.1f%% win rate, %.1f point lead)":                       "%sはKataGoの最善手です(勝率%.1f%%、%.1f目リード)",
+	"%s is nearly as good as the best move (%.1f%% win rate, rank #%d)":                  "%sは最善手とほぼ同等です(勝率%.1f%%、候補%d位)",
+	"%s is a reasonable move but slightly inferior (%.1f%% win rate, -%1.f%% from best)": "%sは妥当な手ですがやや劣ります(勝率%.1f%%、最善手から-%1.f%%)",
+	"%s is questionable, losing %.1f%% win rate compared to %s":                          "%[1]sは疑問手で、%[3]sと比べて勝率を%.1[2]f%%失います",
+	"This move loses %.1f%% win rate":                                                    "この手で勝率を%.1f%%失います",
+	"This move tenukis from the hot area around %s and loses %.1f%% win rate":            "この手は%sの急場を手抜きしており、勝率を%.1f%%失います",
+	"A brilliant find: KataGo's best move despite a %.1f%% policy prior":                 "妙手: 方策確率%.1f%%ながらKataGoの最善手",
+	"The key move: %.1f%% win rate better than %s":                                       "%[2]sより勝率が%.1[1]f%%高い急所の一手",
+	"KataGo's top choice":         "KataGoの最善手",
+	"Similar strength":            "ほぼ同等",
+	"Prefers %s over %s":          "%[2]sより%[1]sを優先",
+	"Alternative in %s":           "%sでの代替手",
+	"%.1f%% better":               "%.1f%%良い",
+	"Slightly different approach": "やや異なる方針",
+	"Well-explored by the engine": "エンジンが十分に読んでいる",
+	"Natural-looking move":        "自然な手",
+	"Nearly optimal":              "ほぼ最善",
+	"Maintains %.1f point lead":   "%.1f目のリードを保つ",
+	"Secures corner territory":    "隅の地を確保する",
+	"Develops along the side":     "辺に展開する",
+	"Playable move":               "打てる手",
+	"Loses %.1f%% win rate":       "勝率を%.1f%%失う",
+	"Unconventional choice":       "珍しい選択",
+	"Limited engine exploration":  "エンジンの読みが少ない",
+	"%s is better":                "%sの方が良い",
+	"Slightly suboptimal":         "やや最善に劣る",
+
+	// Categories, levels and strategic terms
+	"blunder":                                "大悪手",
+	"mistake":                                "悪手",
+	"inaccuracy":                             "緩手",
+	"brilliant":                              "妙手",
+	"excellent":                              "好手",
+	"Professional":                           "プロ",
+	"Strong Amateur (5d+)":                   "アマ高段者(5段以上)",
+	"Amateur Dan (1d-4d)":                    "アマ有段者(初段〜4段)",
+	"Strong Kyu (5k-1k)":                     "上級者(5級〜1級)",
+	"Mid Kyu (10k-6k)":                       "中級者(10級〜6級)",
+	"Weak Kyu (15k-11k)":                     "初中級者(15級〜11級)",
+	"Beginner (20k-16k)":                     "初心者(20級〜16級)",
+	"corner":                                 "隅",
+	"side":                                   "辺",
+	"center":                                 "中央",
+	"critical":                               "急場",
+	"important":                              "大場",
+	"optional":                               "緩やか",
+	"corner enclosure":                       "シマリ",
+	"side development":                       "辺の展開",
+	"local response":                         "応手",
+	"territory":                              "地",
+	"influence":                              "厚み",
+	"attack":                                 "攻め",
+	"defense":                                "守り",
+	"top":                                    "上辺",
+	"bottom":                                 "下辺",
+	"left":                                   "左辺",
+	"right":                                  "右辺",
+	"Ownership change":                       "所有権の変化",
+	"Secures about %.1f points of territory": "約%.1f目の地を確保する",
+	"Builds influence toward the %s":         "%s方向に厚みを築く",
+	"Attacks %s":                             "%sを攻める",
+	"Strengthens %s":                         "%sを補強する",
+	"Gives up about %.1f points of ownership": "所有権を約%.1f目失う",
+
+	// Go terms
+	"Go Terms":  "囲碁用語",
+	"tsuke":     "ツケ",
+	"hane":      "ハネ",
+	"shimari":   "シマリ",
+	"invasion":  "打ち込み",
+	"reduction": "消し",
+	"An attachment: a stone played in contact with an opponent's stone that has no friendly support nearby.":            "相手の石に直接接触して打つ手で、近くに自分の石の支えがないもの。",
+	"A stone played diagonally from one's own stone to bend around the head or side of an opponent's stone.":            "自分の石から斜めに打ち、相手の石の頭や横を回り込む手。",
+	"A corner enclosure: a second stone in a corner that secures it against approaches and invasions.":                  "隅の二つ目の石で、カカリや打ち込みから隅を守る手。",
+	"A stone played low, on the third line or below, inside an area the opponent has staked out, aiming to live there.": "相手の勢力圏内の三線以下に打ち込み、そこで生きることを狙う手。",
+	"A stone played high, on the fourth or fifth line, above the opponent's framework to shrink it from outside.":       "四線や五線の高い位置から相手の模様を外側から小さくする手。",
+
+	// explainMove labels
+	"Move Explanation: %s":    "着手の解説: %s",
+	"Statistics":              "統計",
+	"Win rate":                "勝率",
+	"Score lead":              "目数リード",
+	"%.1f points":             "%.1f目",
+	"Engine visits":           "探索数",
+	"Strategic Analysis":      "戦略分析",
+	"Board region":            "盤上の位置",
+	"Urgency":                 "緊急度",
+	"Purpose":                 "狙い",
+	"Pros":                    "長所",
+	"Cons":                    "短所",
+	"Better Alternatives":     "より良い候補手",
+	"Opponent's Best Replies": "相手の最善の応手",
+	"Expect %s next.":         "次は%sが予想されます。",
+	"Cuts %s from %s":         "%sと%sを切断する",
+	"Captures %s":             "%sを取る",
+	"Puts %s in atari":        "%sをアタリにする",
+	"%.1f%% WR":               "勝率%.1f%%",
+
+	// findMistakes labels
+	"Game Review":            "対局の検討",
+	"Black: %s vs White: %s": "黒: %s 対 白: %s",
+	"result %s":              "結果 %s",
+	"Summary":                "概要",
+	"Total moves":            "総手数",
+	"**Partial review**: deadline reached after %d of %d moves":                             "**途中までの検討**: %[2]d手中%[1]d手で時間切れ",
+	"**Decided** after move %d: later moves were analyzed at minimal visits and not judged": "**勝負あり**: %d手目で勝敗が決まりました。以降の手は最小限の探索のみで評価していません",
+	"Black accuracy":                               "黒の正確度",
+	"White accuracy":                               "白の正確度",
+	"Black top-1/top-3 agreement":                  "黒の一致率(1位/3位以内)",
+	"White top-1/top-3 agreement":                  "白の一致率(1位/3位以内)",
+	"Average policy of moves played (Black/White)": "着手の平均方策確率(黒/白)",
+	"Black mistakes/blunders":                      "黒の悪手/大悪手",
+	"White mistakes/blunders":                      "白の悪手/大悪手",
+	"Tenuki from hot areas (Black/White)":          "急場の手抜き(黒/白)",
+	"Black mistakes in hard/easy positions":        "難しい/易しい局面での黒の悪手",
+	"White mistakes in hard/easy positions":        "難しい/易しい局面での白の悪手",
+	"Estimated level":                              "推定棋力",
+	"Recorded result":                              "記録された結果",
+	"KataGo's estimate":                            "KataGoの推定",
+	"Result disputed":                              "結果に疑義あり",
+	"The game was decided off the board, so the final position does not decide the result": "対局は盤外で決着したため、最終局面では結果を判定できません",
+	"The player who resigned had a %.1f%% win rate in the final position":                  "投了した側は最終局面で勝率%.1f%%でした",
+	"The game was recorded as a draw but KataGo counts %s":                                 "持碁と記録されていますが、KataGoの計算では%sです",
+	"The recorded winner is behind in the final position; KataGo counts %s":                "記録された勝者は最終局面で劣勢です。KataGoの計算では%sです",
+	"The recorded margin differs from KataGo's count of %s by %.1f points":                 "記録された差はKataGoの計算%sと%.1f目違います",
+	"Mistakes Found":                 "見つかった悪手",
+	"Move %d (%s)":                   "%d手目(%s)",
+	"B":                              "黒",
+	"W":                              "白",
+	"Category":                       "分類",
+	"Played":                         "着手",
+	"Better":                         "より良い手",
+	"Win rate drop":                  "勝率の低下",
+	"Difficulty":                     "難易度",
+	"hard":                           "難",
+	"easy":                           "易",
+	"No significant mistakes found!": "大きな悪手は見つかりませんでした!",
+	"Tenuki From Hot Areas":          "急場の手抜き",
+	"Best Moves":                     "好手・妙手",
+	"Existing Annotations":           "既存の注釈",
+	"Analyzer Findings":              "解析プラグインの指摘",
+	"Repeated Positions":             "同一局面の再現",
+	"These moves recreated an earlier position, as in a triple ko. A position repeated with the same player to move was analyzed once.": "三コウのように、以前の局面を再現した手です。手番も同じ局面は一度だけ解析しました。",
+	"Move %d (%s) repeats the starting position":      "%d手目(%s)は開始局面を再現しています",
+	"Move %d (%s) repeats the position after move %d": "%d手目(%s)は%d手目の後の局面を再現しています",
+	"Time Pressure": "持ち時間と悪手",
+	"Time control":  "持ち時間",
+	"Time left":     "残り時間",
+	"Black moves":   "黒の手数",
+	"White moves":   "白の手数",
+	"under 30s":     "30秒未満",
+	"30s to 2m":     "30秒〜2分",
+	"2m to 10m":     "2分〜10分",
+	"over 10m":      "10分以上",
+	"Black":         "黒",
+	"White":         "白",
+	"Most blunders by %s (%d of %d) came with under 30 seconds left":                        "%sの大悪手の大半(%d/%d)は残り30秒未満で打たれました",
+	"%s erred on %.0f%% of moves with under 30 seconds left, against %.0f%% with more time": "%sは残り30秒未満の手の%.0f%%で悪手を打ちましたが、時間に余裕のある手では%.0f%%でした",
+	"Comment":             "コメント",
+	"Variation":           "変化図",
+	"The SGF suggests %s": "SGFの変化図は%sを示しています",
+	"After the variation": "変化図の後",
+	"Black win rate %.1f%%, score lead %+.1f":                                                   "黒の勝率%.1f%%、目数差%+.1f",
+	"KataGo agrees: %s is its first choice":                                                     "KataGoも同意: %sが第一候補です",
+	"KataGo prefers %s by %.1f points (%.1f%% win rate)":                                        "KataGoは%sを%.1f目(勝率%.1f%%)上と見ています",
+	"KataGo prefers %s and did not consider %s":                                                 "KataGoは%sを推奨し、%sは候補にありません",
+	"These moves were played elsewhere while KataGo's top choices were all in one urgent area.": "KataGoの候補手がすべて一つの急場に集まっているのに、他の場所に打たれた手です。",
+	"Hot area":                 "急場",
+	"%s (best: %s, %.1f%% WR)": "%s(最善手: %s、勝率%.1f%%)",
+	"Listed %d of %d blunders, starting at number %d.":                             "%[2]d件中%[1]d件の大悪手を%[3]d番目から表示しました。",
+	"Listed %d of %d mistakes, starting at number %d.":                             "%[2]d件中%[1]d件の悪手を%[3]d番目から表示しました。",
+	"The costliest are listed first.":                                              "損失の大きい順に表示しています。",
+	"Call findMistakes with offset %d for the next ones.":                          "続きはfindMistakesをoffset %dで呼び出してください。",
+	"Call getMoveDetails with a move number for KataGo's candidates at that move.": "手の番号を指定してgetMoveDetailsを呼び出すと、その局面のKataGoの候補手を確認できます。",
+
+	// Mistake themes
+	"Theme":             "テーマ",
+	"Themes to Work On": "取り組むべきテーマ",
+	"Direction of play": "打つ方向",
+	"Life and death":    "死活",
+	"Endgame counting":  "ヨセの計算",
+	"Overplay":          "無理手",
+	"%d mistakes (moves %s), %.1f%% win rate lost":                                                                "悪手%d回(%s手目)、勝率%.1f%%を失いました",
+	"Before answering locally, compare the largest open areas of the whole board and which way your stones face.": "部分的に応じる前に、盤全体で最も大きい空き地と石の向きを比べましょう。",
+	"Read out whether groups live before leaving or attacking them; life-and-death problems help most.":           "石を手抜きしたり攻めたりする前に、生きているかを読み切りましょう。詰碁の練習が最も効果的です。",
+	"Count what each boundary move is worth, and play sente moves and the largest gote moves first.":              "境界の手の価値を数え、先手と最も大きい後手から打ちましょう。",
+	"These moves went into the opponent's strength and died; reduce from outside, or strengthen first.":           "相手の強い所に入って取られました。外から消すか、先に自分を強くしましょう。",
+}
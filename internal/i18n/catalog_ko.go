@@ -0,0 +1,193 @@
+package i18n
+
+// korean translates messages into Korean.
+var korean = map[string]string{
+	// Explanations
+	"%s is KataGo's top choice (%.1f%% win rate, %.1f point lead)":                       "%s은(는) KataGo의 최선수입니다 (승률 %.1f%%, %.1f집 우세)",
+	"%s is nearly as good as the best move (%.1f%% win rate, rank #%d)":                  "%s은(는) 최선수와 거의 같습니다 (승률 %.1f%%, 후보 %d위)",
+	"%s is a reasonable move but slightly inferior (%.1f%% win rate, -%1.f%% from best)": "%s은(는) 무난한 수이지만 조금 못합니다 (승률 %.1f%%, 최선수 대비 -%1.f%%)",
+	"%s is questionable, losing %.1f%% win rate compared to %s":                          "%[1]s은(는) 의문수로, %[3]s에 비해 승률을 %.1[2]f%% 잃습니다",
+	"This move loses %.1f%% win rate":                                                    "이 수로 승률을 %.1f%% 잃습니다",
+	"This move tenukis from the hot area around %s and loses %.1f%% win rate":            "이 수는 %s 부근의 급소를 손빼어 승률을 %.1f%% 잃습니다",
+	"A brilliant find: KataGo's best move despite a %.1f%% policy prior":                 "묘수: 정책 확률 %.1f%%에도 KataGo의 최선의 수",
+	"The key move: %.1f%% win rate better than %s":                                       "%[2]s보다 승률이 %.1[1]f%% 높은 급소",
+	"KataGo's top choice":         "KataGo의 최선수",
+	"Similar strength":            "비슷한 수준",
+	"Prefers %s over %s":          "%[2]s보다 %[1]s 선호",
+	"Alternative in %s":           "%s의 대안",
+	"%.1f%% better":               "%.1f%% 더 좋음",
+	"Slightly different approach": "조금 다른 방향",
+	"Well-explored by the engine": "엔진이 충분히 탐색함",
+	"Natural-looking move":        "자연스러운 수",
+	"Nearly optimal":              "거의 최선",
+	"Maintains %.1f point lead":   "%.1f집 우세 유지",
+	"Secures corner territory":    "귀의 집을 확보",
+	"Develops along the side":     "변으로 전개",
+	"Playable move":               "둘 만한 수",
+	"Loses %.1f%% win rate":       "승률 %.1f%% 손해",
+	"Unconventional choice":       "흔치 않은 선택",
+	"Limited engine exploration":  "엔진 탐색이 적음",
+	"%s is better":                "%s이(가) 더 좋음",
+	"Slightly suboptimal":         "최선에 조금 못 미침",
+
+	// Categories, levels and strategic terms
+	"blunder":                                "대악수",
+	"mistake":                                "악수",
+	"inaccuracy":                             "완착",
+	"brilliant":                              "묘수",
+	"excellent":                              "호수",
+	"Professional":                           "프로",
+	"Strong Amateur (5d+)":                   "아마 고단자 (5단 이상)",
+	"Amateur Dan (1d-4d)":                    "아마 유단자 (1단-4단)",
+	"Strong Kyu (5k-1k)":                     "상급자 (5급-1급)",
+	"Mid Kyu (10k-6k)":                       "중급자 (10급-6급)",
+	"Weak Kyu (15k-11k)":                     "초중급자 (15급-11급)",
+	"Beginner (20k-16k)":                     "입문자 (20급-16급)",
+	"corner":                                 "귀",
+	"side":                                   "변",
+	"center":                                 "중앙",
+	"critical":                               "급소",
+	"important":                              "큰 자리",
+	"optional":                               "여유",
+	"corner enclosure":                       "굳힘",
+	"side development":                       "변 전개",
+	"local response":                         "응수",
+	"territory":                              "실리",
+	"influence":                              "세력",
+	"attack":                                 "공격",
+	"defense":                                "수비",
+	"top":                                    "상변",
+	"bottom":                                 "하변",
+	"left":                                   "좌변",
+	"right":                                  "우변",
+	"Ownership change":                       "소유권 변화",
+	"Secures about %.1f points of territory": "약 %.1f집의 실리를 확보",
+	"Builds influence toward the %s":         "%s 방향으로 세력을 쌓음",
+	"Attacks %s":                             "%s 공격",
+	"Strengthens %s":                         "%s 보강",
+	"Gives up about %.1f points of ownership": "소유권 약 %.1f집 손해",
+
+	// Go terms
+	"Go Terms":  "바둑 용어",
+	"tsuke":     "붙임",
+	"hane":      "젖힘",
+	"shimari":   "굳힘",
+	"invasion":  "침입",
+	"reduction": "삭감",
+	"An attachment: a stone played in contact with an opponent's stone that has no friendly support nearby.":            "상대 돌에 바로 붙여 두는 수로, 근처에 자기 돌의 도움이 없는 것.",
+	"A stone played diagonally from one's own stone to bend around the head or side of an opponent's stone.":            "자기 돌에서 대각선으로 두어 상대 돌의 머리나 옆을 돌아 감싸는 수.",
+	"A corner enclosure: a second stone in a corner that secures it against approaches and invasions.":                  "귀의 두 번째 돌로, 걸침과 침입으로부터 귀를 지키는 수.",
+	"A stone played low, on the third line or below, inside an area the opponent has staked out, aiming to live there.": "상대 세력권 안의 3선 이하에 들어가 살기를 노리는 수.",
+	"A stone played high, on the fourth or fifth line, above the opponent's framework to shrink it from outside.":       "4선이나 5선의 높은 곳에서 상대 모양을 바깥에서 줄이는 수.",
+
+	// explainMove labels
+	"Move Explanation: %s":    "착수 해설: %s",
+	"Statistics":              "통계",
+	"Win rate":                "승률",
+	"Score lead":              "집 차이",
+	"%.1f points":             "%.1f집",
+	"Engine visits":           "탐색 수",
+	"Strategic Analysis":      "전략 분석",
+	"Board region":            "위치",
+	"Urgency":                 "긴급도",
+	"Purpose":                 "목적",
+	"Pros":                    "장점",
+	"Cons":                    "단점",
+	"Better Alternatives":     "더 좋은 후보수",
+	"Opponent's Best Replies": "상대의 최선 응수",
+	"Expect %s next.":         "다음에는 %s이(가) 예상됩니다.",
+	"Cuts %s from %s":         "%s와(과) %s을(를) 끊음",
+	"Captures %s":             "%s을(를) 잡음",
+	"Puts %s in atari":        "%s을(를) 단수로 몲",
+	"%.1f%% WR":               "승률 %.1f%%",
+
+	// findMistakes labels
+	"Game Review":            "대국 복기",
+	"Black: %s vs White: %s": "흑: %s 대 백: %s",
+	"result %s":              "결과 %s",
+	"Summary":                "요약",
+	"Total moves":            "총 수",
+	"**Partial review**: deadline reached after %d of %d moves":                             "**부분 복기**: %[2]d수 중 %[1]d수에서 시간 초과",
+	"**Decided** after move %d: later moves were analyzed at minimal visits and not judged": "**승부 결정**: %d수에서 승부가 결정되어 이후의 수는 최소 탐색으로만 분석하고 평가하지 않았습니다",
+	"Black accuracy":                               "흑 정확도",
+	"White accuracy":                               "백 정확도",
+	"Black top-1/top-3 agreement":                  "흑 일치율 (1순위/3순위 이내)",
+	"White top-1/top-3 agreement":                  "백 일치율 (1순위/3순위 이내)",
+	"Average policy of moves played (Black/White)": "착수의 평균 정책 확률 (흑/백)",
+	"Black mistakes/blunders":                      "흑 악수/대악수",
+	"White mistakes/blunders":                      "백 악수/대악수",
+	"Tenuki from hot areas (Black/White)":          "급소 손빼기 (흑/백)",
+	"Black mistakes in hard/easy positions":        "어려운/쉬운 국면에서 흑의 실수",
+	"White mistakes in hard/easy positions":        "어려운/쉬운 국면에서 백의 실수",
+	"Estimated level":                              "추정 기력",
+	"Recorded result":                              "기록된 결과",
+	"KataGo's estimate":                            "KataGo 추정",
+	"Result disputed":                              "결과 이의",
+	"The game was decided off the board, so the final position does not decide the result": "대국이 반상 밖에서 결정되어 최종 국면으로 결과를 판정할 수 없습니다",
+	"The player who resigned had a %.1f%% win rate in the final position":                  "기권한 쪽은 최종 국면에서 승률 %.1f%%였습니다",
+	"The game was recorded as a draw but KataGo counts %s":                                 "무승부로 기록되었지만 KataGo의 계산은 %s입니다",
+	"The recorded winner is behind in the final position; KataGo counts %s":                "기록된 승자가 최종 국면에서 불리합니다. KataGo의 계산은 %s입니다",
+	"The recorded margin differs from KataGo's count of %s by %.1f points":                 "기록된 차이가 KataGo의 계산 %s와 %.1f집 다릅니다",
+	"Mistakes Found":                 "발견된 악수",
+	"Move %d (%s)":                   "%d수 (%s)",
+	"B":                              "흑",
+	"W":                              "백",
+	"Category":                       "분류",
+	"Played":                         "착수",
+	"Better":                         "더 좋은 수",
+	"Win rate drop":                  "승률 하락",
+	"Difficulty":                     "난이도",
+	"hard":                           "어려움",
+	"easy":                           "쉬움",
+	"No significant mistakes found!": "큰 악수가 발견되지 않았습니다!",
+	"Tenuki From Hot Areas":          "급소 손빼기",
+	"Best Moves":                     "좋은 수",
+	"Existing Annotations":           "기존 주석",
+	"Analyzer Findings":              "분석 플러그인의 지적",
+	"Repeated Positions":             "반복된 국면",
+	"These moves recreated an earlier position, as in a triple ko. A position repeated with the same player to move was analyzed once.": "삼패처럼 이전 국면을 다시 만든 수입니다. 차례까지 같은 국면은 한 번만 분석했습니다.",
+	"Move %d (%s) repeats the starting position":      "%d수 (%s)는 시작 국면을 반복합니다",
+	"Move %d (%s) repeats the position after move %d": "%d수 (%s)는 %d수 이후의 국면을 반복합니다",
+	"Time Pressure": "시간 압박",
+	"Time control":  "제한 시간",
+	"Time left":     "남은 시간",
+	"Black moves":   "흑 수",
+	"White moves":   "백 수",
+	"under 30s":     "30초 미만",
+	"30s to 2m":     "30초~2분",
+	"2m to 10m":     "2분~10분",
+	"over 10m":      "10분 이상",
+	"Black":         "흑",
+	"White":         "백",
+	"Most blunders by %s (%d of %d) came with under 30 seconds left":                        "%s의 대악수 대부분(%d/%d)은 남은 시간 30초 미만에서 나왔습니다",
+	"%s erred on %.0f%% of moves with under 30 seconds left, against %.0f%% with more time": "%s은(는) 남은 시간 30초 미만의 수 중 %.0f%%에서 실수했고, 시간 여유가 있을 때는 %.0f%%였습니다",
+	"Comment":             "코멘트",
+	"Variation":           "변화도",
+	"The SGF suggests %s": "SGF 변화도는 %s을(를) 제시합니다",
+	"After the variation": "변화도 이후",
+	"Black win rate %.1f%%, score lead %+.1f":                                                   "흑 승률 %.1f%%, 집 차이 %+.1f",
+	"KataGo agrees: %s is its first choice":                                                     "KataGo도 동의: %s이(가) 첫 번째 후보입니다",
+	"KataGo prefers %s by %.1f points (%.1f%% win rate)":                                        "KataGo는 %s을(를) %.1f집(승률 %.1f%%) 더 선호합니다",
+	"KataGo prefers %s and did not consider %s":                                                 "KataGo는 %s을(를) 선호하며 %s은(는) 후보로 보지 않았습니다",
+	"These moves were played elsewhere while KataGo's top choices were all in one urgent area.": "KataGo의 후보수가 모두 한 급소에 모여 있는데 다른 곳에 둔 수입니다.",
+	"Hot area":                 "급소",
+	"%s (best: %s, %.1f%% WR)": "%s (최선수: %s, 승률 %.1f%%)",
+	"Listed %d of %d blunders, starting at number %d.":                             "%[2]d개 중 %[1]d개의 대악수를 %[3]d번째부터 표시했습니다.",
+	"Listed %d of %d mistakes, starting at number %d.":                             "%[2]d개 중 %[1]d개의 악수를 %[3]d번째부터 표시했습니다.",
+	"The costliest are listed first.":                                              "손실이 큰 순서로 표시합니다.",
+	"Call findMistakes with offset %d for the next ones.":                          "다음 항목은 offset %d로 findMistakes를 호출하세요.",
+	"Call getMoveDetails with a move number for KataGo's candidates at that move.": "수 번호와 함께 getMoveDetails를 호출하면 그 수에서 KataGo의 후보수를 볼 수 있습니다.",
+
+	// Mistake themes
+	"Theme":             "주제",
+	"Themes to Work On": "보완할 주제",
+	"Direction of play": "방향",
+	"Life and death":    "사활",
+	"Endgame counting":  "끝내기 계산",
+	"Overplay":          "무리수",
+	"%d mistakes (moves %s), %.1f%% win rate lost":                                                                "실수 %d회(%s수), 승률 %.1f%% 손실",
+	"Before answering locally, compare the largest open areas of the whole board and which way your stones face.": "국지적으로 받기 전에 판 전체에서 가장 큰 빈 곳과 돌의 방향을 비교하세요.",
+	"Read out whether groups live before leaving or attacking them; life-and-death problems help most.":           "돌을 손빼거나 공격하기 전에 사는지 수읽기하세요. 사활 문제 연습이 가장 도움이 됩니다.",
+	"Count what each boundary move is worth, and play sente moves and the largest gote moves first.":              "경계의 각 수의 가치를 계산하고 선수와 가장 큰 후수부터 두세요.",
+	"These moves went into the opponent's strength and died; reduce from outside, or strengthen first.":           "상대의 강한 곳에 들어가 잡혔습니다. 밖에서 삭감하거나 먼저 보강하세요.",
+}
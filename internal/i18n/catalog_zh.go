@@ -0,0 +1,193 @@
+package i18n
+
+// chinese translates messages into Simplified Chinese.
+var chinese = map[string]string{
+	// Explanations
+	"%s is KataGo's top choice (%.1f%% win rate, %.1f point lead)":                       "%s是KataGo的首选(胜率%.1f%%,领先%.1f目)",
+	"%s is nearly as good as the best move (%.1f%% win rate, rank #%d)":                  "%s与最佳着法几乎相当(胜率%.1f%%,排名第%d)",
+	"%s is a reasonable move but slightly inferior (%.1f%% win rate, -%1.f%% from best)": "%s是合理的着法但稍逊(胜率%.1f%%,比最佳低%1.f%%)",
+	"%s is questionable, losing %.1f%% win rate compared to %s":                          "%[1]s是疑问手,与%[3]s相比损失%.1[2]f%%胜率",
+	"This move loses %.1f%% win rate":                                                    "此手损失%.1f%%胜率",
+	"This move tenukis from the hot area around %s and loses %.1f%% win rate":            "此手脱先了%s附近的急所,损失%.1f%%胜率",
+	"A brilliant find: KataGo's best move despite a %.1f%% policy prior":                 "妙手: 策略概率仅%.1f%%却是KataGo的最佳着法",
+	"The key move: %.1f%% win rate better than %s":                                       "要点: 胜率比%[2]s高%.1[1]f%%",
+	"KataGo's top choice":         "KataGo的首选",
+	"Similar strength":            "强度相近",
+	"Prefers %s over %s":          "相比%[2]s更倾向%[1]s",
+	"Alternative in %s":           "%s的替代着法",
+	"%.1f%% better":               "好%.1f%%",
+	"Slightly different approach": "思路略有不同",
+	"Well-explored by the engine": "引擎已充分计算",
+	"Natural-looking move":        "自然的着法",
+	"Nearly optimal":              "接近最佳",
+	"Maintains %.1f point lead":   "保持领先%.1f目",
+	"Secures corner territory":    "确保角地",
+	"Develops along the side":     "沿边展开",
+	"Playable move":               "可下的着法",
+	"Loses %.1f%% win rate":       "损失%.1f%%胜率",
+	"Unconventional choice":       "非常规的选择",
+	"Limited engine exploration":  "引擎计算较少",
+	"%s is better":                "%s更好",
+	"Slightly suboptimal":         "略逊于最佳",
+
+	// Categories, levels and strategic terms
+	"blunder":                                "大恶手",
+	"mistake":                                "恶手",
+	"inaccuracy":                             "缓手",
+	"brilliant":                              "妙手",
+	"excellent":                              "好手",
+	"Professional":                           "职业",
+	"Strong Amateur (5d+)":                   "业余高段(5段以上)",
+	"Amateur Dan (1d-4d)":                    "业余段位(1段-4段)",
+	"Strong Kyu (5k-1k)":                     "高级(5级-1级)",
+	"Mid Kyu (10k-6k)":                       "中级(10级-6级)",
+	"Weak Kyu (15k-11k)":                     "初中级(15级-11级)",
+	"Beginner (20k-16k)":                     "入门(20级-16级)",
+	"corner":                                 "角",
+	"side":                                   "边",
+	"center":                                 "中腹",
+	"critical":                               "急所",
+	"important":                              "大场",
+	"optional":                               "缓",
+	"corner enclosure":                       "守角",
+	"side development":                       "边上展开",
+	"local response":                         "局部应对",
+	"territory":                              "实地",
+	"influence":                              "厚势",
+	"attack":                                 "攻击",
+	"defense":                                "防守",
+	"top":                                    "上边",
+	"bottom":                                 "下边",
+	"left":                                   "左边",
+	"right":                                  "右边",
+	"Ownership change":                       "归属变化",
+	"Secures about %.1f points of territory": "确保约%.1f目实地",
+	"Builds influence toward the %s":         "向%s方向构筑厚势",
+	"Attacks %s":                             "攻击%s",
+	"Strengthens %s":                         "加强%s",
+	"Gives up about %.1f points of ownership": "归属损失约%.1f目",
+
+	// Go terms
+	"Go Terms":  "围棋术语",
+	"tsuke":     "碰",
+	"hane":      "扳",
+	"shimari":   "守角",
+	"invasion":  "打入",
+	"reduction": "浅消",
+	"An attachment: a stone played in contact with an opponent's stone that has no friendly support nearby.":            "紧贴对方棋子落下、附近没有己方棋子支援的一手。",
+	"A stone played diagonally from one's own stone to bend around the head or side of an opponent's stone.":            "从己方棋子斜向落子,绕过对方棋子头部或侧面的一手。",
+	"A corner enclosure: a second stone in a corner that secures it against approaches and invasions.":                  "角上的第二颗子,防止对方挂角和打入。",
+	"A stone played low, on the third line or below, inside an area the opponent has staked out, aiming to live there.": "在对方势力范围内的三线或更低处落子,以求做活。",
+	"A stone played high, on the fourth or fifth line, above the opponent's framework to shrink it from outside.":       "在四线或五线的高处从外侧压缩对方模样的一手。",
+
+	// explainMove labels
+	"Move Explanation: %s":    "着法解说: %s",
+	"Statistics":              "统计",
+	"Win rate":                "胜率",
+	"Score lead":              "目差",
+	"%.1f points":             "%.1f目",
+	"Engine visits":           "计算量",
+	"Strategic Analysis":      "战略分析",
+	"Board region":            "棋盘位置",
+	"Urgency":                 "紧迫度",
+	"Purpose":                 "目的",
+	"Pros":                    "优点",
+	"Cons":                    "缺点",
+	"Better Alternatives":     "更好的选点",
+	"Opponent's Best Replies": "对方的最佳应手",
+	"Expect %s next.":         "预计对方下一手是%s。",
+	"Cuts %s from %s":         "切断%s与%s",
+	"Captures %s":             "提掉%s",
+	"Puts %s in atari":        "打吃%s",
+	"%.1f%% WR":               "胜率%.1f%%",
+
+	// findMistakes labels
+	"Game Review":            "对局复盘",
+	"Black: %s vs White: %s": "黑方: %s 对 白方: %s",
+	"result %s":              "结果 %s",
+	"Summary":                "概要",
+	"Total moves":            "总手数",
+	"**Partial review**: deadline reached after %d of %d moves":                             "**部分复盘**: 共%[2]d手,在第%[1]d手时超时",
+	"**Decided** after move %d: later moves were analyzed at minimal visits and not judged": "**胜负已定**: 第%d手后胜负已定,之后的着法仅以最少访问数分析,不作评判",
+	"Black accuracy":                               "黑棋准确率",
+	"White accuracy":                               "白棋准确率",
+	"Black top-1/top-3 agreement":                  "黑方吻合率(第一/前三)",
+	"White top-1/top-3 agreement":                  "白方吻合率(第一/前三)",
+	"Average policy of moves played (Black/White)": "所下着法的平均策略概率(黑/白)",
+	"Black mistakes/blunders":                      "黑棋恶手/大恶手",
+	"White mistakes/blunders":                      "白棋恶手/大恶手",
+	"Tenuki from hot areas (Black/White)":          "急所脱先(黑/白)",
+	"Black mistakes in hard/easy positions":        "黑在难/易局面的失误",
+	"White mistakes in hard/easy positions":        "白在难/易局面的失误",
+	"Estimated level":                              "估计棋力",
+	"Recorded result":                              "记录的结果",
+	"KataGo's estimate":                            "KataGo的估计",
+	"Result disputed":                              "结果存疑",
+	"The game was decided off the board, so the final position does not decide the result": "对局在盘外决定胜负,终局局面无法判定结果",
+	"The player who resigned had a %.1f%% win rate in the final position":                  "认输一方在终局局面的胜率为%.1f%%",
+	"The game was recorded as a draw but KataGo counts %s":                                 "记录为和棋,但KataGo的计算为%s",
+	"The recorded winner is behind in the final position; KataGo counts %s":                "记录的胜方在终局局面落后,KataGo的计算为%s",
+	"The recorded margin differs from KataGo's count of %s by %.1f points":                 "记录的差距与KataGo的计算%s相差%.1f目",
+	"Mistakes Found":                 "发现的恶手",
+	"Move %d (%s)":                   "第%d手(%s)",
+	"B":                              "黑",
+	"W":                              "白",
+	"Category":                       "类别",
+	"Played":                         "实战",
+	"Better":                         "更好",
+	"Win rate drop":                  "胜率下降",
+	"Difficulty":                     "难度",
+	"hard":                           "难",
+	"easy":                           "易",
+	"No significant mistakes found!": "未发现明显恶手!",
+	"Tenuki From Hot Areas":          "急所脱先",
+	"Best Moves":                     "好棋",
+	"Existing Annotations":           "已有注释",
+	"Analyzer Findings":              "分析插件的发现",
+	"Repeated Positions":             "重复局面",
+	"These moves recreated an earlier position, as in a triple ko. A position repeated with the same player to move was analyzed once.": "这些着法重现了之前的局面,如三劫循环。轮走方也相同的局面只分析一次。",
+	"Move %d (%s) repeats the starting position":      "第%d手(%s)重现了初始局面",
+	"Move %d (%s) repeats the position after move %d": "第%d手(%s)重现了第%d手后的局面",
+	"Time Pressure": "时间压力",
+	"Time control":  "用时",
+	"Time left":     "剩余时间",
+	"Black moves":   "黑方手数",
+	"White moves":   "白方手数",
+	"under 30s":     "不足30秒",
+	"30s to 2m":     "30秒至2分",
+	"2m to 10m":     "2分至10分",
+	"over 10m":      "10分以上",
+	"Black":         "黑方",
+	"White":         "白方",
+	"Most blunders by %s (%d of %d) came with under 30 seconds left":                        "%s的大恶手大多(%d/%d)出现在剩余时间不足30秒时",
+	"%s erred on %.0f%% of moves with under 30 seconds left, against %.0f%% with more time": "%s在剩余时间不足30秒时有%.0f%%的着法出错,时间充裕时为%.0f%%",
+	"Comment":             "评论",
+	"Variation":           "变化图",
+	"The SGF suggests %s": "SGF 变化图建议 %s",
+	"After the variation": "变化之后",
+	"Black win rate %.1f%%, score lead %+.1f":                                                   "黑方胜率 %.1f%%，目差 %+.1f",
+	"KataGo agrees: %s is its first choice":                                                     "KataGo 同意：%s 是首选",
+	"KataGo prefers %s by %.1f points (%.1f%% win rate)":                                        "KataGo 更喜欢 %s，领先 %.1f 目（胜率 %.1f%%）",
+	"KataGo prefers %s and did not consider %s":                                                 "KataGo 更喜欢 %s，并未考虑 %s",
+	"These moves were played elsewhere while KataGo's top choices were all in one urgent area.": "KataGo的候选着法都集中在一处急所时,这些着法却下在了别处。",
+	"Hot area":                 "急所",
+	"%s (best: %s, %.1f%% WR)": "%s(最佳: %s,胜率%.1f%%)",
+	"Listed %d of %d blunders, starting at number %d.":                             "已列出%[2]d个大恶手中的%[1]d个，从第%[3]d个开始。",
+	"Listed %d of %d mistakes, starting at number %d.":                             "已列出%[2]d个恶手中的%[1]d个，从第%[3]d个开始。",
+	"The costliest are listed first.":                                              "按损失从大到小排列。",
+	"Call findMistakes with offset %d for the next ones.":                          "以offset %d调用findMistakes获取后续内容。",
+	"Call getMoveDetails with a move number for KataGo's candidates at that move.": "以手数调用getMoveDetails可查看KataGo在该手的候选着法。",
+
+	// Mistake themes
+	"Theme":             "主题",
+	"Themes to Work On": "需要加强的主题",
+	"Direction of play": "行棋方向",
+	"Life and death":    "死活",
+	"Endgame counting":  "官子计算",
+	"Overplay":          "无理手",
+	"%d mistakes (moves %s), %.1f%% win rate lost":                                                                "失误%d次(第%s手),损失胜率%.1f%%",
+	"Before answering locally, compare the largest open areas of the whole board and which way your stones face.": "在局部应对之前,比较全盘最大的空地以及棋子的朝向。",
+	"Read out whether groups live before leaving or attacking them; life-and-death problems help most.":           "在脱先或攻击之前,先算清棋块的死活;做死活题最有帮助。",
+	"Count what each boundary move is worth, and play sente moves and the largest gote moves first.":              "计算每个边界官子的价值,先下先手和最大的后手。",
+	"These moves went into the opponent's strength and died; reduce from outside, or strengthen first.":           "这些手打入对方厚势后被吃;应从外面消,或先补强自己。",
+}
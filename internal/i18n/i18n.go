@@ -0,0 +1,102 @@
+// Package i18n translates the explanations and labels in analysis output.
+// Messages are looked up by their English text, so English needs no
+// catalog and a message missing from a catalog falls back to English.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// DefaultLanguage is the language messages are written in.
+const DefaultLanguage = "en"
+
+// catalogs maps a language to translations keyed by English message.
+var catalogs = map[string]map[string]string{
+	"ja": japanese,
+	"ko": korean,
+	"zh": chinese,
+}
+
+// Languages returns the supported language codes, English first.
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs)+1)
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return append([]string{DefaultLanguage}, langs...)
+}
+
+// Normalize returns the supported language for a code such as "ja" or
+// "ja-JP", or an error if there is none. An empty code is English.
+func Normalize(lang string) (string, error) {
+	base := strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(base, "-_"); i >= 0 {
+		base = base[:i]
+	}
+	if base == "" || base == DefaultLanguage {
+		return DefaultLanguage, nil
+	}
+	if _, ok := catalogs[base]; !ok {
+		return "", apperrors.New(apperrors.CodeInvalidArgument, "unsupported language %q (supported: %s)",
+			lang, strings.Join(Languages(), ", "))
+	}
+	return base, nil
+}
+
+// Printer formats messages in one language.
+type Printer struct {
+	lang    string
+	catalog map[string]string
+}
+
+// English formats messages untranslated.
+var English = &Printer{lang: DefaultLanguage}
+
+// NewPrinter returns a printer for a language code, as accepted by
+// Normalize.
+func NewPrinter(lang string) (*Printer, error) {
+	lang, err := Normalize(lang)
+	if err != nil {
+		return nil, err
+	}
+	return &Printer{lang: lang, catalog: catalogs[lang]}, nil
+}
+
+// Language returns the printer's language code.
+func (p *Printer) Language() string {
+	return p.lang
+}
+
+// T translates a message without arguments.
+func (p *Printer) T(msg string) string {
+	if translated, ok := p.catalog[msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Sprintf translates a format string and formats it with args.
+func (p *Printer) Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(p.T(format), args...)
+}
+
+type printerKey struct{}
+
+// WithPrinter returns a context whose explanations are written by p.
+func WithPrinter(ctx context.Context, p *Printer) context.Context {
+	return context.WithValue(ctx, printerKey{}, p)
+}
+
+// FromContext returns the printer carried by ctx, or English.
+func FromContext(ctx context.Context) *Printer {
+	if p, ok := ctx.Value(printerKey{}).(*Printer); ok && p != nil {
+		return p
+	}
+	return English
+}
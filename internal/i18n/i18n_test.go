@@ -0,0 +1,116 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+var verbPattern = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]*)?[sdf]`)
+
+// sampleArgs returns distinct arguments for the verbs in an English
+// message, and their English formatting.
+func sampleArgs(msg string) (args []interface{}, formatted []string) {
+	for i, verb := range verbPattern.FindAllString(strings.ReplaceAll(msg, "%%", ""), -1) {
+		var arg interface{}
+		switch verb[len(verb)-1] {
+		case 's':
+			arg = fmt.Sprintf("S%d", i)
+		case 'd':
+			arg = 10 + i
+		case 'f':
+			arg = 1.25 + float64(i)
+		}
+		args = append(args, arg)
+		formatted = append(formatted, fmt.Sprintf(verb, arg))
+	}
+	return args, formatted
+}
+
+func TestCatalogsFormat(t *testing.T) {
+	for lang, catalog := range catalogs {
+		p, err := NewPrinter(lang)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for msg, translated := range catalog {
+			if translated == "" {
+				t.Errorf("%s: empty translation for %q", lang, msg)
+				continue
+			}
+			args, formatted := sampleArgs(msg)
+			got := p.Sprintf(msg, args...)
+			if strings.Contains(got, "%!") {
+				t.Errorf("%s: %q formats badly: %s", lang, msg, got)
+			}
+			for _, want := range formatted {
+				if !strings.Contains(got, want) {
+					t.Errorf("%s: %q drops argument %s: %s", lang, msg, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "en"},
+		{"en", "en"},
+		{"en-US", "en"},
+		{"ja", "ja"},
+		{"ja-JP", "ja"},
+		{"KO", "ko"},
+		{"zh_CN", "zh"},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.in)
+		if err != nil {
+			t.Errorf("Normalize(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	_, err := Normalize("xx")
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Normalize(xx) error = %v, want invalid argument", err)
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	p, err := NewPrinter("ja")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.T("blunder"); got != "大悪手" {
+		t.Errorf("T(blunder) = %q", got)
+	}
+	if got := p.T("not in any catalog"); got != "not in any catalog" {
+		t.Errorf("untranslated message = %q, want English", got)
+	}
+	if got := English.Sprintf("Loses %.1f%% win rate", 12.34); got != "Loses 12.3% win rate" {
+		t.Errorf("English.Sprintf = %q", got)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != English {
+		t.Errorf("FromContext without a printer = %v, want English", got.Language())
+	}
+	p, _ := NewPrinter("ko")
+	if got := FromContext(WithPrinter(context.Background(), p)).Language(); got != "ko" {
+		t.Errorf("FromContext language = %q, want ko", got)
+	}
+	if langs := Languages(); langs[0] != DefaultLanguage || len(langs) != 4 {
+		t.Errorf("Languages() = %v", langs)
+	}
+}
@@ -3,7 +3,11 @@ package katago
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
 )
 
 // AnalysisRequest represents a request to analyze a position.
@@ -14,14 +18,33 @@ type AnalysisRequest struct {
 	// Analysis parameters (override defaults if specified)
 	MaxVisits *int     `json:"maxVisits,omitempty"`
 	MaxTime   *float64 `json:"maxTime,omitempty"`
+	// Komi overrides the position's komi, allowing a komi of zero
+	Komi *float64 `json:"komi,omitempty"`
 
 	// Optional parameters
-	IncludePolicy         bool     `json:"includePolicy,omitempty"`
-	IncludeOwnership      bool     `json:"includeOwnership,omitempty"`
-	IncludeMovesOwnership bool     `json:"includeMovesOwnership,omitempty"`
-	IncludePVVisits       bool     `json:"includePVVisits,omitempty"`
-	AvoidMoves            []string `json:"avoidMoves,omitempty"`
-	AllowMoves            []string `json:"allowMoves,omitempty"`
+	IncludePolicy         bool `json:"includePolicy,omitempty"`
+	IncludeOwnership      bool `json:"includeOwnership,omitempty"`
+	IncludeMovesOwnership bool `json:"includeMovesOwnership,omitempty"`
+	IncludePVVisits       bool `json:"includePVVisits,omitempty"`
+	// IncludeScoreDistribution requests ownership and its standard
+	// deviation, from which NewScoreDistribution finds contested points
+	IncludeScoreDistribution bool     `json:"includeScoreDistribution,omitempty"`
+	AvoidMoves               []string `json:"avoidMoves,omitempty"`
+	AllowMoves               []string `json:"allowMoves,omitempty"`
+	// RestrictionDepth applies AvoidMoves to both players for this many
+	// moves of the search; zero restricts the root move only. KataGo takes
+	// a single allowMoves entry, so it cannot be combined with AllowMoves.
+	RestrictionDepth int `json:"restrictionDepth,omitempty"`
+
+	// Human SL profile (e.g. "rank_5k"); requires a human model
+	HumanProfile string `json:"humanProfile,omitempty"`
+
+	// Search settings; unset fields use the engine's configured values
+	Search config.SearchConfig `json:"search,omitempty"`
+
+	// AverageSymmetries analyzes the position under every board symmetry
+	// and averages the results
+	AverageSymmetries bool `json:"averageSymmetries,omitempty"`
 }
 
 // AnalysisResult represents the analysis result.
@@ -35,26 +58,67 @@ type AnalysisResult struct {
 	// Policy prior (if requested) - neural network's move probabilities
 	Policy []float64 `json:"policy,omitempty"`
 
+	// Human SL policy (if requested with a human profile)
+	HumanPolicy []float64 `json:"humanPolicy,omitempty"`
+
 	// Ownership map (if requested)
 	Ownership []float64 `json:"ownership,omitempty"`
 
+	// Standard deviation of each point's ownership across the search (if
+	// requested with IncludeScoreDistribution)
+	OwnershipStdev []float64 `json:"ownershipStdev,omitempty"`
+
 	// Move-specific ownership (if requested)
 	MovesOwnership map[string][][]float64 `json:"movesOwnership,omitempty"`
 }
 
 // Analyze analyzes a position using KataGo.
 func (e *Engine) Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+	if req.AverageSymmetries {
+		if err := ValidatePosition(req.Position); err != nil {
+			return nil, fmt.Errorf("invalid position: %w", err)
+		}
+		return analyzeSymmetries(ctx, e.Analyze, req)
+	}
+
+	// Apply the engine's search settings without modifying the caller's request
+	withDefaults := *req
+	if e.config != nil {
+		withDefaults.Search = req.Search.Merge(e.config.Search)
+	}
+
+	query, err := buildAnalysisQuery(&withDefaults)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send query with caching
+	resp, err := e.sendQueryWithCache(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return analysisResult(req, resp)
+}
+
+// buildAnalysisQuery validates a request and converts it to a KataGo query.
+func buildAnalysisQuery(req *AnalysisRequest) (map[string]interface{}, error) {
 	// Validate request
 	if err := ValidatePosition(req.Position); err != nil {
 		return nil, fmt.Errorf("invalid position: %w", err)
 	}
 
-	// Build query - analysis engine doesn't use "action" field
+	// Build query - analysis engine doesn't use "action" field. PV visits
+	// are always requested, so that results with and without them share
+	// a cache entry, and left out of results that did not ask for them.
 	query := map[string]interface{}{
 		"includePolicy":         req.IncludePolicy,
-		"includeOwnership":      req.IncludeOwnership,
+		"includeOwnership":      req.IncludeOwnership || req.IncludeScoreDistribution,
 		"includeMovesOwnership": req.IncludeMovesOwnership,
-		"includePVVisits":       req.IncludePVVisits,
+		"includePVVisits":       true,
+	}
+	if req.IncludeScoreDistribution {
+		query["includeOwnershipStdev"] = true
 	}
 
 	// Add position data
@@ -62,7 +126,9 @@ func (e *Engine) Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisRe
 	query["boardXSize"] = req.Position.BoardXSize
 	query["boardYSize"] = req.Position.BoardYSize
 
-	if req.Position.Komi != 0 {
+	if req.Komi != nil {
+		query["komi"] = *req.Komi
+	} else if req.Position.Komi != 0 {
 		query["komi"] = req.Position.Komi
 	}
 
@@ -72,7 +138,7 @@ func (e *Engine) Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisRe
 		for i, stone := range req.Position.InitialStones {
 			// Validate stone location format
 			if !isValidMoveFormat(stone.Location, req.Position.BoardXSize) {
-				return nil, fmt.Errorf("invalid initial stone location at index %d: %s", i, stone.Location)
+				return nil, apperrors.New(apperrors.CodeBadCoordinate, "invalid initial stone location at index %d: %s", i, stone.Location)
 			}
 			stones[i] = []interface{}{stone.Color, stone.Location}
 		}
@@ -87,15 +153,16 @@ func (e *Engine) Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisRe
 		} else {
 			// Validate move format
 			if !isValidMoveFormat(move.Location, req.Position.BoardXSize) {
-				return nil, fmt.Errorf("invalid move format at index %d: %s", i, move.Location)
+				return nil, apperrors.New(apperrors.CodeBadCoordinate, "invalid move format at index %d: %s", i, move.Location)
 			}
 			moves[i] = []interface{}{move.Color, move.Location}
 		}
 	}
 	query["moves"] = moves
 
-	// Add initial player only if there are moves
-	if req.Position.InitialPlayer != "" && len(req.Position.Moves) > 0 {
+	// Add initial player, which is also the player to move in a position
+	// without moves
+	if req.Position.InitialPlayer != "" {
 		query["initialPlayer"] = req.Position.InitialPlayer
 	}
 
@@ -107,28 +174,82 @@ func (e *Engine) Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisRe
 		query["maxTime"] = *req.MaxTime
 	}
 
-	// Add move restrictions
-	if len(req.AvoidMoves) > 0 {
-		avoid := make([]map[string]interface{}, len(req.AvoidMoves))
-		for i, move := range req.AvoidMoves {
-			avoid[i] = map[string]interface{}{
-				"moves":      []string{move},
-				"untilDepth": 1,
+	// Add move restrictions for the player to move. They apply to the root
+	// move only, so the search still reads replies anywhere on the board,
+	// unless a restriction depth confines both players to the avoid list.
+	if len(req.AvoidMoves) > 0 || len(req.AllowMoves) > 0 {
+		player := nextPlayer(req.Position)
+		if len(req.AvoidMoves) > 0 {
+			if err := validateRestrictedMoves("avoid", req.AvoidMoves, req.Position.BoardXSize); err != nil {
+				return nil, err
+			}
+			avoid := []map[string]interface{}{
+				{"player": player, "moves": req.AvoidMoves, "untilDepth": 1},
+			}
+			if req.RestrictionDepth > 1 {
+				avoid = []map[string]interface{}{
+					{"player": player, "moves": req.AvoidMoves, "untilDepth": req.RestrictionDepth},
+					{"player": opponent(player), "moves": req.AvoidMoves, "untilDepth": req.RestrictionDepth},
+				}
+			}
+			query["avoidMoves"] = avoid
+		}
+		if len(req.AllowMoves) > 0 {
+			if req.RestrictionDepth > 1 {
+				return nil, apperrors.New(apperrors.CodeInvalidArgument, "a restriction depth cannot be combined with allowed moves")
+			}
+			if err := validateRestrictedMoves("allow", req.AllowMoves, req.Position.BoardXSize); err != nil {
+				return nil, err
+			}
+			query["allowMoves"] = []map[string]interface{}{
+				{"player": player, "moves": req.AllowMoves, "untilDepth": 1},
 			}
 		}
-		query["avoidMoves"] = avoid
 	}
 
-	if len(req.AllowMoves) > 0 {
-		query["allowMoves"] = req.AllowMoves
+	overrides := map[string]interface{}{}
+
+	// Condition the human SL model on a rank or era
+	if req.HumanProfile != "" {
+		if err := ValidateHumanProfile(req.HumanProfile); err != nil {
+			return nil, err
+		}
+		overrides["humanSLProfile"] = req.HumanProfile
 	}
 
-	// Send query with caching
-	resp, err := e.sendQueryWithCache(query)
-	if err != nil {
-		return nil, err
+	// Trade search sharpness for diversity of suggested moves
+	if err := req.Search.Validate(); err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "invalid search settings")
+	}
+	if req.Search.WideRootNoise != nil {
+		overrides["wideRootNoise"] = *req.Search.WideRootNoise
+	}
+	if req.Search.RootPolicyTemperature != nil {
+		overrides["rootPolicyTemperature"] = *req.Search.RootPolicyTemperature
+	}
+	if req.Search.AnalysisPVLen != nil {
+		overrides["analysisPVLen"] = *req.Search.AnalysisPVLen
+	}
+
+	if len(overrides) > 0 {
+		query["overrideSettings"] = overrides
+	}
+
+	return query, nil
+}
+
+// validateRestrictedMoves checks the coordinates of an avoid or allow list.
+func validateRestrictedMoves(kind string, moves []string, boardSize int) error {
+	for i, move := range moves {
+		if !isValidMoveFormat(move, boardSize) {
+			return apperrors.New(apperrors.CodeBadCoordinate, "invalid %s move at index %d: %s", kind, i, move)
+		}
 	}
+	return nil
+}
 
+// analysisResult converts a KataGo response to an analysis result.
+func analysisResult(req *AnalysisRequest, resp *Response) (*AnalysisResult, error) {
 	// Check for error in response
 	if resp.Error != nil {
 		switch v := resp.Error.(type) {
@@ -147,6 +268,14 @@ func (e *Engine) Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisRe
 		MoveInfos: resp.MoveInfos,
 		RootInfo:  resp.RootInfo,
 	}
+	if !req.IncludePVVisits && len(resp.MoveInfos) > 0 {
+		// Copied, since the response may be cached
+		result.MoveInfos = make([]MoveInfo, len(resp.MoveInfos))
+		copy(result.MoveInfos, resp.MoveInfos)
+		for i := range result.MoveInfos {
+			result.MoveInfos[i].PVVisits, result.MoveInfos[i].PVEdgeVisits = nil, nil
+		}
+	}
 
 	// Extract additional data from raw response
 	if req.IncludePolicy {
@@ -158,9 +287,17 @@ func (e *Engine) Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisRe
 				}
 			}
 		}
+		if humanPolicyData, ok := resp.Raw["humanPolicy"].([]interface{}); ok {
+			result.HumanPolicy = make([]float64, len(humanPolicyData))
+			for i, item := range humanPolicyData {
+				if val, ok := item.(float64); ok {
+					result.HumanPolicy[i] = val
+				}
+			}
+		}
 	}
 
-	if req.IncludeOwnership {
+	if req.IncludeOwnership || req.IncludeScoreDistribution {
 		if ownershipData, ok := resp.Raw["ownership"].([]interface{}); ok {
 			result.Ownership = make([]float64, len(ownershipData))
 			for i, val := range ownershipData {
@@ -171,6 +308,17 @@ func (e *Engine) Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisRe
 		}
 	}
 
+	if req.IncludeScoreDistribution {
+		if stdevData, ok := resp.Raw["ownershipStdev"].([]interface{}); ok {
+			result.OwnershipStdev = make([]float64, len(stdevData))
+			for i, val := range stdevData {
+				if v, ok := val.(float64); ok {
+					result.OwnershipStdev[i] = v
+				}
+			}
+		}
+	}
+
 	if req.IncludeMovesOwnership {
 		if movesOwnershipData, ok := resp.Raw["movesOwnership"].(map[string]interface{}); ok {
 			result.MovesOwnership = make(map[string][][]float64)
@@ -218,7 +366,64 @@ func (e *Engine) AnalyzeSGF(ctx context.Context, sgfContent string, moveNum int)
 	return e.Analyze(ctx, req)
 }
 
+// Move sort orders for analysis output.
+const (
+	SortByVisits = "visits" // Most visited first
+	SortByLCB    = "lcb"    // Highest win rate lower confidence bound first
+)
+
+// SortMoveInfos returns a copy of the candidate moves ordered by visits or
+// LCB. Ties keep KataGo's order. The input is not modified, since it may be
+// shared with the cache.
+func SortMoveInfos(moves []MoveInfo, by string) []MoveInfo {
+	sorted := append([]MoveInfo(nil), moves...)
+	switch by {
+	case SortByLCB:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].LCB > sorted[j].LCB
+		})
+	case SortByVisits:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Visits > sorted[j].Visits
+		})
+	}
+	return sorted
+}
+
+// pvSpeculativeShare is the share of a PV's first move visits below which
+// a later move of the line is marked as speculative: KataGo searched it too
+// little to stand behind it.
+const pvSpeculativeShare = 0.1
+
+// formatPV writes a move's principal variation, up to 11 moves. When the
+// PV's visits are known, each move is followed by its visits and moves
+// searched too little to trust are marked with "?", e.g.
+// "D4(60) Q16(41) C3(4)?".
+func formatPV(move MoveInfo) string {
+	var sb strings.Builder
+	for j, pv := range move.PV {
+		if j > 0 {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(pv)
+		if j < len(move.PVVisits) {
+			sb.WriteString(fmt.Sprintf("(%d)", move.PVVisits[j]))
+			if float64(move.PVVisits[j]) < pvSpeculativeShare*float64(move.PVVisits[0]) {
+				sb.WriteString("?")
+			}
+		}
+		if j >= 10 {
+			sb.WriteString("...")
+			break
+		}
+	}
+	return sb.String()
+}
+
 // FormatAnalysisResult formats an analysis result as human-readable text.
+// Verbose output is a table including LCB, utility, score stdev and prior,
+// with PVs annotated with their visits when requested, plus the root's raw
+// network evaluation.
 func FormatAnalysisResult(result *AnalysisResult, verbose bool, boardSize int) string {
 	var sb strings.Builder
 
@@ -228,36 +433,55 @@ func FormatAnalysisResult(result *AnalysisResult, verbose bool, boardSize int) s
 	sb.WriteString(fmt.Sprintf("Visits: %d\n", result.RootInfo.Visits))
 	sb.WriteString(fmt.Sprintf("Win rate: %.1f%%\n", result.RootInfo.Winrate*100))
 	sb.WriteString(fmt.Sprintf("Score: %.1f\n", result.RootInfo.ScoreMean))
+	if verbose {
+		root := result.RootInfo
+		sb.WriteString(fmt.Sprintf("Score lead: %+.1f\n", root.ScoreLead))
+		sb.WriteString(fmt.Sprintf("Score stdev: %.1f\n", root.ScoreStdev))
+		sb.WriteString(fmt.Sprintf("Utility: %+.3f\n", root.Utility))
+		if root.RawWinrate != 0 || root.RawLead != 0 {
+			sb.WriteString(fmt.Sprintf("Raw network: win %.1f%% lead %+.1f selfplay score %+.1f\n",
+				root.RawWinrate*100, root.RawLead, root.RawScoreSelfplay))
+			sb.WriteString(fmt.Sprintf("Raw uncertainty: winrate %.3f score %.2f\n",
+				root.RawStWrError, root.RawStScoreError))
+		}
+	}
 	sb.WriteString("\n")
 
 	// Top moves
 	sb.WriteString("=== Top Moves ===\n")
+	annotatedPVs := false
+	if verbose {
+		sb.WriteString(fmt.Sprintf("%-3s %-4s %7s %6s %6s %5s %6s %7s %6s  %s\n",
+			"#", "Move", "Visits", "Win", "Score", "Stdev", "LCB", "Utility", "Prior", "PV"))
+	}
 	for i, move := range result.MoveInfos {
 		if i >= 10 && !verbose {
 			break
 		}
 
-		sb.WriteString(fmt.Sprintf("%2d. %-4s ", i+1, move.Move))
-		sb.WriteString(fmt.Sprintf("visits:%6d ", move.Visits))
-		sb.WriteString(fmt.Sprintf("win:%.1f%% ", move.Winrate*100))
-		sb.WriteString(fmt.Sprintf("score:%+.1f", move.ScoreLead))
+		if verbose {
+			sb.WriteString(fmt.Sprintf("%2d. %-4s %7d %5.1f%% %+6.1f %5.1f %5.1f%% %+7.3f %5.1f%%",
+				i+1, move.Move, move.Visits, move.Winrate*100, move.ScoreLead,
+				move.ScoreStdev, move.LCB*100, move.Utility, move.Prior*100))
+		} else {
+			sb.WriteString(fmt.Sprintf("%2d. %-4s ", i+1, move.Move))
+			sb.WriteString(fmt.Sprintf("visits:%6d ", move.Visits))
+			sb.WriteString(fmt.Sprintf("win:%.1f%% ", move.Winrate*100))
+			sb.WriteString(fmt.Sprintf("score:%+.1f", move.ScoreLead))
+		}
 
 		if verbose && len(move.PV) > 0 {
-			sb.WriteString(" pv: ")
-			for j, pv := range move.PV {
-				if j > 0 {
-					sb.WriteString(" ")
-				}
-				sb.WriteString(pv)
-				if j >= 10 {
-					sb.WriteString("...")
-					break
-				}
-			}
+			sb.WriteString("  ")
+			sb.WriteString(formatPV(move))
+			annotatedPVs = annotatedPVs || len(move.PVVisits) > 0
 		}
 
 		sb.WriteString("\n")
 	}
+	if annotatedPVs {
+		sb.WriteString(fmt.Sprintf("PV visits in parentheses; ? marks moves with under %.0f%% of the first move's visits\n",
+			pvSpeculativeShare*100))
+	}
 
 	// Policy priors
 	if len(result.Policy) > 0 && verbose {
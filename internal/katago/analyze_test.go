@@ -2,8 +2,11 @@ package katago
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -187,3 +190,131 @@ func TestMoveFormatValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestSortMoveInfos(t *testing.T) {
+	moves := []MoveInfo{
+		{Move: "D4", Visits: 300, LCB: 0.48},
+		{Move: "Q16", Visits: 500, LCB: 0.46},
+		{Move: "C3", Visits: 100, LCB: 0.52},
+	}
+
+	byLCB := SortMoveInfos(moves, SortByLCB)
+	if byLCB[0].Move != "C3" || byLCB[1].Move != "D4" || byLCB[2].Move != "Q16" {
+		t.Errorf("Unexpected LCB order: %v", byLCB)
+	}
+	byVisits := SortMoveInfos(moves, SortByVisits)
+	if byVisits[0].Move != "Q16" || byVisits[2].Move != "C3" {
+		t.Errorf("Unexpected visits order: %v", byVisits)
+	}
+	if moves[0].Move != "D4" {
+		t.Error("Expected input to be left unchanged")
+	}
+}
+
+func TestFormatAnalysisResultVerbose(t *testing.T) {
+	result := &AnalysisResult{
+		RootInfo: RootInfo{CurrentPlayer: "B", Visits: 100, Winrate: 0.5, ScoreStdev: 12.5, Utility: -0.02, RawWinrate: 0.47},
+		MoveInfos: []MoveInfo{
+			{Move: "D4", Visits: 60, Winrate: 0.51, ScoreLead: 0.4, ScoreStdev: 11.2, LCB: 0.493, Utility: 0.021, Prior: 0.25, PV: []string{"D4", "Q16"}},
+		},
+	}
+
+	text := FormatAnalysisResult(result, true, 19)
+	for _, want := range []string{"Score stdev: 12.5", "Utility: -0.020", "Raw network: win 47.0%", "LCB", "49.3%", "+0.021", "25.0%", "D4 Q16"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in verbose output, got:\n%s", want, text)
+		}
+	}
+
+	if text := FormatAnalysisResult(result, false, 19); strings.Contains(text, "LCB") {
+		t.Errorf("Expected no LCB column in compact output, got:\n%s", text)
+	}
+
+	// PV visits mark where the line becomes speculative
+	result.MoveInfos[0].PV = []string{"D4", "Q16", "C3", "R4"}
+	result.MoveInfos[0].PVVisits = []int{60, 41, 6, 2}
+	text = FormatAnalysisResult(result, true, 19)
+	for _, want := range []string{"D4(60) Q16(41) C3(6) R4(2)?", "PV visits in parentheses"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in verbose output, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestBuildAnalysisQuerySearchSettings(t *testing.T) {
+	position := &Position{Rules: "chinese", BoardXSize: 9, BoardYSize: 9}
+	noise, pvLen := 0.2, 30
+
+	query, err := buildAnalysisQuery(&AnalysisRequest{Position: position})
+	require.NoError(t, err)
+	assert.NotContains(t, query, "overrideSettings")
+
+	query, err = buildAnalysisQuery(&AnalysisRequest{
+		Position:     position,
+		HumanProfile: "rank_5k",
+		Search:       config.SearchConfig{WideRootNoise: &noise, AnalysisPVLen: &pvLen},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"humanSLProfile": "rank_5k",
+		"wideRootNoise":  0.2,
+		"analysisPVLen":  30,
+	}, query["overrideSettings"])
+
+	noise = 5
+	_, err = buildAnalysisQuery(&AnalysisRequest{Position: position, Search: config.SearchConfig{WideRootNoise: &noise}})
+	assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err))
+}
+
+func TestBuildAnalysisQueryInitialPlayer(t *testing.T) {
+	position := &Position{Rules: "chinese", BoardXSize: 9, BoardYSize: 9,
+		InitialStones: []Stone{{Color: "b", Location: "E5"}}}
+
+	query, err := buildAnalysisQuery(&AnalysisRequest{Position: position})
+	require.NoError(t, err)
+	assert.NotContains(t, query, "initialPlayer")
+
+	// A position without moves still sets the player to move
+	position.InitialPlayer = "w"
+	query, err = buildAnalysisQuery(&AnalysisRequest{Position: position})
+	require.NoError(t, err)
+	assert.Equal(t, "w", query["initialPlayer"])
+}
+
+func TestBuildAnalysisQueryRestrictionDepth(t *testing.T) {
+	position := &Position{Rules: "chinese", BoardXSize: 9, BoardYSize: 9}
+
+	query, err := buildAnalysisQuery(&AnalysisRequest{Position: position, AvoidMoves: []string{"E5"}, RestrictionDepth: 20})
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"player": "B", "moves": []string{"E5"}, "untilDepth": 20},
+		{"player": "W", "moves": []string{"E5"}, "untilDepth": 20},
+	}, query["avoidMoves"])
+
+	_, err = buildAnalysisQuery(&AnalysisRequest{Position: position, AllowMoves: []string{"E5"}, RestrictionDepth: 20})
+	assert.Equal(t, apperrors.CodeInvalidArgument, apperrors.CodeOf(err))
+}
+
+func TestAnalysisPVVisitsShareCacheEntry(t *testing.T) {
+	position := &Position{Rules: "chinese", BoardXSize: 9, BoardYSize: 9}
+	plain, err := buildAnalysisQuery(&AnalysisRequest{Position: position})
+	require.NoError(t, err)
+	verbose, err := buildAnalysisQuery(&AnalysisRequest{Position: position, IncludePVVisits: true})
+	require.NoError(t, err)
+	assert.Equal(t, plain, verbose, "Expected the same query, and so the same cache entry, with and without PV visits")
+	assert.Equal(t, true, plain["includePVVisits"])
+
+	// PV visits are left out of results that did not ask for them, without
+	// changing the possibly cached response
+	resp := &Response{MoveInfos: []MoveInfo{{Move: "E5", PV: []string{"E5", "C3"}, PVVisits: []int{10, 4}, PVEdgeVisits: []int{10, 4}}}}
+	result, err := analysisResult(&AnalysisRequest{Position: position}, resp)
+	require.NoError(t, err)
+	assert.Nil(t, result.MoveInfos[0].PVVisits)
+	assert.Nil(t, result.MoveInfos[0].PVEdgeVisits)
+	assert.Equal(t, []string{"E5", "C3"}, result.MoveInfos[0].PV)
+	assert.Equal(t, []int{10, 4}, resp.MoveInfos[0].PVVisits)
+
+	result, err = analysisResult(&AnalysisRequest{Position: position, IncludePVVisits: true}, resp)
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 4}, result.MoveInfos[0].PVVisits)
+}
@@ -0,0 +1,101 @@
+package katago
+
+import (
+	"context"
+	"strings"
+)
+
+// Analyzer examines the moves of a game under review alongside the mistake
+// detector, such as a detector of bad shape. Its findings are added to the
+// review. An analyzer may be asked about moves of several reviews at once.
+type Analyzer interface {
+	// Name identifies the analyzer in its findings and logs.
+	Name() string
+	// AnalyzeMove returns what the analyzer finds about a move, if
+	// anything. An error skips the move for this analyzer only.
+	AnalyzeMove(ctx context.Context, move *MoveContext) ([]Finding, error)
+}
+
+// MoveContext is a move the mistake detector judged, with KataGo's
+// analyses around it.
+type MoveContext struct {
+	Game       *Position `json:"-"`
+	MoveNumber int       `json:"moveNumber"`
+	Color      string    `json:"color"` // "B" or "W"
+	Move       string    `json:"move"`  // Empty for a pass
+	BoardXSize int       `json:"boardXSize"`
+	BoardYSize int       `json:"boardYSize"`
+	Komi       float64   `json:"komi"`
+	Rules      string    `json:"rules"`
+	// Moves are the game's moves up to and including this one
+	Moves []Move `json:"moves"`
+	// Before is KataGo's analysis of the position the move was played in,
+	// After of the position it led to, nil if that was not analyzed
+	Before *AnalysisResult `json:"before"`
+	After  *AnalysisResult `json:"after,omitempty"`
+	// Mistake is the mistake detector's verdict, nil if the move was not a
+	// mistake
+	Mistake *Mistake `json:"mistake,omitempty"`
+}
+
+// Finding is something an analyzer noticed about a move. Findings without a
+// move number are credited to the move they were found on.
+type Finding struct {
+	Analyzer    string                 `json:"analyzer"`
+	MoveNumber  int                    `json:"moveNumber"`
+	Color       string                 `json:"color"`
+	Move        string                 `json:"move,omitempty"`
+	Category    string                 `json:"category"` // Chosen by the analyzer, such as "empty triangle"
+	Explanation string                 `json:"explanation,omitempty"`
+	Data        map[string]interface{} `json:"data,omitempty"` // Anything else the analyzer reports
+}
+
+// runAnalyzers asks each of the engine's analyzers about the judged moves
+// of a review, in move order. Analyzer errors are logged and skipped;
+// analysis stops when the context is done.
+func (e *Engine) runAnalyzers(ctx context.Context, game *Position, review *GameReview, judged []int) []Finding {
+	if len(e.analyzers) == 0 {
+		return nil
+	}
+	mistakes := make(map[int]*Mistake, len(review.Mistakes))
+	for i := range review.Mistakes {
+		mistakes[review.Mistakes[i].MoveNumber] = &review.Mistakes[i]
+	}
+
+	var findings []Finding
+	for _, n := range judged {
+		played := game.Moves[n-1]
+		move := &MoveContext{
+			Game:       game,
+			MoveNumber: n,
+			Color:      strings.ToUpper(played.Color),
+			Move:       played.Location,
+			BoardXSize: game.BoardXSize,
+			BoardYSize: game.BoardYSize,
+			Komi:       game.Komi,
+			Rules:      game.Rules,
+			Moves:      game.Moves[:n],
+			Before:     review.analysisAfter(n - 1),
+			After:      review.analysisAfter(n),
+			Mistake:    mistakes[n],
+		}
+		for _, analyzer := range e.analyzers {
+			if ctx.Err() != nil {
+				return findings
+			}
+			found, err := analyzer.AnalyzeMove(ctx, move)
+			if err != nil {
+				e.logger.Warn("Analyzer failed", "analyzer", analyzer.Name(), "move", n, "error", err)
+				continue
+			}
+			for _, f := range found {
+				f.Analyzer = analyzer.Name()
+				if f.MoveNumber == 0 {
+					f.MoveNumber, f.Color, f.Move = n, move.Color, move.Move
+				}
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,95 @@
+package katago
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// shapeAnalyzer finds an empty triangle at every move it is given and
+// fails on passes.
+type shapeAnalyzer struct {
+	moves []*MoveContext
+}
+
+func (a *shapeAnalyzer) Name() string { return "shapes" }
+
+func (a *shapeAnalyzer) AnalyzeMove(ctx context.Context, move *MoveContext) ([]Finding, error) {
+	a.moves = append(a.moves, move)
+	if move.Move == "" {
+		return nil, errors.New("cannot analyze a pass")
+	}
+	return []Finding{{Category: "empty triangle", Data: map[string]interface{}{"stones": 3}}}, nil
+}
+
+func TestRunAnalyzers(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "error"))
+	engine := NewEngine(&config.KataGoConfig{BinaryPath: "katago"}, logger, nil)
+	game := &Position{
+		BoardXSize: 19, BoardYSize: 19, Komi: 6.5, Rules: "japanese",
+		Moves: []Move{{Color: "b", Location: "Q16"}, {Color: "w", Location: "D4"}, {Color: "b", Location: ""}},
+	}
+	analyses := []*AnalysisResult{{RootInfo: RootInfo{Visits: 1}}, {RootInfo: RootInfo{Visits: 2}}, {RootInfo: RootInfo{Visits: 3}}}
+	review := &GameReview{
+		Mistakes: []Mistake{{MoveNumber: 2, Category: "blunder"}},
+		Analyses: analyses,
+	}
+
+	// Without analyzers nothing is found
+	if findings := engine.runAnalyzers(context.Background(), game, review, []int{1, 2, 3}); findings != nil {
+		t.Errorf("Expected no findings, got %+v", findings)
+	}
+
+	analyzer := &shapeAnalyzer{}
+	engine.SetAnalyzers([]Analyzer{analyzer})
+	findings := engine.runAnalyzers(context.Background(), game, review, []int{2, 3})
+	if len(findings) != 1 {
+		t.Fatalf("Expected a finding for move 2 only, got %+v", findings)
+	}
+	if f := findings[0]; f.Analyzer != "shapes" || f.MoveNumber != 2 || f.Color != "W" || f.Move != "D4" || f.Data["stones"] != 3 {
+		t.Errorf("Expected the finding credited to W D4, got %+v", f)
+	}
+	move := analyzer.moves[0]
+	if move.Before != analyses[1] || move.After != analyses[2] || move.Mistake != &review.Mistakes[0] ||
+		len(move.Moves) != 2 || move.Rules != "japanese" {
+		t.Errorf("Unexpected move context: %+v", move)
+	}
+	if analyzer.moves[1].After != nil {
+		t.Errorf("Expected no analysis after the last move, got %+v", analyzer.moves[1].After)
+	}
+}
+
+func TestProcessAnalyzer(t *testing.T) {
+	t.Setenv("KATAGO_MCP_HELPER_PROCESS", "analyzer")
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "error"))
+	analyzer := NewProcessAnalyzer(config.AnalyzerConfig{
+		Name:           "echo",
+		Command:        os.Args[0],
+		Args:           []string{"-test.run=^TestHelperProcess$"},
+		TimeoutSeconds: 1,
+	}, logger)
+	defer func() { _ = analyzer.Close() }()
+	ctx := context.Background()
+
+	findings, err := analyzer.AnalyzeMove(ctx, &MoveContext{MoveNumber: 1, Move: "Q16", Before: &AnalysisResult{}})
+	if err != nil || len(findings) != 1 || findings[0].Category != "seen" || findings[0].Explanation != "Q16" {
+		t.Fatalf("Expected the plugin to answer, got %+v, %v", findings, err)
+	}
+	if _, err := analyzer.AnalyzeMove(ctx, &MoveContext{MoveNumber: 2}); err == nil || !strings.Contains(err.Error(), "pass") {
+		t.Errorf("Expected the plugin's error, got %v", err)
+	}
+
+	// A plugin that stops answering is restarted for the next move
+	if _, err := analyzer.AnalyzeMove(ctx, &MoveContext{MoveNumber: 3, Move: "K10"}); err == nil {
+		t.Error("Expected a timeout")
+	}
+	findings, err = analyzer.AnalyzeMove(ctx, &MoveContext{MoveNumber: 4, Move: "D4"})
+	if err != nil || len(findings) != 1 || findings[0].Explanation != "D4" {
+		t.Errorf("Expected the restarted plugin to answer, got %+v, %v", findings, err)
+	}
+}
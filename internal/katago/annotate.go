@@ -0,0 +1,256 @@
+package katago
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// AnnotateSGF returns the game with a review written into it: a summary
+// comment on the root node, and a comment plus a bad move mark (BM[1] for
+// mistakes, BM[2] for blunders) on each mistake, and a comment on each move
+// an analyzer found something about. Moves are numbered along
+// the main line as SGFParser reads it, so variations are left untouched.
+func AnnotateSGF(sgf string, review *GameReview) (string, error) {
+	return annotateSGF(sgf, review, false)
+}
+
+// annotateSGF writes a review into a game as AnnotateSGF does, adding the
+// analysis of each position for desktop GUIs if analysis is set.
+func annotateSGF(sgf string, review *GameReview, analysis bool) (string, error) {
+	byMove := make(map[int]*Mistake, len(review.Mistakes))
+	for i := range review.Mistakes {
+		byMove[review.Mistakes[i].MoveNumber] = &review.Mistakes[i]
+	}
+	findings := make(map[int][]Finding)
+	for _, f := range review.Findings {
+		findings[f.MoveNumber] = append(findings[f.MoveNumber], f)
+	}
+
+	start := strings.IndexByte(sgf, '(')
+	if start < 0 {
+		return "", apperrors.New(apperrors.CodeInvalidSGF, "invalid SGF: no opening parenthesis")
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(sgf) + 256*len(review.Mistakes))
+	sb.WriteString(sgf[:start+1])
+
+	i := start + 1
+	moveNumber := 0
+	root := true
+	for i < len(sgf) {
+		switch sgf[i] {
+		case ';':
+			node, isMove, end, err := scanNode(sgf, i+1)
+			if err != nil {
+				return "", err
+			}
+			var comments []string
+			mark := ""
+			if root {
+				root = false
+				comments = append(comments, reviewComment(review))
+			}
+			if isMove {
+				moveNumber++
+				if mistake, ok := byMove[moveNumber]; ok {
+					comments = append(comments, mistakeComment(mistake))
+					mark = "BM[1]"
+					if mistake.Category == "blunder" {
+						mark = "BM[2]"
+					}
+				}
+				for _, f := range findings[moveNumber] {
+					comments = append(comments, findingComment(f))
+				}
+			}
+			sb.WriteByte(';')
+			if analysis {
+				node = addSGFProperties(node, lizzieProperties(review.analysisAfter(moveNumber)))
+			}
+			if len(comments) > 0 {
+				node = annotateNode(node, strings.Join(comments, "\n\n"), mark)
+			}
+			sb.WriteString(node)
+			i = end
+		case '(':
+			// Variations are copied as they are
+			end := skipSGFTree(sgf, i)
+			sb.WriteString(sgf[i:end])
+			i = end
+		case ')':
+			sb.WriteString(sgf[i:])
+			return sb.String(), nil
+		default:
+			sb.WriteByte(sgf[i])
+			i++
+		}
+	}
+	return "", apperrors.New(apperrors.CodeInvalidSGF, "invalid SGF: unclosed game tree")
+}
+
+// scanNode returns the properties of the node starting at i, whether it
+// holds a move, and the index just past it.
+func scanNode(sgf string, i int) (node string, isMove bool, end int, err error) {
+	start := i
+	for i < len(sgf) {
+		switch c := sgf[i]; {
+		case c == ';' || c == '(' || c == ')':
+			return sgf[start:i], isMove, i, nil
+		case c == '[':
+			i, err = skipSGFValue(sgf, i)
+			if err != nil {
+				return "", false, 0, err
+			}
+		case c >= 'A' && c <= 'Z':
+			nameStart := i
+			for i < len(sgf) && sgf[i] >= 'A' && sgf[i] <= 'Z' {
+				i++
+			}
+			if name := sgf[nameStart:i]; name == "B" || name == "W" {
+				isMove = true
+			}
+		default:
+			i++
+		}
+	}
+	return sgf[start:], isMove, len(sgf), nil
+}
+
+// skipSGFValue returns the index just past the property value at i.
+func skipSGFValue(sgf string, i int) (int, error) {
+	for i++; i < len(sgf); i++ {
+		switch sgf[i] {
+		case '\\':
+			i++
+		case ']':
+			return i + 1, nil
+		}
+	}
+	return 0, apperrors.New(apperrors.CodeInvalidSGF, "unclosed property value")
+}
+
+// skipSGFTree returns the index just past the game tree starting at i.
+func skipSGFTree(sgf string, i int) int {
+	depth := 0
+	for i < len(sgf) {
+		switch sgf[i] {
+		case '[':
+			end, err := skipSGFValue(sgf, i)
+			if err != nil {
+				return len(sgf)
+			}
+			i = end
+			continue
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+		i++
+	}
+	return len(sgf)
+}
+
+// annotateNode appends a comment and an optional mark to a node's
+// properties, adding to an existing comment rather than repeating C.
+func annotateNode(node, comment, mark string) string {
+	body := strings.TrimRight(node, " \t\r\n")
+	trailing := node[len(body):]
+	if mark != "" && findSGFProperty(body, "BM") < 0 {
+		body += mark
+	}
+
+	if at := findSGFProperty(body, "C"); at >= 0 {
+		end, err := skipSGFValue(body, at)
+		if err == nil {
+			return body[:end-1] + "\n\n" + escapeSGFText(comment) + body[end-1:] + trailing
+		}
+	}
+	return body + "C[" + escapeSGFText(comment) + "]" + trailing
+}
+
+// addSGFProperties appends properties to a node, before any trailing
+// whitespace.
+func addSGFProperties(node, properties string) string {
+	body := strings.TrimRight(node, " \t\r\n")
+	return body + properties + node[len(body):]
+}
+
+// findSGFProperty returns the index of the value of a property in a node,
+// or -1 if the node does not have it.
+func findSGFProperty(node, name string) int {
+	for i := 0; i < len(node); {
+		switch c := node[i]; {
+		case c == '[':
+			end, err := skipSGFValue(node, i)
+			if err != nil {
+				return -1
+			}
+			i = end
+		case c >= 'A' && c <= 'Z':
+			nameStart := i
+			for i < len(node) && node[i] >= 'A' && node[i] <= 'Z' {
+				i++
+			}
+			if node[nameStart:i] == name {
+				for i < len(node) && node[i] != '[' {
+					i++
+				}
+				if i < len(node) {
+					return i
+				}
+			}
+		default:
+			i++
+		}
+	}
+	return -1
+}
+
+// escapeSGFText escapes text for an SGF property value.
+func escapeSGFText(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	return strings.ReplaceAll(s, "]", "\\]")
+}
+
+// reviewComment summarizes a review for the root node.
+func reviewComment(review *GameReview) string {
+	s := review.Summary
+	var sb strings.Builder
+	sb.WriteString("KataGo review\n")
+	if s.Partial {
+		sb.WriteString(fmt.Sprintf("Partial review: %d of %d moves analyzed\n", s.AnalyzedMoves, s.TotalMoves))
+	}
+	sb.WriteString(fmt.Sprintf("Black accuracy: %.1f%%, mistakes/blunders: %d/%d\n",
+		s.BlackAccuracy, s.BlackMistakes, s.BlackBlunders))
+	sb.WriteString(fmt.Sprintf("White accuracy: %.1f%%, mistakes/blunders: %d/%d",
+		s.WhiteAccuracy, s.WhiteMistakes, s.WhiteBlunders))
+	if s.EstimatedLevel != "" {
+		sb.WriteString(fmt.Sprintf("\nEstimated level: %s", s.EstimatedLevel))
+	}
+	return sb.String()
+}
+
+// mistakeComment describes a mistake for its move's node.
+func mistakeComment(m *Mistake) string {
+	played := m.PlayedMove
+	if played == "" {
+		played = "pass"
+	}
+	return fmt.Sprintf("KataGo: %s. %s played %s (%.1f%% WR); %s was better (%.1f%% WR). %s",
+		m.Category, m.Color, played, m.PlayedWR*100, m.BestMove, m.BestWR*100, m.Explanation)
+}
+
+// findingComment describes an analyzer's finding for its move's node.
+func findingComment(f Finding) string {
+	if f.Explanation == "" {
+		return fmt.Sprintf("%s: %s.", f.Analyzer, f.Category)
+	}
+	return fmt.Sprintf("%s: %s. %s", f.Analyzer, f.Category, f.Explanation)
+}
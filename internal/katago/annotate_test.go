@@ -0,0 +1,68 @@
+package katago
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnnotateSGF(t *testing.T) {
+	sgf := "(;GM[1]SZ[9]PB[Alice]PW[Bob]\n;B[ee];W[cc]C[Opening\\] note]\n(;B[gg];W[cg])(;B[cg]C[variation]))"
+	review := &GameReview{
+		Mistakes: []Mistake{
+			{MoveNumber: 2, Color: "W", PlayedMove: "C7", BestMove: "G3", Category: "blunder",
+				WinrateDrop: 0.2, PlayedWR: 0.3, BestWR: 0.5, Explanation: "This move loses 20.0% win rate"},
+		},
+		Summary: ReviewSummary{TotalMoves: 2, AnalyzedMoves: 2, BlackAccuracy: 100, WhiteBlunders: 1},
+	}
+
+	annotated, err := AnnotateSGF(sgf, review)
+	if err != nil {
+		t.Fatalf("AnnotateSGF failed: %v", err)
+	}
+	for _, want := range []string{
+		"PW[Bob]C[KataGo review\nBlack accuracy: 100.0%",
+		";W[cc]C[Opening\\] note\n\nKataGo: blunder. W played C7 (30.0% WR); G3 was better (50.0% WR).",
+		"BM[2]",
+		"(;B[gg];W[cg])(;B[cg]C[variation]))",
+	} {
+		if !strings.Contains(annotated, want) {
+			t.Errorf("Expected %q in annotated SGF:\n%s", want, annotated)
+		}
+	}
+	if strings.Count(annotated, "C[") != 3 {
+		t.Errorf("Expected comments to be merged rather than repeated:\n%s", annotated)
+	}
+
+	// The annotated game still parses to the same moves
+	original, _ := NewSGFParser(sgf).Parse()
+	parsed, err := NewSGFParser(annotated).Parse()
+	if err != nil {
+		t.Fatalf("Annotated SGF does not parse: %v", err)
+	}
+	if len(parsed.Moves) != len(original.Moves) || parsed.PlayerBlack != "Alice" {
+		t.Errorf("Expected annotated game to keep its moves and info, got %+v", parsed)
+	}
+
+	// Analyzer findings are added to their moves' comments
+	review.Findings = []Finding{
+		{Analyzer: "shapes", MoveNumber: 1, Color: "B", Move: "E5", Category: "tengen"},
+		{Analyzer: "shapes", MoveNumber: 2, Color: "W", Move: "C7", Category: "slow", Explanation: "Too close."},
+	}
+	annotated, err = AnnotateSGF(sgf, review)
+	if err != nil {
+		t.Fatalf("AnnotateSGF failed: %v", err)
+	}
+	for _, want := range []string{";B[ee]C[shapes: tengen.]", "50.0% WR). This move loses 20.0% win rate\n\nshapes: slow. Too close.]"} {
+		if !strings.Contains(annotated, want) {
+			t.Errorf("Expected %q in annotated SGF:\n%s", want, annotated)
+		}
+	}
+}
+
+func TestAnnotateSGFErrors(t *testing.T) {
+	for _, sgf := range []string{"no game here", "(;GM[1]C[unclosed", "(;GM[1];B[aa]"} {
+		if _, err := AnnotateSGF(sgf, &GameReview{}); err == nil {
+			t.Errorf("Expected error for %q", sgf)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package katago
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// Limits on what an SGF archive may unpack to, so that a small upload
+// cannot expand into an unbounded amount of memory or review work.
+const (
+	MaxArchiveGames     = 200
+	MaxArchiveGameBytes = 1 << 20  // 1 MiB per game
+	MaxArchiveBytes     = 32 << 20 // 32 MiB in total
+)
+
+// ArchiveGame is an SGF file read from an archive.
+type ArchiveGame struct {
+	Name string // Path within the archive
+	SGF  string
+}
+
+// ReadSGFArchive reads the .sgf files of a zip, tar or gzipped tar
+// archive, in archive order. Directories, links and other files are
+// skipped, as are macOS resource forks. Nothing is written to disk, so
+// entry paths are only used as names. It fails if the archive holds no
+// games or more than MaxArchiveGames, or if a game or the games together
+// are larger than MaxArchiveGameBytes or MaxArchiveBytes once unpacked.
+func ReadSGFArchive(data []byte) ([]ArchiveGame, error) {
+	var games []ArchiveGame
+	var err error
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")) || bytes.HasPrefix(data, []byte("PK\x05\x06")):
+		games, err = readZipArchive(data)
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		var gz *gzip.Reader
+		gz, err = gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "invalid gzip archive")
+		}
+		defer gz.Close()
+		games, err = readTarArchive(gz)
+	case len(data) > 262 && string(data[257:262]) == "ustar":
+		games, err = readTarArchive(bytes.NewReader(data))
+	default:
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "archive is not a zip, tar or tar.gz file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "archive holds no .sgf files")
+	}
+	return games, nil
+}
+
+// archiveReader collects games while enforcing the archive limits.
+type archiveReader struct {
+	games []ArchiveGame
+	total int64
+}
+
+// add reads an entry if it is an SGF file.
+func (a *archiveReader) add(name string, r io.Reader) error {
+	base := path.Base(name)
+	if !strings.EqualFold(path.Ext(base), ".sgf") || strings.HasPrefix(base, "._") || strings.HasPrefix(name, "__MACOSX/") {
+		return nil
+	}
+	if len(a.games) == MaxArchiveGames {
+		return apperrors.New(apperrors.CodeInvalidArgument, "archive holds more than %d games", MaxArchiveGames)
+	}
+	content, err := io.ReadAll(io.LimitReader(r, MaxArchiveGameBytes+1))
+	if err != nil {
+		return apperrors.Wrap(apperrors.CodeInvalidArgument, err, "failed to read %s", name)
+	}
+	if len(content) > MaxArchiveGameBytes {
+		return apperrors.New(apperrors.CodeInvalidArgument, "%s is larger than %d bytes", name, MaxArchiveGameBytes)
+	}
+	a.total += int64(len(content))
+	if a.total > MaxArchiveBytes {
+		return apperrors.New(apperrors.CodeInvalidArgument, "archive unpacks to more than %d bytes", MaxArchiveBytes)
+	}
+	a.games = append(a.games, ArchiveGame{Name: name, SGF: string(content)})
+	return nil
+}
+
+func readZipArchive(data []byte) ([]ArchiveGame, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "invalid zip archive")
+	}
+	var a archiveReader
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "failed to open %s", f.Name)
+		}
+		err = a.add(f.Name, rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return a.games, nil
+}
+
+func readTarArchive(r io.Reader) ([]ArchiveGame, error) {
+	tr := tar.NewReader(r)
+	var a archiveReader
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return a.games, nil
+		}
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "invalid tar archive")
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := a.add(header.Name, tr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// ArchiveGameResult is the review of one game of an archive.
+type ArchiveGameResult struct {
+	Name        string         `json:"name"`
+	PlayerBlack string         `json:"playerBlack,omitempty"`
+	PlayerWhite string         `json:"playerWhite,omitempty"`
+	Result      string         `json:"result,omitempty"`
+	Summary     *ReviewSummary `json:"summary,omitempty"`
+	Error       string         `json:"error,omitempty"`   // Why the game could not be reviewed
+	Skipped     bool           `json:"skipped,omitempty"` // A problem's setup, with no moves to review
+}
+
+// ArchivePlayerStats aggregates a player's games in an archive.
+type ArchivePlayerStats struct {
+	Name     string  `json:"name"`
+	Games    int     `json:"games"`
+	Mistakes int     `json:"mistakes"`
+	Blunders int     `json:"blunders"`
+	Accuracy float64 `json:"accuracy"` // Mean accuracy over their games
+}
+
+// ArchiveReview is the review of every game in an archive.
+type ArchiveReview struct {
+	Games    int                  `json:"games"`
+	Reviewed int                  `json:"reviewed"`
+	Failed   int                  `json:"failed"`
+	Skipped  int                  `json:"skipped,omitempty"`
+	Moves    int                  `json:"moves"` // Moves analyzed across the reviewed games
+	Mistakes int                  `json:"mistakes"`
+	Blunders int                  `json:"blunders"`
+	Players  []ArchivePlayerStats `json:"players"` // Most games first
+	Results  []ArchiveGameResult  `json:"results"` // In archive order
+	// Partial is set when the context was done before every game was
+	// reviewed; games not reached have no summary or error.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// ReviewArchive reviews the games of an archive one after another with
+// review, such as an engine's ReviewGame, reporting progress per game. A
+// game that cannot be reviewed is recorded as failed rather than failing
+// the whole archive, and a problem's setup, with no moves, is skipped.
+func ReviewArchive(ctx context.Context,
+	review func(context.Context, string, *MistakeThresholds) (*GameReview, error),
+	games []ArchiveGame, thresholds *MistakeThresholds) *ArchiveReview {
+	archive := &ArchiveReview{Games: len(games), Results: make([]ArchiveGameResult, len(games))}
+	players := make(map[string]*ArchivePlayerStats)
+	for i, game := range games {
+		result := &archive.Results[i]
+		result.Name = game.Name
+		if position, err := NewSGFParser(game.SGF).Parse(); err == nil {
+			result.PlayerBlack, result.PlayerWhite, result.Result = position.PlayerBlack, position.PlayerWhite, position.Result
+			if position.SetupOnly() {
+				result.Skipped = true
+				archive.Skipped++
+				continue
+			}
+		}
+		if ctx.Err() != nil {
+			archive.Partial = true
+			continue
+		}
+
+		gameReview, err := review(gameProgress(ctx, i, len(games)), game.SGF, thresholds)
+		if err != nil {
+			if ctx.Err() != nil {
+				archive.Partial = true
+				continue
+			}
+			result.Error = err.Error()
+			archive.Failed++
+			continue
+		}
+		summary := gameReview.Summary
+		result.Summary = &summary
+		archive.Partial = archive.Partial || summary.Partial
+		archive.Reviewed++
+		archive.Moves += summary.AnalyzedMoves
+		archive.Mistakes += summary.BlackMistakes + summary.WhiteMistakes
+		archive.Blunders += summary.BlackBlunders + summary.WhiteBlunders
+
+		for _, side := range []struct {
+			name               string
+			mistakes, blunders int
+			accuracy           float64
+		}{
+			{result.PlayerBlack, summary.BlackMistakes, summary.BlackBlunders, summary.BlackAccuracy},
+			{result.PlayerWhite, summary.WhiteMistakes, summary.WhiteBlunders, summary.WhiteAccuracy},
+		} {
+			if side.name == "" {
+				continue
+			}
+			key := strings.ToLower(side.name)
+			stats, ok := players[key]
+			if !ok {
+				stats = &ArchivePlayerStats{Name: side.name}
+				players[key] = stats
+			}
+			// Accumulate the total accuracy; it is averaged below
+			stats.Games++
+			stats.Mistakes += side.mistakes
+			stats.Blunders += side.blunders
+			stats.Accuracy += side.accuracy
+		}
+	}
+
+	archive.Players = make([]ArchivePlayerStats, 0, len(players))
+	for _, stats := range players {
+		stats.Accuracy /= float64(stats.Games)
+		archive.Players = append(archive.Players, *stats)
+	}
+	sort.Slice(archive.Players, func(i, j int) bool {
+		a, b := archive.Players[i], archive.Players[j]
+		if a.Games != b.Games {
+			return a.Games > b.Games
+		}
+		return a.Name < b.Name
+	})
+	return archive
+}
@@ -0,0 +1,127 @@
+package katago
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+func zipArchive(t *testing.T, files map[string]string, order ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range order {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadSGFArchive(t *testing.T) {
+	files := map[string]string{
+		"club/game1.sgf":            "(;PB[Alice]PW[Bob];B[pd])",
+		"club/notes.txt":            "not a game",
+		"__MACOSX/club/._game1.sgf": "resource fork",
+		"club/GAME2.SGF":            "(;PB[Carol]PW[Alice];B[dd])",
+	}
+	games, err := ReadSGFArchive(zipArchive(t, files, "club/game1.sgf", "club/notes.txt", "__MACOSX/club/._game1.sgf", "club/GAME2.SGF"))
+	if err != nil {
+		t.Fatalf("ReadSGFArchive failed: %v", err)
+	}
+	if len(games) != 2 || games[0].Name != "club/game1.sgf" || games[1].SGF != files["club/GAME2.SGF"] {
+		t.Errorf("Expected the two games in archive order, got %+v", games)
+	}
+
+	// The same games as a tar.gz
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{"club/game1.sgf", "club/GAME2.SGF"} {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: "club/link.sgf", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	gz.Close()
+	if games, err := ReadSGFArchive(buf.Bytes()); err != nil || len(games) != 2 {
+		t.Errorf("Expected two games from the tar.gz without the link, got %+v, %v", games, err)
+	}
+
+	huge := map[string]string{"big.sgf": strings.Repeat("x", MaxArchiveGameBytes+1)}
+	for name, data := range map[string][]byte{
+		"too large": zipArchive(t, huge, "big.sgf"),
+		"no games":  zipArchive(t, files, "club/notes.txt"),
+		"not zip":   []byte("(;B[pd])"),
+	} {
+		if _, err := ReadSGFArchive(data); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+			t.Errorf("%s: expected %s, got %v", name, apperrors.CodeInvalidArgument, err)
+		}
+	}
+}
+
+func TestReviewArchive(t *testing.T) {
+	games := []ArchiveGame{
+		{Name: "1.sgf", SGF: "(;PB[Alice]PW[Bob]RE[B+R];B[pd])"},
+		{Name: "2.sgf", SGF: "(;PB[Bob]PW[alice];B[dd])"},
+		{Name: "3.sgf", SGF: "broken"},
+		{Name: "problem.sgf", SGF: "(;SZ[19]AB[pd][qc]AW[qd]PL[W])"},
+	}
+	review := func(ctx context.Context, sgf string, thresholds *MistakeThresholds) (*GameReview, error) {
+		if sgf == "broken" {
+			return nil, errors.New("failed to parse SGF")
+		}
+		return &GameReview{Summary: ReviewSummary{
+			AnalyzedMoves: 100, BlackMistakes: 2, WhiteMistakes: 1, BlackBlunders: 1,
+			BlackAccuracy: 80, WhiteAccuracy: 90,
+		}}, nil
+	}
+
+	archive := ReviewArchive(context.Background(), review, games, nil)
+	if archive.Games != 4 || archive.Reviewed != 2 || archive.Failed != 1 || archive.Skipped != 1 || archive.Moves != 200 ||
+		archive.Mistakes != 6 || archive.Blunders != 2 || archive.Partial {
+		t.Errorf("Unexpected totals: %+v", archive)
+	}
+	if r := archive.Results[0]; r.PlayerBlack != "Alice" || r.Result != "B+R" || r.Summary == nil {
+		t.Errorf("Unexpected first result: %+v", r)
+	}
+	if r := archive.Results[2]; r.Error != "failed to parse SGF" || r.Summary != nil {
+		t.Errorf("Expected the broken game to fail, got %+v", r)
+	}
+	if r := archive.Results[3]; !r.Skipped || r.Error != "" || r.Summary != nil {
+		t.Errorf("Expected the problem to be skipped, got %+v", r)
+	}
+	if len(archive.Players) != 2 {
+		t.Fatalf("Expected two players, got %+v", archive.Players)
+	}
+	// Alice played Black once and White once, matched without regard to case
+	if p := archive.Players[0]; p.Name != "Alice" || p.Games != 2 || p.Mistakes != 3 || p.Blunders != 1 || p.Accuracy != 85 {
+		t.Errorf("Unexpected stats for Alice: %+v", p)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if archive := ReviewArchive(ctx, review, games, nil); !archive.Partial || archive.Reviewed != 0 || archive.Results[0].Name != "1.sgf" {
+		t.Errorf("Expected a partial review after cancellation, got %+v", archive)
+	}
+}
@@ -0,0 +1,261 @@
+package katago
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// Board tracks stones on a Go board, applying captures and rejecting
+// occupied points, suicide and immediate ko recaptures.
+type Board struct {
+	xSize, ySize int
+	points       []string // "b", "w" or "" for each point, row 0 at the top
+	koPoint      int      // Point that may not be played next, or -1
+	captures     map[string]int
+	lastMove     int // Point of the last stone played, or -1
+	hash         uint64
+}
+
+// NewBoard creates an empty board.
+func NewBoard(xSize, ySize int) *Board {
+	return &Board{
+		xSize:    xSize,
+		ySize:    ySize,
+		points:   make([]string, xSize*ySize),
+		koPoint:  -1,
+		captures: map[string]int{"b": 0, "w": 0},
+		lastMove: -1,
+	}
+}
+
+// BoardFromPosition builds the board for a position by placing its initial
+// stones and playing its moves. It returns an error naming the first
+// illegal move.
+func BoardFromPosition(position *Position) (*Board, error) {
+	if position.BoardXSize < 1 || position.BoardYSize < 1 || position.BoardXSize > maxSGFBoardSize || position.BoardYSize > maxSGFBoardSize {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid board size: %dx%d", position.BoardXSize, position.BoardYSize)
+	}
+	board := NewBoard(position.BoardXSize, position.BoardYSize)
+	for _, stone := range position.InitialStones {
+		x, y, err := regionCorner(strings.ToUpper(stone.Location), board.xSize, board.ySize)
+		if err != nil {
+			return nil, err
+		}
+		board.place(y*board.xSize+x, strings.ToLower(stone.Color))
+	}
+	for i, move := range position.Moves {
+		if err := board.Play(move.Color, move.Location); err != nil {
+			return nil, fmt.Errorf("move %d: %w", i+1, err)
+		}
+	}
+	return board, nil
+}
+
+// Play places a stone for color ("b" or "w") at a GTP location. An empty
+// location or "pass" passes.
+func (b *Board) Play(color, location string) error {
+	color = strings.ToLower(color)
+	if color != "b" && color != "w" {
+		return apperrors.New(apperrors.CodeInvalidArgument, "invalid color: %s", color)
+	}
+	if location == "" || strings.EqualFold(location, "pass") {
+		b.koPoint = -1
+		b.lastMove = -1
+		return nil
+	}
+
+	x, y, err := regionCorner(strings.ToUpper(location), b.xSize, b.ySize)
+	if err != nil {
+		return err
+	}
+	point := y*b.xSize + x
+	if b.points[point] != "" {
+		return apperrors.New(apperrors.CodeInvalidArgument, "illegal move %s: point is occupied", location)
+	}
+	if point == b.koPoint {
+		return apperrors.New(apperrors.CodeInvalidArgument, "illegal move %s: ko recapture", location)
+	}
+
+	b.place(point, color)
+	enemy := "w"
+	if color == "w" {
+		enemy = "b"
+	}
+	var captured []int
+	for _, n := range b.neighbors(point) {
+		if b.points[n] == enemy {
+			if stones, libs := b.group(n); libs == 0 {
+				for _, s := range stones {
+					b.place(s, "")
+				}
+				captured = append(captured, stones...)
+			}
+		}
+	}
+	stones, libs := b.group(point)
+	if libs == 0 {
+		b.place(point, "")
+		return apperrors.New(apperrors.CodeInvalidArgument, "illegal move %s: suicide", location)
+	}
+
+	// A single stone capturing a single stone creates a ko
+	b.koPoint = -1
+	if len(captured) == 1 && len(stones) == 1 && libs == 1 {
+		b.koPoint = captured[0]
+	}
+	b.captures[color] += len(captured)
+	b.lastMove = point
+	return nil
+}
+
+// place sets the stone at a point, "" to remove it, keeping the hash.
+func (b *Board) place(point int, color string) {
+	if old := b.points[point]; old != "" {
+		b.hash ^= zobristKey(point, old)
+	}
+	if color != "" {
+		b.hash ^= zobristKey(point, color)
+	}
+	b.points[point] = color
+}
+
+// Hash returns the Zobrist hash of the stones on the board. Boards of the
+// same size with the same stones have the same hash, whatever moves led to
+// them, so a repeated position such as the cycle of a triple ko is found
+// by comparing hashes. Captures, the ko point and the player to move are
+// not part of it, as a positional superko rule compares positions.
+func (b *Board) Hash() uint64 {
+	return b.hash
+}
+
+// zobristKey returns the Zobrist key of a stone of color ("b" or "w") at a
+// point, the same on every board: the splitmix64 mix of the point and
+// color.
+func zobristKey(point int, color string) uint64 {
+	v := uint64(point)*2 + 1
+	if color == "w" {
+		v++
+	}
+	v += 0x9e3779b97f4a7c15
+	v = (v ^ (v >> 30)) * 0xbf58476d1ce4e5b9
+	v = (v ^ (v >> 27)) * 0x94d049bb133111eb
+	return v ^ (v >> 31)
+}
+
+// Stone returns the color ("b" or "w") of the stone at a GTP location, or
+// "" if the point is empty or the location is off the board.
+func (b *Board) Stone(location string) string {
+	x, y, err := regionCorner(strings.ToUpper(location), b.xSize, b.ySize)
+	if err != nil {
+		return ""
+	}
+	return b.points[y*b.xSize+x]
+}
+
+// IsLegal reports whether color may play at a GTP location, without
+// changing the board.
+func (b *Board) IsLegal(color, location string) bool {
+	return b.Clone().Play(color, location) == nil
+}
+
+// Clone returns an independent copy of the board.
+func (b *Board) Clone() *Board {
+	clone := *b
+	clone.points = append([]string(nil), b.points...)
+	clone.captures = map[string]int{"b": b.captures["b"], "w": b.captures["w"]}
+	return &clone
+}
+
+// Captures returns the number of stones captured by color.
+func (b *Board) Captures(color string) int {
+	return b.captures[strings.ToLower(color)]
+}
+
+// LastMove returns the location of the last stone played, or "" after a
+// pass or on a new board.
+func (b *Board) LastMove() string {
+	if b.lastMove < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%c%d", ColumnLetter(b.lastMove%b.xSize), b.ySize-b.lastMove/b.xSize)
+}
+
+// String renders the board with coordinates.
+func (b *Board) String() string {
+	var sb strings.Builder
+	header := "   "
+	for x := 0; x < b.xSize; x++ {
+		header += fmt.Sprintf(" %c", ColumnLetter(x))
+	}
+	sb.WriteString(header + "\n")
+	for y := 0; y < b.ySize; y++ {
+		row := b.ySize - y
+		sb.WriteString(fmt.Sprintf("%2d ", row))
+		for x := 0; x < b.xSize; x++ {
+			switch b.points[y*b.xSize+x] {
+			case "b":
+				sb.WriteString(" ●")
+			case "w":
+				sb.WriteString(" ○")
+			default:
+				sb.WriteString(" ·")
+			}
+		}
+		sb.WriteString(fmt.Sprintf(" %d\n", row))
+	}
+	sb.WriteString(header + "\n")
+	return sb.String()
+}
+
+// neighbors returns the points adjacent to point.
+func (b *Board) neighbors(point int) []int {
+	x, y := point%b.xSize, point/b.xSize
+	result := make([]int, 0, 4)
+	if x > 0 {
+		result = append(result, point-1)
+	}
+	if x < b.xSize-1 {
+		result = append(result, point+1)
+	}
+	if y > 0 {
+		result = append(result, point-b.xSize)
+	}
+	if y < b.ySize-1 {
+		result = append(result, point+b.xSize)
+	}
+	return result
+}
+
+// group returns the stones connected to point and their liberty count.
+func (b *Board) group(point int) (stones []int, liberties int) {
+	color := b.points[point]
+	seen := map[int]bool{point: true}
+	libs := map[int]bool{}
+	stack := []int{point}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		stones = append(stones, p)
+		for _, n := range b.neighbors(p) {
+			switch {
+			case b.points[n] == "":
+				libs[n] = true
+			case b.points[n] == color && !seen[n]:
+				seen[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return stones, len(libs)
+}
+
+// ColumnLetter returns the GTP column letter for a 0-based index.
+func ColumnLetter(x int) byte {
+	col := byte('A' + x)
+	if col >= 'I' {
+		col++
+	}
+	return col
+}
@@ -0,0 +1,144 @@
+package katago
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+func TestBoardCaptures(t *testing.T) {
+	board := NewBoard(9, 9)
+	// Surround the white stone at B1 in the corner
+	for _, m := range []Move{{"w", "A1"}, {"b", "B1"}, {"w", "E5"}, {"b", "A2"}} {
+		if err := board.Play(m.Color, m.Location); err != nil {
+			t.Fatalf("Unexpected error playing %s: %v", m.Location, err)
+		}
+	}
+	if board.Captures("B") != 1 || board.Captures("w") != 0 {
+		t.Errorf("Expected black to capture one stone, got %d/%d", board.Captures("b"), board.Captures("w"))
+	}
+	if board.LastMove() != "A2" {
+		t.Errorf("Expected last move A2, got %q", board.LastMove())
+	}
+	// A1 is empty again, but playing there is suicide for white
+	if err := board.Play("w", "A1"); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument || !strings.Contains(err.Error(), "suicide") {
+		t.Errorf("Expected suicide error, got %v", err)
+	}
+	if err := board.Play("w", "B1"); err == nil || !strings.Contains(err.Error(), "occupied") {
+		t.Errorf("Expected occupied error, got %v", err)
+	}
+}
+
+func TestBoardKo(t *testing.T) {
+	// Black surrounds D5 and white surrounds E5, forming a ko
+	position := &Position{
+		BoardXSize: 9,
+		BoardYSize: 9,
+		Moves: []Move{
+			{"b", "C5"}, {"w", "F5"},
+			{"b", "D4"}, {"w", "E4"},
+			{"b", "D6"}, {"w", "E6"},
+			{"b", "E5"}, {"w", "D5"}, // White captures E5
+		},
+	}
+	board, err := BoardFromPosition(position)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if board.Captures("w") != 1 {
+		t.Fatalf("Expected white to capture E5, got %d captures", board.Captures("w"))
+	}
+	if err := board.Play("b", "E5"); err == nil || !strings.Contains(err.Error(), "ko") {
+		t.Errorf("Expected ko error, got %v", err)
+	}
+
+	// After a move elsewhere the ko may be retaken
+	if err := board.Play("b", "A1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := board.Play("w", "A9"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := board.Play("b", "E5"); err != nil {
+		t.Errorf("Expected ko recapture to be legal after a threat, got %v", err)
+	}
+}
+
+func TestBoardFromPositionIllegalMove(t *testing.T) {
+	position := &Position{
+		BoardXSize: 9,
+		BoardYSize: 9,
+		Moves:      []Move{{"b", "E5"}, {"w", "E5"}},
+	}
+	if _, err := BoardFromPosition(position); err == nil || !strings.Contains(err.Error(), "move 2") {
+		t.Errorf("Expected error for move 2, got %v", err)
+	}
+	if _, err := BoardFromPosition(&Position{BoardXSize: -1, BoardYSize: 19}); err == nil {
+		t.Error("Expected error for a negative board size")
+	}
+}
+
+func TestBoardIsLegal(t *testing.T) {
+	board := NewBoard(9, 9)
+	for _, move := range []Move{{"b", "A2"}, {"b", "B1"}} {
+		if err := board.Play(move.Color, move.Location); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if board.IsLegal("w", "A2") {
+		t.Error("Expected occupied point to be illegal")
+	}
+	if board.IsLegal("w", "A1") {
+		t.Error("Expected suicide to be illegal")
+	}
+	if !board.IsLegal("b", "A1") {
+		t.Error("Expected filling own eye to be legal")
+	}
+	if board.Stone("A1") != "" || board.Stone("A2") != "b" || board.Stone("Z99") != "" {
+		t.Errorf("IsLegal changed the board:\n%s", board)
+	}
+}
+
+func TestBoardHash(t *testing.T) {
+	// The same stones reached by different moves, or placed, hash alike
+	a, b := NewBoard(9, 9), NewBoard(9, 9)
+	for _, move := range []Move{{"b", "C3"}, {"w", "G7"}, {"b", "C7"}} {
+		if err := a.Play(move.Color, move.Location); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	for _, move := range []Move{{"b", "C7"}, {"w", "G7"}, {"b", "C3"}} {
+		if err := b.Play(move.Color, move.Location); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	placed, err := BoardFromPosition(&Position{BoardXSize: 9, BoardYSize: 9, InitialStones: []Stone{
+		{Color: "B", Location: "C3"}, {Color: "B", Location: "C7"}, {Color: "W", Location: "G7"},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if a.Hash() != b.Hash() || a.Hash() != placed.Hash() {
+		t.Errorf("Expected equal hashes, got %x, %x and %x", a.Hash(), b.Hash(), placed.Hash())
+	}
+	if a.Hash() == NewBoard(9, 9).Hash() {
+		t.Error("Expected stones to change the hash")
+	}
+
+	// Capturing the stone played restores the hash of the board before it
+	board := NewBoard(9, 9)
+	for _, move := range []Move{{"b", "B1"}, {"b", "A2"}} {
+		if err := board.Play(move.Color, move.Location); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	before := board.Hash()
+	if err := board.Play("w", "A1"); err == nil {
+		t.Fatal("Expected suicide to be illegal")
+	}
+	if board.Hash() != before {
+		t.Error("Expected a rejected move to leave the hash unchanged")
+	}
+}
@@ -0,0 +1,201 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+const (
+	defaultCompareTopMoves = 5
+	// maxCompareTopMoves bounds the top moves compared from each analysis.
+	maxCompareTopMoves = 20
+	// Two analyses agree when they pick the same best move and their
+	// evaluations are this close.
+	agreeWinrateDiff   = 0.02
+	agreeScoreLeadDiff = 1.0
+)
+
+// AnalysisSetting is one of the two settings a comparison analyzes a
+// position at.
+type AnalysisSetting struct {
+	Label     string // Names the setting in the result, e.g. "fast, 100 visits"
+	MaxVisits int    // Visits for the analysis (default: the engine's)
+	// Analyze runs the analysis, so that the two settings may use
+	// different engines
+	Analyze func(context.Context, *AnalysisRequest) (*AnalysisResult, error)
+}
+
+// CompareOptions controls an analysis comparison.
+type CompareOptions struct {
+	TopMoves int // Top moves compared from each analysis (default: 5)
+}
+
+// ComparisonSide is the evaluation of the position at one setting.
+type ComparisonSide struct {
+	Label     string   `json:"label"`
+	MaxVisits int      `json:"maxVisits,omitempty"`
+	Visits    int      `json:"visits"` // Visits KataGo spent on the position
+	Winrate   float64  `json:"winrate"`
+	ScoreLead float64  `json:"scoreLead"`
+	BestMove  string   `json:"bestMove,omitempty"`
+	TopMoves  []string `json:"topMoves"`
+	Seconds   float64  `json:"seconds"` // Time the analysis took
+}
+
+// ComparedMove is a candidate move as each analysis ranks it.
+type ComparedMove struct {
+	Move string `json:"move"`
+	// RankA and RankB are the move's 1-based rank among each analysis's
+	// top moves, 0 when it is not among them.
+	RankA int `json:"rankA"`
+	RankB int `json:"rankB"`
+	// WinrateA and WinrateB are unset when an analysis did not search the
+	// move.
+	WinrateA *float64 `json:"winrateA,omitempty"`
+	WinrateB *float64 `json:"winrateB,omitempty"`
+}
+
+// AnalysisComparison reports how the analyses of one position at two
+// settings differ.
+type AnalysisComparison struct {
+	CurrentPlayer string         `json:"currentPlayer"`
+	A             ComparisonSide `json:"a"`
+	B             ComparisonSide `json:"b"`
+	// WinrateDiff and ScoreLeadDiff are B minus A.
+	WinrateDiff   float64 `json:"winrateDiff"`
+	ScoreLeadDiff float64 `json:"scoreLeadDiff"`
+	SameBestMove  bool    `json:"sameBestMove"`
+	// TopMoveOverlap counts the moves among both analyses' top moves.
+	TopMoveOverlap int `json:"topMoveOverlap"`
+	// Agree is set when the best moves match and the win rates are within
+	// 2% and the score leads within a point.
+	Agree bool           `json:"agree"`
+	Moves []ComparedMove `json:"moves"` // A's top moves, then B's others
+}
+
+// CompareAnalyses analyzes a position at two settings in parallel and
+// reports how the evaluations and top moves differ, to show whether the
+// cheaper setting reaches the same conclusions.
+func CompareAnalyses(ctx context.Context, position *Position, a, b AnalysisSetting, opts *CompareOptions) (*AnalysisComparison, error) {
+	if err := ValidatePosition(position); err != nil {
+		return nil, fmt.Errorf("invalid position: %w", err)
+	}
+	topMoves := defaultCompareTopMoves
+	if opts != nil && opts.TopMoves > 0 {
+		topMoves = opts.TopMoves
+	}
+	if topMoves > maxCompareTopMoves {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "at most %d top moves can be compared", maxCompareTopMoves)
+	}
+
+	settings := []AnalysisSetting{a, b}
+	results := make([]*AnalysisResult, len(settings))
+	durations := make([]time.Duration, len(settings))
+	errs := make([]error, len(settings))
+	var wg sync.WaitGroup
+	for i, setting := range settings {
+		wg.Add(1)
+		go func(i int, setting AnalysisSetting) {
+			defer wg.Done()
+			req := &AnalysisRequest{Position: position}
+			if setting.MaxVisits > 0 {
+				visits := setting.MaxVisits
+				req.MaxVisits = &visits
+			}
+			start := time.Now()
+			results[i], errs[i] = setting.Analyze(ctx, req)
+			durations[i] = time.Since(start)
+		}(i, setting)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze at %s: %w", settings[i].Label, err)
+		}
+	}
+
+	comparison := &AnalysisComparison{
+		CurrentPlayer: results[0].RootInfo.CurrentPlayer,
+		A:             comparisonSide(a, results[0], durations[0], topMoves),
+		B:             comparisonSide(b, results[1], durations[1], topMoves),
+	}
+	comparison.WinrateDiff = comparison.B.Winrate - comparison.A.Winrate
+	comparison.ScoreLeadDiff = roundTenth(comparison.B.ScoreLead - comparison.A.ScoreLead)
+	comparison.SameBestMove = comparison.A.BestMove != "" && strings.EqualFold(comparison.A.BestMove, comparison.B.BestMove)
+	comparison.Agree = comparison.SameBestMove &&
+		math.Abs(comparison.WinrateDiff) < agreeWinrateDiff && math.Abs(comparison.ScoreLeadDiff) < agreeScoreLeadDiff
+
+	winrates := []map[string]float64{moveWinrates(results[0]), moveWinrates(results[1])}
+	seen := make(map[string]bool)
+	for _, move := range append(append([]string{}, comparison.A.TopMoves...), comparison.B.TopMoves...) {
+		key := strings.ToUpper(move)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		compared := ComparedMove{
+			Move:  move,
+			RankA: moveRank(comparison.A.TopMoves, move),
+			RankB: moveRank(comparison.B.TopMoves, move),
+		}
+		if w, ok := winrates[0][key]; ok {
+			compared.WinrateA = &w
+		}
+		if w, ok := winrates[1][key]; ok {
+			compared.WinrateB = &w
+		}
+		if compared.RankA > 0 && compared.RankB > 0 {
+			comparison.TopMoveOverlap++
+		}
+		comparison.Moves = append(comparison.Moves, compared)
+	}
+	return comparison, nil
+}
+
+// comparisonSide summarizes one analysis of a comparison.
+func comparisonSide(setting AnalysisSetting, result *AnalysisResult, took time.Duration, topMoves int) ComparisonSide {
+	side := ComparisonSide{
+		Label:     setting.Label,
+		MaxVisits: setting.MaxVisits,
+		Visits:    result.RootInfo.Visits,
+		Winrate:   result.RootInfo.Winrate,
+		ScoreLead: result.RootInfo.ScoreLead,
+		TopMoves:  []string{},
+		Seconds:   math.Round(took.Seconds()*100) / 100,
+	}
+	for _, info := range result.MoveInfos {
+		if len(side.TopMoves) == topMoves {
+			break
+		}
+		side.TopMoves = append(side.TopMoves, info.Move)
+	}
+	if len(side.TopMoves) > 0 {
+		side.BestMove = side.TopMoves[0]
+	}
+	return side
+}
+
+// moveWinrates maps each searched move, upper-cased, to its win rate.
+func moveWinrates(result *AnalysisResult) map[string]float64 {
+	winrates := make(map[string]float64, len(result.MoveInfos))
+	for _, info := range result.MoveInfos {
+		winrates[strings.ToUpper(info.Move)] = info.Winrate
+	}
+	return winrates
+}
+
+// moveRank returns the 1-based position of move in moves, or 0.
+func moveRank(moves []string, move string) int {
+	for i, m := range moves {
+		if strings.EqualFold(m, move) {
+			return i + 1
+		}
+	}
+	return 0
+}
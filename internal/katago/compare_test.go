@@ -0,0 +1,76 @@
+package katago
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+func TestCompareAnalyses(t *testing.T) {
+	position := &Position{Rules: "chinese", BoardXSize: 19, BoardYSize: 19, Komi: 7.5,
+		Moves: []Move{{Color: "b", Location: "D4"}}}
+	var requests []*AnalysisRequest
+	fast := AnalysisSetting{Label: "100 visits", MaxVisits: 100, Analyze: func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		requests = append(requests, req)
+		return &AnalysisResult{
+			RootInfo: RootInfo{Visits: 100, Winrate: 0.47, ScoreLead: -0.6, CurrentPlayer: "W"},
+			MoveInfos: []MoveInfo{
+				{Move: "Q16", Winrate: 0.47}, {Move: "Q4", Winrate: 0.46}, {Move: "R16", Winrate: 0.4},
+			},
+		}, nil
+	}}
+	deep := AnalysisSetting{Label: "5000 visits", MaxVisits: 5000, Analyze: func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		return &AnalysisResult{
+			RootInfo: RootInfo{Visits: 5000, Winrate: 0.48, ScoreLead: -0.2, CurrentPlayer: "W"},
+			MoveInfos: []MoveInfo{
+				{Move: "Q16", Winrate: 0.48}, {Move: "D16", Winrate: 0.47}, {Move: "Q4", Winrate: 0.47},
+			},
+		}, nil
+	}}
+
+	comparison, err := CompareAnalyses(context.Background(), position, fast, deep, &CompareOptions{TopMoves: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(requests) != 1 || *requests[0].MaxVisits != 100 {
+		t.Errorf("Expected the fast setting analyzed at 100 visits, got %+v", requests)
+	}
+	if !comparison.SameBestMove || !comparison.Agree || comparison.TopMoveOverlap != 1 {
+		t.Errorf("Expected agreement on Q16 with one shared top move, got %+v", comparison)
+	}
+	if comparison.ScoreLeadDiff != 0.4 || comparison.WinrateDiff < 0.0099 || comparison.WinrateDiff > 0.0101 {
+		t.Errorf("Unexpected differences: %+v", comparison)
+	}
+	if len(comparison.Moves) != 3 || comparison.Moves[2].Move != "D16" || comparison.Moves[2].RankA != 0 || comparison.Moves[2].WinrateA != nil {
+		t.Fatalf("Expected D16 only among the deep top moves, got %+v", comparison.Moves)
+	}
+	if q4 := comparison.Moves[1]; q4.RankA != 2 || q4.RankB != 0 || q4.WinrateB == nil || *q4.WinrateB != 0.47 {
+		t.Errorf("Expected Q4 second at 100 visits and searched at 5000, got %+v", q4)
+	}
+
+	// A different best move is a disagreement
+	comparison, err = CompareAnalyses(context.Background(), position, deep, AnalysisSetting{Label: "other", Analyze: func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		if req.MaxVisits != nil {
+			t.Errorf("Expected the engine's visits, got %d", *req.MaxVisits)
+		}
+		return &AnalysisResult{RootInfo: RootInfo{Winrate: 0.48}, MoveInfos: []MoveInfo{{Move: "D16"}}}, nil
+	}}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if comparison.SameBestMove || comparison.Agree {
+		t.Errorf("Expected disagreement, got %+v", comparison)
+	}
+
+	failing := AnalysisSetting{Label: "broken", Analyze: func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		return nil, errors.New("engine crashed")
+	}}
+	if _, err := CompareAnalyses(context.Background(), position, fast, failing, nil); err == nil {
+		t.Error("Expected the failing analysis reported")
+	}
+	if _, err := CompareAnalyses(context.Background(), position, fast, deep, &CompareOptions{TopMoves: 21}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
@@ -0,0 +1,165 @@
+package katago
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// Analysis protocol features that differ between KataGo releases.
+const (
+	FeatureScoreLead      = "scoreLead"      // scoreLead reported separately from scoreMean
+	FeaturePVVisits       = "pvVisits"       // includePVVisits
+	FeatureMovesOwnership = "movesOwnership" // includeMovesOwnership
+	FeatureRawErrors      = "rawErrors"      // rawStWrError, rawStScoreError and rawVarTimeLeft
+	FeatureHumanSL        = "humanSL"        // humanSLProfile and humanPolicy
+)
+
+// protocolFeatures lists the first KataGo release supporting each feature,
+// oldest first.
+var protocolFeatures = []struct {
+	name  string
+	since Version
+}{
+	{FeatureScoreLead, Version{1, 4, 0}},
+	{FeaturePVVisits, Version{1, 10, 0}},
+	{FeatureMovesOwnership, Version{1, 11, 0}},
+	{FeatureRawErrors, Version{1, 12, 0}},
+	{FeatureHumanSL, Version{1, 15, 0}},
+}
+
+// Version is a KataGo release number.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// ParseVersion extracts a release number from strings such as "1.14.1"
+// or "KataGo v1.14.1".
+func ParseVersion(s string) (Version, error) {
+	m := versionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, fmt.Errorf("no version number in %q", s)
+	}
+	var v Version
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	return v, nil
+}
+
+// AtLeast reports whether v is the same release as other or a later one.
+func (v Version) AtLeast(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch >= other.Patch
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Capabilities describes the protocol spoken by a running KataGo, as found
+// by asking it for its version at startup. Until the version is known every
+// feature is assumed to be supported.
+type Capabilities struct {
+	Version     string   `json:"version,omitempty"`
+	GitHash     string   `json:"gitHash,omitempty"`
+	Features    []string `json:"features,omitempty"`
+	Unsupported []string `json:"unsupported,omitempty"`
+}
+
+// CapabilitiesFor returns the capabilities of a KataGo release.
+func CapabilitiesFor(version string) (Capabilities, error) {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return Capabilities{}, err
+	}
+	caps := Capabilities{Version: version}
+	for _, feature := range protocolFeatures {
+		if v.AtLeast(feature.since) {
+			caps.Features = append(caps.Features, feature.name)
+		} else {
+			caps.Unsupported = append(caps.Unsupported, feature.name)
+		}
+	}
+	return caps, nil
+}
+
+// Known reports whether the engine's version has been negotiated.
+func (c Capabilities) Known() bool {
+	return c.Version != ""
+}
+
+// Supports reports whether the engine supports a feature.
+func (c Capabilities) Supports(feature string) bool {
+	for _, name := range c.Unsupported {
+		if name == feature {
+			return false
+		}
+	}
+	return true
+}
+
+// RequiredVersion returns the first release supporting a feature.
+func RequiredVersion(feature string) string {
+	for _, f := range protocolFeatures {
+		if f.name == feature {
+			return f.since.String()
+		}
+	}
+	return ""
+}
+
+// adaptQuery removes fields an older KataGo does not understand. Optional
+// extras are dropped and returned so the caller can log them; a query that
+// cannot be answered without a missing feature is rejected.
+func (c Capabilities) adaptQuery(query map[string]interface{}) ([]string, error) {
+	var dropped []string
+	drop := func(feature, field string) {
+		if c.Supports(feature) {
+			return
+		}
+		if v, ok := query[field]; ok {
+			if v == true {
+				dropped = append(dropped, field)
+			}
+			delete(query, field)
+		}
+	}
+	drop(FeaturePVVisits, "includePVVisits")
+	drop(FeatureMovesOwnership, "includeMovesOwnership")
+
+	if overrides, ok := query["overrideSettings"].(map[string]interface{}); ok && !c.Supports(FeatureHumanSL) {
+		if _, ok := overrides["humanSLProfile"]; ok {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument,
+				"KataGo %s does not support human SL profiles (requires %s)", c.Version, RequiredVersion(FeatureHumanSL))
+		}
+	}
+	return dropped, nil
+}
+
+// adaptResponse fills fields an older KataGo reports differently.
+func (c Capabilities) adaptResponse(resp *Response) {
+	if c.Supports(FeatureScoreLead) {
+		return
+	}
+	// Before scoreLead existed, scoreMean was the lead
+	for i := range resp.MoveInfos {
+		if resp.MoveInfos[i].ScoreLead == 0 {
+			resp.MoveInfos[i].ScoreLead = resp.MoveInfos[i].ScoreMean
+		}
+	}
+	if resp.RootInfo.ScoreLead == 0 {
+		resp.RootInfo.ScoreLead = resp.RootInfo.ScoreMean
+	}
+}
@@ -0,0 +1,128 @@
+package katago
+
+import (
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Version
+	}{
+		{"1.14.1", Version{1, 14, 1}},
+		{"KataGo v1.15.3", Version{1, 15, 3}},
+		{"1.9", Version{1, 9, 0}},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseVersion(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+	if _, err := ParseVersion("unknown"); err == nil {
+		t.Error("Expected error for a string without a version")
+	}
+
+	if !(Version{1, 10, 0}).AtLeast(Version{1, 9, 5}) || (Version{1, 9, 5}).AtLeast(Version{1, 10, 0}) {
+		t.Error("Expected minor versions to compare numerically")
+	}
+	if !(Version{2, 0, 0}).AtLeast(Version{1, 15, 0}) || !(Version{1, 12, 0}).AtLeast(Version{1, 12, 0}) {
+		t.Error("Expected later and equal versions to satisfy AtLeast")
+	}
+}
+
+// TestCompatibilityMatrix checks which features each supported KataGo
+// release line is expected to have.
+func TestCompatibilityMatrix(t *testing.T) {
+	tests := []struct {
+		version     string
+		unsupported []string
+	}{
+		{"1.3.5", []string{FeatureScoreLead, FeaturePVVisits, FeatureMovesOwnership, FeatureRawErrors, FeatureHumanSL}},
+		{"1.10.0", []string{FeatureMovesOwnership, FeatureRawErrors, FeatureHumanSL}},
+		{"1.12.4", []string{FeatureHumanSL}},
+		{"1.14.1", []string{FeatureHumanSL}},
+		{"1.15.3", nil},
+	}
+	for _, tt := range tests {
+		caps, err := CapabilitiesFor(tt.version)
+		if err != nil {
+			t.Fatalf("CapabilitiesFor(%q): %v", tt.version, err)
+		}
+		if len(caps.Unsupported) != len(tt.unsupported) {
+			t.Errorf("%s: expected unsupported %v, got %v", tt.version, tt.unsupported, caps.Unsupported)
+			continue
+		}
+		for _, feature := range tt.unsupported {
+			if caps.Supports(feature) {
+				t.Errorf("%s: expected %s to be unsupported", tt.version, feature)
+			}
+		}
+		if len(caps.Features)+len(caps.Unsupported) != len(protocolFeatures) {
+			t.Errorf("%s: expected every feature to be classified, got %+v", tt.version, caps)
+		}
+	}
+
+	// Until negotiated, everything is assumed to work
+	var unknown Capabilities
+	if unknown.Known() || !unknown.Supports(FeatureHumanSL) {
+		t.Error("Expected unknown capabilities to support every feature")
+	}
+}
+
+func TestAdaptQuery(t *testing.T) {
+	caps, _ := CapabilitiesFor("1.10.2")
+	query := map[string]interface{}{
+		"includePVVisits":       true,
+		"includeMovesOwnership": true,
+		"includeOwnership":      true,
+	}
+	dropped, err := caps.adaptQuery(query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(dropped) != 1 || dropped[0] != "includeMovesOwnership" {
+		t.Errorf("Expected includeMovesOwnership to be dropped, got %v", dropped)
+	}
+	if _, ok := query["includeMovesOwnership"]; ok {
+		t.Error("Expected includeMovesOwnership to be removed from the query")
+	}
+	if query["includePVVisits"] != true || query["includeOwnership"] != true {
+		t.Errorf("Expected supported fields to be kept, got %v", query)
+	}
+
+	// Human SL profiles change the analysis, so they cannot be dropped
+	_, err = caps.adaptQuery(map[string]interface{}{
+		"overrideSettings": map[string]interface{}{"humanSLProfile": "rank_5k"},
+	})
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected invalid argument for human SL on 1.10.2, got %v", err)
+	}
+	current, _ := CapabilitiesFor("1.15.0")
+	if _, err := current.adaptQuery(map[string]interface{}{
+		"overrideSettings": map[string]interface{}{"humanSLProfile": "rank_5k"},
+	}); err != nil {
+		t.Errorf("Expected human SL to be allowed on 1.15.0, got %v", err)
+	}
+}
+
+func TestAdaptResponse(t *testing.T) {
+	resp := &Response{
+		MoveInfos: []MoveInfo{{Move: "D4", ScoreMean: 2.5}},
+		RootInfo:  RootInfo{ScoreMean: 1.5},
+	}
+	old, _ := CapabilitiesFor("1.3.5")
+	old.adaptResponse(resp)
+	if resp.MoveInfos[0].ScoreLead != 2.5 || resp.RootInfo.ScoreLead != 1.5 {
+		t.Errorf("Expected scoreMean to fill scoreLead, got %+v", resp)
+	}
+
+	resp = &Response{RootInfo: RootInfo{ScoreMean: 1.5, ScoreLead: 1.0}}
+	current, _ := CapabilitiesFor("1.14.1")
+	current.adaptResponse(resp)
+	if resp.RootInfo.ScoreLead != 1.0 {
+		t.Errorf("Expected scoreLead to be left alone, got %v", resp.RootInfo.ScoreLead)
+	}
+}
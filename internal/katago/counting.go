@@ -0,0 +1,119 @@
+package katago
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CountingRegions are the quarters of the board a counting quiz compares,
+// named as ExpandMoveRegion names them.
+var CountingRegions = []string{"top-left", "top-right", "bottom-left", "bottom-right"}
+
+// RegionCount is KataGo's count of one quarter of the board, and the
+// guess for it if one was made.
+type RegionCount struct {
+	Region     string   `json:"region"`
+	Black      int      `json:"black"` // Points Black owns, stones included
+	White      int      `json:"white"` // Points White owns, stones included
+	Lead       int      `json:"lead"`  // Black's lead in the region
+	DeadStones []string `json:"deadStones,omitempty"`
+	Guess      *float64 `json:"guess,omitempty"` // The guessed Black lead
+	Error      *float64 `json:"error,omitempty"` // Guess less Lead
+}
+
+// CountingAnswer is KataGo's count of a position compared with a guess of
+// the result. Leads are Black's, as KataGo reports them with
+// reportAnalysisWinratesAs = BLACK.
+type CountingAnswer struct {
+	Komi        float64 `json:"komi"`
+	BlackPoints int     `json:"blackPoints"`
+	WhitePoints int     `json:"whitePoints"`
+	DamePoints  int     `json:"damePoints"` // Points neither player owns beyond the threshold
+	// ScoreLead is KataGo's estimate of the result, komi included, and
+	// Result the same as a result string, e.g. "W+2.5"
+	ScoreLead   float64       `json:"scoreLead"`
+	Result      string        `json:"result"`
+	Regions     []RegionCount `json:"regions"`
+	Guess       float64       `json:"guess"`
+	GuessResult string        `json:"guessResult"`
+	Error       float64       `json:"error"`   // Guess less ScoreLead
+	Correct     bool          `json:"correct"` // Error is within the margin CheckResult allows
+}
+
+// countingRegion returns the quarter of the board a point is counted in.
+// The center lines of odd-sized boards count with the top and left
+// quarters, as ExpandMoveRegion's quarters include them.
+func countingRegion(x, y, xSize, ySize int) int {
+	region := 0
+	if x >= (xSize+1)/2 {
+		region++
+	}
+	if y >= (ySize+1)/2 {
+		region += 2
+	}
+	return region
+}
+
+// GradeCounting counts a position from result, an analysis requested with
+// IncludeOwnership, and compares the count with a guess of Black's lead.
+// Points owned beyond threshold count for their owner, and stones on
+// points the other player owns are dead. regionGuesses, Black's lead
+// guessed in any of CountingRegions, may be nil.
+func GradeCounting(position *Position, result *AnalysisResult, threshold, guess float64, regionGuesses map[string]float64) (*CountingAnswer, error) {
+	xSize, ySize := position.BoardXSize, position.BoardYSize
+	if len(result.Ownership) < xSize*ySize {
+		return nil, fmt.Errorf("no ownership data returned")
+	}
+	for region := range regionGuesses {
+		if !contains(CountingRegions, region) {
+			return nil, fmt.Errorf("unknown region %q: must be one of %s", region, strings.Join(CountingRegions, ", "))
+		}
+	}
+	board, err := BoardFromPosition(position)
+	if err != nil {
+		return nil, err
+	}
+
+	answer := &CountingAnswer{
+		Komi:        position.Komi,
+		ScoreLead:   roundTenth(result.RootInfo.ScoreLead),
+		Result:      formatResult(result.RootInfo.ScoreLead),
+		Guess:       guess,
+		GuessResult: formatResult(guess),
+	}
+	answer.Error = roundTenth(guess - result.RootInfo.ScoreLead)
+	answer.Correct = math.Abs(answer.Error) <= resultMarginTolerance
+	for _, region := range CountingRegions {
+		answer.Regions = append(answer.Regions, RegionCount{Region: region})
+	}
+
+	for i, ownership := range result.Ownership[:xSize*ySize] {
+		x, y := i%xSize, i/xSize
+		region := &answer.Regions[countingRegion(x, y, xSize, ySize)]
+		owner := territoryOwner(ownership, threshold)
+		switch owner {
+		case "B":
+			answer.BlackPoints++
+			region.Black++
+		case "W":
+			answer.WhitePoints++
+			region.White++
+		default:
+			answer.DamePoints++
+		}
+		if stone := strings.ToUpper(board.points[i]); stone != "" && owner != "?" && stone != owner {
+			region.DeadStones = append(region.DeadStones, coordToString(x, y, ySize))
+		}
+	}
+
+	for i := range answer.Regions {
+		region := &answer.Regions[i]
+		region.Lead = region.Black - region.White
+		if guess, ok := regionGuesses[region.Region]; ok {
+			regionErr := guess - float64(region.Lead)
+			region.Guess, region.Error = &guess, &regionErr
+		}
+	}
+	return answer, nil
+}
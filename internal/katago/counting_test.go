@@ -0,0 +1,73 @@
+package katago
+
+import "testing"
+
+func TestGradeCounting(t *testing.T) {
+	// A 5x5 board: Black owns columns A-C, where White's B4 is dead, and
+	// White owns D and E. C3 is undecided.
+	position, err := NewSGFParser("(;GM[1]SZ[5]KM[0.5];B[aa];W[bb];B[ee])").Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := &AnalysisResult{RootInfo: RootInfo{ScoreLead: 3.5}}
+	for i := 0; i < 25; i++ {
+		ownership := -0.9
+		if i%5 < 3 {
+			ownership = 0.9
+		}
+		result.Ownership = append(result.Ownership, ownership)
+	}
+	result.Ownership[12] = 0.1
+
+	answer, err := GradeCounting(position, result, 0.5, 0.5, map[string]float64{"top-left": 8, "top-right": -4})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if answer.BlackPoints != 14 || answer.WhitePoints != 10 || answer.DamePoints != 1 {
+		t.Errorf("Expected 14 points for Black, 10 for White and 1 undecided, got %+v", answer)
+	}
+	if answer.Result != "B+3.5" || answer.GuessResult != "B+0.5" || answer.Error != -3 || answer.Correct {
+		t.Errorf("Expected the guess 3 points short, got %+v", answer)
+	}
+
+	want := []struct {
+		black, white, lead int
+		err                *float64
+	}{
+		{8, 0, 8, ptr(0.0)},
+		{0, 6, -6, ptr(2.0)},
+		{6, 0, 6, nil},
+		{0, 4, -4, nil},
+	}
+	for i, r := range answer.Regions {
+		w := want[i]
+		if r.Region != CountingRegions[i] || r.Black != w.black || r.White != w.white || r.Lead != w.lead {
+			t.Errorf("Unexpected count of the %s: %+v", CountingRegions[i], r)
+		}
+		if (r.Error == nil) != (w.err == nil) || r.Error != nil && *r.Error != *w.err {
+			t.Errorf("Expected the %s's guess off by %v, got %v", r.Region, w.err, r.Error)
+		}
+	}
+	// White's B4 stands in Black's area, and Black's E1 in White's
+	if dead := answer.Regions[0].DeadStones; len(dead) != 1 || dead[0] != "B4" {
+		t.Errorf("Expected B4 dead in the top-left, got %v", dead)
+	}
+	if dead := answer.Regions[3].DeadStones; len(dead) != 1 || dead[0] != "E1" {
+		t.Errorf("Expected E1 dead in the bottom-right, got %v", dead)
+	}
+
+	if answer, _ := GradeCounting(position, result, 0.5, 4.5, nil); !answer.Correct {
+		t.Errorf("Expected a guess 1 point over to be right, got %+v", answer)
+	}
+	if _, err := GradeCounting(position, result, 0.5, 0, map[string]float64{"center": 1}); err == nil {
+		t.Error("Expected an unknown region to be rejected")
+	}
+	result.Ownership = nil
+	if _, err := GradeCounting(position, result, 0.5, 0, nil); err == nil {
+		t.Error("Expected an error without ownership")
+	}
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
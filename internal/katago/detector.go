@@ -9,17 +9,35 @@ import (
 	"strings"
 )
 
+// KataGo neural network backends, as reported by `katago version`.
+const (
+	BackendCUDA     = "cuda"
+	BackendTensorRT = "tensorrt"
+	BackendOpenCL   = "opencl"
+	BackendEigen    = "eigen"
+)
+
+// containerDirs are well-known install locations in KataGo container images.
+var containerDirs = []string{"/katago", "/app/katago", "/opt/katago"}
+
 type DetectedSetup struct {
 	BinaryPath string
 	ModelPath  string
 	ConfigPath string
 	Version    string
-	Errors     []string
+	Backend    string // Neural network backend of the binary, e.g. "cuda" or "eigen"
+	// ReplacedBinaryPath is the binary found before switching to one with a
+	// suitable backend, or "" if it was kept
+	ReplacedBinaryPath string
+	InContainer        bool
+	Errors             []string
+	Warnings           []string // Problems that do not prevent starting, such as a CPU fallback
 }
 
 func DetectKataGo() (*DetectedSetup, error) {
 	setup := &DetectedSetup{
-		Errors: []string{},
+		Errors:      []string{},
+		InContainer: inContainer(),
 	}
 
 	// 1. Find KataGo binary
@@ -29,10 +47,11 @@ func DetectKataGo() (*DetectedSetup, error) {
 	} else {
 		setup.BinaryPath = binaryPath
 
-		// Get version if binary found
-		if version, vErr := getKataGoVersion(binaryPath); vErr == nil {
-			setup.Version = version
+		// Get version and backend if binary found
+		if output, vErr := getKataGoVersion(binaryPath); vErr == nil {
+			setup.Version, setup.Backend = parseVersionOutput(output)
 		}
+		resolveBackend(setup, os.Getenv("KATAGO_BACKEND"), gpuAvailable, getKataGoVersion)
 	}
 
 	// 2. Find model files
@@ -71,6 +90,10 @@ func findKataGoBinary() (string, error) {
 		"/usr/bin/katago",
 		"/opt/homebrew/bin/katago",
 		"/opt/local/bin/katago",
+		// Container images
+		"/katago/katago",
+		"/app/katago/katago",
+		"/opt/katago/katago",
 		// Windows paths
 		"C:\\Program Files\\KataGo\\katago.exe",
 		"C:\\KataGo\\katago.exe",
@@ -98,16 +121,8 @@ func findKataGoBinary() (string, error) {
 			}
 		}
 
-		// Check if file exists and is executable
-		if info, err := os.Stat(path); err == nil && !info.IsDir() {
-			// On Windows, .exe files are always executable
-			if runtime.GOOS == "windows" && strings.HasSuffix(path, ".exe") {
-				return path, nil
-			}
-			// On Unix, check execute permission
-			if runtime.GOOS != "windows" && info.Mode()&0o111 != 0 {
-				return path, nil
-			}
+		if isExecutable(path) {
+			return path, nil
 		}
 	}
 
@@ -143,6 +158,12 @@ func findKataGoModel() (string, error) {
 		"/opt/katago/models",
 	)
 
+	// Container images
+	for _, dir := range containerDirs {
+		searchDirs = append(searchDirs, dir, filepath.Join(dir, "models"))
+	}
+	searchDirs = append(searchDirs, "/app/models", "/models")
+
 	// Windows paths
 	if runtime.GOOS == "windows" {
 		searchDirs = append(searchDirs,
@@ -205,7 +226,9 @@ func findOrGenerateConfig(binaryPath, modelPath string) (string, error) {
 		"/usr/local/share/katago",
 		"/usr/share/katago",
 		"/etc/katago",
+		"/app/config",
 	)
+	searchDirs = append(searchDirs, containerDirs...)
 
 	for _, dir := range searchDirs {
 		for _, name := range configNames {
@@ -237,6 +260,178 @@ func getKataGoVersion(binaryPath string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// parseVersionOutput extracts the version line and backend from the output
+// of `katago version`, e.g. "KataGo v1.14.1" and "Using CUDA backend".
+func parseVersionOutput(output string) (version, backend string) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if version == "" && line != "" {
+			version = line
+		}
+		lower := strings.ToLower(line)
+		if strings.HasPrefix(lower, "using ") && strings.HasSuffix(lower, " backend") {
+			backend = normalizeBackend(strings.TrimSuffix(strings.TrimPrefix(lower, "using "), " backend"))
+		}
+	}
+	return version, backend
+}
+
+// normalizeBackend maps backend names such as "Eigen(CPU)" or "CUDA" to
+// the Backend constants.
+func normalizeBackend(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case name == "":
+		return ""
+	case strings.HasPrefix(name, "eigen"), name == "cpu":
+		return BackendEigen
+	case strings.HasPrefix(name, "tensorrt"), name == "trt":
+		return BackendTensorRT
+	case strings.HasPrefix(name, "cuda"):
+		return BackendCUDA
+	case strings.HasPrefix(name, "opencl"):
+		return BackendOpenCL
+	}
+	return name
+}
+
+// isGPUBackend reports whether a backend needs a GPU.
+func isGPUBackend(backend string) bool {
+	return backend == BackendCUDA || backend == BackendTensorRT || backend == BackendOpenCL
+}
+
+// resolveBackend checks that the detected binary's backend suits the host.
+// A binary for the backend requested with KATAGO_BACKEND is preferred when
+// installed next to it as katago-<backend>, and a GPU binary on a host
+// without a GPU falls back to an Eigen (CPU) binary when one is available.
+func resolveBackend(setup *DetectedSetup, preferred string, hasGPU func(backend string) bool,
+	versionOf func(binaryPath string) (string, error)) {
+	// use switches to another binary if its version output confirms the backend
+	use := func(backend string) bool {
+		path := findBackendBinary(filepath.Dir(setup.BinaryPath), backend)
+		if path == "" {
+			return false
+		}
+		output, err := versionOf(path)
+		if err != nil {
+			return false
+		}
+		version, actual := parseVersionOutput(output)
+		if actual != backend {
+			return false
+		}
+		if setup.ReplacedBinaryPath == "" {
+			setup.ReplacedBinaryPath = setup.BinaryPath
+		}
+		setup.BinaryPath, setup.Version, setup.Backend = path, version, actual
+		return true
+	}
+
+	if preferred = normalizeBackend(preferred); preferred != "" && preferred != setup.Backend {
+		from := setup.Backend
+		if !use(preferred) {
+			setup.Warnings = append(setup.Warnings, fmt.Sprintf(
+				"KATAGO_BACKEND is %s but %s uses the %s backend and no katago-%s binary was found",
+				preferred, setup.BinaryPath, backendName(from), preferred))
+		}
+	}
+
+	if isGPUBackend(setup.Backend) && !hasGPU(setup.Backend) {
+		gpuBackend, gpuBinary := setup.Backend, setup.BinaryPath
+		if use(BackendEigen) {
+			setup.Warnings = append(setup.Warnings, fmt.Sprintf(
+				"No GPU available for the %s build at %s; falling back to the CPU (Eigen) build at %s. Analysis will be much slower",
+				gpuBackend, gpuBinary, setup.BinaryPath))
+		} else {
+			setup.Warnings = append(setup.Warnings, fmt.Sprintf(
+				"No GPU available for the %s build at %s, so KataGo will likely fail to start. "+
+					"Expose a GPU to the container, or install a CPU (Eigen) build as katago-eigen to fall back automatically",
+				gpuBackend, gpuBinary))
+		}
+	}
+}
+
+// backendName returns a backend for messages.
+func backendName(backend string) string {
+	if backend == "" {
+		return "an unknown"
+	}
+	return "the " + backend
+}
+
+// findBackendBinary looks for a katago-<backend> binary next to the
+// detected binary and in container install locations.
+func findBackendBinary(dir, backend string) string {
+	names := []string{"katago-" + backend}
+	if backend == BackendEigen {
+		names = append(names, "katago-eigenavx2", "katago-cpu")
+	}
+	dirs := append([]string{dir}, containerDirs...)
+	dirs = append(dirs, "/usr/local/bin")
+	for _, d := range dirs {
+		for _, name := range names {
+			path := filepath.Join(d, name)
+			if runtime.GOOS == "windows" {
+				path += ".exe"
+			}
+			if isExecutable(path) {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// gpuAvailable reports whether the host exposes a GPU usable by a backend.
+// Device nodes can only be checked on Linux; elsewhere a GPU is assumed.
+func gpuAvailable(backend string) bool {
+	if runtime.GOOS != "linux" {
+		return true
+	}
+	patterns := []string{"/dev/nvidia[0-9]*"}
+	if backend == BackendOpenCL {
+		// AMD and Intel GPUs through the DRM render nodes
+		patterns = append(patterns, "/dev/dri/renderD*", "/dev/kfd")
+	}
+	for _, pattern := range patterns {
+		if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// inContainer reports whether the server runs inside a container.
+func inContainer() bool {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true
+	}
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	data, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+	cgroup := string(data)
+	return strings.Contains(cgroup, "docker") || strings.Contains(cgroup, "kubepods") || strings.Contains(cgroup, "containerd")
+}
+
+// isExecutable reports whether path is an executable file.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	// On Windows, .exe files are always executable
+	if runtime.GOOS == "windows" {
+		return strings.HasSuffix(path, ".exe")
+	}
+	return info.Mode()&0o111 != 0
+}
+
 func getHomeDir() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -274,6 +469,7 @@ func GetInstallationInstructions() string {
 	instructions.WriteString("  export KATAGO_BINARY_PATH=/path/to/katago\n")
 	instructions.WriteString("  export KATAGO_MODEL_PATH=/path/to/model.bin.gz\n")
 	instructions.WriteString("  export KATAGO_CONFIG_PATH=/path/to/analysis.cfg\n")
+	instructions.WriteString("  export KATAGO_BACKEND=eigen  # Prefer a katago-<backend> binary: cuda, tensorrt, opencl or eigen\n")
 
 	return instructions.String()
 }
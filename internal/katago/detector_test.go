@@ -0,0 +1,92 @@
+package katago
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseVersionOutput(t *testing.T) {
+	tests := []struct {
+		output  string
+		version string
+		backend string
+	}{
+		{"KataGo v1.14.1\nGit revision: abc123\nCompile Time: Jan 1 2024\nUsing CUDA backend\n", "KataGo v1.14.1", BackendCUDA},
+		{"KataGo v1.13.0\nUsing TensorRT backend", "KataGo v1.13.0", BackendTensorRT},
+		{"KataGo v1.12.4\nUsing OpenCL backend", "KataGo v1.12.4", BackendOpenCL},
+		{"KataGo v1.14.1\nCompiled with AVX2 and FMA instructions\nUsing Eigen(CPU) backend", "KataGo v1.14.1", BackendEigen},
+		{"KataGo v1.10.0\n", "KataGo v1.10.0", ""},
+	}
+	for _, tt := range tests {
+		version, backend := parseVersionOutput(tt.output)
+		if version != tt.version || backend != tt.backend {
+			t.Errorf("parseVersionOutput(%q) = %q, %q; want %q, %q", tt.output, version, backend, tt.version, tt.backend)
+		}
+	}
+}
+
+func TestResolveBackend(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test binaries use Unix permissions")
+	}
+	dir := t.TempDir()
+	binaries := map[string]string{
+		"katago":       "KataGo v1.14.1\nUsing CUDA backend",
+		"katago-eigen": "KataGo v1.14.1\nUsing Eigen(CPU) backend",
+	}
+	for name := range binaries {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	versionOf := func(path string) (string, error) {
+		if output, ok := binaries[filepath.Base(path)]; ok {
+			return output, nil
+		}
+		return "", errors.New("not found")
+	}
+	detect := func(preferred string, gpu bool) *DetectedSetup {
+		setup := &DetectedSetup{BinaryPath: filepath.Join(dir, "katago")}
+		setup.Version, setup.Backend = parseVersionOutput(binaries["katago"])
+		resolveBackend(setup, preferred, func(string) bool { return gpu }, versionOf)
+		return setup
+	}
+
+	setup := detect("", true)
+	if setup.Backend != BackendCUDA || len(setup.Warnings) != 0 {
+		t.Errorf("Expected CUDA binary on a GPU host without warnings, got %+v", setup)
+	}
+
+	// No GPU falls back to the Eigen build with a warning
+	setup = detect("", false)
+	if setup.Backend != BackendEigen || setup.BinaryPath != filepath.Join(dir, "katago-eigen") ||
+		setup.ReplacedBinaryPath != filepath.Join(dir, "katago") {
+		t.Errorf("Expected Eigen fallback, got %+v", setup)
+	}
+	if len(setup.Warnings) != 1 || !strings.Contains(setup.Warnings[0], "falling back") {
+		t.Errorf("Expected fallback warning, got %v", setup.Warnings)
+	}
+
+	// KATAGO_BACKEND selects the matching binary
+	setup = detect("Eigen", true)
+	if setup.Backend != BackendEigen || len(setup.Warnings) != 0 {
+		t.Errorf("Expected requested Eigen binary, got %+v", setup)
+	}
+	setup = detect("opencl", true)
+	if setup.Backend != BackendCUDA || len(setup.Warnings) != 1 || !strings.Contains(setup.Warnings[0], "KATAGO_BACKEND") {
+		t.Errorf("Expected mismatch warning for missing OpenCL binary, got %+v", setup)
+	}
+
+	// Without an Eigen build there is nothing to fall back to
+	if err := os.Remove(filepath.Join(dir, "katago-eigen")); err != nil {
+		t.Fatal(err)
+	}
+	setup = detect("", false)
+	if setup.Backend != BackendCUDA || len(setup.Warnings) != 1 || !strings.Contains(setup.Warnings[0], "fail to start") {
+		t.Errorf("Expected no-GPU warning without fallback, got %+v", setup)
+	}
+}
@@ -0,0 +1,201 @@
+package katago
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+)
+
+// Sizes of what an engine keeps for diagnostics bundles.
+const (
+	diagnosticsStderrLines = 200
+	diagnosticsQueries     = 50
+)
+
+// gpuQueryTimeout bounds the nvidia-smi call describing the GPUs.
+const gpuQueryTimeout = 5 * time.Second
+
+// DiagnosticsBundle is what an engine collects when KataGo crashes or stops
+// responding, to find out why without reproducing it.
+type DiagnosticsBundle struct {
+	Time   time.Time `json:"time"`
+	Reason string    `json:"reason"`
+	Engine string    `json:"engine"`
+	// Dir is where the bundle was written, or WriteError why it was not
+	Dir        string `json:"dir,omitempty"`
+	WriteError string `json:"writeError,omitempty"`
+
+	Config config.KataGoConfig `json:"config"`
+	// Settings identifies the models and KataGo's config file, or
+	// SettingsError says why they could not be read
+	Settings      *AnalysisSettings `json:"settings,omitempty"`
+	SettingsError string            `json:"settingsError,omitempty"`
+	Startup       StartupProgress   `json:"startup"`
+	Load          EngineLoad        `json:"load"`
+	System        SystemInfo        `json:"system"`
+
+	Stderr  []string       `json:"stderr"`  // Last lines KataGo printed, oldest first
+	Queries []RecordedLine `json:"queries"` // Last queries sent to KataGo, oldest first
+}
+
+// SystemInfo describes the host the server runs on.
+type SystemInfo struct {
+	OS        string   `json:"os"`
+	Arch      string   `json:"arch"`
+	CPUs      int      `json:"cpus"`
+	GoVersion string   `json:"goVersion"`
+	Hostname  string   `json:"hostname,omitempty"`
+	GPUs      []string `json:"gpus,omitempty"` // Name, driver and memory of each NVIDIA GPU
+}
+
+// lineTail keeps the most recent lines of a stream.
+type lineTail struct {
+	mu    sync.Mutex
+	lines []string
+	next  int // Index of the oldest line once full
+	size  int
+}
+
+func newLineTail(size int) *lineTail {
+	return &lineTail{lines: make([]string, 0, size), size: size}
+}
+
+func (t *lineTail) add(line string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.lines) < t.size {
+		t.lines = append(t.lines, line)
+		return
+	}
+	t.lines[t.next] = line
+	t.next = (t.next + 1) % t.size
+}
+
+// snapshot returns the lines, oldest first.
+func (t *lineTail) snapshot() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	lines := make([]string, 0, len(t.lines))
+	lines = append(lines, t.lines[t.next:]...)
+	return append(lines, t.lines[:t.next]...)
+}
+
+// CollectDiagnostics gathers a diagnostics bundle, writes it to the
+// configured directory if any and keeps it as the latest. Describing the
+// models may hash them, which takes a few seconds the first time.
+func (e *Engine) CollectDiagnostics(reason string) *DiagnosticsBundle {
+	name := e.cacheScope
+	if name == "" {
+		name = config.DefaultEngineName
+	}
+	bundle := &DiagnosticsBundle{
+		Time:    time.Now().UTC(),
+		Reason:  reason,
+		Engine:  name,
+		Config:  *e.config,
+		Startup: e.StartupProgress(),
+		Load:    e.Load(),
+		System:  systemInfo(),
+		Stderr:  e.stderrTail.snapshot(),
+		Queries: e.recentQueries.Lines(),
+	}
+	settings, err := DescribeSettings(name, e.config, e.Capabilities(), nil)
+	if err != nil {
+		bundle.SettingsError = err.Error()
+	} else {
+		bundle.Settings = settings
+	}
+	if e.config.DiagnosticsDir != "" {
+		dir, err := writeDiagnostics(e.config.DiagnosticsDir, bundle)
+		if err != nil {
+			bundle.WriteError = err.Error()
+			e.logger.Error("Failed to write diagnostics bundle", "dir", e.config.DiagnosticsDir, "error", err)
+		} else {
+			bundle.Dir = dir
+		}
+	}
+
+	e.diagnosticsMu.Lock()
+	e.diagnostics = bundle
+	e.diagnosticsMu.Unlock()
+	e.logger.Warn("Collected KataGo diagnostics", "reason", reason, "dir", bundle.Dir)
+	return bundle
+}
+
+// Diagnostics returns the latest diagnostics bundle, or nil if none was
+// collected since the server started.
+func (e *Engine) Diagnostics() *DiagnosticsBundle {
+	e.diagnosticsMu.Lock()
+	defer e.diagnosticsMu.Unlock()
+	return e.diagnostics
+}
+
+// writeDiagnostics writes a bundle to a new directory under root, named for
+// its time and engine, and returns that directory. bundle.json holds the
+// whole bundle; stderr.log, queries.jsonl and katago.cfg repeat parts of it
+// in their usual formats.
+func writeDiagnostics(root string, bundle *DiagnosticsBundle) (string, error) {
+	dir := filepath.Join(root, "crash-"+bundle.Time.Format("20060102T150405.000Z")+"-"+bundle.Engine)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", err
+	}
+
+	var queries strings.Builder
+	if err := WriteRecording(&queries, bundle.Queries); err != nil {
+		return "", err
+	}
+	files := map[string]string{
+		"stderr.log":    strings.Join(bundle.Stderr, "\n") + "\n",
+		"queries.jsonl": queries.String(),
+	}
+	if bundle.Settings != nil && bundle.Settings.ConfigFile != nil {
+		files["katago.cfg"] = bundle.Settings.ConfigFile.Contents
+	}
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	files["bundle.json"] = string(data) + "\n"
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o600); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// systemInfo describes the host, including its NVIDIA GPUs when
+// nvidia-smi is installed.
+func systemInfo() SystemInfo {
+	info := SystemInfo{
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		GoVersion: runtime.Version(),
+	}
+	info.Hostname, _ = os.Hostname()
+	if _, err := exec.LookPath("nvidia-smi"); err != nil {
+		return info
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gpuQueryTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "nvidia-smi",
+		"--query-gpu=name,driver_version,memory.total", "--format=csv,noheader").Output()
+	if err != nil {
+		return info
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			info.GPUs = append(info.GPUs, line)
+		}
+	}
+	return info
+}
@@ -0,0 +1,82 @@
+package katago
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+func TestLineTail(t *testing.T) {
+	tail := newLineTail(3)
+	for _, line := range []string{"a", "b"} {
+		tail.add(line)
+	}
+	if got := strings.Join(tail.snapshot(), ","); got != "a,b" {
+		t.Errorf("Expected a,b, got %s", got)
+	}
+	for _, line := range []string{"c", "d", "e"} {
+		tail.add(line)
+	}
+	if got := strings.Join(tail.snapshot(), ","); got != "c,d,e" {
+		t.Errorf("Expected the last three lines, got %s", got)
+	}
+}
+
+func TestCollectDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "b18.bin.gz")
+	katagoConfig := filepath.Join(dir, "analysis.cfg")
+	if err := os.WriteFile(model, []byte("weights"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(katagoConfig, []byte("numAnalysisThreads = 4\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.KataGoConfig{
+		BinaryPath:     "/usr/bin/katago",
+		ModelPath:      model,
+		ConfigPath:     katagoConfig,
+		DiagnosticsDir: filepath.Join(dir, "diagnostics"),
+	}
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := NewEngine(cfg, logger, nil)
+	if engine.Diagnostics() != nil {
+		t.Fatal("Expected no diagnostics before a crash")
+	}
+	engine.stderrTail.add("CUDA error: out of memory")
+	engine.recentQueries.Record(DirectionQuery, []byte(`{"id":"1","moves":[]}`))
+
+	bundle := engine.CollectDiagnostics("health check failed: timeout")
+	if engine.Diagnostics() != bundle {
+		t.Error("Expected the bundle to be kept as the latest")
+	}
+	if bundle.Engine != config.DefaultEngineName || bundle.WriteError != "" || bundle.SettingsError != "" ||
+		bundle.Settings.Model.Name != "b18" || len(bundle.Stderr) != 1 || len(bundle.Queries) != 1 {
+		t.Fatalf("Unexpected bundle: %+v", bundle)
+	}
+	if !strings.HasPrefix(filepath.Base(bundle.Dir), "crash-") || !strings.HasSuffix(bundle.Dir, "-default") {
+		t.Errorf("Unexpected bundle directory %s", bundle.Dir)
+	}
+
+	for file, want := range map[string]string{
+		"stderr.log":    "CUDA error: out of memory\n",
+		"queries.jsonl": `"data":{"id":"1","moves":[]}`,
+		"katago.cfg":    "numAnalysisThreads = 4\n",
+		"bundle.json":   `"reason": "health check failed: timeout"`,
+	} {
+		data, err := os.ReadFile(filepath.Join(bundle.Dir, file))
+		if err != nil || !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in %s, got %q (%v)", want, file, data, err)
+		}
+	}
+	data, _ := os.ReadFile(filepath.Join(bundle.Dir, "bundle.json"))
+	var written DiagnosticsBundle
+	if err := json.Unmarshal(data, &written); err != nil || written.Config.BinaryPath != "/usr/bin/katago" {
+		t.Errorf("Unexpected bundle.json: %v", err)
+	}
+}
@@ -0,0 +1,329 @@
+package katago
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// diagramKomi is the komi given to positions parsed from a board, which
+// carries none.
+const diagramKomi = 7.5
+
+// Symbols for the points of a text diagram. Stars and hoshi marks are
+// empty points.
+var diagramSymbols = map[rune]string{
+	'.': "", '+': "", ',': "", '·': "",
+	'X': "b", 'x': "b", '#': "b", '@': "b", '●': "b", 'B': "b",
+	'O': "w", 'o': "w", '○': "w", 'W': "w",
+}
+
+// ParseBoard parses a board pasted as text into a position whose stones
+// are its initial stones, with no moves. Two forms are accepted:
+//
+//   - A diagram with one row per line, using '.' or '+' for empty points,
+//     'X' for Black and 'O' for White. Row numbers and a header of column
+//     letters, as printed by GTP showboard, place the diagram on the board.
+//     Sensei's Library diagrams ("$$ | . X O |") are accepted too, and their
+//     edges place a partial diagram in its corner or side.
+//   - A GTP stone list of colors and vertices, such as "B D4 W Q16",
+//     optionally preceded by the set_position command.
+//
+// boardSize is used for stone lists and partial diagrams; when zero it
+// defaults to 19, or to the diagram's size when the diagram shows the whole
+// board. The position has Black to play unless a Sensei's Library title
+// ("$$W") says otherwise, and komi 7.5.
+func ParseBoard(text string, boardSize int) (*Position, error) {
+	if boardSize < 0 || boardSize > 25 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid board size: %d", boardSize)
+	}
+	var position *Position
+	var err error
+	if isStoneList(text) {
+		position, err = parseStoneList(text, boardSize)
+	} else {
+		position, err = parseDiagram(text, boardSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidatePosition(position); err != nil {
+		return nil, err
+	}
+	return position, nil
+}
+
+// isStoneList reports whether text is a GTP stone list rather than a
+// diagram: it starts with set_position, or a color followed by a vertex.
+func isStoneList(text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+	if strings.EqualFold(fields[0], "set_position") {
+		return true
+	}
+	return len(fields) >= 2 && stoneListColor(fields[0]) != "" && isVertex(fields[1])
+}
+
+// parseStoneList parses colors and vertices into initial stones.
+func parseStoneList(text string, boardSize int) (*Position, error) {
+	fields := strings.Fields(text)
+	if strings.EqualFold(fields[0], "set_position") {
+		fields = fields[1:]
+	}
+	if len(fields)%2 != 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "stone list must alternate colors and vertices")
+	}
+	if boardSize == 0 {
+		boardSize = 19
+	}
+	board := NewBoard(boardSize, boardSize)
+	position := newBoardPosition(boardSize, boardSize)
+	for i := 0; i < len(fields); i += 2 {
+		color := stoneListColor(fields[i])
+		if color == "" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "stone %d: invalid color %q", i/2+1, fields[i])
+		}
+		vertex := strings.ToUpper(fields[i+1])
+		x, y, err := regionCorner(vertex, boardSize, boardSize)
+		if err != nil {
+			return nil, fmt.Errorf("stone %d: %w", i/2+1, err)
+		}
+		if board.points[y*boardSize+x] != "" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "stone %d: %s is listed twice", i/2+1, vertex)
+		}
+		board.place(y*boardSize+x, color)
+		position.InitialStones = append(position.InitialStones, Stone{Color: color, Location: vertex})
+	}
+	return position, nil
+}
+
+// stoneListColor returns "b" or "w" for a GTP color, or "".
+func stoneListColor(s string) string {
+	switch strings.ToLower(s) {
+	case "b", "black":
+		return "b"
+	case "w", "white":
+		return "w"
+	}
+	return ""
+}
+
+// isVertex reports whether s looks like a GTP vertex such as "D4".
+func isVertex(s string) bool {
+	if len(s) < 2 || !unicode.IsLetter(rune(s[0])) {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// diagram is a text diagram as read, before it is placed on the board.
+type diagram struct {
+	rows        [][]string // Stone colors, row by row from the top
+	firstColumn int        // Column of the first point, from a header; -1 if unknown
+	firstRow    int        // Row number of the first row, from a label; 0 if unknown
+	top, bottom bool       // Whether the top and bottom edges are drawn
+	left, right bool       // Whether the left and right edges are drawn
+	toPlay      string     // "b" or "w" from a Sensei's Library title
+}
+
+// parseDiagram reads a text diagram and places it on the board.
+func parseDiagram(text string, boardSize int) (*Position, error) {
+	d := &diagram{firstColumn: -1}
+	for n, line := range strings.Split(text, "\n") {
+		if err := d.readLine(strings.TrimRight(line, "\r")); err != nil {
+			return nil, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "line %d", n+1)
+		}
+	}
+	if len(d.rows) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "board has no rows")
+	}
+	width := len(d.rows[0])
+	for i, row := range d.rows {
+		if len(row) != width {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument,
+				"board row %d has %d points; the first row has %d", i+1, len(row), width)
+		}
+	}
+	height := len(d.rows)
+
+	// The diagram is the whole board when it shows all its edges or none,
+	// and any labels start at the top left corner
+	edges := d.top || d.bottom || d.left || d.right
+	whole := !edges || (d.top && d.bottom && d.left && d.right)
+	if d.firstColumn > 0 || (d.firstRow > 0 && d.firstRow != height) {
+		whole = false
+	}
+	xSize, ySize := width, height
+	switch {
+	case boardSize > 0:
+		xSize, ySize = boardSize, boardSize
+	case !whole:
+		xSize, ySize = 19, 19
+	}
+	if width > xSize || height > ySize {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument,
+			"board is %dx%d, larger than a %dx%d board", width, height, xSize, ySize)
+	}
+
+	// Labels place the diagram; otherwise its edges do, and a diagram
+	// without a bottom or right edge sits at the top left.
+	xOffset, yOffset := 0, 0
+	switch {
+	case d.firstColumn >= 0:
+		xOffset = d.firstColumn
+	case d.right && !d.left:
+		xOffset = xSize - width
+	}
+	switch {
+	case d.firstRow > 0:
+		yOffset = ySize - d.firstRow
+	case d.bottom && !d.top:
+		yOffset = ySize - height
+	}
+	if xOffset < 0 || yOffset < 0 || xOffset+width > xSize || yOffset+height > ySize {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "board labels fall outside a %dx%d board", xSize, ySize)
+	}
+
+	position := newBoardPosition(xSize, ySize)
+	if d.toPlay != "" {
+		position.InitialPlayer = d.toPlay
+	}
+	for y, row := range d.rows {
+		for x, color := range row {
+			if color == "" {
+				continue
+			}
+			location := fmt.Sprintf("%c%d", ColumnLetter(x+xOffset), ySize-(y+yOffset))
+			position.InitialStones = append(position.InitialStones, Stone{Color: color, Location: location})
+		}
+	}
+	return position, nil
+}
+
+// readLine reads one line of a diagram: a title, an edge, a header of
+// column letters or a row of points.
+func (d *diagram) readLine(line string) error {
+	if rest, ok := strings.CutPrefix(line, "$$"); ok {
+		// A title follows "$$" directly: "$$B Black to play"
+		if len(d.rows) == 0 && rest != "" && rest[0] != ' ' {
+			switch rest[0] {
+			case 'B':
+				d.toPlay = "b"
+			case 'W':
+				d.toPlay = "w"
+			}
+			return nil
+		}
+		line = rest
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+	if strings.Trim(line, "-+|= ") == "" && strings.Contains(line, "-") {
+		if len(d.rows) == 0 {
+			d.top = true
+		} else {
+			d.bottom = true
+		}
+		return nil
+	}
+	if isColumnHeader(line) {
+		d.firstColumn = columnIndex(strings.Fields(line)[0][0])
+		return nil
+	}
+	return d.readRow(line)
+}
+
+// readRow reads a row of points, with optional row numbers and edges.
+func (d *diagram) readRow(line string) error {
+	label, rest := leadingNumber(line)
+	if label > 0 && len(d.rows) == 0 {
+		d.firstRow = label
+	}
+	var row []string
+points:
+	for _, r := range rest {
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case r == '|':
+			if len(row) == 0 {
+				d.left = true
+				continue
+			}
+			d.right = true
+			break points
+		case unicode.IsDigit(r):
+			// A trailing row number ends the row; GTP engines may print
+			// more after it
+			break points
+		default:
+			color, ok := diagramSymbols[r]
+			if !ok {
+				return fmt.Errorf("unknown board symbol %q", r)
+			}
+			row = append(row, color)
+		}
+	}
+	if len(row) == 0 {
+		return fmt.Errorf("row has no points")
+	}
+	d.rows = append(d.rows, row)
+	return nil
+}
+
+// isColumnHeader reports whether a line is a header of column letters.
+// Unlike a row of stones, it has a letter that is not a stone symbol.
+func isColumnHeader(line string) bool {
+	fields := strings.Fields(line)
+	label := false
+	for _, field := range fields {
+		if len(field) != 1 || field[0] < 'A' || field[0] > 'Z' || field[0] == 'I' {
+			return false
+		}
+		if _, ok := diagramSymbols[rune(field[0])]; !ok {
+			label = true
+		}
+	}
+	return len(fields) >= 2 && label
+}
+
+// columnIndex returns the 0-based column of a GTP column letter.
+func columnIndex(col byte) int {
+	x := int(col - 'A')
+	if col > 'I' {
+		x--
+	}
+	return x
+}
+
+// leadingNumber splits a leading row number from a line.
+func leadingNumber(line string) (int, string) {
+	end := 0
+	for end < len(line) && line[end] >= '0' && line[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return 0, line
+	}
+	n, _ := strconv.Atoi(line[:end])
+	return n, line[end:]
+}
+
+// newBoardPosition returns an empty position for a parsed board.
+func newBoardPosition(xSize, ySize int) *Position {
+	return &Position{
+		Rules:      "chinese",
+		BoardXSize: xSize,
+		BoardYSize: ySize,
+		Moves:      []Move{},
+		Komi:       diagramKomi,
+	}
+}
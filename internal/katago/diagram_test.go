@@ -0,0 +1,157 @@
+package katago
+
+import (
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+func TestParseBoard(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		boardSize int
+		size      int
+		toPlay    string
+		stones    []Stone
+	}{
+		{
+			name: "plain diagram",
+			text: ". . X . .\n. O X . .\n. . . . .\n. . . . .\n. . . . .",
+			size: 5,
+			stones: []Stone{
+				{Color: "b", Location: "C5"}, {Color: "w", Location: "B4"}, {Color: "b", Location: "C4"},
+			},
+		},
+		{
+			name: "compact rows with star points",
+			text: "X+.\n.O.\n..+\n",
+			size: 3,
+			stones: []Stone{
+				{Color: "b", Location: "A3"}, {Color: "w", Location: "B2"},
+			},
+		},
+		{
+			name: "GTP showboard",
+			text: "   A B C D E F G H J\n" +
+				" 9 . . . . . . . . . 9\n" +
+				" 8 . . . . . . . . . 8\n" +
+				" 7 . . + . . . + . . 7     WHITE (O) has captured 0 stones\n" +
+				" 6 . . . . . . . . . 6\n" +
+				" 5 . . . . X . . . . 5\n" +
+				" 4 . . . . . . . . . 4\n" +
+				" 3 . . O . . . + . . 3\n" +
+				" 2 . . . . . . . . . 2\n" +
+				" 1 . . . . . . . . . 1\n" +
+				"   A B C D E F G H J\n",
+			size: 9,
+			stones: []Stone{
+				{Color: "b", Location: "E5"}, {Color: "w", Location: "C3"},
+			},
+		},
+		{
+			name: "labelled corner",
+			text: "   O P Q R S T\n" +
+				"19 . . . . . .\n" +
+				"18 . . . . . .\n" +
+				"17 . . . X . .\n" +
+				"16 . . O . . .\n",
+			size: 19,
+			stones: []Stone{
+				{Color: "b", Location: "R17"}, {Color: "w", Location: "Q16"},
+			},
+		},
+		{
+			name: "Sensei's Library corner",
+			text: "$$W White to live\n" +
+				"$$ ------------\n" +
+				"$$ | . . . . . .\n" +
+				"$$ | X X X X . .\n" +
+				"$$ | O O O X . .\n" +
+				"$$ | . . O X . .\n",
+			size:   19,
+			toPlay: "w",
+			stones: []Stone{
+				{Color: "b", Location: "A18"}, {Color: "b", Location: "B18"}, {Color: "b", Location: "C18"}, {Color: "b", Location: "D18"},
+				{Color: "w", Location: "A17"}, {Color: "w", Location: "B17"}, {Color: "w", Location: "C17"}, {Color: "b", Location: "D17"},
+				{Color: "w", Location: "C16"}, {Color: "b", Location: "D16"},
+			},
+		},
+		{
+			name: "bottom right corner",
+			text: ". . X |\n. O . |\n------+\n",
+			size: 9, boardSize: 9,
+			stones: []Stone{
+				{Color: "b", Location: "J2"}, {Color: "w", Location: "H1"},
+			},
+		},
+		{
+			name: "stone list",
+			text: "B D4 W q16 black C3",
+			size: 19,
+			stones: []Stone{
+				{Color: "b", Location: "D4"}, {Color: "w", Location: "Q16"}, {Color: "b", Location: "C3"},
+			},
+		},
+		{
+			name: "set_position",
+			text: "set_position b E5 w C3",
+			size: 9, boardSize: 9,
+			stones: []Stone{
+				{Color: "b", Location: "E5"}, {Color: "w", Location: "C3"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, err := ParseBoard(tt.text, tt.boardSize)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if position.BoardXSize != tt.size || position.BoardYSize != tt.size {
+				t.Errorf("Expected a %dx%d board, got %dx%d", tt.size, tt.size, position.BoardXSize, position.BoardYSize)
+			}
+			if position.InitialPlayer != tt.toPlay {
+				t.Errorf("Expected %q to play, got %q", tt.toPlay, position.InitialPlayer)
+			}
+			if position.Komi != 7.5 || len(position.Moves) != 0 {
+				t.Errorf("Expected komi 7.5 and no moves, got %v and %v", position.Komi, position.Moves)
+			}
+			if len(position.InitialStones) != len(tt.stones) {
+				t.Fatalf("Expected stones %v, got %v", tt.stones, position.InitialStones)
+			}
+			for i, stone := range tt.stones {
+				if position.InitialStones[i] != stone {
+					t.Errorf("Stone %d: expected %v, got %v", i+1, stone, position.InitialStones[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseBoardErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		boardSize int
+		code      apperrors.Code
+	}{
+		{"empty", "  \n", 0, apperrors.CodeInvalidArgument},
+		{"unknown symbol", ". X ?\n. . .\n. . .", 0, apperrors.CodeInvalidArgument},
+		{"ragged rows", ". X .\n. .\n. . .", 0, apperrors.CodeInvalidArgument},
+		{"larger than the board", ". . . .\n. . . .\n. . . .\n. . . .", 3, apperrors.CodeInvalidArgument},
+		{"labels off the board", "22 . . X", 0, apperrors.CodeInvalidArgument},
+		{"odd stone list", "B D4 W", 0, apperrors.CodeInvalidArgument},
+		{"bad color", "set_position B D4 R Q16", 0, apperrors.CodeInvalidArgument},
+		{"duplicate stone", "B D4 W D4", 0, apperrors.CodeInvalidArgument},
+		{"vertex off the board", "B K10", 9, apperrors.CodeBadCoordinate},
+		{"bad board size", ". X", 30, apperrors.CodeInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseBoard(tt.text, tt.boardSize); apperrors.CodeOf(err) != tt.code {
+				t.Errorf("Expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package katago
+
+import "math"
+
+const (
+	// plausibleMoves is the number of equally likely moves at which the
+	// policy counts as fully uncertain.
+	plausibleMoves = 10
+	// spreadCandidates is the number of KataGo's top choices whose win
+	// rates are compared: the best move against the last of them.
+	spreadCandidates = 3
+	// hardSpread is the win rate between the best move and the others at
+	// which choosing wrong counts as fully costly.
+	hardSpread = 0.2
+	// hardDifficulty is the difficulty from which a position is hard.
+	hardDifficulty = 0.5
+)
+
+// MoveDifficulty is how hard the position before a move was to play well.
+type MoveDifficulty struct {
+	MoveNumber int    `json:"moveNumber"`
+	Color      string `json:"color"`
+	// Difficulty, from 0 to 1, combines the two measures below: a position
+	// is hard when the policy sees many plausible moves and only the best
+	// of them keeps the win rate
+	Difficulty float64 `json:"difficulty"`
+	// PolicyEntropy is the policy's uncertainty, from 0 for a single
+	// obvious move to 1 for plausibleMoves equally likely ones or more
+	PolicyEntropy float64 `json:"policyEntropy"`
+	// WinrateSpread is the win rate the best move keeps over KataGo's third
+	// choice, or its last if it searched fewer
+	WinrateSpread float64 `json:"winrateSpread"`
+}
+
+// Hard reports whether the position was hard to play well.
+func (d MoveDifficulty) Hard() bool {
+	return d.Difficulty >= hardDifficulty
+}
+
+// Hard reports whether the mistake was made in a hard position.
+func (m Mistake) Hard() bool {
+	return m.Difficulty >= hardDifficulty
+}
+
+// moveDifficulty measures how hard a position was from its analysis, which
+// must have at least one candidate move. The policy's entropy comes from
+// the full policy when the analysis has one, or else from the priors of
+// the candidates.
+func moveDifficulty(result *AnalysisResult, moveNumber int, color string) MoveDifficulty {
+	priors := result.Policy
+	if len(priors) == 0 {
+		for _, mi := range result.MoveInfos {
+			priors = append(priors, mi.Prior)
+		}
+	}
+	total, entropy := 0.0, 0.0
+	for _, p := range priors {
+		if p > 0 {
+			total += p
+		}
+	}
+	for _, p := range priors {
+		if p > 0 && total > 0 {
+			entropy -= p / total * math.Log(p/total)
+		}
+	}
+	entropyScore := math.Min(entropy/math.Log(plausibleMoves), 1)
+
+	last := result.MoveInfos[min(spreadCandidates, len(result.MoveInfos))-1]
+	// The best move is the mover's, whichever side win rates are reported
+	// for
+	spread := math.Abs(result.MoveInfos[0].Winrate - last.Winrate)
+	spreadScore := math.Min(spread/hardSpread, 1)
+
+	// A geometric mean, so that a position is easy when either measure is
+	// low: an obvious move, however costly the others, or a choice of
+	// equally good ones
+	return MoveDifficulty{
+		MoveNumber:    moveNumber,
+		Color:         color,
+		Difficulty:    roundHundredth(math.Sqrt(entropyScore * spreadScore)),
+		PolicyEntropy: roundHundredth(entropyScore),
+		WinrateSpread: roundHundredth(spread),
+	}
+}
+
+// markDifficulty sets a mistake's difficulty and counts it as made in a
+// hard or an easy position.
+func markDifficulty(summary *ReviewSummary, mistake *Mistake, difficulty MoveDifficulty) {
+	mistake.Difficulty = difficulty.Difficulty
+	switch {
+	case mistake.Color == "B" && difficulty.Hard():
+		summary.BlackHardMistakes++
+	case mistake.Color == "B":
+		summary.BlackEasyMistakes++
+	case difficulty.Hard():
+		summary.WhiteHardMistakes++
+	default:
+		summary.WhiteEasyMistakes++
+	}
+}
@@ -0,0 +1,79 @@
+package katago
+
+import "testing"
+
+func TestMoveDifficulty(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *AnalysisResult
+		hard   bool
+	}{
+		{"obvious answer", &AnalysisResult{
+			Policy: []float64{0.95, 0.03, 0.02},
+			MoveInfos: []MoveInfo{
+				{Move: "D4", Winrate: 0.6},
+				{Move: "C3", Winrate: 0.2},
+				{Move: "E5", Winrate: 0.1},
+			},
+		}, false},
+		{"many equal moves", &AnalysisResult{
+			Policy: []float64{0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, -1},
+			MoveInfos: []MoveInfo{
+				{Move: "D4", Winrate: 0.51},
+				{Move: "Q16", Winrate: 0.5},
+				{Move: "C16", Winrate: 0.5},
+			},
+		}, false},
+		{"one good move among many plausible", &AnalysisResult{
+			Policy: []float64{0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1, 0.1},
+			MoveInfos: []MoveInfo{
+				{Move: "D4", Winrate: 0.6},
+				{Move: "Q16", Winrate: 0.4},
+				{Move: "C16", Winrate: 0.3},
+			},
+		}, true},
+		{"priors without a policy", &AnalysisResult{
+			MoveInfos: []MoveInfo{
+				{Move: "D4", Winrate: 0.6, Prior: 0.35},
+				{Move: "Q16", Winrate: 0.3, Prior: 0.35},
+				{Move: "C16", Winrate: 0.3, Prior: 0.3},
+			},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := moveDifficulty(tt.result, 7, "B")
+			if d.MoveNumber != 7 || d.Color != "B" {
+				t.Errorf("Expected move 7 by B, got %+v", d)
+			}
+			if d.Difficulty < 0 || d.Difficulty > 1 || d.Hard() != tt.hard {
+				t.Errorf("Expected hard %v, got %+v", tt.hard, d)
+			}
+		})
+	}
+
+	// Ten equally likely moves are fully uncertain
+	d := moveDifficulty(tests[2].result, 1, "W")
+	if d.PolicyEntropy != 1 || d.WinrateSpread != 0.3 || d.Difficulty != 1 {
+		t.Errorf("Expected entropy 1, spread 0.3 and difficulty 1, got %+v", d)
+	}
+}
+
+func TestMarkDifficulty(t *testing.T) {
+	summary := &ReviewSummary{}
+	hard := MoveDifficulty{Difficulty: 0.8}
+	easy := MoveDifficulty{Difficulty: 0.2}
+
+	black := &Mistake{Color: "B"}
+	markDifficulty(summary, black, hard)
+	markDifficulty(summary, &Mistake{Color: "W"}, easy)
+	markDifficulty(summary, &Mistake{Color: "W"}, easy)
+	if black.Difficulty != 0.8 || !black.Hard() {
+		t.Errorf("Expected a hard mistake, got %+v", black)
+	}
+	if summary.BlackHardMistakes != 1 || summary.BlackEasyMistakes != 0 ||
+		summary.WhiteHardMistakes != 0 || summary.WhiteEasyMistakes != 2 {
+		t.Errorf("Unexpected counts: %+v", summary)
+	}
+}
@@ -0,0 +1,166 @@
+package katago
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Reasons a response reached no waiting query.
+const (
+	OrphanLate      = "late"      // The query had expired or been abandoned
+	OrphanDuplicate = "duplicate" // The query had already been answered
+	OrphanUnknown   = "unknown"   // No query with the ID was sent
+)
+
+// finishedQueryMemory is the number of finished query IDs remembered to
+// tell late and duplicate responses from unknown ones.
+const finishedQueryMemory = 1024
+
+// How a remembered query finished.
+const (
+	queryAnswered = iota + 1
+	queryExpired
+	queryIgnored // A fire-and-forget query whose response is discarded
+)
+
+// dispatcher matches the responses KataGo prints to the queries awaiting
+// them. Each query waits under its own context, and expires when its caller
+// gives up or its timeout passes, so a response that never arrives leaks
+// nothing. Responses that reach no waiting query are counted as orphans.
+type dispatcher struct {
+	mu       sync.Mutex
+	pending  map[string]*pendingQuery
+	finished map[string]int // Recently finished query IDs to how they finished
+	order    []string       // Finished query IDs, oldest first
+	orphans  map[string]int // Orphan responses by reason
+	onOrphan func(id, reason string)
+}
+
+// pendingQuery is a query awaiting its response.
+type pendingQuery struct {
+	ch   chan *Response
+	stop func() bool // Stops the query's expiry
+}
+
+// newDispatcher creates a dispatcher that calls onOrphan, if not nil, for
+// each response that reaches no waiting query.
+func newDispatcher(onOrphan func(id, reason string)) *dispatcher {
+	return &dispatcher{
+		pending:  make(map[string]*pendingQuery),
+		finished: make(map[string]int),
+		orphans:  make(map[string]int),
+		onOrphan: onOrphan,
+	}
+}
+
+// register adds a query awaiting a response. The returned context ends when
+// ctx does or the timeout passes, expiring the query; the response, if it
+// comes first, arrives on the channel. The caller must call release once it
+// stops waiting.
+func (d *dispatcher) register(ctx context.Context, id string, timeout time.Duration) (context.Context, <-chan *Response, func()) {
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	query := &pendingQuery{ch: make(chan *Response, 1)}
+	query.stop = context.AfterFunc(queryCtx, func() { d.expire(id, query) })
+	d.mu.Lock()
+	d.pending[id] = query
+	d.mu.Unlock()
+
+	release := func() {
+		cancel()
+		d.expire(id, query)
+	}
+	return queryCtx, query.ch, release
+}
+
+// expire removes a query that is still waiting.
+func (d *dispatcher) expire(id string, query *pendingQuery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending[id] == query {
+		delete(d.pending, id)
+		d.remember(id, queryExpired)
+	}
+}
+
+// ignore discards the response to a fire-and-forget query rather than
+// counting it as an orphan.
+func (d *dispatcher) ignore(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.remember(id, queryIgnored)
+}
+
+// deliver sends a response to the query waiting for it, reporting false if
+// none was.
+func (d *dispatcher) deliver(resp *Response) bool {
+	d.mu.Lock()
+	if query, ok := d.pending[resp.ID]; ok {
+		delete(d.pending, resp.ID)
+		d.remember(resp.ID, queryAnswered)
+		d.mu.Unlock()
+		query.stop()
+		query.ch <- resp
+		return true
+	}
+
+	reason := OrphanUnknown
+	switch d.finished[resp.ID] {
+	case queryIgnored:
+		d.mu.Unlock()
+		return false
+	case queryAnswered:
+		reason = OrphanDuplicate
+	case queryExpired:
+		reason = OrphanLate
+	}
+	d.orphans[reason]++
+	d.mu.Unlock()
+	if d.onOrphan != nil {
+		d.onOrphan(resp.ID, reason)
+	}
+	return false
+}
+
+// closeAll answers every waiting query with an error response.
+func (d *dispatcher) closeAll(message string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for id, query := range d.pending {
+		query.stop()
+		query.ch <- &Response{ID: id, Error: message}
+		delete(d.pending, id)
+		d.remember(id, queryExpired)
+	}
+}
+
+// waiting returns the number of queries awaiting a response.
+func (d *dispatcher) waiting() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.pending)
+}
+
+// orphanCounts returns the number of orphan responses by reason.
+func (d *dispatcher) orphanCounts() map[string]int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	counts := make(map[string]int, len(d.orphans))
+	for reason, n := range d.orphans {
+		counts[reason] = n
+	}
+	return counts
+}
+
+// remember records how a query finished, forgetting the oldest once
+// finishedQueryMemory queries are remembered. The caller must hold d.mu.
+func (d *dispatcher) remember(id string, how int) {
+	if _, ok := d.finished[id]; !ok {
+		d.order = append(d.order, id)
+	}
+	d.finished[id] = how
+	if len(d.order) > finishedQueryMemory {
+		delete(d.finished, d.order[0])
+		d.order = d.order[1:]
+	}
+}
@@ -0,0 +1,113 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDispatcher(t *testing.T) {
+	var orphans []string
+	d := newDispatcher(func(id, reason string) { orphans = append(orphans, id+" "+reason) })
+
+	// Out-of-order responses reach their own queries
+	_, first, releaseFirst := d.register(context.Background(), "q1", time.Minute)
+	defer releaseFirst()
+	_, second, releaseSecond := d.register(context.Background(), "q2", time.Minute)
+	defer releaseSecond()
+	if d.waiting() != 2 {
+		t.Fatalf("Expected 2 waiting queries, got %d", d.waiting())
+	}
+	if !d.deliver(&Response{ID: "q2", TurnNumber: 2}) || !d.deliver(&Response{ID: "q1", TurnNumber: 1}) {
+		t.Fatal("Expected both responses delivered")
+	}
+	if resp := <-first; resp.TurnNumber != 1 {
+		t.Errorf("Expected q1's response, got %+v", resp)
+	}
+	if resp := <-second; resp.TurnNumber != 2 {
+		t.Errorf("Expected q2's response, got %+v", resp)
+	}
+
+	// A duplicated response is an orphan
+	if d.deliver(&Response{ID: "q1"}) {
+		t.Error("Expected a duplicate response not delivered")
+	}
+
+	// A lost response expires its query, and arrives late if at all
+	ctx, lost, releaseLost := d.register(context.Background(), "q3", 10*time.Millisecond)
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Errorf("Expected the query to time out, got %v", ctx.Err())
+	}
+	waitFor(t, func() bool { return d.waiting() == 0 })
+	releaseLost()
+	if d.deliver(&Response{ID: "q3"}) {
+		t.Error("Expected a late response not delivered")
+	}
+	select {
+	case resp := <-lost:
+		t.Errorf("Expected no response to an expired query, got %+v", resp)
+	default:
+	}
+
+	// A query abandoned by its caller expires too
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, _, releaseAbandoned := d.register(parent, "q4", time.Minute)
+	cancel()
+	<-ctx.Done()
+	releaseAbandoned()
+	if d.waiting() != 0 {
+		t.Errorf("Expected an abandoned query removed, got %d waiting", d.waiting())
+	}
+
+	// Fire-and-forget responses are discarded quietly
+	d.ignore("q4-terminate")
+	d.deliver(&Response{ID: "q4-terminate"})
+	d.deliver(&Response{ID: "q99"})
+
+	want := []string{"q1 duplicate", "q3 late", "q99 unknown"}
+	if !reflect.DeepEqual(orphans, want) {
+		t.Errorf("Expected orphans %v, got %v", want, orphans)
+	}
+	if counts := d.orphanCounts(); counts[OrphanDuplicate] != 1 || counts[OrphanLate] != 1 || counts[OrphanUnknown] != 1 {
+		t.Errorf("Unexpected orphan counts: %v", counts)
+	}
+
+	// Stopping answers every waiting query
+	_, stopped, releaseStopped := d.register(context.Background(), "q5", time.Minute)
+	defer releaseStopped()
+	d.closeAll("engine stopped")
+	if resp := <-stopped; resp.Error != "engine stopped" {
+		t.Errorf("Expected an engine stopped error, got %+v", resp)
+	}
+	if d.waiting() != 0 {
+		t.Errorf("Expected no waiting queries, got %d", d.waiting())
+	}
+}
+
+func TestDispatcherForgetsOldQueries(t *testing.T) {
+	d := newDispatcher(nil)
+	for i := 0; i <= finishedQueryMemory; i++ {
+		d.ignore(fmt.Sprintf("q%d", i))
+	}
+	if len(d.finished) != finishedQueryMemory || len(d.order) != finishedQueryMemory {
+		t.Errorf("Expected %d remembered queries, got %d", finishedQueryMemory, len(d.finished))
+	}
+	if _, ok := d.finished["q0"]; ok {
+		t.Error("Expected the oldest query forgotten")
+	}
+}
+
+// waitFor polls until cond holds or a second passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,106 @@
+package katago
+
+import (
+	"fmt"
+	"sort"
+)
+
+// contestedOwnershipStdev is the ownership standard deviation above which a
+// point counts as contested: the search is split on who will own it.
+const contestedOwnershipStdev = 0.5
+
+// maxUncertainPoints is the number of most uncertain points listed.
+const maxUncertainPoints = 10
+
+// scorePercentiles are the percentiles of the final score reported, with
+// their standard normal quantiles.
+var scorePercentiles = []struct {
+	percentile int
+	z          float64
+}{
+	{5, -1.645},
+	{25, -0.674},
+	{50, 0},
+	{75, 0.674},
+	{95, 1.645},
+}
+
+// ScoreDistribution describes how sure KataGo is of a position's final
+// score. KataGo's analysis engine reports the mean and standard deviation
+// of the score but no histogram, so percentiles assume the score is
+// normally distributed around the lead. Scores are Black's lead, as KataGo
+// reports them with reportAnalysisWinratesAs = BLACK.
+type ScoreDistribution struct {
+	CurrentPlayer string  `json:"currentPlayer"`
+	Visits        int     `json:"visits"`
+	ScoreLead     float64 `json:"scoreLead"`
+	ScoreStdev    float64 `json:"scoreStdev"`
+	BlackWinrate  float64 `json:"blackWinrate"`
+	// Estimate is the lead with its standard deviation, e.g. "B+3.5 ± 6.2"
+	Estimate    string            `json:"estimate"`
+	Percentiles []ScorePercentile `json:"percentiles"`
+
+	// Ownership uncertainty, when KataGo reports ownershipStdev
+	ContestedPoints int                `json:"contestedPoints"`
+	MostUncertain   []PointUncertainty `json:"mostUncertain,omitempty"`
+}
+
+// ScorePercentile is a percentile of the final score.
+type ScorePercentile struct {
+	Percentile int     `json:"percentile"`
+	ScoreLead  float64 `json:"scoreLead"`
+	Result     string  `json:"result"` // e.g. "W+4.2"
+}
+
+// PointUncertainty is how uncertain the owner of a point is.
+type PointUncertainty struct {
+	Point     string  `json:"point"`
+	Ownership float64 `json:"ownership"` // -1 (White) to 1 (Black)
+	Stdev     float64 `json:"stdev"`
+}
+
+// NewScoreDistribution describes the score distribution of an analysis
+// requested with IncludeScoreDistribution.
+func NewScoreDistribution(result *AnalysisResult, xSize, ySize int) *ScoreDistribution {
+	root := result.RootInfo
+	d := &ScoreDistribution{
+		CurrentPlayer: root.CurrentPlayer,
+		Visits:        root.Visits,
+		ScoreLead:     roundTenth(root.ScoreLead),
+		ScoreStdev:    roundTenth(root.ScoreStdev),
+		BlackWinrate:  root.Winrate,
+		Estimate:      fmt.Sprintf("%s ± %.1f", formatResult(root.ScoreLead), root.ScoreStdev),
+	}
+	for _, p := range scorePercentiles {
+		lead := root.ScoreLead + p.z*root.ScoreStdev
+		d.Percentiles = append(d.Percentiles, ScorePercentile{
+			Percentile: p.percentile,
+			ScoreLead:  roundTenth(lead),
+			Result:     formatResult(lead),
+		})
+	}
+
+	if len(result.OwnershipStdev) < xSize*ySize {
+		return d
+	}
+	var points []PointUncertainty
+	for i, stdev := range result.OwnershipStdev[:xSize*ySize] {
+		if stdev < contestedOwnershipStdev {
+			continue
+		}
+		point := PointUncertainty{Point: coordToString(i%xSize, i/xSize, ySize), Stdev: stdev}
+		if i < len(result.Ownership) {
+			point.Ownership = result.Ownership[i]
+		}
+		points = append(points, point)
+	}
+	d.ContestedPoints = len(points)
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Stdev > points[j].Stdev
+	})
+	if len(points) > maxUncertainPoints {
+		points = points[:maxUncertainPoints]
+	}
+	d.MostUncertain = points
+	return d
+}
@@ -0,0 +1,61 @@
+package katago
+
+import "testing"
+
+func TestNewScoreDistribution(t *testing.T) {
+	result := &AnalysisResult{
+		RootInfo: RootInfo{CurrentPlayer: "W", Visits: 400, Winrate: 0.62, ScoreLead: 3.5, ScoreStdev: 6.2},
+		// A 3x2 board: C2 is contested, B1 less so, the rest are settled
+		Ownership:      []float64{0.9, 0.8, 0.1, -0.9, -0.4, -0.95},
+		OwnershipStdev: []float64{0.2, 0.3, 0.9, 0.2, 0.6, 0.1},
+	}
+
+	d := NewScoreDistribution(result, 3, 2)
+	if d.Estimate != "B+3.5 ± 6.2" {
+		t.Errorf("Expected estimate B+3.5 ± 6.2, got %s", d.Estimate)
+	}
+	want := []string{"W+6.7", "W+0.7", "B+3.5", "B+7.7", "B+13.7"}
+	if len(d.Percentiles) != len(want) {
+		t.Fatalf("Expected %d percentiles, got %+v", len(want), d.Percentiles)
+	}
+	for i, p := range d.Percentiles {
+		if p.Result != want[i] {
+			t.Errorf("Expected %dth percentile %s, got %s", p.Percentile, want[i], p.Result)
+		}
+	}
+
+	if d.ContestedPoints != 2 || len(d.MostUncertain) != 2 {
+		t.Fatalf("Expected 2 contested points, got %+v", d)
+	}
+	if first := d.MostUncertain[0]; first.Point != "C2" || first.Ownership != 0.1 || first.Stdev != 0.9 {
+		t.Errorf("Expected C2 to be the most uncertain point, got %+v", first)
+	}
+	if second := d.MostUncertain[1]; second.Point != "B1" {
+		t.Errorf("Expected B1 next, got %+v", second)
+	}
+
+	// Without ownership stdev there are no contested points
+	result.OwnershipStdev = nil
+	if d := NewScoreDistribution(result, 3, 2); d.ContestedPoints != 0 || d.MostUncertain != nil {
+		t.Errorf("Expected no ownership uncertainty, got %+v", d)
+	}
+}
+
+func TestBuildAnalysisQueryScoreDistribution(t *testing.T) {
+	position := &Position{Rules: "chinese", BoardXSize: 9, BoardYSize: 9}
+	query, err := buildAnalysisQuery(&AnalysisRequest{Position: position, IncludeScoreDistribution: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query["includeOwnership"] != true || query["includeOwnershipStdev"] != true {
+		t.Errorf("Expected ownership and its stdev to be requested, got %v", query)
+	}
+
+	query, err = buildAnalysisQuery(&AnalysisRequest{Position: position})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := query["includeOwnershipStdev"]; ok {
+		t.Errorf("Expected no ownership stdev by default, got %v", query)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
 )
 
 // MoveExplanation provides detailed explanation for a move.
@@ -17,6 +20,11 @@ type MoveExplanation struct {
 	Cons         []string      `json:"cons"`
 	Alternatives []Alternative `json:"alternatives"`
 	Strategic    StrategicInfo `json:"strategic"`
+	Terms        []GoTerm      `json:"terms,omitempty"` // Shapes the move makes, e.g. hane
+	// How the move changes ownership, when KataGo reports per-move ownership
+	Ownership *OwnershipImpact `json:"ownership,omitempty"`
+	// The opponent's strongest answers to the move
+	Replies []Reply `json:"replies,omitempty"`
 }
 
 // Alternative represents an alternative move option.
@@ -37,13 +45,32 @@ type StrategicInfo struct {
 	InfluenceMove bool     `json:"influenceMove"`
 }
 
-// ExplainMove provides explanation for why a move is good or bad.
-func (e *Engine) ExplainMove(ctx context.Context, position *Position, move string) (*MoveExplanation, error) {
+// ExplainOptions controls the search behind a move explanation.
+type ExplainOptions struct {
+	MaxVisits int     // Visits for the analysis (default: the engine's maxVisits)
+	MaxTime   float64 // Seconds for the analysis (default: the engine's maxTime)
+}
+
+// ExplainMove provides explanation for why a move is good or bad, in the
+// language of the context's i18n printer. Strategic fields are left in
+// English for callers to translate.
+func (e *Engine) ExplainMove(ctx context.Context, position *Position, move string, opts *ExplainOptions) (*MoveExplanation, error) {
+	p := i18n.FromContext(ctx)
+
 	// Analyze the position
 	req := &AnalysisRequest{
-		Position:         position,
-		IncludePolicy:    true,
-		IncludeOwnership: true,
+		Position:              position,
+		IncludePolicy:         true,
+		IncludeOwnership:      true,
+		IncludeMovesOwnership: true,
+	}
+	if opts != nil && opts.MaxVisits > 0 {
+		maxVisits := opts.MaxVisits
+		req.MaxVisits = &maxVisits
+	}
+	if opts != nil && opts.MaxTime > 0 {
+		maxTime := opts.MaxTime
+		req.MaxTime = &maxTime
 	}
 
 	result, err := e.Analyze(ctx, req)
@@ -86,24 +113,48 @@ func (e *Engine) ExplainMove(ctx context.Context, position *Position, move strin
 	// Generate main explanation
 	switch {
 	case moveRank == 1:
-		explanation.Explanation = fmt.Sprintf("%s is KataGo's top choice (%.1f%% win rate, %.1f point lead)",
+		explanation.Explanation = p.Sprintf("%s is KataGo's top choice (%.1f%% win rate, %.1f point lead)",
 			move, moveInfo.Winrate*100, moveInfo.ScoreLead)
 	case winrateDiff < 0.02:
-		explanation.Explanation = fmt.Sprintf("%s is nearly as good as the best move (%.1f%% win rate, rank #%d)",
+		explanation.Explanation = p.Sprintf("%s is nearly as good as the best move (%.1f%% win rate, rank #%d)",
 			move, moveInfo.Winrate*100, moveRank)
 	case winrateDiff < 0.05:
-		explanation.Explanation = fmt.Sprintf("%s is a reasonable move but slightly inferior (%.1f%% win rate, -%1.f%% from best)",
+		explanation.Explanation = p.Sprintf("%s is a reasonable move but slightly inferior (%.1f%% win rate, -%1.f%% from best)",
 			move, moveInfo.Winrate*100, winrateDiff*100)
 	default:
-		explanation.Explanation = fmt.Sprintf("%s is questionable, losing %.1f%% win rate compared to %s",
+		explanation.Explanation = p.Sprintf("%s is questionable, losing %.1f%% win rate compared to %s",
 			move, winrateDiff*100, bestMove.Move)
 	}
 
-	// Analyze strategic aspects
+	// Analyze strategic aspects, from the change in ownership when KataGo
+	// reports it and from the move's location otherwise
 	explanation.Strategic = analyzeStrategicAspects(move, position, result)
+	board, err := BoardFromPosition(position)
+	if err == nil {
+		explanation.Ownership = analyzeOwnershipImpact(board, nextPlayer(position), move, result.Ownership, moveInfo.Ownership)
+		if explanation.Ownership != nil {
+			explanation.Strategic = strategicFromImpact(explanation.Ownership, explanation.Strategic.BoardRegion)
+		}
+
+		// Name the shape the move makes from the surrounding stones
+		explanation.Terms = DescribeTerms(p, ClassifyMove(board, nextPlayer(position), move))
+	}
 
 	// Generate pros and cons
-	explanation.Pros, explanation.Cons = generateProsAndCons(moveInfo, bestMove, position)
+	explanation.Pros, explanation.Cons = generateProsAndCons(p, moveInfo, bestMove, position, explanation.Ownership)
+
+	// Look ahead to the opponent's best replies; the explanation stands
+	// without them if that analysis fails
+	if !strings.EqualFold(move, "pass") {
+		replies, err := e.analyzeReplies(ctx, p, position, nextPlayer(position), move)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			e.logger.Warn("Failed to analyze replies", "move", move, "error", err)
+		}
+		explanation.Replies = replies
+	}
 
 	// Add alternatives
 	for i, altMove := range topMoves {
@@ -119,9 +170,9 @@ func (e *Engine) ExplainMove(ctx context.Context, position *Position, move strin
 
 		// Generate reasoning for alternative
 		if i == 0 {
-			alt.Reasoning = "KataGo's top choice"
+			alt.Reasoning = p.T("KataGo's top choice")
 		} else {
-			alt.Reasoning = compareMove(&altMove, moveInfo, position)
+			alt.Reasoning = compareMove(p, &altMove, moveInfo, position)
 		}
 
 		explanation.Alternatives = append(explanation.Alternatives, alt)
@@ -212,8 +263,11 @@ func isNearStones(_, _ int, position *Position) bool {
 	return len(position.Moves) > 4 && len(position.Moves) < 50
 }
 
-// generateProsAndCons creates lists of advantages and disadvantages.
-func generateProsAndCons(moveInfo, bestMove *MoveInfo, position *Position) (pros, cons []string) {
+// generateProsAndCons creates lists of advantages and disadvantages. The
+// move's ownership impact describes what it achieves when known; otherwise
+// its board region does.
+func generateProsAndCons(p *i18n.Printer, moveInfo, bestMove *MoveInfo, position *Position,
+	impact *OwnershipImpact) (pros, cons []string) {
 	pros = []string{}
 	cons = []string{}
 
@@ -222,66 +276,74 @@ func generateProsAndCons(moveInfo, bestMove *MoveInfo, position *Position) (pros
 
 	// Pros
 	if moveInfo.Visits > 100 {
-		pros = append(pros, "Well-explored by the engine")
+		pros = append(pros, p.T("Well-explored by the engine"))
 	}
 
 	if moveInfo.Prior > 0.1 {
-		pros = append(pros, "Natural-looking move")
+		pros = append(pros, p.T("Natural-looking move"))
 	}
 
 	if winrateDiff < 0.02 {
-		pros = append(pros, "Nearly optimal")
+		pros = append(pros, p.T("Nearly optimal"))
 	}
 
 	if moveInfo.ScoreLead > 0 {
-		pros = append(pros, fmt.Sprintf("Maintains %.1f point lead", moveInfo.ScoreLead))
+		pros = append(pros, p.Sprintf("Maintains %.1f point lead", moveInfo.ScoreLead))
 	}
 
-	// Move-specific pros based on board position
-	x, y := parseCoord(moveInfo.Move, position.BoardXSize)
-	region := getBoardRegion(x, y, position.BoardXSize)
-	if region == "corner" {
-		pros = append(pros, "Secures corner territory")
-	} else if region == "side" {
-		pros = append(pros, "Develops along the side")
+	// Move-specific pros based on ownership or board position
+	var impactCons []string
+	if impact != nil {
+		var impactPros []string
+		impactPros, impactCons = ownershipProsAndCons(p, impact)
+		pros = append(pros, impactPros...)
+	} else {
+		x, y := parseCoord(moveInfo.Move, position.BoardXSize)
+		region := getBoardRegion(x, y, position.BoardXSize)
+		if region == "corner" {
+			pros = append(pros, p.T("Secures corner territory"))
+		} else if region == "side" {
+			pros = append(pros, p.T("Develops along the side"))
+		}
 	}
 
 	// Cons
 	if winrateDiff > 0.05 {
-		cons = append(cons, fmt.Sprintf("Loses %.1f%% win rate", winrateDiff*100))
+		cons = append(cons, p.Sprintf("Loses %.1f%% win rate", winrateDiff*100))
 	} else if winrateDiff > 0.01 {
-		cons = append(cons, fmt.Sprintf("Loses %.1f%% win rate", winrateDiff*100))
+		cons = append(cons, p.Sprintf("Loses %.1f%% win rate", winrateDiff*100))
 	}
 
 	if moveInfo.Prior < 0.01 {
-		cons = append(cons, "Unconventional choice")
+		cons = append(cons, p.T("Unconventional choice"))
 	}
 
 	if moveInfo.Visits < 50 {
-		cons = append(cons, "Limited engine exploration")
+		cons = append(cons, p.T("Limited engine exploration"))
 	}
 
 	if winrateDiff > 0.02 && bestMove.Move != "" {
-		cons = append(cons, fmt.Sprintf("%s is better", bestMove.Move))
+		cons = append(cons, p.Sprintf("%s is better", bestMove.Move))
 	}
+	cons = append(cons, impactCons...)
 
 	// Ensure we have at least one item in each list
 	if len(pros) == 0 {
-		pros = append(pros, "Playable move")
+		pros = append(pros, p.T("Playable move"))
 	}
 	if len(cons) == 0 && winrateDiff > 0 {
-		cons = append(cons, "Slightly suboptimal")
+		cons = append(cons, p.T("Slightly suboptimal"))
 	}
 
 	return pros, cons
 }
 
 // compareMove generates a comparison between two moves.
-func compareMove(move1, move2 *MoveInfo, position *Position) string {
+func compareMove(p *i18n.Printer, move1, move2 *MoveInfo, position *Position) string {
 	winrateDiff := move1.Winrate - move2.Winrate
 
 	if math.Abs(winrateDiff) < 0.01 {
-		return "Similar strength"
+		return p.T("Similar strength")
 	}
 
 	x1, y1 := parseCoord(move1.Move, position.BoardXSize)
@@ -292,16 +354,16 @@ func compareMove(move1, move2 *MoveInfo, position *Position) string {
 
 	if region1 != region2 {
 		if winrateDiff > 0 {
-			return fmt.Sprintf("Prefers %s over %s", region1, region2)
+			return p.Sprintf("Prefers %s over %s", p.T(region1), p.T(region2))
 		}
-		return fmt.Sprintf("Alternative in %s", region1)
+		return p.Sprintf("Alternative in %s", p.T(region1))
 	}
 
 	if winrateDiff > 0.02 {
-		return fmt.Sprintf("%.1f%% better", winrateDiff*100)
+		return p.Sprintf("%.1f%% better", winrateDiff*100)
 	}
 
-	return "Slightly different approach"
+	return p.T("Slightly different approach")
 }
 
 // contains checks if a slice contains a string.
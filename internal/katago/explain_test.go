@@ -3,6 +3,8 @@ package katago
 import (
 	"strings"
 	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
 )
 
 func TestGetBoardRegion(t *testing.T) {
@@ -187,7 +189,7 @@ func TestGenerateProsAndCons(t *testing.T) {
 		BoardYSize: 19,
 	}
 
-	pros, cons := generateProsAndCons(moveInfo, bestMove, position)
+	pros, cons := generateProsAndCons(i18n.English, moveInfo, bestMove, position, nil)
 
 	// Should have at least one pro and con
 	if len(pros) == 0 {
@@ -244,7 +246,7 @@ func TestCompareMove(t *testing.T) {
 		BoardYSize: 19,
 	}
 
-	result := compareMove(move1, move2, position)
+	result := compareMove(i18n.English, move1, move2, position)
 
 	// Should indicate move1 is better
 	if !strings.Contains(result, "better") && !strings.Contains(result, "Prefers") {
@@ -253,10 +255,18 @@ func TestCompareMove(t *testing.T) {
 
 	// Test similar moves
 	move2.Winrate = 0.515
-	result = compareMove(move1, move2, position)
+	result = compareMove(i18n.English, move1, move2, position)
 	if result != "Similar strength" {
 		t.Errorf("Expected 'Similar strength' for close winrates, got: %s", result)
 	}
+
+	japanese, err := i18n.NewPrinter("ja")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result = compareMove(japanese, move1, move2, position); result != "ほぼ同等" {
+		t.Errorf("Expected a Japanese comparison, got: %s", result)
+	}
 }
 
 func TestMoveExplanationStruct(t *testing.T) {
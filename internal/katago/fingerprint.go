@@ -0,0 +1,113 @@
+package katago
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+)
+
+// fingerprintLength is the number of hex digits of a cache fingerprint.
+const fingerprintLength = 16
+
+// fileIdentity identifies a file by path, size and modification time, or by
+// path alone when it cannot be read.
+type fileIdentity struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size,omitempty"`
+	ModTime time.Time `json:"modTime,omitempty"`
+	SHA256  string    `json:"sha256,omitempty"`
+}
+
+// cacheFingerprint identifies what an engine's results depend on besides
+// the query: the networks it runs, by content hash, the KataGo binary and
+// version, KataGo's own config file and the server's settings that change
+// how positions are searched. Engines with different fingerprints never
+// share cached analyses. The version is "" until it has been negotiated.
+func cacheFingerprint(cfg *config.KataGoConfig, version string) string {
+	identify := func(path string, hash bool) *fileIdentity {
+		if path == "" {
+			return nil
+		}
+		id := &fileIdentity{Path: path}
+		if hash {
+			if model, err := describeModel(path); err == nil {
+				id.SHA256 = model.SHA256
+				return id
+			}
+		}
+		if info, err := os.Stat(path); err == nil {
+			id.Size, id.ModTime = info.Size(), info.ModTime().UTC()
+		}
+		return id
+	}
+	var configFile string
+	if cfg.ConfigPath != "" {
+		if data, err := os.ReadFile(cfg.ConfigPath); err == nil { // #nosec G304 -- path is operator configuration
+			sum := sha256.Sum256(data)
+			configFile = hex.EncodeToString(sum[:])
+		} else {
+			configFile = cfg.ConfigPath
+		}
+	}
+
+	data, _ := json.Marshal(struct {
+		Model        *fileIdentity       `json:"model"`
+		HumanModel   *fileIdentity       `json:"humanModel"`
+		Binary       *fileIdentity       `json:"binary"`
+		Version      string              `json:"version"`
+		ConfigFile   string              `json:"configFile"`
+		HumanProfile string              `json:"humanProfile"`
+		MaxTime      float64             `json:"maxTime"`
+		NumThreads   int                 `json:"numThreads"`
+		Search       config.SearchConfig `json:"search"`
+	}{
+		Model:        identify(cfg.ModelPath, true),
+		HumanModel:   identify(cfg.HumanModelPath, true),
+		Binary:       identify(cfg.BinaryPath, false),
+		Version:      version,
+		ConfigFile:   configFile,
+		HumanProfile: cfg.HumanProfile,
+		MaxTime:      cfg.MaxTime,
+		NumThreads:   cfg.NumThreads,
+		Search:       cfg.Search,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:fingerprintLength]
+}
+
+// setFingerprint sets the fingerprint the engine's cache keys start with,
+// and drops the cached analyses of its previous fingerprint, which can no
+// longer be served. The caller must hold e.mu.
+func (e *Engine) setFingerprint(fingerprint string) {
+	previous := e.fingerprint
+	e.fingerprint = fingerprint
+	if previous == "" || previous == fingerprint || e.cache == nil {
+		return
+	}
+	removed := e.cache.Invalidate(func(key string) bool {
+		return strings.HasPrefix(key, previous+":") || strings.Contains(key, ":"+previous+":")
+	})
+	e.logger.Info("Engine model or configuration changed, invalidated cached analyses",
+		"previous", previous, "fingerprint", fingerprint, "entries", removed)
+}
+
+// cacheKey scopes a position key to the engine's fingerprint and cache
+// scope and the request's cache namespace.
+func (e *Engine) cacheKey(ctx context.Context, key string) string {
+	e.mu.Lock()
+	fingerprint := e.fingerprint
+	e.mu.Unlock()
+	if fingerprint != "" {
+		key = fingerprint + ":" + key
+	}
+	if e.cacheScope != "" {
+		key = e.cacheScope + ":" + key
+	}
+	return namespacedKey(ctx, key)
+}
@@ -0,0 +1,71 @@
+package katago
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/cache"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+func TestCacheFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	modelPath := filepath.Join(dir, "kata1-b18c384nbt-s123.bin.gz")
+	if err := os.WriteFile(modelPath, []byte("weights"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.KataGoConfig{BinaryPath: "katago", ModelPath: modelPath, MaxTime: 10}
+
+	base := cacheFingerprint(cfg, "1.15.3")
+	if len(base) != fingerprintLength || cacheFingerprint(cfg, "1.15.3") != base {
+		t.Fatalf("Expected a stable fingerprint, got %q", base)
+	}
+	if cacheFingerprint(cfg, "1.16.0") == base {
+		t.Error("Expected another KataGo version to change the fingerprint")
+	}
+	noise := 0.04
+	searched := *cfg
+	searched.Search = config.SearchConfig{WideRootNoise: &noise}
+	if cacheFingerprint(&searched, "1.15.3") == base {
+		t.Error("Expected search settings to change the fingerprint")
+	}
+	if err := os.WriteFile(modelPath, []byte("new weights"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if cacheFingerprint(cfg, "1.15.3") == base {
+		t.Error("Expected a new network in the same file to change the fingerprint")
+	}
+}
+
+func TestSetFingerprintInvalidatesCache(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	cacheManager := cache.NewManager(&config.CacheConfig{Enabled: true, MaxItems: 10}, logger)
+	engine := NewEngine(&config.KataGoConfig{BinaryPath: "katago", MaxVisits: 100}, logger, cacheManager)
+	engine.SetCacheScope("fast")
+	alice := WithCacheNamespace(context.Background(), "team:alice")
+
+	engine.setFingerprint("aaaa")
+	oldKey, oldNamespaced := engine.cacheKey(context.Background(), "pos"), engine.cacheKey(alice, "pos")
+	if oldKey != "fast:aaaa:pos" {
+		t.Errorf("Expected the key scoped to the engine and fingerprint, got %q", oldKey)
+	}
+	cacheManager.PutForVisits(oldKey, &Response{}, 100, 10)
+	cacheManager.PutForVisits(oldNamespaced, &Response{}, 100, 10)
+	cacheManager.PutForVisits("other:pos", &Response{}, 100, 10)
+
+	engine.setFingerprint("bbbb")
+	if engine.cacheKey(context.Background(), "pos") == oldKey {
+		t.Error("Expected a new fingerprint to change the key")
+	}
+	for _, key := range []string{oldKey, oldNamespaced} {
+		if _, _, ok := cacheManager.Lookup(key); ok {
+			t.Errorf("Expected %q invalidated", key)
+		}
+	}
+	if _, _, ok := cacheManager.Lookup("other:pos"); !ok {
+		t.Error("Expected other engines' entries kept")
+	}
+}
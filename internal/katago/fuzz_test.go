@@ -0,0 +1,91 @@
+package katago
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// addSGFCorpus seeds a fuzz target with the SGFs in testdata/sgf.
+func addSGFCorpus(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("testdata", "sgf", "*.sgf"))
+	if err != nil || len(files) == 0 {
+		f.Fatalf("No SGF corpus found: %v", err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(data))
+	}
+}
+
+func FuzzSGFParse(f *testing.F) {
+	addSGFCorpus(f)
+	for _, sgf := range []string{"", "(", "(;", "(;B[", "(;SZ[0];B[aa])", "(;SZ[-5]AB[aa])", "(;B[zz])", "((((;B[aa]", ";)(;W[ab]"} {
+		f.Add(sgf)
+	}
+	f.Fuzz(func(t *testing.T, sgf string) {
+		position, err := NewSGFParser(sgf).Parse()
+		if err != nil {
+			return
+		}
+		// What the tools do with a parsed game, some of which replay it
+		// before it is validated
+		_, _ = BoardFromPosition(position)
+		FindRepetitions(position)
+		_ = ValidatePosition(position)
+	})
+}
+
+func FuzzParseCoord(f *testing.F) {
+	for _, coord := range []string{"A1", "T19", "J10", "I5", "Z99", "pass", "", "A", "A0", "A-1", "a1", "A1x", "A999999999999999999999"} {
+		f.Add(coord, 19)
+	}
+	f.Add("N13", 13)
+	f.Fuzz(func(t *testing.T, coord string, size int) {
+		x, y := parseCoord(coord, size)
+		if x < 0 || y < 0 {
+			return
+		}
+		if x >= size || y >= size {
+			t.Fatalf("parseCoord(%q, %d) = (%d, %d), off the board", coord, size, x, y)
+		}
+		if x2, y2 := parseCoord(coordToString(x, y, size), size); x2 != x || y2 != y {
+			t.Fatalf("%q parses to (%d, %d) but %s to (%d, %d)", coord, x, y, coordToString(x, y, size), x2, y2)
+		}
+	})
+}
+
+func FuzzParseResponse(f *testing.F) {
+	for _, line := range []string{
+		`{"id":"q1","turnNumber":0,"moveInfos":[{"move":"Q16","visits":50,"winrate":0.48,"scoreLead":-0.3,"order":0,"pv":["Q16","D4"]}],"rootInfo":{"visits":100,"winrate":0.47,"scoreLead":-0.4,"currentPlayer":"B"},"ownership":[0.1,-0.2],"policy":[0.01,-1]}`,
+		`{"id":"q2","movesOwnership":{"Q16":[[0.5,0.5],[0.1]]},"ownershipStdev":[0.2]}`,
+		`{"id":"q3","error":"Illegal move"}`,
+		`{"id":"q4","error":{"message":"bad"},"field":"moves"}`,
+		`{"id":"q5","moveInfos":"not a list"}`,
+		`{"id":"health","action":"query_version","version":"1.15.3","git_hash":"abc"}`,
+		`{"id":`,
+		`null`,
+		`[]`,
+	} {
+		f.Add(line)
+	}
+	req := &AnalysisRequest{IncludePolicy: true, IncludeOwnership: true, IncludeMovesOwnership: true, IncludeScoreDistribution: true}
+	f.Fuzz(func(t *testing.T, line string) {
+		response, _, err := parseResponse(line)
+		if response == nil {
+			t.Fatalf("parseResponse(%q) returned no response", line)
+		}
+		if err != nil {
+			return
+		}
+		_, _ = analysisResult(req, response)
+		if value, err := decodeResponse([]byte(line)); err == nil {
+			if _, err := encodeResponse(value); err != nil {
+				t.Fatalf("Failed to encode a decoded response: %v", err)
+			}
+		}
+	})
+}
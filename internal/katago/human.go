@@ -0,0 +1,139 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// maxHumanMoves is the number of human-likely moves returned by SuggestHumanMove.
+const maxHumanMoves = 5
+
+// humanProfilePattern matches KataGo humanSLProfile values: rank_20k to
+// rank_9d, preaz_20k to preaz_9d, and proyear_1800 to proyear_2099.
+var humanProfilePattern = regexp.MustCompile(`^((rank|preaz)_(1[0-9]|20|[1-9])k|(rank|preaz)_[1-9]d|proyear_(18|19|20)[0-9]{2})$`)
+
+// ValidateHumanProfile checks that a humanSLProfile is one KataGo understands.
+func ValidateHumanProfile(profile string) error {
+	if !humanProfilePattern.MatchString(profile) {
+		return apperrors.New(apperrors.CodeInvalidArgument,
+			"invalid human profile %q (expected e.g. rank_5k, rank_3d, preaz_1d or proyear_1990)", profile)
+	}
+	return nil
+}
+
+// HumanMove is a move a human player of the requested profile is likely to play.
+type HumanMove struct {
+	Move        string  `json:"move"`
+	Probability float64 `json:"probability"`         // Human SL policy probability
+	Analyzed    bool    `json:"analyzed"`            // Whether KataGo searched this move
+	Winrate     float64 `json:"winrate,omitempty"`   // Only set for analyzed moves
+	ScoreLead   float64 `json:"scoreLead,omitempty"` // Only set for analyzed moves
+	PointLoss   float64 `json:"pointLoss,omitempty"` // Score lost relative to the best move
+}
+
+// HumanMoveSuggestion compares human-likely moves with KataGo's best move.
+type HumanMoveSuggestion struct {
+	HumanProfile  string      `json:"humanProfile"`
+	CurrentPlayer string      `json:"currentPlayer"`
+	Moves         []HumanMove `json:"moves"` // Most likely first
+	BestMove      string      `json:"bestMove"`
+	BestWinrate   float64     `json:"bestWinrate"`
+	BestScoreLead float64     `json:"bestScoreLead"`
+}
+
+// SuggestHumanMove predicts what a player of the given humanSLProfile would
+// likely play, using the engine's human SL model. An empty profile uses the
+// engine's configured default.
+func (e *Engine) SuggestHumanMove(ctx context.Context, position *Position, humanProfile string) (*HumanMoveSuggestion, error) {
+	if humanProfile == "" {
+		humanProfile = e.config.HumanProfile
+	}
+	if humanProfile == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "humanProfile is required when no default is configured")
+	}
+	if e.config.HumanModelPath == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "engine has no human SL model configured (humanModelPath)")
+	}
+
+	req := &AnalysisRequest{
+		Position:      position,
+		IncludePolicy: true,
+		HumanProfile:  humanProfile,
+	}
+
+	result, err := e.Analyze(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze position: %w", err)
+	}
+
+	return buildHumanSuggestion(result, humanProfile, position.BoardXSize), nil
+}
+
+// buildHumanSuggestion ranks moves by the human policy and attaches search
+// results for moves KataGo analyzed.
+func buildHumanSuggestion(result *AnalysisResult, humanProfile string, boardSize int) *HumanMoveSuggestion {
+	suggestion := &HumanMoveSuggestion{
+		HumanProfile:  humanProfile,
+		CurrentPlayer: result.RootInfo.CurrentPlayer,
+	}
+
+	analyzed := make(map[string]MoveInfo, len(result.MoveInfos))
+	for _, mi := range result.MoveInfos {
+		analyzed[mi.Move] = mi
+	}
+
+	var best *MoveInfo
+	for i := range result.MoveInfos {
+		if best == nil || result.MoveInfos[i].Order < best.Order {
+			best = &result.MoveInfos[i]
+		}
+	}
+	if best != nil {
+		suggestion.BestMove = best.Move
+		suggestion.BestWinrate = best.Winrate
+		suggestion.BestScoreLead = best.ScoreLead
+	}
+
+	var moves []HumanMove
+	if len(result.HumanPolicy) > 0 {
+		for i, prob := range result.HumanPolicy {
+			if prob > 0 {
+				moves = append(moves, HumanMove{Move: indexToCoordinate(i, boardSize), Probability: prob})
+			}
+		}
+	} else {
+		// Fall back to the human priors of searched moves
+		for _, mi := range result.MoveInfos {
+			if mi.HumanPrior > 0 {
+				moves = append(moves, HumanMove{Move: mi.Move, Probability: mi.HumanPrior})
+			}
+		}
+	}
+
+	sort.SliceStable(moves, func(i, j int) bool {
+		return moves[i].Probability > moves[j].Probability
+	})
+	if len(moves) > maxHumanMoves {
+		moves = moves[:maxHumanMoves]
+	}
+
+	for i := range moves {
+		mi, ok := analyzed[moves[i].Move]
+		if !ok {
+			continue
+		}
+		moves[i].Analyzed = true
+		moves[i].Winrate = mi.Winrate
+		moves[i].ScoreLead = mi.ScoreLead
+		if best != nil {
+			moves[i].PointLoss = best.ScoreLead - mi.ScoreLead
+		}
+	}
+	suggestion.Moves = moves
+
+	return suggestion
+}
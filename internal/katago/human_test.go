@@ -0,0 +1,82 @@
+package katago
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+func TestValidateHumanProfile(t *testing.T) {
+	valid := []string{"rank_20k", "rank_5k", "rank_1d", "rank_9d", "preaz_3k", "proyear_1990"}
+	for _, profile := range valid {
+		if err := ValidateHumanProfile(profile); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", profile, err)
+		}
+	}
+
+	invalid := []string{"", "5k", "rank_21k", "rank_10d", "rank_0k", "proyear_90", "RANK_5K"}
+	for _, profile := range invalid {
+		err := ValidateHumanProfile(profile)
+		if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+			t.Errorf("Expected %q to be rejected with %s, got %v", profile, apperrors.CodeInvalidArgument, err)
+		}
+	}
+}
+
+func TestBuildHumanSuggestion(t *testing.T) {
+	// 3x3 board: index 0 is A3, index 4 is B2, index 9 is pass
+	result := &AnalysisResult{
+		RootInfo: RootInfo{CurrentPlayer: "B"},
+		MoveInfos: []MoveInfo{
+			{Move: "C1", Order: 0, Winrate: 0.6, ScoreLead: 3.0},
+			{Move: "B2", Order: 1, Winrate: 0.5, ScoreLead: 1.0},
+		},
+		HumanPolicy: []float64{0.1, 0, 0, 0, 0.6, 0, 0, 0, 0.05, 0.25},
+	}
+
+	suggestion := buildHumanSuggestion(result, "rank_5k", 3)
+
+	if suggestion.BestMove != "C1" {
+		t.Errorf("Expected best move C1, got %s", suggestion.BestMove)
+	}
+	if len(suggestion.Moves) != 4 {
+		t.Fatalf("Expected 4 human moves, got %d", len(suggestion.Moves))
+	}
+	top := suggestion.Moves[0]
+	if top.Move != "B2" || top.Probability != 0.6 {
+		t.Errorf("Expected B2 at 60%% first, got %+v", top)
+	}
+	if !top.Analyzed || top.PointLoss != 2.0 {
+		t.Errorf("Expected analyzed B2 losing 2 points, got %+v", top)
+	}
+	if suggestion.Moves[1].Move != "pass" || suggestion.Moves[1].Analyzed {
+		t.Errorf("Expected unanalyzed pass second, got %+v", suggestion.Moves[1])
+	}
+}
+
+func TestBuildHumanSuggestionFromPriors(t *testing.T) {
+	result := &AnalysisResult{
+		MoveInfos: []MoveInfo{
+			{Move: "D4", Order: 0, HumanPrior: 0.1},
+			{Move: "Q16", Order: 1, HumanPrior: 0.3},
+		},
+	}
+
+	suggestion := buildHumanSuggestion(result, "rank_1d", 19)
+	if len(suggestion.Moves) != 2 || suggestion.Moves[0].Move != "Q16" {
+		t.Errorf("Expected moves ordered by human prior, got %+v", suggestion.Moves)
+	}
+}
+
+func TestSuggestHumanMoveRequiresHumanModel(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := NewEngine(&config.KataGoConfig{HumanProfile: "rank_5k"}, logger, nil)
+
+	_, err := engine.SuggestHumanMove(context.Background(), &Position{BoardXSize: 19, BoardYSize: 19}, "")
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s without a human model, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
@@ -13,12 +13,44 @@ type EngineInterface interface {
 	// Stop stops the engine process
 	Stop() error
 
+	// Drain refuses new queries and stops the engine once outstanding
+	// queries finish or ctx ends
+	Drain(ctx context.Context) error
+
 	// IsRunning returns whether the engine is running
 	IsRunning() bool
 
 	// Ping checks if the engine is responsive
 	Ping(ctx context.Context) error
 
+	// ProcessID returns the engine process ID, or 0 if it is not running
+	ProcessID() int
+
+	// Load returns the number of queries waiting on the engine
+	Load() EngineLoad
+
+	// Capabilities returns the protocol features of the running KataGo release
+	Capabilities() Capabilities
+
+	// StartupProgress returns how far KataGo has got loading its model
+	StartupProgress() StartupProgress
+
+	// Recording returns the raw lines exchanged with KataGo, or nil when
+	// recording is disabled
+	Recording() []RecordedLine
+
+	// SlowQueries returns the queries slower than the slow query
+	// threshold, or nil when the slow query log is disabled
+	SlowQueries() []SlowQuery
+
+	// CollectDiagnostics gathers a diagnostics bundle after KataGo crashed
+	// or stopped responding, and keeps it as the latest
+	CollectDiagnostics(reason string) *DiagnosticsBundle
+
+	// Diagnostics returns the latest diagnostics bundle, or nil if none
+	// was collected
+	Diagnostics() *DiagnosticsBundle
+
 	// Analyze analyzes a position
 	Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error)
 
@@ -28,11 +60,54 @@ type EngineInterface interface {
 	// ReviewGame reviews a complete game for mistakes
 	ReviewGame(ctx context.Context, sgf string, thresholds *MistakeThresholds) (*GameReview, error)
 
+	// KeyMoments picks the most pivotal moves of a game
+	KeyMoments(ctx context.Context, sgf string, opts *KeyMomentOptions) (*KeyMoments, error)
+
 	// EstimateTerritory estimates territory ownership
 	EstimateTerritory(ctx context.Context, position *Position, threshold float64) (*TerritoryEstimate, error)
 
+	// TerritoryTimeline tracks each player's territory through a game
+	TerritoryTimeline(ctx context.Context, sgf string, opts *TimelineOptions) (*TerritoryTimeline, error)
+
 	// ExplainMove explains why a move is good or bad
-	ExplainMove(ctx context.Context, position *Position, move string) (*MoveExplanation, error)
+	ExplainMove(ctx context.Context, position *Position, move string, opts *ExplainOptions) (*MoveExplanation, error)
+
+	// SuggestHumanMove predicts moves a human of the given profile would play
+	SuggestHumanMove(ctx context.Context, position *Position, humanProfile string) (*HumanMoveSuggestion, error)
+
+	// EstimateRank estimates a player's rank from one or more games
+	EstimateRank(ctx context.Context, sgfs []string, player string, opts *RankOptions) (*RankEstimate, error)
+
+	// ExpandVariation re-analyzes a candidate move's principal variation
+	ExpandVariation(ctx context.Context, position *Position, move string, opts *VariationOptions) (*VariationTree, error)
+
+	// SweepKomi evaluates a position across komi values to find the fair komi
+	SweepKomi(ctx context.Context, position *Position, opts *KomiSweepOptions) (*KomiSweep, error)
+
+	// WhatIf evaluates a hypothetical sequence of moves against the best line
+	WhatIf(ctx context.Context, position *Position, moves []string, opts *WhatIfOptions) (*WhatIfResult, error)
+
+	// SolveTsumego solves a local life-and-death problem within a region
+	SolveTsumego(ctx context.Context, position *Position, opts *TsumegoOptions) (*TsumegoSolution, error)
+
+	// GenerateProblems turns a player's blunders into a problem set
+	GenerateProblems(ctx context.Context, sgfs []string, player string, opts *ProblemOptions) (*ProblemSet, error)
+
+	// OpeningBook builds a player's opening tree and evaluates their habits
+	OpeningBook(ctx context.Context, sgfs []string, player string, opts *OpeningOptions) (*OpeningBook, error)
+}
+
+// EngineLoad counts the queries waiting on an engine.
+type EngineLoad struct {
+	Pending int `json:"pending"` // Sent to KataGo and awaiting a response
+	Queued  int `json:"queued"`  // Waiting to be sent
+	// Lanes counts the queries waiting for a slot in each scheduling lane
+	Lanes map[string]int `json:"lanes,omitempty"`
+	// Draining is set while the engine refuses new queries to stop
+	Draining bool `json:"draining,omitempty"`
+	// IdleSeconds is the time since the engine was started or last sent or
+	// answered a query, when it has none outstanding
+	IdleSeconds float64 `json:"idleSeconds,omitempty"`
 }
 
 // Ensure Engine implements EngineInterface.
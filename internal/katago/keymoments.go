@@ -0,0 +1,300 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// Kinds of key moment.
+const (
+	MomentDecisive  = "decisive"  // The last time the game changed hands
+	MomentBlunder   = "blunder"   // A large loss for the player who moved
+	MomentBrilliant = "brilliant" // KataGo's best move found despite a low policy prior
+	MomentSwing     = "swing"     // Any other large change in evaluation
+)
+
+const (
+	defaultKeyMoments      = 8
+	maxKeyMoments          = 20
+	defaultKeyMomentVisits = 50
+	// keyMomentWorkers bounds the positions analyzed at once, so a long
+	// game does not fill the engine's queue.
+	keyMomentWorkers = 4
+	// keyMomentBlunder is the win rate loss that makes a move a blunder,
+	// as in DefaultMistakeThresholds.
+	keyMomentBlunder = 0.15
+	// brilliantPrior is the policy prior below which finding the best move
+	// is brilliant.
+	brilliantPrior = 0.05
+	// pointsPerWinrate converts score changes to the scale of win rate
+	// changes when ranking moments, so swings in decided games still count.
+	pointsPerWinrate = 20.0
+	// Bonuses added to a moment's importance for its kind.
+	decisiveBonus  = 0.5
+	brilliantBonus = 0.25
+	// minMomentImportance leaves out quiet moves when a game has fewer
+	// pivotal moments than requested.
+	minMomentImportance = 0.02
+)
+
+// KeyMomentOptions controls key moment detection.
+type KeyMomentOptions struct {
+	Count     int // Moments to return (default: 8, at most 20)
+	MaxVisits int // Visits per position (default: 50)
+}
+
+// KeyMoment is one of the most pivotal moves of a game. Win rates are the
+// mover's.
+type KeyMoment struct {
+	MoveNumber    int     `json:"moveNumber"`
+	Color         string  `json:"color"`
+	Move          string  `json:"move"`
+	Kind          string  `json:"kind"`
+	BestMove      string  `json:"bestMove,omitempty"`
+	Prior         float64 `json:"prior"` // Policy prior of the played move
+	WinrateBefore float64 `json:"winrateBefore"`
+	WinrateAfter  float64 `json:"winrateAfter"`
+	ScoreChange   float64 `json:"scoreChange"`
+	Annotation    string  `json:"annotation"`
+	importance    float64
+}
+
+// KeyMoments are the most pivotal moves of a game, in move order.
+type KeyMoments struct {
+	TotalMoves    int         `json:"totalMoves"`
+	MovesAnalyzed int         `json:"movesAnalyzed"`
+	Moments       []KeyMoment `json:"moments"`
+	// Partial is set when some positions could not be analyzed.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// KeyMoments analyzes every position of a game at low visits and picks
+// the moves where the game turned: the largest win rate and score swings,
+// the move that decided the game, and best moves found despite a low
+// policy prior. It is a faster alternative to ReviewGame. Win rates must
+// be reported for Black (reportAnalysisWinratesAs = BLACK).
+func (e *Engine) KeyMoments(ctx context.Context, sgf string, opts *KeyMomentOptions) (*KeyMoments, error) {
+	return keyMoments(ctx, e.Analyze, sgf, opts, func(moveNumber int, err error) {
+		e.logger.Error("Failed to analyze position for key moments", "move", moveNumber, "error", err)
+	})
+}
+
+// keyMoments finds key moments with the given analysis function. onError
+// is called for each position that could not be analyzed.
+func keyMoments(ctx context.Context, analyze func(context.Context, *AnalysisRequest) (*AnalysisResult, error),
+	sgf string, opts *KeyMomentOptions, onError func(int, error)) (*KeyMoments, error) {
+	game, err := NewSGFParser(sgf).Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+	if len(game.Moves) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "the game has no moves")
+	}
+	count, visits := defaultKeyMoments, defaultKeyMomentVisits
+	if opts != nil && opts.Count > 0 {
+		count = min(opts.Count, maxKeyMoments)
+	}
+	if opts != nil && opts.MaxVisits > 0 {
+		visits = opts.MaxVisits
+	}
+
+	// results[i] is the analysis of the position after i moves
+	results := make([]*AnalysisResult, len(game.Moves)+1)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < keyMomentWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				position := *game
+				position.Moves = game.Moves[:i]
+				positionVisits := visits
+				result, err := analyze(ctx, &AnalysisRequest{Position: &position, MaxVisits: &positionVisits})
+				if err != nil {
+					if ctx.Err() == nil {
+						onError(i, err)
+					}
+					continue
+				}
+				results[i] = result
+			}
+		}()
+	}
+	for i := range results {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	moments := &KeyMoments{TotalMoves: len(game.Moves), Moments: []KeyMoment{}}
+	for _, result := range results {
+		if result == nil {
+			moments.Partial = true
+		}
+	}
+	candidates := momentCandidates(game.Moves, results)
+	moments.MovesAnalyzed = len(candidates)
+	if len(candidates) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, apperrors.Wrap(apperrors.CodeOf(err), err, "key moment detection stopped before any move was analyzed")
+		}
+		return nil, fmt.Errorf("no move could be analyzed")
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].importance > candidates[j].importance
+	})
+	for _, moment := range candidates {
+		if len(moments.Moments) == count || moment.importance < minMomentImportance {
+			break
+		}
+		moments.Moments = append(moments.Moments, moment)
+	}
+	sort.Slice(moments.Moments, func(i, j int) bool {
+		return moments.Moments[i].MoveNumber < moments.Moments[j].MoveNumber
+	})
+	return moments, nil
+}
+
+// momentCandidates evaluates every move whose positions before and after
+// were analyzed.
+func momentCandidates(moves []Move, results []*AnalysisResult) []KeyMoment {
+	// The move after the last analyzed position in which the player to win
+	// was behind
+	decisive := 0
+	if final := results[len(moves)]; final != nil {
+		winner := leader(final)
+		for i := len(moves); i > 0; i-- {
+			if results[i-1] != nil && leader(results[i-1]) != winner {
+				decisive = i
+				break
+			}
+		}
+	}
+
+	var candidates []KeyMoment
+	for i := 1; i <= len(moves); i++ {
+		before, after := results[i-1], results[i]
+		if before == nil || after == nil {
+			continue
+		}
+		move := moves[i-1]
+		color := strings.ToUpper(move.Color)
+		played := move.Location
+		if played == "" {
+			played = "pass"
+		}
+		moment := KeyMoment{
+			MoveNumber:    i,
+			Color:         color,
+			Move:          played,
+			WinrateBefore: moverWinrate(before, color),
+			WinrateAfter:  moverWinrate(after, color),
+			ScoreChange:   roundTenth(moverLead(after, color) - moverLead(before, color)),
+			Kind:          MomentSwing,
+		}
+		change := moment.WinrateAfter - moment.WinrateBefore
+		moment.importance = math.Abs(change) + math.Abs(moment.ScoreChange)/pointsPerWinrate
+
+		if best, ok := bestMoveInfo(before); ok {
+			moment.BestMove = best.Move
+			for _, mi := range before.MoveInfos {
+				if mi.Move == played {
+					moment.Prior = mi.Prior
+				}
+			}
+			if best.Move == played && best.Prior < brilliantPrior {
+				moment.Kind = MomentBrilliant
+				moment.importance += brilliantBonus
+			}
+		}
+		if moment.BestMove == played {
+			moment.BestMove = ""
+		}
+		switch {
+		case i == decisive && change < 0:
+			moment.Kind = MomentDecisive
+			moment.importance += decisiveBonus
+		case moment.Kind != MomentBrilliant && -change >= keyMomentBlunder:
+			moment.Kind = MomentBlunder
+		}
+		moment.Annotation = annotateMoment(&moment)
+		candidates = append(candidates, moment)
+	}
+	return candidates
+}
+
+// leader returns the player ahead in an analysis reported for Black.
+func leader(result *AnalysisResult) string {
+	if result.RootInfo.Winrate < 0.5 {
+		return "W"
+	}
+	return "B"
+}
+
+// moverWinrate returns a player's win rate from an analysis reported for
+// Black.
+func moverWinrate(result *AnalysisResult, color string) float64 {
+	if color == "W" {
+		return 1 - result.RootInfo.Winrate
+	}
+	return result.RootInfo.Winrate
+}
+
+// moverLead returns a player's score lead from an analysis reported for
+// Black.
+func moverLead(result *AnalysisResult, color string) float64 {
+	if color == "W" {
+		return -result.RootInfo.ScoreLead
+	}
+	return result.RootInfo.ScoreLead
+}
+
+// bestMoveInfo returns KataGo's first choice in an analysis.
+func bestMoveInfo(result *AnalysisResult) (MoveInfo, bool) {
+	if len(result.MoveInfos) == 0 {
+		return MoveInfo{}, false
+	}
+	best := result.MoveInfos[0]
+	for _, mi := range result.MoveInfos {
+		if mi.Order < best.Order {
+			best = mi
+		}
+	}
+	return best, true
+}
+
+// annotateMoment describes a key moment in a sentence.
+func annotateMoment(m *KeyMoment) string {
+	player := "Black"
+	if m.Color == "W" {
+		player = "White"
+	}
+	var sb strings.Builder
+	change := (m.WinrateAfter - m.WinrateBefore) * 100
+	switch m.Kind {
+	case MomentDecisive:
+		sb.WriteString(fmt.Sprintf("Decided the game: %s's win rate fell from %.1f%% to %.1f%% and never recovered",
+			player, m.WinrateBefore*100, m.WinrateAfter*100))
+	case MomentBrilliant:
+		sb.WriteString(fmt.Sprintf("Found KataGo's best move despite a %.1f%% policy prior", m.Prior*100))
+	case MomentBlunder:
+		sb.WriteString(fmt.Sprintf("Loses %.1f%% win rate (%+.1f points)", -change, m.ScoreChange))
+	default:
+		sb.WriteString(fmt.Sprintf("Changes %s's win rate by %+.1f%% (%+.1f points)", player, change, m.ScoreChange))
+	}
+	if m.BestMove != "" && m.Kind != MomentBrilliant {
+		sb.WriteString(fmt.Sprintf("; %s was best", m.BestMove))
+	}
+	return sb.String()
+}
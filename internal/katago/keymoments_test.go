@@ -0,0 +1,77 @@
+package katago
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestKeyMoments(t *testing.T) {
+	// White takes the lead with move 6, hands it back with move 10 and
+	// blunders again with move 12. Black's move 15, B4, is KataGo's best
+	// move with a 1% prior. The position after move 18 cannot be analyzed.
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		moves := len(req.Position.Moves)
+		result := &AnalysisResult{MoveInfos: []MoveInfo{{Move: "E5", Prior: 0.3}}}
+		switch {
+		case moves == 18:
+			return nil, errors.New("engine failure")
+		case moves == 14:
+			result.MoveInfos = []MoveInfo{{Move: "B4", Prior: 0.01}}
+			fallthrough
+		case moves >= 12:
+			result.RootInfo = RootInfo{Winrate: 0.95, ScoreLead: 15}
+		case moves >= 10:
+			result.RootInfo = RootInfo{Winrate: 0.7, ScoreLead: 5}
+		case moves >= 6:
+			result.RootInfo = RootInfo{Winrate: 0.3, ScoreLead: -4}
+		default:
+			result.RootInfo = RootInfo{Winrate: 0.55, ScoreLead: 1}
+		}
+		return result, nil
+	}
+
+	var failed []int
+	moments, err := keyMoments(context.Background(), analyze, testGame(20), nil,
+		func(moveNumber int, err error) { failed = append(failed, moveNumber) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !moments.Partial || !reflect.DeepEqual(failed, []int{18}) || moments.MovesAnalyzed != 18 {
+		t.Errorf("Expected a partial result missing moves 18 and 19, got %+v (failed %v)", moments, failed)
+	}
+
+	var numbers []int
+	var kinds []string
+	for _, m := range moments.Moments {
+		numbers = append(numbers, m.MoveNumber)
+		kinds = append(kinds, m.Kind)
+	}
+	if !reflect.DeepEqual(numbers, []int{6, 10, 12, 15}) {
+		t.Fatalf("Expected moments at moves 6, 10, 12 and 15, got %v", numbers)
+	}
+	if want := []string{MomentSwing, MomentDecisive, MomentBlunder, MomentBrilliant}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Expected kinds %v, got %v", want, kinds)
+	}
+
+	decisive := moments.Moments[1]
+	if decisive.Color != "W" || decisive.WinrateBefore != 0.7 || decisive.ScoreChange != -9 || decisive.BestMove != "E5" {
+		t.Errorf("Unexpected decisive moment: %+v", decisive)
+	}
+	if want := "Decided the game: White's win rate fell from 70.0% to 30.0% and never recovered; E5 was best"; decisive.Annotation != want {
+		t.Errorf("Expected annotation %q, got %q", want, decisive.Annotation)
+	}
+	if brilliant := moments.Moments[3]; brilliant.Move != "B4" || brilliant.BestMove != "" || brilliant.Prior != 0.01 {
+		t.Errorf("Unexpected brilliant moment: %+v", brilliant)
+	}
+
+	// Fewer moments keep the most important
+	moments, err = keyMoments(context.Background(), analyze, testGame(20), &KeyMomentOptions{Count: 2}, func(int, error) {})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(moments.Moments) != 2 || moments.Moments[0].MoveNumber != 10 || moments.Moments[1].MoveNumber != 12 {
+		t.Errorf("Expected moves 10 and 12, got %+v", moments.Moments)
+	}
+}
@@ -0,0 +1,200 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+const (
+	defaultKomiSweepVisits = 200
+	// defaultKomiSweepRange is the distance either side of the position's
+	// komi covered when no bounds are given.
+	defaultKomiSweepRange = 10.0
+	defaultKomiSweepStep  = 2.0
+	// defaultKomi is the center of the sweep for positions without komi.
+	defaultKomi = 7.5
+	// maxKomiSweepPoints bounds the number of queries one sweep may send.
+	maxKomiSweepPoints = 41
+	// maxAbsKomi is the largest komi KataGo accepts.
+	maxAbsKomi = 150.0
+)
+
+// KomiSweepOptions controls a komi sweep.
+type KomiSweepOptions struct {
+	MinKomi   *float64 // Lowest komi (default: position komi - 10)
+	MaxKomi   *float64 // Highest komi (default: position komi + 10)
+	Step      float64  // Komi increment, a multiple of 0.5 (default: 2)
+	MaxVisits int      // Visits per komi value (default: 200)
+}
+
+// KomiPoint is KataGo's evaluation of a position at one komi.
+type KomiPoint struct {
+	Komi      float64 `json:"komi"`
+	Winrate   float64 `json:"winrate"`
+	ScoreLead float64 `json:"scoreLead"`
+	Visits    int     `json:"visits"`
+}
+
+// KomiSweep is the result of evaluating a position across komi values.
+type KomiSweep struct {
+	CurrentPlayer string      `json:"currentPlayer"`
+	Points        []KomiPoint `json:"points"` // Ordered by komi
+	// FairKomi is the komi at which the win rate crosses 50%, interpolated
+	// between the neighboring sweep points. It is unset when the win rate
+	// stays on one side of 50% across the sweep.
+	FairKomi *float64 `json:"fairKomi,omitempty"`
+	// Partial is set when some komi values could not be analyzed.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// SweepKomi analyzes a position at several komi values in parallel and
+// finds the komi at which the game is even. The crossing does not depend
+// on whose perspective KataGo reports win rates from.
+func (e *Engine) SweepKomi(ctx context.Context, position *Position, opts *KomiSweepOptions) (*KomiSweep, error) {
+	return sweepKomi(ctx, e.Analyze, position, opts, func(komi float64, err error) {
+		e.logger.Error("Failed to analyze position for komi sweep", "komi", komi, "error", err)
+	})
+}
+
+// sweepKomi runs a komi sweep with the given analysis function. onError is
+// called for each komi value that could not be analyzed.
+func sweepKomi(ctx context.Context, analyze func(context.Context, *AnalysisRequest) (*AnalysisResult, error),
+	position *Position, opts *KomiSweepOptions, onError func(float64, error)) (*KomiSweep, error) {
+	if err := ValidatePosition(position); err != nil {
+		return nil, fmt.Errorf("invalid position: %w", err)
+	}
+	komis, err := komiSweepValues(position, opts)
+	if err != nil {
+		return nil, err
+	}
+	visits := defaultKomiSweepVisits
+	if opts != nil && opts.MaxVisits > 0 {
+		visits = opts.MaxVisits
+	}
+
+	points := make([]*KomiPoint, len(komis))
+	results := make([]*AnalysisResult, len(komis))
+	var wg sync.WaitGroup
+	for i, komi := range komis {
+		wg.Add(1)
+		go func(i int, komi float64) {
+			defer wg.Done()
+			komiVisits := visits
+			result, err := analyze(ctx, &AnalysisRequest{Position: position, MaxVisits: &komiVisits, Komi: &komi})
+			if err != nil {
+				if ctx.Err() == nil {
+					onError(komi, err)
+				}
+				return
+			}
+			results[i] = result
+			points[i] = &KomiPoint{
+				Komi:      komi,
+				Winrate:   result.RootInfo.Winrate,
+				ScoreLead: result.RootInfo.ScoreLead,
+				Visits:    result.RootInfo.Visits,
+			}
+		}(i, komi)
+	}
+	wg.Wait()
+
+	sweep := &KomiSweep{}
+	for i, point := range points {
+		if point == nil {
+			sweep.Partial = true
+			continue
+		}
+		sweep.CurrentPlayer = results[i].RootInfo.CurrentPlayer
+		sweep.Points = append(sweep.Points, *point)
+	}
+	if len(sweep.Points) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, apperrors.Wrap(apperrors.CodeOf(err), err, "komi sweep stopped before any komi was analyzed")
+		}
+		return nil, fmt.Errorf("no komi value could be analyzed")
+	}
+	sweep.FairKomi = fairKomi(sweep.Points)
+	return sweep, nil
+}
+
+// komiSweepValues lists the komi values to analyze, applying defaults and
+// bounds to the options.
+func komiSweepValues(position *Position, opts *KomiSweepOptions) ([]float64, error) {
+	center := position.Komi
+	if center == 0 {
+		center = defaultKomi
+	}
+	low, high, step := center-defaultKomiSweepRange, center+defaultKomiSweepRange, defaultKomiSweepStep
+	if opts != nil {
+		if opts.MinKomi != nil {
+			low = *opts.MinKomi
+		}
+		if opts.MaxKomi != nil {
+			high = *opts.MaxKomi
+		}
+		if opts.Step != 0 {
+			step = opts.Step
+		}
+	}
+
+	if step <= 0 || !isHalfInteger(step) {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "komi step must be a positive multiple of 0.5")
+	}
+	if !isHalfInteger(low) || !isHalfInteger(high) {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "komi bounds must be multiples of 0.5")
+	}
+	if low > high {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "minimum komi %.1f is above maximum komi %.1f", low, high)
+	}
+	// Clamp the default range to what KataGo accepts
+	low, high = math.Max(low, -maxAbsKomi), math.Min(high, maxAbsKomi)
+	if low > high {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "komi must be between %.0f and %.0f", -maxAbsKomi, maxAbsKomi)
+	}
+
+	count := int(math.Floor((high-low)/step)) + 1
+	if count > maxKomiSweepPoints {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument,
+			"komi sweep would analyze %d values; at most %d are allowed", count, maxKomiSweepPoints)
+	}
+	komis := make([]float64, 0, count+1)
+	for i := 0; i < count; i++ {
+		komis = append(komis, low+float64(i)*step)
+	}
+	if komis[len(komis)-1] != high {
+		komis = append(komis, high)
+	}
+	return komis, nil
+}
+
+// fairKomi finds where the win rate crosses 50% between adjacent points.
+func fairKomi(points []KomiPoint) *float64 {
+	sorted := append([]KomiPoint(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Komi < sorted[j].Komi })
+
+	for i, p := range sorted {
+		if p.Winrate == 0.5 {
+			komi := p.Komi
+			return &komi
+		}
+		if i == 0 {
+			continue
+		}
+		prev := sorted[i-1]
+		if (prev.Winrate-0.5)*(p.Winrate-0.5) < 0 {
+			komi := prev.Komi + (0.5-prev.Winrate)*(p.Komi-prev.Komi)/(p.Winrate-prev.Winrate)
+			return &komi
+		}
+	}
+	return nil
+}
+
+// isHalfInteger reports whether v is a multiple of 0.5.
+func isHalfInteger(v float64) bool {
+	return v*2 == math.Trunc(v*2)
+}
@@ -0,0 +1,115 @@
+package katago
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+func TestKomiSweepValues(t *testing.T) {
+	position := &Position{Rules: "chinese", BoardXSize: 19, BoardYSize: 19, Komi: 6.5}
+	komis, err := komiSweepValues(position, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(komis) != 11 || komis[0] != -3.5 || komis[10] != 16.5 {
+		t.Errorf("Expected 11 values from -3.5 to 16.5, got %v", komis)
+	}
+
+	low, high := 0.0, 5.0
+	komis, err = komiSweepValues(position, &KomiSweepOptions{MinKomi: &low, MaxKomi: &high, Step: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(komis) != 4 || komis[0] != 0 || komis[3] != 5 {
+		t.Errorf("Expected 0, 2, 4, 5, got %v", komis)
+	}
+
+	tests := []struct {
+		name string
+		opts *KomiSweepOptions
+	}{
+		{"fractional step", &KomiSweepOptions{Step: 0.3}},
+		{"negative step", &KomiSweepOptions{Step: -1}},
+		{"fractional bound", &KomiSweepOptions{MinKomi: floatPtr(0.25)}},
+		{"inverted bounds", &KomiSweepOptions{MinKomi: floatPtr(10), MaxKomi: floatPtr(0)}},
+		{"too many values", &KomiSweepOptions{MinKomi: floatPtr(-20), Step: 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := komiSweepValues(position, tt.opts); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+				t.Errorf("Expected %s, got %v", apperrors.CodeInvalidArgument, err)
+			}
+		})
+	}
+}
+
+func TestSweepKomi(t *testing.T) {
+	position := &Position{Rules: "chinese", BoardXSize: 9, BoardYSize: 9, Komi: 7}
+	var mu sync.Mutex
+	var sent []float64
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		mu.Lock()
+		sent = append(sent, *req.Komi)
+		mu.Unlock()
+		if *req.Komi == 9 {
+			return nil, errors.New("engine failure")
+		}
+		// Even at komi 5.5
+		return &AnalysisResult{RootInfo: RootInfo{Visits: 10, Winrate: 0.5 + 0.05*(5.5-*req.Komi), CurrentPlayer: "B"}}, nil
+	}
+
+	low, high := 1.0, 9.0
+	var failed []float64
+	sweep, err := sweepKomi(context.Background(), analyze, position,
+		&KomiSweepOptions{MinKomi: &low, MaxKomi: &high, Step: 2},
+		func(komi float64, err error) { failed = append(failed, komi) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sent) != 5 {
+		t.Errorf("Expected 5 queries, got %v", sent)
+	}
+	if !sweep.Partial || len(failed) != 1 || len(sweep.Points) != 4 {
+		t.Errorf("Expected a partial sweep missing komi 9, got %+v (failed %v)", sweep, failed)
+	}
+	for i := 1; i < len(sweep.Points); i++ {
+		if sweep.Points[i].Komi <= sweep.Points[i-1].Komi {
+			t.Errorf("Expected points ordered by komi, got %+v", sweep.Points)
+		}
+	}
+	if sweep.FairKomi == nil || *sweep.FairKomi < 5.49 || *sweep.FairKomi > 5.51 {
+		t.Errorf("Expected fair komi 5.5, got %v", sweep.FairKomi)
+	}
+}
+
+func TestFairKomi(t *testing.T) {
+	// Win rates reported for White rise with komi; the crossing is the same
+	points := []KomiPoint{{Komi: 4, Winrate: 0.3}, {Komi: 6, Winrate: 0.45}, {Komi: 8, Winrate: 0.65}}
+	if komi := fairKomi(points); komi == nil || *komi != 6.5 {
+		t.Errorf("Expected fair komi 6.5, got %v", komi)
+	}
+	if komi := fairKomi([]KomiPoint{{Komi: 4, Winrate: 0.7}, {Komi: 6, Winrate: 0.6}}); komi != nil {
+		t.Errorf("Expected no crossing, got %v", *komi)
+	}
+}
+
+func TestBuildAnalysisQueryKomiOverride(t *testing.T) {
+	query, err := buildAnalysisQuery(&AnalysisRequest{
+		Position: &Position{Rules: "chinese", BoardXSize: 9, BoardYSize: 9, Komi: 7},
+		Komi:     floatPtr(0),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query["komi"] != 0.0 {
+		t.Errorf("Expected komi 0 to be sent, got %v", query["komi"])
+	}
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
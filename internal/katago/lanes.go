@@ -0,0 +1,203 @@
+package katago
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+)
+
+// defaultMaxInFlight is the number of queries sent to KataGo at once when
+// neither the server nor KataGo's config file sets it, matching KataGo's
+// own default numAnalysisThreads.
+const defaultMaxInFlight = 2
+
+// laneKey is the context key of a request's scheduling lane.
+type laneKey struct{}
+
+// WithLane returns a context whose queries are scheduled in the given lane,
+// one of config.LaneInteractive, config.LaneBatch and
+// config.LaneBackground.
+func WithLane(ctx context.Context, lane string) context.Context {
+	return context.WithValue(ctx, laneKey{}, lane)
+}
+
+// LaneFromContext returns the scheduling lane of a context's queries,
+// interactive unless another was set.
+func LaneFromContext(ctx context.Context) string {
+	if lane, ok := ctx.Value(laneKey{}).(string); ok && lane != "" {
+		return lane
+	}
+	return config.LaneInteractive
+}
+
+// laneScheduler admits queries to KataGo a limited number at a time. While
+// queries wait, each free slot goes to the waiting lane with the least
+// virtual time, and admitting a query advances its lane's virtual time by
+// the inverse of the lane's weight. Lanes thus share KataGo in proportion
+// to their weights, and a busy background lane cannot starve an
+// interactive one.
+type laneScheduler struct {
+	mu       sync.Mutex
+	slots    int
+	inFlight int
+	lanes    map[string]*lane
+	order    []*lane // Lanes in priority order, to break ties
+	vtime    float64 // Virtual time of the last admitted query
+}
+
+// lane is a scheduling lane and its waiting queries.
+type lane struct {
+	name    string
+	stride  float64 // Virtual time charged per admitted query
+	pass    float64 // Virtual time of the lane's next admission
+	waiting []*laneWaiter
+}
+
+// laneWaiter is a query waiting for a slot.
+type laneWaiter struct {
+	ready    chan struct{}
+	admitted bool // Set, under the scheduler's lock, when it gets a slot
+}
+
+// newLaneScheduler creates a scheduler admitting slots queries at a time,
+// with lane weights defaulting to 1.
+func newLaneScheduler(slots int, weights map[string]int) *laneScheduler {
+	s := &laneScheduler{
+		slots: max(slots, 1),
+		lanes: make(map[string]*lane, len(config.Lanes)),
+	}
+	for _, name := range config.Lanes {
+		weight := weights[name]
+		if weight < 1 {
+			weight = 1
+		}
+		l := &lane{name: name, stride: 1 / float64(weight)}
+		s.lanes[name] = l
+		s.order = append(s.order, l)
+	}
+	return s
+}
+
+// maxInFlight returns the number of queries an engine sends to KataGo at
+// once: the configured number, else numAnalysisThreads from KataGo's config
+// file, else defaultMaxInFlight.
+func maxInFlight(cfg *config.KataGoConfig) int {
+	if cfg.Lanes.MaxInFlight > 0 {
+		return cfg.Lanes.MaxInFlight
+	}
+	if cfg.ConfigPath != "" {
+		if file, err := readConfigFile(cfg.ConfigPath); err == nil {
+			if threads, err := strconv.Atoi(file.Settings["numAnalysisThreads"]); err == nil && threads > 0 {
+				return threads
+			}
+		}
+	}
+	return defaultMaxInFlight
+}
+
+// acquire waits for a slot for a query in the named lane. The query must
+// call release once KataGo has answered it. It fails if ctx ends first. A
+// nil scheduler admits every query at once.
+func (s *laneScheduler) acquire(ctx context.Context, name string) (release func(), err error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	s.mu.Lock()
+	l, ok := s.lanes[name]
+	if !ok {
+		l = s.lanes[config.LaneInteractive]
+	}
+	if s.inFlight < s.slots && s.queued() == 0 {
+		// An idle lane does not bank time it did not use
+		l.pass = max(l.pass, s.vtime)
+		s.admit(l)
+		s.mu.Unlock()
+		return s.release, nil
+	}
+	w := &laneWaiter{ready: make(chan struct{})}
+	if len(l.waiting) == 0 {
+		l.pass = max(l.pass, s.vtime)
+	}
+	l.waiting = append(l.waiting, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return s.release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		if w.admitted {
+			s.mu.Unlock()
+			s.release()
+		} else {
+			l.remove(w)
+			s.mu.Unlock()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// release frees a slot and admits the next waiting query.
+func (s *laneScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+	for s.inFlight < s.slots {
+		var next *lane
+		for _, l := range s.order {
+			if len(l.waiting) > 0 && (next == nil || l.pass < next.pass) {
+				next = l
+			}
+		}
+		if next == nil {
+			return
+		}
+		w := next.waiting[0]
+		next.waiting = next.waiting[1:]
+		s.admit(next)
+		w.admitted = true
+		close(w.ready)
+	}
+}
+
+// admit takes a slot for a query in lane l. The caller must hold s.mu.
+func (s *laneScheduler) admit(l *lane) {
+	s.inFlight++
+	s.vtime = l.pass
+	l.pass += l.stride
+}
+
+// queued returns the number of waiting queries. The caller must hold s.mu.
+func (s *laneScheduler) queued() int {
+	n := 0
+	for _, l := range s.order {
+		n += len(l.waiting)
+	}
+	return n
+}
+
+// waitingByLane returns the number of queries waiting in each lane.
+func (s *laneScheduler) waitingByLane() map[string]int {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	waiting := make(map[string]int, len(s.order))
+	for _, l := range s.order {
+		waiting[l.name] = len(l.waiting)
+	}
+	return waiting
+}
+
+// remove drops a waiter that gave up.
+func (l *lane) remove(w *laneWaiter) {
+	for i, waiting := range l.waiting {
+		if waiting == w {
+			l.waiting = append(l.waiting[:i], l.waiting[i+1:]...)
+			return
+		}
+	}
+}
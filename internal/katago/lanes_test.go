@@ -0,0 +1,167 @@
+package katago
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+)
+
+// admission is a query that got a slot.
+type admission struct {
+	lane    string
+	release func()
+}
+
+// queueQueries queues a query for each lane in order behind a scheduler
+// whose slots are all taken, and returns the channel their admissions
+// arrive on.
+func queueQueries(t *testing.T, s *laneScheduler, lanes []string) <-chan admission {
+	t.Helper()
+	admitted := make(chan admission, len(lanes))
+	for i, lane := range lanes {
+		go func(lane string) {
+			release, err := s.acquire(context.Background(), lane)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			admitted <- admission{lane: lane, release: release}
+		}(lane)
+		// Queue them one at a time so ties keep their order
+		waitFor(t, func() bool {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.queued() == i+1
+		})
+	}
+	return admitted
+}
+
+// laneLetters abbreviates lanes in admission orders.
+var laneLetters = map[string]string{config.LaneInteractive: "i", config.LaneBatch: "b", config.LaneBackground: "g"}
+
+// admissionOrder releases the held slot and each admitted query in turn,
+// returning the lanes in the order they were admitted.
+func admissionOrder(t *testing.T, release func(), admitted <-chan admission, n int) string {
+	t.Helper()
+	var order []string
+	for i := 0; i < n; i++ {
+		release()
+		select {
+		case a := <-admitted:
+			order = append(order, laneLetters[a.lane])
+			release = a.release
+		case <-time.After(time.Second):
+			t.Fatalf("No query admitted after %v", order)
+		}
+	}
+	release()
+	return strings.Join(order, "")
+}
+
+func TestLaneScheduler(t *testing.T) {
+	weights := map[string]int{config.LaneInteractive: 8, config.LaneBatch: 2, config.LaneBackground: 1}
+
+	t.Run("LaneFromContext", func(t *testing.T) {
+		if lane := LaneFromContext(context.Background()); lane != config.LaneInteractive {
+			t.Errorf("Expected interactive by default, got %s", lane)
+		}
+		ctx := WithLane(context.Background(), config.LaneBatch)
+		if lane := LaneFromContext(ctx); lane != config.LaneBatch {
+			t.Errorf("Expected batch, got %s", lane)
+		}
+	})
+
+	t.Run("FreeSlots", func(t *testing.T) {
+		s := newLaneScheduler(2, weights)
+		first, err := s.acquire(context.Background(), config.LaneBackground)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := s.acquire(context.Background(), config.LaneBackground)
+		if err != nil {
+			t.Fatal(err)
+		}
+		first()
+		second()
+		if s.inFlight != 0 {
+			t.Errorf("Expected all slots free, got %d in flight", s.inFlight)
+		}
+	})
+
+	t.Run("InteractiveFirst", func(t *testing.T) {
+		s := newLaneScheduler(1, weights)
+		held, err := s.acquire(context.Background(), config.LaneBackground)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lanes := []string{config.LaneBackground, config.LaneBackground, config.LaneInteractive, config.LaneInteractive}
+		admitted := queueQueries(t, s, lanes)
+		if order := admissionOrder(t, held, admitted, len(lanes)); order != "iigg" {
+			t.Errorf("Expected interactive queries to overtake background ones, got %s", order)
+		}
+	})
+
+	t.Run("WeightedShare", func(t *testing.T) {
+		s := newLaneScheduler(1, weights)
+		held, err := s.acquire(context.Background(), config.LaneInteractive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lanes []string
+		for i := 0; i < 6; i++ {
+			lanes = append(lanes, config.LaneBatch, config.LaneBackground)
+		}
+		admitted := queueQueries(t, s, lanes)
+		// Batch has twice background's weight, but background is not starved
+		if order := admissionOrder(t, held, admitted, 6); order != "bgbbgb" {
+			t.Errorf("Expected batch to get two slots for each background one, got %s", order)
+		}
+		for i := 6; i < len(lanes); i++ {
+			(<-admitted).release()
+		}
+	})
+
+	t.Run("CanceledWhileQueued", func(t *testing.T) {
+		s := newLaneScheduler(1, weights)
+		held, err := s.acquire(context.Background(), config.LaneInteractive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := s.acquire(ctx, config.LaneBatch); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the wait to end with the context, got %v", err)
+		}
+		if waiting := s.waitingByLane(); waiting[config.LaneBatch] != 0 {
+			t.Errorf("Expected the canceled query to leave its lane, got %v", waiting)
+		}
+		held()
+		if s.inFlight != 0 {
+			t.Errorf("Expected the slot to be free, got %d in flight", s.inFlight)
+		}
+	})
+
+	t.Run("MaxInFlight", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "analysis.cfg")
+		if err := os.WriteFile(configPath, []byte("numAnalysisThreads = 6\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if n := maxInFlight(&config.KataGoConfig{ConfigPath: configPath}); n != 6 {
+			t.Errorf("Expected numAnalysisThreads from the config file, got %d", n)
+		}
+		cfg := &config.KataGoConfig{ConfigPath: configPath, Lanes: config.LaneConfig{MaxInFlight: 3}}
+		if n := maxInFlight(cfg); n != 3 {
+			t.Errorf("Expected the configured maximum, got %d", n)
+		}
+		if n := maxInFlight(&config.KataGoConfig{}); n != defaultMaxInFlight {
+			t.Errorf("Expected the default, got %d", n)
+		}
+	})
+}
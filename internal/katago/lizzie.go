@@ -0,0 +1,58 @@
+package katago
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LizzieSGF returns the game annotated as by AnnotateSGF, with KataGo's
+// analysis of each position of the main line in the properties desktop
+// GUIs read: LZ, the search Lizzie and LizzieYzy show as candidate moves
+// and a winrate graph, and SBKV, Black's win rate, which Sabaki graphs.
+// Positions the review did not analyze, such as the final one, are left
+// without them. Win rates must be reported for Black.
+func LizzieSGF(sgf string, review *GameReview) (string, error) {
+	return annotateSGF(sgf, review, true)
+}
+
+// lizzieProperties returns the LZ and SBKV properties of a position's
+// analysis, or "" if there is none. The first line of LZ holds the engine,
+// the win rate of the player who made the node's move as a percentage, the
+// visits and that player's score lead. It is followed by one Leela Zero
+// "info" entry per candidate, with win rates, priors and LCBs in
+// hundredths of a percent for the player to move.
+func lizzieProperties(result *AnalysisResult) string {
+	if result == nil || result.RootInfo.Visits == 0 {
+		return ""
+	}
+	toMove := strings.ToUpper(result.RootInfo.CurrentPlayer)
+	moved := "B"
+	if toMove != "W" {
+		moved = "W"
+	}
+
+	infos := append([]MoveInfo(nil), result.MoveInfos...)
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Order < infos[j].Order
+	})
+	entries := make([]string, len(infos))
+	for i, mi := range infos {
+		winrate, lcb, lead := mi.Winrate, mi.LCB, mi.ScoreLead
+		if toMove == "W" {
+			winrate, lcb, lead = 1-winrate, 1-lcb, -lead
+		}
+		entries[i] = fmt.Sprintf("info move %s visits %d winrate %d prior %d lcb %d order %d scoreMean %.2f scoreStdev %.2f pv %s",
+			mi.Move, mi.Visits, hundredths(winrate), hundredths(mi.Prior), hundredths(lcb), mi.Order,
+			lead, mi.ScoreStdev, strings.Join(mi.PV, " "))
+	}
+
+	lz := fmt.Sprintf("KataGo %.1f %d %.1f\n%s", moverWinrate(result, moved)*100, result.RootInfo.Visits,
+		moverLead(result, moved), strings.Join(entries, " "))
+	return fmt.Sprintf("LZ[%s]SBKV[%.2f]", escapeSGFText(lz), result.RootInfo.Winrate*100)
+}
+
+// hundredths converts a probability to hundredths of a percent.
+func hundredths(p float64) int {
+	return int(p*10000 + 0.5)
+}
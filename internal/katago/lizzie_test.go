@@ -0,0 +1,54 @@
+package katago
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLizzieSGF(t *testing.T) {
+	sgf := "(;GM[1]SZ[9]PB[Alice]PW[Bob]\n;B[ee];W[cc](;B[gg])(;B[cg]))"
+	review := &GameReview{
+		Summary: ReviewSummary{TotalMoves: 3, AnalyzedMoves: 3},
+		Analyses: []*AnalysisResult{
+			{
+				RootInfo: RootInfo{Visits: 500, Winrate: 0.6, ScoreLead: 2.5, CurrentPlayer: "B"},
+				MoveInfos: []MoveInfo{
+					{Move: "D5", Visits: 100, Winrate: 0.55, LCB: 0.5, Prior: 0.1, Order: 1, ScoreLead: 1.5, PV: []string{"D5"}},
+					{Move: "E5", Visits: 400, Winrate: 0.6, LCB: 0.58, Prior: 0.8, Order: 0, ScoreLead: 2.5, PV: []string{"E5", "C7"}},
+				},
+			},
+			{
+				RootInfo:  RootInfo{Visits: 200, Winrate: 0.7, ScoreLead: 4, CurrentPlayer: "W"},
+				MoveInfos: []MoveInfo{{Move: "G3", Visits: 200, Winrate: 0.7, LCB: 0.65, Prior: 0.3, ScoreLead: 4, PV: []string{"G3"}}},
+			},
+			nil,
+		},
+	}
+
+	exported, err := LizzieSGF(sgf, review)
+	if err != nil {
+		t.Fatalf("LizzieSGF failed: %v", err)
+	}
+	for _, want := range []string{
+		// The empty board, with Black's candidates in order
+		"LZ[KataGo 40.0 500 -2.5\ninfo move E5 visits 400 winrate 6000 prior 8000 lcb 5800 order 0 scoreMean 2.50 scoreStdev 0.00 pv E5 C7 " +
+			"info move D5 visits 100 winrate 5500 prior 1000 lcb 5000 order 1 scoreMean 1.50 scoreStdev 0.00 pv D5]SBKV[60.00]",
+		// After Black's move, with Black's win rate and White's candidates
+		";B[ee]LZ[KataGo 70.0 200 4.0\ninfo move G3 visits 200 winrate 3000 prior 3000 lcb 3500 order 0 scoreMean -4.00",
+		"SBKV[70.00]",
+		"(;B[gg])(;B[cg]))",
+	} {
+		if !strings.Contains(exported, want) {
+			t.Errorf("Expected %q in exported SGF:\n%s", want, exported)
+		}
+	}
+	if strings.Count(exported, "LZ[") != 2 {
+		t.Errorf("Expected only analyzed positions to carry LZ:\n%s", exported)
+	}
+
+	original, _ := NewSGFParser(sgf).Parse()
+	parsed, err := NewSGFParser(exported).Parse()
+	if err != nil || len(parsed.Moves) != len(original.Moves) {
+		t.Fatalf("Expected the exported game to keep its moves, got %+v, %v", parsed, err)
+	}
+}
@@ -2,8 +2,14 @@ package katago
 
 import (
 	"context"
-	"fmt"
+	"math"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
 )
 
 // MockEngine is a mock implementation of EngineInterface for testing.
@@ -13,11 +19,19 @@ type MockEngine struct {
 	pingErr        error
 	analyzeResp    *AnalysisResult
 	analyzeErr     error
+	lastAnalyzeReq *AnalysisRequest
 	startErr       error
 	stopErr        error
 	pingCallCount  int
 	startCallCount int
 	stopCallCount  int
+	pid            int
+	load           EngineLoad
+	capabilities   Capabilities
+	startup        StartupProgress
+	recording      []RecordedLine
+	slowQueries    []SlowQuery
+	diagnostics    *DiagnosticsBundle
 }
 
 // NewMockEngine creates a new mock engine.
@@ -61,6 +75,13 @@ func (m *MockEngine) GetPingCallCount() int {
 	return m.pingCallCount
 }
 
+// GetLastAnalyzeRequest returns the most recent request passed to Analyze.
+func (m *MockEngine) GetLastAnalyzeRequest() *AnalysisRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastAnalyzeReq
+}
+
 // Start implements EngineInterface.
 func (m *MockEngine) Start(ctx context.Context) error {
 	m.mu.Lock()
@@ -85,6 +106,12 @@ func (m *MockEngine) Stop() error {
 	return nil
 }
 
+// Drain implements EngineInterface. The mock has no outstanding queries, so
+// it stops at once.
+func (m *MockEngine) Drain(ctx context.Context) error {
+	return m.Stop()
+}
+
 // IsRunning implements EngineInterface.
 func (m *MockEngine) IsRunning() bool {
 	m.mu.Lock()
@@ -92,13 +119,122 @@ func (m *MockEngine) IsRunning() bool {
 	return m.running
 }
 
+// SetProcessID sets the process ID reported while the mock engine is running.
+func (m *MockEngine) SetProcessID(pid int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pid = pid
+}
+
+// ProcessID implements EngineInterface.
+func (m *MockEngine) ProcessID() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return 0
+	}
+	return m.pid
+}
+
+// SetLoad sets the load reported by the mock engine.
+func (m *MockEngine) SetLoad(load EngineLoad) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.load = load
+}
+
+// Load implements EngineInterface.
+func (m *MockEngine) Load() EngineLoad {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.load
+}
+
+// SetCapabilities sets the capabilities reported by the mock engine.
+func (m *MockEngine) SetCapabilities(caps Capabilities) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.capabilities = caps
+}
+
+// Capabilities implements EngineInterface.
+func (m *MockEngine) Capabilities() Capabilities {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.capabilities
+}
+
+// SetStartupProgress sets the startup progress reported by the mock engine.
+func (m *MockEngine) SetStartupProgress(progress StartupProgress) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.startup = progress
+}
+
+// StartupProgress implements EngineInterface.
+func (m *MockEngine) StartupProgress() StartupProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.startup
+}
+
+// SetRecording sets the lines returned by Recording.
+func (m *MockEngine) SetRecording(lines []RecordedLine) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recording = lines
+}
+
+// Recording implements EngineInterface.
+func (m *MockEngine) Recording() []RecordedLine {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.recording
+}
+
+// SetSlowQueries sets the queries returned by SlowQueries.
+func (m *MockEngine) SetSlowQueries(queries []SlowQuery) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.slowQueries = queries
+}
+
+// SlowQueries implements EngineInterface.
+func (m *MockEngine) SlowQueries() []SlowQuery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.slowQueries
+}
+
+// CollectDiagnostics implements EngineInterface with a bundle holding only
+// the reason and the recording.
+func (m *MockEngine) CollectDiagnostics(reason string) *DiagnosticsBundle {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.diagnostics = &DiagnosticsBundle{
+		Time:    time.Now().UTC(),
+		Reason:  reason,
+		Engine:  config.DefaultEngineName,
+		Startup: m.startup,
+		Queries: m.recording,
+	}
+	return m.diagnostics
+}
+
+// Diagnostics implements EngineInterface.
+func (m *MockEngine) Diagnostics() *DiagnosticsBundle {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.diagnostics
+}
+
 // Ping implements EngineInterface.
 func (m *MockEngine) Ping(ctx context.Context) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.pingCallCount++
 	if !m.running {
-		return fmt.Errorf("engine not running")
+		return apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
 	}
 	return m.pingErr
 }
@@ -107,8 +243,12 @@ func (m *MockEngine) Ping(ctx context.Context) error {
 func (m *MockEngine) Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.lastAnalyzeReq = req
 	if !m.running {
-		return nil, fmt.Errorf("engine not running")
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	if m.analyzeResp != nil && m.analyzeErr == nil {
+		UsageFromContext(ctx).recordQuery(&Response{RootInfo: m.analyzeResp.RootInfo}, 0, 0, 0)
 	}
 	return m.analyzeResp, m.analyzeErr
 }
@@ -118,7 +258,7 @@ func (m *MockEngine) AnalyzeSGF(ctx context.Context, sgf string, moveNum int) (*
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if !m.running {
-		return nil, fmt.Errorf("engine not running")
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
 	}
 	return m.analyzeResp, m.analyzeErr
 }
@@ -128,7 +268,7 @@ func (m *MockEngine) ReviewGame(ctx context.Context, sgf string, thresholds *Mis
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if !m.running {
-		return nil, fmt.Errorf("engine not running")
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
 	}
 	// Return a simple review
 	return &GameReview{
@@ -137,16 +277,39 @@ func (m *MockEngine) ReviewGame(ctx context.Context, sgf string, thresholds *Mis
 			BlackAccuracy: 90.0,
 			WhiteAccuracy: 85.0,
 		},
-		Mistakes: []Mistake{},
+		Mistakes:  []Mistake{},
+		GoodMoves: []GoodMove{},
 	}, nil
 }
 
+// KeyMoments implements EngineInterface.
+func (m *MockEngine) KeyMoments(ctx context.Context, sgf string, opts *KeyMomentOptions) (*KeyMoments, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	// An even game until Black's fifth move hands White a 30-point lead.
+	// KataGo's best move is always Q16, with a 2% prior.
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		winrate, lead := 0.5, 0.5
+		if len(req.Position.Moves) >= 9 {
+			winrate, lead = 0.1, -30
+		}
+		return &AnalysisResult{
+			RootInfo:  RootInfo{Visits: *req.MaxVisits, Winrate: winrate, ScoreLead: lead},
+			MoveInfos: []MoveInfo{{Move: "Q16", Prior: 0.02}},
+		}, nil
+	}
+	return keyMoments(ctx, analyze, sgf, opts, func(int, error) {})
+}
+
 // EstimateTerritory implements EngineInterface.
 func (m *MockEngine) EstimateTerritory(ctx context.Context, position *Position, threshold float64) (*TerritoryEstimate, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if !m.running {
-		return nil, fmt.Errorf("engine not running")
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
 	}
 	// Return a simple estimate
 	return &TerritoryEstimate{
@@ -158,19 +321,302 @@ func (m *MockEngine) EstimateTerritory(ctx context.Context, position *Position,
 	}, nil
 }
 
+// TerritoryTimeline implements EngineInterface.
+func (m *MockEngine) TerritoryTimeline(ctx context.Context, sgf string, opts *TimelineOptions) (*TerritoryTimeline, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	// Black owns a point for each move played and White half as many, until
+	// White takes 20 more points from move 30 on
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		size := req.Position.BoardXSize * req.Position.BoardYSize
+		moves := len(req.Position.Moves)
+		white := moves / 2
+		if moves >= 30 {
+			white += 20
+		}
+		ownership := make([]float64, size)
+		for i := 0; i < min(moves, size); i++ {
+			ownership[i] = 1
+		}
+		for i := 0; i < min(white, size-moves); i++ {
+			ownership[size-1-i] = -1
+		}
+		return &AnalysisResult{RootInfo: RootInfo{Visits: *req.MaxVisits, ScoreLead: float64(moves - white)}, Ownership: ownership}, nil
+	}
+	return territoryTimeline(ctx, analyze, sgf, opts, func(int, error) {})
+}
+
 // ExplainMove implements EngineInterface.
-func (m *MockEngine) ExplainMove(ctx context.Context, position *Position, move string) (*MoveExplanation, error) {
+func (m *MockEngine) ExplainMove(ctx context.Context, position *Position, move string, opts *ExplainOptions) (*MoveExplanation, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if !m.running {
-		return nil, fmt.Errorf("engine not running")
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
 	}
 	// Return a simple explanation
-	return &MoveExplanation{
+	explanation := &MoveExplanation{
 		Move:        move,
 		Explanation: "This is a good move",
 		Winrate:     0.55,
 		ScoreLead:   0.5,
 		Visits:      100,
+	}
+	if board, err := BoardFromPosition(position); err == nil {
+		explanation.Terms = DescribeTerms(i18n.FromContext(ctx), ClassifyMove(board, nextPlayer(position), move))
+	}
+	return explanation, nil
+}
+
+// SuggestHumanMove implements EngineInterface.
+func (m *MockEngine) SuggestHumanMove(ctx context.Context, position *Position, humanProfile string) (*HumanMoveSuggestion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	if humanProfile == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "humanProfile is required when no default is configured")
+	}
+	if err := ValidateHumanProfile(humanProfile); err != nil {
+		return nil, err
+	}
+	// Return a simple suggestion
+	return &HumanMoveSuggestion{
+		HumanProfile:  humanProfile,
+		CurrentPlayer: "B",
+		Moves: []HumanMove{
+			{Move: "D4", Probability: 0.4, Analyzed: true, Winrate: 0.5, ScoreLead: 0.5, PointLoss: 0.3},
+			{Move: "C3", Probability: 0.2},
+		},
+		BestMove:      "Q16",
+		BestWinrate:   0.52,
+		BestScoreLead: 0.8,
+	}, nil
+}
+
+// EstimateRank implements EngineInterface.
+func (m *MockEngine) EstimateRank(ctx context.Context, sgfs []string, player string, opts *RankOptions) (*RankEstimate, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	games, colors, err := parseRankGames(sgfs, player)
+	if err != nil {
+		return nil, err
+	}
+	// Return a simple estimate
+	estimate := &RankEstimate{
+		Player:        player,
+		Games:         len(games),
+		MovesAnalyzed: 10 * len(games),
+		AvgPointLoss:  1.5,
+		MedianLoss:    0.8,
+		StdDevLoss:    2.0,
+		AccuracyRate:  0.6,
+		BlunderRate:   0.1,
+		Rank:          "5k",
+		RankLow:       "8k",
+		RankHigh:      "3k",
+		Evidence: []RankEvidence{
+			{Game: 1, MoveNumber: 31, Color: colors[0], PlayedMove: "C3", BestMove: "R4", PointLoss: 12.5},
+		},
+	}
+	for i := range games {
+		estimate.PerGame = append(estimate.PerGame, GameRankStats{
+			Game: i + 1, Color: colors[i], MovesAnalyzed: 10, AvgPointLoss: 1.5, Rank: "5k",
+		})
+	}
+	return estimate, nil
+}
+
+// ExpandVariation implements EngineInterface.
+func (m *MockEngine) ExpandVariation(ctx context.Context, position *Position, move string, opts *VariationOptions) (*VariationTree, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	if _, _, _, err := variationLimits(opts); err != nil {
+		return nil, err
+	}
+	// Return a simple two-ply variation that deviates from the original PV
+	color := nextPlayer(position)
+	moveNumber := len(position.Moves) + 1
+	return &VariationTree{
+		Root: &VariationNode{
+			Move: move, Color: color, MoveNumber: moveNumber, Winrate: 0.55, ScoreLead: 2.0,
+			Visits: 100, PV: []string{"Q16", "D16"}, InOriginal: true, Analyzed: true,
+			Children: []*VariationNode{{
+				Move: "Q16", Color: opponent(color), MoveNumber: moveNumber + 1, Winrate: 0.54, ScoreLead: 1.8,
+				Visits: 100, PV: []string{"D16"}, Analyzed: true,
+			}},
+		},
+		OriginalPV: []string{move, "R4"},
+		Depth:      1,
+		Nodes:      3,
 	}, nil
 }
+
+// SweepKomi implements EngineInterface.
+func (m *MockEngine) SweepKomi(ctx context.Context, position *Position, opts *KomiSweepOptions) (*KomiSweep, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	// Evaluate a position whose fair komi is 6.5, losing 4% per point
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		lead := 6.5 - *req.Komi
+		return &AnalysisResult{RootInfo: RootInfo{
+			Visits:        *req.MaxVisits,
+			Winrate:       math.Max(0, math.Min(1, 0.5+0.04*lead)),
+			ScoreLead:     lead,
+			CurrentPlayer: nextPlayer(req.Position),
+		}}, nil
+	}
+	return sweepKomi(ctx, analyze, position, opts, func(float64, error) {})
+}
+
+// WhatIf implements EngineInterface.
+func (m *MockEngine) WhatIf(ctx context.Context, position *Position, moves []string, opts *WhatIfOptions) (*WhatIfResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	// Evaluate positions as 2.5 points to Black, less a point for each move
+	// on the board, with Q16 then D4 as the best line
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		lead := 2.5 - float64(len(req.Position.Moves))
+		return &AnalysisResult{
+			RootInfo: RootInfo{
+				Visits:        *req.MaxVisits,
+				Winrate:       math.Max(0, math.Min(1, 0.5+0.05*lead)),
+				ScoreLead:     lead,
+				CurrentPlayer: nextPlayer(req.Position),
+			},
+			MoveInfos: []MoveInfo{{Move: "Q16", Visits: *req.MaxVisits, PV: []string{"Q16", "D4"}}},
+		}, nil
+	}
+	return whatIf(ctx, analyze, position, moves, opts)
+}
+
+// SolveTsumego implements EngineInterface.
+func (m *MockEngine) SolveTsumego(ctx context.Context, position *Position, opts *TsumegoOptions) (*TsumegoSolution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	// The first empty point of the region wins everything for the player
+	// to move; the second loses everything
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		board, err := BoardFromPosition(req.Position)
+		if err != nil {
+			return nil, err
+		}
+		avoided := make(map[string]bool)
+		for _, move := range req.AvoidMoves {
+			avoided[move] = true
+		}
+		var points []string
+		for _, move := range rectangleMoves(0, 0, board.xSize-1, board.ySize-1, board.ySize) {
+			if !avoided[move] && board.Stone(move) == "" {
+				points = append(points, move)
+			}
+		}
+		if len(points) < 2 {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "region has fewer than two empty points")
+		}
+		win := make([]float64, board.xSize*board.ySize)
+		lose := make([]float64, len(win))
+		sign := 1.0
+		if nextPlayer(req.Position) == "W" {
+			sign = -1
+		}
+		for i := range win {
+			win[i], lose[i] = sign, -sign
+		}
+		visits := *req.MaxVisits
+		return &AnalysisResult{
+			RootInfo:  RootInfo{Visits: visits, CurrentPlayer: nextPlayer(req.Position)},
+			Ownership: win,
+			MoveInfos: []MoveInfo{
+				{Move: points[0], Visits: visits * 3 / 4, PV: []string{points[0], points[1]}, Ownership: win},
+				{Move: points[1], Visits: visits / 4, PV: []string{points[1], points[0]}, Ownership: lose},
+			},
+		}, nil
+	}
+	return solveTsumego(ctx, analyze, position, opts)
+}
+
+// GenerateProblems implements EngineInterface.
+func (m *MockEngine) GenerateProblems(ctx context.Context, sgfs []string, player string, opts *ProblemOptions) (*ProblemSet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	// Each player blunders with their last move, when an empty 4-4 point
+	// was clearly best
+	review := func(ctx context.Context, sgf string, thresholds *MistakeThresholds) (*GameReview, error) {
+		game, err := NewSGFParser(sgf).Parse()
+		if err != nil {
+			return nil, err
+		}
+		review := &GameReview{Summary: ReviewSummary{TotalMoves: len(game.Moves)}, Mistakes: []Mistake{}}
+		for i := len(game.Moves) - 1; i >= 0 && i >= len(game.Moves)-2; i-- {
+			move := game.Moves[i]
+			review.Mistakes = append(review.Mistakes, Mistake{
+				MoveNumber:  i + 1,
+				Color:       strings.ToUpper(move.Color),
+				PlayedMove:  move.Location,
+				WinrateDrop: 0.25,
+				Category:    "blunder",
+			})
+		}
+		AttributeReview(review, game.GameInfo)
+		return review, nil
+	}
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		board, err := BoardFromPosition(req.Position)
+		if err != nil {
+			return nil, err
+		}
+		toPlay := nextPlayer(req.Position)
+		result := &AnalysisResult{RootInfo: RootInfo{Visits: *req.MaxVisits}}
+		for _, move := range []string{"D4", "Q16", "Q4", "D16"} {
+			if board.IsLegal(toPlay, move) {
+				result.MoveInfos = append(result.MoveInfos,
+					MoveInfo{Move: move, Winrate: 0.6 - 0.2*float64(len(result.MoveInfos)), PV: []string{move}})
+			}
+		}
+		return result, nil
+	}
+	return generateProblems(ctx, review, analyze, sgfs, player, opts)
+}
+
+// OpeningBook implements EngineInterface.
+func (m *MockEngine) OpeningBook(ctx context.Context, sgfs []string, player string, opts *OpeningOptions) (*OpeningBook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running {
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	// Q16 and D4 are always best, and any other move loses 3 points
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		return &AnalysisResult{
+			RootInfo: RootInfo{Visits: *req.MaxVisits, ScoreLead: 0.5},
+			MoveInfos: []MoveInfo{
+				{Move: "Q16", ScoreLead: 0.5, Order: 0},
+				{Move: "D4", ScoreLead: 0.5, Order: 1},
+				{Move: "K10", ScoreLead: -2.5, Order: 2},
+			},
+		}, nil
+	}
+	return openingBook(ctx, analyze, sgfs, player, opts)
+}
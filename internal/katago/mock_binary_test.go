@@ -0,0 +1,401 @@
+package katago
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// buildMockKataGo builds cmd/katago-mock into a temporary directory.
+func buildMockKataGo(t *testing.T) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping katago-mock build in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not available to build katago-mock")
+	}
+	binary := filepath.Join(t.TempDir(), "katago-mock")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	cmd := exec.Command(goTool, "build", "-o", binary, "github.com/dmmcquay/katago-mcp/cmd/katago-mock") // #nosec G204 -- fixed arguments
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build katago-mock: %v\n%s", err, output)
+	}
+	return binary
+}
+
+// TestEngineWithMockBinary runs the real engine against katago-mock, so
+// process handling and the JSON protocol are exercised without a model.
+func TestEngineWithMockBinary(t *testing.T) {
+	cfg := &config.KataGoConfig{
+		BinaryPath: buildMockKataGo(t),
+		MaxVisits:  50,
+		MaxTime:    5,
+	}
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := NewEngine(cfg, logger, nil)
+	ctx := context.Background()
+
+	if err := engine.Start(ctx); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer func() { _ = engine.Stop() }()
+
+	if err := engine.Ping(ctx); err != nil {
+		t.Errorf("Failed to ping engine: %v", err)
+	}
+
+	position := &Position{
+		Rules:      "chinese",
+		BoardXSize: 9,
+		BoardYSize: 9,
+		Komi:       7,
+		Moves:      []Move{{Color: "b", Location: "E5"}, {Color: "w", Location: "C3"}},
+	}
+	first, err := engine.Analyze(ctx, &AnalysisRequest{Position: position, IncludeOwnership: true})
+	if err != nil {
+		t.Fatalf("Failed to analyze position: %v", err)
+	}
+	if len(first.MoveInfos) == 0 || first.RootInfo.CurrentPlayer != "B" {
+		t.Errorf("Unexpected analysis: %+v", first.RootInfo)
+	}
+	if len(first.Ownership) != 81 {
+		t.Errorf("Expected 81 ownership values, got %d", len(first.Ownership))
+	}
+	if progress := engine.StartupProgress(); !progress.Ready() {
+		t.Errorf("Expected startup to be complete after analysis, got %+v", progress)
+	}
+
+	// Once started, a ping is answered by an analysis query
+	if err := engine.Ping(ctx); err != nil {
+		t.Errorf("Failed to ping started engine: %v", err)
+	}
+	lines := engine.recentQueries.Lines()
+	if last := string(lines[len(lines)-1].Data); !strings.Contains(last, `"id":"health`) || !strings.Contains(last, `"maxVisits":1`) {
+		t.Errorf("Expected a health check query, got %s", last)
+	}
+
+	// The mock is deterministic, so a repeated query gives the same answer
+	second, err := engine.Analyze(ctx, &AnalysisRequest{Position: position})
+	if err != nil {
+		t.Fatalf("Failed to analyze position again: %v", err)
+	}
+	if second.MoveInfos[0].Move != first.MoveInfos[0].Move || second.RootInfo.Winrate != first.RootInfo.Winrate {
+		t.Errorf("Expected identical results, got %s/%v and %s/%v",
+			first.MoveInfos[0].Move, first.RootInfo.Winrate, second.MoveInfos[0].Move, second.RootInfo.Winrate)
+	}
+
+	review, err := engine.ReviewGame(ctx, "(;GM[1]SZ[9]KM[7];B[ee];W[cc];B[gg];W[gc])", nil)
+	if err != nil {
+		t.Fatalf("Failed to review game: %v", err)
+	}
+	if review.Summary.TotalMoves != 4 {
+		t.Errorf("Expected 4 reviewed moves, got %d", review.Summary.TotalMoves)
+	}
+	if len(review.Winrates) != 4 || review.Winrates[3].MoveNumber != 3 {
+		t.Errorf("Expected a winrate point before each move, got %+v", review.Winrates)
+	}
+	if review.Summary.BlackAveragePolicy <= 0 || review.Summary.WhiteAveragePolicy <= 0 {
+		t.Errorf("Expected the policy of played moves, got %+v", review.Summary)
+	}
+
+	// Analyzing positions concurrently gives the same review in move order,
+	// reporting progress as positions finish
+	thresholds := DefaultMistakeThresholds()
+	thresholds.Concurrency = 3
+	var reports []string
+	progressCtx := WithProgress(ctx, func(progress, total float64, message string) {
+		reports = append(reports, fmt.Sprintf("%g/%g %s", progress, total, message))
+	})
+	concurrent, err := engine.ReviewGame(progressCtx, "(;GM[1]SZ[9]KM[7];B[ee];W[cc];B[gg];W[gc])", thresholds)
+	if err != nil {
+		t.Fatalf("Failed to review game concurrently: %v", err)
+	}
+	if !reflect.DeepEqual(concurrent.Winrates, review.Winrates) || concurrent.Summary != review.Summary {
+		t.Errorf("Expected the sequential review, got %+v", concurrent)
+	}
+	want := fmt.Sprintf("4/4 Analyzed 4 of 4 positions, %d mistakes found", len(review.Mistakes))
+	if len(reports) != 4 || !strings.HasPrefix(reports[0], "1/4 ") || reports[3] != want {
+		t.Errorf("Expected progress after each position ending in %q, got %q", want, reports)
+	}
+
+	// Positions repeated by a triple ko are analyzed once
+	reports = nil
+	ko, err := engine.ReviewGame(progressCtx, tripleKoSGF, thresholds)
+	if err != nil {
+		t.Fatalf("Failed to review triple ko: %v", err)
+	}
+	if len(ko.Repetitions) != 7 || len(ko.Winrates) != 12 || ko.Winrates[6].Winrate != ko.Winrates[0].Winrate {
+		t.Errorf("Expected the repeated positions annotated and evaluated alike, got %+v and %+v", ko.Repetitions, ko.Winrates)
+	}
+	if len(reports) != 6 || !strings.HasPrefix(reports[5], "12/12 ") {
+		t.Errorf("Expected progress after each of 6 analyzed positions, got %q", reports)
+	}
+
+	explanation, err := engine.ExplainMove(ctx, position, first.MoveInfos[0].Move, nil)
+	if err != nil {
+		t.Fatalf("Failed to explain move: %v", err)
+	}
+	if explanation.Ownership == nil || explanation.Ownership.ScoreChange <= 0 {
+		t.Errorf("Expected the move to gain ownership, got %+v", explanation.Ownership)
+	}
+	if len(explanation.Replies) != maxReplies || explanation.Replies[0].Move == first.MoveInfos[0].Move {
+		t.Errorf("Expected %d replies to the move, got %+v", maxReplies, explanation.Replies)
+	}
+
+	if _, err := engine.Analyze(ctx, &AnalysisRequest{Position: &Position{
+		BoardXSize: 9,
+		BoardYSize: 9,
+		Moves:      []Move{{Color: "b", Location: "E5"}, {Color: "w", Location: "E5"}},
+	}}); err == nil {
+		t.Error("Expected an error for an illegal move")
+	}
+
+	if load := engine.Load(); load.IdleSeconds <= 0 {
+		t.Errorf("Expected the idle time of an engine without queries, got %+v", load)
+	}
+
+	// A stopped engine starts again, and outlives the request that started it
+	if err := engine.Stop(); err != nil {
+		t.Fatalf("Failed to stop engine: %v", err)
+	}
+	requestCtx, cancel := context.WithCancel(ctx)
+	if err := engine.Start(requestCtx); err != nil {
+		t.Fatalf("Failed to restart engine: %v", err)
+	}
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+	if _, err := engine.Analyze(ctx, &AnalysisRequest{Position: position}); err != nil {
+		t.Errorf("Failed to analyze after a restart: %v", err)
+	}
+}
+
+// TestEngineSetupOnlyProblem runs a problem's setup, with no moves,
+// through the real engine against katago-mock.
+func TestEngineSetupOnlyProblem(t *testing.T) {
+	cfg := &config.KataGoConfig{
+		BinaryPath: buildMockKataGo(t),
+		MaxVisits:  50,
+		MaxTime:    5,
+	}
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := NewEngine(cfg, logger, nil)
+	ctx := context.Background()
+	if err := engine.Start(ctx); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer func() { _ = engine.Stop() }()
+
+	position, err := NewSGFParser(cornerProblem).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse problem: %v", err)
+	}
+	result, err := engine.Analyze(ctx, &AnalysisRequest{Position: position, IncludeOwnership: true})
+	if err != nil {
+		t.Fatalf("Failed to analyze problem: %v", err)
+	}
+	if result.RootInfo.CurrentPlayer != "W" || len(result.MoveInfos) == 0 || len(result.Ownership) != 361 {
+		t.Fatalf("Expected White to play the problem, got %+v", result.RootInfo)
+	}
+
+	explanation, err := engine.ExplainMove(ctx, position, result.MoveInfos[0].Move, nil)
+	if err != nil {
+		t.Fatalf("Failed to explain move: %v", err)
+	}
+	if len(explanation.Replies) == 0 || explanation.Replies[0].Move == result.MoveInfos[0].Move {
+		t.Errorf("Expected Black's replies to White's move, got %+v", explanation.Replies)
+	}
+
+	if _, err := engine.ReviewGame(ctx, cornerProblem, nil); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected a problem not to be reviewed, got %v", err)
+	}
+}
+
+// TestEngineProtocolVersions is a contract test of version negotiation
+// against katago-mock emulating several KataGo releases.
+func TestEngineProtocolVersions(t *testing.T) {
+	binary := buildMockKataGo(t)
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	position := &Position{
+		Rules:      "chinese",
+		BoardXSize: 9,
+		BoardYSize: 9,
+		Komi:       7,
+		Moves:      []Move{{Color: "b", Location: "E5"}},
+	}
+
+	for _, version := range []string{"1.3.5", "1.12.4", "1.15.3"} {
+		t.Run(version, func(t *testing.T) {
+			t.Setenv("KATAGO_MOCK_VERSION", version)
+			engine := NewEngine(&config.KataGoConfig{BinaryPath: binary, MaxTime: 5}, logger, nil)
+			ctx := context.Background()
+			if err := engine.Start(ctx); err != nil {
+				t.Fatalf("Failed to start engine: %v", err)
+			}
+			defer func() { _ = engine.Stop() }()
+
+			deadline := time.Now().Add(5 * time.Second)
+			for !engine.Capabilities().Known() && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			caps := engine.Capabilities()
+			if caps.Version != version {
+				t.Fatalf("Expected negotiated version %s, got %+v", version, caps)
+			}
+			expected, _ := CapabilitiesFor(version)
+			if len(caps.Unsupported) != len(expected.Unsupported) {
+				t.Errorf("Expected unsupported %v, got %v", expected.Unsupported, caps.Unsupported)
+			}
+
+			result, err := engine.Analyze(ctx, &AnalysisRequest{
+				Position:              position,
+				IncludePVVisits:       true,
+				IncludeMovesOwnership: true,
+			})
+			if err != nil {
+				t.Fatalf("Failed to analyze position: %v", err)
+			}
+			root := result.RootInfo
+			if root.ScoreLead == 0 || root.ScoreLead != root.ScoreMean {
+				t.Errorf("Expected scoreLead on every version, got lead %v mean %v", root.ScoreLead, root.ScoreMean)
+			}
+			if hasMovesOwnership := len(result.MoveInfos[0].Ownership) == 81; hasMovesOwnership != caps.Supports(FeatureMovesOwnership) {
+				t.Errorf("Expected per-move ownership only when supported, got %d values", len(result.MoveInfos[0].Ownership))
+			}
+			if best := result.MoveInfos[0]; (len(best.PVVisits) == len(best.PV)) != caps.Supports(FeaturePVVisits) {
+				t.Errorf("Expected PV visits only when supported, got %v for %v", best.PVVisits, best.PV)
+			}
+			if hasRawErrors := root.RawStWrError != 0; hasRawErrors != caps.Supports(FeatureRawErrors) {
+				t.Errorf("Expected raw errors only when supported, got %v", root.RawStWrError)
+			}
+
+			_, err = engine.Analyze(ctx, &AnalysisRequest{Position: position, HumanProfile: "rank_5k"})
+			if caps.Supports(FeatureHumanSL) {
+				if err != nil {
+					t.Errorf("Expected human SL analysis to succeed, got %v", err)
+				}
+			} else if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+				t.Errorf("Expected human SL to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+// TestEngineVersionAfterSlowStart checks that the version is negotiated
+// with a KataGo that reads no queries while its model loads.
+func TestEngineVersionAfterSlowStart(t *testing.T) {
+	binary := buildMockKataGo(t)
+	t.Setenv("KATAGO_MOCK_VERSION", "1.15.3")
+	t.Setenv("KATAGO_MOCK_STARTUP_DELAY", "1s")
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := NewEngine(&config.KataGoConfig{BinaryPath: binary, MaxTime: 5}, logger, nil)
+	if err := engine.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer func() { _ = engine.Stop() }()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !engine.Capabilities().Known() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if caps := engine.Capabilities(); caps.Version != "1.15.3" {
+		t.Fatalf("Expected negotiated version 1.15.3, got %+v", caps)
+	}
+
+	// The version is asked for only once the model has loaded
+	progress := engine.StartupProgress()
+	readyAt := progress.StartedAt.Add(time.Duration(progress.ElapsedSeconds * float64(time.Second)))
+	for _, line := range engine.recentQueries.Lines() {
+		if strings.Contains(string(line.Data), "query_version") && line.Time.Before(readyAt) {
+			t.Errorf("Expected query_version after startup was ready at %v, sent at %v", readyAt, line.Time)
+		}
+	}
+}
+
+// TestEngineRecordingReplay records a session against katago-mock and
+// replays it with a doctored response standing in for a KataGo bug.
+func TestEngineRecordingReplay(t *testing.T) {
+	binary := buildMockKataGo(t)
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	position := &Position{
+		Rules:      "chinese",
+		BoardXSize: 9,
+		BoardYSize: 9,
+		Komi:       7,
+		Moves:      []Move{{Color: "b", Location: "E5"}},
+	}
+	analyze := func(cfg *config.KataGoConfig) (*Engine, *AnalysisResult) {
+		t.Helper()
+		engine := NewEngine(cfg, logger, nil)
+		ctx := context.Background()
+		if err := engine.Start(ctx); err != nil {
+			t.Fatalf("Failed to start engine: %v", err)
+		}
+		defer func() { _ = engine.Stop() }()
+		result, err := engine.Analyze(ctx, &AnalysisRequest{Position: position})
+		if err != nil {
+			t.Fatalf("Failed to analyze position: %v", err)
+		}
+		return engine, result
+	}
+
+	path := filepath.Join(t.TempDir(), "recording.jsonl")
+	engine, _ := analyze(&config.KataGoConfig{BinaryPath: binary, MaxVisits: 50, MaxTime: 5,
+		Recorder: config.RecorderConfig{Enabled: true, Path: path}})
+	if lines := engine.Recording(); len(lines) < 2 || lines[0].Direction != DirectionQuery {
+		t.Fatalf("Expected the session to be recorded, got %+v", lines)
+	}
+
+	// Change the recorded win rate, as if KataGo had reported it
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := ReadRecording(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read recording: %v", err)
+	}
+	doctored := false
+	for i, line := range lines {
+		var resp map[string]interface{}
+		if line.Direction != DirectionResponse || json.Unmarshal(line.Data, &resp) != nil || resp["rootInfo"] == nil {
+			continue
+		}
+		resp["rootInfo"].(map[string]interface{})["winrate"] = 0.123
+		lines[i].Data, _ = json.Marshal(resp)
+		doctored = true
+	}
+	if !doctored {
+		t.Fatal("Expected an analysis response in the recording")
+	}
+	replay := filepath.Join(t.TempDir(), "replay.jsonl")
+	var buf bytes.Buffer
+	if err := WriteRecording(&buf, lines); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(replay, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("KATAGO_MOCK_REPLAY", replay)
+	_, result := analyze(&config.KataGoConfig{BinaryPath: binary, MaxVisits: 50, MaxTime: 5})
+	if result.RootInfo.Winrate != 0.123 {
+		t.Errorf("Expected the recorded win rate 0.123, got %v", result.RootInfo.Winrate)
+	}
+}
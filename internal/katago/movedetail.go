@@ -0,0 +1,71 @@
+package katago
+
+import (
+	"fmt"
+	"strings"
+)
+
+// moveDetailCandidates is the number of KataGo's choices a move detail
+// lists.
+const moveDetailCandidates = 5
+
+// MoveDetail is one move of a game as a review judges it, with the
+// analysis behind the judgement.
+type MoveDetail struct {
+	MoveNumber  int     `json:"moveNumber"`
+	Color       string  `json:"color"`
+	PlayedMove  string  `json:"playedMove"`         // Empty for a pass
+	Category    string  `json:"category,omitempty"` // "blunder", "mistake", "inaccuracy", or empty for a good move
+	WinrateDrop float64 `json:"winrateDrop"`
+	// Rank is the played move's place among KataGo's choices, from 1, or
+	// 0 if KataGo did not search it; Prior is its policy prior
+	Rank  int     `json:"rank,omitempty"`
+	Prior float64 `json:"prior"`
+	// Before is KataGo's evaluation of the position before the move
+	Before RootInfo `json:"before"`
+	// Played is KataGo's analysis of the played move, nil if it was not
+	// searched
+	Played     *MoveInfo  `json:"played,omitempty"`
+	Candidates []MoveInfo `json:"candidates"` // KataGo's top choices, best first
+}
+
+// DescribeMove judges move n of a game, counted from 1, as ReviewGame
+// would from result, the analysis of ReviewRequest for the move.
+func DescribeMove(game *Position, n int, result *AnalysisResult, thresholds *MistakeThresholds) (*MoveDetail, error) {
+	if n < 1 || n > len(game.Moves) {
+		return nil, fmt.Errorf("move %d is not in the game, which has %d moves", n, len(game.Moves))
+	}
+	if len(result.MoveInfos) == 0 {
+		return nil, fmt.Errorf("KataGo found no moves before move %d", n)
+	}
+
+	move := game.Moves[n-1]
+	detail := &MoveDetail{
+		MoveNumber: n,
+		Color:      strings.ToUpper(move.Color),
+		PlayedMove: move.Location,
+		Before:     result.RootInfo,
+	}
+	rank, prior := playedRank(result, move.Location, game.BoardXSize, game.BoardYSize)
+	detail.Rank, detail.Prior = rank+1, prior
+	if rank >= 0 {
+		played := result.MoveInfos[rank]
+		detail.Played = &played
+	}
+
+	detail.WinrateDrop, _ = moveWinrateDrop(result, move.Location)
+	switch {
+	case detail.WinrateDrop >= thresholds.Blunder:
+		detail.Category = "blunder"
+	case detail.WinrateDrop >= thresholds.Mistake:
+		detail.Category = "mistake"
+	case detail.WinrateDrop >= thresholds.Inaccuracy:
+		detail.Category = "inaccuracy"
+	}
+
+	detail.Candidates = result.MoveInfos
+	if len(detail.Candidates) > moveDetailCandidates {
+		detail.Candidates = detail.Candidates[:moveDetailCandidates]
+	}
+	return detail, nil
+}
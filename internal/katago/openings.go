@@ -0,0 +1,322 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+const (
+	defaultOpeningDepth    = 12
+	defaultOpeningMinGames = 2
+	defaultOpeningVisits   = 200
+	defaultOpeningMinLoss  = 2.0
+	// maxOpeningDepth bounds the moves of each game put in the tree.
+	maxOpeningDepth = 40
+	// maxLosingLines is the number of costliest habitual lines reported.
+	maxLosingLines = 10
+)
+
+// OpeningOptions controls how an opening book is built.
+type OpeningOptions struct {
+	Depth int // Moves from the start of each game put in the tree (default: 12, max: 40)
+	// MinGames is the number of games in which the player must have made
+	// a move for it to be habitual, and evaluated (default: 2)
+	MinGames  int
+	MaxVisits int     // Visits per evaluated position (default: 200)
+	MinLoss   float64 // Points a habitual move must lose to be reported (default: 2)
+}
+
+// OpeningNode is a move of an opening tree, with the games that reached it.
+type OpeningNode struct {
+	MoveNumber int    `json:"moveNumber"`
+	Color      string `json:"color"`
+	Move       string `json:"move"`
+	Games      int    `json:"games"`
+	Wins       int    `json:"wins"`   // Games the player won
+	Losses     int    `json:"losses"` // Games the player lost
+	// KataGo's evaluation of the player's habitual moves: the points the
+	// move loses against KataGo's best move
+	Evaluated bool           `json:"evaluated,omitempty"`
+	PointLoss float64        `json:"pointLoss,omitempty"`
+	BestMove  string         `json:"bestMove,omitempty"`
+	Children  []*OpeningNode `json:"children,omitempty"`
+	first     *Position      // The first game to reach the move
+	index     map[string]*OpeningNode
+}
+
+// LosingLine is a habitual move of the player that loses points, with the
+// line leading to it.
+type LosingLine struct {
+	Color      string   `json:"color"` // The player's color in the line
+	Line       []string `json:"line"`  // Moves from the start, the losing move last
+	MoveNumber int      `json:"moveNumber"`
+	Move       string   `json:"move"`
+	BestMove   string   `json:"bestMove"`
+	PointLoss  float64  `json:"pointLoss"`
+	Games      int      `json:"games"`
+	Wins       int      `json:"wins"`
+	Losses     int      `json:"losses"`
+}
+
+// OpeningBook is a player's opening tree built from their games. Games are
+// rotated and mirrored so that lines which differ only in orientation are
+// merged.
+type OpeningBook struct {
+	Player    string         `json:"player"`
+	Games     int            `json:"games"`   // Games put in the tree
+	Skipped   int            `json:"skipped"` // Handicap games and games on other board sizes
+	BoardSize int            `json:"boardSize"`
+	Depth     int            `json:"depth"`
+	MinGames  int            `json:"minGames"`
+	AsBlack   []*OpeningNode `json:"asBlack"` // First moves of the games the player had Black
+	AsWhite   []*OpeningNode `json:"asWhite"`
+	// Positions counts the positions KataGo evaluated
+	Positions   int          `json:"positions"`
+	LosingLines []LosingLine `json:"losingLines"`
+	// Partial is set when the time limit was reached before every
+	// habitual move was evaluated.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// OpeningBook builds a player's opening tree from their games and has
+// KataGo evaluate the moves they play habitually. The player is matched
+// against each game's PB and PW properties.
+func (e *Engine) OpeningBook(ctx context.Context, sgfs []string, player string, opts *OpeningOptions) (*OpeningBook, error) {
+	return openingBook(ctx, e.Analyze, sgfs, player, opts)
+}
+
+// openingBook builds an opening book with the given analysis function.
+func openingBook(ctx context.Context, analyze func(context.Context, *AnalysisRequest) (*AnalysisResult, error),
+	sgfs []string, player string, opts *OpeningOptions) (*OpeningBook, error) {
+	games, colors, err := parseRankGames(sgfs, player)
+	if err != nil {
+		return nil, err
+	}
+	depth, minGames, visits, minLoss := defaultOpeningDepth, defaultOpeningMinGames, defaultOpeningVisits, defaultOpeningMinLoss
+	if opts != nil {
+		if opts.Depth > 0 {
+			depth = opts.Depth
+		}
+		if opts.MinGames > 0 {
+			minGames = opts.MinGames
+		}
+		if opts.MaxVisits > 0 {
+			visits = opts.MaxVisits
+		}
+		if opts.MinLoss > 0 {
+			minLoss = opts.MinLoss
+		}
+	}
+	if depth > maxOpeningDepth {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "depth must be at most %d", maxOpeningDepth)
+	}
+
+	book := &OpeningBook{Player: player, Depth: depth, MinGames: minGames, BoardSize: games[0].BoardXSize, LosingLines: []LosingLine{}}
+	roots := map[string]*OpeningNode{"B": {index: map[string]*OpeningNode{}}, "W": {index: map[string]*OpeningNode{}}}
+	for i, game := range games {
+		if len(game.InitialStones) > 0 || game.BoardXSize != book.BoardSize || game.BoardYSize != book.BoardSize {
+			book.Skipped++
+			continue
+		}
+		book.Games++
+		won, lost := false, false
+		if result, ok := ParseResult(game.Result); ok && result.Winner != "" {
+			won, lost = result.Winner == colors[i], result.Winner != colors[i]
+		}
+		node := roots[colors[i]]
+		for n, move := range canonicalOpening(game, depth) {
+			child, ok := node.index[move]
+			if !ok {
+				child = &OpeningNode{
+					MoveNumber: n + 1,
+					Color:      strings.ToUpper(game.Moves[n].Color),
+					Move:       move,
+					index:      map[string]*OpeningNode{},
+					first:      game,
+				}
+				node.index[move] = child
+				node.Children = append(node.Children, child)
+			}
+			child.Games++
+			if won {
+				child.Wins++
+			}
+			if lost {
+				child.Losses++
+			}
+			node = child
+		}
+	}
+	if book.Games == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "no even games on a %dx%d board to build a book from", book.BoardSize, book.BoardSize)
+	}
+	sortOpeningNodes(roots["B"])
+	sortOpeningNodes(roots["W"])
+	book.AsBlack, book.AsWhite = roots["B"].Children, roots["W"].Children
+
+	// Evaluate each position where the player habitually moved, once for
+	// all of their habitual moves there
+	type pending struct {
+		color string
+		line  []string
+		node  *OpeningNode
+	}
+	var queue []pending
+	var walk func(color string, line []string, node *OpeningNode)
+	walk = func(color string, line []string, node *OpeningNode) {
+		for _, child := range node.Children {
+			if child.Color == color && child.Games >= minGames {
+				queue = append(queue, pending{color, line, node})
+				break
+			}
+		}
+		for _, child := range node.Children {
+			if child.Games >= minGames {
+				walk(color, append(line[:len(line):len(line)], child.Move), child)
+			}
+		}
+	}
+	walk("B", nil, roots["B"])
+	walk("W", nil, roots["W"])
+
+	for i, p := range queue {
+		if ctx.Err() != nil {
+			book.Partial = true
+			break
+		}
+		first := p.node.Children[0].first
+		position := &Position{
+			Rules:      first.Rules,
+			BoardXSize: first.BoardXSize,
+			BoardYSize: first.BoardYSize,
+			Komi:       first.Komi,
+		}
+		for n, move := range p.line {
+			position.Moves = append(position.Moves, Move{Color: first.Moves[n].Color, Location: move})
+		}
+		positionVisits := visits
+		result, err := analyze(ctx, &AnalysisRequest{Position: position, MaxVisits: &positionVisits})
+		reportProgress(ctx, float64(i+1), float64(len(queue)),
+			fmt.Sprintf("Evaluated %d of %d opening positions", i+1, len(queue)))
+		if err != nil {
+			if ctx.Err() != nil {
+				book.Partial = true
+				break
+			}
+			return nil, fmt.Errorf("failed to evaluate opening position after %s: %w", strings.Join(p.line, " "), err)
+		}
+		book.Positions++
+
+		for _, child := range p.node.Children {
+			if child.Color != p.color || child.Games < minGames {
+				continue
+			}
+			loss, best, ok := pointLoss(result, child.Move)
+			if !ok {
+				continue
+			}
+			child.Evaluated, child.PointLoss, child.BestMove = true, roundTenth(loss), best
+			if loss >= minLoss && child.Move != best {
+				book.LosingLines = append(book.LosingLines, LosingLine{
+					Color:      p.color,
+					Line:       append(p.line[:len(p.line):len(p.line)], child.Move),
+					MoveNumber: child.MoveNumber,
+					Move:       child.Move,
+					BestMove:   best,
+					PointLoss:  child.PointLoss,
+					Games:      child.Games,
+					Wins:       child.Wins,
+					Losses:     child.Losses,
+				})
+			}
+		}
+	}
+
+	// The lines that cost the most points over all games come first
+	sort.SliceStable(book.LosingLines, func(i, j int) bool {
+		a, b := book.LosingLines[i], book.LosingLines[j]
+		return a.PointLoss*float64(a.Games) > b.PointLoss*float64(b.Games)
+	})
+	if len(book.LosingLines) > maxLosingLines {
+		book.LosingLines = book.LosingLines[:maxLosingLines]
+	}
+	return book, nil
+}
+
+// sortOpeningNodes orders each node's children by the games that reached
+// them, most first.
+func sortOpeningNodes(node *OpeningNode) {
+	sort.SliceStable(node.Children, func(i, j int) bool {
+		return node.Children[i].Games > node.Children[j].Games
+	})
+	for _, child := range node.Children {
+		sortOpeningNodes(child)
+	}
+}
+
+// canonicalOpening returns the first depth moves of a game, rotated and
+// mirrored into the orientation that comes first when the moves' points
+// are compared in order, counting from the top right. Games whose openings
+// differ only in orientation, from their first move on, get the same
+// moves. Non-square boards are not rotated.
+func canonicalOpening(game *Position, depth int) []string {
+	size := game.BoardXSize
+	moves := game.Moves
+	if len(moves) > depth {
+		moves = moves[:depth]
+	}
+	symmetries := 8
+	if game.BoardYSize != size {
+		symmetries = 1
+	}
+
+	var best []string
+	var bestKeys []int
+	for s := 0; s < symmetries; s++ {
+		line := make([]string, len(moves))
+		keys := make([]int, len(moves))
+		for i, move := range moves {
+			x, y := parseCoord(move.Location, game.BoardYSize)
+			if move.Location == "" || strings.EqualFold(move.Location, "pass") || x < 0 {
+				line[i], keys[i] = "pass", size*game.BoardYSize
+				continue
+			}
+			x, y = transformPoint(s, x, y, size)
+			line[i] = coordToString(x, y, game.BoardYSize)
+			keys[i] = y*size + (size - 1 - x)
+		}
+		if best == nil || lessKeys(keys, bestKeys) {
+			best, bestKeys = line, keys
+		}
+	}
+	return best
+}
+
+// transformPoint applies symmetry s, 0 to 7, of a square board to a point.
+// Symmetry 0 is the identity.
+func transformPoint(s, x, y, size int) (int, int) {
+	if s&1 != 0 {
+		x = size - 1 - x
+	}
+	if s&2 != 0 {
+		y = size - 1 - y
+	}
+	if s&4 != 0 {
+		x, y = y, x
+	}
+	return x, y
+}
+
+// lessKeys compares two sequences of point keys in order.
+func lessKeys(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
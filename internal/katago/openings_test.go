@@ -0,0 +1,104 @@
+package katago
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalOpening(t *testing.T) {
+	parse := func(sgf string) *Position {
+		game, err := NewSGFParser(sgf).Parse()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return game
+	}
+	// The same opening in two orientations, and one that differs
+	a := canonicalOpening(parse("(;SZ[19];B[pd];W[dp];B[qq])"), 12)
+	b := canonicalOpening(parse("(;SZ[19];B[dd];W[pp];B[cq])"), 12)
+	c := canonicalOpening(parse("(;SZ[19];B[pd];W[dp];B[dd])"), 12)
+	if strings.Join(a, " ") != strings.Join(b, " ") {
+		t.Errorf("Expected mirrored openings to match, got %v and %v", a, b)
+	}
+	// Q16 and D4 lie on the diagonal through the top right, so the third
+	// move is reflected across it
+	if got := strings.Join(a, " "); got != "Q16 D4 C17" {
+		t.Errorf("Expected Q16 D4 C17, got %s", got)
+	}
+	if got := strings.Join(c, " "); got != "Q16 D4 D16" {
+		t.Errorf("Expected Q16 D4 D16, got %s", got)
+	}
+	if got := canonicalOpening(parse("(;SZ[19];B[pd];W[dp];B[qq])"), 2); len(got) != 2 {
+		t.Errorf("Expected the opening cut at 2 moves, got %v", got)
+	}
+}
+
+func TestOpeningBook(t *testing.T) {
+	sgfs := []string{
+		"(;SZ[19]PB[Alice]PW[Bob]RE[B+R];B[pd];W[dp];B[qq])",
+		"(;SZ[19]PB[Alice]PW[Bob]RE[W+5];B[dd];W[pp];B[cq])",
+		"(;SZ[19]PB[Alice]PW[Bob]RE[B+2];B[pd];W[dp];B[dd])",
+		"(;SZ[19]PB[Bob]PW[Alice]RE[W+R];B[pd];W[qf])",
+		"(;SZ[19]PB[Bob]PW[Alice];B[pd];W[qf])",
+		"(;SZ[19]PB[Bob]PW[Alice]HA[2]AB[pd][dp];W[qf])",
+	}
+	// Q16 and D4 are always best, and any other move loses 3 points
+	var analyzed []string
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		var line []string
+		for _, move := range req.Position.Moves {
+			line = append(line, move.Location)
+		}
+		analyzed = append(analyzed, strings.Join(line, " "))
+		return &AnalysisResult{MoveInfos: []MoveInfo{
+			{Move: "Q16", ScoreLead: 0.5, Order: 0},
+			{Move: "D4", ScoreLead: 0.5, Order: 1},
+			{Move: "K10", ScoreLead: -2.5, Order: 2},
+		}}, nil
+	}
+
+	book, err := openingBook(context.Background(), analyze, sgfs, "alice", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if book.Games != 5 || book.Skipped != 1 || book.Positions != 3 {
+		t.Errorf("Expected 5 games, 1 skipped and 3 positions, got %+v", book)
+	}
+	// The empty board, then the positions before the habitual third move
+	// as Black and second move as White
+	if got := strings.Join(analyzed, ", "); got != ", Q16 D4, Q16" {
+		t.Errorf("Unexpected positions analyzed: %q", got)
+	}
+
+	if len(book.AsBlack) != 1 {
+		t.Fatalf("Expected one first move as Black, got %d", len(book.AsBlack))
+	}
+	first := book.AsBlack[0]
+	if first.Move != "Q16" || first.Games != 3 || first.Wins != 2 || first.Losses != 1 || !first.Evaluated || first.PointLoss != 0 {
+		t.Errorf("Unexpected first move: %+v", first)
+	}
+	third := first.Children[0].Children
+	if len(third) != 2 || third[0].Move != "C17" || third[0].Games != 2 || third[1].Evaluated {
+		t.Errorf("Expected C17 twice before the unevaluated D16, got %+v", third)
+	}
+
+	if len(book.LosingLines) != 2 {
+		t.Fatalf("Expected 2 losing lines, got %+v", book.LosingLines)
+	}
+	black, white := book.LosingLines[0], book.LosingLines[1]
+	if black.Color != "B" || strings.Join(black.Line, " ") != "Q16 D4 C17" || black.PointLoss != 3 ||
+		black.BestMove != "Q16" || black.Games != 2 || black.Wins != 1 || black.Losses != 1 {
+		t.Errorf("Unexpected losing line as Black: %+v", black)
+	}
+	if white.Color != "W" || strings.Join(white.Line, " ") != "Q16 O17" || white.Games != 2 || white.Wins != 1 {
+		t.Errorf("Unexpected losing line as White: %+v", white)
+	}
+
+	if _, err := openingBook(context.Background(), analyze, sgfs, "alice", &OpeningOptions{Depth: 41}); err == nil {
+		t.Error("Expected too deep a book to be rejected")
+	}
+	if _, err := openingBook(context.Background(), analyze, sgfs[5:], "alice", nil); err == nil {
+		t.Error("Expected an error without even games")
+	}
+}
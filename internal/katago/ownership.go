@@ -0,0 +1,201 @@
+package katago
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+const (
+	// groupShiftThreshold is the change in a group's average ownership
+	// that counts as attacking or strengthening it.
+	groupShiftThreshold = 0.2
+	// settledOwnership is the ownership above which a point counts as
+	// territory rather than influence.
+	settledOwnership = 0.6
+	// minInfluenceGain is the ownership gain on unsettled points needed to
+	// call a move an influence move.
+	minInfluenceGain = 2.0
+	// minTerritoryGain is the ownership gain on settled points needed to
+	// call a move a territory move.
+	minTerritoryGain = 2.0
+)
+
+// OwnershipImpact describes how a move changes KataGo's ownership map,
+// from the point of view of the player making it.
+type OwnershipImpact struct {
+	ScoreChange        float64  `json:"scoreChange"`                  // Net change in owned points
+	TerritoryGained    float64  `json:"territoryGained"`              // Gain on points now settled for the mover
+	InfluenceGained    float64  `json:"influenceGained"`              // Gain on points still unsettled
+	InfluenceDirection string   `json:"influenceDirection,omitempty"` // "center", "top", "bottom", "left" or "right"
+	AttackedGroups     []string `json:"attackedGroups,omitempty"`     // Opponent groups weakened, by their first stone
+	StrengthenedGroups []string `json:"strengthenedGroups,omitempty"` // Own groups made safer, by their first stone
+}
+
+// analyzeOwnershipImpact compares ownership before a move (the root's)
+// with ownership after it (the move's, from includeMovesOwnership). Both
+// maps are from Black's point of view, row by row from the top. It
+// returns nil when either map is missing, as with KataGo versions that
+// lack per-move ownership.
+func analyzeOwnershipImpact(board *Board, color, move string, before, after []float64) *OwnershipImpact {
+	size := board.xSize * board.ySize
+	if len(before) != size || len(after) != size {
+		return nil
+	}
+	mx, my, err := regionCorner(strings.ToUpper(move), board.xSize, board.ySize)
+	if err != nil {
+		return nil
+	}
+	sign := 1.0
+	if strings.EqualFold(color, "W") {
+		sign = -1
+	}
+	own := strings.ToLower(color)
+
+	impact := &OwnershipImpact{}
+	var sumX, sumY, weight float64
+	for p := range before {
+		delta := sign * (after[p] - before[p])
+		impact.ScoreChange += delta
+		if delta <= 0 || board.points[p] != "" || p == my*board.xSize+mx {
+			continue
+		}
+		if sign*after[p] > settledOwnership {
+			impact.TerritoryGained += delta
+		} else {
+			impact.InfluenceGained += delta
+			sumX += float64(p%board.xSize) * delta
+			sumY += float64(p/board.xSize) * delta
+			weight += delta
+		}
+	}
+	if weight > 0 && impact.InfluenceGained >= minInfluenceGain {
+		impact.InfluenceDirection = board.direction(mx, my, sumX/weight, sumY/weight)
+	}
+
+	// Average each group's ownership, from its owner's point of view
+	seen := make(map[int]bool)
+	for p, stone := range board.points {
+		if stone == "" || seen[p] {
+			continue
+		}
+		stones, _ := board.group(p)
+		owner := 1.0
+		if stone == "w" {
+			owner = -1
+		}
+		var shift float64
+		for _, s := range stones {
+			seen[s] = true
+			shift += owner * (after[s] - before[s])
+		}
+		shift /= float64(len(stones))
+		name := board.groupName(topLeft(stones), len(stones))
+		switch {
+		case stone == own && shift >= groupShiftThreshold:
+			impact.StrengthenedGroups = append(impact.StrengthenedGroups, name)
+		case stone != own && shift <= -groupShiftThreshold:
+			impact.AttackedGroups = append(impact.AttackedGroups, name)
+		}
+	}
+	sort.Strings(impact.AttackedGroups)
+	sort.Strings(impact.StrengthenedGroups)
+
+	impact.ScoreChange = roundTenth(impact.ScoreChange)
+	impact.TerritoryGained = roundTenth(impact.TerritoryGained)
+	impact.InfluenceGained = roundTenth(impact.InfluenceGained)
+	return impact
+}
+
+// strategicFromImpact describes a move's purpose from its ownership impact.
+func strategicFromImpact(impact *OwnershipImpact, region string) StrategicInfo {
+	info := StrategicInfo{Purpose: []string{}, BoardRegion: region}
+	if len(impact.AttackedGroups) > 0 {
+		info.Purpose = append(info.Purpose, "attack")
+		info.FightingMove = true
+	}
+	if len(impact.StrengthenedGroups) > 0 {
+		info.Purpose = append(info.Purpose, "defense")
+		info.FightingMove = true
+	}
+	if impact.TerritoryGained >= minTerritoryGain {
+		info.Purpose = append(info.Purpose, "territory")
+		info.TerritoryMove = true
+	}
+	if impact.InfluenceDirection != "" {
+		info.Purpose = append(info.Purpose, "influence")
+		info.InfluenceMove = true
+	}
+
+	swing := math.Abs(impact.ScoreChange)
+	switch {
+	case info.FightingMove && swing >= 5:
+		info.Urgency = "critical"
+	case swing >= 5 || info.FightingMove:
+		info.Urgency = "important"
+	default:
+		info.Urgency = "optional"
+	}
+	return info
+}
+
+// ownershipProsAndCons describes a move's ownership impact.
+func ownershipProsAndCons(p *i18n.Printer, impact *OwnershipImpact) (pros, cons []string) {
+	if impact.TerritoryGained >= minTerritoryGain {
+		pros = append(pros, p.Sprintf("Secures about %.1f points of territory", impact.TerritoryGained))
+	}
+	if impact.InfluenceDirection != "" {
+		pros = append(pros, p.Sprintf("Builds influence toward the %s", p.T(impact.InfluenceDirection)))
+	}
+	if len(impact.AttackedGroups) > 0 {
+		pros = append(pros, p.Sprintf("Attacks %s", strings.Join(impact.AttackedGroups, ", ")))
+	}
+	if len(impact.StrengthenedGroups) > 0 {
+		pros = append(pros, p.Sprintf("Strengthens %s", strings.Join(impact.StrengthenedGroups, ", ")))
+	}
+	if impact.ScoreChange < 0 {
+		cons = append(cons, p.Sprintf("Gives up about %.1f points of ownership", -impact.ScoreChange))
+	}
+	return pros, cons
+}
+
+// direction names where a point lies relative to a move: toward the
+// center of the board or toward one edge. It returns "" for points close
+// to the move, whose gain is just the stone's own surroundings.
+func (b *Board) direction(mx, my int, x, y float64) string {
+	dx, dy := x-float64(mx), y-float64(my)
+	if math.Hypot(dx, dy) < 1.5 {
+		return ""
+	}
+	cx, cy := float64(b.xSize-1)/2, float64(b.ySize-1)/2
+	if math.Hypot(x-cx, y-cy) < math.Hypot(float64(mx)-cx, float64(my)-cy)-1 {
+		return "center"
+	}
+	if math.Abs(dx) > math.Abs(dy) {
+		if dx < 0 {
+			return "left"
+		}
+		return "right"
+	}
+	if dy < 0 {
+		return "top"
+	}
+	return "bottom"
+}
+
+// groupName names a group by its top-left stone and size.
+func (b *Board) groupName(point, stones int) string {
+	location := fmt.Sprintf("%c%d", ColumnLetter(point%b.xSize), b.ySize-point/b.xSize)
+	if stones == 1 {
+		return location
+	}
+	return fmt.Sprintf("%s (%d stones)", location, stones)
+}
+
+// roundTenth rounds to one decimal place.
+func roundTenth(v float64) float64 {
+	return math.Round(v*10) / 10
+}
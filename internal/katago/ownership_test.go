@@ -0,0 +1,94 @@
+package katago
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+// ownershipMap returns a 9x9 ownership map with the given values.
+func ownershipMap(t *testing.T, values map[string]float64) []float64 {
+	t.Helper()
+	ownership := make([]float64, 81)
+	for location, v := range values {
+		x, y, err := regionCorner(location, 9, 9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ownership[y*9+x] = v
+	}
+	return ownership
+}
+
+func TestAnalyzeOwnershipImpact(t *testing.T) {
+	board := NewBoard(9, 9)
+	for _, stone := range [][2]string{{"w", "C3"}, {"b", "G7"}} {
+		if err := board.Play(stone[0], stone[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := ownershipMap(t, map[string]float64{"C3": -0.9, "G7": 0.9})
+	after := ownershipMap(t, map[string]float64{
+		"C3": -0.5, "G7": 0.9, "G3": 0.9,
+		"H2": 0.8, "J2": 0.8, "H1": 0.8,
+		"D5": 0.3, "E5": 0.3, "F5": 0.3, "D6": 0.3, "E6": 0.3, "F6": 0.3, "E4": 0.3,
+	})
+
+	impact := analyzeOwnershipImpact(board, "B", "G3", before, after)
+	want := &OwnershipImpact{
+		ScoreChange:        5.8,
+		TerritoryGained:    2.4,
+		InfluenceGained:    2.1,
+		InfluenceDirection: "center",
+		AttackedGroups:     []string{"C3"},
+	}
+	if !reflect.DeepEqual(impact, want) {
+		t.Fatalf("Got %+v, want %+v", impact, want)
+	}
+
+	info := strategicFromImpact(impact, "side")
+	if !reflect.DeepEqual(info.Purpose, []string{"attack", "territory", "influence"}) || info.Urgency != "critical" ||
+		!info.FightingMove || !info.TerritoryMove || !info.InfluenceMove || info.BoardRegion != "side" {
+		t.Errorf("Unexpected strategic info: %+v", info)
+	}
+
+	pros, cons := ownershipProsAndCons(i18n.English, impact)
+	wantPros := []string{"Secures about 2.4 points of territory", "Builds influence toward the center", "Attacks C3"}
+	if !reflect.DeepEqual(pros, wantPros) || len(cons) != 0 {
+		t.Errorf("Unexpected pros %q and cons %q", pros, cons)
+	}
+
+	if analyzeOwnershipImpact(board, "B", "G3", before, nil) != nil {
+		t.Error("Expected no impact without per-move ownership")
+	}
+}
+
+func TestAnalyzeOwnershipImpactWhite(t *testing.T) {
+	board := NewBoard(9, 9)
+	for _, stone := range [][2]string{{"w", "D4"}, {"b", "F4"}, {"w", "D5"}} {
+		if err := board.Play(stone[0], stone[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := ownershipMap(t, map[string]float64{"D4": -0.3, "D5": -0.3, "F4": 0.9})
+	after := ownershipMap(t, map[string]float64{"D4": -0.8, "D5": -0.8, "F4": 0.9, "E4": -0.9})
+
+	impact := analyzeOwnershipImpact(board, "W", "E4", before, after)
+	if impact == nil || impact.ScoreChange != 1.9 || impact.InfluenceDirection != "" ||
+		!reflect.DeepEqual(impact.StrengthenedGroups, []string{"D5 (2 stones)"}) || impact.AttackedGroups != nil {
+		t.Fatalf("Unexpected impact: %+v", impact)
+	}
+
+	info := strategicFromImpact(impact, "center")
+	if !reflect.DeepEqual(info.Purpose, []string{"defense"}) || info.Urgency != "important" {
+		t.Errorf("Unexpected strategic info: %+v", info)
+	}
+
+	impact.ScoreChange = -2
+	_, cons := ownershipProsAndCons(i18n.English, impact)
+	if len(cons) != 1 || !strings.Contains(cons[0], "Gives up about 2.0 points") {
+		t.Errorf("Expected a con for losing ownership, got %q", cons)
+	}
+}
@@ -0,0 +1,293 @@
+package katago
+
+import (
+	"math"
+	"sort"
+)
+
+// sekiNeutralOwnership is the ownership, either way, within which a shared
+// liberty counts as owned by neither player, as the liberties of a seki
+// are.
+const sekiNeutralOwnership = 0.5
+
+// PassAlive returns, for each point of the board, the player ("b" or "w")
+// for whom it is pass-alive, or "". Pass-alive stones cannot be captured
+// even if their player always passes, and pass-alive territory is enclosed
+// by such stones with every empty point next to them, so that the opponent
+// cannot live there. It is Benson's algorithm, which KataGo uses for the
+// areas it never lets a player give up.
+func (b *Board) PassAlive() []string {
+	owners := make([]string, len(b.points))
+	for _, color := range []string{"b", "w"} {
+		b.markPassAlive(color, owners)
+	}
+	return owners
+}
+
+// markPassAlive marks the points pass-alive for color in owners.
+func (b *Board) markPassAlive(color string, owners []string) {
+	// Number the chains of color and the regions they enclose: connected
+	// points that are empty or hold the opponent's stones
+	chainOf := make([]int, len(b.points))
+	regionOf := make([]int, len(b.points))
+	var chains, regions [][]int
+	for point := range b.points {
+		chainOf[point], regionOf[point] = -1, -1
+	}
+	for point, stone := range b.points {
+		switch {
+		case stone == color && chainOf[point] < 0:
+			stones, _ := b.group(point)
+			for _, s := range stones {
+				chainOf[s] = len(chains)
+			}
+			chains = append(chains, stones)
+		case stone != color && regionOf[point] < 0:
+			region := []int{point}
+			regionOf[point] = len(regions)
+			for i := 0; i < len(region); i++ {
+				for _, n := range b.neighbors(region[i]) {
+					if b.points[n] != color && regionOf[n] < 0 {
+						regionOf[n] = len(regions)
+						region = append(region, n)
+					}
+				}
+			}
+			regions = append(regions, region)
+		}
+	}
+
+	// The chains bordering each region, and those the region is vital to:
+	// every empty point of the region is one of their liberties
+	borders := make([]map[int]bool, len(regions))
+	vital := make([]map[int]bool, len(regions))
+	for r, region := range regions {
+		borders[r] = map[int]bool{}
+		for _, point := range region {
+			for _, n := range b.neighbors(point) {
+				if chainOf[n] >= 0 {
+					borders[r][chainOf[n]] = true
+				}
+			}
+		}
+		vital[r] = map[int]bool{}
+		for chain := range borders[r] {
+			isVital := true
+			for _, point := range region {
+				if b.points[point] == "" && !b.touches(point, chainOf, chain) {
+					isVital = false
+					break
+				}
+			}
+			if isVital {
+				vital[r][chain] = true
+			}
+		}
+	}
+
+	// Drop chains with fewer than two vital regions, and regions bordered
+	// by a dropped chain, until none is dropped
+	aliveChains := make([]bool, len(chains))
+	liveRegions := make([]bool, len(regions))
+	for i := range aliveChains {
+		aliveChains[i] = true
+	}
+	for i := range liveRegions {
+		liveRegions[i] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for c := range chains {
+			if !aliveChains[c] {
+				continue
+			}
+			count := 0
+			for r := range regions {
+				if liveRegions[r] && vital[r][c] {
+					count++
+				}
+			}
+			if count < 2 {
+				aliveChains[c], changed = false, true
+			}
+		}
+		for r := range regions {
+			if !liveRegions[r] {
+				continue
+			}
+			for c := range borders[r] {
+				if !aliveChains[c] {
+					liveRegions[r], changed = false, true
+					break
+				}
+			}
+		}
+	}
+
+	for c, stones := range chains {
+		if aliveChains[c] {
+			for _, s := range stones {
+				owners[s] = color
+			}
+		}
+	}
+	for r, region := range regions {
+		if !liveRegions[r] || len(borders[r]) == 0 {
+			continue
+		}
+		enclosed := true
+		for _, point := range region {
+			if b.points[point] != "" {
+				continue
+			}
+			touchesAlive := false
+			for _, n := range b.neighbors(point) {
+				if chainOf[n] >= 0 && aliveChains[chainOf[n]] {
+					touchesAlive = true
+					break
+				}
+			}
+			if !touchesAlive {
+				enclosed = false
+				break
+			}
+		}
+		if enclosed {
+			for _, point := range region {
+				owners[point] = color
+			}
+		}
+	}
+}
+
+// touches reports whether a point is next to a stone of the given chain.
+func (b *Board) touches(point int, chainOf []int, chain int) bool {
+	for _, n := range b.neighbors(point) {
+		if chainOf[n] == chain {
+			return true
+		}
+	}
+	return false
+}
+
+// Seki returns, for each point of the board, whether it holds a stone in
+// seki, judged from KataGo's ownership of the position: a stone of a chain
+// that is not pass-alive and that KataGo expects to live, sharing a
+// liberty that neither player owns with a living opponent chain, where
+// playing that liberty would leave either player in atari without
+// capturing.
+func (b *Board) Seki(ownership []float64) []bool {
+	seki, _ := b.sekiPoints(ownership)
+	return seki
+}
+
+// SekiGroup is stones of both players living together in seki and the
+// liberties they share, which neither player can fill.
+type SekiGroup struct {
+	Stones    []string `json:"stones"`
+	Liberties []string `json:"liberties"`
+}
+
+// SekiGroups returns the seki of the board, judged from KataGo's ownership
+// as by Seki, each with the chains of both players that live together and
+// the liberties they share, in board order.
+func (b *Board) SekiGroups(ownership []float64) []SekiGroup {
+	return b.sekiGroups(b.sekiPoints(ownership))
+}
+
+// sekiGroups groups the stones in seki and the liberties they share into
+// connected areas.
+func (b *Board) sekiGroups(stones, liberties []bool) []SekiGroup {
+	var groups []SekiGroup
+	seen := make([]bool, len(b.points))
+	for start := range b.points {
+		if seen[start] || !stones[start] {
+			continue
+		}
+		// The stones and shared liberties connected to the first stone
+		area := []int{start}
+		seen[start] = true
+		for i := 0; i < len(area); i++ {
+			for _, n := range b.neighbors(area[i]) {
+				if !seen[n] && (stones[n] || liberties[n]) {
+					seen[n] = true
+					area = append(area, n)
+				}
+			}
+		}
+		sort.Ints(area)
+		var group SekiGroup
+		for _, point := range area {
+			coord := coordToString(point%b.xSize, point/b.xSize, b.ySize)
+			if stones[point] {
+				group.Stones = append(group.Stones, coord)
+			} else {
+				group.Liberties = append(group.Liberties, coord)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// sekiPoints returns, for each point of the board, whether it holds a
+// stone in seki and whether it is a liberty shared in seki.
+func (b *Board) sekiPoints(ownership []float64) (seki, shared []bool) {
+	seki = make([]bool, len(b.points))
+	shared = make([]bool, len(b.points))
+	if len(ownership) < len(b.points) {
+		return seki, shared
+	}
+	passAlive := b.PassAlive()
+	living := func(point int) bool {
+		if passAlive[point] != "" {
+			return false
+		}
+		stones, _ := b.group(point)
+		owned := 0.0
+		for _, s := range stones {
+			owned += ownership[s]
+		}
+		if b.points[point] == "w" {
+			owned = -owned
+		}
+		return owned > 0
+	}
+	selfAtari := func(color string, point int) bool {
+		clone := b.Clone()
+		if clone.Play(color, coordToString(point%b.xSize, point/b.xSize, b.ySize)) != nil {
+			return true
+		}
+		if clone.Captures(color) > b.Captures(color) {
+			return false
+		}
+		_, libs := clone.group(point)
+		return libs <= 1
+	}
+
+	for point, stone := range b.points {
+		if stone != "" || math.Abs(ownership[point]) > sekiNeutralOwnership {
+			continue
+		}
+		var black, white []int
+		for _, n := range b.neighbors(point) {
+			switch {
+			case b.points[n] == "b" && living(n):
+				black = append(black, n)
+			case b.points[n] == "w" && living(n):
+				white = append(white, n)
+			}
+		}
+		if len(black) == 0 || len(white) == 0 || !selfAtari("b", point) || !selfAtari("w", point) {
+			continue
+		}
+		shared[point] = true
+		for _, n := range append(black, white...) {
+			stones, _ := b.group(n)
+			for _, s := range stones {
+				seki[s] = true
+			}
+		}
+	}
+	return seki, shared
+}
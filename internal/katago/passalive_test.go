@@ -0,0 +1,154 @@
+package katago
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// boardFromRows builds a board from rows of "B", "W" and "." from the top.
+func boardFromRows(t *testing.T, rows ...string) *Board {
+	t.Helper()
+	board := NewBoard(len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x, c := range row {
+			if c != '.' {
+				board.place(y*board.xSize+x, strings.ToLower(string(c)))
+			}
+		}
+	}
+	return board
+}
+
+func TestPassAlive(t *testing.T) {
+	// Black's wall has two eyes, A1-B1 with a dead White stone in it and
+	// D1-E1; the open area above is nobody's
+	board := boardFromRows(t,
+		".....",
+		".....",
+		".....",
+		"BBBBB",
+		"W.B..",
+	)
+	want := strings.Join([]string{"", "", "", "bbbbb", "bbbbb"}, "")
+	var got strings.Builder
+	for _, owner := range board.PassAlive() {
+		got.WriteString(owner)
+	}
+	if got.String() != want {
+		t.Errorf("Expected the wall and its eyes pass-alive, got %q", got.String())
+	}
+
+	// With one eye the wall is not pass-alive
+	board = boardFromRows(t,
+		".....",
+		".....",
+		".....",
+		"BBBBB",
+		".....",
+	)
+	for point, owner := range board.PassAlive() {
+		if owner != "" {
+			t.Errorf("Expected nothing pass-alive with one eye, got %s at %d", owner, point)
+		}
+	}
+}
+
+func TestSeki(t *testing.T) {
+	// Black and White share both of their liberties
+	board := boardFromRows(t,
+		"B.W",
+		"B.W",
+	)
+	ownership := []float64{0.8, 0, -0.8, 0.8, 0, -0.8}
+	seki := board.Seki(ownership)
+	for point, want := range []bool{true, false, true, true, false, true} {
+		if seki[point] != want {
+			t.Errorf("Expected seki %v at %d, got %v", want, point, seki[point])
+		}
+	}
+
+	// With two more liberties White can fill a shared one and capture
+	board = boardFromRows(t,
+		"B.W.",
+		"B.W.",
+	)
+	ownership = []float64{0.8, 0, -0.8, -0.9, 0.8, 0, -0.8, -0.9}
+	for point, inSeki := range board.Seki(ownership) {
+		if inSeki {
+			t.Errorf("Expected no seki, got one at %d", point)
+		}
+	}
+}
+
+func TestBuildTerritoryEstimateSeki(t *testing.T) {
+	// Black's A column and White's stones share B3 and B2, and KataGo
+	// leans Black's way on B3
+	position := &Position{
+		BoardXSize: 3,
+		BoardYSize: 3,
+		Moves: []Move{
+			{"b", "A3"}, {"w", "C3"}, {"b", "A2"}, {"w", "C2"}, {"b", "A1"}, {"w", "C1"}, {"b", "pass"}, {"w", "B1"},
+		},
+	}
+	ownership := []float64{0.8, 0.4, -0.8, 0.8, 0, -0.8, 0.8, -0.8, -0.8}
+
+	estimate, err := buildTerritoryEstimate(position, &AnalysisResult{Ownership: ownership}, 0.3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []SekiGroup{{Stones: []string{"A3", "C3", "A2", "C2", "A1", "B1", "C1"}, Liberties: []string{"B3", "B2"}}}
+	if !reflect.DeepEqual(estimate.Map.SekiGroups, want) {
+		t.Errorf("Expected seki %+v, got %+v", want, estimate.Map.SekiGroups)
+	}
+	if estimate.SekiPoints != 9 || len(estimate.Map.DeadStones) != 0 {
+		t.Errorf("Expected 9 points in seki and no dead stones, got %+v", estimate)
+	}
+	if estimate.Map.Territory[0][1] != "?" || estimate.DamePoints != 2 {
+		t.Errorf("Expected the shared liberties to be dame, got %v", estimate.Map.Territory)
+	}
+	viz := GetTerritoryVisualization(estimate)
+	for _, want := range []string{" 3  × × ×", "Seki (×): A3 C3 A2 C2 A1 B1 C1, sharing B3 B2\n"} {
+		if !strings.Contains(viz, want) {
+			t.Errorf("Expected %q in visualization, got:\n%s", want, viz)
+		}
+	}
+}
+
+func TestBuildTerritoryEstimateSettled(t *testing.T) {
+	// The position of TestPassAlive, with KataGo unsure of the open area
+	// and wrongly giving White A1
+	position := &Position{
+		BoardXSize: 5,
+		BoardYSize: 5,
+		Moves: []Move{
+			{"b", "A2"}, {"w", "A1"}, {"b", "B2"}, {"w", "pass"}, {"b", "C2"}, {"w", "pass"},
+			{"b", "D2"}, {"w", "pass"}, {"b", "E2"}, {"w", "pass"}, {"b", "C1"},
+		},
+	}
+	ownership := make([]float64, 25)
+	for i := 15; i < 25; i++ {
+		ownership[i] = 0.95
+	}
+	for i := 0; i < 15; i++ {
+		ownership[i] = 0.9
+	}
+	ownership[20] = -0.9
+
+	estimate, err := buildTerritoryEstimate(position, &AnalysisResult{Ownership: ownership}, 0.85)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if estimate.SettledBlack != 10 || estimate.ContestedBlack != 15 || estimate.BlackTerritory != 25 || estimate.WhiteTerritory != 0 {
+		t.Errorf("Expected 10 settled and 15 contested points for Black, got %+v", estimate)
+	}
+	if dead := estimate.Map.DeadStones; len(dead) != 1 || dead[0] != "A1" {
+		t.Errorf("Expected A1 dead in Black's settled territory, got %v", dead)
+	}
+	if estimate.Map.Settled[4][0] != "B" || estimate.Map.Settled[0][0] != "" {
+		t.Errorf("Unexpected settled map: %v", estimate.Map.Settled)
+	}
+	if viz := GetTerritoryVisualization(estimate); !strings.Contains(viz, "Settled (pass-alive): Black 10, White 0\nContested: Black 15, White 0\n") {
+		t.Errorf("Expected settled and contested counts, got:\n%s", viz)
+	}
+}
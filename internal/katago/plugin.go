@@ -0,0 +1,159 @@
+package katago
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// ProcessAnalyzer is an Analyzer run as a subprocess speaking JSON lines,
+// as described by config.AnalyzerConfig. Moves are sent one at a time; a
+// process that fails, times out or exits is restarted for the next move.
+type ProcessAnalyzer struct {
+	cfg    config.AnalyzerConfig
+	logger logging.ContextLogger
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+// pluginResponse is a plugin's answer about a move.
+type pluginResponse struct {
+	Findings []Finding `json:"findings"`
+	Error    string    `json:"error,omitempty"` // Set when the plugin could not analyze the move
+}
+
+// NewProcessAnalyzer creates an analyzer for a plugin. Its process is
+// started when it is first asked about a move.
+func NewProcessAnalyzer(cfg config.AnalyzerConfig, logger logging.ContextLogger) *ProcessAnalyzer {
+	return &ProcessAnalyzer{cfg: cfg, logger: logger.WithField("analyzer", cfg.Name)}
+}
+
+// Name returns the configured name of the plugin.
+func (a *ProcessAnalyzer) Name() string {
+	return a.cfg.Name
+}
+
+// AnalyzeMove sends the move to the plugin and waits for its findings.
+func (a *ProcessAnalyzer) AnalyzeMove(ctx context.Context, move *MoveContext) ([]Finding, error) {
+	request, err := json.Marshal(move)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode move: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cmd == nil {
+		if err := a.start(); err != nil {
+			return nil, err
+		}
+	}
+
+	type answer struct {
+		line []byte
+		err  error
+	}
+	answered := make(chan answer, 1)
+	stdin, stdout := a.stdin, a.stdout
+	go func() {
+		if _, err := stdin.Write(append(request, '\n')); err != nil {
+			answered <- answer{err: err}
+			return
+		}
+		line, err := stdout.ReadBytes('\n')
+		answered <- answer{line: line, err: err}
+	}()
+
+	timer := time.NewTimer(time.Duration(a.cfg.TimeoutSeconds) * time.Second)
+	defer timer.Stop()
+	select {
+	case ans := <-answered:
+		if ans.err != nil {
+			a.stop()
+			return nil, fmt.Errorf("plugin exited: %w", ans.err)
+		}
+		var resp pluginResponse
+		if err := json.Unmarshal(ans.line, &resp); err != nil {
+			return nil, fmt.Errorf("invalid plugin response: %w", err)
+		}
+		if resp.Error != "" {
+			return nil, errors.New(resp.Error)
+		}
+		return resp.Findings, nil
+	case <-ctx.Done():
+		a.stop()
+		<-answered
+		return nil, ctx.Err()
+	case <-timer.C:
+		a.stop()
+		<-answered
+		return nil, fmt.Errorf("plugin did not answer within %ds", a.cfg.TimeoutSeconds)
+	}
+}
+
+// Close stops the plugin's process, if it is running.
+func (a *ProcessAnalyzer) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stop()
+	return nil
+}
+
+// start runs the plugin. Its stderr is logged. Must be called with mu held.
+func (a *ProcessAnalyzer) start() error {
+	cmd := exec.Command(a.cfg.Command, a.cfg.Args...) // #nosec G204 -- the command is configuration
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create plugin stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create plugin stdout: %w", err)
+	}
+	cmd.Stderr = &pluginLog{logger: a.logger}
+	configureProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start plugin: %w", err)
+	}
+	a.logger.Info("Started analyzer plugin", "command", a.cfg.Command, "pid", cmd.Process.Pid)
+	a.cmd, a.stdin, a.stdout = cmd, stdin, bufio.NewReader(stdout)
+	return nil
+}
+
+// stop kills the plugin's process, if it is running, so the next move
+// starts it again. Must be called with mu held.
+func (a *ProcessAnalyzer) stop() {
+	if a.cmd == nil {
+		return
+	}
+	_ = a.stdin.Close()
+	_ = killProcess(a.cmd.Process)
+	_ = a.cmd.Wait()
+	a.cmd, a.stdin, a.stdout = nil, nil, nil
+}
+
+// pluginLog logs what a plugin writes to stderr, a line at a time.
+type pluginLog struct {
+	logger logging.ContextLogger
+}
+
+func (l *pluginLog) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if line != "" {
+			l.logger.Warn("Analyzer plugin: " + line)
+		}
+	}
+	return len(p), nil
+}
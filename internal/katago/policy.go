@@ -0,0 +1,69 @@
+package katago
+
+import (
+	"fmt"
+	"sort"
+)
+
+// policyTopMoves is the number of highest-policy moves listed in a policy
+// evaluation.
+const policyTopMoves = 10
+
+// PolicyMove is a move and its raw policy probability.
+type PolicyMove struct {
+	Move  string  `json:"move"`
+	Prior float64 `json:"prior"`
+}
+
+// PolicyEvaluation is the neural network's view of a position without
+// search: the policy distribution and the value head's estimate.
+type PolicyEvaluation struct {
+	CurrentPlayer string       `json:"currentPlayer"`
+	Winrate       float64      `json:"winrate"`
+	ScoreLead     float64      `json:"scoreLead"`
+	TopMoves      []PolicyMove `json:"topMoves"`
+	// Policy holds a probability per point, row by row from the top,
+	// followed by pass. Illegal moves are -1.
+	Policy []float64 `json:"policy"`
+}
+
+// PolicyOnlyRequest returns a single-visit request for position that
+// includes the policy. One visit evaluates only the root, so the result is
+// the raw network output and takes a fraction of the time of a search.
+func PolicyOnlyRequest(position *Position) *AnalysisRequest {
+	visits := 1
+	return &AnalysisRequest{
+		Position:      position,
+		MaxVisits:     &visits,
+		IncludePolicy: true,
+	}
+}
+
+// NewPolicyEvaluation extracts the policy evaluation from the result of a
+// PolicyOnlyRequest.
+func NewPolicyEvaluation(result *AnalysisResult, xSize, ySize int) *PolicyEvaluation {
+	eval := &PolicyEvaluation{
+		CurrentPlayer: result.RootInfo.CurrentPlayer,
+		Winrate:       result.RootInfo.Winrate,
+		ScoreLead:     result.RootInfo.ScoreLead,
+		Policy:        result.Policy,
+	}
+
+	for i, prior := range result.Policy {
+		if prior <= 0 || i > xSize*ySize {
+			continue
+		}
+		move := "pass"
+		if i < xSize*ySize {
+			move = fmt.Sprintf("%c%d", ColumnLetter(i%xSize), ySize-i/xSize)
+		}
+		eval.TopMoves = append(eval.TopMoves, PolicyMove{Move: move, Prior: prior})
+	}
+	sort.SliceStable(eval.TopMoves, func(i, j int) bool {
+		return eval.TopMoves[i].Prior > eval.TopMoves[j].Prior
+	})
+	if len(eval.TopMoves) > policyTopMoves {
+		eval.TopMoves = eval.TopMoves[:policyTopMoves]
+	}
+	return eval
+}
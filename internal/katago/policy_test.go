@@ -0,0 +1,41 @@
+package katago
+
+import (
+	"testing"
+)
+
+func TestPolicyOnlyRequest(t *testing.T) {
+	req := PolicyOnlyRequest(&Position{Rules: "chinese", BoardXSize: 9, BoardYSize: 9})
+	query, err := buildAnalysisQuery(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if query["maxVisits"] != 1 || query["includePolicy"] != true {
+		t.Errorf("Expected a single-visit query with policy, got %v", query)
+	}
+}
+
+func TestNewPolicyEvaluation(t *testing.T) {
+	// 3x2 board: A2 B2 C2 / A1 B1 C1, then pass
+	result := &AnalysisResult{
+		RootInfo: RootInfo{Visits: 1, Winrate: 0.6, ScoreLead: 2.5, CurrentPlayer: "B"},
+		Policy:   []float64{0.1, -1, 0.5, 0, 0.3, 0.05, 0.05},
+	}
+	eval := NewPolicyEvaluation(result, 3, 2)
+
+	if eval.Winrate != 0.6 || eval.ScoreLead != 2.5 || eval.CurrentPlayer != "B" {
+		t.Errorf("Unexpected value estimate: %+v", eval)
+	}
+	want := []PolicyMove{{"C2", 0.5}, {"B1", 0.3}, {"A2", 0.1}, {"C1", 0.05}, {"pass", 0.05}}
+	if len(eval.TopMoves) != len(want) {
+		t.Fatalf("Expected %d moves, got %+v", len(want), eval.TopMoves)
+	}
+	for i, move := range want {
+		if eval.TopMoves[i] != move {
+			t.Errorf("Move %d: expected %+v, got %+v", i, move, eval.TopMoves[i])
+		}
+	}
+	if len(eval.Policy) != 7 {
+		t.Errorf("Expected the full policy, got %d values", len(eval.Policy))
+	}
+}
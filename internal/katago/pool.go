@@ -0,0 +1,152 @@
+package katago
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/dmmcquay/katago-mcp/internal/cache"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/metrics"
+)
+
+// Pool supervises a set of named KataGo engines, such as a small fast
+// network for interactive tools alongside a large one for game reviews.
+type Pool struct {
+	supervisors map[string]*Supervisor
+	logger      logging.ContextLogger
+}
+
+// NewPool creates a supervisor for each engine configuration. Engines other
+// than the default get their own cache scope.
+func NewPool(engines map[string]*config.KataGoConfig, logger logging.ContextLogger, cacheManager *cache.Manager) *Pool {
+	p := &Pool{
+		supervisors: make(map[string]*Supervisor, len(engines)),
+		logger:      logger,
+	}
+	for name, cfg := range engines {
+		supervisor := NewSupervisor(cfg, logger.WithField("engine", name), cacheManager)
+		if engine, ok := supervisor.engine.(*Engine); ok && name != config.DefaultEngineName {
+			engine.SetCacheScope(name)
+		}
+		p.supervisors[name] = supervisor
+	}
+	return p
+}
+
+// SetPrometheus records each engine's metrics with collector, labeled with
+// the engine's name. Must be called before Start.
+func (p *Pool) SetPrometheus(collector *metrics.PrometheusCollector) {
+	for name, supervisor := range p.supervisors {
+		if engine, ok := supervisor.engine.(*Engine); ok {
+			engine.SetPrometheus(collector.Engine(name))
+		}
+	}
+}
+
+// SetAnalyzers sets the analyzers every engine runs in game reviews. Must
+// be called before Start.
+func (p *Pool) SetAnalyzers(analyzers []Analyzer) {
+	for _, supervisor := range p.supervisors {
+		if engine, ok := supervisor.engine.(*Engine); ok {
+			engine.SetAnalyzers(analyzers)
+		}
+	}
+}
+
+// Start starts every engine's supervisor.
+func (p *Pool) Start(ctx context.Context) error {
+	for _, name := range p.Names() {
+		if err := p.supervisors[name].Start(ctx); err != nil {
+			return fmt.Errorf("failed to start engine %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Stop stops every engine's supervisor.
+func (p *Pool) Stop() error {
+	var errs []error
+	for _, name := range p.Names() {
+		if err := p.supervisors[name].Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("engine %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Drain drains every engine at once, so that they share the time ctx
+// allows.
+func (p *Pool) Drain(ctx context.Context) error {
+	names := p.Names()
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			if err := p.supervisors[name].Drain(ctx); err != nil {
+				errs[i] = fmt.Errorf("engine %s: %w", name, err)
+			}
+		}(i, name)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Names returns the engine names in sorted order.
+func (p *Pool) Names() []string {
+	names := make([]string, 0, len(p.supervisors))
+	for name := range p.supervisors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Engine returns the named engine.
+func (p *Pool) Engine(name string) (EngineInterface, bool) {
+	supervisor, ok := p.supervisors[name]
+	if !ok {
+		return nil, false
+	}
+	return supervisor.GetEngine(), true
+}
+
+// Ready returns a readiness check for the named engine.
+func (p *Pool) Ready(name string, limits *config.ReadinessConfig) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		supervisor, ok := p.supervisors[name]
+		if !ok {
+			return fmt.Errorf("unknown engine: %s", name)
+		}
+		return supervisor.Ready(ctx, limits)
+	}
+}
+
+// GetStatus returns every engine's state and load for monitoring.
+func (p *Pool) GetStatus() map[string]interface{} {
+	status := make(map[string]interface{}, len(p.supervisors))
+	for name, supervisor := range p.supervisors {
+		status[name] = supervisor.Status()
+	}
+	return status
+}
+
+// Default returns the default engine.
+func (p *Pool) Default() EngineInterface {
+	engine, _ := p.Engine(config.DefaultEngineName)
+	return engine
+}
+
+// Engines returns every engine keyed by name.
+func (p *Pool) Engines() map[string]EngineInterface {
+	engines := make(map[string]EngineInterface, len(p.supervisors))
+	for name, supervisor := range p.supervisors {
+		engines[name] = supervisor.GetEngine()
+	}
+	return engines
+}
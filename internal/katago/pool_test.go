@@ -0,0 +1,47 @@
+package katago
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+func TestPool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	pool := NewPool(map[string]*config.KataGoConfig{
+		config.DefaultEngineName: {BinaryPath: "katago", MaxVisits: 1000},
+		"fast":                   {BinaryPath: "katago", MaxVisits: 100},
+	}, logger, nil)
+
+	if names := pool.Names(); !reflect.DeepEqual(names, []string{"default", "fast"}) {
+		t.Errorf("Unexpected engine names: %v", names)
+	}
+	if _, ok := pool.Engine("missing"); ok {
+		t.Error("Expected no engine for unknown name")
+	}
+
+	defaultEngine := pool.Default().(*Engine)
+	if defaultEngine.cacheScope != "" {
+		t.Errorf("Expected default engine to use the unscoped cache, got %q", defaultEngine.cacheScope)
+	}
+	fast, ok := pool.Engine("fast")
+	if !ok {
+		t.Fatal("Expected fast engine")
+	}
+	if scope := fast.(*Engine).cacheScope; scope != "fast" {
+		t.Errorf("Expected cache scope fast, got %q", scope)
+	}
+	if len(pool.Engines()) != 2 {
+		t.Errorf("Expected 2 engines, got %d", len(pool.Engines()))
+	}
+
+	if err := pool.Stop(); err != nil {
+		t.Errorf("Unexpected error stopping unstarted pool: %v", err)
+	}
+	if err := pool.Drain(context.Background()); err != nil {
+		t.Errorf("Unexpected error draining unstarted pool: %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+const (
+	defaultProblemGap    = 0.1
+	defaultProblemVisits = 400
+	defaultMaxProblems   = 20
+	// maxProblems bounds the size of one problem set.
+	maxProblems = 100
+	// maxSolutionMoves is the longest solution line written to a problem.
+	maxSolutionMoves = 10
+)
+
+// ProblemOptions controls which mistakes become problems.
+type ProblemOptions struct {
+	// MinGap is the win rate by which the solution must beat the
+	// second-best move, so that the problem has one clear answer
+	// (default: 0.1).
+	MinGap      float64
+	MaxProblems int // Largest number of problems, the costliest kept (default: 20)
+	MaxVisits   int // Visits to confirm each problem (default: 400)
+	// IncludeMistakes also considers mistakes, not only blunders.
+	IncludeMistakes bool
+	Thresholds      *MistakeThresholds // Review thresholds (default: DefaultMistakeThresholds)
+}
+
+// Problem is a training position taken from a player's mistake.
+type Problem struct {
+	Game        int      `json:"game"`       // 1-based index of the game
+	MoveNumber  int      `json:"moveNumber"` // The mistaken move; the problem is the position before it
+	Color       string   `json:"color"`      // Player to move, "B" or "W"
+	Category    string   `json:"category"`   // "blunder" or "mistake"
+	PlayedMove  string   `json:"playedMove"`
+	BestMove    string   `json:"bestMove"`
+	Solution    []string `json:"solution"` // Best move and KataGo's continuation
+	WinrateDrop float64  `json:"winrateDrop"`
+	Gap         float64  `json:"gap"` // Win rate margin of the best move over the second best
+}
+
+// ProblemSet is a collection of problems from a player's games.
+type ProblemSet struct {
+	Player   string    `json:"player"`
+	Games    int       `json:"games"`
+	Problems []Problem `json:"problems"`
+	// Unclear counts mistakes skipped for lacking a clear best move.
+	Unclear int `json:"unclear"`
+	// Partial is set when the games could not all be reviewed.
+	Partial bool `json:"partial,omitempty"`
+	// SGF holds the problems as an SGF collection, one game tree each,
+	// with the solution and the move played as variations.
+	SGF string `json:"sgf"`
+}
+
+// GenerateProblems reviews a player's games and turns the positions where
+// they blundered, but one move was clearly best, into a problem set. The
+// player is matched against each game's PB and PW properties.
+func (e *Engine) GenerateProblems(ctx context.Context, sgfs []string, player string, opts *ProblemOptions) (*ProblemSet, error) {
+	return generateProblems(ctx, e.ReviewGame, e.Analyze, sgfs, player, opts)
+}
+
+// generateProblems builds a problem set with the given review and analysis
+// functions.
+func generateProblems(ctx context.Context,
+	review func(context.Context, string, *MistakeThresholds) (*GameReview, error),
+	analyze func(context.Context, *AnalysisRequest) (*AnalysisResult, error),
+	sgfs []string, player string, opts *ProblemOptions) (*ProblemSet, error) {
+	games, colors, err := parseRankGames(sgfs, player)
+	if err != nil {
+		return nil, err
+	}
+	gap, limit, visits := defaultProblemGap, defaultMaxProblems, defaultProblemVisits
+	var thresholds *MistakeThresholds
+	includeMistakes := false
+	if opts != nil {
+		if opts.MinGap > 0 {
+			gap = opts.MinGap
+		}
+		if opts.MaxProblems > 0 {
+			limit = opts.MaxProblems
+		}
+		if opts.MaxVisits > 0 {
+			visits = opts.MaxVisits
+		}
+		thresholds = opts.Thresholds
+		includeMistakes = opts.IncludeMistakes
+	}
+	if limit > maxProblems {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "at most %d problems are allowed", maxProblems)
+	}
+
+	set := &ProblemSet{Player: player, Games: len(games), Problems: []Problem{}}
+	for i, game := range games {
+		gameReview, err := review(gameProgress(ctx, i, len(games)), sgfs[i], thresholds)
+		if err != nil {
+			if ctx.Err() != nil {
+				set.Partial = true
+				break
+			}
+			return nil, fmt.Errorf("failed to review game %d: %w", i+1, err)
+		}
+		set.Partial = set.Partial || gameReview.Summary.Partial
+		for _, mistake := range gameReview.Mistakes {
+			if mistake.Color != colors[i] || (mistake.Category != "blunder" && !includeMistakes) ||
+				mistake.MoveNumber < 1 || mistake.MoveNumber > len(game.Moves) {
+				continue
+			}
+			position := *game
+			position.Moves = game.Moves[:mistake.MoveNumber-1]
+			problemVisits := visits
+			result, err := analyze(ctx, &AnalysisRequest{Position: &position, MaxVisits: &problemVisits})
+			if err != nil {
+				if ctx.Err() != nil {
+					set.Partial = true
+					break
+				}
+				return nil, fmt.Errorf("failed to analyze game %d move %d: %w", i+1, mistake.MoveNumber, err)
+			}
+			problem, ok := newProblem(i+1, &mistake, result, gap)
+			if !ok {
+				set.Unclear++
+				continue
+			}
+			set.Problems = append(set.Problems, problem)
+		}
+		if ctx.Err() != nil {
+			set.Partial = true
+			break
+		}
+	}
+
+	// Keep the costliest problems, then present them in game order
+	sort.SliceStable(set.Problems, func(a, b int) bool {
+		return set.Problems[a].WinrateDrop > set.Problems[b].WinrateDrop
+	})
+	if len(set.Problems) > limit {
+		set.Problems = set.Problems[:limit]
+	}
+	sort.SliceStable(set.Problems, func(a, b int) bool {
+		pa, pb := set.Problems[a], set.Problems[b]
+		if pa.Game != pb.Game {
+			return pa.Game < pb.Game
+		}
+		return pa.MoveNumber < pb.MoveNumber
+	})
+
+	set.SGF, err = WriteProblemSGF(games, set.Problems)
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// newProblem turns a mistake into a problem when the analysis of the
+// position before it shows one clearly best move that the player missed.
+func newProblem(game int, mistake *Mistake, result *AnalysisResult, minGap float64) (Problem, bool) {
+	if len(result.MoveInfos) == 0 {
+		return Problem{}, false
+	}
+	best := result.MoveInfos[0]
+	if strings.EqualFold(best.Move, "pass") || strings.EqualFold(best.Move, mistake.PlayedMove) {
+		return Problem{}, false
+	}
+	// Win rates share one perspective, so the margin does not depend on it
+	gap := 1.0
+	if len(result.MoveInfos) > 1 {
+		gap = math.Abs(best.Winrate - result.MoveInfos[1].Winrate)
+	}
+	if gap < minGap {
+		return Problem{}, false
+	}
+	solution := best.PV
+	if len(solution) == 0 {
+		solution = []string{best.Move}
+	}
+	if len(solution) > maxSolutionMoves {
+		solution = solution[:maxSolutionMoves]
+	}
+	return Problem{
+		Game:        game,
+		MoveNumber:  mistake.MoveNumber,
+		Color:       mistake.Color,
+		Category:    mistake.Category,
+		PlayedMove:  mistake.PlayedMove,
+		BestMove:    best.Move,
+		Solution:    solution,
+		WinrateDrop: mistake.WinrateDrop,
+		Gap:         gap,
+	}, true
+}
+
+// WriteProblemSGF writes problems as an SGF collection. Each problem is a
+// game tree that sets up the position before the mistake, with the
+// solution as the first variation and the move played as the second.
+func WriteProblemSGF(games []*Position, problems []Problem) (string, error) {
+	var sb strings.Builder
+	for n, problem := range problems {
+		if problem.Game < 1 || problem.Game > len(games) {
+			return "", apperrors.New(apperrors.CodeInvalidArgument, "problem %d refers to unknown game %d", n+1, problem.Game)
+		}
+		game := games[problem.Game-1]
+		position := *game
+		position.Moves = game.Moves[:problem.MoveNumber-1]
+		board, err := BoardFromPosition(&position)
+		if err != nil {
+			return "", fmt.Errorf("problem %d: %w", n+1, err)
+		}
+
+		if n > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString("(;GM[1]FF[4]CA[UTF-8]")
+		if board.xSize == board.ySize {
+			sb.WriteString(fmt.Sprintf("SZ[%d]", board.xSize))
+		} else {
+			sb.WriteString(fmt.Sprintf("SZ[%d:%d]", board.xSize, board.ySize))
+		}
+		sb.WriteString(fmt.Sprintf("KM[%g]RU[%s]GN[Problem %d]", game.Komi, sgfRules(game.Rules), n+1))
+		for _, color := range []string{"b", "w"} {
+			var points []string
+			for p, stone := range board.points {
+				if stone == color {
+					points = append(points, sgfPoint(p%board.xSize, p/board.xSize))
+				}
+			}
+			if len(points) > 0 {
+				sb.WriteString(fmt.Sprintf("A%s[%s]", strings.ToUpper(color), strings.Join(points, "][")))
+			}
+		}
+		sb.WriteString(fmt.Sprintf("PL[%s]C[%s]\n", problem.Color, escapeSGFText(fmt.Sprintf(
+			"From game %d, move %d. %s to play.", problem.Game, problem.MoveNumber, colorWord(problem.Color)))))
+
+		solution, err := sgfLine(board, problem.Color, problem.Solution, "Correct.")
+		if err != nil {
+			return "", fmt.Errorf("problem %d solution: %w", n+1, err)
+		}
+		played, err := sgfLine(board, problem.Color, []string{moveLocation(Move{Location: problem.PlayedMove})},
+			fmt.Sprintf("Wrong: played in the game, losing %.1f%% win rate.", problem.WinrateDrop*100))
+		if err != nil {
+			return "", fmt.Errorf("problem %d: %w", n+1, err)
+		}
+		sb.WriteString("(" + solution + ")\n(" + played + "))\n")
+	}
+	return sb.String(), nil
+}
+
+// sgfLine writes moves as a sequence of SGF nodes, alternating colors
+// from color, with a comment on the first move.
+func sgfLine(board *Board, color string, moves []string, comment string) (string, error) {
+	var sb strings.Builder
+	for i, move := range moves {
+		point := ""
+		if !strings.EqualFold(move, "pass") {
+			x, y, err := regionCorner(strings.ToUpper(move), board.xSize, board.ySize)
+			if err != nil {
+				return "", err
+			}
+			point = sgfPoint(x, y)
+		}
+		sb.WriteString(fmt.Sprintf(";%s[%s]", strings.ToUpper(color), point))
+		if i == 0 {
+			sb.WriteString("C[" + escapeSGFText(comment) + "]")
+		}
+		color = opponent(color)
+	}
+	return sb.String(), nil
+}
+
+// sgfPoint returns the SGF coordinate of a point, with row 0 at the top.
+func sgfPoint(x, y int) string {
+	return string([]byte{byte('a' + x), byte('a' + y)})
+}
+
+// sgfRules names rules as SGF's RU property does.
+func sgfRules(rules string) string {
+	switch rules {
+	case "japanese":
+		return "Japanese"
+	case "korean":
+		return "Korean"
+	case "aga":
+		return "AGA"
+	case "new_zealand":
+		return "New Zealand"
+	case "tromp-taylor":
+		return "Tromp-Taylor"
+	}
+	return "Chinese"
+}
+
+// colorWord names a color, "B" or "W".
+func colorWord(color string) string {
+	if strings.EqualFold(color, "W") {
+		return "White"
+	}
+	return "Black"
+}
@@ -0,0 +1,127 @@
+package katago
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+func TestGenerateProblems(t *testing.T) {
+	sgfs := []string{
+		"(;GM[1]FF[4]SZ[19]KM[6.5]RU[Japanese]PB[Alice]PW[Bob];B[pd];W[dp];B[cc];W[dd];B[qq])",
+		"(;GM[1]FF[4]SZ[19]KM[6.5]PB[Bob]PW[Alice];B[pd];W[dp];B[pp];W[cc])",
+	}
+	reviews := map[string]*GameReview{
+		sgfs[0]: {Mistakes: []Mistake{
+			{MoveNumber: 3, Color: "B", PlayedMove: "C17", Category: "blunder", WinrateDrop: 0.2},
+			{MoveNumber: 4, Color: "W", PlayedMove: "D16", Category: "blunder", WinrateDrop: 0.4},
+			{MoveNumber: 5, Color: "B", PlayedMove: "R3", Category: "mistake", WinrateDrop: 0.08},
+		}},
+		sgfs[1]: {Mistakes: []Mistake{
+			{MoveNumber: 4, Color: "W", PlayedMove: "C17", Category: "blunder", WinrateDrop: 0.3},
+		}},
+	}
+	review := func(ctx context.Context, sgf string, thresholds *MistakeThresholds) (*GameReview, error) {
+		return reviews[sgf], nil
+	}
+	var requests []*AnalysisRequest
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		requests = append(requests, req)
+		infos := []MoveInfo{
+			{Move: "D16", Winrate: 0.6, PV: []string{"D16", "C17", "C16"}},
+			{Move: "Q4", Winrate: 0.45},
+		}
+		if len(req.Position.Moves) == 4 {
+			// Move 5 of the first game has no clear answer
+			infos[1].Winrate = 0.58
+		}
+		return &AnalysisResult{MoveInfos: infos}, nil
+	}
+
+	set, err := generateProblems(context.Background(), review, analyze, sgfs, "alice",
+		&ProblemOptions{MaxVisits: 50, IncludeMistakes: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(requests) != 3 || len(requests[0].Position.Moves) != 2 || *requests[0].MaxVisits != 50 {
+		t.Errorf("Expected Alice's three mistakes analyzed before they were played, got %d requests", len(requests))
+	}
+	if set.Games != 2 || set.Unclear != 1 || len(set.Problems) != 2 {
+		t.Fatalf("Expected two problems and one unclear mistake, got %+v", set)
+	}
+	first := set.Problems[0]
+	if first.Game != 1 || first.MoveNumber != 3 || first.BestMove != "D16" || first.Color != "B" {
+		t.Errorf("Unexpected first problem: %+v", first)
+	}
+	if first.Gap < 0.149 || first.Gap > 0.151 || len(first.Solution) != 3 {
+		t.Errorf("Expected a 0.15 gap and a three-move solution, got %+v", first)
+	}
+	if second := set.Problems[1]; second.Game != 2 || second.MoveNumber != 4 || second.Color != "W" {
+		t.Errorf("Unexpected second problem: %+v", second)
+	}
+
+	// The costliest problem is kept
+	limited, err := generateProblems(context.Background(), review, analyze, sgfs, "Alice", &ProblemOptions{MaxProblems: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(limited.Problems) != 1 || limited.Problems[0].Game != 2 {
+		t.Errorf("Expected only the problem from game 2, got %+v", limited.Problems)
+	}
+
+	tests := []struct {
+		name   string
+		player string
+		opts   *ProblemOptions
+		code   apperrors.Code
+	}{
+		{"unknown player", "Carol", nil, apperrors.CodeInvalidArgument},
+		{"too many problems", "Alice", &ProblemOptions{MaxProblems: 101}, apperrors.CodeInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := generateProblems(context.Background(), review, analyze, sgfs, tt.player, tt.opts); apperrors.CodeOf(err) != tt.code {
+				t.Errorf("Expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestWriteProblemSGF(t *testing.T) {
+	game, err := NewSGFParser("(;GM[1]FF[4]SZ[9]KM[6.5]RU[Japanese];B[ee];W[cc];B[gc])").Parse()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	problems := []Problem{{
+		Game: 1, MoveNumber: 3, Color: "B", PlayedMove: "G7", BestMove: "C3",
+		Solution: []string{"C3", "pass", "D3"}, WinrateDrop: 0.25,
+	}}
+	sgf, err := WriteProblemSGF([]*Position{game}, problems)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"SZ[9]KM[6.5]RU[Japanese]GN[Problem 1]AB[ee]AW[cc]PL[B]",
+		"(;B[cg]C[Correct.];W[];B[dg])",
+		"(;B[gc]C[Wrong: played in the game, losing 25.0% win rate.])",
+	} {
+		if !strings.Contains(sgf, want) {
+			t.Errorf("Expected %q in %q", want, sgf)
+		}
+	}
+
+	// The problem reads back as its starting position
+	position, err := NewSGFParser(sgf).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse problem: %v", err)
+	}
+	if len(position.Moves) != 0 || position.InitialPlayer != "b" || len(position.InitialStones) != 2 || position.Rules != "japanese" {
+		t.Errorf("Unexpected problem position: %+v", position)
+	}
+
+	if _, err := WriteProblemSGF([]*Position{game}, []Problem{{Game: 2, MoveNumber: 1}}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for an unknown game, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
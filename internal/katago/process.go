@@ -6,37 +6,88 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"os/exec"
+	"strings"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	"time"
 
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
 	"github.com/dmmcquay/katago-mcp/internal/cache"
 	"github.com/dmmcquay/katago-mcp/internal/config"
 	"github.com/dmmcquay/katago-mcp/internal/logging"
 	"github.com/dmmcquay/katago-mcp/internal/metrics"
+	"github.com/dmmcquay/katago-mcp/internal/retry"
+	"github.com/dmmcquay/katago-mcp/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Engine manages a KataGo process for analysis.
 type Engine struct {
 	config     *config.KataGoConfig
 	logger     logging.ContextLogger
-	prometheus *metrics.PrometheusCollector
+	prometheus *metrics.EngineMetrics
 	cache      *cache.Manager
+	cacheScope string
+	analyzers  []Analyzer // Run on each judged move of a review
 
 	cmd    *exec.Cmd
 	stdin  io.WriteCloser
 	stdout *bufio.Reader
 	stderr *bufio.Reader
 
-	mu          sync.Mutex
-	running     bool
-	queryID     int
-	pending     map[string]chan *Response
-	stopCh      chan struct{}
-	healthCheck chan struct{}
+	mu         sync.Mutex
+	running    bool
+	queryID    int
+	queries    *dispatcher
+	refreshing map[string]struct{}
+	inflight   *flightGroup
+	lanes      *laneScheduler
+	active     atomic.Int32 // Queries waiting to be sent or awaiting a response
+	draining   atomic.Bool  // New queries are refused while the engine drains
+	stopCh     chan struct{}
+	// lastActivity is when the engine was started or last sent or answered
+	// a query, in Unix nanoseconds
+	lastActivity atomic.Int64
+
+	// capabilities of the running KataGo, negotiated after each start
+	capabilities Capabilities
+	// fingerprint of the model and configuration the running KataGo was
+	// started with, which its cache keys start with
+	fingerprint string
+
+	startupMu sync.Mutex
+	startup   StartupProgress
+	// startupReady is closed once KataGo reports it is ready for queries
+	startupReady chan struct{}
+
+	// recorder keeps the raw protocol lines when recording is enabled
+	recorder *Recorder
+	// slowQueries keeps the queries slower than the configured threshold,
+	// when one is set
+	slowQueries *SlowQueryLog
+
+	// The last stderr lines and queries, and the latest bundle, for
+	// diagnosing crashes
+	stderrTail    *lineTail
+	recentQueries *Recorder
+	diagnosticsMu sync.Mutex
+	diagnostics   *DiagnosticsBundle
+
+	// schemaDrift holds the response schema issues already warned about
+	schemaDrift sync.Map
 }
 
+// drainPollInterval is how often a draining engine checks for outstanding
+// queries.
+const drainPollInterval = 50 * time.Millisecond
+
+// versionNegotiationTimeout bounds each query_version request sent once
+// KataGo is ready. Failed requests are retried with backoff.
+const versionNegotiationTimeout = 10 * time.Second
+
 // Response represents a KataGo analysis response.
 type Response struct {
 	ID         string                 `json:"id"`
@@ -54,12 +105,20 @@ type MoveInfo struct {
 	Winrate    float64  `json:"winrate"`
 	ScoreLead  float64  `json:"scoreLead"`
 	ScoreMean  float64  `json:"scoreMean"`
-	ScoreStdev float64  `json:"scoreStdev,omitempty"`
+	ScoreStdev float64  `json:"scoreStdev"`
 	Prior      float64  `json:"prior"` // Neural network's initial probability
-	Utility    float64  `json:"utility,omitempty"`
-	LCB        float64  `json:"lcb,omitempty"`
+	HumanPrior float64  `json:"humanPrior,omitempty"`
+	Utility    float64  `json:"utility"`
+	LCB        float64  `json:"lcb"` // Lower confidence bound of the win rate
 	PV         []string `json:"pv"`
-	Order      int      `json:"order"`
+	// Visits of each PV move's node and of the edge into it (if requested
+	// with includePVVisits). Few visits deep in a PV mean KataGo is unsure
+	// of the line there.
+	PVVisits     []int `json:"pvVisits,omitempty"`
+	PVEdgeVisits []int `json:"pvEdgeVisits,omitempty"`
+	Order        int   `json:"order"`
+	// Ownership after the move (if requested with includeMovesOwnership)
+	Ownership []float64 `json:"ownership,omitempty"`
 }
 
 // RootInfo contains information about the root position.
@@ -69,7 +128,16 @@ type RootInfo struct {
 	ScoreLead     float64 `json:"scoreLead"`
 	ScoreMean     float64 `json:"scoreMean"`
 	ScoreStdev    float64 `json:"scoreStdev"`
+	Utility       float64 `json:"utility"`
 	CurrentPlayer string  `json:"currentPlayer"`
+
+	// Raw neural network evaluation of the root, before search
+	RawWinrate       float64 `json:"rawWinrate,omitempty"`
+	RawLead          float64 `json:"rawLead,omitempty"`
+	RawScoreSelfplay float64 `json:"rawScoreSelfplay,omitempty"`
+	RawStWrError     float64 `json:"rawStWrError,omitempty"`
+	RawStScoreError  float64 `json:"rawStScoreError,omitempty"`
+	RawVarTimeLeft   float64 `json:"rawVarTimeLeft,omitempty"`
 }
 
 // ErrorResponse represents an error from KataGo.
@@ -78,21 +146,125 @@ type ErrorResponse struct {
 	Code    string `json:"code,omitempty"`
 }
 
+// ResponseCodec converts responses for a shared cache backend. The raw
+// KataGo JSON is stored so policy and ownership survive the round trip.
+var ResponseCodec = cache.Codec{Encode: encodeResponse, Decode: decodeResponse}
+
+// encodeResponse serializes a cached response.
+func encodeResponse(value interface{}) ([]byte, error) {
+	resp, ok := value.(*Response)
+	if !ok {
+		return nil, fmt.Errorf("unexpected cached value %T", value)
+	}
+	if resp.Raw != nil {
+		return json.Marshal(resp.Raw)
+	}
+	return json.Marshal(resp)
+}
+
+// decodeResponse restores a response serialized by encodeResponse.
+func decodeResponse(data []byte) (interface{}, error) {
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &resp.Raw); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // NewEngine creates a new KataGo engine.
 func NewEngine(cfg *config.KataGoConfig, logger logging.ContextLogger, cacheManager *cache.Manager) *Engine {
-	return &Engine{
-		config:      cfg,
-		logger:      logger,
-		prometheus:  metrics.NewPrometheusCollector(),
-		cache:       cacheManager,
-		pending:     make(map[string]chan *Response),
-		stopCh:      make(chan struct{}),
-		healthCheck: make(chan struct{}, 1),
+	var recorder *Recorder
+	if cfg.Recorder.Enabled {
+		var err error
+		recorder, err = NewRecorder(cfg.Recorder.Size, cfg.Recorder.Path)
+		if err != nil {
+			logger.Warn("Engine recording disabled", "error", err)
+		}
+	}
+	var slowQueries *SlowQueryLog
+	if cfg.SlowQueries.ThresholdSeconds > 0 {
+		slowQueries = NewSlowQueryLog(time.Duration(cfg.SlowQueries.ThresholdSeconds*float64(time.Second)), cfg.SlowQueries.Size)
+	}
+	recentQueries, _ := NewRecorder(diagnosticsQueries, "")
+	e := &Engine{
+		recorder:      recorder,
+		slowQueries:   slowQueries,
+		stderrTail:    newLineTail(diagnosticsStderrLines),
+		recentQueries: recentQueries,
+		config:        cfg,
+		logger:        logger,
+		cache:         cacheManager,
+		refreshing:    make(map[string]struct{}),
+		inflight:      newFlightGroup(),
+		lanes:         newLaneScheduler(maxInFlight(cfg), cfg.Lanes.Weights),
+		stopCh:        make(chan struct{}),
+	}
+	e.queries = newDispatcher(e.recordOrphan)
+	return e
+}
+
+// SetPrometheus sets where the engine records its Prometheus metrics. An
+// engine without one records none. Must be called before Start.
+func (e *Engine) SetPrometheus(m *metrics.EngineMetrics) {
+	e.prometheus = m
+}
+
+// SetCacheScope namespaces this engine's cache entries so engines running
+// different models do not share results. Must be called before Start.
+func (e *Engine) SetCacheScope(scope string) {
+	e.cacheScope = scope
+}
+
+// SetAnalyzers sets the analyzers run alongside the mistake detector in
+// game reviews. Must be called before Start.
+func (e *Engine) SetAnalyzers(analyzers []Analyzer) {
+	e.analyzers = analyzers
+}
+
+// cacheNamespaceKey is the context key of a request's cache namespace.
+type cacheNamespaceKey struct{}
+
+// WithCacheNamespace returns a context whose queries only use and fill the
+// cache entries of the given namespace, and only join in-flight queries
+// from the same namespace, so tenants cannot see each other's results.
+func WithCacheNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, cacheNamespaceKey{}, namespace)
+}
+
+// CacheNamespaceFromContext returns the cache namespace of a context's
+// queries, if it has one.
+func CacheNamespaceFromContext(ctx context.Context) (string, bool) {
+	namespace, ok := ctx.Value(cacheNamespaceKey{}).(string)
+	return namespace, ok && namespace != ""
+}
+
+// namespacedKey scopes a cache or in-flight query key to the context's
+// cache namespace, if it has one.
+func namespacedKey(ctx context.Context, key string) string {
+	if namespace, ok := CacheNamespaceFromContext(ctx); ok {
+		return CacheNamespacePrefix(namespace) + key
 	}
+	return key
+}
+
+// CacheNamespacePrefix returns the prefix of the cache keys of a
+// namespace's queries, for clearing its cached results.
+func CacheNamespacePrefix(namespace string) string {
+	return "ns/" + url.QueryEscape(namespace) + ":"
 }
 
 // Start starts the KataGo process.
 func (e *Engine) Start(ctx context.Context) error {
+	// Hashing the models takes a few seconds the first time, so it is done
+	// before taking the lock
+	var fingerprint string
+	if e.cache != nil && e.cache.IsEnabled() {
+		fingerprint = cacheFingerprint(e.config, "")
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -108,9 +280,14 @@ func (e *Engine) Start(ctx context.Context) error {
 	if e.config.ModelPath != "" {
 		args = append(args, "-model", e.config.ModelPath)
 	}
+	if e.config.HumanModelPath != "" {
+		args = append(args, "-human-model", e.config.HumanModelPath)
+	}
 
-	// Create command
-	e.cmd = exec.CommandContext(ctx, e.config.BinaryPath, args...) // #nosec G204 -- BinaryPath is validated configuration
+	// Create command. The process outlives the context, which may be that of
+	// a request starting a stopped engine on demand; Stop ends it.
+	e.cmd = exec.CommandContext(context.WithoutCancel(ctx), e.config.BinaryPath, args...) // #nosec G204 -- BinaryPath is validated configuration
+	configureProcess(e.cmd)
 
 	// Set up pipes
 	stdin, err := e.cmd.StdinPipe()
@@ -137,6 +314,17 @@ func (e *Engine) Start(ctx context.Context) error {
 	}
 
 	e.running = true
+	e.stopCh = make(chan struct{})
+	e.lastActivity.Store(time.Now().UnixNano())
+	e.capabilities = Capabilities{}
+	if fingerprint != "" {
+		e.setFingerprint(fingerprint)
+	}
+	ready := make(chan struct{})
+	e.startupMu.Lock()
+	e.startup = StartupProgress{Phase: PhaseStarting, StartedAt: time.Now()}
+	e.startupReady = ready
+	e.startupMu.Unlock()
 	e.logger.Info("KataGo engine started",
 		"binary", e.config.BinaryPath,
 		"model", e.config.ModelPath,
@@ -153,18 +341,94 @@ func (e *Engine) Start(ctx context.Context) error {
 	}
 
 	// Start reader goroutines
-	go e.readStdout()
-	go e.readStderr()
+	go e.readStdout(e.stdout, e.stopCh)
+	go e.readStderr(e.stderr, e.stopCh)
 
 	// Send initial configuration
 	e.configure()
 
-	// Start health check routine
-	go e.healthCheckRoutine()
+	// Learn which protocol features this KataGo release supports
+	go e.negotiateVersion(e.stopCh, ready)
 
 	return nil
 }
 
+// negotiateVersion asks KataGo for its version and records the protocol
+// capabilities of that release. KataGo reads no queries until its model has
+// loaded, which can take minutes while tuning, so it waits until startup
+// reports ready and then retries until KataGo answers or the process stops.
+// If KataGo cannot report a version, queries are sent unchanged.
+func (e *Engine) negotiateVersion(stopCh <-chan struct{}, ready <-chan struct{}) {
+	select {
+	case <-ready:
+	case <-stopCh:
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var resp *Response
+	retryManager := retry.NewManager(retry.Config{
+		InitialDelay: 1 * time.Second,
+		MaxDelay:     30 * time.Second,
+		Multiplier:   2.0,
+		Jitter:       0.1,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			e.logger.Warn("Failed to query KataGo version, retrying", "attempt", attempt, "delay", delay, "error", err)
+		},
+	})
+	err := retryManager.Run(ctx, func(ctx context.Context) error {
+		attemptCtx, cancel := context.WithTimeout(ctx, versionNegotiationTimeout)
+		defer cancel()
+		var err error
+		resp, err = e.sendQuery(attemptCtx, map[string]interface{}{"action": "query_version"})
+		return err
+	})
+	if err != nil {
+		return
+	}
+	version, _ := resp.Raw["version"].(string)
+	caps, err := CapabilitiesFor(version)
+	if err != nil {
+		e.logger.Warn("Unrecognized KataGo version", "version", version, "error", err)
+		return
+	}
+	caps.GitHash, _ = resp.Raw["git_hash"].(string)
+
+	var fingerprint string
+	if e.cache != nil && e.cache.IsEnabled() {
+		fingerprint = cacheFingerprint(e.config, caps.Version)
+	}
+
+	e.mu.Lock()
+	e.capabilities = caps
+	if fingerprint != "" {
+		e.setFingerprint(fingerprint)
+	}
+	e.mu.Unlock()
+
+	e.logger.Info("Negotiated KataGo protocol", "version", caps.Version, "unsupported", caps.Unsupported)
+	if e.prometheus != nil {
+		e.prometheus.RecordEngineStatus(true, caps.Version)
+	}
+}
+
+// Capabilities returns the protocol capabilities of the running KataGo.
+// They are unknown until the version has been negotiated after a start.
+func (e *Engine) Capabilities() Capabilities {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.capabilities
+}
+
 // Stop stops the KataGo process gracefully.
 func (e *Engine) Stop() error {
 	e.mu.Lock()
@@ -181,7 +445,7 @@ func (e *Engine) Stop() error {
 	// Send quit command if possible
 	if e.stdin != nil {
 		// Try to send quit command first for graceful shutdown
-		_, _ = e.stdin.Write([]byte(`{"id":"quit","action":"quit"}` + "\n"))
+		_ = e.writeLine([]byte(`{"id":"quit","action":"quit"}`))
 		_ = e.stdin.Close()
 	}
 
@@ -201,10 +465,12 @@ func (e *Engine) Stop() error {
 			e.logger.Warn("KataGo process exited with error", "error", err)
 		}
 	case <-time.After(10 * time.Second):
-		// Try SIGTERM first
+		// Ask the process to terminate first
 		if e.cmd != nil && e.cmd.Process != nil {
-			e.logger.Warn("KataGo not responding to quit, sending SIGTERM")
-			_ = e.cmd.Process.Signal(syscall.SIGTERM)
+			e.logger.Warn("KataGo not responding to quit, terminating")
+			if err := terminateProcess(e.cmd.Process); err != nil {
+				e.logger.Warn("Failed to terminate KataGo", "error", err)
+			}
 
 			// Wait a bit more
 			select {
@@ -213,20 +479,13 @@ func (e *Engine) Stop() error {
 			case <-time.After(5 * time.Second):
 				// Force kill if still not exited
 				e.logger.Warn("KataGo still running, force killing")
-				_ = e.cmd.Process.Kill()
+				_ = killProcess(e.cmd.Process)
 			}
 		}
 	}
 
 	// Cancel all pending queries
-	for id, ch := range e.pending {
-		ch <- &Response{
-			ID:    id,
-			Error: "engine stopped",
-		}
-		close(ch)
-	}
-	e.pending = make(map[string]chan *Response)
+	e.queries.closeAll("engine stopped")
 
 	e.logger.Info("KataGo engine stopped")
 	if e.prometheus != nil {
@@ -235,6 +494,30 @@ func (e *Engine) Stop() error {
 	return nil
 }
 
+// Drain stops the engine gracefully: new queries are refused at once, the
+// queries already accepted are given until ctx ends to finish, and then
+// KataGo is stopped, failing any still outstanding.
+func (e *Engine) Drain(ctx context.Context) error {
+	if !e.IsRunning() {
+		return nil
+	}
+	e.draining.Store(true)
+	defer e.draining.Store(false)
+
+	e.logger.Info("Draining KataGo engine", "outstanding", e.active.Load())
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for e.active.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			e.logger.Warn("Engine drain timed out, stopping with queries outstanding", "outstanding", e.active.Load())
+			return e.Stop()
+		case <-ticker.C:
+		}
+	}
+	return e.Stop()
+}
+
 // IsRunning returns whether the engine is running.
 func (e *Engine) IsRunning() bool {
 	e.mu.Lock()
@@ -242,6 +525,31 @@ func (e *Engine) IsRunning() bool {
 	return e.running
 }
 
+// ProcessID returns the KataGo process ID, or 0 if the engine is not running.
+func (e *Engine) ProcessID() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.running || e.cmd == nil || e.cmd.Process == nil {
+		return 0
+	}
+	return e.cmd.Process.Pid
+}
+
+// Load returns the number of queries waiting on KataGo.
+func (e *Engine) Load() EngineLoad {
+	pending := e.queries.waiting()
+	load := EngineLoad{
+		Pending:  pending,
+		Queued:   max(0, int(e.active.Load())-pending),
+		Lanes:    e.lanes.waitingByLane(),
+		Draining: e.draining.Load(),
+	}
+	if last := e.lastActivity.Load(); last != 0 && e.active.Load() == 0 {
+		load.IdleSeconds = time.Since(time.Unix(0, last)).Seconds()
+	}
+	return load
+}
+
 // configure sends initial configuration commands to KataGo.
 func (e *Engine) configure() {
 	// The analysis engine doesn't need initial configuration
@@ -250,14 +558,14 @@ func (e *Engine) configure() {
 	time.Sleep(500 * time.Millisecond)
 }
 
-// readStdout reads responses from KataGo.
-func (e *Engine) readStdout() {
+// readStdout reads responses from KataGo until stopCh is closed.
+func (e *Engine) readStdout(stdout *bufio.Reader, stopCh <-chan struct{}) {
 	for {
 		select {
-		case <-e.stopCh:
+		case <-stopCh:
 			return
 		default:
-			line, err := e.stdout.ReadString('\n')
+			line, err := stdout.ReadString('\n')
 			if err != nil {
 				if err != io.EOF {
 					e.logger.Error("Failed to read stdout", "error", err)
@@ -268,129 +576,201 @@ func (e *Engine) readStdout() {
 			if line == "" || line == "\n" {
 				continue
 			}
-
-			// Parse JSON response
-			var response Response
-			if err := json.Unmarshal([]byte(line), &response); err != nil {
-				e.logger.Warn("Failed to parse response", "line", line, "error", err)
-				continue
+			if e.recorder != nil {
+				e.recorder.Record(DirectionResponse, []byte(line))
 			}
-			e.logger.Debug("Received response", "id", response.ID, "hasError", response.Error != nil)
+			e.handleResponse(line)
+		}
+	}
+}
 
-			// Also unmarshal into raw map for debugging
-			_ = json.Unmarshal([]byte(line), &response.Raw)
+// handleResponse parses a line KataGo printed and delivers it to the query
+// waiting for it. A response that does not match the expected format fails
+// its query at once rather than being misread or left to time out.
+func (e *Engine) handleResponse(line string) {
+	response, issues, err := parseResponse(line)
+	e.reportSchemaIssues(issues, line)
+	if err != nil {
+		e.logger.Warn("Failed to parse response", "line", line, "error", err)
+		e.deliverResponse(response)
+		return
+	}
+	e.logger.Debug("Received response", "id", response.ID, "hasError", response.Error != nil)
 
-			// Handle health check responses
-			if response.ID == "health" {
-				select {
-				case e.healthCheck <- struct{}{}:
-				default:
-				}
-				continue
-			}
+	// Answering a query shows KataGo has started, even if its
+	// stderr did not say so
+	e.recordStartupLine("Started, ready to begin handling requests")
+	e.deliverResponse(response)
+}
 
-			// Skip startup responses that we're not waiting for
-			if response.ID == "startup" {
-				e.logger.Debug("Received startup response, ignoring")
-				continue
-			}
+// parseResponse parses a line KataGo printed, returning the schema issues
+// found in it. A line that is not a well-formed response is returned as a
+// response failing its query, with the error.
+func parseResponse(line string) (*Response, []SchemaIssue, error) {
+	var response Response
+	if err := json.Unmarshal([]byte(line), &response.Raw); err != nil {
+		return &Response{ID: responseID(line), Error: fmt.Sprintf("malformed response: %v", err)},
+			[]SchemaIssue{{Issue: SchemaInvalidJSON}}, err
+	}
+	issues := ValidateResponse(response.Raw)
 
-			// Send to waiting channel
-			e.mu.Lock()
-			if ch, ok := e.pending[response.ID]; ok {
-				ch <- &response
-				close(ch)
-				delete(e.pending, response.ID)
-			} else if response.ID != "" {
-				// This can happen during shutdown when responses arrive after cleanup
-				e.logger.Debug("Received response for unknown query", "id", response.ID)
-			}
-			e.mu.Unlock()
+	if err := json.Unmarshal([]byte(line), &response); err != nil {
+		id, _ := response.Raw["id"].(string)
+		return &Response{ID: id, Error: fmt.Sprintf("malformed response: %v", err), Raw: response.Raw}, issues, err
+	}
+	return &response, issues, nil
+}
+
+// deliverResponse sends a response to the query waiting for it.
+func (e *Engine) deliverResponse(response *Response) {
+	// Skip startup responses that we're not waiting for
+	if response.ID == "startup" {
+		e.logger.Debug("Received startup response, ignoring")
+		return
+	}
+
+	e.queries.deliver(response)
+}
+
+// recordOrphan accounts for a response that reached no waiting query.
+func (e *Engine) recordOrphan(id, reason string) {
+	// Late responses are expected after a query is abandoned or the
+	// engine stops
+	e.logger.Debug("Received response for no waiting query", "id", id, "reason", reason)
+	if e.prometheus != nil {
+		e.prometheus.RecordOrphanResponse(reason)
+	}
+}
+
+// reportSchemaIssues counts the ways a response differs from the expected
+// format and warns the first time each one is seen, since a drift in
+// KataGo's output usually repeats on every response.
+func (e *Engine) reportSchemaIssues(issues []SchemaIssue, line string) {
+	for _, issue := range issues {
+		if e.prometheus != nil {
+			e.prometheus.RecordResponseSchemaIssue(issue.Issue, issue.Field)
+		}
+		if _, seen := e.schemaDrift.LoadOrStore(issue, struct{}{}); !seen {
+			e.logger.Warn("KataGo response format drifted from what is expected",
+				"issue", issue.Issue, "field", issue.Field, "line", strings.TrimSpace(line))
 		}
 	}
 }
 
-// readStderr logs stderr output.
-func (e *Engine) readStderr() {
-	scanner := bufio.NewScanner(e.stderr)
+// readStderr logs stderr output until stopCh is closed.
+func (e *Engine) readStderr(stderr *bufio.Reader, stopCh <-chan struct{}) {
+	scanner := bufio.NewScanner(stderr)
 	for scanner.Scan() {
 		select {
-		case <-e.stopCh:
+		case <-stopCh:
 			return
 		default:
 			line := scanner.Text()
 			if line != "" {
+				e.stderrTail.add(line)
 				e.logger.Debug("KataGo stderr", "line", line)
+				e.recordStartupLine(line)
 			}
 		}
 	}
 }
 
-// healthCheckRoutine periodically checks if the engine is responsive.
-func (e *Engine) healthCheckRoutine() {
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-e.stopCh:
-			return
-		case <-ticker.C:
-			// Send a simple query to check if engine is responsive
-			query := map[string]interface{}{
-				"id":     "health",
-				"action": "query_version",
-			}
+// recordStartupLine updates startup progress from a line of stderr and logs
+// each step, so a slow start is visibly not a hang.
+func (e *Engine) recordStartupLine(line string) {
+	e.startupMu.Lock()
+	if e.startup.Phase == "" || !parseStartupLine(&e.startup, line) {
+		e.startupMu.Unlock()
+		return
+	}
+	if e.startup.Ready() {
+		e.startup.ElapsedSeconds = time.Since(e.startup.StartedAt).Seconds()
+		if e.startupReady != nil {
+			close(e.startupReady)
+			e.startupReady = nil
+		}
+	}
+	progress := e.startup
+	e.startupMu.Unlock()
 
-			data, _ := json.Marshal(query)
-			e.mu.Lock()
-			if e.running && e.stdin != nil {
-				_, _ = fmt.Fprintf(e.stdin, "%s\n", data)
-			}
-			e.mu.Unlock()
+	e.logger.Info("KataGo startup progress",
+		"phase", progress.Phase,
+		"percent", progress.Percent,
+		"backend", progress.Backend,
+		"gpu", progress.GPU,
+	)
+}
 
-			// Wait for response
-			select {
-			case <-e.healthCheck:
-				// Healthy
-			case <-time.After(5 * time.Second):
-				e.logger.Error("KataGo health check timeout")
-				// Could implement auto-restart here
-			}
-		}
+// StartupProgress returns how far KataGo has got since it was last
+// started. The phase is empty before the first start.
+func (e *Engine) StartupProgress() StartupProgress {
+	e.startupMu.Lock()
+	defer e.startupMu.Unlock()
+	progress := e.startup
+	if progress.Phase != "" && !progress.Ready() {
+		progress.ElapsedSeconds = time.Since(progress.StartedAt).Seconds()
 	}
+	return progress
 }
 
 // sendQueryWithCache sends a query to KataGo with caching support.
-func (e *Engine) sendQueryWithCache(query map[string]interface{}) (*Response, error) {
+// Cached results are keyed by position, and served when they were computed
+// with at least as many visits as the query requests.
+func (e *Engine) sendQueryWithCache(ctx context.Context, query map[string]interface{}) (*Response, error) {
 	// Check if caching is enabled and this is a cacheable query
 	if e.cache != nil && e.cache.IsEnabled() {
 		// Generate cache key
-		cacheKey, err := e.cache.CacheKey(query)
+		cacheKey, err := e.cache.PositionKey(query)
 		if err == nil {
+			cacheKey = e.cacheKey(ctx, cacheKey)
+			visits := e.requestedVisits(query)
+
 			// Try to get from cache
-			if cached, ok := e.cache.Get(cacheKey); ok {
+			_, lookupSpan := tracing.StartSpan(ctx, "cache.lookup", attribute.Int("visits", visits))
+			cached, cachedVisits, found := e.cache.Lookup(cacheKey)
+			lookupSpan.SetAttributes(
+				attribute.Bool("cache.found", found),
+				attribute.Int("cache.visits", cachedVisits),
+			)
+			lookupSpan.End()
+
+			if found {
 				if resp, ok := cached.(*Response); ok {
-					e.logger.Debug("Cache hit", "key", cacheKey)
-					if e.prometheus != nil {
-						e.prometheus.RecordCacheHit()
+					if cachedVisits >= visits {
+						e.logger.Debug("Cache hit", "key", cacheKey, "visits", visits, "cachedVisits", cachedVisits)
+						if e.prometheus != nil {
+							e.prometheus.RecordCacheHit()
+						}
+						UsageFromContext(ctx).recordCacheHit(resp)
+						return resp, nil
+					}
+
+					if e.cache.AsyncRefreshEnabled() {
+						e.logger.Debug("Serving shallower cached result while refreshing",
+							"key", cacheKey, "visits", visits, "cachedVisits", cachedVisits)
+						if e.prometheus != nil {
+							e.prometheus.RecordCacheHit()
+						}
+						UsageFromContext(ctx).recordCacheHit(resp)
+						e.refreshCacheAsync(ctx, cacheKey, query, visits)
+						return resp, nil
 					}
-					return resp, nil
 				}
 			}
 			if e.prometheus != nil {
 				e.prometheus.RecordCacheMiss()
 			}
+			UsageFromContext(ctx).recordCacheMiss()
 
 			// Not in cache, execute query
-			resp, queryErr := e.sendQuery(query)
+			resp, queryErr := e.sendQueryShared(ctx, query)
 			if queryErr != nil {
 				return nil, queryErr
 			}
 
 			// Cache the successful response
 			size := cache.EstimateSize(resp)
-			e.cache.Put(cacheKey, resp, size)
+			e.cache.PutForVisits(cacheKey, resp, visits, size)
 
 			return resp, nil
 		} else {
@@ -399,21 +779,136 @@ func (e *Engine) sendQueryWithCache(query map[string]interface{}) (*Response, er
 	}
 
 	// No caching, just send query
-	return e.sendQuery(query)
+	return e.sendQueryShared(ctx, query)
+}
+
+// sendQueryShared sends a query to KataGo, sharing a single engine call
+// between identical queries that are in flight at the same time in the
+// same lane.
+func (e *Engine) sendQueryShared(ctx context.Context, query map[string]interface{}) (*Response, error) {
+	key, err := sharedQueryKey(ctx, query)
+	if err != nil {
+		e.logger.Warn("Failed to generate query key", "error", err)
+		return e.sendQuery(ctx, query)
+	}
+
+	resp, shared, err := e.inflight.Do(ctx, key, func(ctx context.Context) (*Response, error) {
+		return e.sendQuery(ctx, query)
+	})
+	if shared {
+		UsageFromContext(ctx).recordShared(resp)
+		trace.SpanFromContext(ctx).AddEvent("joined in-flight query")
+		e.logger.Debug("Joined in-flight query", "key", key)
+	}
+	return resp, err
+}
+
+// sharedQueryKey returns the key under which identical in-flight queries
+// share an engine call. It includes the lane, since the shared call is
+// scheduled in the lane of the caller that started it, so that an
+// interactive query is never held behind a background one.
+func sharedQueryKey(ctx context.Context, query map[string]interface{}) (string, error) {
+	key, err := queryKey(query)
+	if err != nil {
+		return "", err
+	}
+	return LaneFromContext(ctx) + "/" + namespacedKey(ctx, key), nil
+}
+
+// requestedVisits returns the visit budget a query will be analyzed with.
+func (e *Engine) requestedVisits(query map[string]interface{}) int {
+	if visits, ok := query["maxVisits"].(int); ok && visits > 0 {
+		return visits
+	}
+	return e.config.MaxVisits
+}
+
+// refreshCacheAsync deepens a cache entry in the background. Only one
+// refresh per cache key runs at a time.
+func (e *Engine) refreshCacheAsync(ctx context.Context, cacheKey string, query map[string]interface{}, visits int) {
+	e.mu.Lock()
+	if _, busy := e.refreshing[cacheKey]; busy {
+		e.mu.Unlock()
+		return
+	}
+	e.refreshing[cacheKey] = struct{}{}
+	e.mu.Unlock()
+
+	// Copy the query since sendQuery assigns it an ID
+	refreshQuery := make(map[string]interface{}, len(query))
+	for k, v := range query {
+		refreshQuery[k] = v
+	}
+
+	// Keep the trace but not the cancellation or resource accounting of the
+	// triggering request
+	refreshCtx := WithLane(withoutUsage(context.WithoutCancel(ctx)), config.LaneBackground)
+
+	go func() {
+		defer func() {
+			e.mu.Lock()
+			delete(e.refreshing, cacheKey)
+			e.mu.Unlock()
+		}()
+
+		resp, err := e.sendQuery(refreshCtx, refreshQuery)
+		if err != nil {
+			e.logger.Warn("Background cache refresh failed", "key", cacheKey, "error", err)
+			return
+		}
+		e.cache.PutForVisits(cacheKey, resp, visits, cache.EstimateSize(resp))
+		e.logger.Debug("Background cache refresh completed", "key", cacheKey, "visits", visits)
+	}()
 }
 
 // sendQuery sends a query to KataGo and waits for response.
-func (e *Engine) sendQuery(query map[string]interface{}) (*Response, error) {
+func (e *Engine) sendQuery(ctx context.Context, query map[string]interface{}) (*Response, error) {
 	start := time.Now()
 	queryType := "unknown"
 	if action, ok := query["action"].(string); ok {
 		queryType = action
 	}
+	e.active.Add(1)
+	e.lastActivity.Store(start.UnixNano())
+	defer func() {
+		e.lastActivity.Store(time.Now().UnixNano())
+		e.active.Add(-1)
+	}()
+	if e.draining.Load() {
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine is draining")
+	}
 
+	// Wait for a slot in the query's lane, then for the engine
+	lane := LaneFromContext(ctx)
+	_, waitSpan := tracing.StartSpan(ctx, "engine.queue_wait", attribute.String("katago.lane", lane))
+	releaseSlot, err := e.lanes.acquire(ctx, lane)
+	if err != nil {
+		waitSpan.End()
+		return nil, apperrors.Wrap(apperrors.CodeOf(err), err, "query abandoned in the %s lane", lane)
+	}
+	defer releaseSlot()
 	e.mu.Lock()
+	waitSpan.End()
+	queueWait := time.Since(start)
+	if e.prometheus != nil {
+		e.prometheus.RecordLaneWait(lane, queueWait.Seconds())
+	}
 	if !e.running {
 		e.mu.Unlock()
-		return nil, fmt.Errorf("engine not running")
+		return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+
+	// Adapt the query to the protocol of the running KataGo release
+	caps := e.capabilities
+	if caps.Known() {
+		dropped, err := caps.adaptQuery(query)
+		if err != nil {
+			e.mu.Unlock()
+			return nil, err
+		}
+		if len(dropped) > 0 {
+			e.logger.Debug("Dropped fields unsupported by KataGo", "version", caps.Version, "fields", dropped)
+		}
 	}
 
 	// Generate query ID
@@ -421,86 +916,225 @@ func (e *Engine) sendQuery(query map[string]interface{}) (*Response, error) {
 	id := fmt.Sprintf("q%d", e.queryID)
 	query["id"] = id
 
-	// Create response channel
-	respCh := make(chan *Response, 1)
-	e.pending[id] = respCh
+	// Wait for the response until the query times out
+	timeout := time.Duration(e.config.MaxTime*2) * time.Second
+	queryCtx, respCh, release := e.queries.register(ctx, id, timeout)
+	defer release()
+
+	_, span := tracing.StartSpan(ctx, "katago.query",
+		attribute.String("katago.query_id", id),
+		attribute.String("katago.query_type", queryType),
+	)
+	defer span.End()
 
 	// Marshal and send query
 	data, err := json.Marshal(query)
 	if err != nil {
-		delete(e.pending, id)
 		e.mu.Unlock()
+		tracing.RecordError(span, err)
 		return nil, fmt.Errorf("failed to marshal query: %w", err)
 	}
 
-	if _, err := fmt.Fprintf(e.stdin, "%s\n", data); err != nil {
-		delete(e.pending, id)
+	if err := e.writeLine(data); err != nil {
 		e.mu.Unlock()
-		return nil, fmt.Errorf("failed to send query: %w", err)
+		tracing.RecordError(span, err)
+		return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to send query")
 	}
 	e.logger.Debug("Sent query", "id", id, "query", string(data))
+	pid := 0
+	if e.cmd != nil && e.cmd.Process != nil {
+		pid = e.cmd.Process.Pid
+	}
+	sent := time.Now()
 	e.mu.Unlock()
 
-	// Wait for response with timeout
+	var resp *Response
 	select {
-	case resp := <-respCh:
+	case resp = <-respCh:
+	case <-queryCtx.Done():
+		// A response may have arrived as the query expired
+		select {
+		case resp = <-respCh:
+		default:
+		}
+	}
+
+	switch {
+	case resp != nil:
 		if e.prometheus != nil {
 			e.prometheus.RecordEngineQuery(queryType, time.Since(start).Seconds())
 		}
 		if resp.Error != nil {
-			switch v := resp.Error.(type) {
-			case string:
-				return nil, fmt.Errorf("KataGo error: %s", v)
-			case map[string]interface{}:
-				if msg, ok := v["message"].(string); ok {
-					return nil, fmt.Errorf("KataGo error: %s", msg)
-				}
-			case *ErrorResponse:
-				return nil, fmt.Errorf("KataGo error: %s", v.Message)
-			}
-			return nil, fmt.Errorf("KataGo error: %v", resp.Error)
+			e.logSlowQuery(id, lane, data, SlowQueryError, nil, start, sent)
+			UsageFromContext(ctx).recordQuery(nil, queueWait, time.Since(sent), pid)
+			err := responseError(resp.Error)
+			tracing.RecordError(span, err)
+			return nil, err
 		}
+		caps.adaptResponse(resp)
+		e.logSlowQuery(id, lane, data, SlowQueryOK, resp, start, sent)
+		UsageFromContext(ctx).recordQuery(resp, queueWait, time.Since(sent), pid)
 		return resp, nil
-	case <-time.After(time.Duration(e.config.MaxTime*2) * time.Second):
+	case ctx.Err() != nil:
+		e.logSlowQuery(id, lane, data, SlowQueryAbandoned, nil, start, sent)
 		e.mu.Lock()
-		delete(e.pending, id)
+		// Stop KataGo from spending more time on an abandoned query
+		if e.running && e.stdin != nil {
+			e.queries.ignore(id + "-terminate")
+			_ = e.writeLine([]byte(fmt.Sprintf(`{"id":"%s-terminate","action":"terminate","terminateId":"%s"}`, id, id)))
+		}
 		e.mu.Unlock()
+		e.logger.Warn("Query abandoned", "id", id, "error", ctx.Err())
+		err := apperrors.Wrap(apperrors.CodeOf(ctx.Err()), ctx.Err(), "query %s abandoned", id)
+		tracing.RecordError(span, err)
+		return nil, err
+	default:
+		e.logSlowQuery(id, lane, data, SlowQueryTimeout, nil, start, sent)
 		e.logger.Error("Query timeout", "id", id, "timeout", e.config.MaxTime*2)
-		return nil, fmt.Errorf("query timeout after %.1f seconds", e.config.MaxTime*2)
+		err := apperrors.New(apperrors.CodeTimeout, "query timeout after %.1f seconds", e.config.MaxTime*2)
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+}
+
+// writeLine sends a line of the protocol to KataGo, recording it when
+// recording is enabled. The caller must hold e.mu.
+func (e *Engine) writeLine(data []byte) error {
+	if e.recorder != nil {
+		e.recorder.Record(DirectionQuery, data)
+	}
+	e.recentQueries.Record(DirectionQuery, data)
+	_, err := e.stdin.Write(append(data, '\n'))
+	return err
+}
+
+// Recording returns the raw lines exchanged with KataGo, oldest first, or
+// nil when recording is disabled.
+func (e *Engine) Recording() []RecordedLine {
+	if e.recorder == nil {
+		return nil
+	}
+	return e.recorder.Lines()
+}
+
+// responseError converts the error field of a KataGo response to an error.
+func responseError(respErr interface{}) error {
+	switch v := respErr.(type) {
+	case string:
+		return fmt.Errorf("KataGo error: %s", v)
+	case map[string]interface{}:
+		if msg, ok := v["message"].(string); ok {
+			return fmt.Errorf("KataGo error: %s", msg)
+		}
+	case *ErrorResponse:
+		return fmt.Errorf("KataGo error: %s", v.Message)
 	}
+	return fmt.Errorf("KataGo error: %v", respErr)
 }
 
-// Ping checks if the engine is responsive.
+// Ping checks that the engine's process is alive and, once KataGo has
+// started, that it answers a tiny analysis query within the configured
+// health check timeout. While KataGo is still loading its model queries
+// wait for it, so only the process is checked.
 func (e *Engine) Ping(ctx context.Context) error {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+	err := e.ping(ctx)
+	if e.prometheus != nil {
+		e.prometheus.RecordEngineHealthCheck(err == nil)
+	}
+	return err
+}
 
+// ping checks the engine for Ping.
+func (e *Engine) ping(ctx context.Context) error {
+	e.mu.Lock()
 	if !e.running {
-		if e.prometheus != nil {
-			e.prometheus.RecordEngineHealthCheck(false)
-		}
-		return fmt.Errorf("engine not running")
+		e.mu.Unlock()
+		return apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
 	}
 
 	// Check if the process is still alive
-	if e.cmd != nil && e.cmd.Process != nil {
-		// Try to check process state without killing it
-		// On Unix, sending signal 0 checks if process exists
-		if err := e.cmd.Process.Signal(syscall.Signal(0)); err != nil {
-			if e.prometheus != nil {
-				e.prometheus.RecordEngineHealthCheck(false)
-			}
-			return fmt.Errorf("engine process not responding: %w", err)
+	if e.cmd == nil || e.cmd.Process == nil {
+		e.mu.Unlock()
+		return apperrors.New(apperrors.CodeEngineUnavailable, "engine process not found")
+	}
+	// Check process state without affecting it
+	if err := processAlive(e.cmd.Process); err != nil {
+		e.mu.Unlock()
+		return apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "engine process not responding")
+	}
+	e.mu.Unlock()
+
+	if !e.StartupProgress().Ready() {
+		return nil
+	}
+	return e.probe(ctx)
+}
+
+// healthQueryPriority is the KataGo priority of health check queries, so
+// that they are searched ahead of any analysis waiting in KataGo.
+const healthQueryPriority = 1000
+
+// probe sends KataGo a health check query, the empty 9x9 board searched
+// with a single visit, which its neural network cache answers at once
+// after the first time. It bypasses the scheduling lanes and the analysis
+// cache, so that only a KataGo that stopped answering fails it.
+func (e *Engine) probe(ctx context.Context) error {
+	timeout := e.config.HealthCheck.Timeout()
+	e.mu.Lock()
+	if !e.running || e.stdin == nil {
+		e.mu.Unlock()
+		return apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+	}
+	e.queryID++
+	id := fmt.Sprintf("health%d", e.queryID)
+	data, err := json.Marshal(map[string]interface{}{
+		"id":         id,
+		"rules":      "chinese",
+		"komi":       7,
+		"boardXSize": 9,
+		"boardYSize": 9,
+		"moves":      [][]string{},
+		"maxVisits":  1,
+		"priority":   healthQueryPriority,
+	})
+	if err != nil {
+		e.mu.Unlock()
+		return fmt.Errorf("failed to marshal health check query: %w", err)
+	}
+	queryCtx, respCh, release := e.queries.register(ctx, id, timeout)
+	defer release()
+	if err := e.writeLine(data); err != nil {
+		e.mu.Unlock()
+		return apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to send health check query")
+	}
+	e.mu.Unlock()
+
+	var resp *Response
+	select {
+	case resp = <-respCh:
+	case <-queryCtx.Done():
+		// A response may have arrived as the query expired
+		select {
+		case resp = <-respCh:
+		default:
 		}
-	} else {
-		if e.prometheus != nil {
-			e.prometheus.RecordEngineHealthCheck(false)
+	}
+	if resp != nil {
+		if resp.Error != nil {
+			return apperrors.Wrap(apperrors.CodeEngineUnavailable, responseError(resp.Error), "health check query failed")
 		}
-		return fmt.Errorf("engine process not found")
+		return nil
 	}
 
-	if e.prometheus != nil {
-		e.prometheus.RecordEngineHealthCheck(true)
+	// Stop KataGo from spending more time on the query
+	e.mu.Lock()
+	if e.running && e.stdin != nil {
+		e.queries.ignore(id + "-terminate")
+		_ = e.writeLine([]byte(fmt.Sprintf(`{"id":"%s-terminate","action":"terminate","terminateId":"%s"}`, id, id)))
 	}
-	return nil
+	e.mu.Unlock()
+	if ctx.Err() != nil {
+		return apperrors.Wrap(apperrors.CodeEngineUnavailable, ctx.Err(), "engine did not answer a health check query")
+	}
+	return apperrors.New(apperrors.CodeEngineUnavailable, "engine did not answer a health check query within %s", timeout)
 }
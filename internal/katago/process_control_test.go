@@ -0,0 +1,190 @@
+package katago
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// TestHelperProcess stands in for KataGo in process control tests. It is
+// run as a child of the test binary and does nothing when run as a test.
+func TestHelperProcess(t *testing.T) {
+	switch os.Getenv("KATAGO_MCP_HELPER_PROCESS") {
+	case "exit-on-eof":
+		// Exits when stdin closes, like KataGo after a quit
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		os.Exit(0)
+	case "sleep":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	case "analyzer":
+		// Answers like an analyzer plugin, naming each move; passes are
+		// an error and tengen is never answered
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			var move MoveContext
+			_ = json.Unmarshal(scanner.Bytes(), &move)
+			switch move.Move {
+			case "":
+				fmt.Println(`{"error": "cannot analyze a pass"}`)
+			case "K10":
+				time.Sleep(time.Minute)
+			default:
+				fmt.Printf(`{"findings": [{"category": "seen", "explanation": %q}]}`+"\n", move.Move)
+			}
+		}
+		os.Exit(0)
+	}
+}
+
+// startHelperProcess starts the test binary as a stand-in KataGo process.
+func startHelperProcess(t *testing.T, mode string) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$") // #nosec G204 -- the test binary itself
+	cmd.Env = append(os.Environ(), "KATAGO_MCP_HELPER_PROCESS="+mode)
+	configureProcess(cmd)
+	return cmd
+}
+
+func TestProcessControl(t *testing.T) {
+	cmd := startHelperProcess(t, "sleep")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start helper process: %v", err)
+	}
+	defer func() { _ = killProcess(cmd.Process) }()
+
+	if err := processAlive(cmd.Process); err != nil {
+		t.Fatalf("Expected running process to be alive: %v", err)
+	}
+
+	if err := terminateProcess(cmd.Process); err != nil {
+		t.Fatalf("Failed to terminate process: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Process did not exit after termination")
+	}
+
+	if err := processAlive(cmd.Process); err == nil {
+		t.Error("Expected exited process to be reported dead")
+	}
+}
+
+func TestKillProcess(t *testing.T) {
+	cmd := startHelperProcess(t, "sleep")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start helper process: %v", err)
+	}
+	if err := killProcess(cmd.Process); err != nil {
+		t.Fatalf("Failed to kill process: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Process did not exit after kill")
+	}
+}
+
+// TestEngineStopAndPingWithHelperProcess checks engine lifecycle handling
+// against a stand-in process rather than a real KataGo install.
+func TestEngineStopAndPingWithHelperProcess(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := NewEngine(&config.KataGoConfig{MaxTime: 1}, logger, nil)
+
+	cmd := startHelperProcess(t, "exit-on-eof")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start helper process: %v", err)
+	}
+	engine.cmd = cmd
+	engine.stdin = stdin
+	engine.stdout = bufio.NewReader(io.LimitReader(nil, 0))
+	engine.running = true
+
+	if err := engine.Ping(context.Background()); err != nil {
+		t.Errorf("Expected running helper to answer ping: %v", err)
+	}
+	if pid := engine.ProcessID(); pid != cmd.Process.Pid {
+		t.Errorf("Expected process ID %d, got %d", cmd.Process.Pid, pid)
+	}
+
+	start := time.Now()
+	if err := engine.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected graceful stop, took %v", elapsed)
+	}
+	if err := engine.Ping(context.Background()); err == nil {
+		t.Error("Expected ping to fail after stop")
+	}
+}
+
+// TestEnginePingUnansweredQuery checks that a started KataGo whose process
+// is alive but which no longer answers queries fails the health check.
+func TestEnginePingUnansweredQuery(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	cfg := &config.KataGoConfig{MaxTime: 1, HealthCheck: config.HealthCheckConfig{TimeoutSeconds: 0.2}}
+	engine := NewEngine(cfg, logger, nil)
+
+	cmd := startHelperProcess(t, "exit-on-eof")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start helper process: %v", err)
+	}
+	engine.cmd = cmd
+	engine.stdin = stdin
+	engine.stdout = bufio.NewReader(io.LimitReader(nil, 0))
+	engine.running = true
+	defer func() { _ = engine.Stop() }()
+
+	// While KataGo is loading, only the process is checked
+	engine.startup = StartupProgress{Phase: PhaseLoadingModel, StartedAt: time.Now()}
+	if err := engine.Ping(context.Background()); err != nil {
+		t.Errorf("Expected a starting engine to pass: %v", err)
+	}
+
+	engine.startup.Phase = PhaseReady
+	start := time.Now()
+	err = engine.Ping(context.Background())
+	if apperrors.CodeOf(err) != apperrors.CodeEngineUnavailable {
+		t.Fatalf("Expected %s for an unanswered health check, got %v", apperrors.CodeEngineUnavailable, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the health check timeout to bound the ping, took %v", elapsed)
+	}
+	lines := engine.recentQueries.Lines()
+	if len(lines) != 2 || !strings.Contains(string(lines[0].Data), `"priority":1000`) ||
+		!strings.Contains(string(lines[1].Data), `"action":"terminate"`) {
+		t.Errorf("Expected the health check query and its termination, got %+v", lines)
+	}
+}
@@ -2,8 +2,11 @@ package katago
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/dmmcquay/katago-mcp/internal/cache"
 	"github.com/dmmcquay/katago-mcp/internal/config"
 	"github.com/dmmcquay/katago-mcp/internal/logging"
 )
@@ -82,3 +85,246 @@ func TestEngineIsRunning(t *testing.T) {
 		t.Error("Engine should not be running after setting running=false")
 	}
 }
+
+// TestSendQueryWithCacheVisitTiers tests that cached results are only served
+// when they were computed with enough visits.
+func TestSendQueryWithCacheVisitTiers(t *testing.T) {
+	cfg := &config.KataGoConfig{
+		BinaryPath: "katago",
+		MaxVisits:  100,
+		MaxTime:    1.0,
+	}
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	cacheManager := cache.NewManager(&config.CacheConfig{Enabled: true, MaxItems: 10}, logger)
+	engine := NewEngine(cfg, logger, cacheManager)
+
+	query := map[string]interface{}{
+		"rules":      "chinese",
+		"boardXSize": 19,
+		"boardYSize": 19,
+		"moves":      [][]interface{}{},
+		"maxVisits":  200,
+	}
+	key, err := cacheManager.PositionKey(query)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	cached := &Response{ID: "cached"}
+	cacheManager.PutForVisits(key, cached, 200, 100)
+
+	// Same or fewer visits are served from cache without touching the engine
+	resp, err := engine.sendQueryWithCache(context.Background(), query)
+	if err != nil || resp != cached {
+		t.Fatalf("Expected cached response, got %v, %v", resp, err)
+	}
+	delete(query, "maxVisits") // Falls back to config MaxVisits (100)
+	resp, err = engine.sendQueryWithCache(context.Background(), query)
+	if err != nil || resp != cached {
+		t.Fatalf("Expected cached response for default visits, got %v, %v", resp, err)
+	}
+
+	// More visits than cached requires a real query
+	query["maxVisits"] = 1000
+	if _, err := engine.sendQueryWithCache(context.Background(), query); err == nil {
+		t.Error("Expected deeper request to miss the cache and hit the stopped engine")
+	}
+}
+
+// TestSendQueryWithCacheNamespace tests that a cache namespace keeps its
+// entries apart from those of other namespaces.
+func TestSendQueryWithCacheNamespace(t *testing.T) {
+	cfg := &config.KataGoConfig{
+		BinaryPath: "katago",
+		MaxVisits:  100,
+		MaxTime:    1.0,
+	}
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	cacheManager := cache.NewManager(&config.CacheConfig{Enabled: true, MaxItems: 10}, logger)
+	engine := NewEngine(cfg, logger, cacheManager)
+
+	query := map[string]interface{}{
+		"rules":      "chinese",
+		"boardXSize": 19,
+		"boardYSize": 19,
+		"moves":      [][]interface{}{},
+	}
+	key, err := cacheManager.PositionKey(query)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	alice := WithCacheNamespace(context.Background(), "team:alice")
+	cached := &Response{ID: "alice"}
+	cacheManager.PutForVisits(namespacedKey(alice, key), cached, 100, 100)
+
+	resp, err := engine.sendQueryWithCache(alice, query)
+	if err != nil || resp != cached {
+		t.Fatalf("Expected the namespace's cached response, got %v, %v", resp, err)
+	}
+	for _, ctx := range []context.Context{context.Background(), WithCacheNamespace(context.Background(), "team:bob")} {
+		if _, err := engine.sendQueryWithCache(ctx, query); err == nil {
+			t.Error("Expected another namespace to miss the cache and hit the stopped engine")
+		}
+	}
+}
+
+// TestSendQueryRecordsUsage verifies that cache lookups are accounted to the request's usage.
+func TestSendQueryRecordsUsage(t *testing.T) {
+	cfg := &config.KataGoConfig{
+		BinaryPath: "katago",
+		MaxVisits:  100,
+		MaxTime:    1.0,
+	}
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	cacheManager := cache.NewManager(&config.CacheConfig{Enabled: true, MaxItems: 10}, logger)
+	engine := NewEngine(cfg, logger, cacheManager)
+
+	query := map[string]interface{}{
+		"rules":      "chinese",
+		"boardXSize": 19,
+		"boardYSize": 19,
+		"moves":      [][]interface{}{},
+		"maxVisits":  100,
+	}
+	key, err := cacheManager.PositionKey(query)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	cacheManager.PutForVisits(key, &Response{ID: "cached", RootInfo: RootInfo{Visits: 120}}, 100, 100)
+
+	ctx, usage := WithUsage(context.Background())
+	if _, err := engine.sendQueryWithCache(ctx, query); err != nil {
+		t.Fatalf("Expected cached response, got %v", err)
+	}
+	query["maxVisits"] = 500
+	_, _ = engine.sendQueryWithCache(ctx, query)
+
+	stats := usage.Stats()
+	if stats.CacheHits != 1 || stats.CacheMisses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %d hits and %d misses", stats.CacheHits, stats.CacheMisses)
+	}
+	if stats.Visits != 120 {
+		t.Errorf("Expected 120 visits used, got %d", stats.Visits)
+	}
+	if stats.EngineVisits != 0 {
+		t.Errorf("Expected cached visits not to count as engine visits, got %d", stats.EngineVisits)
+	}
+
+	// A nil usage is safe to record into
+	var none *Usage
+	none.recordCacheMiss()
+	if got := none.Stats(); got != (UsageStats{}) {
+		t.Errorf("Expected empty stats from nil usage, got %+v", got)
+	}
+}
+
+// TestResponseCodec verifies that responses keep their raw fields through a
+// shared cache backend.
+func TestResponseCodec(t *testing.T) {
+	line := `{"id":"q1","turnNumber":3,"moveInfos":[{"move":"D4","visits":10,"winrate":0.6,"order":0}],` +
+		`"rootInfo":{"visits":10,"winrate":0.55,"currentPlayer":"B"},"ownership":[0.5,-0.5]}`
+	var original Response
+	if err := json.Unmarshal([]byte(line), &original); err != nil {
+		t.Fatal(err)
+	}
+	_ = json.Unmarshal([]byte(line), &original.Raw)
+
+	data, err := ResponseCodec.Encode(&original)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	decoded, err := ResponseCodec.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	resp := decoded.(*Response)
+	if resp.TurnNumber != 3 || len(resp.MoveInfos) != 1 || resp.MoveInfos[0].Move != "D4" || resp.RootInfo.Winrate != 0.55 {
+		t.Errorf("Unexpected decoded response: %+v", resp)
+	}
+	if ownership, ok := resp.Raw["ownership"].([]interface{}); !ok || len(ownership) != 2 {
+		t.Errorf("Expected ownership in raw fields, got %v", resp.Raw["ownership"])
+	}
+
+	if _, err := ResponseCodec.Encode("not a response"); err == nil {
+		t.Error("Expected error encoding a non-response value")
+	}
+}
+
+// TestHandleResponse verifies that malformed responses fail their queries
+// instead of being misread or left to time out.
+func TestHandleResponse(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := NewEngine(&config.KataGoConfig{BinaryPath: "katago"}, logger, nil)
+
+	tests := []struct {
+		name    string
+		line    string
+		wantErr bool
+	}{
+		{"expected format", `{"id":"q1","turnNumber":0,"rootInfo":{"winrate":0.5},"newField":1}`, false},
+		{"malformed number", `{"id":"q1","turnNumber":0,"rootInfo":{"winrate":"0.5"}}`, true},
+		{"invalid JSON", `{"id":"q1","turnNumber":0,"rootInfo":{"winrate":nan}}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ch, release := engine.queries.register(context.Background(), "q1", time.Minute)
+			defer release()
+
+			engine.handleResponse(tt.line + "\n")
+			select {
+			case resp := <-ch:
+				if (resp.Error != nil) != tt.wantErr {
+					t.Errorf("Expected error %v, got %v", tt.wantErr, resp.Error)
+				}
+			default:
+				t.Fatal("Expected the response delivered to the waiting query")
+			}
+		})
+	}
+
+	// Each drift is remembered so it is warned about once
+	if _, seen := engine.schemaDrift.Load(SchemaIssue{Issue: SchemaUnknownField, Field: "newField"}); !seen {
+		t.Error("Expected the unknown field recorded")
+	}
+}
+
+// TestEngineDrain verifies that a draining engine refuses new queries and
+// stops once its outstanding queries finish or the drain times out.
+func TestEngineDrain(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	newRunningEngine := func() *Engine {
+		engine := NewEngine(&config.KataGoConfig{BinaryPath: "katago", MaxTime: 1}, logger, nil)
+		engine.running = true
+		// One query is outstanding
+		engine.active.Add(1)
+		return engine
+	}
+
+	engine := newRunningEngine()
+	done := make(chan error, 1)
+	go func() { done <- engine.Drain(context.Background()) }()
+	waitFor(t, func() bool { return engine.Load().Draining })
+	if _, err := engine.sendQuery(context.Background(), map[string]interface{}{}); err == nil || err.Error() != "engine is draining" {
+		t.Errorf("Expected a new query refused, got %v", err)
+	}
+	if !engine.IsRunning() {
+		t.Error("Expected the engine running while a query is outstanding")
+	}
+	engine.active.Add(-1)
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if engine.IsRunning() || engine.Load().Draining {
+		t.Errorf("Expected the drained engine stopped, got %+v", engine.Load())
+	}
+
+	// A query that never finishes does not hold up the stop
+	engine = newRunningEngine()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := engine.Drain(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if engine.IsRunning() {
+		t.Error("Expected the engine stopped when the drain timed out")
+	}
+}
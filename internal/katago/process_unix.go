@@ -0,0 +1,28 @@
+//go:build !windows
+
+package katago
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// configureProcess prepares the KataGo command before it is started.
+func configureProcess(cmd *exec.Cmd) {}
+
+// processAlive returns an error if the process has exited. Signal 0 checks
+// that the process exists without affecting it.
+func processAlive(p *os.Process) error {
+	return p.Signal(syscall.Signal(0))
+}
+
+// terminateProcess asks the process to exit with SIGTERM.
+func terminateProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
+
+// killProcess forcibly stops the process.
+func killProcess(p *os.Process) error {
+	return p.Kill()
+}
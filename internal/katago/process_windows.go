@@ -0,0 +1,69 @@
+//go:build windows
+
+package katago
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+const (
+	// processQueryLimitedInformation is the least access right that allows
+	// reading a process's exit code.
+	processQueryLimitedInformation = 0x1000
+	// stillActive is the exit code reported for a running process.
+	stillActive = 259
+)
+
+var procGenerateConsoleCtrlEvent = syscall.NewLazyDLL("kernel32.dll").NewProc("GenerateConsoleCtrlEvent")
+
+// configureProcess starts KataGo in its own process group so it can be sent
+// CTRL_BREAK without interrupting the server.
+func configureProcess(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// processAlive returns an error if the process has exited. Windows has no
+// signal 0, so the process's exit code is checked instead.
+func processAlive(p *os.Process) error {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(p.Pid)) // #nosec G115 -- PIDs are positive
+	if err != nil {
+		return fmt.Errorf("process %d not found: %w", p.Pid, err)
+	}
+	defer func() { _ = syscall.CloseHandle(h) }()
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return fmt.Errorf("failed to query process %d: %w", p.Pid, err)
+	}
+	if code != stillActive {
+		return fmt.Errorf("process %d exited with code %d", p.Pid, code)
+	}
+	return nil
+}
+
+// terminateProcess asks the process to exit by sending CTRL_BREAK to its
+// process group, the console equivalent of SIGTERM.
+func terminateProcess(p *os.Process) error {
+	r, _, err := procGenerateConsoleCtrlEvent.Call(syscall.CTRL_BREAK_EVENT, uintptr(p.Pid))
+	if r == 0 {
+		return fmt.Errorf("failed to send CTRL_BREAK to process %d: %w", p.Pid, err)
+	}
+	return nil
+}
+
+// killProcess forcibly stops the process and any children it started,
+// which os.Process.Kill alone would leave running.
+func killProcess(p *os.Process) error {
+	// #nosec G204 -- the only argument is a numeric PID
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(p.Pid)).Run(); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
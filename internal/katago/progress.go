@@ -0,0 +1,168 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Startup phases reported while KataGo loads its model.
+const (
+	PhaseStarting     = "starting"
+	PhaseLoadingModel = "loading model"
+	PhaseTuning       = "tuning"
+	PhaseReady        = "ready"
+)
+
+// StartupProgress describes how far KataGo has got loading its model and
+// tuning for the GPU, parsed from the lines it prints to stderr. Startup
+// can take a minute or more, mostly spent in OpenCL tuning or building a
+// TensorRT engine.
+type StartupProgress struct {
+	Phase   string `json:"phase,omitempty"`
+	Percent int    `json:"percent"`
+	Backend string `json:"backend,omitempty"`
+	GPU     string `json:"gpu,omitempty"`
+	// Message is the last stderr line that advanced startup
+	Message        string    `json:"message,omitempty"`
+	StartedAt      time.Time `json:"startedAt"`
+	ElapsedSeconds float64   `json:"elapsedSeconds"`
+}
+
+// Ready reports whether KataGo has finished starting.
+func (p StartupProgress) Ready() bool {
+	return p.Phase == PhaseReady
+}
+
+var (
+	// "2024-05-01 10:00:00+0000: " prefixes KataGo's log lines
+	logTimestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:[+-]\d{4})?: `)
+	// "Cuda backend thread 0: Found GPU NVIDIA GeForce RTX 3080 memory ..."
+	backendThreadPattern = regexp.MustCompile(`^(\S+(?:\s*\(CPU\))?) backend thread \d+:`)
+	gpuPattern           = regexp.MustCompile(`Found GPU (.+?)(?: memory \d+| compute capability|$)`)
+	// "Found OpenCL Device 0: NVIDIA GeForce RTX 3080 (NVIDIA Corporation) (score 11000300)"
+	openCLDevicePattern = regexp.MustCompile(`Found OpenCL Device \d+: (.+?)(?: \(score \d+\))?$`)
+	// "Tuning 12/60 matMul ..."
+	tuningPattern = regexp.MustCompile(`Tuning (\d+)/(\d+)`)
+)
+
+// Percentages for startup milestones. Tuning fills the range between
+// tuningStartPercent and tuningEndPercent.
+const (
+	configLoadedPercent = 10
+	modelLoadingPercent = 20
+	tuningStartPercent  = 30
+	tuningEndPercent    = 90
+	modelLoadedPercent  = 95
+)
+
+// parseStartupLine updates progress from a line of KataGo's stderr and
+// reports whether the line told us anything new. Percentages never go
+// backwards, since lines from several backend threads interleave.
+func parseStartupLine(p *StartupProgress, line string) bool {
+	if p.Ready() {
+		return false
+	}
+	line = logTimestampPattern.ReplaceAllString(strings.TrimSpace(line), "")
+	if line == "" {
+		return false
+	}
+	before := *p
+
+	if m := backendThreadPattern.FindStringSubmatch(line); m != nil {
+		p.Backend = normalizeBackend(m[1])
+		p.advance(PhaseLoadingModel, modelLoadingPercent)
+	}
+	if m := gpuPattern.FindStringSubmatch(line); m != nil {
+		p.GPU = strings.TrimSpace(m[1])
+	} else if m := openCLDevicePattern.FindStringSubmatch(line); m != nil && p.GPU == "" {
+		p.GPU = strings.TrimSpace(m[1])
+		p.Backend = BackendOpenCL
+	}
+
+	switch {
+	case strings.HasPrefix(line, "Loaded config"):
+		p.advance(PhaseStarting, configLoadedPercent)
+	case strings.Contains(line, "Loading model"), strings.Contains(line, "Model name:"), strings.Contains(line, "Model version"):
+		p.advance(PhaseLoadingModel, modelLoadingPercent)
+	case strings.Contains(line, "Performing autotuning"), strings.Contains(line, "Building TensorRT engine"),
+		strings.Contains(line, "Creating new timing cache"):
+		p.advance(PhaseTuning, tuningStartPercent)
+	case strings.HasPrefix(line, "Tuning"):
+		if m := tuningPattern.FindStringSubmatch(line); m != nil {
+			done, _ := strconv.Atoi(m[1])
+			total, _ := strconv.Atoi(m[2])
+			if total > 0 && done <= total {
+				p.advance(PhaseTuning, tuningStartPercent+(tuningEndPercent-tuningStartPercent)*done/total)
+			}
+		}
+	case strings.Contains(line, "Done tuning"):
+		p.advance(PhaseTuning, tuningEndPercent)
+	case strings.HasPrefix(line, "Loaded model"):
+		p.advance(PhaseLoadingModel, modelLoadedPercent)
+	case strings.Contains(line, "ready to begin handling requests"):
+		p.advance(PhaseReady, 100)
+	}
+
+	if p.Phase == before.Phase && p.Percent == before.Percent && p.Backend == before.Backend && p.GPU == before.GPU {
+		return false
+	}
+	p.Message = line
+	return true
+}
+
+// advance moves progress forward to a phase and percentage. A line for an
+// earlier milestone, such as a model line printed by another backend thread
+// during tuning, is ignored.
+func (p *StartupProgress) advance(phase string, percent int) {
+	if percent < p.Percent {
+		return
+	}
+	p.Phase = phase
+	p.Percent = percent
+}
+
+// ProgressFunc receives the progress of a long analysis, such as a game
+// review: progress out of total, counting positions or games, and a
+// message describing it. Calls never go backwards and are not concurrent.
+type ProgressFunc func(progress, total float64, message string)
+
+// progressKey is the context key for a request's ProgressFunc.
+type progressKey struct{}
+
+// WithProgress returns a context whose long analyses report their progress
+// to fn.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ProgressFromContext returns the context's ProgressFunc, or nil.
+func ProgressFromContext(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// reportProgress reports progress to the context's ProgressFunc, if any.
+func reportProgress(ctx context.Context, progress, total float64, message string) {
+	if fn := ProgressFromContext(ctx); fn != nil {
+		fn(progress, total, message)
+	}
+}
+
+// gameProgress returns a context in which the progress of game g, counted
+// from zero, is reported as a share of the progress through all games.
+func gameProgress(ctx context.Context, g, games int) context.Context {
+	fn := ProgressFromContext(ctx)
+	if fn == nil || games <= 1 {
+		return ctx
+	}
+	return WithProgress(ctx, func(progress, total float64, message string) {
+		if total > 0 {
+			progress /= total
+		}
+		fn(float64(g)+progress, float64(games), fmt.Sprintf("Game %d of %d: %s", g+1, games, message))
+	})
+}
@@ -0,0 +1,82 @@
+package katago
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseStartupLine(t *testing.T) {
+	// Abridged stderr from an OpenCL KataGo tuning on first start
+	lines := []string{
+		"2024-05-01 10:00:00+0000: Loaded config /etc/katago/analysis.cfg",
+		"2024-05-01 10:00:00+0000: Loading model and initializing benchmark...",
+		"Found OpenCL Device 0: NVIDIA GeForce RTX 3080 (NVIDIA Corporation) (score 11000300)",
+		"Performing autotuning",
+		"Tuning 0/20 xGemmDirect",
+		"Tuning 10/20 xGemmDirect",
+		"Tuning 20/20 xGemmDirect",
+		"Done tuning",
+		"OpenCL backend thread 0: Model name: kata1-b18c384nbt",
+		"2024-05-01 10:01:02+0000: Loaded model /models/kata1.bin.gz",
+		"2024-05-01 10:01:02+0000: Started, ready to begin handling requests",
+	}
+	wantPhases := []string{
+		PhaseStarting, PhaseLoadingModel, PhaseLoadingModel, PhaseTuning, PhaseTuning, PhaseTuning,
+		PhaseTuning, PhaseTuning, PhaseTuning, PhaseLoadingModel, PhaseReady,
+	}
+	wantPercents := []int{10, 20, 20, 30, 30, 60, 90, 90, 90, 95, 100}
+
+	progress := StartupProgress{Phase: PhaseStarting}
+	for i, line := range lines {
+		parseStartupLine(&progress, line)
+		if progress.Phase != wantPhases[i] || progress.Percent != wantPercents[i] {
+			t.Errorf("After %q: got %s %d%%, want %s %d%%", line, progress.Phase, progress.Percent, wantPhases[i], wantPercents[i])
+		}
+	}
+	if progress.Backend != BackendOpenCL || progress.GPU != "NVIDIA GeForce RTX 3080 (NVIDIA Corporation)" {
+		t.Errorf("Unexpected backend %q and GPU %q", progress.Backend, progress.GPU)
+	}
+	if progress.Message != "Started, ready to begin handling requests" {
+		t.Errorf("Expected timestamp to be stripped from message, got %q", progress.Message)
+	}
+	if parseStartupLine(&progress, "Tuning 1/20 xGemmDirect") || !progress.Ready() {
+		t.Error("Expected ready to be final")
+	}
+}
+
+func TestParseStartupLineCUDA(t *testing.T) {
+	progress := StartupProgress{Phase: PhaseStarting}
+	if !parseStartupLine(&progress, "Cuda backend thread 0: Found GPU NVIDIA A100-SXM4-40GB memory 42505273344 compute capability major 8 minor 0") {
+		t.Fatal("Expected GPU line to update progress")
+	}
+	if progress.Backend != BackendCUDA || progress.GPU != "NVIDIA A100-SXM4-40GB" || progress.Phase != PhaseLoadingModel {
+		t.Errorf("Unexpected progress: %+v", progress)
+	}
+	if parseStartupLine(&progress, "Cuda backend thread 0: Model version 14 useFP16 = true") {
+		t.Error("Expected repeated milestone not to count as progress")
+	}
+	if parseStartupLine(&progress, "Some unrelated line") {
+		t.Error("Expected unrelated line to be ignored")
+	}
+}
+
+func TestGameProgress(t *testing.T) {
+	// Without a ProgressFunc nothing is reported
+	reportProgress(gameProgress(context.Background(), 1, 3), 5, 10, "ignored")
+
+	var progress, total float64
+	var message string
+	ctx := WithProgress(context.Background(), func(p, tot float64, msg string) {
+		progress, total, message = p, tot, msg
+	})
+	reportProgress(gameProgress(ctx, 1, 4), 25, 100, "Analyzed 25 of 100 positions")
+	if progress != 1.25 || total != 4 || message != "Game 2 of 4: Analyzed 25 of 100 positions" {
+		t.Errorf("Expected a quarter of the second of four games, got %v/%v %q", progress, total, message)
+	}
+
+	// A single game reports its own progress
+	reportProgress(gameProgress(ctx, 0, 1), 25, 100, "Analyzed 25 of 100 positions")
+	if progress != 25 || total != 100 || message != "Analyzed 25 of 100 positions" {
+		t.Errorf("Expected the game's own progress, got %v/%v %q", progress, total, message)
+	}
+}
@@ -0,0 +1,333 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+const (
+	// defaultRankVisits is the search budget per move for rank estimation.
+	defaultRankVisits = 100
+	// rankBlunderPoints is the point loss at which a move counts as a blunder.
+	rankBlunderPoints = 5.0
+	// rankAccuratePoints is the point loss below which a move counts as accurate.
+	rankAccuratePoints = 1.0
+	// maxRankEvidence is the number of costliest moves reported as evidence.
+	maxRankEvidence = 5
+)
+
+// rankAnchors maps average point loss per move to a rank value, where 1d
+// is 1, 1k is 0 and 20k is -19. Values in between are interpolated.
+var rankAnchors = []struct {
+	pointLoss float64
+	rank      float64
+}{
+	{0.4, 9},   // 9d
+	{0.7, 5},   // 5d
+	{1.0, 1},   // 1d
+	{1.5, -4},  // 5k
+	{2.5, -9},  // 10k
+	{4.0, -14}, // 15k
+	{6.0, -19}, // 20k
+}
+
+// RankOptions controls rank estimation.
+type RankOptions struct {
+	MaxVisits int // Visits per analyzed move (default: 100)
+}
+
+// RankEvidence is a costly move that informed a rank estimate.
+type RankEvidence struct {
+	Game       int     `json:"game"` // 1-based index into the submitted games
+	MoveNumber int     `json:"moveNumber"`
+	Color      string  `json:"color"`
+	PlayedMove string  `json:"playedMove"`
+	BestMove   string  `json:"bestMove"`
+	PointLoss  float64 `json:"pointLoss"`
+}
+
+// GameRankStats summarizes the player's moves in one game.
+type GameRankStats struct {
+	Game          int     `json:"game"`
+	Color         string  `json:"color"`
+	Opponent      string  `json:"opponent,omitempty"`
+	MovesAnalyzed int     `json:"movesAnalyzed"`
+	AvgPointLoss  float64 `json:"avgPointLoss"`
+	Rank          string  `json:"rank"`
+}
+
+// RankEstimate is a rank estimate for a player aggregated over games.
+type RankEstimate struct {
+	Player        string          `json:"player"`
+	Games         int             `json:"games"`
+	MovesAnalyzed int             `json:"movesAnalyzed"`
+	AvgPointLoss  float64         `json:"avgPointLoss"`
+	MedianLoss    float64         `json:"medianPointLoss"`
+	StdDevLoss    float64         `json:"stdDevPointLoss"`
+	AccuracyRate  float64         `json:"accuracyRate"` // Fraction of moves losing under 1 point
+	BlunderRate   float64         `json:"blunderRate"`  // Fraction of moves losing 5 points or more
+	Rank          string          `json:"rank"`
+	RankLow       string          `json:"rankLow"`  // Weaker end of the 95% confidence interval
+	RankHigh      string          `json:"rankHigh"` // Stronger end of the 95% confidence interval
+	PerGame       []GameRankStats `json:"perGame"`
+	Evidence      []RankEvidence  `json:"evidence"`
+	// Partial is set when estimation stopped early because the context
+	// was done; statistics cover only the analyzed moves.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// EstimateRank estimates a player's rank from the point loss of their moves
+// across one or more games. The player is matched against each game's PB
+// and PW properties.
+func (e *Engine) EstimateRank(ctx context.Context, sgfs []string, player string, opts *RankOptions) (*RankEstimate, error) {
+	games, colors, err := parseRankGames(sgfs, player)
+	if err != nil {
+		return nil, err
+	}
+
+	visits := defaultRankVisits
+	if opts != nil && opts.MaxVisits > 0 {
+		visits = opts.MaxVisits
+	}
+
+	estimate := &RankEstimate{Player: player, Games: len(games)}
+	var losses []float64
+	var evidence []RankEvidence
+
+gameLoop:
+	for g, game := range games {
+		color := colors[g]
+		stats := GameRankStats{Game: g + 1, Color: color, Opponent: game.PlayerWhite}
+		if color == "W" {
+			stats.Opponent = game.PlayerBlack
+		}
+		var gameLosses []float64
+		gameCtx := gameProgress(ctx, g, len(games))
+
+		for i, move := range game.Moves {
+			if !strings.EqualFold(move.Color, color) {
+				continue
+			}
+			// Stop at the deadline and keep what has been analyzed so far
+			if ctx.Err() != nil {
+				estimate.Partial = true
+				estimate.PerGame = appendGameStats(estimate.PerGame, stats, gameLosses)
+				break gameLoop
+			}
+
+			position := &Position{
+				Rules:         game.Rules,
+				BoardXSize:    game.BoardXSize,
+				BoardYSize:    game.BoardYSize,
+				Komi:          game.Komi,
+				Moves:         game.Moves[:i],
+				InitialStones: game.InitialStones,
+			}
+			moveVisits := visits
+			result, err := e.Analyze(ctx, &AnalysisRequest{Position: position, MaxVisits: &moveVisits})
+			reportProgress(gameCtx, float64(i+1), float64(len(game.Moves)),
+				fmt.Sprintf("Analyzed %s's moves up to move %d of %d", player, i+1, len(game.Moves)))
+			if err != nil {
+				if ctx.Err() != nil {
+					estimate.Partial = true
+					estimate.PerGame = appendGameStats(estimate.PerGame, stats, gameLosses)
+					break gameLoop
+				}
+				e.logger.Error("Failed to analyze position for rank estimate", "game", g+1, "move", i+1, "error", err)
+				continue
+			}
+
+			played := move.Location
+			if played == "" {
+				played = "pass"
+			}
+			loss, best, ok := pointLoss(result, played)
+			if !ok {
+				continue
+			}
+			gameLosses = append(gameLosses, loss)
+			evidence = append(evidence, RankEvidence{
+				Game:       g + 1,
+				MoveNumber: i + 1,
+				Color:      color,
+				PlayedMove: played,
+				BestMove:   best,
+				PointLoss:  loss,
+			})
+		}
+
+		losses = append(losses, gameLosses...)
+		estimate.PerGame = appendGameStats(estimate.PerGame, stats, gameLosses)
+	}
+
+	if estimate.Partial {
+		e.logger.Warn("Rank estimate stopped early", "player", player, "analyzedMoves", len(losses))
+	}
+	if len(losses) == 0 {
+		if estimate.Partial {
+			return nil, apperrors.Wrap(apperrors.CodeOf(ctx.Err()), ctx.Err(), "rank estimate stopped before any moves were analyzed")
+		}
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "no moves by %s could be analyzed", player)
+	}
+
+	summarizeRank(estimate, losses)
+
+	sort.SliceStable(evidence, func(i, j int) bool {
+		return evidence[i].PointLoss > evidence[j].PointLoss
+	})
+	if len(evidence) > maxRankEvidence {
+		evidence = evidence[:maxRankEvidence]
+	}
+	estimate.Evidence = evidence
+
+	return estimate, nil
+}
+
+// parseRankGames parses the games and finds the player's color in each.
+func parseRankGames(sgfs []string, player string) ([]*Position, []string, error) {
+	if len(sgfs) == 0 {
+		return nil, nil, apperrors.New(apperrors.CodeInvalidArgument, "at least one game is required")
+	}
+	if strings.TrimSpace(player) == "" {
+		return nil, nil, apperrors.New(apperrors.CodeInvalidArgument, "player is required")
+	}
+
+	games := make([]*Position, len(sgfs))
+	colors := make([]string, len(sgfs))
+	for i, sgf := range sgfs {
+		game, err := NewSGFParser(sgf).Parse()
+		if err != nil {
+			return nil, nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse game %d", i+1)
+		}
+
+		switch {
+		case strings.EqualFold(game.PlayerBlack, player):
+			colors[i] = "B"
+		case strings.EqualFold(game.PlayerWhite, player):
+			colors[i] = "W"
+		default:
+			return nil, nil, apperrors.New(apperrors.CodeInvalidArgument,
+				"player %q not found in game %d (black: %q, white: %q)", player, i+1, game.PlayerBlack, game.PlayerWhite)
+		}
+		games[i] = game
+	}
+	return games, colors, nil
+}
+
+// pointLoss returns how many points the played move loses compared with
+// KataGo's best move. Moves KataGo did not search are charged at least the
+// loss of the worst searched move.
+func pointLoss(result *AnalysisResult, played string) (loss float64, best string, ok bool) {
+	if len(result.MoveInfos) == 0 {
+		return 0, "", false
+	}
+
+	bestInfo := result.MoveInfos[0]
+	for _, mi := range result.MoveInfos {
+		if mi.Order < bestInfo.Order {
+			bestInfo = mi
+		}
+	}
+
+	// Scores may be reported from either player's perspective, but the best
+	// move is never worse for the mover, so the difference is the loss.
+	worst := 0.0
+	for _, mi := range result.MoveInfos {
+		diff := math.Abs(bestInfo.ScoreLead - mi.ScoreLead)
+		if mi.Move == played {
+			return diff, bestInfo.Move, true
+		}
+		worst = math.Max(worst, diff)
+	}
+	return worst, bestInfo.Move, true
+}
+
+// appendGameStats finalizes per-game statistics.
+func appendGameStats(perGame []GameRankStats, stats GameRankStats, losses []float64) []GameRankStats {
+	stats.MovesAnalyzed = len(losses)
+	if len(losses) > 0 {
+		stats.AvgPointLoss = mean(losses)
+		stats.Rank = formatRank(rankForLoss(stats.AvgPointLoss))
+	}
+	return append(perGame, stats)
+}
+
+// summarizeRank fills in aggregate statistics and the rank interval.
+func summarizeRank(estimate *RankEstimate, losses []float64) {
+	n := float64(len(losses))
+	estimate.MovesAnalyzed = len(losses)
+	estimate.AvgPointLoss = mean(losses)
+
+	var accurate, blunders int
+	var sumSq float64
+	for _, loss := range losses {
+		if loss < rankAccuratePoints {
+			accurate++
+		}
+		if loss >= rankBlunderPoints {
+			blunders++
+		}
+		sumSq += (loss - estimate.AvgPointLoss) * (loss - estimate.AvgPointLoss)
+	}
+	estimate.AccuracyRate = float64(accurate) / n
+	estimate.BlunderRate = float64(blunders) / n
+	if len(losses) > 1 {
+		estimate.StdDevLoss = math.Sqrt(sumSq / (n - 1))
+	}
+
+	sorted := append([]float64(nil), losses...)
+	sort.Float64s(sorted)
+	if len(sorted)%2 == 1 {
+		estimate.MedianLoss = sorted[len(sorted)/2]
+	} else {
+		estimate.MedianLoss = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+
+	// 95% confidence interval of the mean loss, mapped onto ranks
+	margin := 1.96 * estimate.StdDevLoss / math.Sqrt(n)
+	estimate.Rank = formatRank(rankForLoss(estimate.AvgPointLoss))
+	estimate.RankLow = formatRank(rankForLoss(estimate.AvgPointLoss + margin))
+	estimate.RankHigh = formatRank(rankForLoss(math.Max(0, estimate.AvgPointLoss-margin)))
+}
+
+// rankForLoss interpolates a rank value from an average point loss.
+func rankForLoss(loss float64) float64 {
+	first, last := rankAnchors[0], rankAnchors[len(rankAnchors)-1]
+	if loss <= first.pointLoss {
+		return first.rank
+	}
+	if loss >= last.pointLoss {
+		return last.rank
+	}
+	for i := 1; i < len(rankAnchors); i++ {
+		lo, hi := rankAnchors[i-1], rankAnchors[i]
+		if loss <= hi.pointLoss {
+			frac := (loss - lo.pointLoss) / (hi.pointLoss - lo.pointLoss)
+			return lo.rank + frac*(hi.rank-lo.rank)
+		}
+	}
+	return last.rank
+}
+
+// formatRank converts a rank value to kyu/dan notation.
+func formatRank(value float64) string {
+	rank := int(math.Round(value))
+	if rank >= 1 {
+		return fmt.Sprintf("%dd", rank)
+	}
+	return fmt.Sprintf("%dk", 1-rank)
+}
+
+// mean returns the arithmetic mean of values.
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,130 @@
+package katago
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+func TestRankForLoss(t *testing.T) {
+	tests := []struct {
+		loss float64
+		want string
+	}{
+		{0.1, "9d"},
+		{0.7, "5d"},
+		{1.0, "1d"},
+		{1.2, "2k"},
+		{1.5, "5k"},
+		{2.5, "10k"},
+		{10.0, "20k"},
+	}
+
+	for _, tt := range tests {
+		if got := formatRank(rankForLoss(tt.loss)); got != tt.want {
+			t.Errorf("rank for loss %.2f = %s, want %s", tt.loss, got, tt.want)
+		}
+	}
+}
+
+func TestPointLoss(t *testing.T) {
+	result := &AnalysisResult{
+		MoveInfos: []MoveInfo{
+			{Move: "D4", Order: 0, ScoreLead: -2.0},
+			{Move: "Q16", Order: 1, ScoreLead: -1.0},
+			{Move: "C3", Order: 2, ScoreLead: 4.0},
+		},
+	}
+
+	// White to move with scores reported for black: lower is better
+	loss, best, ok := pointLoss(result, "Q16")
+	if !ok || best != "D4" || loss != 1.0 {
+		t.Errorf("Expected Q16 to lose 1 point to D4, got %v %s %v", loss, best, ok)
+	}
+
+	// Unsearched moves are charged the worst searched loss
+	loss, _, _ = pointLoss(result, "A1")
+	if loss != 6.0 {
+		t.Errorf("Expected unsearched move to lose 6 points, got %v", loss)
+	}
+
+	if _, _, ok := pointLoss(&AnalysisResult{}, "D4"); ok {
+		t.Error("Expected no loss without move infos")
+	}
+}
+
+func TestSummarizeRank(t *testing.T) {
+	estimate := &RankEstimate{}
+	summarizeRank(estimate, []float64{0, 0.5, 1.0, 2.0, 6.5})
+
+	if estimate.MovesAnalyzed != 5 || estimate.AvgPointLoss != 2.0 || estimate.MedianLoss != 1.0 {
+		t.Errorf("Unexpected statistics: %+v", estimate)
+	}
+	if estimate.AccuracyRate != 0.4 || estimate.BlunderRate != 0.2 {
+		t.Errorf("Expected 40%% accurate and 20%% blunders, got %v and %v", estimate.AccuracyRate, estimate.BlunderRate)
+	}
+	if estimate.Rank != "8k" {
+		t.Errorf("Expected 8k, got %s", estimate.Rank)
+	}
+	if rankValue(t, estimate.RankLow) >= rankValue(t, estimate.Rank) ||
+		rankValue(t, estimate.RankHigh) <= rankValue(t, estimate.Rank) {
+		t.Errorf("Expected %s < %s < %s", estimate.RankLow, estimate.Rank, estimate.RankHigh)
+	}
+}
+
+// rankValue converts kyu/dan notation back to a comparable value.
+func rankValue(t *testing.T, rank string) float64 {
+	t.Helper()
+	for v := 9.0; v >= -19; v-- {
+		if formatRank(v) == rank {
+			return v
+		}
+	}
+	t.Fatalf("Unknown rank %q", rank)
+	return 0
+}
+
+func TestParseRankGames(t *testing.T) {
+	games := []string{
+		"(;GM[1]SZ[19]PB[Alice]PW[bob];B[dd];W[pp])",
+		"(;GM[1]SZ[19]PB[Bob]PW[Carol];B[dd];W[pp])",
+	}
+
+	_, colors, err := parseRankGames(games, "Bob")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if colors[0] != "W" || colors[1] != "B" {
+		t.Errorf("Expected colors W and B, got %v", colors)
+	}
+
+	_, _, err = parseRankGames(games, "Alice")
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for missing player, got %v", apperrors.CodeInvalidArgument, err)
+	}
+	_, _, err = parseRankGames(nil, "Bob")
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s without games, got %v", apperrors.CodeInvalidArgument, err)
+	}
+	_, _, err = parseRankGames([]string{"not an sgf"}, "Bob")
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidSGF {
+		t.Errorf("Expected %s for bad SGF, got %v", apperrors.CodeInvalidSGF, err)
+	}
+}
+
+func TestEstimateRankStopsAtDeadline(t *testing.T) {
+	cfg := &config.KataGoConfig{BinaryPath: "katago", MaxVisits: 10, MaxTime: 1.0}
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := NewEngine(cfg, logger, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := engine.EstimateRank(ctx, []string{"(;GM[1]SZ[19]PB[Alice]PW[Bob];B[dd];W[pp])"}, "Alice", nil)
+	if apperrors.CodeOf(err) != apperrors.CodeCanceled {
+		t.Errorf("Expected %s, got %v", apperrors.CodeCanceled, err)
+	}
+}
@@ -0,0 +1,215 @@
+package katago
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Directions of a recorded line.
+const (
+	DirectionQuery    = "query"
+	DirectionResponse = "response"
+)
+
+// defaultRecorderSize is the number of lines a recorder keeps in memory.
+const defaultRecorderSize = 1000
+
+// RecordedLine is one line of the analysis protocol exchanged with KataGo.
+type RecordedLine struct {
+	Time      time.Time       `json:"time"`
+	Direction string          `json:"direction"` // "query" or "response"
+	Data      json.RawMessage `json:"data"`
+}
+
+// Recorder is a flight recorder of the raw queries sent to KataGo and the
+// responses it returns. The most recent lines are kept in a ring buffer;
+// when a file is given, every line is also appended to it as JSON Lines,
+// so a crash loses nothing.
+type Recorder struct {
+	mu    sync.Mutex
+	lines []RecordedLine
+	next  int // Index of the oldest line once the buffer is full
+	full  bool
+	file  *os.File
+}
+
+// NewRecorder creates a recorder keeping size lines in memory (default:
+// 1000), appending them to the file at path unless it is empty.
+func NewRecorder(size int, path string) (*Recorder, error) {
+	if size <= 0 {
+		size = defaultRecorderSize
+	}
+	r := &Recorder{lines: make([]RecordedLine, 0, size)}
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600) // #nosec G304 -- path is operator configuration
+		if err != nil {
+			return nil, fmt.Errorf("failed to open recording: %w", err)
+		}
+		r.file = file
+	}
+	return r, nil
+}
+
+// Record adds a line sent to or received from KataGo. Lines that are not
+// JSON are kept as JSON strings.
+func (r *Recorder) Record(direction string, data []byte) {
+	data = []byte(strings.TrimSpace(string(data)))
+	line := RecordedLine{Time: time.Now(), Direction: direction, Data: json.RawMessage(data)}
+	if !json.Valid(data) {
+		line.Data, _ = json.Marshal(string(data))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.full {
+		r.lines[r.next] = line
+		r.next = (r.next + 1) % len(r.lines)
+	} else {
+		r.lines = append(r.lines, line)
+		r.full = len(r.lines) == cap(r.lines)
+	}
+	if r.file != nil {
+		if encoded, err := json.Marshal(line); err == nil {
+			_, _ = r.file.Write(append(encoded, '\n'))
+		}
+	}
+}
+
+// Lines returns the recorded lines in memory, oldest first.
+func (r *Recorder) Lines() []RecordedLine {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := make([]RecordedLine, 0, len(r.lines))
+	lines = append(lines, r.lines[r.next:]...)
+	return append(lines, r.lines[:r.next]...)
+}
+
+// WriteRecording writes lines as JSON Lines, the format of a recording file.
+func WriteRecording(w io.Writer, lines []RecordedLine) error {
+	encoder := json.NewEncoder(w)
+	for _, line := range lines {
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadRecording reads a recording written by a recorder or WriteRecording.
+func ReadRecording(r io.Reader) ([]RecordedLine, error) {
+	var lines []RecordedLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var line RecordedLine
+		if err := json.Unmarshal([]byte(text), &line); err != nil {
+			return nil, fmt.Errorf("recording line %d: %w", n, err)
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	return lines, nil
+}
+
+// Replayer answers queries with the responses KataGo gave to the same
+// queries in a recording, so a session can be reproduced without KataGo.
+// Queries match when they are equal apart from their IDs. A query recorded
+// more than once is answered with each recorded answer in turn, the last
+// one repeating.
+type Replayer struct {
+	mu      sync.Mutex
+	answers map[string][][]json.RawMessage // Query key to the responses of each recorded query
+	served  map[string]int
+}
+
+// NewReplayer pairs the queries in a recording with their responses.
+func NewReplayer(lines []RecordedLine) (*Replayer, error) {
+	r := &Replayer{answers: make(map[string][][]json.RawMessage), served: make(map[string]int)}
+	type pending struct {
+		key   string
+		index int
+	}
+	byID := make(map[string]pending)
+	for i, line := range lines {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(line.Data, &fields); err != nil {
+			// Lines KataGo printed that are not JSON objects are not answers
+			continue
+		}
+		id, _ := fields["id"].(string)
+		switch line.Direction {
+		case DirectionQuery:
+			key, err := queryKey(fields)
+			if err != nil {
+				return nil, fmt.Errorf("recorded line %d: %w", i+1, err)
+			}
+			byID[id] = pending{key: key, index: len(r.answers[key])}
+			r.answers[key] = append(r.answers[key], nil)
+		case DirectionResponse:
+			query, ok := byID[id]
+			if !ok {
+				continue
+			}
+			r.answers[query.key][query.index] = append(r.answers[query.key][query.index], line.Data)
+		default:
+			return nil, fmt.Errorf("recorded line %d: unknown direction %q", i+1, line.Direction)
+		}
+	}
+	return r, nil
+}
+
+// Respond returns the recorded responses to a query, with their IDs
+// replaced by the query's, or false if the query was not recorded or
+// never answered.
+func (r *Replayer) Respond(query []byte) ([][]byte, bool) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(query, &fields); err != nil {
+		return nil, false
+	}
+	key, err := queryKey(fields)
+	if err != nil {
+		return nil, false
+	}
+
+	r.mu.Lock()
+	recorded := r.answers[key]
+	n := r.served[key]
+	if n < len(recorded)-1 {
+		r.served[key] = n + 1
+	} else {
+		n = len(recorded) - 1
+	}
+	r.mu.Unlock()
+	if n < 0 || len(recorded[n]) == 0 {
+		return nil, false
+	}
+
+	responses := make([][]byte, 0, len(recorded[n]))
+	for _, data := range recorded[n] {
+		var resp map[string]interface{}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return nil, false
+		}
+		if id, ok := fields["id"]; ok {
+			resp["id"] = id
+		}
+		encoded, err := json.Marshal(resp)
+		if err != nil {
+			return nil, false
+		}
+		responses = append(responses, encoded)
+	}
+	return responses, true
+}
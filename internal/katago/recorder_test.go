@@ -0,0 +1,119 @@
+package katago
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.jsonl")
+	recorder, err := NewRecorder(3, path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i, line := range []string{`{"id":"q1"}`, `{"id":"q1","turnNumber":0}`, `{"id":"q2"}`, "KataGo crashed\n"} {
+		direction := DirectionQuery
+		if i%2 == 1 {
+			direction = DirectionResponse
+		}
+		recorder.Record(direction, []byte(line))
+	}
+
+	// The buffer keeps the newest lines, oldest first
+	lines := recorder.Lines()
+	if len(lines) != 3 || string(lines[0].Data) != `{"id":"q1","turnNumber":0}` {
+		t.Fatalf("Expected the last three lines, got %+v", lines)
+	}
+	if string(lines[2].Data) != `"KataGo crashed"` || lines[2].Direction != DirectionResponse {
+		t.Errorf("Expected a line that is not JSON kept as a string, got %+v", lines[2])
+	}
+
+	// The file has every line
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	recorded, err := ReadRecording(file)
+	if err != nil {
+		t.Fatalf("Failed to read recording: %v", err)
+	}
+	if len(recorded) != 4 || recorded[0].Direction != DirectionQuery || recorded[0].Time.IsZero() {
+		t.Errorf("Expected four recorded lines, got %+v", recorded)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteRecording(&buf, lines); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Count(buf.String(), "\n") != 3 {
+		t.Errorf("Expected one line per recorded line, got %q", buf.String())
+	}
+
+	if _, err := ReadRecording(strings.NewReader("{\"direction\":\"query\"}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an error for line 2, got %v", err)
+	}
+	if _, err := NewRecorder(0, filepath.Join(t.TempDir(), "missing", "recording.jsonl")); err == nil {
+		t.Error("Expected an error for a file that cannot be created")
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	recording := `{"direction":"query","data":{"id":"q1","action":"query_version"}}
+{"direction":"response","data":{"id":"q1","action":"query_version","version":"1.15.3"}}
+{"direction":"query","data":{"id":"q2","moves":[["B","D4"]],"analyzeTurns":[0,1],"maxVisits":10}}
+{"direction":"response","data":{"id":"q2","turnNumber":0,"rootInfo":{"winrate":0.5}}}
+{"direction":"response","data":"Uncaught exception"}
+{"direction":"response","data":{"id":"q2","turnNumber":1,"rootInfo":{"winrate":0.4}}}
+{"direction":"query","data":{"id":"q3","moves":[["B","D4"]],"analyzeTurns":[0,1],"maxVisits":10}}
+{"direction":"response","data":{"id":"q3","turnNumber":0,"rootInfo":{"winrate":0.9}}}
+{"direction":"query","data":{"id":"q4","moves":[["B","Q16"]]}}
+`
+	lines, err := ReadRecording(strings.NewReader(recording))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	replayer, err := NewReplayer(lines)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Both responses come back under the new query's ID
+	responses, ok := replayer.Respond([]byte(`{"maxVisits":10,"id":"r1","analyzeTurns":[0,1],"moves":[["B","D4"]]}`))
+	if !ok || len(responses) != 2 {
+		t.Fatalf("Expected two recorded responses, got %d", len(responses))
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(responses[1], &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["id"] != "r1" || resp["turnNumber"] != float64(1) {
+		t.Errorf("Expected turn 1 answered as r1, got %v", resp)
+	}
+
+	// A repeated query gets the next recorded answer, then the last again
+	for _, want := range []string{`"winrate":0.9`, `"winrate":0.9`} {
+		responses, ok = replayer.Respond([]byte(`{"id":"r2","moves":[["B","D4"]],"analyzeTurns":[0,1],"maxVisits":10}`))
+		if !ok || len(responses) != 1 || !strings.Contains(string(responses[0]), want) {
+			t.Errorf("Expected %s, got %s", want, responses)
+		}
+	}
+
+	if responses, ok = replayer.Respond([]byte(`{"id":"health","action":"query_version"}`)); !ok || !strings.Contains(string(responses[0]), "1.15.3") {
+		t.Errorf("Expected the recorded version, got %s", responses)
+	}
+	if _, ok := replayer.Respond([]byte(`{"id":"r3","moves":[["B","Q16"]]}`)); ok {
+		t.Error("Expected no answer to a query that was never answered")
+	}
+	if _, ok := replayer.Respond([]byte(`{"id":"r4","moves":[]}`)); ok {
+		t.Error("Expected no answer to a query that was not recorded")
+	}
+
+	if _, err := NewReplayer([]RecordedLine{{Direction: "sideways", Data: json.RawMessage(`{}`)}}); err == nil {
+		t.Error("Expected an error for an unknown direction")
+	}
+}
@@ -0,0 +1,186 @@
+package katago
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// ExpandMoveRegion expands a move or region specification into board
+// coordinates. A specification is one of:
+//   - a single move, e.g. "D4" or "pass"
+//   - a rectangle given by two opposite corners, e.g. "C3-F6" or "C3:F6"
+//   - a named area: "top", "bottom", "left", "right", "center",
+//     "top-left", "top-right", "bottom-left" or "bottom-right"
+//
+// Named halves and quadrants include the center line on odd-sized boards.
+func ExpandMoveRegion(spec string, xSize, ySize int) ([]string, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "empty move region")
+	}
+	if strings.EqualFold(spec, "pass") {
+		return []string{"pass"}, nil
+	}
+
+	if x0, y0, x1, y1, ok := namedRegion(strings.ToLower(spec), xSize, ySize); ok {
+		return rectangleMoves(x0, y0, x1, y1, ySize), nil
+	}
+
+	upper := strings.ToUpper(spec)
+	if i := strings.IndexAny(upper, "-:"); i >= 0 {
+		x0, y0, err := regionCorner(upper[:i], xSize, ySize)
+		if err != nil {
+			return nil, err
+		}
+		x1, y1, err := regionCorner(upper[i+1:], xSize, ySize)
+		if err != nil {
+			return nil, err
+		}
+		if x0 > x1 {
+			x0, x1 = x1, x0
+		}
+		if y0 > y1 {
+			y0, y1 = y1, y0
+		}
+		return rectangleMoves(x0, y0, x1, y1, ySize), nil
+	}
+
+	if _, _, err := regionCorner(upper, xSize, ySize); err != nil {
+		return nil, err
+	}
+	return []string{upper}, nil
+}
+
+// ResolveMoveRestrictions converts avoid and allow specifications into a
+// single list of moves to avoid. Every point outside the allowed regions is
+// avoided, so "only consider the top-right" becomes an avoid list covering
+// the rest of the board. Passing is never avoided unless listed explicitly.
+func ResolveMoveRestrictions(avoid, allow []string, xSize, ySize int) ([]string, error) {
+	avoided := make(map[string]bool)
+	for _, spec := range avoid {
+		moves, err := ExpandMoveRegion(spec, xSize, ySize)
+		if err != nil {
+			return nil, err
+		}
+		for _, move := range moves {
+			avoided[move] = true
+		}
+	}
+
+	if len(allow) > 0 {
+		allowed := make(map[string]bool)
+		for _, spec := range allow {
+			moves, err := ExpandMoveRegion(spec, xSize, ySize)
+			if err != nil {
+				return nil, err
+			}
+			for _, move := range moves {
+				allowed[move] = true
+			}
+		}
+		for _, move := range rectangleMoves(0, 0, xSize-1, ySize-1, ySize) {
+			if !allowed[move] {
+				avoided[move] = true
+			}
+		}
+	}
+
+	// Keep board order so equivalent restrictions share a cache key
+	var result []string
+	remaining := 0
+	for _, move := range rectangleMoves(0, 0, xSize-1, ySize-1, ySize) {
+		if avoided[move] {
+			result = append(result, move)
+		} else {
+			remaining++
+		}
+	}
+	if len(result) > 0 && remaining == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "move restrictions exclude every point on the board")
+	}
+	if avoided["pass"] {
+		result = append(result, "pass")
+	}
+	return result, nil
+}
+
+// namedRegion returns the corners of a named board area as 0-based column
+// and row indices, with row 0 at the top.
+func namedRegion(name string, xSize, ySize int) (x0, y0, x1, y1 int, ok bool) {
+	midX, midY := (xSize-1)/2, (ySize-1)/2
+	// Upper bound of the left/top halves includes the center line
+	leftEnd, topEnd := xSize/2, ySize/2
+	if xSize%2 == 0 {
+		leftEnd = xSize/2 - 1
+	}
+	if ySize%2 == 0 {
+		topEnd = ySize/2 - 1
+	}
+	rightStart, bottomStart := midX, midY
+	if xSize%2 == 0 {
+		rightStart = xSize / 2
+	}
+	if ySize%2 == 0 {
+		bottomStart = ySize / 2
+	}
+
+	switch strings.ReplaceAll(name, "_", "-") {
+	case "top":
+		return 0, 0, xSize - 1, topEnd, true
+	case "bottom":
+		return 0, bottomStart, xSize - 1, ySize - 1, true
+	case "left":
+		return 0, 0, leftEnd, ySize - 1, true
+	case "right":
+		return rightStart, 0, xSize - 1, ySize - 1, true
+	case "top-left":
+		return 0, 0, leftEnd, topEnd, true
+	case "top-right":
+		return rightStart, 0, xSize - 1, topEnd, true
+	case "bottom-left":
+		return 0, bottomStart, leftEnd, ySize - 1, true
+	case "bottom-right":
+		return rightStart, bottomStart, xSize - 1, ySize - 1, true
+	case "center":
+		// The middle third of the board in each direction
+		return xSize / 3, ySize / 3, xSize - 1 - xSize/3, ySize - 1 - ySize/3, true
+	}
+	return 0, 0, 0, 0, false
+}
+
+// regionCorner parses a GTP coordinate into 0-based column and row
+// indices, with row 0 at the top.
+func regionCorner(coord string, xSize, ySize int) (x, y int, err error) {
+	coord = strings.TrimSpace(coord)
+	if len(coord) < 2 {
+		return 0, 0, apperrors.New(apperrors.CodeBadCoordinate, "invalid coordinate: %q", coord)
+	}
+	col := coord[0]
+	if col < 'A' || col > 'Z' || col == 'I' {
+		return 0, 0, apperrors.New(apperrors.CodeBadCoordinate, "invalid coordinate: %q", coord)
+	}
+	x = int(col - 'A')
+	if col > 'I' {
+		x--
+	}
+	row, convErr := strconv.Atoi(coord[1:])
+	if convErr != nil || x >= xSize || row < 1 || row > ySize {
+		return 0, 0, apperrors.New(apperrors.CodeBadCoordinate, "invalid coordinate for %dx%d board: %q", xSize, ySize, coord)
+	}
+	return x, ySize - row, nil
+}
+
+// rectangleMoves lists the coordinates in a rectangle of 0-based indices,
+// row by row from the top.
+func rectangleMoves(x0, y0, x1, y1, ySize int) []string {
+	moves := make([]string, 0, (x1-x0+1)*(y1-y0+1))
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			moves = append(moves, fmt.Sprintf("%c%d", ColumnLetter(x), ySize-y))
+		}
+	}
+	return moves
+}
@@ -0,0 +1,113 @@
+package katago
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+func TestExpandMoveRegion(t *testing.T) {
+	tests := []struct {
+		spec  string
+		size  int
+		count int
+		first string
+		last  string
+	}{
+		{"d4", 19, 1, "D4", "D4"},
+		{"pass", 19, 1, "pass", "pass"},
+		{"C3-D4", 19, 4, "C4", "D3"},
+		{"D4:C3", 19, 4, "C4", "D3"},
+		{"top-right", 19, 100, "K19", "T10"},
+		{"bottom_left", 19, 100, "A10", "K1"},
+		{"top", 19, 190, "A19", "T10"},
+		{"top-left", 8, 16, "A8", "D5"},
+		{"center", 19, 49, "G13", "N7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			moves, err := ExpandMoveRegion(tt.spec, tt.size, tt.size)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(moves) != tt.count || moves[0] != tt.first || moves[len(moves)-1] != tt.last {
+				t.Errorf("Expected %d moves from %s to %s, got %d from %s to %s",
+					tt.count, tt.first, tt.last, len(moves), moves[0], moves[len(moves)-1])
+			}
+		})
+	}
+
+	for _, spec := range []string{"", "Z1", "I5", "D20", "A1-T25", "middle"} {
+		if _, err := ExpandMoveRegion(spec, 19, 19); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestResolveMoveRestrictions(t *testing.T) {
+	// Allowing a region avoids the rest of the board
+	avoid, err := ResolveMoveRestrictions(nil, []string{"A1-B2"}, 5, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(avoid) != 21 {
+		t.Errorf("Expected 21 avoided points, got %d: %v", len(avoid), avoid)
+	}
+	for _, move := range avoid {
+		if move == "A1" || move == "B2" || move == "pass" {
+			t.Errorf("Did not expect %s to be avoided", move)
+		}
+	}
+
+	// Avoid lists are merged, deduplicated and kept in board order
+	avoid, err = ResolveMoveRestrictions([]string{"C3", "A5-B5", "B5", "pass"}, nil, 5, 5)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := []string{"A5", "B5", "C3", "pass"}; !reflect.DeepEqual(avoid, want) {
+		t.Errorf("Expected %v, got %v", want, avoid)
+	}
+
+	_, err = ResolveMoveRestrictions([]string{"left"}, []string{"A1-A5"}, 5, 5)
+	if code := apperrors.CodeOf(err); code != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s when every point is excluded, got %s (%v)", apperrors.CodeInvalidArgument, code, err)
+	}
+
+	_, err = ResolveMoveRestrictions([]string{"X9"}, nil, 5, 5)
+	if code := apperrors.CodeOf(err); code != apperrors.CodeBadCoordinate {
+		t.Errorf("Expected %s for an off-board point, got %s (%v)", apperrors.CodeBadCoordinate, code, err)
+	}
+}
+
+func TestBuildAnalysisQueryMoveRestrictions(t *testing.T) {
+	req := &AnalysisRequest{
+		Position: &Position{
+			Rules:      "chinese",
+			BoardXSize: 19,
+			BoardYSize: 19,
+			Moves:      []Move{{Color: "b", Location: "D4"}},
+		},
+		AvoidMoves: []string{"Q16", "R16"},
+		AllowMoves: []string{"D16"},
+	}
+
+	query, err := buildAnalysisQuery(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []map[string]interface{}{{"player": "W", "moves": []string{"Q16", "R16"}, "untilDepth": 1}}
+	if !reflect.DeepEqual(query["avoidMoves"], want) {
+		t.Errorf("Expected avoidMoves %v, got %v", want, query["avoidMoves"])
+	}
+	want = []map[string]interface{}{{"player": "W", "moves": []string{"D16"}, "untilDepth": 1}}
+	if !reflect.DeepEqual(query["allowMoves"], want) {
+		t.Errorf("Expected allowMoves %v, got %v", want, query["allowMoves"])
+	}
+
+	req.AvoidMoves = []string{"Z99"}
+	if _, err := buildAnalysisQuery(req); apperrors.CodeOf(err) != apperrors.CodeBadCoordinate {
+		t.Errorf("Expected %s, got %v", apperrors.CodeBadCoordinate, err)
+	}
+}
@@ -0,0 +1,153 @@
+package katago
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Repetition is a move of a game that recreates an earlier position, as
+// in the cycle of a triple ko or a long ko fight.
+type Repetition struct {
+	MoveNumber int    `json:"moveNumber"`
+	Color      string `json:"color"`
+	// RepeatsMove is the first move after which the board had the same
+	// stones, 0 for the starting position
+	RepeatsMove int `json:"repeatsMove"`
+}
+
+// superkoRules maps the rules that forbid a move to repeat an earlier
+// position to the superko they apply: "positional" compares the stones
+// alone, "situational" the stones and the player to move. KataGo's
+// chinese, japanese and korean rules use simple ko and allow repetition.
+var superkoRules = map[string]string{
+	"tromp-taylor": "positional",
+	"aga":          "situational",
+	"new_zealand":  "situational",
+}
+
+// RepetitionError reports a move that repeats an earlier position where
+// the position's rules forbid it.
+type RepetitionError struct {
+	Rules   string
+	Superko string // "positional" or "situational"
+	// MoveIndex is the index of the offending move in the position's
+	// moves, and RepeatsMove the number of moves after which the position
+	// first occurred, 0 for the start
+	MoveIndex   int
+	Move        Move
+	RepeatsMove int
+}
+
+// Error implements the error interface.
+func (e *RepetitionError) Error() string {
+	return fmt.Sprintf("move %d (%s %s) repeats the position after move %d, which %s superko forbids under %s rules",
+		e.MoveIndex, e.Move.Color, e.Move.Location, e.RepeatsMove, e.Superko, e.Rules)
+}
+
+// checkSuperko returns a *RepetitionError for the first move of a
+// position that repeats an earlier position where its rules forbid it, or
+// nil. Passes never violate superko. Positions with an otherwise illegal
+// move are left to KataGo.
+func checkSuperko(pos *Position) error {
+	superko, ok := superkoRules[pos.Rules]
+	if !ok || len(pos.Moves) == 0 {
+		return nil
+	}
+	hashes := positionHashes(pos)
+	if hashes == nil {
+		return nil
+	}
+	// A situational superko tells positions apart by the player who moved
+	// into them; the start counts as a move by the first mover's opponent
+	type seenPosition struct {
+		hash  uint64
+		mover string
+	}
+	key := func(n int) seenPosition {
+		if superko == "positional" {
+			return seenPosition{hash: hashes[n]}
+		}
+		if n == 0 {
+			return seenPosition{hashes[0], opponent(strings.ToUpper(pos.Moves[0].Color))}
+		}
+		return seenPosition{hashes[n], strings.ToUpper(pos.Moves[n-1].Color)}
+	}
+	first := map[seenPosition]int{key(0): 0}
+	for i, move := range pos.Moves {
+		k := key(i + 1)
+		earlier, seen := first[k]
+		if !seen {
+			first[k] = i + 1
+			continue
+		}
+		if move.Location != "" && !strings.EqualFold(move.Location, "pass") {
+			return &RepetitionError{Rules: pos.Rules, Superko: superko, MoveIndex: i, Move: move, RepeatsMove: earlier}
+		}
+	}
+	return nil
+}
+
+// FindRepetitions returns the moves of a game that recreate an earlier
+// position, in move order, found by comparing board hashes. Passes, which
+// leave the board as it was, are not repetitions. It returns nil if a
+// move of the game is illegal.
+func FindRepetitions(game *Position) []Repetition {
+	hashes := positionHashes(game)
+	if hashes == nil {
+		return nil
+	}
+	first := map[uint64]int{}
+	var repetitions []Repetition
+	for n, hash := range hashes {
+		earlier, seen := first[hash]
+		if !seen {
+			first[hash] = n
+			continue
+		}
+		move := game.Moves[n-1]
+		if move.Location == "" || strings.EqualFold(move.Location, "pass") {
+			continue
+		}
+		repetitions = append(repetitions, Repetition{
+			MoveNumber:  n,
+			Color:       strings.ToUpper(move.Color),
+			RepeatsMove: earlier,
+		})
+	}
+	return repetitions
+}
+
+// positionHashes returns the hash of the board after each number of moves
+// of a game, from 0, or nil if a move is illegal.
+func positionHashes(game *Position) []uint64 {
+	board, err := BoardFromPosition(&Position{
+		BoardXSize:    game.BoardXSize,
+		BoardYSize:    game.BoardYSize,
+		InitialStones: game.InitialStones,
+	})
+	if err != nil {
+		return nil
+	}
+	hashes := []uint64{board.Hash()}
+	for _, move := range game.Moves {
+		if err := board.Play(move.Color, move.Location); err != nil {
+			return nil
+		}
+		hashes = append(hashes, board.Hash())
+	}
+	return hashes
+}
+
+// repeatedReviewPositions maps each position a review analyzes, the
+// position before a move counted as the moves played, to the earlier one
+// it repeats with the same player to move, so that it is analyzed once.
+func repeatedReviewPositions(game *Position, repetitions []Repetition) map[int]int {
+	repeats := map[int]int{}
+	for _, r := range repetitions {
+		if r.MoveNumber < len(game.Moves) &&
+			strings.EqualFold(game.Moves[r.MoveNumber].Color, game.Moves[r.RepeatsMove].Color) {
+			repeats[r.MoveNumber] = r.RepeatsMove
+		}
+	}
+	return repeats
+}
@@ -0,0 +1,88 @@
+package katago
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// tripleKoSGF is a game on three kos, taken in turn so that the position
+// repeats every six moves.
+const tripleKoSGF = "(;GM[1]SZ[9]KM[7]" +
+	"AB[ac][bd][bb][ag][bh][bf][fc][gd][gb][hc]AW[cd][cb][dc][bc][ch][cf][dg][bg][ic][hd][hb]" +
+	";B[cc];W[gc];B[cg];W[bc];B[hc];W[bg];B[cc];W[gc];B[cg];W[bc];B[hc];W[bg])"
+
+func TestFindRepetitions(t *testing.T) {
+	game, err := NewSGFParser(tripleKoSGF).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	repetitions := FindRepetitions(game)
+	if len(repetitions) != 7 {
+		t.Fatalf("Expected 7 repetitions, got %+v", repetitions)
+	}
+	for i, r := range repetitions[:6] {
+		if r.MoveNumber != i+6 || r.RepeatsMove != i {
+			t.Errorf("Expected move %d to repeat move %d, got %+v", i+6, i, r)
+		}
+	}
+	if last := repetitions[6]; last.MoveNumber != 12 || last.Color != "W" || last.RepeatsMove != 0 {
+		t.Errorf("Expected move 12 to repeat the starting position, got %+v", last)
+	}
+
+	// The positions before moves 7 to 12 were analyzed before moves 1 to 6
+	repeats := repeatedReviewPositions(game, repetitions)
+	if len(repeats) != 6 || repeats[6] != 0 || repeats[11] != 5 {
+		t.Errorf("Unexpected repeated review positions: %v", repeats)
+	}
+
+	// Passes leave the board as it was but repeat nothing
+	passes, err := NewSGFParser("(;GM[1]SZ[9];B[ee];W[];B[];W[cc])").Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repetitions := FindRepetitions(passes); len(repetitions) != 0 {
+		t.Errorf("Expected no repetitions, got %+v", repetitions)
+	}
+	illegal := &Position{BoardXSize: 9, BoardYSize: 9, Moves: []Move{{"b", "E5"}, {"w", "E5"}}}
+	if repetitions := FindRepetitions(illegal); repetitions != nil {
+		t.Errorf("Expected no repetitions in an illegal game, got %+v", repetitions)
+	}
+}
+
+func TestValidatePositionSuperko(t *testing.T) {
+	game, err := NewSGFParser(tripleKoSGF).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rules := range []string{"chinese", "japanese", "korean"} {
+		game.Rules = rules
+		if err := ValidatePosition(game); err != nil {
+			t.Errorf("Expected %s rules to allow the triple ko, got %v", rules, err)
+		}
+	}
+
+	// Move 6 recreates the starting position, with Black to move again
+	for rules, superko := range map[string]string{"tromp-taylor": "positional", "aga": "situational", "new_zealand": "situational"} {
+		game.Rules = rules
+		err := ValidatePosition(game)
+		if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+			t.Fatalf("Expected %s under %s rules, got %v", apperrors.CodeInvalidArgument, rules, err)
+		}
+		var repetition *RepetitionError
+		if !errors.As(err, &repetition) {
+			t.Fatalf("Expected a RepetitionError under %s rules, got %v", rules, err)
+		}
+		if repetition.MoveIndex != 5 || repetition.Move.Location != "B3" || repetition.RepeatsMove != 0 || repetition.Superko != superko {
+			t.Errorf("Unexpected repetition under %s rules: %+v", rules, repetition)
+		}
+	}
+
+	// Passes never repeat a position illegally
+	passes := &Position{Rules: "tromp-taylor", BoardXSize: 9, BoardYSize: 9,
+		Moves: []Move{{"b", "E5"}, {"w", ""}, {"b", ""}, {"w", "C3"}}}
+	if err := ValidatePosition(passes); err != nil {
+		t.Errorf("Expected passes to be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package katago
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+// Verdicts of a result check.
+const (
+	ResultConsistent           = "consistent"            // KataGo agrees with the recorded result
+	ResultWrongWinner          = "wrong_winner"          // The recorded winner is behind on the board
+	ResultWrongMargin          = "wrong_margin"          // The winner is right but the margin is off
+	ResultPrematureResignation = "premature_resignation" // The player who resigned was not behind
+	ResultUnverified           = "unverified"            // Decided off the board, by time or forfeit
+)
+
+// How a recorded result was decided.
+const (
+	decidedByScore       = "score"
+	decidedByResignation = "resignation"
+	decidedByTime        = "time"
+	decidedByForfeit     = "forfeit"
+	decidedByDraw        = "draw"
+)
+
+// resultMarginTolerance is how far, in points, a recorded margin may be
+// from KataGo's estimate before the count is flagged. It allows for the
+// difference between territory and area scoring.
+const resultMarginTolerance = 2.0
+
+// RecordedResult is a game result parsed from an SGF RE property.
+type RecordedResult struct {
+	Winner    string  // "B" or "W", empty for a draw
+	DecidedBy string  // score, resignation, time, forfeit or draw
+	Margin    float64 // Winning margin in points, when decided by score
+}
+
+// ParseResult parses an RE property such as "B+R", "W+3.5", "B+Time" or
+// "0". It reports false for results that name no winner or margin, such as
+// "Void" and "?".
+func ParseResult(re string) (RecordedResult, bool) {
+	re = strings.TrimSpace(re)
+	switch strings.ToLower(re) {
+	case "0", "draw", "jigo":
+		return RecordedResult{DecidedBy: decidedByDraw}, true
+	}
+	winner, how, ok := strings.Cut(strings.ToUpper(re), "+")
+	if !ok || (winner != "B" && winner != "W") {
+		return RecordedResult{}, false
+	}
+	result := RecordedResult{Winner: winner}
+	switch how {
+	case "R", "RESIGN":
+		result.DecidedBy = decidedByResignation
+	case "T", "TIME":
+		result.DecidedBy = decidedByTime
+	case "F", "FORFEIT":
+		result.DecidedBy = decidedByForfeit
+	default:
+		margin, err := strconv.ParseFloat(how, 64)
+		if err != nil || margin < 0 {
+			return RecordedResult{}, false
+		}
+		result.DecidedBy = decidedByScore
+		result.Margin = margin
+	}
+	return result, true
+}
+
+// ResultCheck compares a game's recorded result with KataGo's evaluation
+// of its final position.
+type ResultCheck struct {
+	Recorded string `json:"recorded"` // As in the SGF, e.g. "W+R"
+	// Estimated is the result KataGo expects from the final position, e.g.
+	// "B+3.4"
+	Estimated    string  `json:"estimated"`
+	ScoreLead    float64 `json:"scoreLead"`    // Black's lead in the final position
+	BlackWinrate float64 `json:"blackWinrate"` // Black's win rate in the final position
+	Verdict      string  `json:"verdict"`
+	Explanation  string  `json:"explanation,omitempty"` // Set unless the result is consistent
+}
+
+// Disputed reports whether KataGo's evaluation contradicts the recorded
+// result.
+func (c *ResultCheck) Disputed() bool {
+	return c.Verdict != ResultConsistent && c.Verdict != ResultUnverified
+}
+
+// CheckResult compares a recorded result with the analysis of the game's
+// final position, which must report win rates and scores for Black
+// (reportAnalysisWinratesAs = BLACK). Explanations are written in p's
+// language. It reports false when the result cannot be parsed.
+func CheckResult(p *i18n.Printer, recorded string, final *AnalysisResult) (*ResultCheck, bool) {
+	result, ok := ParseResult(recorded)
+	if !ok {
+		return nil, false
+	}
+	lead := final.RootInfo.ScoreLead
+	check := &ResultCheck{
+		Recorded:     recorded,
+		Estimated:    formatResult(lead),
+		ScoreLead:    roundTenth(lead),
+		BlackWinrate: final.RootInfo.Winrate,
+		Verdict:      ResultConsistent,
+	}
+	// The recorded winner's lead and win rate
+	winnerLead, winnerWinrate := lead, final.RootInfo.Winrate
+	if result.Winner == "W" {
+		winnerLead, winnerWinrate = -lead, 1-final.RootInfo.Winrate
+	}
+
+	switch result.DecidedBy {
+	case decidedByTime, decidedByForfeit:
+		check.Verdict = ResultUnverified
+		check.Explanation = p.T("The game was decided off the board, so the final position does not decide the result")
+	case decidedByResignation:
+		if winnerWinrate < 0.5 {
+			check.Verdict = ResultPrematureResignation
+			check.Explanation = p.Sprintf("The player who resigned had a %.1f%% win rate in the final position",
+				(1-winnerWinrate)*100)
+		}
+	case decidedByDraw:
+		if math.Abs(lead) > resultMarginTolerance {
+			check.Verdict = ResultWrongMargin
+			check.Explanation = p.Sprintf("The game was recorded as a draw but KataGo counts %s", check.Estimated)
+		}
+	case decidedByScore:
+		switch {
+		case winnerLead < 0:
+			check.Verdict = ResultWrongWinner
+			check.Explanation = p.Sprintf("The recorded winner is behind in the final position; KataGo counts %s", check.Estimated)
+		case math.Abs(winnerLead-result.Margin) > resultMarginTolerance:
+			check.Verdict = ResultWrongMargin
+			check.Explanation = p.Sprintf("The recorded margin differs from KataGo's count of %s by %.1f points",
+				check.Estimated, math.Abs(winnerLead-result.Margin))
+		}
+	}
+	return check, true
+}
+
+// formatResult writes Black's lead as a result, e.g. "B+3.4" or "W+0.5".
+func formatResult(lead float64) string {
+	lead = roundTenth(lead)
+	if lead == 0 {
+		return "0"
+	}
+	if lead > 0 {
+		return fmt.Sprintf("B+%.1f", lead)
+	}
+	return fmt.Sprintf("W+%.1f", -lead)
+}
@@ -0,0 +1,75 @@
+package katago
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+func TestParseResult(t *testing.T) {
+	tests := []struct {
+		re   string
+		want RecordedResult
+		ok   bool
+	}{
+		{"B+R", RecordedResult{Winner: "B", DecidedBy: "resignation"}, true},
+		{"w+resign", RecordedResult{Winner: "W", DecidedBy: "resignation"}, true},
+		{"W+3.5", RecordedResult{Winner: "W", DecidedBy: "score", Margin: 3.5}, true},
+		{"B+T", RecordedResult{Winner: "B", DecidedBy: "time"}, true},
+		{"W+Forfeit", RecordedResult{Winner: "W", DecidedBy: "forfeit"}, true},
+		{"Jigo", RecordedResult{DecidedBy: "draw"}, true},
+		{"0", RecordedResult{DecidedBy: "draw"}, true},
+		{"Void", RecordedResult{}, false},
+		{"?", RecordedResult{}, false},
+		{"X+1", RecordedResult{}, false},
+		{"B+lots", RecordedResult{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseResult(tt.re)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseResult(%q) = %+v, %v; want %+v, %v", tt.re, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCheckResult(t *testing.T) {
+	final := func(winrate, lead float64) *AnalysisResult {
+		return &AnalysisResult{RootInfo: RootInfo{Winrate: winrate, ScoreLead: lead}}
+	}
+	tests := []struct {
+		recorded  string
+		final     *AnalysisResult
+		verdict   string
+		estimated string
+	}{
+		{"B+R", final(0.95, 12.3), ResultConsistent, "B+12.3"},
+		{"W+R", final(0.71, 3.4), ResultPrematureResignation, "B+3.4"},
+		{"W+6.5", final(0.02, -6.0), ResultConsistent, "W+6.0"},
+		{"B+0.5", final(0.1, -2.5), ResultWrongWinner, "W+2.5"},
+		{"B+10.5", final(0.97, 4.5), ResultWrongMargin, "B+4.5"},
+		{"0", final(0.5, 0.2), ResultConsistent, "B+0.2"},
+		{"Draw", final(0.9, 5), ResultWrongMargin, "B+5.0"},
+		{"W+T", final(0.9, 20), ResultUnverified, "B+20.0"},
+	}
+	for _, tt := range tests {
+		check, ok := CheckResult(i18n.English, tt.recorded, tt.final)
+		if !ok {
+			t.Fatalf("Expected %q to be checked", tt.recorded)
+		}
+		if check.Verdict != tt.verdict || check.Estimated != tt.estimated {
+			t.Errorf("%s: expected %s with estimate %s, got %+v", tt.recorded, tt.verdict, tt.estimated, check)
+		}
+		if (check.Verdict == ResultConsistent) != (check.Explanation == "") {
+			t.Errorf("%s: expected an explanation only for an inconsistent result, got %q", tt.recorded, check.Explanation)
+		}
+	}
+
+	check, _ := CheckResult(i18n.English, "W+R", final(0.71, 3.4))
+	if !check.Disputed() || !strings.Contains(check.Explanation, "71.0% win rate") {
+		t.Errorf("Expected a disputed resignation, got %+v", check)
+	}
+	if _, ok := CheckResult(i18n.English, "Void", final(0.5, 0)); ok {
+		t.Error("Expected a void result not to be checked")
+	}
+}
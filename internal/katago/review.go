@@ -3,7 +3,12 @@ package katago
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
+	"sync"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
 )
 
 // MistakeThresholds defines thresholds for categorizing mistakes.
@@ -12,6 +17,19 @@ type MistakeThresholds struct {
 	Mistake       float64 // Win rate drop >= this is a mistake (default: 0.05)
 	Inaccuracy    float64 // Win rate drop >= this is an inaccuracy (default: 0.02)
 	MinimumVisits int     // Minimum visits for reliable analysis
+	Concurrency   int     // Positions analyzed at once (default: 1)
+	// Once Black's win rate stays at or above DecidedWinrate, or at or
+	// below one minus it, for DecidedMoves consecutive positions, the rest
+	// of the game is analyzed at decidedGameVisits and not judged
+	// (0 disables)
+	DecidedWinrate float64
+	DecidedMoves   int
+	// VerifyVariations analyzes the end of each variation recorded in the
+	// SGF
+	VerifyVariations bool
+	// Themes sorts the costliest mistakes into themes to work on, with
+	// two more queries for each
+	Themes bool
 }
 
 // DefaultMistakeThresholds returns default thresholds.
@@ -37,12 +55,83 @@ type Mistake struct {
 	BestWR       float64 `json:"bestWinrate"`
 	PolicyPlayed float64 `json:"policyPlayed,omitempty"`
 	PolicyBest   float64 `json:"policyBest,omitempty"`
+	// Tenuki is set when the move was played away from a hot area where
+	// KataGo's top choices were concentrated; HotArea lists those choices.
+	Tenuki  bool     `json:"tenuki,omitempty"`
+	HotArea []string `json:"hotArea,omitempty"`
+	// Player names who played the move, when the SGF does
+	Player string `json:"player,omitempty"`
+	// TimeLeft is the player's time in seconds after the move, when the
+	// SGF records it
+	TimeLeft *float64 `json:"timeLeft,omitempty"`
+	// Difficulty is how hard the position was, from 0 to 1; see
+	// MoveDifficulty
+	Difficulty float64 `json:"difficulty"`
+	// Theme is the kind of mistake it is, e.g. ThemeLifeAndDeath, when
+	// the review sorted it into themes
+	Theme string `json:"theme,omitempty"`
+}
+
+// GoodMove is an excellent move in a game: KataGo's first choice, found
+// despite a low policy prior or clearly better than every alternative.
+type GoodMove struct {
+	MoveNumber  int     `json:"moveNumber"`
+	Color       string  `json:"color"`
+	PlayedMove  string  `json:"playedMove"`
+	Category    string  `json:"category"` // "brilliant", "excellent"
+	Explanation string  `json:"explanation"`
+	PlayedWR    float64 `json:"playedWinrate"`
+	Policy      float64 `json:"policy"`
+	// Gain is the win rate the move keeps over the second-best option
+	Gain       float64 `json:"gain"`
+	SecondBest string  `json:"secondBest,omitempty"`
+	// Player names who played the move, when the SGF does
+	Player string `json:"player,omitempty"`
 }
 
 // GameReview contains the analysis of an entire game.
 type GameReview struct {
-	Mistakes []Mistake     `json:"mistakes"`
-	Summary  ReviewSummary `json:"summary"`
+	Game      *GameInfo     `json:"game,omitempty"` // Unset when the SGF records no metadata
+	Mistakes  []Mistake     `json:"mistakes"`
+	GoodMoves []GoodMove    `json:"goodMoves"`
+	Summary   ReviewSummary `json:"summary"`
+	// Winrates traces the evaluation through the game for winrate graphs
+	Winrates []WinratePoint `json:"winrates,omitempty"`
+	// Notes are the comments and variations already in the SGF, with
+	// KataGo's opinion of them
+	Notes []SGFNote `json:"notes,omitempty"`
+	// Findings are what the engine's analyzers found, in move order
+	Findings []Finding `json:"findings,omitempty"`
+	// Repetitions are the moves that recreated an earlier position, as in
+	// a triple ko. A position repeated with the same player to move is
+	// analyzed once.
+	Repetitions []Repetition `json:"repetitions,omitempty"`
+	// TimePressure correlates the mistakes with the players' clocks, when
+	// the SGF records the time left after moves
+	TimePressure *TimePressure `json:"timePressure,omitempty"`
+	// Difficulties are how hard the position before each judged move was
+	Difficulties []MoveDifficulty `json:"difficulties,omitempty"`
+	// Themes are the kinds of mistake that cost the most, when requested
+	Themes []MistakeTheme `json:"themes,omitempty"`
+	// Analyses are KataGo's analyses of the position after each number of
+	// moves, nil where there is none, kept for exports such as LizzieSGF
+	Analyses []*AnalysisResult `json:"-"`
+}
+
+// analysisAfter returns the analysis of the position after n moves, or nil.
+func (r *GameReview) analysisAfter(n int) *AnalysisResult {
+	if n < len(r.Analyses) {
+		return r.Analyses[n]
+	}
+	return nil
+}
+
+// WinratePoint is KataGo's evaluation of the position after a number of
+// moves, as reported by the engine.
+type WinratePoint struct {
+	MoveNumber int     `json:"moveNumber"`
+	Winrate    float64 `json:"winrate"`
+	ScoreLead  float64 `json:"scoreLead"`
 }
 
 // ReviewSummary provides overall game statistics.
@@ -52,13 +141,87 @@ type ReviewSummary struct {
 	WhiteMistakes  int     `json:"whiteMistakes"`
 	BlackBlunders  int     `json:"blackBlunders"`
 	WhiteBlunders  int     `json:"whiteBlunders"`
+	BlackTenukis   int     `json:"blackTenukis"` // Mistakes and blunders that ignored a hot area
+	WhiteTenukis   int     `json:"whiteTenukis"`
 	BlackAccuracy  float64 `json:"blackAccuracy"` // Percentage of good moves
 	WhiteAccuracy  float64 `json:"whiteAccuracy"`
 	EstimatedLevel string  `json:"estimatedLevel,omitempty"`
+	AnalyzedMoves  int     `json:"analyzedMoves"`
+	// Percentages of moves matching KataGo's first choice, or one of its
+	// first three, and the mean policy prior of the moves played
+	BlackTop1Agreement float64 `json:"blackTop1Agreement"`
+	WhiteTop1Agreement float64 `json:"whiteTop1Agreement"`
+	BlackTop3Agreement float64 `json:"blackTop3Agreement"`
+	WhiteTop3Agreement float64 `json:"whiteTop3Agreement"`
+	BlackAveragePolicy float64 `json:"blackAveragePolicy"`
+	WhiteAveragePolicy float64 `json:"whiteAveragePolicy"`
+	// Mistakes and blunders made in hard positions, which only strong
+	// reading finds the way through, and in easy ones, which are careless
+	BlackHardMistakes int `json:"blackHardMistakes"`
+	WhiteHardMistakes int `json:"whiteHardMistakes"`
+	BlackEasyMistakes int `json:"blackEasyMistakes"`
+	WhiteEasyMistakes int `json:"whiteEasyMistakes"`
+	// Partial is set when the review stopped early because the context
+	// was done; statistics cover only the analyzed moves.
+	Partial bool `json:"partial,omitempty"`
+	// DecidedAtMove is the move after which the game was decided, when
+	// the review stopped judging moves there; statistics cover the moves
+	// up to the decision.
+	DecidedAtMove int `json:"decidedAtMove,omitempty"`
+	// Result compares the recorded result with KataGo's evaluation of the
+	// final position. It is unset when the SGF records no result or the
+	// review is partial.
+	Result *ResultCheck `json:"result,omitempty"`
+}
+
+// agreement accumulates how often a player's moves matched KataGo's.
+type agreement struct {
+	moves, top1, top3 int
+	policy            float64
+}
+
+// add counts a move and its rank among KataGo's choices, -1 if unranked.
+func (a *agreement) add(rank int, prior float64) {
+	a.moves++
+	if rank == 0 {
+		a.top1++
+	}
+	if rank >= 0 && rank < 3 {
+		a.top3++
+	}
+	a.policy += prior
+}
+
+// rates returns the top-1 and top-3 agreement percentages and the mean
+// policy prior.
+func (a *agreement) rates() (top1, top3, policy float64) {
+	if a.moves == 0 {
+		return 0, 0, 0
+	}
+	n := float64(a.moves)
+	return float64(a.top1) / n * 100, float64(a.top3) / n * 100, a.policy / n
 }
 
-// ReviewGame analyzes a complete game to find mistakes.
+// resultCheckVisits is the least number of visits spent on the final
+// position to check a game's result.
+const resultCheckVisits = 200
+
+// checkReviewable returns an error for a game without moves to review,
+// such as a problem's setup.
+func checkReviewable(game *Position) error {
+	switch {
+	case game.SetupOnly():
+		return apperrors.New(apperrors.CodeInvalidArgument, "the SGF sets up a position with no moves to review, as a problem does")
+	case len(game.Moves) == 0:
+		return apperrors.New(apperrors.CodeInvalidArgument, "the game has no moves")
+	}
+	return nil
+}
+
+// ReviewGame analyzes a complete game to find mistakes. Explanations are
+// written in the language of the context's i18n printer.
 func (e *Engine) ReviewGame(ctx context.Context, sgf string, thresholds *MistakeThresholds) (*GameReview, error) {
+	p := i18n.FromContext(ctx)
 	if thresholds == nil {
 		thresholds = DefaultMistakeThresholds()
 	}
@@ -69,29 +232,54 @@ func (e *Engine) ReviewGame(ctx context.Context, sgf string, thresholds *Mistake
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse SGF: %w", err)
 	}
+	if err := checkReviewable(fullGame); err != nil {
+		return nil, err
+	}
 
 	review := &GameReview{
-		Mistakes: []Mistake{},
+		Mistakes:  []Mistake{},
+		GoodMoves: []GoodMove{},
 	}
 
 	// Track statistics
 	blackMoves, whiteMoves := 0, 0
 	blackGoodMoves, whiteGoodMoves := 0, 0
+	var blackAgreement, whiteAgreement agreement
+	var judged []int // Moves the mistake detector judged, for the analyzers
 
-	// Analyze each position after each move
+	// Analyze the position before each move, once for each repeated
+	// position, then review the moves in order
+	review.Repetitions = FindRepetitions(fullGame)
+	positions, decidedAt := e.analyzeReviewPositions(ctx, fullGame, thresholds,
+		repeatedReviewPositions(fullGame, review.Repetitions))
+	review.Summary.DecidedAtMove = decidedAt
+	analyzed := 0
 	for i := 1; i <= len(fullGame.Moves); i++ {
-		// Create position before the move at index i-1
-		position := &Position{
-			Rules:         fullGame.Rules,
-			BoardXSize:    fullGame.BoardXSize,
-			BoardYSize:    fullGame.BoardYSize,
-			Moves:         fullGame.Moves[:i-1], // Position before move i
-			InitialStones: fullGame.InitialStones,
+		// Stop at the first move not reviewed before the deadline and keep
+		// what has been reviewed so far
+		position := positions[i-1]
+		if position.result == nil && (position.err == nil || position.canceled) {
+			review.Summary.Partial = true
+			break
 		}
 
 		// The move we're evaluating
 		currentMove := fullGame.Moves[i-1]
 		color := strings.ToUpper(currentMove.Color)
+		result, err := position.result, position.err
+
+		// Moves after the game was decided only trace the evaluation
+		if position.shallow {
+			analyzed++
+			if err == nil {
+				review.Winrates = append(review.Winrates, WinratePoint{
+					MoveNumber: i - 1,
+					Winrate:    result.RootInfo.Winrate,
+					ScoreLead:  result.RootInfo.ScoreLead,
+				})
+			}
+			continue
+		}
 
 		// Track move counts
 		if color == "B" {
@@ -100,22 +288,17 @@ func (e *Engine) ReviewGame(ctx context.Context, sgf string, thresholds *Mistake
 			whiteMoves++
 		}
 
-		// Analyze position
-		req := &AnalysisRequest{
-			Position:         position,
-			IncludePolicy:    true,
-			IncludeOwnership: false,
-		}
-		if thresholds.MinimumVisits > 0 {
-			visits := thresholds.MinimumVisits
-			req.MaxVisits = &visits
-		}
-
-		result, err := e.Analyze(ctx, req)
 		if err != nil {
-			e.logger.Error("Failed to analyze position at move %d: %v", i+1, err)
+			e.logger.Error("Failed to analyze position at move %d: %v", i, err)
+			analyzed++
 			continue
 		}
+		analyzed++
+		review.Winrates = append(review.Winrates, WinratePoint{
+			MoveNumber: i - 1,
+			Winrate:    result.RootInfo.Winrate,
+			ScoreLead:  result.RootInfo.ScoreLead,
+		})
 
 		// Skip if not enough visits
 		if result.RootInfo.Visits < thresholds.MinimumVisits {
@@ -125,39 +308,25 @@ func (e *Engine) ReviewGame(ctx context.Context, sgf string, thresholds *Mistake
 		// Get the actual played move
 		playedMove := currentMove.Location
 
-		// Find the played move in analysis
-		var playedInfo *MoveInfo
-		for _, mi := range result.MoveInfos {
-			if mi.Move == playedMove {
-				playedInfo = &mi
-				break
-			}
-		}
-
-		// If we didn't find the played move, it might be a pass or very bad
-		if playedInfo == nil && playedMove != "" {
-			// Estimate a low winrate for unanalyzed moves
-			playedInfo = &MoveInfo{
-				Move:    playedMove,
-				Winrate: result.RootInfo.Winrate * 0.8, // Rough estimate
-			}
-		}
-
 		// Get best move
 		if len(result.MoveInfos) == 0 {
 			continue
 		}
 		bestMove := result.MoveInfos[0]
+		judged = append(judged, i)
+		difficulty := moveDifficulty(result, i, color)
+		review.Difficulties = append(review.Difficulties, difficulty)
 
-		// Calculate winrate drop
-		var winrateDrop float64
-		if playedInfo != nil {
-			winrateDrop = bestMove.Winrate - playedInfo.Winrate
-		} else if playedMove == "" {
-			// Pass move when better moves exist
-			winrateDrop = bestMove.Winrate - result.RootInfo.Winrate
+		rank, prior := playedRank(result, playedMove, fullGame.BoardXSize, fullGame.BoardYSize)
+		if color == "B" {
+			blackAgreement.add(rank, prior)
+		} else {
+			whiteAgreement.add(rank, prior)
 		}
 
+		// Calculate winrate drop
+		winrateDrop, playedInfo := moveWinrateDrop(result, playedMove)
+
 		// Categorize mistake
 		switch {
 		case winrateDrop >= thresholds.Blunder:
@@ -168,7 +337,7 @@ func (e *Engine) ReviewGame(ctx context.Context, sgf string, thresholds *Mistake
 				BestMove:    bestMove.Move,
 				WinrateDrop: winrateDrop,
 				Category:    "blunder",
-				Explanation: fmt.Sprintf("This move loses %.1f%% win rate", winrateDrop*100),
+				Explanation: p.Sprintf("This move loses %.1f%% win rate", winrateDrop*100),
 			}
 			if playedInfo != nil {
 				mistake.PlayedWR = playedInfo.Winrate
@@ -176,6 +345,8 @@ func (e *Engine) ReviewGame(ctx context.Context, sgf string, thresholds *Mistake
 			}
 			mistake.BestWR = bestMove.Winrate
 			mistake.PolicyBest = bestMove.Prior
+			markTenuki(p, &review.Summary, &mistake, result, fullGame)
+			markDifficulty(&review.Summary, &mistake, difficulty)
 
 			review.Mistakes = append(review.Mistakes, mistake)
 			if color == "B" {
@@ -191,7 +362,7 @@ func (e *Engine) ReviewGame(ctx context.Context, sgf string, thresholds *Mistake
 				BestMove:    bestMove.Move,
 				WinrateDrop: winrateDrop,
 				Category:    "mistake",
-				Explanation: fmt.Sprintf("This move loses %.1f%% win rate", winrateDrop*100),
+				Explanation: p.Sprintf("This move loses %.1f%% win rate", winrateDrop*100),
 			}
 			if playedInfo != nil {
 				mistake.PlayedWR = playedInfo.Winrate
@@ -199,6 +370,8 @@ func (e *Engine) ReviewGame(ctx context.Context, sgf string, thresholds *Mistake
 			}
 			mistake.BestWR = bestMove.Winrate
 			mistake.PolicyBest = bestMove.Prior
+			markTenuki(p, &review.Summary, &mistake, result, fullGame)
+			markDifficulty(&review.Summary, &mistake, difficulty)
 
 			review.Mistakes = append(review.Mistakes, mistake)
 			if color == "B" {
@@ -216,28 +389,402 @@ func (e *Engine) ReviewGame(ctx context.Context, sgf string, thresholds *Mistake
 			} else {
 				whiteGoodMoves++
 			}
+			if good, ok := recognizeGoodMove(p, result, i, color, playedMove, thresholds); ok {
+				review.GoodMoves = append(review.GoodMoves, good)
+			}
 		}
 	}
 
 	// Calculate summary statistics
 	review.Summary.TotalMoves = len(fullGame.Moves)
+	review.Summary.AnalyzedMoves = analyzed
+	if review.Summary.Partial {
+		e.logger.Warn("Game review stopped early", "analyzedMoves", analyzed, "totalMoves", len(fullGame.Moves))
+	}
 	if blackMoves > 0 {
 		review.Summary.BlackAccuracy = float64(blackGoodMoves) / float64(blackMoves) * 100
 	}
 	if whiteMoves > 0 {
 		review.Summary.WhiteAccuracy = float64(whiteGoodMoves) / float64(whiteMoves) * 100
 	}
+	review.Summary.BlackTop1Agreement, review.Summary.BlackTop3Agreement, review.Summary.BlackAveragePolicy = blackAgreement.rates()
+	review.Summary.WhiteTop1Agreement, review.Summary.WhiteTop3Agreement, review.Summary.WhiteAveragePolicy = whiteAgreement.rates()
 
 	// Estimate playing level based on accuracy and mistakes
 	review.Summary.EstimatedLevel = estimateLevel(review.Summary)
 
+	if fullGame.Result != "" && !review.Summary.Partial {
+		review.Summary.Result = e.checkResult(ctx, p, fullGame, thresholds.MinimumVisits)
+	}
+	review.Notes = e.reviewNotes(ctx, p, fullGame, positions, thresholds)
+	review.Analyses = make([]*AnalysisResult, len(positions))
+	for i, position := range positions {
+		review.Analyses[i] = position.result
+	}
+	review.Findings = e.runAnalyzers(ctx, fullGame, review, judged)
+	review.TimePressure = timePressure(p, fullGame, review, judged)
+	if thresholds.Themes {
+		review.Themes = mistakeThemes(ctx, e.Analyze, p, fullGame, review, thresholds.MinimumVisits)
+	}
+
+	AttributeReview(review, fullGame.GameInfo)
 	return review, nil
 }
 
+// reviewProgressInterval is the number of positions between progress logs
+// of a review.
+const reviewProgressInterval = 25
+
+// DefaultDecidedMoves is the number of consecutive positions with a pinned
+// win rate that decide a game when only the win rate is set.
+const DefaultDecidedMoves = 10
+
+// decidedGameVisits is the number of visits spent on each position after a
+// game was decided, enough to trace its evaluation.
+const decidedGameVisits = 10
+
+// reviewedPosition is the analysis of the position before a move, or why
+// there is none.
+type reviewedPosition struct {
+	result   *AnalysisResult
+	err      error
+	canceled bool // The analysis failed because the context was done
+	shallow  bool // After the game was decided; the move is not judged
+}
+
+// decisionTracker finds where a game was decided from the evaluations of
+// its positions in move order: the first of moves consecutive positions
+// in which Black's win rate is at least winrate, or at most one minus it.
+type decisionTracker struct {
+	winrate float64
+	moves   int
+	side    string // Player favored by the current run of positions
+	start   int    // First position of the run
+	run     int
+	// decidedAt is the position after which the game was decided and
+	// confirmedAt the position that confirmed it, both 0 until then
+	decidedAt   int
+	confirmedAt int
+}
+
+// observe takes the evaluation of the position after n moves, nil if it
+// could not be analyzed, and reports whether the game is decided.
+func (d *decisionTracker) observe(n int, result *AnalysisResult) bool {
+	if d.decidedAt > 0 {
+		return true
+	}
+	side := ""
+	if result != nil && n > 0 {
+		switch {
+		case result.RootInfo.Winrate >= d.winrate:
+			side = "B"
+		case result.RootInfo.Winrate <= 1-d.winrate:
+			side = "W"
+		}
+	}
+	if side == "" || side != d.side {
+		d.side, d.start, d.run = side, n, 0
+	}
+	if side != "" {
+		d.run++
+	}
+	if d.run >= d.moves {
+		d.decidedAt, d.confirmedAt = d.start, n
+		return true
+	}
+	return false
+}
+
+// analyzeReviewPositions analyzes the position before each move of a game,
+// thresholds.Concurrency positions at a time. Positions not analyzed
+// because the context was done are left empty. When thresholds set a
+// decided game, positions sent after it was found decided are analyzed at
+// decidedGameVisits, and every position after the one that confirmed it is
+// marked shallow. Progress, with the mistakes found so far, is reported to
+// the context's ProgressFunc as each position finishes. A position in
+// repeats, mapped to the earlier position it repeats, is not sent but
+// given the earlier position's analysis. It returns the move after which
+// the game was decided, or 0.
+func (e *Engine) analyzeReviewPositions(ctx context.Context, game *Position,
+	thresholds *MistakeThresholds, repeats map[int]int) ([]reviewedPosition, int) {
+	positions := make([]reviewedPosition, len(game.Moves))
+	copies := map[int][]int{} // Positions given each analyzed position's analysis
+	for i, earlier := range repeats {
+		copies[earlier] = append(copies[earlier], i)
+	}
+	workers := max(thresholds.Concurrency, 1)
+	var tracker *decisionTracker
+	if thresholds.DecidedWinrate > 0 && thresholds.DecidedMoves > 0 {
+		tracker = &decisionTracker{winrate: thresholds.DecidedWinrate, moves: thresholds.DecidedMoves}
+	}
+	var mu sync.Mutex
+	finished := make([]bool, len(positions))
+	next, decided := 0, false // Positions observed in order, and whether the game is decided
+	done, mistakes := 0, 0    // Positions analyzed, and the mistakes found in them so far
+
+	type reviewJob struct {
+		index   int
+		shallow bool
+	}
+	jobs := make(chan reviewJob)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				i := job.index
+				result, err := e.Analyze(ctx, reviewJobRequest(game, i+1, thresholds, job.shallow))
+
+				mu.Lock()
+				for _, n := range append([]int{i}, copies[i]...) {
+					positions[n] = reviewedPosition{result: result, err: err, canceled: err != nil && ctx.Err() != nil}
+					finished[n] = true
+					done++
+					if err == nil && !job.shallow && isReviewMistake(result, game.Moves[n].Location, thresholds) {
+						mistakes++
+					}
+				}
+				for ; tracker != nil && next < len(positions) && finished[next]; next++ {
+					if !decided && tracker.observe(next, positions[next].result) {
+						decided = true
+						e.logger.Info("Game decided, analyzing the rest at minimal visits",
+							"decidedAtMove", tracker.decidedAt, "totalMoves", len(game.Moves))
+					}
+				}
+				reportProgress(ctx, float64(done), float64(len(positions)),
+					fmt.Sprintf("Analyzed %d of %d positions, %d mistakes found", done, len(positions), mistakes))
+				if done%reviewProgressInterval == 0 {
+					e.logger.Debug("Game review progress", "analyzedMoves", done, "totalMoves", len(game.Moves))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range positions {
+		if ctx.Err() != nil {
+			break
+		}
+		if _, repeated := repeats[i]; repeated {
+			continue
+		}
+		mu.Lock()
+		shallow := decided
+		mu.Unlock()
+		jobs <- reviewJob{index: i, shallow: shallow}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if tracker == nil || tracker.decidedAt == 0 {
+		return positions, 0
+	}
+	for i := tracker.confirmedAt + 1; i < len(positions); i++ {
+		positions[i].shallow = true
+	}
+	return positions, tracker.decidedAt
+}
+
+// ReviewRequest returns the query a review sends for the position before
+// move n of a game, counted from 1, so the move can be examined again
+// from the cache.
+func ReviewRequest(game *Position, n int, thresholds *MistakeThresholds) *AnalysisRequest {
+	req := &AnalysisRequest{
+		Position: &Position{
+			Rules:         game.Rules,
+			BoardXSize:    game.BoardXSize,
+			BoardYSize:    game.BoardYSize,
+			Moves:         game.Moves[:n-1],
+			InitialStones: game.InitialStones,
+		},
+		IncludePolicy:    true,
+		IncludeOwnership: false,
+	}
+	if thresholds.MinimumVisits > 0 {
+		visits := thresholds.MinimumVisits
+		req.MaxVisits = &visits
+	}
+	return req
+}
+
+// reviewJobRequest returns the query for the position before move n, as
+// ReviewRequest, searched at no more than decidedGameVisits once the game
+// is decided, whatever the review's visits.
+func reviewJobRequest(game *Position, n int, thresholds *MistakeThresholds, decided bool) *AnalysisRequest {
+	req := ReviewRequest(game, n, thresholds)
+	if decided {
+		visits := decidedGameVisits
+		if req.MaxVisits != nil {
+			visits = min(*req.MaxVisits, decidedGameVisits)
+		}
+		req.MaxVisits = &visits
+	}
+	return req
+}
+
+// moveWinrateDrop returns the win rate the played move loses compared with
+// KataGo's best move, and the analysis of the played move. result must
+// have at least one candidate move.
+func moveWinrateDrop(result *AnalysisResult, playedMove string) (float64, *MoveInfo) {
+	// Find the played move in analysis
+	var playedInfo *MoveInfo
+	for _, mi := range result.MoveInfos {
+		if mi.Move == playedMove {
+			playedInfo = &mi
+			break
+		}
+	}
+
+	// If we didn't find the played move, it might be a pass or very bad
+	if playedInfo == nil && playedMove != "" {
+		// Estimate a low winrate for unanalyzed moves
+		playedInfo = &MoveInfo{
+			Move:    playedMove,
+			Winrate: result.RootInfo.Winrate * 0.8, // Rough estimate
+		}
+	}
+
+	bestMove := result.MoveInfos[0]
+	if playedInfo != nil {
+		return bestMove.Winrate - playedInfo.Winrate, playedInfo
+	}
+	// Pass move when better moves exist
+	return bestMove.Winrate - result.RootInfo.Winrate, nil
+}
+
+// isReviewMistake reports whether ReviewGame would count the move played
+// from a position as a mistake or blunder.
+func isReviewMistake(result *AnalysisResult, playedMove string, thresholds *MistakeThresholds) bool {
+	if result.RootInfo.Visits < thresholds.MinimumVisits || len(result.MoveInfos) == 0 {
+		return false
+	}
+	drop, _ := moveWinrateDrop(result, playedMove)
+	return drop >= thresholds.Mistake
+}
+
+// checkResult analyzes a game's final position and compares the evaluation
+// with the recorded result, returning nil when either is unavailable.
+func (e *Engine) checkResult(ctx context.Context, p *i18n.Printer, game *Position, visits int) *ResultCheck {
+	if visits < resultCheckVisits {
+		visits = resultCheckVisits
+	}
+	final, err := e.Analyze(ctx, &AnalysisRequest{Position: game, MaxVisits: &visits})
+	if err != nil {
+		e.logger.Error("Failed to analyze final position to check the result", "error", err)
+		return nil
+	}
+	check, ok := CheckResult(p, game.Result, final)
+	if !ok {
+		return nil
+	}
+	if check.Disputed() {
+		e.logger.Info("Recorded result disagrees with the final position",
+			"recorded", check.Recorded, "estimated", check.Estimated, "verdict", check.Verdict)
+	}
+	return check
+}
+
+// AttributeReview adds a game's metadata to its review and names the
+// player of each mistake.
+func AttributeReview(review *GameReview, info GameInfo) {
+	if !info.Known() {
+		return
+	}
+	review.Game = &info
+	for i := range review.Mistakes {
+		review.Mistakes[i].Player = info.Player(review.Mistakes[i].Color)
+	}
+	for i := range review.GoodMoves {
+		review.GoodMoves[i].Player = info.Player(review.GoodMoves[i].Color)
+	}
+}
+
+// playedRank returns the rank of a played move among KataGo's choices, or
+// -1 if KataGo did not consider it, and its policy prior. Moves KataGo did
+// not search take their prior from the policy, which reviews request.
+func playedRank(result *AnalysisResult, playedMove string, xSize, ySize int) (int, float64) {
+	if playedMove == "" {
+		playedMove = "pass"
+	}
+	for i, mi := range result.MoveInfos {
+		if mi.Move == playedMove {
+			return i, mi.Prior
+		}
+	}
+	index := xSize * ySize
+	if playedMove != "pass" {
+		x, y := parseCoord(playedMove, ySize)
+		if x < 0 || x >= xSize || y < 0 || y >= ySize {
+			return -1, 0
+		}
+		index = y*xSize + x
+	}
+	if index < len(result.Policy) && result.Policy[index] > 0 {
+		return -1, result.Policy[index]
+	}
+	return -1, 0
+}
+
+// recognizeGoodMove praises a played move that matches KataGo's first
+// choice when its policy prior was below brilliantPrior, or when it keeps
+// at least a mistake's worth of win rate over the second-best option.
+func recognizeGoodMove(p *i18n.Printer, result *AnalysisResult, moveNumber int, color, playedMove string,
+	thresholds *MistakeThresholds) (GoodMove, bool) {
+	if len(result.MoveInfos) == 0 || playedMove == "" || result.MoveInfos[0].Move != playedMove {
+		return GoodMove{}, false
+	}
+	best := result.MoveInfos[0]
+	good := GoodMove{
+		MoveNumber: moveNumber,
+		Color:      color,
+		PlayedMove: playedMove,
+		PlayedWR:   best.Winrate,
+		Policy:     best.Prior,
+	}
+	if len(result.MoveInfos) > 1 {
+		// The best move is the mover's, whichever side win rates are
+		// reported for
+		good.SecondBest = result.MoveInfos[1].Move
+		good.Gain = math.Abs(best.Winrate - result.MoveInfos[1].Winrate)
+	}
+	switch {
+	case best.Prior < brilliantPrior:
+		good.Category = "brilliant"
+		good.Explanation = p.Sprintf("A brilliant find: KataGo's best move despite a %.1f%% policy prior", best.Prior*100)
+	case good.SecondBest != "" && good.Gain >= thresholds.Mistake:
+		good.Category = "excellent"
+		good.Explanation = p.Sprintf("The key move: %.1f%% win rate better than %s", good.Gain*100, good.SecondBest)
+	default:
+		return GoodMove{}, false
+	}
+	return good, true
+}
+
+// markTenuki flags a mistake that ignored a hot area and counts it.
+func markTenuki(p *i18n.Printer, summary *ReviewSummary, mistake *Mistake, result *AnalysisResult, game *Position) {
+	hotArea, ok := detectTenuki(result, mistake.PlayedMove, game.BoardXSize, game.BoardYSize)
+	if !ok {
+		return
+	}
+	mistake.Tenuki = true
+	mistake.HotArea = hotArea
+	mistake.Explanation = p.Sprintf("This move tenukis from the hot area around %s and loses %.1f%% win rate",
+		strings.Join(hotArea, ", "), mistake.WinrateDrop*100)
+	if mistake.Color == "B" {
+		summary.BlackTenukis++
+	} else {
+		summary.WhiteTenukis++
+	}
+}
+
 // estimateLevel provides a rough estimate of playing strength.
 func estimateLevel(summary ReviewSummary) string {
 	avgAccuracy := (summary.BlackAccuracy + summary.WhiteAccuracy) / 2
-	blunderRate := float64(summary.BlackBlunders+summary.WhiteBlunders) / float64(summary.TotalMoves)
+	moves := summary.TotalMoves
+	if summary.Partial {
+		moves = summary.AnalyzedMoves
+	}
+	blunderRate := float64(summary.BlackBlunders+summary.WhiteBlunders) / float64(moves)
 
 	switch {
 	case avgAccuracy > 95 && blunderRate < 0.01:
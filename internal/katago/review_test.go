@@ -1,7 +1,12 @@
 package katago
 
 import (
+	"context"
 	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
 )
 
 func TestDefaultMistakeThresholds(t *testing.T) {
@@ -175,3 +180,179 @@ func TestGameReviewStruct(t *testing.T) {
 		t.Errorf("Expected 50 total moves, got %d", review.Summary.TotalMoves)
 	}
 }
+
+func TestReviewGameStopsAtDeadline(t *testing.T) {
+	cfg := &config.KataGoConfig{BinaryPath: "katago", MaxVisits: 10, MaxTime: 1.0}
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := NewEngine(cfg, logger, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	review, err := engine.ReviewGame(ctx, "(;GM[1]FF[4]SZ[19];B[dd];W[pp];B[dp])", nil)
+	if err != nil {
+		t.Fatalf("Expected partial review, got error: %v", err)
+	}
+	if !review.Summary.Partial {
+		t.Error("Expected review to be marked partial")
+	}
+	if review.Summary.AnalyzedMoves != 0 || review.Summary.TotalMoves != 3 {
+		t.Errorf("Expected 0 of 3 moves analyzed, got %d of %d",
+			review.Summary.AnalyzedMoves, review.Summary.TotalMoves)
+	}
+}
+
+func TestRecognizeGoodMove(t *testing.T) {
+	thresholds := DefaultMistakeThresholds()
+	analysis := func(infos ...MoveInfo) *AnalysisResult {
+		return &AnalysisResult{MoveInfos: infos}
+	}
+	tests := []struct {
+		name     string
+		result   *AnalysisResult
+		color    string
+		played   string
+		category string
+		want     string
+	}{
+		{
+			name:     "low prior best move",
+			result:   analysis(MoveInfo{Move: "R4", Winrate: 0.62, Prior: 0.018}, MoveInfo{Move: "D4", Winrate: 0.6, Prior: 0.4}),
+			color:    "B",
+			played:   "R4",
+			category: "brilliant",
+			want:     "A brilliant find: KataGo's best move despite a 1.8% policy prior",
+		},
+		{
+			// Win rates reported for Black fall with White's better moves
+			name:     "only move for White",
+			result:   analysis(MoveInfo{Move: "C3", Winrate: 0.3, Prior: 0.3}, MoveInfo{Move: "D5", Winrate: 0.42, Prior: 0.2}),
+			color:    "W",
+			played:   "C3",
+			category: "excellent",
+			want:     "The key move: 12.0% win rate better than D5",
+		},
+		{
+			name:   "ordinary best move",
+			result: analysis(MoveInfo{Move: "C3", Winrate: 0.5, Prior: 0.3}, MoveInfo{Move: "D5", Winrate: 0.49, Prior: 0.2}),
+			color:  "B",
+			played: "C3",
+		},
+		{
+			name:   "not the best move",
+			result: analysis(MoveInfo{Move: "C3", Winrate: 0.5, Prior: 0.01}, MoveInfo{Move: "D5", Winrate: 0.3, Prior: 0.2}),
+			color:  "B",
+			played: "D5",
+		},
+		{
+			name:   "pass",
+			result: analysis(MoveInfo{Move: "pass", Winrate: 0.5, Prior: 0.01}),
+			color:  "B",
+			played: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			good, ok := recognizeGoodMove(i18n.English, tt.result, 7, tt.color, tt.played, thresholds)
+			if ok != (tt.category != "") {
+				t.Fatalf("Expected recognized %v, got %+v", tt.category != "", good)
+			}
+			if ok && (good.Category != tt.category || good.Explanation != tt.want || good.MoveNumber != 7 || good.Color != tt.color) {
+				t.Errorf("Unexpected good move: %+v", good)
+			}
+		})
+	}
+}
+
+func TestPlayedRank(t *testing.T) {
+	// A 3x3 board: the policy lists nine points, then pass
+	result := &AnalysisResult{
+		MoveInfos: []MoveInfo{{Move: "B2", Prior: 0.5}, {Move: "A3", Prior: 0.2}, {Move: "C1", Prior: 0.1}},
+		Policy:    []float64{0.2, 0.05, -1, 0.03, 0.5, 0.02, 0.01, 0.04, 0.1, 0.05},
+	}
+	tests := []struct {
+		move  string
+		rank  int
+		prior float64
+	}{
+		{"B2", 0, 0.5},
+		{"C1", 2, 0.1},
+		{"C2", -1, 0.02}, // From the policy
+		{"", -1, 0.05},   // Pass
+		{"C3", -1, 0},    // Illegal
+		{"Z9", -1, 0},
+	}
+	for _, tt := range tests {
+		rank, prior := playedRank(result, tt.move, 3, 3)
+		if rank != tt.rank || prior != tt.prior {
+			t.Errorf("playedRank(%q) = %d, %v, want %d, %v", tt.move, rank, prior, tt.rank, tt.prior)
+		}
+	}
+
+	var a agreement
+	for _, rank := range []int{0, 2, -1, 0} {
+		a.add(rank, 0.25)
+	}
+	if top1, top3, policy := a.rates(); top1 != 50 || top3 != 75 || policy != 0.25 {
+		t.Errorf("Expected 50%%/75%% agreement and 0.25 policy, got %v/%v and %v", top1, top3, policy)
+	}
+}
+
+func TestDecisionTracker(t *testing.T) {
+	eval := func(winrate float64) *AnalysisResult {
+		return &AnalysisResult{RootInfo: RootInfo{Winrate: winrate}}
+	}
+	d := &decisionTracker{winrate: 0.9, moves: 3}
+	// A White run broken by a failed analysis, then a Black run from move 6
+	evals := []*AnalysisResult{eval(0.5), eval(0.05), eval(0.08), nil, eval(0.04), eval(0.6), eval(0.92), eval(0.95), eval(0.97), eval(0.5)}
+	decided := -1
+	for n, result := range evals {
+		if d.observe(n, result) && decided < 0 {
+			decided = n
+		}
+	}
+	if decided != 8 || d.decidedAt != 6 || d.confirmedAt != 8 {
+		t.Errorf("Expected the game decided after move 6 and confirmed at 8, got %d, %+v", decided, d)
+	}
+
+	// The starting position does not count
+	d = &decisionTracker{winrate: 0.9, moves: 2}
+	if d.observe(0, eval(0.95)) || d.observe(1, eval(0.95)) || !d.observe(2, eval(0.95)) || d.decidedAt != 1 {
+		t.Errorf("Expected the game decided after move 1, got %+v", d)
+	}
+}
+
+func TestReviewJobRequestDecided(t *testing.T) {
+	game := &Position{BoardXSize: 9, BoardYSize: 9, Moves: []Move{{Color: "B", Location: "E5"}, {Color: "W", Location: "C3"}}}
+	visits := func(req *AnalysisRequest) int {
+		if req.MaxVisits == nil {
+			return 0
+		}
+		return *req.MaxVisits
+	}
+	tests := []struct {
+		name          string
+		minimumVisits int
+		decided       bool
+		want          int // 0 for the engine's default
+	}{
+		{"default visits", 0, false, 0},
+		{"default visits, decided", 0, true, decidedGameVisits},
+		{"review visits, decided", 50, true, decidedGameVisits},
+		{"fewer review visits, decided", 5, true, 5},
+		{"review visits", 50, false, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thresholds := DefaultMistakeThresholds()
+			thresholds.MinimumVisits = tt.minimumVisits
+			req := reviewJobRequest(game, 2, thresholds, tt.decided)
+			if got := visits(req); got != tt.want {
+				t.Errorf("Expected %d visits, got %d", tt.want, got)
+			}
+			if len(req.Position.Moves) != 1 {
+				t.Errorf("Expected the position before move 2, got %v", req.Position.Moves)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package katago
+
+import (
+	"math"
+	"regexp"
+	"sort"
+)
+
+// Kinds of schema issue found in a KataGo response.
+const (
+	SchemaUnknownField   = "unknown_field"   // A field this server does not know
+	SchemaMalformedField = "malformed_field" // A known field with a value of the wrong type
+	SchemaInvalidJSON    = "invalid_json"    // A line that is not a JSON object
+)
+
+// SchemaIssue is a difference between a KataGo response and the format this
+// server expects. Field is the path of the field, such as
+// "moveInfos.winrate".
+type SchemaIssue struct {
+	Issue string `json:"issue"`
+	Field string `json:"field"`
+}
+
+// fieldKind is the expected type of a response field.
+type fieldKind int
+
+const (
+	kindAny fieldKind = iota
+	kindNumber
+	kindInt
+	kindString
+	kindBool
+	kindNumbers
+	kindStrings
+	kindInts
+	kindObject
+	kindObjects
+)
+
+// responseFields are the top-level fields of analysis and action responses.
+var responseFields = map[string]fieldKind{
+	"id":             kindString,
+	"isDuringSearch": kindBool,
+	"turnNumber":     kindInt,
+	"moveInfos":      kindObjects,
+	"rootInfo":       kindObject,
+	"ownership":      kindNumbers,
+	"ownershipStdev": kindNumbers,
+	"policy":         kindNumbers,
+	"humanPolicy":    kindNumbers,
+	"movesOwnership": kindObject,
+	"noResults":      kindBool,
+	"error":          kindAny, // A message, or an object with one
+	"field":          kindString,
+	"warning":        kindString,
+	"action":         kindString,
+	"version":        kindString,
+	"git_hash":       kindString,
+	"terminateId":    kindString,
+	"turnNumbers":    kindInts,
+	"models":         kindObjects,
+}
+
+// moveInfoFields are the fields of each entry of moveInfos.
+var moveInfoFields = map[string]fieldKind{
+	"move":               kindString,
+	"visits":             kindInt,
+	"edgeVisits":         kindInt,
+	"winrate":            kindNumber,
+	"scoreMean":          kindNumber,
+	"scoreStdev":         kindNumber,
+	"scoreLead":          kindNumber,
+	"scoreSelfplay":      kindNumber,
+	"prior":              kindNumber,
+	"humanPrior":         kindNumber,
+	"utility":            kindNumber,
+	"utilityLcb":         kindNumber,
+	"lcb":                kindNumber,
+	"order":              kindInt,
+	"isSymmetryOf":       kindString,
+	"pv":                 kindStrings,
+	"pvVisits":           kindInts,
+	"pvEdgeVisits":       kindInts,
+	"ownership":          kindNumbers,
+	"ownershipStdev":     kindNumbers,
+	"weight":             kindNumber,
+	"edgeWeight":         kindNumber,
+	"playSelectionValue": kindNumber,
+}
+
+// rootInfoFields are the fields of rootInfo.
+var rootInfoFields = map[string]fieldKind{
+	"winrate":               kindNumber,
+	"scoreLead":             kindNumber,
+	"scoreMean":             kindNumber,
+	"scoreStdev":            kindNumber,
+	"scoreSelfplay":         kindNumber,
+	"utility":               kindNumber,
+	"visits":                kindInt,
+	"edgeVisits":            kindInt,
+	"weight":                kindNumber,
+	"thisHash":              kindString,
+	"symHash":               kindString,
+	"currentPlayer":         kindString,
+	"rawWinrate":            kindNumber,
+	"rawLead":               kindNumber,
+	"rawScoreSelfplay":      kindNumber,
+	"rawScoreSelfplayStdev": kindNumber,
+	"rawNoResultProb":       kindNumber,
+	"rawStWrError":          kindNumber,
+	"rawStScoreError":       kindNumber,
+	"rawVarTimeLeft":        kindNumber,
+	"humanWinrate":          kindNumber,
+	"humanScoreMean":        kindNumber,
+	"humanScoreStdev":       kindNumber,
+	"humanStWrError":        kindNumber,
+	"humanStScoreError":     kindNumber,
+}
+
+// ValidateResponse compares a decoded KataGo response with the format this
+// server expects and returns each field that is unknown or malformed, once
+// per field, sorted by field. Unknown fields are usually harmless additions
+// in a newer KataGo; malformed ones mean the response would be misread.
+func ValidateResponse(raw map[string]interface{}) []SchemaIssue {
+	found := make(map[SchemaIssue]bool)
+	validateFields(raw, responseFields, "", found)
+	if root, ok := raw["rootInfo"].(map[string]interface{}); ok {
+		validateFields(root, rootInfoFields, "rootInfo.", found)
+	}
+	if infos, ok := raw["moveInfos"].([]interface{}); ok {
+		for _, entry := range infos {
+			info, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			validateFields(info, moveInfoFields, "moveInfos.", found)
+		}
+	}
+
+	issues := make([]SchemaIssue, 0, len(found))
+	for issue := range found {
+		issues = append(issues, issue)
+	}
+	sort.Slice(issues, func(a, b int) bool {
+		if issues[a].Field != issues[b].Field {
+			return issues[a].Field < issues[b].Field
+		}
+		return issues[a].Issue < issues[b].Issue
+	})
+	return issues
+}
+
+// validateFields checks the fields of one object against their kinds.
+func validateFields(object map[string]interface{}, fields map[string]fieldKind, prefix string, found map[SchemaIssue]bool) {
+	for name, value := range object {
+		kind, known := fields[name]
+		switch {
+		case !known:
+			found[SchemaIssue{Issue: SchemaUnknownField, Field: prefix + name}] = true
+		case !hasKind(value, kind):
+			found[SchemaIssue{Issue: SchemaMalformedField, Field: prefix + name}] = true
+		}
+	}
+}
+
+// hasKind reports whether a decoded JSON value has the expected kind.
+func hasKind(value interface{}, kind fieldKind) bool {
+	switch kind {
+	case kindNumber:
+		_, ok := value.(float64)
+		return ok
+	case kindInt:
+		return isInt(value)
+	case kindString:
+		_, ok := value.(string)
+		return ok
+	case kindBool:
+		_, ok := value.(bool)
+		return ok
+	case kindNumbers, kindInts:
+		list, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, v := range list {
+			if _, ok := v.(float64); !ok || (kind == kindInts && !isInt(v)) {
+				return false
+			}
+		}
+		return true
+	case kindStrings:
+		list, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, v := range list {
+			if _, ok := v.(string); !ok {
+				return false
+			}
+		}
+		return true
+	case kindObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case kindObjects:
+		list, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, v := range list {
+			if _, ok := v.(map[string]interface{}); !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+// isInt reports whether a decoded JSON value is a whole number.
+func isInt(value interface{}) bool {
+	n, ok := value.(float64)
+	return ok && n == math.Trunc(n)
+}
+
+// responseIDPattern finds the query ID in a line that is not valid JSON.
+var responseIDPattern = regexp.MustCompile(`"id"\s*:\s*"((?:[^"\\]|\\.)*)"`)
+
+// responseID returns the query ID of a line that could not be decoded, or
+// "" if it has none.
+func responseID(line string) string {
+	match := responseIDPattern.FindStringSubmatch(line)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
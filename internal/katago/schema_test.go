@@ -0,0 +1,58 @@
+package katago
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValidateResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []SchemaIssue
+	}{
+		{
+			name: "expected format",
+			line: `{"id":"q1","isDuringSearch":false,"turnNumber":2,"rootInfo":{"winrate":0.5,"visits":100,"currentPlayer":"B"},` +
+				`"moveInfos":[{"move":"D4","visits":60,"winrate":0.52,"pv":["D4","Q16"],"order":0}],"ownership":[0.1,-0.2]}`,
+			want: []SchemaIssue{},
+		},
+		{
+			name: "action response",
+			line: `{"id":"v","action":"query_version","version":"1.15.3","git_hash":"abc"}`,
+			want: []SchemaIssue{},
+		},
+		{
+			name: "drifted fields",
+			line: `{"id":"q1","turnNumber":1.5,"newField":true,"rootInfo":{"winrate":"0.5","newRoot":1},` +
+				`"moveInfos":[{"move":"D4","pv":"D4 Q16"},{"move":"Q16","visits":10,"extra":0}]}`,
+			want: []SchemaIssue{
+				{Issue: SchemaUnknownField, Field: "moveInfos.extra"},
+				{Issue: SchemaMalformedField, Field: "moveInfos.pv"},
+				{Issue: SchemaUnknownField, Field: "newField"},
+				{Issue: SchemaUnknownField, Field: "rootInfo.newRoot"},
+				{Issue: SchemaMalformedField, Field: "rootInfo.winrate"},
+				{Issue: SchemaMalformedField, Field: "turnNumber"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var raw map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.line), &raw); err != nil {
+				t.Fatal(err)
+			}
+			if got := ValidateResponse(raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateResponse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if id := responseID(`{"id":"q\"7","rootInfo":{"winrate":nan}}`); id != `q\"7` {
+		t.Errorf("Expected the ID of a line that is not JSON, got %q", id)
+	}
+	if id := responseID("KataGo crashed"); id != "" {
+		t.Errorf("Expected no ID, got %q", id)
+	}
+}
@@ -0,0 +1,219 @@
+package katago
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+)
+
+// AnalysisSettings is the configuration an engine analyzes with, to be
+// recorded alongside a review so that it can be reproduced. MaxVisits,
+// MaxTime and NumSearchThreads are the values in KataGo's config file when
+// it sets them, as KataGo applies those to queries that do not override
+// them, else the server's configured values.
+type AnalysisSettings struct {
+	Engine           string              `json:"engine"`
+	KataGoVersion    string              `json:"katagoVersion,omitempty"` // Unset until the engine has started
+	GitHash          string              `json:"gitHash,omitempty"`
+	Model            *ModelFile          `json:"model,omitempty"`
+	HumanModel       *ModelFile          `json:"humanModel,omitempty"`
+	HumanProfile     string              `json:"humanProfile,omitempty"`
+	DefaultRules     string              `json:"defaultRules"` // Rules of positions that name none
+	MaxVisits        int                 `json:"maxVisits"`
+	MaxTime          float64             `json:"maxTime"`
+	NumSearchThreads int                 `json:"numSearchThreads"`
+	Search           config.SearchConfig `json:"search"` // Settings sent with every query
+	Cache            CacheSettings       `json:"cache"`
+	ConfigFile       *ConfigFile         `json:"configFile,omitempty"`
+}
+
+// ModelFile identifies a neural network file.
+type ModelFile struct {
+	Name      string `json:"name"` // File name without its extensions
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"sizeBytes"`
+	SHA256    string `json:"sha256"`
+}
+
+// CacheSettings describes the analysis cache. Credentials are left out.
+type CacheSettings struct {
+	Enabled      bool   `json:"enabled"`
+	MaxItems     int    `json:"maxItems,omitempty"`
+	MaxSizeBytes int64  `json:"maxSizeBytes,omitempty"`
+	TTLSeconds   int    `json:"ttlSeconds,omitempty"`
+	AsyncRefresh bool   `json:"asyncRefresh,omitempty"`
+	Redis        string `json:"redis,omitempty"` // Address of the shared cache
+	Scope        string `json:"scope,omitempty"` // Namespace of the engine's entries
+	// Fingerprint identifies the models, KataGo version and configuration
+	// the engine's entries were computed with
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// ConfigFile is KataGo's own config file.
+type ConfigFile struct {
+	Path     string            `json:"path"`
+	SHA256   string            `json:"sha256"`
+	Settings map[string]string `json:"settings"` // Its key = value lines
+	Contents string            `json:"contents"`
+}
+
+// DescribeSettings reports the settings of the engine with the given name
+// and configuration. The model files are hashed the first time they are
+// described, which takes a few seconds for large networks.
+func DescribeSettings(name string, cfg *config.KataGoConfig, caps Capabilities, cacheCfg *config.CacheConfig) (*AnalysisSettings, error) {
+	settings := &AnalysisSettings{
+		Engine:           name,
+		KataGoVersion:    caps.Version,
+		GitHash:          caps.GitHash,
+		HumanProfile:     cfg.HumanProfile,
+		DefaultRules:     DefaultRules,
+		MaxVisits:        cfg.MaxVisits,
+		MaxTime:          cfg.MaxTime,
+		NumSearchThreads: cfg.NumThreads,
+		Search:           cfg.Search,
+	}
+
+	var err error
+	if cfg.ModelPath != "" {
+		if settings.Model, err = describeModel(cfg.ModelPath); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.HumanModelPath != "" {
+		if settings.HumanModel, err = describeModel(cfg.HumanModelPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if cacheCfg != nil && cacheCfg.Enabled {
+		settings.Cache = CacheSettings{
+			Enabled:      true,
+			MaxItems:     cacheCfg.MaxItems,
+			MaxSizeBytes: cacheCfg.MaxSizeBytes,
+			TTLSeconds:   cacheCfg.TTLSeconds,
+			AsyncRefresh: cacheCfg.AsyncRefresh,
+			Redis:        cacheCfg.Redis.Addr,
+			Fingerprint:  cacheFingerprint(cfg, caps.Version),
+		}
+		// Matches the scope the pool gives engines other than the default
+		if name != config.DefaultEngineName {
+			settings.Cache.Scope = name
+		}
+	}
+
+	if cfg.ConfigPath != "" {
+		if settings.ConfigFile, err = readConfigFile(cfg.ConfigPath); err != nil {
+			return nil, err
+		}
+		if v, ok := settings.ConfigFile.Settings["maxVisits"]; ok {
+			if visits, err := strconv.Atoi(v); err == nil {
+				settings.MaxVisits = visits
+			}
+		}
+		if v, ok := settings.ConfigFile.Settings["maxTime"]; ok {
+			if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+				settings.MaxTime = seconds
+			}
+		}
+		if v, ok := settings.ConfigFile.Settings["numSearchThreads"]; ok {
+			if threads, err := strconv.Atoi(v); err == nil {
+				settings.NumSearchThreads = threads
+			}
+		}
+	}
+	return settings, nil
+}
+
+// modelHash is the hash of a model file as it was when it was hashed.
+type modelHash struct {
+	size    int64
+	modTime time.Time
+	sha256  string
+}
+
+// modelHashes remembers model hashes by path, so that a model is only
+// hashed again when the file changes.
+var modelHashes sync.Map
+
+// describeModel identifies a model file by name and content hash.
+func describeModel(path string) (*ModelFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model: %w", err)
+	}
+	model := &ModelFile{
+		Name:      modelName(path),
+		Path:      path,
+		SizeBytes: info.Size(),
+	}
+	if cached, ok := modelHashes.Load(path); ok {
+		hash := cached.(modelHash)
+		if hash.size == info.Size() && hash.modTime.Equal(info.ModTime()) {
+			model.SHA256 = hash.sha256
+			return model, nil
+		}
+	}
+
+	file, err := os.Open(path) // #nosec G304 -- path is operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model: %w", err)
+	}
+	defer file.Close()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return nil, fmt.Errorf("failed to hash model: %w", err)
+	}
+	model.SHA256 = hex.EncodeToString(hasher.Sum(nil))
+	modelHashes.Store(path, modelHash{size: info.Size(), modTime: info.ModTime(), sha256: model.SHA256})
+	return model, nil
+}
+
+// modelName returns a model's file name without extensions such as
+// ".bin.gz", e.g. "kata1-b18c384nbt-s9131461376-d4087399203".
+func modelName(path string) string {
+	name := filepath.Base(path)
+	for _, ext := range []string{".gz", ".bin", ".txt"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// readConfigFile reads KataGo's config file and its key = value settings.
+func readConfigFile(path string) (*ConfigFile, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is operator configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read KataGo config: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	file := &ConfigFile{
+		Path:     path,
+		SHA256:   hex.EncodeToString(sum[:]),
+		Settings: make(map[string]string),
+		Contents: string(data),
+	}
+	scanner := bufio.NewScanner(strings.NewReader(file.Contents))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			file.Settings[key] = strings.TrimSpace(value)
+		}
+	}
+	return file, nil
+}
@@ -0,0 +1,73 @@
+package katago
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+)
+
+func TestDescribeSettings(t *testing.T) {
+	dir := t.TempDir()
+	modelPath := filepath.Join(dir, "kata1-b18c384nbt-s123.bin.gz")
+	if err := os.WriteFile(modelPath, []byte("weights"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "analysis.cfg")
+	contents := "# Analysis config\nmaxVisits = 500  # per query\nnumSearchThreads=16\nlogDir = logs\n"
+	if err := os.WriteFile(configPath, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	noise := 0.04
+	cfg := &config.KataGoConfig{
+		ModelPath:  modelPath,
+		ConfigPath: configPath,
+		NumThreads: 4,
+		MaxVisits:  1000,
+		MaxTime:    10,
+		Search:     config.SearchConfig{WideRootNoise: &noise},
+	}
+	cacheCfg := &config.CacheConfig{Enabled: true, MaxItems: 100, Redis: config.RedisConfig{Addr: "redis:6379", Password: "secret"}}
+
+	settings, err := DescribeSettings("fast", cfg, Capabilities{Version: "1.15.3", GitHash: "abc123"}, cacheCfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings.Model == nil || settings.Model.Name != "kata1-b18c384nbt-s123" || settings.Model.SizeBytes != 7 {
+		t.Fatalf("Expected the model described, got %+v", settings.Model)
+	}
+	if want := "9a129038d9a00aed0cf6a7ea059ca50a813449061ab87848cf1a13eafdf33b2c"; settings.Model.SHA256 != want {
+		t.Errorf("Expected the SHA-256 of the model, got %q", settings.Model.SHA256)
+	}
+	// The config file's values take precedence, as KataGo applies them
+	if settings.MaxVisits != 500 || settings.NumSearchThreads != 16 || settings.MaxTime != 10 {
+		t.Errorf("Expected 500 visits, 16 threads and 10s, got %+v", settings)
+	}
+	if settings.ConfigFile == nil || settings.ConfigFile.Contents != contents || settings.ConfigFile.Settings["logDir"] != "logs" {
+		t.Errorf("Expected the config file and its settings, got %+v", settings.ConfigFile)
+	}
+	if settings.Cache.Redis != "redis:6379" || settings.Cache.Scope != "fast" {
+		t.Errorf("Expected the cache settings scoped to the engine, got %+v", settings.Cache)
+	}
+	if settings.DefaultRules != "chinese" || settings.KataGoVersion != "1.15.3" || *settings.Search.WideRootNoise != 0.04 {
+		t.Errorf("Unexpected settings: %+v", settings)
+	}
+
+	// A changed model is hashed again
+	hash := settings.Model.SHA256
+	if err := os.WriteFile(modelPath, []byte("new weights"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if settings, err = DescribeSettings("fast", cfg, Capabilities{}, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if settings.Model.SHA256 == hash || settings.Cache.Enabled {
+		t.Errorf("Expected a new hash and no cache, got %+v", settings)
+	}
+
+	cfg.ModelPath = filepath.Join(dir, "missing.bin.gz")
+	if _, err := DescribeSettings("fast", cfg, Capabilities{}, nil); err == nil {
+		t.Error("Expected an error for a missing model")
+	}
+}
@@ -5,8 +5,13 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
 )
 
+// DefaultRules are the rules of an SGF that does not name any.
+const DefaultRules = "chinese"
+
 // Position represents a board position for KataGo analysis.
 type Position struct {
 	// Board state
@@ -17,6 +22,95 @@ type Position struct {
 	Moves         []Move  `json:"moves"`
 	InitialPlayer string  `json:"initialPlayer,omitempty"`
 	Komi          float64 `json:"komi"`
+
+	// Game information
+	GameInfo
+
+	// Comments recorded on the main line, keyed by the number of moves
+	// played at the commented node (0 for the root)
+	Comments map[int]string `json:"-"`
+	// Variations recorded as branches off the main line
+	Variations []Variation `json:"-"`
+
+	// Time control from the root's TM, the main time in seconds, and OT,
+	// the overtime as the SGF describes it
+	MainTime float64 `json:"-"`
+	Overtime string  `json:"-"`
+	// Clock holds the seconds left for the player who made each main line
+	// move, recorded after it with BL or WL, keyed by move number
+	Clock map[int]float64 `json:"-"`
+}
+
+// SetupOnly reports whether a position has setup stones but no moves, as
+// a problem's setup with AB, AW and PL has.
+func (position *Position) SetupOnly() bool {
+	return len(position.Moves) == 0 && len(position.InitialStones) > 0
+}
+
+// Variation is a line of play recorded as a branch in an SGF, such as a
+// teacher's suggestion. Only its first path is kept.
+type Variation struct {
+	MoveNumber int    // Number of the main line move the variation replaces
+	Moves      []Move // Moves along the variation's first path
+	Comment    string // First comment along the path
+}
+
+// GameInfo is the game metadata recorded in an SGF's root node.
+type GameInfo struct {
+	PlayerBlack string `json:"playerBlack,omitempty"`
+	PlayerWhite string `json:"playerWhite,omitempty"`
+	BlackRank   string `json:"blackRank,omitempty"`
+	WhiteRank   string `json:"whiteRank,omitempty"`
+	Result      string `json:"result,omitempty"` // e.g. "W+R" or "B+3.5"
+	Date        string `json:"date,omitempty"`
+	Event       string `json:"event,omitempty"`
+}
+
+// Known reports whether any metadata was recorded.
+func (g GameInfo) Known() bool {
+	return g != GameInfo{}
+}
+
+// Player names the player of a color ("B" or "W") with their rank, e.g.
+// "Lee 3d", or returns "" when the SGF does not name them.
+func (g GameInfo) Player(color string) string {
+	name, rank := g.PlayerWhite, g.WhiteRank
+	if strings.EqualFold(color, "B") {
+		name, rank = g.PlayerBlack, g.BlackRank
+	}
+	if name == "" {
+		return ""
+	}
+	if rank != "" {
+		return name + " " + rank
+	}
+	return name
+}
+
+// String summarizes the game, e.g. "Black: Lee 3d vs White: Kim 2d, result
+// W+R (Club League, 2024-03-01)".
+func (g GameInfo) String() string {
+	black, white := g.Player("B"), g.Player("W")
+	if black == "" {
+		black = "?"
+	}
+	if white == "" {
+		white = "?"
+	}
+	s := fmt.Sprintf("Black: %s vs White: %s", black, white)
+	if g.Result != "" {
+		s += ", result " + g.Result
+	}
+	var when []string
+	for _, v := range []string{g.Event, g.Date} {
+		if v != "" {
+			when = append(when, v)
+		}
+	}
+	if len(when) > 0 {
+		s += " (" + strings.Join(when, ", ") + ")"
+	}
+	return s
 }
 
 // Stone represents a stone on the board.
@@ -31,11 +125,19 @@ type Move struct {
 	Location string `json:"location"`
 }
 
+// maxSGFBoardSize is the largest board SGF coordinates can address.
+const maxSGFBoardSize = 52
+
+// maxSGFNesting bounds how deeply variations may nest, so that
+// malformed input cannot exhaust the stack.
+const maxSGFNesting = 1000
+
 // SGFParser parses SGF files.
 type SGFParser struct {
 	content   string
 	index     int
 	boardSize int // Track board size for coordinate conversion
+	depth     int // Variations being read
 }
 
 // NewSGFParser creates a new SGF parser.
@@ -51,15 +153,15 @@ func NewSGFParser(content string) *SGFParser {
 func (p *SGFParser) Parse() (*Position, error) {
 	// Skip to first '('
 	if !p.skipTo('(') {
-		return nil, fmt.Errorf("invalid SGF: no opening parenthesis")
+		return nil, apperrors.New(apperrors.CodeInvalidSGF, "invalid SGF: no opening parenthesis")
 	}
 	p.index++ // Skip '('
 
 	// Parse game tree
 	position := &Position{
-		Rules:      "chinese", // Default
-		BoardXSize: 19,        // Default
-		BoardYSize: 19,        // Default
+		Rules:      DefaultRules,
+		BoardXSize: 19, // Default
+		BoardYSize: 19, // Default
 		Moves:      []Move{},
 	}
 
@@ -82,23 +184,54 @@ func (p *SGFParser) Parse() (*Position, error) {
 				return nil, err
 			}
 		case '(':
-			// Skip variations for now
-			p.skipVariation()
+			// The main line stops at the first branch; branches are kept as
+			// variations, and a malformed one ends the tree
+			if err := p.parseVariation(position); err != nil {
+				p.index = len(p.content)
+			}
 		default:
 			p.index++
 		}
 	}
 
-	// Set initial player if not specified
+	// Set initial player if not specified, from the first move or, in a
+	// problem without moves, the first move of its solutions
 	if position.InitialPlayer == "" && len(position.Moves) > 0 {
 		position.InitialPlayer = position.Moves[0].Color
 	}
+	if position.InitialPlayer == "" {
+		for _, v := range position.Variations {
+			if v.MoveNumber == 1 && len(v.Moves) > 0 {
+				position.InitialPlayer = v.Moves[0].Color
+				break
+			}
+		}
+	}
 
 	return position, nil
 }
 
 // parseNode parses a single SGF node.
 func (p *SGFParser) parseNode(position *Position) error {
+	comment := ""
+	moves := len(position.Moves)
+	timeLeft := map[string]float64{} // Seconds left by color, from BL and WL
+	defer func() {
+		if comment != "" {
+			if position.Comments == nil {
+				position.Comments = make(map[int]string)
+			}
+			position.Comments[len(position.Moves)] = comment
+		}
+		if len(position.Moves) > moves {
+			if left, ok := timeLeft[position.Moves[len(position.Moves)-1].Color]; ok {
+				if position.Clock == nil {
+					position.Clock = make(map[int]float64)
+				}
+				position.Clock[len(position.Moves)] = left
+			}
+		}
+	}()
 	for p.index < len(p.content) {
 		p.skipWhitespace()
 
@@ -155,7 +288,10 @@ func (p *SGFParser) parseNode(position *Position) error {
 
 		case "SZ": // Board size
 			if len(values) > 0 {
-				size, err := strconv.Atoi(values[0])
+				size, err := strconv.Atoi(strings.TrimSpace(values[0]))
+				if err == nil && (size < 1 || size > maxSGFBoardSize) {
+					return apperrors.New(apperrors.CodeInvalidSGF, "invalid board size: %d", size)
+				}
 				if err == nil {
 					position.BoardXSize = size
 					position.BoardYSize = size
@@ -188,12 +324,71 @@ func (p *SGFParser) parseNode(position *Position) error {
 				}
 			}
 
-		case "PL": // Player to play
+		case "PB": // Black player name
 			if len(values) > 0 {
-				switch values[0] {
-				case "B":
+				position.PlayerBlack = strings.TrimSpace(values[0])
+			}
+
+		case "PW": // White player name
+			if len(values) > 0 {
+				position.PlayerWhite = strings.TrimSpace(values[0])
+			}
+
+		case "BR": // Black rank
+			if len(values) > 0 {
+				position.BlackRank = strings.TrimSpace(values[0])
+			}
+
+		case "WR": // White rank
+			if len(values) > 0 {
+				position.WhiteRank = strings.TrimSpace(values[0])
+			}
+
+		case "RE": // Result
+			if len(values) > 0 {
+				position.Result = strings.TrimSpace(values[0])
+			}
+
+		case "DT": // Date
+			if len(values) > 0 {
+				position.Date = strings.TrimSpace(values[0])
+			}
+
+		case "EV": // Event
+			if len(values) > 0 {
+				position.Event = strings.TrimSpace(values[0])
+			}
+
+		case "C": // Comment
+			if len(values) > 0 {
+				comment = strings.TrimSpace(values[0])
+			}
+
+		case "TM": // Main time in seconds
+			if len(values) > 0 {
+				if seconds, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64); err == nil {
+					position.MainTime = seconds
+				}
+			}
+
+		case "OT": // Overtime
+			if len(values) > 0 {
+				position.Overtime = strings.TrimSpace(values[0])
+			}
+
+		case "BL", "WL": // Time left after the node's move
+			if len(values) > 0 {
+				if seconds, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64); err == nil {
+					timeLeft[strings.ToLower(prop[:1])] = seconds
+				}
+			}
+
+		case "PL": // Player to play, which only sets the first player
+			if len(values) > 0 && len(position.Moves) == 0 {
+				switch strings.ToUpper(strings.TrimSpace(values[0])) {
+				case "B", "BLACK":
 					position.InitialPlayer = "b"
-				case "W":
+				case "W", "WHITE":
 					position.InitialPlayer = "w"
 				}
 			}
@@ -212,7 +407,7 @@ func (p *SGFParser) parseProperty() (prop string, values []string, err error) {
 	}
 
 	if p.index == propStart {
-		return "", nil, fmt.Errorf("expected property name at position %d", p.index)
+		return "", nil, apperrors.New(apperrors.CodeInvalidSGF, "expected property name at position %d", p.index)
 	}
 
 	prop = p.content[propStart:p.index]
@@ -244,7 +439,7 @@ func (p *SGFParser) parseProperty() (prop string, values []string, err error) {
 		}
 
 		if p.index >= len(p.content) {
-			return "", nil, fmt.Errorf("unclosed property value")
+			return "", nil, apperrors.New(apperrors.CodeInvalidSGF, "unclosed property value")
 		}
 
 		value := p.content[valueStart:p.index]
@@ -259,20 +454,25 @@ func (p *SGFParser) parseProperty() (prop string, values []string, err error) {
 
 	// Properties must have at least one value
 	if len(values) == 0 {
-		return "", nil, fmt.Errorf("property %s must have at least one value", prop)
+		return "", nil, apperrors.New(apperrors.CodeInvalidSGF, "property %s must have at least one value", prop)
 	}
 
 	return prop, values, nil
 }
 
-// sgfToKataGo converts SGF coordinates to KataGo format.
+// sgfToKataGo converts SGF coordinates to KataGo format. Coordinates that
+// are not two letters on the board are returned as they are, for
+// ValidatePosition to reject.
 func (p *SGFParser) sgfToKataGo(coord string) string {
-	if len(coord) != 2 {
+	if len(coord) != 2 || coord[0] < 'a' || coord[0] > 'z' || coord[1] < 'a' || coord[1] > 'z' {
 		return coord
 	}
 
 	x := coord[0] - 'a'
 	y := coord[1] - 'a'
+	if int(x) >= p.boardSize || int(y) >= p.boardSize {
+		return coord
+	}
 
 	// KataGo uses A1 style (A-T, skipping I)
 	var col string
@@ -307,6 +507,68 @@ func (p *SGFParser) skipTo(ch byte) bool {
 	return false
 }
 
+// parseVariation reads a branch off the main line as a variation.
+func (p *SGFParser) parseVariation(position *Position) error {
+	variation := Variation{MoveNumber: len(position.Moves) + 1}
+	if err := p.parseBranch(&variation); err != nil {
+		return err
+	}
+	position.Variations = append(position.Variations, variation)
+	return nil
+}
+
+// parseBranch reads the subtree starting at '(' into a variation,
+// following the first child at each further branch and skipping the
+// others.
+func (p *SGFParser) parseBranch(variation *Variation) error {
+	if p.depth++; p.depth > maxSGFNesting {
+		return apperrors.New(apperrors.CodeInvalidSGF, "variations nested more than %d deep", maxSGFNesting)
+	}
+	defer func() { p.depth-- }()
+	p.index++ // Skip '('
+	followed := false
+	for p.index < len(p.content) {
+		p.skipWhitespace()
+		if p.index >= len(p.content) {
+			break
+		}
+		switch p.content[p.index] {
+		case ')':
+			p.index++
+			return nil
+		case '(':
+			if followed {
+				p.skipVariation()
+				continue
+			}
+			followed = true
+			if err := p.parseBranch(variation); err != nil {
+				return err
+			}
+		case ';':
+			p.index++
+		default:
+			prop, values, err := p.parseProperty()
+			if err != nil {
+				return err
+			}
+			switch prop {
+			case "B", "W":
+				move := Move{Color: strings.ToLower(prop)}
+				if len(values) > 0 && values[0] != "" && values[0] != "tt" {
+					move.Location = p.sgfToKataGo(values[0])
+				}
+				variation.Moves = append(variation.Moves, move)
+			case "C":
+				if variation.Comment == "" && len(values) > 0 {
+					variation.Comment = strings.TrimSpace(values[0])
+				}
+			}
+		}
+	}
+	return apperrors.New(apperrors.CodeInvalidSGF, "unclosed variation")
+}
+
 // skipVariation skips a variation subtree.
 func (p *SGFParser) skipVariation() {
 	depth := 0
@@ -324,11 +586,13 @@ func (p *SGFParser) skipVariation() {
 	}
 }
 
-// ValidatePosition validates a position for KataGo analysis.
+// ValidatePosition validates a position for KataGo analysis. Under rules
+// with a superko, a move that repeats an earlier position is rejected with
+// a *RepetitionError naming the move.
 func ValidatePosition(pos *Position) error {
 	// Validate board size
 	if pos.BoardXSize < 2 || pos.BoardXSize > 25 || pos.BoardYSize < 2 || pos.BoardYSize > 25 {
-		return fmt.Errorf("invalid board size: %dx%d", pos.BoardXSize, pos.BoardYSize)
+		return apperrors.New(apperrors.CodeInvalidArgument, "invalid board size: %dx%d", pos.BoardXSize, pos.BoardYSize)
 	}
 
 	// Validate rules
@@ -337,29 +601,32 @@ func ValidatePosition(pos *Position) error {
 		"aga": true, "new_zealand": true, "tromp-taylor": true,
 	}
 	if !validRules[pos.Rules] {
-		return fmt.Errorf("invalid rules: %s", pos.Rules)
+		return apperrors.New(apperrors.CodeInvalidArgument, "invalid rules: %s", pos.Rules)
 	}
 
 	// Validate moves
 	coordPattern := regexp.MustCompile(`^[A-T]\d{1,2}$`)
 	for i, move := range pos.Moves {
 		if move.Color != "b" && move.Color != "w" {
-			return fmt.Errorf("invalid color in move %d: %s", i, move.Color)
+			return apperrors.New(apperrors.CodeInvalidArgument, "invalid color in move %d: %s", i, move.Color)
 		}
 		if move.Location != "" && !coordPattern.MatchString(move.Location) {
-			return fmt.Errorf("invalid location in move %d: %s", i, move.Location)
+			return apperrors.New(apperrors.CodeBadCoordinate, "invalid location in move %d: %s", i, move.Location)
 		}
 	}
 
 	// Validate initial stones
 	for i, stone := range pos.InitialStones {
 		if stone.Color != "b" && stone.Color != "w" {
-			return fmt.Errorf("invalid color in initial stone %d: %s", i, stone.Color)
+			return apperrors.New(apperrors.CodeInvalidArgument, "invalid color in initial stone %d: %s", i, stone.Color)
 		}
 		if !coordPattern.MatchString(stone.Location) {
-			return fmt.Errorf("invalid location in initial stone %d: %s", i, stone.Location)
+			return apperrors.New(apperrors.CodeBadCoordinate, "invalid location in initial stone %d: %s", i, stone.Location)
 		}
 	}
 
+	if err := checkSuperko(pos); err != nil {
+		return apperrors.Wrap(apperrors.CodeInvalidArgument, err, "illegal repetition")
+	}
 	return nil
 }
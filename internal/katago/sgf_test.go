@@ -1,7 +1,10 @@
 package katago
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
 )
 
 func TestSGFParser(t *testing.T) {
@@ -102,6 +105,8 @@ func TestSGFCoordinateConversion(t *testing.T) {
 		{"dd", "D16"}, // Standard corner stone
 		{"pd", "Q16"}, // Standard corner stone
 		{"jj", "K10"}, // Center (skipping I)
+		{"`a", "`a"},  // Not a letter, left for validation to reject
+		{"ut", "ut"},  // Off the board
 	}
 
 	for _, tt := range tests {
@@ -212,6 +217,8 @@ func TestSGFErrorCases(t *testing.T) {
 		{"No opening parenthesis", "GM[1]FF[4]SZ[19];B[dd]"},
 		{"Unclosed property", "(;GM[1]FF[4]SZ[19]B[dd"},
 		{"Malformed property", "(;GM[1]FF[4]SZ[19];B)"},
+		{"Board too small", "(;GM[1]FF[4]SZ[0];B[aa])"},
+		{"Board too large", "(;GM[1]FF[4]SZ[53];B[aa])"},
 	}
 
 	for _, tc := range testCases {
@@ -225,6 +232,18 @@ func TestSGFErrorCases(t *testing.T) {
 	}
 }
 
+func TestSGFDeeplyNestedVariations(t *testing.T) {
+	// Branches nested past the limit end the tree like any malformed branch
+	sgf := "(;SZ[19];B[pd]" + strings.Repeat("(;W[dd]", maxSGFNesting+1)
+	position, err := NewSGFParser(sgf).Parse()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(position.Moves) != 1 || len(position.Variations) != 0 {
+		t.Errorf("Expected the main line alone, got %d moves and %d variations", len(position.Moves), len(position.Variations))
+	}
+}
+
 func TestSGFPlayerToMove(t *testing.T) {
 	// Test with explicit player to move
 	sgfWithPlayer := `(;GM[1]FF[4]SZ[19]KM[7.5]PL[W]
@@ -244,3 +263,133 @@ func TestSGFPlayerToMove(t *testing.T) {
 		}
 	}
 }
+
+// cornerProblem is a corner problem in the classic form: setup stones,
+// the player to move, and a correct and a wrong solution as variations.
+const cornerProblem = `(;GM[1]FF[4]SZ[19]C[White to play]
+	AB[pr][qr][rr][sr][pq][pp][qo][ro]AW[po][op][oq][or][os][nn][qn][rn][sn][so]PL[W]
+	(;W[sp]C[Correct];B[rp];W[rq])
+	(;W[rq];B[sp]C[Wrong]))`
+
+func TestSGFSetupOnly(t *testing.T) {
+	tests := []struct {
+		name      string
+		sgf       string
+		setupOnly bool
+		player    string
+	}{
+		{"classic problem", cornerProblem, true, "w"},
+		{"lower-case player", "(;SZ[9]AB[cc]AW[gg]PL[b])", true, "b"},
+		{"player from the solutions", "(;SZ[9]AB[cc][dc]AW[cd](;W[dd])(;W[ed]))", true, "w"},
+		{"player to move by default", "(;SZ[9]AB[cc]AW[gg])", true, ""},
+		{"player to play later in the game", "(;SZ[9]AB[cc];W[gg];B[cd]PL[B])", false, "w"},
+		{"empty board", "(;SZ[9])", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, err := NewSGFParser(tt.sgf).Parse()
+			if err != nil {
+				t.Fatalf("Failed to parse SGF: %v", err)
+			}
+			if position.SetupOnly() != tt.setupOnly || position.InitialPlayer != tt.player {
+				t.Errorf("Expected setup-only %v with initial player %q, got %v and %q",
+					tt.setupOnly, tt.player, position.SetupOnly(), position.InitialPlayer)
+			}
+		})
+	}
+
+	position, _ := NewSGFParser(cornerProblem).Parse()
+	if len(position.InitialStones) != 18 || len(position.Variations) != 2 || position.Variations[0].Moves[0].Location != "T4" {
+		t.Errorf("Expected the setup and both solutions, got %+v and %+v", position.InitialStones, position.Variations)
+	}
+	if nextPlayer(position) != "W" {
+		t.Errorf("Expected White to play, got %s", nextPlayer(position))
+	}
+	if err := checkReviewable(position); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected a problem not to be reviewable, got %v", err)
+	}
+}
+
+func TestSGFGameInfo(t *testing.T) {
+	sgf := `(;GM[1]FF[4]SZ[19]PB[Lee]BR[3d]PW[Kim]WR[2d]RE[W+R]DT[2024-03-01]EV[Club League];B[dd];W[pp])`
+	position, err := NewSGFParser(sgf).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse SGF: %v", err)
+	}
+	want := GameInfo{
+		PlayerBlack: "Lee", PlayerWhite: "Kim", BlackRank: "3d", WhiteRank: "2d",
+		Result: "W+R", Date: "2024-03-01", Event: "Club League",
+	}
+	if position.GameInfo != want {
+		t.Errorf("Expected %+v, got %+v", want, position.GameInfo)
+	}
+	if got := position.GameInfo.String(); got != "Black: Lee 3d vs White: Kim 2d, result W+R (Club League, 2024-03-01)" {
+		t.Errorf("Unexpected summary: %q", got)
+	}
+
+	info := GameInfo{PlayerWhite: "Kim", Result: "B+3.5"}
+	if info.Player("b") != "" || info.Player("W") != "Kim" {
+		t.Errorf("Expected only White named, got %q and %q", info.Player("B"), info.Player("W"))
+	}
+	if got := info.String(); got != "Black: ? vs White: Kim, result B+3.5" {
+		t.Errorf("Unexpected summary: %q", got)
+	}
+	if (GameInfo{}).Known() {
+		t.Error("Expected no metadata to be unknown")
+	}
+}
+
+func TestSGFCommentsAndVariations(t *testing.T) {
+	sgf := `(;GM[1]SZ[19]C[Club game];B[dd];W[pp]C[Too passive (should approach)]
+		(;B[pd]C[Teacher: take the corner];W[dp](;B[qq])(;B[cc]))
+		(;B[qq]))`
+	position, err := NewSGFParser(sgf).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse SGF: %v", err)
+	}
+	if len(position.Moves) != 2 {
+		t.Fatalf("Expected the main line to stop at the branch, got %d moves", len(position.Moves))
+	}
+	if position.Comments[0] != "Club game" || position.Comments[2] != "Too passive (should approach)" {
+		t.Errorf("Unexpected comments: %v", position.Comments)
+	}
+	if len(position.Variations) != 2 {
+		t.Fatalf("Expected 2 variations, got %+v", position.Variations)
+	}
+	first := position.Variations[0]
+	if first.MoveNumber != 3 || first.Comment != "Teacher: take the corner" {
+		t.Errorf("Unexpected variation: %+v", first)
+	}
+	// Only the first path of a variation is kept
+	want := []Move{{Color: "b", Location: "Q16"}, {Color: "w", Location: "D4"}, {Color: "b", Location: "R3"}}
+	if len(first.Moves) != len(want) {
+		t.Fatalf("Expected moves %v, got %v", want, first.Moves)
+	}
+	for i, move := range want {
+		if first.Moves[i] != move {
+			t.Errorf("Move %d: expected %v, got %v", i+1, move, first.Moves[i])
+		}
+	}
+	if second := position.Variations[1]; second.MoveNumber != 3 || len(second.Moves) != 1 {
+		t.Errorf("Unexpected variation: %+v", second)
+	}
+}
+
+func TestSGFTimeControl(t *testing.T) {
+	sgf := `(;GM[1]SZ[19]TM[600]OT[5x30 byo-yomi]
+		;B[pd]BL[595.2];WL[588]W[dp];B[pp]WL[12];W[dd]
+		(;B[qc]BL[1]))`
+	position, err := NewSGFParser(sgf).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse SGF: %v", err)
+	}
+	if position.MainTime != 600 || position.Overtime != "5x30 byo-yomi" {
+		t.Errorf("Unexpected time control: %v + %q", position.MainTime, position.Overtime)
+	}
+	// The clock of the player who moved is kept, whatever the order of the
+	// properties; White's time on Black's move and the variation are not
+	want := map[int]float64{1: 595.2, 2: 588}
+	if len(position.Clock) != len(want) || position.Clock[1] != want[1] || position.Clock[2] != want[2] {
+		t.Errorf("Expected clock %v, got %v", want, position.Clock)
+	}
+}
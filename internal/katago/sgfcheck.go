@@ -0,0 +1,300 @@
+package katago
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SGFDiagnostics describes how an SGF reads and what may stop it from
+// being analyzed as intended.
+type SGFDiagnostics struct {
+	Valid       bool       `json:"valid"` // No errors were found
+	BoardSize   int        `json:"boardSize,omitempty"`
+	Moves       int        `json:"moves"`      // Moves along the main line
+	Variations  int        `json:"variations"` // Branches off the main line
+	Rules       string     `json:"rules,omitempty"`
+	Komi        float64    `json:"komi"`
+	Issues      []SGFIssue `json:"issues,omitempty"`
+	Suggestions []string   `json:"suggestions,omitempty"`
+}
+
+// SGFIssue is a problem found in an SGF.
+type SGFIssue struct {
+	Severity   string `json:"severity"`             // "error" or "warning"
+	MoveNumber int    `json:"moveNumber,omitempty"` // Main line move, if the issue is at one
+	Property   string `json:"property,omitempty"`
+	Message    string `json:"message"`
+}
+
+// Errors returns the number of issues that are errors.
+func (d *SGFDiagnostics) Errors() int {
+	n := 0
+	for _, issue := range d.Issues {
+		if issue.Severity == "error" {
+			n++
+		}
+	}
+	return n
+}
+
+// ignoredSGFProperties are standard properties that do not change the
+// position, so reading past them is expected.
+var ignoredSGFProperties = map[string]bool{
+	// Game info
+	"AN": true, "AP": true, "BT": true, "CA": true, "CP": true, "FF": true, "GC": true,
+	"GM": true, "GN": true, "HA": true, "ON": true, "OT": true, "PC": true, "RO": true,
+	"SO": true, "ST": true, "TM": true, "US": true, "WT": true,
+	// Timing and move annotations
+	"BL": true, "WL": true, "OB": true, "OW": true, "BM": true, "DO": true, "IT": true,
+	"TE": true, "KO": true, "MN": true,
+	// Node annotations and markup
+	"N": true, "DM": true, "GB": true, "GW": true, "HO": true, "UC": true, "V": true,
+	"AR": true, "CR": true, "DD": true, "LB": true, "LN": true, "MA": true, "SL": true,
+	"SQ": true, "TR": true, "TB": true, "TW": true, "VW": true, "FG": true, "PM": true,
+	// Analysis written by LizzieSGF
+	"LZ": true, "SBKV": true,
+}
+
+// ValidateSGF parses an SGF and checks it the way analysis would read it:
+// property syntax, board size, coordinates, setup stones, and the legality
+// of every move on the main line and its variations. Moves that cannot be
+// played are reported and skipped, so one bad move does not hide the rest.
+// Suggestions say how to normalize the SGF so it reads as intended.
+func ValidateSGF(sgf string) *SGFDiagnostics {
+	d := &SGFDiagnostics{}
+	position, err := NewSGFParser(sgf).Parse()
+	if err != nil {
+		d.add("error", 0, "", err.Error())
+		if msg := err.Error(); strings.Contains(msg, "property name") || strings.Contains(msg, "at least one value") {
+			d.suggest("Write property names in upper case as in FF[4], e.g. AB rather than AddBlack, each followed by its values in brackets")
+		}
+		return d
+	}
+	d.BoardSize = position.BoardXSize
+	d.Moves = len(position.Moves)
+	d.Variations = len(position.Variations)
+	d.Rules = position.Rules
+	d.Komi = position.Komi
+
+	offBoard := d.checkProperties(sgf, position.BoardXSize)
+	if size := position.BoardXSize; size < 2 || size > 25 {
+		d.add("error", 0, "SZ", fmt.Sprintf("board size %d is not supported; sizes from 2 to 25 are", size))
+	} else {
+		d.checkMoves(position, offBoard)
+	}
+	d.Valid = d.Errors() == 0
+	return d
+}
+
+// checkProperties walks every node of the SGF, reporting properties that
+// are malformed or ignored. It returns the main line moves whose
+// coordinates are off the board.
+func (d *SGFDiagnostics) checkProperties(sgf string, size int) map[int]bool {
+	offBoard := make(map[int]bool)
+	reported := make(map[string]bool)
+	p := NewSGFParser(sgf)
+	p.skipTo('(')
+
+	depth, nodes, moveNumber := 0, 0, 0
+	mainLine := true
+	hasKomi, hasRules := false, false
+	for p.index < len(p.content) {
+		switch c := p.content[p.index]; {
+		case c == '(':
+			depth++
+			if depth == 1 && nodes > 0 {
+				d.add("warning", 0, "", "the SGF holds more than one game; only the first is read")
+				d.suggest("Save each game as its own SGF")
+				p.index = len(p.content)
+				continue
+			}
+			if depth > 1 {
+				mainLine = false
+			}
+			p.index++
+		case c == ')':
+			depth--
+			p.index++
+		case c == ';':
+			nodes++
+			p.index++
+		case c >= 'A' && c <= 'Z':
+			prop, values, err := p.parseProperty()
+			if err != nil {
+				return offBoard
+			}
+			switch prop {
+			case "B", "W":
+				if mainLine {
+					moveNumber++
+				}
+				if validSGFPoint(values[0], size, true) {
+					break
+				}
+				if mainLine {
+					offBoard[moveNumber] = true
+					d.add("error", moveNumber, prop, fmt.Sprintf("coordinate %q is off the %dx%d board", values[0], size, size))
+				} else {
+					d.add("warning", 0, prop, fmt.Sprintf("coordinate %q in a variation is off the %dx%d board", values[0], size, size))
+				}
+			case "AB", "AW", "AE":
+				if prop == "AE" {
+					d.add("warning", 0, prop, "removing stones (AE) is not supported and is ignored")
+				} else if nodes > 1 {
+					d.add("warning", 0, prop, "setup stones after the root node are placed before the first move")
+					d.suggest("Move AB and AW setup stones to the root node")
+				}
+				for _, v := range values {
+					switch {
+					case strings.Contains(v, ":"):
+						d.add("error", 0, prop, fmt.Sprintf("compressed point list %q is not supported", v))
+						d.suggest("Expand compressed point lists such as AB[aa:cc] into one value per stone")
+					case prop != "AE" && v != "" && !validSGFPoint(v, size, false):
+						d.add("error", 0, prop, fmt.Sprintf("coordinate %q is off the %dx%d board", v, size, size))
+					}
+				}
+			case "SZ":
+				if _, err := strconv.Atoi(values[0]); err != nil {
+					if strings.Contains(values[0], ":") {
+						d.add("error", 0, prop, fmt.Sprintf("rectangular board %q is not supported", values[0]))
+					} else {
+						d.add("error", 0, prop, fmt.Sprintf("board size %q is not a number; 19x19 is assumed", values[0]))
+					}
+				}
+			case "KM":
+				hasKomi = true
+				if _, err := strconv.ParseFloat(values[0], 64); err != nil {
+					d.add("warning", 0, prop, fmt.Sprintf("komi %q is not a number and is ignored", values[0]))
+					d.suggest("Write komi as a number, e.g. KM[6.5]")
+				}
+			case "RU":
+				hasRules = true
+				if !knownSGFRules(values[0]) {
+					d.add("warning", 0, prop, fmt.Sprintf("rules %q are not recognized; chinese rules are used", values[0]))
+				}
+			case "GM":
+				if values[0] != "1" {
+					d.add("error", 0, prop, fmt.Sprintf("GM[%s] is not a game of Go", values[0]))
+				}
+			case "C", "PL", "PB", "PW", "BR", "WR", "RE", "DT", "EV":
+			default:
+				if !ignoredSGFProperties[prop] && !reported[prop] {
+					reported[prop] = true
+					d.add("warning", 0, prop, fmt.Sprintf("unsupported property %s is ignored", prop))
+				}
+			}
+		default:
+			p.index++
+		}
+	}
+
+	if !hasKomi {
+		d.suggest("Add KM with the game's komi; without it komi is 0")
+	}
+	if !hasRules {
+		d.suggest("Add RU with the game's rules; without it chinese rules are used")
+	}
+	return offBoard
+}
+
+// checkMoves replays the main line and its variations, reporting moves
+// that cannot be played and players moving twice in a row.
+func (d *SGFDiagnostics) checkMoves(position *Position, offBoard map[int]bool) {
+	board, err := BoardFromPosition(&Position{
+		BoardXSize:    position.BoardXSize,
+		BoardYSize:    position.BoardYSize,
+		InitialStones: position.InitialStones,
+	})
+	if err != nil {
+		// Off-board setup stones are already reported
+		board = NewBoard(position.BoardXSize, position.BoardYSize)
+	}
+
+	// Boards before each move a variation branches from
+	before := make(map[int]*Board)
+	for _, v := range position.Variations {
+		before[v.MoveNumber] = nil
+	}
+	snapshot := func(moveNumber int) {
+		if _, ok := before[moveNumber]; ok {
+			before[moveNumber] = board.Clone()
+		}
+	}
+
+	last := ""
+	for i, move := range position.Moves {
+		n := i + 1
+		snapshot(n)
+		if move.Color == last {
+			d.add("warning", n, strings.ToUpper(move.Color), fmt.Sprintf("%s plays twice in a row", colorWord(move.Color)))
+			d.suggest("Insert passes (B[] or W[]) where a player did not move")
+		}
+		last = move.Color
+		if offBoard[n] {
+			continue
+		}
+		if err := board.Play(move.Color, move.Location); err != nil {
+			d.add("error", n, strings.ToUpper(move.Color), err.Error())
+		}
+	}
+	snapshot(len(position.Moves) + 1)
+
+	for _, v := range position.Variations {
+		board := before[v.MoveNumber]
+		if board == nil {
+			continue
+		}
+		board = board.Clone()
+		for i, move := range v.Moves {
+			if err := board.Play(move.Color, move.Location); err != nil {
+				d.add("warning", 0, strings.ToUpper(move.Color),
+					fmt.Sprintf("variation at move %d, move %d: %v", v.MoveNumber, i+1, err))
+				break
+			}
+		}
+	}
+}
+
+func (d *SGFDiagnostics) add(severity string, moveNumber int, property, message string) {
+	d.Issues = append(d.Issues, SGFIssue{Severity: severity, MoveNumber: moveNumber, Property: property, Message: message})
+}
+
+// suggest adds a suggestion unless it was already made.
+func (d *SGFDiagnostics) suggest(suggestion string) {
+	for _, s := range d.Suggestions {
+		if s == suggestion {
+			return
+		}
+	}
+	d.Suggestions = append(d.Suggestions, suggestion)
+}
+
+// validSGFPoint reports whether an SGF point value is on a size x size
+// board. For moves, an empty value or "tt" is a pass, as SGFParser reads it.
+func validSGFPoint(v string, size int, move bool) bool {
+	if move && (v == "" || v == "tt") {
+		return true
+	}
+	if len(v) != 2 {
+		return false
+	}
+	for _, c := range []byte(v) {
+		if c < 'a' || int(c-'a') >= size {
+			return false
+		}
+	}
+	return true
+}
+
+// knownSGFRules reports whether an RU value names rules SGFParser
+// recognizes, rather than falling back to chinese.
+func knownSGFRules(rules string) bool {
+	rules = strings.ToLower(rules)
+	for _, name := range []string{"chin", "japan", "korea", "aga", "new zealand"} {
+		if strings.Contains(rules, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package katago
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSGF(t *testing.T) {
+	d := ValidateSGF("(;GM[1]FF[4]SZ[9]KM[6.5]RU[Japanese]AB[cc]XY[1];B[cc];W[dd];W[ee];B[zz];B[dd](;W[ff])(;W[ee]))")
+	if d.Valid {
+		t.Error("Expected the SGF to be invalid")
+	}
+	if d.BoardSize != 9 || d.Moves != 5 || d.Variations != 2 || d.Rules != "japanese" || d.Komi != 6.5 {
+		t.Errorf("Unexpected summary: %+v", d)
+	}
+
+	want := []SGFIssue{
+		{Severity: "warning", Property: "XY", Message: "unsupported property XY is ignored"},
+		{Severity: "error", MoveNumber: 4, Property: "B", Message: `coordinate "zz" is off the 9x9 board`},
+		{Severity: "error", MoveNumber: 1, Property: "B", Message: "illegal move C7: point is occupied"},
+		{Severity: "warning", MoveNumber: 3, Property: "W", Message: "White plays twice in a row"},
+		{Severity: "warning", MoveNumber: 5, Property: "B", Message: "Black plays twice in a row"},
+		{Severity: "error", MoveNumber: 5, Property: "B", Message: "illegal move D6: point is occupied"},
+		{Severity: "warning", Property: "W", Message: "variation at move 6, move 1: illegal move E5: point is occupied"},
+	}
+	if len(d.Issues) != len(want) {
+		t.Fatalf("Expected %d issues, got %+v", len(want), d.Issues)
+	}
+	for i, issue := range d.Issues {
+		if issue != want[i] {
+			t.Errorf("Issue %d: expected %+v, got %+v", i, want[i], issue)
+		}
+	}
+	if len(d.Suggestions) != 1 || !strings.Contains(d.Suggestions[0], "passes") {
+		t.Errorf("Expected a suggestion to insert passes, got %v", d.Suggestions)
+	}
+}
+
+func TestValidateSGFNormalization(t *testing.T) {
+	tests := []struct {
+		name       string
+		sgf        string
+		valid      bool
+		issue      string
+		suggestion string
+	}{
+		{"clean", "(;GM[1]SZ[19]KM[7.5]RU[Chinese];B[pd];W[dp];B[tt])", true, "", ""},
+		{"missing komi and rules", "(;SZ[9];B[ee])", true, "", "Add KM"},
+		{"unknown rules", "(;KM[7]RU[Ing];B[pd])", true, "not recognized", ""},
+		{"rectangular board", "(;SZ[19:13]KM[7]RU[AGA])", false, "rectangular", ""},
+		{"compressed points", "(;KM[7]RU[AGA]AB[aa:cc])", false, "compressed", "Expand"},
+		{"setup after root", "(;KM[7]RU[AGA];B[pd];AW[dd])", true, "after the root", "root node"},
+		{"two games", "(;KM[7]RU[AGA];B[pd])(;B[dd])", true, "more than one game", "own SGF"},
+		{"lower case names", "(;GM[1]AddBlack[aa])", false, "at least one value", "upper case"},
+		{"not Go", "(;GM[2]KM[7]RU[AGA])", false, "not a game of Go", ""},
+		{"unparsable", "B[pd]", false, "no opening parenthesis", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := ValidateSGF(tt.sgf)
+			if d.Valid != tt.valid {
+				t.Errorf("Expected valid %v, got %+v", tt.valid, d)
+			}
+			if tt.issue == "" && len(d.Issues) > 0 {
+				t.Errorf("Expected no issues, got %+v", d.Issues)
+			}
+			if tt.issue != "" && !containsIssue(d, tt.issue) {
+				t.Errorf("Expected an issue containing %q, got %+v", tt.issue, d.Issues)
+			}
+			if tt.suggestion != "" && !strings.Contains(strings.Join(d.Suggestions, "\n"), tt.suggestion) {
+				t.Errorf("Expected a suggestion containing %q, got %v", tt.suggestion, d.Suggestions)
+			}
+		})
+	}
+}
+
+func containsIssue(d *SGFDiagnostics, text string) bool {
+	for _, issue := range d.Issues {
+		if strings.Contains(issue.Message, text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package katago
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+// maxReviewVariations bounds the SGF variations a review compares with
+// KataGo, so a heavily annotated file does not multiply its queries.
+const maxReviewVariations = 20
+
+// SGFNote is a comment or variation already recorded in a reviewed SGF,
+// with KataGo's opinion of it. Win rates and score leads are as KataGo
+// reports them.
+type SGFNote struct {
+	MoveNumber int      `json:"moveNumber"` // The move commented on, or replaced by the variation
+	Color      string   `json:"color"`
+	PlayedMove string   `json:"playedMove,omitempty"` // Unset for variations after the last move
+	Comment    string   `json:"comment,omitempty"`
+	Variation  []string `json:"variation,omitempty"`
+	BestMove   string   `json:"bestMove,omitempty"`
+	Opinion    string   `json:"opinion,omitempty"` // Unset when the position was not analyzed
+	// KataGo's evaluation at the end of the variation, when verified
+	VariationWinrate   *float64 `json:"variationWinrate,omitempty"`
+	VariationScoreLead *float64 `json:"variationScoreLead,omitempty"`
+}
+
+// reviewNotes compares the comments and variations of a reviewed game with
+// KataGo's opinion, reusing the analyses of the review. positions[i] is
+// the analysis of the position before move i+1.
+func (e *Engine) reviewNotes(ctx context.Context, p *i18n.Printer, game *Position, positions []reviewedPosition,
+	thresholds *MistakeThresholds) []SGFNote {
+	if len(game.Comments) == 0 && len(game.Variations) == 0 {
+		return nil
+	}
+	analyzePosition := func(moves []Move) *AnalysisResult {
+		if ctx.Err() != nil {
+			return nil
+		}
+		position := *game
+		position.Moves = moves
+		req := &AnalysisRequest{Position: &position}
+		if thresholds.MinimumVisits > 0 {
+			visits := thresholds.MinimumVisits
+			req.MaxVisits = &visits
+		}
+		result, err := e.Analyze(ctx, req)
+		if err != nil {
+			e.logger.Warn("Failed to analyze SGF annotation", "moves", len(moves), "error", err)
+			return nil
+		}
+		return result
+	}
+
+	var final *AnalysisResult
+	analysisAt := func(n int) *AnalysisResult {
+		switch {
+		case n < len(positions):
+			if positions[n].shallow {
+				return nil
+			}
+			return positions[n].result
+		case n == len(game.Moves):
+			// Variations after the last move branch from the final position
+			if final == nil {
+				final = analyzePosition(game.Moves)
+			}
+			return final
+		}
+		return nil
+	}
+	var verify func(Variation) *AnalysisResult
+	if thresholds.VerifyVariations {
+		verify = func(v Variation) *AnalysisResult {
+			moves := append(append([]Move{}, game.Moves[:v.MoveNumber-1]...), v.Moves...)
+			return analyzePosition(moves)
+		}
+	}
+	return sgfNotes(p, game, analysisAt, verify)
+}
+
+// sgfNotes lists a game's comments and variations in move order with
+// KataGo's opinion. analysisAt returns the analysis of the position after
+// n moves, nil if there is none. verify, if set, analyzes the position at
+// the end of a variation.
+func sgfNotes(p *i18n.Printer, game *Position, analysisAt func(int) *AnalysisResult,
+	verify func(Variation) *AnalysisResult) []SGFNote {
+	var notes []SGFNote
+	for n, comment := range game.Comments {
+		// Comments on the root describe the game, not a move
+		if n == 0 || n > len(game.Moves) {
+			continue
+		}
+		move := game.Moves[n-1]
+		note := SGFNote{
+			MoveNumber: n,
+			Color:      strings.ToUpper(move.Color),
+			PlayedMove: moveName(move),
+			Comment:    comment,
+		}
+		if result := analysisAt(n - 1); result != nil {
+			note.BestMove, note.Opinion = noteOpinion(p, result, note.Color, note.PlayedMove)
+		}
+		notes = append(notes, note)
+	}
+	for i, v := range game.Variations {
+		if i == maxReviewVariations {
+			break
+		}
+		if len(v.Moves) == 0 {
+			continue
+		}
+		note := SGFNote{
+			MoveNumber: v.MoveNumber,
+			Color:      strings.ToUpper(v.Moves[0].Color),
+			Comment:    v.Comment,
+		}
+		if v.MoveNumber <= len(game.Moves) {
+			note.PlayedMove = moveName(game.Moves[v.MoveNumber-1])
+		}
+		for _, move := range v.Moves {
+			note.Variation = append(note.Variation, moveName(move))
+		}
+		if result := analysisAt(v.MoveNumber - 1); result != nil {
+			note.BestMove, note.Opinion = noteOpinion(p, result, note.Color, note.Variation[0])
+		}
+		if verify != nil {
+			if result := verify(v); result != nil {
+				winrate, lead := result.RootInfo.Winrate, roundTenth(result.RootInfo.ScoreLead)
+				note.VariationWinrate, note.VariationScoreLead = &winrate, &lead
+			}
+		}
+		notes = append(notes, note)
+	}
+	// Comments are on distinct moves, and variations follow the comment on
+	// the same move
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].MoveNumber < notes[j].MoveNumber
+	})
+	return notes
+}
+
+// noteOpinion compares a move suggested or played in an SGF with KataGo's
+// first choice, in points and win rate for the player who moves.
+func noteOpinion(p *i18n.Printer, result *AnalysisResult, color, move string) (string, string) {
+	best, ok := bestMoveInfo(result)
+	if !ok {
+		return "", ""
+	}
+	if best.Move == move {
+		return best.Move, p.Sprintf("KataGo agrees: %s is its first choice", move)
+	}
+	for _, mi := range result.MoveInfos {
+		if mi.Move != move {
+			continue
+		}
+		points, winrate := best.ScoreLead-mi.ScoreLead, best.Winrate-mi.Winrate
+		if color == "W" {
+			points, winrate = -points, -winrate
+		}
+		return best.Move, p.Sprintf("KataGo prefers %s by %.1f points (%.1f%% win rate)", best.Move, points, winrate*100)
+	}
+	return best.Move, p.Sprintf("KataGo prefers %s and did not consider %s", best.Move, move)
+}
+
+// moveName returns a move's coordinate, or "pass".
+func moveName(move Move) string {
+	if move.Location == "" {
+		return "pass"
+	}
+	return move.Location
+}
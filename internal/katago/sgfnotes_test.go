@@ -0,0 +1,77 @@
+package katago
+
+import (
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+func TestSGFNotes(t *testing.T) {
+	game := &Position{
+		Moves: []Move{{Color: "b", Location: "D4"}, {Color: "w", Location: "Q16"}, {Color: "b", Location: "C3"}},
+		Comments: map[int]string{
+			0: "Club game",
+			3: "Too slow",
+			2: "Standard",
+		},
+		Variations: []Variation{
+			{MoveNumber: 3, Moves: []Move{{Color: "b", Location: "Q4"}, {Color: "w", Location: "D16"}}, Comment: "Take the corner"},
+			{MoveNumber: 4, Moves: []Move{{Color: "w", Location: "R3"}}},
+		},
+	}
+	// Win rates and scores are reported for Black
+	analyses := map[int]*AnalysisResult{
+		1: {MoveInfos: []MoveInfo{{Move: "Q16", Winrate: 0.48, ScoreLead: 0.5}}},
+		2: {MoveInfos: []MoveInfo{
+			{Move: "Q4", Winrate: 0.55, ScoreLead: 2.5, Order: 0},
+			{Move: "C3", Winrate: 0.5, ScoreLead: 0.5, Order: 1},
+		}},
+	}
+	analysisAt := func(n int) *AnalysisResult { return analyses[n] }
+	verify := func(v Variation) *AnalysisResult {
+		return &AnalysisResult{RootInfo: RootInfo{Winrate: 0.56, ScoreLead: 2.54}}
+	}
+
+	notes := sgfNotes(i18n.English, game, analysisAt, verify)
+	if len(notes) != 4 {
+		t.Fatalf("Expected 4 notes, got %+v", notes)
+	}
+	tests := []struct {
+		moveNumber int
+		comment    string
+		opinion    string
+	}{
+		{2, "Standard", "KataGo agrees: Q16 is its first choice"},
+		{3, "Too slow", "KataGo prefers Q4 by 2.0 points (5.0% win rate)"},
+		{3, "Take the corner", "KataGo agrees: Q4 is its first choice"},
+		// The final position was not analyzed
+		{4, "", ""},
+	}
+	for i, tt := range tests {
+		note := notes[i]
+		if note.MoveNumber != tt.moveNumber || note.Comment != tt.comment || note.Opinion != tt.opinion {
+			t.Errorf("Note %d: expected move %d %q %q, got %+v", i, tt.moveNumber, tt.comment, tt.opinion, note)
+		}
+	}
+	if v := notes[2]; v.PlayedMove != "C3" || len(v.Variation) != 2 || v.VariationScoreLead == nil || *v.VariationScoreLead != 2.5 {
+		t.Errorf("Unexpected variation note: %+v", v)
+	}
+	if v := notes[3]; v.PlayedMove != "" || v.Color != "W" {
+		t.Errorf("Expected a variation after the last move, got %+v", v)
+	}
+}
+
+func TestNoteOpinion(t *testing.T) {
+	result := &AnalysisResult{MoveInfos: []MoveInfo{
+		{Move: "C3", Winrate: 0.3, ScoreLead: -4, Order: 0},
+		{Move: "D5", Winrate: 0.42, ScoreLead: -1, Order: 1},
+	}}
+	// White's loss is measured from White's side
+	if best, opinion := noteOpinion(i18n.English, result, "W", "D5"); best != "C3" ||
+		opinion != "KataGo prefers C3 by 3.0 points (12.0% win rate)" {
+		t.Errorf("Unexpected opinion: %s %q", best, opinion)
+	}
+	if _, opinion := noteOpinion(i18n.English, result, "W", "pass"); opinion != "KataGo prefers C3 and did not consider pass" {
+		t.Errorf("Unexpected opinion: %q", opinion)
+	}
+}
@@ -0,0 +1,113 @@
+package katago
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// flightCall is an in-flight or completed query shared by concurrent callers.
+type flightCall struct {
+	done    chan struct{} // Closed once resp and err are set
+	resp    *Response
+	err     error
+	waiters int                // Callers still waiting, guarded by the group's lock
+	cancel  context.CancelFunc // Abandons the query once no caller waits
+}
+
+// flightGroup deduplicates identical concurrent engine queries so that
+// only one is sent to KataGo and all callers receive its result.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// newFlightGroup creates a new flight group.
+func newFlightGroup() *flightGroup {
+	return &flightGroup{
+		calls: make(map[string]*flightCall),
+	}
+}
+
+// Do executes fn for the given key unless a call with the same key is
+// already in flight, in which case it waits for and returns that result.
+// shared reports whether the result came from another caller's query.
+//
+// fn runs with ctx's values but not its cancellation, so that the first
+// caller giving up does not fail the others. Each caller stops waiting
+// when its own ctx ends, and fn's context is cancelled once no caller is
+// left waiting. A nil group runs fn directly without deduplication.
+func (g *flightGroup) Do(ctx context.Context, key string, fn func(context.Context) (*Response, error)) (resp *Response, shared bool, err error) {
+	if g == nil {
+		resp, err = fn(ctx)
+		return resp, false, err
+	}
+
+	g.mu.Lock()
+	c, shared := g.calls[key]
+	if shared {
+		c.waiters++
+	} else {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &flightCall{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.calls[key] = c
+		go func() {
+			c.resp, c.err = fn(callCtx)
+			g.mu.Lock()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+			close(c.done)
+			cancel()
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.resp, shared, c.err
+	case <-ctx.Done():
+		g.leave(key, c)
+		return nil, shared, apperrors.Wrap(apperrors.CodeOf(ctx.Err()), ctx.Err(), "query abandoned")
+	}
+}
+
+// leave stops a caller waiting for a call, abandoning the call once no
+// caller waits for it. A later caller then starts a new one.
+func (g *flightGroup) leave(key string, c *flightCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c.waiters--
+	if c.waiters > 0 {
+		return
+	}
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+	c.cancel()
+}
+
+// queryKey generates a deduplication key covering every field of a query.
+func queryKey(query map[string]interface{}) (string, error) {
+	keyData := make(map[string]interface{}, len(query))
+	for k, v := range query {
+		if k != "id" {
+			keyData[k] = v
+		}
+	}
+
+	// Maps marshal with sorted keys, so equal queries produce equal keys
+	data, err := json.Marshal(keyData)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal query key: %w", err)
+	}
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
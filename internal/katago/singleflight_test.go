@@ -0,0 +1,204 @@
+package katago
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+)
+
+func TestFlightGroupDeduplicatesConcurrentCalls(t *testing.T) {
+	g := newFlightGroup()
+
+	var calls int32
+	release := make(chan struct{})
+	fn := func(context.Context) (*Response, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return &Response{ID: "shared"}, nil
+	}
+
+	const callers = 5
+	var wg sync.WaitGroup
+	results := make(chan *Response, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, _, err := g.Do(context.Background(), "same", fn)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			results <- resp
+		}()
+	}
+
+	// Let all callers join the in-flight call before releasing it
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("Expected 1 underlying call, got %d", n)
+	}
+	for resp := range results {
+		if resp == nil || resp.ID != "shared" {
+			t.Errorf("Expected shared response, got %v", resp)
+		}
+	}
+}
+
+func TestFlightGroupSharesErrorsAndForgetsCompletedCalls(t *testing.T) {
+	g := newFlightGroup()
+
+	_, shared, err := g.Do(context.Background(), "key", func(context.Context) (*Response, error) {
+		return nil, fmt.Errorf("engine not running")
+	})
+	if err == nil || shared {
+		t.Errorf("Expected unshared error, got shared=%v err=%v", shared, err)
+	}
+
+	// A completed call must not be reused by later callers
+	resp, shared, err := g.Do(context.Background(), "key", func(context.Context) (*Response, error) {
+		return &Response{ID: "second"}, nil
+	})
+	if err != nil || shared || resp.ID != "second" {
+		t.Errorf("Expected fresh call, got resp=%v shared=%v err=%v", resp, shared, err)
+	}
+}
+
+func TestFlightGroupCallersWaitUnderTheirOwnContext(t *testing.T) {
+	g := newFlightGroup()
+	release := make(chan struct{})
+	callCtx := make(chan context.Context, 1)
+	fn := func(ctx context.Context) (*Response, error) {
+		callCtx <- ctx
+		select {
+		case <-release:
+			return &Response{ID: "shared"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// The first caller gives up; the second, still waiting, gets the answer
+	first, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, _, err := g.Do(first, "key", fn)
+		firstErr <- err
+	}()
+	ctx := <-callCtx
+	second := make(chan *Response, 1)
+	go func() {
+		resp, shared, err := g.Do(context.Background(), "key", fn)
+		if err != nil || !shared {
+			t.Errorf("Expected a shared response, got shared=%v err=%v", shared, err)
+		}
+		second <- resp
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancelFirst()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the first caller to be cancelled, got %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("Expected the call to go on while a caller waits")
+	}
+	close(release)
+	if resp := <-second; resp == nil || resp.ID != "shared" {
+		t.Errorf("Expected the shared response, got %v", resp)
+	}
+
+	// The call is abandoned once its last caller gives up
+	release = make(chan struct{})
+	timeout, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := g.Do(timeout, "key", fn); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the caller's deadline to end its wait, got %v", err)
+	}
+	select {
+	case <-(<-callCtx).Done():
+	case <-time.After(time.Second):
+		t.Error("Expected the abandoned call to be cancelled")
+	}
+}
+
+func TestSharedQueriesKeepTheirLane(t *testing.T) {
+	engine := &Engine{inflight: newFlightGroup()}
+	query := map[string]interface{}{"rules": "chinese", "maxVisits": 100}
+	background := WithLane(context.Background(), config.LaneBackground)
+
+	// An identical background query is stuck behind a busy background lane
+	key, err := sharedQueryKey(background, query)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	release := make(chan struct{})
+	defer close(release)
+	go func() {
+		_, _, _ = engine.inflight.Do(background, key, func(context.Context) (*Response, error) {
+			<-release
+			return &Response{ID: "background"}, nil
+		})
+	}()
+	waitFor(t, func() bool {
+		engine.inflight.mu.Lock()
+		defer engine.inflight.mu.Unlock()
+		return len(engine.inflight.calls) == 1
+	})
+
+	// An interactive caller sends its own query instead of joining it, here
+	// failing at once since the engine is not running
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := engine.sendQueryShared(ctx, query); apperrors.CodeOf(err) != apperrors.CodeEngineUnavailable {
+		t.Errorf("Expected the interactive query sent on its own, got %v", err)
+	}
+
+	if interactive, _ := sharedQueryKey(context.Background(), query); interactive == key {
+		t.Error("Expected queries in different lanes to have different keys")
+	}
+}
+
+func TestQueryKeyIgnoresID(t *testing.T) {
+	q1 := map[string]interface{}{"rules": "chinese", "maxVisits": 100, "id": "q1"}
+	q2 := map[string]interface{}{"rules": "chinese", "maxVisits": 100, "id": "q2"}
+	q3 := map[string]interface{}{"rules": "chinese", "maxVisits": 200}
+
+	k1, _ := queryKey(q1)
+	k2, _ := queryKey(q2)
+	k3, _ := queryKey(q3)
+
+	if k1 != k2 {
+		t.Error("Expected queries differing only by id to share a key")
+	}
+	if k1 == k3 {
+		t.Error("Expected queries with different visits to have different keys")
+	}
+}
+
+func TestFlightGroupNil(t *testing.T) {
+	var g *flightGroup
+
+	resp, shared, err := g.Do(context.Background(), "key", func(context.Context) (*Response, error) {
+		return &Response{ID: "direct"}, nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if shared {
+		t.Error("Expected unshared result from nil group")
+	}
+	if resp.ID != "direct" {
+		t.Errorf("Expected response ID direct, got %s", resp.ID)
+	}
+}
@@ -0,0 +1,67 @@
+package katago
+
+import "reflect"
+
+// Approximate heap sizes of the parts of a decoded response, on 64-bit
+// platforms.
+const (
+	stringHeaderBytes = 16
+	sliceHeaderBytes  = 24
+	interfaceBytes    = 16
+	mapHeaderBytes    = 48
+	// Key, value and bucket overhead of each map[string]interface{} entry
+	mapEntryBytes = stringHeaderBytes + interfaceBytes + 16
+	// A float64 stored in an interface is allocated separately
+	boxedFloatBytes = 8
+)
+
+var (
+	responseBytes = int64(reflect.TypeOf(Response{}).Size())
+	moveInfoBytes = int64(reflect.TypeOf(MoveInfo{}).Size())
+)
+
+// SizeBytes approximates the memory a response takes, for sizing cache
+// entries. It counts both the typed fields and the raw JSON map, where
+// every number is boxed in an interface: ownership after each candidate
+// move takes several times its JSON size.
+func (r *Response) SizeBytes() int64 {
+	size := responseBytes + int64(len(r.ID)+len(r.RootInfo.CurrentPlayer))
+	for i := range r.MoveInfos {
+		m := &r.MoveInfos[i]
+		size += moveInfoBytes + int64(len(m.Move))
+		for _, move := range m.PV {
+			size += stringHeaderBytes + int64(len(move))
+		}
+		size += 8 * int64(len(m.PVVisits)+len(m.PVEdgeVisits)+len(m.Ownership))
+	}
+	if r.Raw != nil {
+		size += rawSize(r.Raw)
+	}
+	return size
+}
+
+// rawSize approximates the memory of a value decoded from JSON into an
+// interface{}, not counting the interface holding it.
+func rawSize(value interface{}) int64 {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		size := int64(mapHeaderBytes)
+		for key, elem := range v {
+			size += mapEntryBytes + int64(len(key)) + rawSize(elem)
+		}
+		return size
+	case []interface{}:
+		size := int64(sliceHeaderBytes + interfaceBytes*len(v))
+		for _, elem := range v {
+			size += rawSize(elem)
+		}
+		return size
+	case string:
+		return stringHeaderBytes + int64(len(v))
+	case float64:
+		return boxedFloatBytes
+	default:
+		// Booleans and null are not allocated
+		return 0
+	}
+}
@@ -0,0 +1,49 @@
+package katago
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/cache"
+)
+
+func TestResponseSizeBytes(t *testing.T) {
+	ownership := make([]float64, 361)
+	for i := range ownership {
+		ownership[i] = 0.123456
+	}
+	raw := map[string]interface{}{
+		"id":        "q1",
+		"rootInfo":  map[string]interface{}{"visits": 100, "winrate": 0.5, "currentPlayer": "B"},
+		"ownership": ownership,
+	}
+	var moveInfos []interface{}
+	for _, move := range []string{"D4", "Q16", "C3"} {
+		moveInfos = append(moveInfos, map[string]interface{}{
+			"move": move, "visits": 10, "pv": []string{move}, "ownership": ownership,
+		})
+	}
+	raw["moveInfos"] = moveInfos
+	data, err := json.Marshal(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ResponseCodec.Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := decoded.(*Response)
+
+	// Four ownership maps, each held both typed and boxed in the raw map
+	// except the root one, which is only raw
+	want := int64(4*361*(interfaceBytes+boxedFloatBytes) + 3*361*8)
+	if size := resp.SizeBytes(); size < want || size > 2*want {
+		t.Errorf("Expected about %d bytes, got %d", want, size)
+	}
+	if size := cache.EstimateSize(resp); size != resp.SizeBytes() || size <= int64(len(data)) {
+		t.Errorf("Expected the cache to size the response above its %d JSON bytes, got %d", len(data), size)
+	}
+	if size := (&Response{ID: "q"}).SizeBytes(); size != responseBytes+1 {
+		t.Errorf("Expected an empty response to take %d bytes, got %d", responseBytes+1, size)
+	}
+}
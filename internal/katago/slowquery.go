@@ -0,0 +1,206 @@
+package katago
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultSlowQueryLogSize is the number of slow queries a log keeps.
+const defaultSlowQueryLogSize = 100
+
+// Outcomes of a slow query.
+const (
+	SlowQueryOK        = "ok"
+	SlowQueryError     = "error"     // KataGo answered with an error
+	SlowQueryTimeout   = "timeout"   // KataGo did not answer in time
+	SlowQueryAbandoned = "abandoned" // The request gave up waiting
+)
+
+// SlowQuery is a query to KataGo that took longer than the slow query
+// threshold, with what it takes to reproduce it.
+type SlowQuery struct {
+	Time    time.Time `json:"time"` // When the query was made
+	ID      string    `json:"id"`
+	Lane    string    `json:"lane"`
+	Outcome string    `json:"outcome"` // SlowQueryOK, SlowQueryError, SlowQueryTimeout or SlowQueryAbandoned
+	// Position is the query's position as a compact SGF, empty for queries
+	// without one
+	Position string `json:"position,omitempty"`
+	// Settings are the query's other fields, e.g. maxVisits
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	Visits   int                    `json:"visits,omitempty"` // Of the search, when KataGo answered
+	// The time, in seconds, spent waiting for a lane slot and the engine,
+	// then for KataGo's answer, and in all
+	QueueSeconds  float64 `json:"queueSeconds"`
+	EngineSeconds float64 `json:"engineSeconds"`
+	TotalSeconds  float64 `json:"totalSeconds"`
+}
+
+// SlowQueryLog keeps the most recent queries that took longer than a
+// threshold in a ring buffer.
+type SlowQueryLog struct {
+	threshold time.Duration
+
+	mu      sync.Mutex
+	queries []SlowQuery
+	next    int // Index of the oldest query once the buffer is full
+	full    bool
+}
+
+// NewSlowQueryLog creates a log of the queries taking longer than
+// threshold, keeping size of them (default: 100).
+func NewSlowQueryLog(threshold time.Duration, size int) *SlowQueryLog {
+	if size <= 0 {
+		size = defaultSlowQueryLogSize
+	}
+	return &SlowQueryLog{threshold: threshold, queries: make([]SlowQuery, 0, size)}
+}
+
+// Threshold returns the time from which a query is slow.
+func (l *SlowQueryLog) Threshold() time.Duration {
+	return l.threshold
+}
+
+// add keeps a slow query, dropping the oldest when the log is full.
+func (l *SlowQueryLog) add(query SlowQuery) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.full {
+		l.queries[l.next] = query
+		l.next = (l.next + 1) % len(l.queries)
+		return
+	}
+	l.queries = append(l.queries, query)
+	l.full = len(l.queries) == cap(l.queries)
+}
+
+// Queries returns the logged queries, oldest first.
+func (l *SlowQueryLog) Queries() []SlowQuery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	queries := make([]SlowQuery, 0, len(l.queries))
+	queries = append(queries, l.queries[l.next:]...)
+	return append(queries, l.queries[:l.next]...)
+}
+
+// SlowQueries returns the queries that took longer than the slow query
+// threshold, oldest first, or nil when the log is disabled.
+func (e *Engine) SlowQueries() []SlowQuery {
+	if e.slowQueries == nil {
+		return nil
+	}
+	return e.slowQueries.Queries()
+}
+
+// logSlowQuery logs a query sent as data if it took longer than the slow
+// query threshold, from start until now, sent to KataGo at sent.
+func (e *Engine) logSlowQuery(id, lane string, data []byte, outcome string, resp *Response, start, sent time.Time) {
+	if e.slowQueries == nil {
+		return
+	}
+	total := time.Since(start)
+	if total < e.slowQueries.threshold {
+		return
+	}
+	query := SlowQuery{
+		Time:          start,
+		ID:            id,
+		Lane:          lane,
+		Outcome:       outcome,
+		QueueSeconds:  roundThousandth(sent.Sub(start).Seconds()),
+		EngineSeconds: roundThousandth(time.Since(sent).Seconds()),
+		TotalSeconds:  roundThousandth(total.Seconds()),
+	}
+	if resp != nil {
+		query.Visits = resp.RootInfo.Visits
+	}
+	query.Position, query.Settings = splitQuery(data)
+	e.slowQueries.add(query)
+	e.logger.Warn("Slow query", "id", id, "lane", lane, "outcome", outcome, "totalSeconds", query.TotalSeconds,
+		"queueSeconds", query.QueueSeconds, "engineSeconds", query.EngineSeconds, "position", query.Position)
+}
+
+// queryPositionFields are the fields of a query that make up its position.
+var queryPositionFields = []string{"rules", "boardXSize", "boardYSize", "komi", "initialStones", "moves", "initialPlayer"}
+
+// splitQuery splits a query into its position, as a compact SGF, and its
+// other fields, leaving out its ID.
+func splitQuery(data []byte) (string, map[string]interface{}) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", nil
+	}
+	var position struct {
+		Rules         string     `json:"rules"`
+		BoardXSize    int        `json:"boardXSize"`
+		BoardYSize    int        `json:"boardYSize"`
+		Komi          *float64   `json:"komi"`
+		InitialStones [][]string `json:"initialStones"`
+		Moves         [][]string `json:"moves"`
+		InitialPlayer string     `json:"initialPlayer"`
+	}
+	_ = json.Unmarshal(data, &position)
+	delete(fields, "id")
+	for _, field := range queryPositionFields {
+		delete(fields, field)
+	}
+	if position.BoardXSize <= 0 || position.BoardYSize <= 0 {
+		return "", fields
+	}
+
+	point := func(location string) string {
+		x, y, err := regionCorner(strings.ToUpper(location), position.BoardXSize, position.BoardYSize)
+		if err != nil {
+			return ""
+		}
+		return sgfPoint(x, y)
+	}
+	var sb strings.Builder
+	sb.WriteString("(;GM[1]FF[4]")
+	if position.BoardXSize == position.BoardYSize {
+		sb.WriteString(fmt.Sprintf("SZ[%d]", position.BoardXSize))
+	} else {
+		sb.WriteString(fmt.Sprintf("SZ[%d:%d]", position.BoardXSize, position.BoardYSize))
+	}
+	if position.Komi != nil {
+		sb.WriteString(fmt.Sprintf("KM[%g]", *position.Komi))
+	}
+	if position.Rules != "" {
+		sb.WriteString(fmt.Sprintf("RU[%s]", sgfRules(position.Rules)))
+	}
+	for _, color := range []string{"B", "W"} {
+		var points []string
+		for _, stone := range position.InitialStones {
+			if len(stone) == 2 && strings.EqualFold(stone[0], color) {
+				points = append(points, point(stone[1]))
+			}
+		}
+		if len(points) > 0 {
+			sb.WriteString(fmt.Sprintf("A%s[%s]", color, strings.Join(points, "][")))
+		}
+	}
+	if position.InitialPlayer != "" {
+		sb.WriteString(fmt.Sprintf("PL[%s]", strings.ToUpper(position.InitialPlayer)))
+	}
+	for _, move := range position.Moves {
+		if len(move) != 2 {
+			continue
+		}
+		location := ""
+		if !strings.EqualFold(move[1], "pass") {
+			location = point(move[1])
+		}
+		sb.WriteString(fmt.Sprintf(";%s[%s]", strings.ToUpper(move[0]), location))
+	}
+	sb.WriteString(")")
+	return sb.String(), fields
+}
+
+// roundThousandth rounds to three decimal places.
+func roundThousandth(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
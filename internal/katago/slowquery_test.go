@@ -0,0 +1,96 @@
+package katago
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+func TestSlowQueryLog(t *testing.T) {
+	log := NewSlowQueryLog(time.Second, 2)
+	if queries := log.Queries(); len(queries) != 0 {
+		t.Fatalf("Expected an empty log, got %+v", queries)
+	}
+	for _, id := range []string{"q1", "q2", "q3"} {
+		log.add(SlowQuery{ID: id})
+	}
+
+	// The log keeps the newest queries, oldest first
+	queries := log.Queries()
+	if len(queries) != 2 || queries[0].ID != "q2" || queries[1].ID != "q3" {
+		t.Errorf("Expected q2 and q3, got %+v", queries)
+	}
+	if NewSlowQueryLog(time.Second, 0).queries == nil || cap(NewSlowQueryLog(time.Second, 0).queries) != defaultSlowQueryLogSize {
+		t.Error("Expected the default size")
+	}
+}
+
+func TestSplitQuery(t *testing.T) {
+	data := []byte(`{"id":"q1","rules":"japanese","boardXSize":19,"boardYSize":19,"komi":6.5,` +
+		`"initialStones":[["B","D4"],["W","Q4"]],"initialPlayer":"W","moves":[["W","Q16"],["B","pass"]],` +
+		`"maxVisits":100,"includeOwnership":true}`)
+	position, settings := splitQuery(data)
+	if want := "(;GM[1]FF[4]SZ[19]KM[6.5]RU[Japanese]AB[dp]AW[pp]PL[W];W[pd];B[])"; position != want {
+		t.Errorf("Expected %s, got %s", want, position)
+	}
+	if want := map[string]interface{}{"maxVisits": 100.0, "includeOwnership": true}; !reflect.DeepEqual(settings, want) {
+		t.Errorf("Expected %v, got %v", want, settings)
+	}
+
+	// The SGF reads back as the same position
+	game, err := NewSGFParser(position).Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", position, err)
+	}
+	if game.Rules != "japanese" || game.Komi != 6.5 || len(game.InitialStones) != 2 || !strings.EqualFold(game.InitialPlayer, "W") ||
+		len(game.Moves) != 2 || game.Moves[0].Location != "Q16" {
+		t.Errorf("Unexpected position: rules %s, komi %g, stones %v, player %s, moves %v",
+			game.Rules, game.Komi, game.InitialStones, game.InitialPlayer, game.Moves)
+	}
+
+	if position, settings := splitQuery([]byte(`{"id":"q2","action":"query_version"}`)); position != "" ||
+		!reflect.DeepEqual(settings, map[string]interface{}{"action": "query_version"}) {
+		t.Errorf("Expected no position, got %q and %v", position, settings)
+	}
+	if position, settings := splitQuery([]byte("not json")); position != "" || settings != nil {
+		t.Errorf("Expected nothing from a query that is not JSON, got %q and %v", position, settings)
+	}
+}
+
+func TestLogSlowQuery(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	if NewEngine(&config.KataGoConfig{}, logger, nil).SlowQueries() != nil {
+		t.Fatal("Expected the log disabled without a threshold")
+	}
+	engine := NewEngine(&config.KataGoConfig{SlowQueries: config.SlowQueryConfig{ThresholdSeconds: 1}}, logger, nil)
+	if queries := engine.SlowQueries(); queries == nil || len(queries) != 0 {
+		t.Fatalf("Expected an empty log, got %+v", queries)
+	}
+
+	data := []byte(`{"id":"q1","boardXSize":9,"boardYSize":9,"moves":[["B","E5"]],"maxVisits":10}`)
+	now := time.Now()
+	engine.logSlowQuery("q1", "interactive", data, SlowQueryOK, nil, now.Add(-500*time.Millisecond), now)
+	if queries := engine.SlowQueries(); len(queries) != 0 {
+		t.Fatalf("Did not expect a fast query logged, got %+v", queries)
+	}
+
+	resp := &Response{}
+	resp.RootInfo.Visits = 10
+	engine.logSlowQuery("q1", "interactive", data, SlowQueryOK, resp, now.Add(-3*time.Second), now.Add(-2*time.Second))
+	queries := engine.SlowQueries()
+	if len(queries) != 1 {
+		t.Fatalf("Expected the slow query logged, got %+v", queries)
+	}
+	q := queries[0]
+	if q.ID != "q1" || q.Lane != "interactive" || q.Outcome != SlowQueryOK || q.Visits != 10 ||
+		q.Position != "(;GM[1]FF[4]SZ[9];B[ee])" || q.Settings["maxVisits"] != 10.0 {
+		t.Errorf("Unexpected slow query: %+v", q)
+	}
+	if q.QueueSeconds < 0.999 || q.QueueSeconds > 1.001 || q.EngineSeconds < 2 || q.TotalSeconds < 3 {
+		t.Errorf("Unexpected timing: %+v", q)
+	}
+}
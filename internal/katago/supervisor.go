@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
 	"github.com/dmmcquay/katago-mcp/internal/cache"
 	"github.com/dmmcquay/katago-mcp/internal/config"
 	"github.com/dmmcquay/katago-mcp/internal/logging"
@@ -24,6 +26,20 @@ type Supervisor struct {
 	stopCh              chan struct{}
 	restartCh           chan struct{}
 	healthCheckInterval time.Duration
+	restarting          atomic.Bool
+	// suspended is set while the engine is stopped for being idle
+	suspended atomic.Bool
+	// failures counts the consecutive failed health checks
+	failures int
+}
+
+// EngineStatus is an engine's state as reported in health responses.
+type EngineStatus struct {
+	Running    bool            `json:"running"`
+	Restarting bool            `json:"restarting"`
+	Suspended  bool            `json:"suspended"` // Stopped while idle, until the next request
+	Startup    StartupProgress `json:"startup"`
+	EngineLoad
 }
 
 // NewSupervisor creates a new KataGo supervisor.
@@ -78,6 +94,28 @@ func (s *Supervisor) Stop() error {
 	return s.engine.Stop()
 }
 
+// Drain stops the supervisor, then drains the engine, giving outstanding
+// queries until the configured drain timeout or ctx ends to finish.
+func (s *Supervisor) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = false
+	s.mu.Unlock()
+
+	close(s.stopCh)
+	return s.drainEngine(ctx)
+}
+
+// drainEngine drains the engine within the configured drain timeout.
+func (s *Supervisor) drainEngine(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.config.DrainTimeoutSeconds)*time.Second)
+	defer cancel()
+	return s.engine.Drain(ctx)
+}
+
 // GetEngine returns the underlying KataGo engine.
 func (s *Supervisor) GetEngine() EngineInterface {
 	return s.engine
@@ -93,6 +131,56 @@ func (s *Supervisor) Restart() {
 	}
 }
 
+// Status returns the engine's running state and load.
+func (s *Supervisor) Status() EngineStatus {
+	running := s.engine.IsRunning()
+	return EngineStatus{
+		Running:    running,
+		Restarting: s.restarting.Load(),
+		Suspended:  s.suspended.Load() && !running,
+		Startup:    s.engine.StartupProgress(),
+		EngineLoad: s.engine.Load(),
+	}
+}
+
+// Ready reports whether the engine can take more work. It fails while the
+// engine is restarting, still loading its model or not responding, and when more queries are waiting
+// than the readiness limits allow, so load balancers route requests to
+// other replicas instead of letting them time out.
+func (s *Supervisor) Ready(ctx context.Context, limits *config.ReadinessConfig) error {
+	// A suspended engine is started by the next request, so it must keep
+	// receiving them
+	if s.suspended.Load() && !s.engine.IsRunning() {
+		return nil
+	}
+	if s.restarting.Load() {
+		return apperrors.New(apperrors.CodeEngineUnavailable, "engine is restarting")
+	}
+	if err := s.engine.Ping(ctx); err != nil {
+		return err
+	}
+	if progress := s.engine.StartupProgress(); progress.Phase != "" && !progress.Ready() {
+		return apperrors.New(apperrors.CodeEngineUnavailable,
+			"engine is starting: %s, %d%% after %.0fs", progress.Phase, progress.Percent, progress.ElapsedSeconds)
+	}
+	load := s.engine.Load()
+	if load.Draining {
+		return apperrors.New(apperrors.CodeEngineUnavailable, "engine is draining")
+	}
+	if limits == nil {
+		return nil
+	}
+	if limits.MaxPendingQueries > 0 && load.Pending >= limits.MaxPendingQueries {
+		return apperrors.New(apperrors.CodeEngineUnavailable,
+			"engine overloaded: %d pending queries (limit %d)", load.Pending, limits.MaxPendingQueries)
+	}
+	if limits.MaxQueuedQueries > 0 && load.Queued >= limits.MaxQueuedQueries {
+		return apperrors.New(apperrors.CodeEngineUnavailable,
+			"engine overloaded: %d queued queries (limit %d)", load.Queued, limits.MaxQueuedQueries)
+	}
+	return nil
+}
+
 // supervise monitors the KataGo engine and restarts it if needed.
 func (s *Supervisor) supervise(ctx context.Context) {
 	s.logger.Info("Starting KataGo supervisor")
@@ -116,36 +204,99 @@ func (s *Supervisor) supervise(ctx context.Context) {
 
 		case <-s.restartCh:
 			s.logger.Info("Processing restart request")
-			if err := s.engine.Stop(); err != nil {
+			s.suspended.Store(false)
+			if err := s.drainEngine(ctx); err != nil {
 				s.logger.Error("Failed to stop engine for restart", "error", err)
 			}
 			s.startEngineWithRetry(ctx)
 
 		case <-healthTicker.C:
+			if s.suspended.Load() {
+				if !s.engine.IsRunning() {
+					// Left stopped until a request starts it
+					continue
+				}
+				s.suspended.Store(false)
+				s.logger.Info("Suspended KataGo engine started on demand")
+			}
+			if s.idle() {
+				s.suspend(ctx)
+				continue
+			}
+
 			// Check if engine is healthy
 			if !s.engine.IsRunning() {
 				s.logger.Warn("KataGo engine not running, restarting")
 				s.startEngineWithRetry(ctx)
 			} else {
-				// Ping to check responsiveness
-				pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-				err := s.engine.Ping(pingCtx)
-				cancel()
-
-				if err != nil {
-					s.logger.Error("KataGo engine health check failed", "error", err)
-					if err := s.engine.Stop(); err != nil {
-						s.logger.Error("Failed to stop unhealthy engine", "error", err)
-					}
-					s.startEngineWithRetry(ctx)
-				}
+				s.checkHealth(ctx)
 			}
 		}
 	}
 }
 
+// checkHealth pings the running engine, which sends KataGo a tiny analysis
+// query, and restarts it after the configured number of consecutive
+// failures. A single slow answer, e.g. while KataGo is busy, does not
+// restart it.
+func (s *Supervisor) checkHealth(ctx context.Context) {
+	timeout := s.config.HealthCheck.Timeout()
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	err := s.engine.Ping(pingCtx)
+	cancel()
+	if err == nil {
+		if s.failures > 0 {
+			s.logger.Info("KataGo engine health check recovered", "failures", s.failures)
+		}
+		s.failures = 0
+		return
+	}
+
+	s.failures++
+	maxFailures := s.config.HealthCheck.Failures()
+	if s.failures < maxFailures {
+		s.logger.Warn("KataGo engine health check failed", "error", err, "failures", s.failures, "maxFailures", maxFailures)
+		return
+	}
+	s.logger.Error("KataGo engine health check failed, restarting", "error", err, "failures", s.failures)
+	s.failures = 0
+	// Collected before stopping, while the queries are still outstanding
+	s.engine.CollectDiagnostics(fmt.Sprintf("health check failed: %v", err))
+	if err := s.engine.Stop(); err != nil {
+		s.logger.Error("Failed to stop unhealthy engine", "error", err)
+	}
+	s.startEngineWithRetry(ctx)
+}
+
+// idle reports whether the running engine has had no queries for the
+// configured idle suspension time.
+func (s *Supervisor) idle() bool {
+	if s.config.IdleSuspendMinutes <= 0 || !s.engine.IsRunning() {
+		return false
+	}
+	load := s.engine.Load()
+	idle := time.Duration(load.IdleSeconds * float64(time.Second))
+	return load.Pending == 0 && load.Queued == 0 && idle >= time.Duration(s.config.IdleSuspendMinutes)*time.Minute
+}
+
+// suspend stops an idle engine to free its GPU memory. Tools start it again
+// on their next request, like any stopped engine.
+func (s *Supervisor) suspend(ctx context.Context) {
+	s.logger.Info("Suspending idle KataGo engine", "idleMinutes", s.config.IdleSuspendMinutes)
+	s.suspended.Store(true)
+	if err := s.drainEngine(ctx); err != nil {
+		s.logger.Error("Failed to stop idle engine", "error", err)
+	}
+}
+
 // startEngineWithRetry starts the engine with exponential backoff retry.
 func (s *Supervisor) startEngineWithRetry(ctx context.Context) {
+	s.restarting.Store(true)
+	defer s.restarting.Store(false)
+
+	// Diagnostics are collected for the first failed start only, not for
+	// every retry
+	diagnosed := false
 	err := s.retryManager.Run(ctx, func(retryCtx context.Context) error {
 		// Check if we should stop
 		select {
@@ -156,12 +307,13 @@ func (s *Supervisor) startEngineWithRetry(ctx context.Context) {
 		default:
 		}
 
-		s.logger.Info("Starting KataGo engine")
-
-		// Start the engine
-		if err := s.engine.Start(retryCtx); err != nil {
-			s.logger.Error("Failed to start KataGo engine", "error", err)
-			return err
+		// A request may already have started the engine on demand
+		if !s.engine.IsRunning() {
+			s.logger.Info("Starting KataGo engine")
+			if err := s.engine.Start(retryCtx); err != nil {
+				s.logger.Error("Failed to start KataGo engine", "error", err)
+				return err
+			}
 		}
 
 		// Verify it's responsive
@@ -170,6 +322,10 @@ func (s *Supervisor) startEngineWithRetry(ctx context.Context) {
 
 		if err := s.engine.Ping(pingCtx); err != nil {
 			s.logger.Error("KataGo engine not responsive after start", "error", err)
+			if !diagnosed {
+				s.engine.CollectDiagnostics(fmt.Sprintf("not responsive after start: %v", err))
+				diagnosed = true
+			}
 			// Stop the engine before retrying
 			_ = s.engine.Stop()
 			return err
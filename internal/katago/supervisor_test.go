@@ -3,6 +3,7 @@ package katago
 import (
 	"context"
 	"errors"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -20,6 +21,10 @@ type mockEngine struct {
 	failStart  atomic.Bool
 	failPing   atomic.Bool
 	startDelay time.Duration
+	// Reason the first diagnostics were collected for
+	diagnosed atomic.Value
+	// Number of pings to fail, one after the other, before failPing applies
+	pingFailures atomic.Int32
 }
 
 func (m *mockEngine) Start(ctx context.Context) error {
@@ -40,12 +45,20 @@ func (m *mockEngine) Stop() error {
 	return nil
 }
 
+func (m *mockEngine) Drain(ctx context.Context) error {
+	return m.Stop()
+}
+
 func (m *mockEngine) IsRunning() bool {
 	return m.running.Load()
 }
 
 func (m *mockEngine) Ping(ctx context.Context) error {
 	m.pingCount.Add(1)
+	if m.pingFailures.Load() > 0 {
+		m.pingFailures.Add(-1)
+		return errors.New("ping failed")
+	}
 	if m.failPing.Load() {
 		return errors.New("ping failed")
 	}
@@ -55,6 +68,39 @@ func (m *mockEngine) Ping(ctx context.Context) error {
 	return nil
 }
 
+func (m *mockEngine) ProcessID() int {
+	return 0
+}
+
+func (m *mockEngine) Load() EngineLoad {
+	return EngineLoad{}
+}
+
+func (m *mockEngine) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+func (m *mockEngine) StartupProgress() StartupProgress {
+	return StartupProgress{}
+}
+
+func (m *mockEngine) Recording() []RecordedLine {
+	return nil
+}
+
+func (m *mockEngine) SlowQueries() []SlowQuery {
+	return nil
+}
+
+func (m *mockEngine) CollectDiagnostics(reason string) *DiagnosticsBundle {
+	m.diagnosed.CompareAndSwap(nil, reason)
+	return &DiagnosticsBundle{Reason: reason}
+}
+
+func (m *mockEngine) Diagnostics() *DiagnosticsBundle {
+	return nil
+}
+
 func (m *mockEngine) Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
 	return nil, errors.New("not implemented")
 }
@@ -67,11 +113,51 @@ func (m *mockEngine) ReviewGame(ctx context.Context, sgf string, thresholds *Mis
 	return nil, errors.New("not implemented")
 }
 
+func (m *mockEngine) KeyMoments(ctx context.Context, sgf string, opts *KeyMomentOptions) (*KeyMoments, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (m *mockEngine) EstimateTerritory(ctx context.Context, position *Position, threshold float64) (*TerritoryEstimate, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (m *mockEngine) ExplainMove(ctx context.Context, position *Position, move string) (*MoveExplanation, error) {
+func (m *mockEngine) TerritoryTimeline(ctx context.Context, sgf string, opts *TimelineOptions) (*TerritoryTimeline, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockEngine) ExplainMove(ctx context.Context, position *Position, move string, opts *ExplainOptions) (*MoveExplanation, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockEngine) SuggestHumanMove(ctx context.Context, position *Position, humanProfile string) (*HumanMoveSuggestion, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockEngine) EstimateRank(ctx context.Context, sgfs []string, player string, opts *RankOptions) (*RankEstimate, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockEngine) ExpandVariation(ctx context.Context, position *Position, move string, opts *VariationOptions) (*VariationTree, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockEngine) SweepKomi(ctx context.Context, position *Position, opts *KomiSweepOptions) (*KomiSweep, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockEngine) WhatIf(ctx context.Context, position *Position, moves []string, opts *WhatIfOptions) (*WhatIfResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockEngine) SolveTsumego(ctx context.Context, position *Position, opts *TsumegoOptions) (*TsumegoSolution, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockEngine) GenerateProblems(ctx context.Context, sgfs []string, player string, opts *ProblemOptions) (*ProblemSet, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockEngine) OpeningBook(ctx context.Context, sgfs []string, player string, opts *OpeningOptions) (*OpeningBook, error) {
 	return nil, errors.New("not implemented")
 }
 
@@ -121,6 +207,26 @@ func TestSupervisor(t *testing.T) {
 		}
 	})
 
+	t.Run("engine already started on demand", func(t *testing.T) {
+		supervisor := NewSupervisor(&config.KataGoConfig{}, logger, nil)
+		mock := &mockEngine{}
+		mock.running.Store(true)
+		supervisor.engine = mock
+
+		if err := supervisor.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start supervisor: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+
+		if mock.startCount.Load() != 0 {
+			t.Errorf("Expected running engine to be adopted, got %d start calls", mock.startCount.Load())
+		}
+		if supervisor.Status().Restarting {
+			t.Error("Expected supervisor to finish starting")
+		}
+		_ = supervisor.Stop()
+	})
+
 	t.Run("auto restart on failure", func(t *testing.T) {
 		cfg := &config.KataGoConfig{}
 		supervisor := NewSupervisor(cfg, logger, nil)
@@ -156,6 +262,31 @@ func TestSupervisor(t *testing.T) {
 		_ = supervisor.Stop()
 	})
 
+	t.Run("diagnostics on failed health check", func(t *testing.T) {
+		cfg := &config.KataGoConfig{HealthCheck: config.HealthCheckConfig{MaxFailures: 1}}
+		supervisor := NewSupervisor(cfg, logger, nil)
+		supervisor.healthCheckInterval = 100 * time.Millisecond
+		mock := &mockEngine{}
+		supervisor.engine = mock
+
+		if err := supervisor.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start supervisor: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if mock.diagnosed.Load() != nil {
+			t.Fatal("Expected no diagnostics for a healthy engine")
+		}
+
+		// Simulate a hung engine
+		mock.failPing.Store(true)
+		time.Sleep(200 * time.Millisecond)
+		_ = supervisor.Stop()
+
+		if reason, _ := mock.diagnosed.Load().(string); reason != "health check failed: ping failed" {
+			t.Errorf("Expected diagnostics for the failed health check, got %q", reason)
+		}
+	})
+
 	t.Run("manual restart", func(t *testing.T) {
 		cfg := &config.KataGoConfig{}
 		supervisor := NewSupervisor(cfg, logger, nil)
@@ -237,7 +368,7 @@ func TestSupervisor(t *testing.T) {
 	})
 
 	t.Run("health check with ping failure", func(t *testing.T) {
-		cfg := &config.KataGoConfig{}
+		cfg := &config.KataGoConfig{HealthCheck: config.HealthCheckConfig{MaxFailures: 1}}
 		supervisor := NewSupervisor(cfg, logger, nil)
 		supervisor.healthCheckInterval = 100 * time.Millisecond
 
@@ -270,4 +401,142 @@ func TestSupervisor(t *testing.T) {
 		// Stop supervisor
 		_ = supervisor.Stop()
 	})
+
+	t.Run("restart after repeated health check failures", func(t *testing.T) {
+		supervisor := NewSupervisor(&config.KataGoConfig{}, logger, nil)
+		mock := &mockEngine{}
+		mock.running.Store(true)
+		supervisor.engine = mock
+		ctx := context.Background()
+
+		// Failures followed by a success do not restart the engine
+		mock.pingFailures.Store(config.DefaultHealthCheckFailures - 1)
+		for i := 0; i < config.DefaultHealthCheckFailures; i++ {
+			supervisor.checkHealth(ctx)
+		}
+		if mock.stopCount.Load() != 0 || supervisor.failures != 0 {
+			t.Fatalf("Expected no restart after a recovered failure, got %d stops and %d failures",
+				mock.stopCount.Load(), supervisor.failures)
+		}
+
+		mock.pingFailures.Store(config.DefaultHealthCheckFailures)
+		for i := 1; i < config.DefaultHealthCheckFailures; i++ {
+			supervisor.checkHealth(ctx)
+		}
+		if mock.stopCount.Load() != 0 {
+			t.Fatalf("Expected no restart before %d failures", config.DefaultHealthCheckFailures)
+		}
+		supervisor.checkHealth(ctx)
+		if mock.stopCount.Load() != 1 || mock.startCount.Load() != 1 || supervisor.failures != 0 {
+			t.Errorf("Expected a restart after %d failures, got %d stops and %d starts",
+				config.DefaultHealthCheckFailures, mock.stopCount.Load(), mock.startCount.Load())
+		}
+		if reason, _ := mock.diagnosed.Load().(string); reason != "health check failed: ping failed" {
+			t.Errorf("Expected diagnostics for the failed health check, got %q", reason)
+		}
+	})
+}
+
+func TestSupervisorReady(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	supervisor := NewSupervisor(&config.KataGoConfig{}, logger, nil)
+	mock := NewMockEngine()
+	supervisor.engine = mock
+	limits := &config.ReadinessConfig{MaxPendingQueries: 4, MaxQueuedQueries: 2}
+	ctx := context.Background()
+
+	if err := supervisor.Ready(ctx, limits); err == nil {
+		t.Error("Expected stopped engine to be unready")
+	}
+
+	mock.SetRunning(true)
+	mock.SetLoad(EngineLoad{Pending: 3, Queued: 1})
+	if err := supervisor.Ready(ctx, limits); err != nil {
+		t.Errorf("Expected engine under its limits to be ready: %v", err)
+	}
+	if status := supervisor.Status(); !status.Running || status.Pending != 3 || status.Queued != 1 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	mock.SetLoad(EngineLoad{Pending: 4})
+	if err := supervisor.Ready(ctx, limits); err == nil || !strings.Contains(err.Error(), "pending") {
+		t.Errorf("Expected pending limit error, got %v", err)
+	}
+	mock.SetLoad(EngineLoad{Queued: 2})
+	if err := supervisor.Ready(ctx, limits); err == nil || !strings.Contains(err.Error(), "queued") {
+		t.Errorf("Expected queued limit error, got %v", err)
+	}
+	if err := supervisor.Ready(ctx, &config.ReadinessConfig{}); err != nil {
+		t.Errorf("Expected zero limits to be unlimited: %v", err)
+	}
+
+	mock.SetLoad(EngineLoad{})
+	mock.SetStartupProgress(StartupProgress{Phase: PhaseTuning, Percent: 45, ElapsedSeconds: 30})
+	if err := supervisor.Ready(ctx, limits); err == nil || !strings.Contains(err.Error(), "tuning, 45%") {
+		t.Errorf("Expected startup error, got %v", err)
+	}
+	if status := supervisor.Status(); status.Startup.Percent != 45 {
+		t.Errorf("Expected status to report startup progress, got %+v", status.Startup)
+	}
+	mock.SetStartupProgress(StartupProgress{Phase: PhaseReady, Percent: 100})
+	if err := supervisor.Ready(ctx, limits); err != nil {
+		t.Errorf("Expected started engine to be ready: %v", err)
+	}
+
+	mock.SetLoad(EngineLoad{Draining: true})
+	if err := supervisor.Ready(ctx, limits); err == nil || !strings.Contains(err.Error(), "draining") {
+		t.Errorf("Expected draining error, got %v", err)
+	}
+	mock.SetLoad(EngineLoad{})
+
+	supervisor.restarting.Store(true)
+	if err := supervisor.Ready(ctx, limits); err == nil || !strings.Contains(err.Error(), "restarting") {
+		t.Errorf("Expected restarting error, got %v", err)
+	}
+	if !supervisor.Status().Restarting {
+		t.Error("Expected status to report restarting")
+	}
+}
+
+func TestSupervisorIdleSuspend(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	supervisor := NewSupervisor(&config.KataGoConfig{IdleSuspendMinutes: 1}, logger, nil)
+	supervisor.healthCheckInterval = 10 * time.Millisecond
+	mock := NewMockEngine()
+	mock.SetRunning(true)
+	mock.SetLoad(EngineLoad{IdleSeconds: 30})
+	supervisor.engine = mock
+	ctx := context.Background()
+
+	if err := supervisor.Start(ctx); err != nil {
+		t.Fatalf("Failed to start supervisor: %v", err)
+	}
+	defer func() { _ = supervisor.Stop() }()
+	time.Sleep(50 * time.Millisecond)
+	if !mock.IsRunning() || supervisor.Status().Suspended {
+		t.Fatal("Expected an engine idle for less than the limit to keep running")
+	}
+
+	mock.SetLoad(EngineLoad{IdleSeconds: 61})
+	waitFor(t, func() bool { return !mock.IsRunning() })
+	if status := supervisor.Status(); !status.Suspended || status.Running {
+		t.Errorf("Expected the idle engine to be suspended, got %+v", status)
+	}
+	if err := supervisor.Ready(ctx, nil); err != nil {
+		t.Errorf("Expected a suspended engine to stay ready for the next request: %v", err)
+	}
+
+	// The supervisor leaves it stopped until a request starts it
+	time.Sleep(50 * time.Millisecond)
+	if mock.IsRunning() {
+		t.Fatal("Expected the suspended engine not to be restarted by health checks")
+	}
+	mock.SetLoad(EngineLoad{})
+	if err := mock.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return !supervisor.suspended.Load() })
+	if status := supervisor.Status(); status.Suspended || !status.Running {
+		t.Errorf("Expected the engine started on demand to be running, got %+v", status)
+	}
 }
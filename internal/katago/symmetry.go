@@ -0,0 +1,334 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// Symmetry is one of the 8 symmetries of a square board: an optional
+// transpose followed by optional horizontal and vertical flips. Rectangular
+// boards have only the 4 symmetries without a transpose.
+type Symmetry int
+
+const (
+	symFlipX     Symmetry = 1 << iota // Mirror left to right
+	symFlipY                          // Mirror top to bottom
+	symTranspose                      // Swap rows and columns
+)
+
+// Symmetries returns the symmetries of a board, starting with the identity.
+func Symmetries(xSize, ySize int) []Symmetry {
+	count := 4
+	if xSize == ySize {
+		count = 8
+	}
+	syms := make([]Symmetry, count)
+	for i := range syms {
+		syms[i] = Symmetry(i)
+	}
+	return syms
+}
+
+// Inverse returns the symmetry that undoes s.
+func (s Symmetry) Inverse() Symmetry {
+	if s&symTranspose == 0 {
+		return s
+	}
+	// Flips applied after a transpose swap axes when undone
+	inv := symTranspose
+	if s&symFlipX != 0 {
+		inv |= symFlipY
+	}
+	if s&symFlipY != 0 {
+		inv |= symFlipX
+	}
+	return inv
+}
+
+// apply maps 0-based column and row indices, with row 0 at the top.
+func (s Symmetry) apply(x, y, xSize, ySize int) (int, int) {
+	if s&symTranspose != 0 {
+		x, y = y, x
+	}
+	if s&symFlipX != 0 {
+		x = xSize - 1 - x
+	}
+	if s&symFlipY != 0 {
+		y = ySize - 1 - y
+	}
+	return x, y
+}
+
+// TransformMove maps a GTP move. Passes and empty locations are unchanged.
+func (s Symmetry) TransformMove(move string, xSize, ySize int) (string, error) {
+	if move == "" || strings.EqualFold(move, "pass") {
+		return move, nil
+	}
+	x, y, err := regionCorner(strings.ToUpper(move), xSize, ySize)
+	if err != nil {
+		return "", err
+	}
+	x, y = s.apply(x, y, xSize, ySize)
+	return fmt.Sprintf("%c%d", ColumnLetter(x), ySize-y), nil
+}
+
+// TransformPosition returns a copy of position with every stone and move
+// mapped by s.
+func (s Symmetry) TransformPosition(position *Position) (*Position, error) {
+	next := *position
+	next.InitialStones = make([]Stone, len(position.InitialStones))
+	for i, stone := range position.InitialStones {
+		location, err := s.TransformMove(stone.Location, position.BoardXSize, position.BoardYSize)
+		if err != nil {
+			return nil, err
+		}
+		next.InitialStones[i] = Stone{Color: stone.Color, Location: location}
+	}
+	next.Moves = make([]Move, len(position.Moves))
+	for i, move := range position.Moves {
+		location, err := s.TransformMove(move.Location, position.BoardXSize, position.BoardYSize)
+		if err != nil {
+			return nil, err
+		}
+		next.Moves[i] = Move{Color: move.Color, Location: location}
+	}
+	return &next, nil
+}
+
+// TransformGrid maps a per-point array such as a policy or ownership map,
+// stored row by row from the top. Trailing entries beyond the board, like
+// the pass probability of a policy, are copied unchanged.
+func (s Symmetry) TransformGrid(values []float64, xSize, ySize int) []float64 {
+	if len(values) < xSize*ySize {
+		return values
+	}
+	out := make([]float64, len(values))
+	copy(out[xSize*ySize:], values[xSize*ySize:])
+	for y := 0; y < ySize; y++ {
+		for x := 0; x < xSize; x++ {
+			tx, ty := s.apply(x, y, xSize, ySize)
+			out[ty*xSize+tx] = values[y*xSize+x]
+		}
+	}
+	return out
+}
+
+// analyzeSymmetries analyzes a position under every board symmetry and
+// averages the results, removing the network's bias toward particular
+// orientations. Each symmetry is searched with the request's full budget.
+func analyzeSymmetries(ctx context.Context, analyze func(context.Context, *AnalysisRequest) (*AnalysisResult, error),
+	req *AnalysisRequest) (*AnalysisResult, error) {
+	if req.IncludeMovesOwnership {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "per-move ownership is not supported with symmetry averaging")
+	}
+	xSize, ySize := req.Position.BoardXSize, req.Position.BoardYSize
+	syms := Symmetries(xSize, ySize)
+
+	// Build every query before sending any, so a bad coordinate fails fast
+	requests := make([]*AnalysisRequest, len(syms))
+	for i, sym := range syms {
+		position, err := sym.TransformPosition(req.Position)
+		if err != nil {
+			return nil, err
+		}
+		symReq := *req
+		symReq.AverageSymmetries = false
+		symReq.Position = position
+		if symReq.AvoidMoves, err = transformMoves(sym, req.AvoidMoves, xSize, ySize); err != nil {
+			return nil, err
+		}
+		if symReq.AllowMoves, err = transformMoves(sym, req.AllowMoves, xSize, ySize); err != nil {
+			return nil, err
+		}
+		requests[i] = &symReq
+	}
+
+	results := make([]*AnalysisResult, len(syms))
+	errs := make([]error, len(syms))
+	var wg sync.WaitGroup
+	for i := range syms {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = analyze(ctx, requests[i])
+		}(i)
+	}
+	wg.Wait()
+
+	// A missing orientation would reintroduce the bias, so all must succeed
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("symmetry %d: %w", syms[i], err)
+		}
+	}
+	for i, sym := range syms {
+		restored, err := restoreResult(results[i], sym.Inverse(), xSize, ySize)
+		if err != nil {
+			return nil, fmt.Errorf("symmetry %d: %w", sym, err)
+		}
+		results[i] = restored
+	}
+	return averageResults(results), nil
+}
+
+// transformMoves maps a list of moves.
+func transformMoves(sym Symmetry, moves []string, xSize, ySize int) ([]string, error) {
+	if len(moves) == 0 {
+		return moves, nil
+	}
+	out := make([]string, len(moves))
+	for i, move := range moves {
+		var err error
+		if out[i], err = sym.TransformMove(move, xSize, ySize); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// restoreResult maps a result analyzed under a symmetry back to the
+// original orientation, where inv is the inverse of that symmetry.
+func restoreResult(result *AnalysisResult, inv Symmetry, xSize, ySize int) (*AnalysisResult, error) {
+	restored := &AnalysisResult{
+		RootInfo:       result.RootInfo,
+		MoveInfos:      make([]MoveInfo, len(result.MoveInfos)),
+		Policy:         inv.TransformGrid(result.Policy, xSize, ySize),
+		HumanPolicy:    inv.TransformGrid(result.HumanPolicy, xSize, ySize),
+		Ownership:      inv.TransformGrid(result.Ownership, xSize, ySize),
+		OwnershipStdev: inv.TransformGrid(result.OwnershipStdev, xSize, ySize),
+	}
+	for i, mi := range result.MoveInfos {
+		move, err := inv.TransformMove(mi.Move, xSize, ySize)
+		if err != nil {
+			return nil, err
+		}
+		pv, err := transformMoves(inv, mi.PV, xSize, ySize)
+		if err != nil {
+			return nil, err
+		}
+		mi.Move, mi.PV = move, pv
+		restored.MoveInfos[i] = mi
+	}
+	return restored, nil
+}
+
+// averageResults combines results in the same orientation. Root values and
+// maps are averaged; candidate moves are merged with visits summed and their
+// evaluations weighted by visits, keeping the PV of the deepest search.
+func averageResults(results []*AnalysisResult) *AnalysisResult {
+	n := float64(len(results))
+	avg := &AnalysisResult{RootInfo: RootInfo{CurrentPlayer: results[0].RootInfo.CurrentPlayer}}
+
+	type merged struct {
+		info        MoveInfo
+		bestVisits  int
+		weight      float64
+		priors      float64
+		humanPriors float64
+		seen        int
+	}
+	moves := make(map[string]*merged)
+	var order []string
+
+	for _, r := range results {
+		root := r.RootInfo
+		avg.RootInfo.Visits += root.Visits
+		avg.RootInfo.Winrate += root.Winrate / n
+		avg.RootInfo.ScoreLead += root.ScoreLead / n
+		avg.RootInfo.ScoreMean += root.ScoreMean / n
+		avg.RootInfo.ScoreStdev += root.ScoreStdev / n
+		avg.RootInfo.Utility += root.Utility / n
+		avg.RootInfo.RawWinrate += root.RawWinrate / n
+		avg.RootInfo.RawLead += root.RawLead / n
+		avg.RootInfo.RawScoreSelfplay += root.RawScoreSelfplay / n
+		avg.RootInfo.RawStWrError += root.RawStWrError / n
+		avg.RootInfo.RawStScoreError += root.RawStScoreError / n
+		avg.RootInfo.RawVarTimeLeft += root.RawVarTimeLeft / n
+
+		for _, mi := range r.MoveInfos {
+			m, ok := moves[mi.Move]
+			if !ok {
+				m = &merged{info: MoveInfo{Move: mi.Move}}
+				moves[mi.Move] = m
+				order = append(order, mi.Move)
+			}
+			// Weight by visits, but never ignore a move searched only once
+			w := float64(max(mi.Visits, 1))
+			m.weight += w
+			m.info.Visits += mi.Visits
+			m.info.Winrate += mi.Winrate * w
+			m.info.ScoreLead += mi.ScoreLead * w
+			m.info.ScoreMean += mi.ScoreMean * w
+			m.info.ScoreStdev += mi.ScoreStdev * w
+			m.info.Utility += mi.Utility * w
+			m.info.LCB += mi.LCB * w
+			m.priors += mi.Prior
+			m.humanPriors += mi.HumanPrior
+			m.seen++
+			if mi.Visits > m.bestVisits || m.info.PV == nil {
+				m.bestVisits = mi.Visits
+				m.info.PV, m.info.PVVisits, m.info.PVEdgeVisits = mi.PV, mi.PVVisits, mi.PVEdgeVisits
+			}
+		}
+	}
+
+	for _, move := range order {
+		m := moves[move]
+		info := m.info
+		info.Winrate /= m.weight
+		info.ScoreLead /= m.weight
+		info.ScoreMean /= m.weight
+		info.ScoreStdev /= m.weight
+		info.Utility /= m.weight
+		info.LCB /= m.weight
+		info.Prior = m.priors / float64(m.seen)
+		info.HumanPrior = m.humanPriors / float64(m.seen)
+		avg.MoveInfos = append(avg.MoveInfos, info)
+	}
+	sort.SliceStable(avg.MoveInfos, func(i, j int) bool {
+		return avg.MoveInfos[i].Visits > avg.MoveInfos[j].Visits
+	})
+	for i := range avg.MoveInfos {
+		avg.MoveInfos[i].Order = i
+	}
+
+	avg.Policy = averageGrids(results, func(r *AnalysisResult) []float64 { return r.Policy }, true)
+	avg.HumanPolicy = averageGrids(results, func(r *AnalysisResult) []float64 { return r.HumanPolicy }, true)
+	avg.Ownership = averageGrids(results, func(r *AnalysisResult) []float64 { return r.Ownership }, false)
+	avg.OwnershipStdev = averageGrids(results, func(r *AnalysisResult) []float64 { return r.OwnershipStdev }, false)
+	return avg
+}
+
+// averageGrids averages a per-point array across results. For policies,
+// points that are illegal (-1) in any result stay illegal.
+func averageGrids(results []*AnalysisResult, grid func(*AnalysisResult) []float64, policy bool) []float64 {
+	first := grid(results[0])
+	if len(first) == 0 {
+		return nil
+	}
+	out := make([]float64, len(first))
+	illegal := make([]bool, len(first))
+	for _, r := range results {
+		values := grid(r)
+		for i := range out {
+			if i >= len(values) {
+				continue
+			}
+			out[i] += values[i] / float64(len(results))
+			if policy && values[i] < 0 {
+				illegal[i] = true
+			}
+		}
+	}
+	for i := range out {
+		if illegal[i] {
+			out[i] = -1
+		}
+	}
+	return out
+}
@@ -0,0 +1,165 @@
+package katago
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+func TestSymmetryInverse(t *testing.T) {
+	const size = 5
+	seen := make(map[[2]int]bool)
+	for _, sym := range Symmetries(size, size) {
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				tx, ty := sym.apply(x, y, size, size)
+				if bx, by := sym.Inverse().apply(tx, ty, size, size); bx != x || by != y {
+					t.Errorf("Symmetry %d: (%d,%d) maps back to (%d,%d)", sym, x, y, bx, by)
+				}
+			}
+		}
+		// B1 has a distinct image under every symmetry
+		tx, ty := sym.apply(1, size-1, size, size)
+		seen[[2]int{tx, ty}] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("Expected 8 distinct images of B1, got %d", len(seen))
+	}
+
+	if got := len(Symmetries(19, 13)); got != 4 {
+		t.Errorf("Expected 4 symmetries on a rectangular board, got %d", got)
+	}
+}
+
+func TestTransformMove(t *testing.T) {
+	tests := []struct {
+		sym  Symmetry
+		move string
+		want string
+	}{
+		{0, "D4", "D4"},
+		{symFlipX, "A1", "T1"},
+		{symFlipY, "A1", "A19"},
+		{symTranspose, "A1", "T19"},
+		{symTranspose, "D3", "R16"},
+		{symFlipX | symFlipY, "q16", "D4"},
+		{symTranspose, "pass", "pass"},
+	}
+	for _, tt := range tests {
+		got, err := tt.sym.TransformMove(tt.move, 19, 19)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Symmetry %d of %s: expected %s, got %s", tt.sym, tt.move, tt.want, got)
+		}
+	}
+	if _, err := symFlipX.TransformMove("Z9", 19, 19); apperrors.CodeOf(err) != apperrors.CodeBadCoordinate {
+		t.Errorf("Expected %s, got %v", apperrors.CodeBadCoordinate, err)
+	}
+}
+
+func TestTransformGrid(t *testing.T) {
+	// 2x2 board plus pass: A2 B2 / A1 B1
+	grid := []float64{1, 2, 3, 4, 9}
+	got := symFlipX.TransformGrid(grid, 2, 2)
+	want := []float64{2, 1, 4, 3, 9}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestAnalyzeSymmetries(t *testing.T) {
+	position := &Position{
+		Rules: "chinese", BoardXSize: 9, BoardYSize: 9,
+		Moves: []Move{{Color: "b", Location: "C4"}},
+	}
+
+	var mu sync.Mutex
+	firstMoves := make(map[string]bool)
+	var calls int
+	// A network biased toward the lower-left corner of whatever it is shown
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		firstMoves[req.Position.Moves[0].Location] = true
+		policy := make([]float64, 82)
+		policy[72] = 1 // A1
+		if req.Position.Moves[0].Location == "C4" {
+			policy[0] = -1 // A9 is illegal in the identity orientation only
+		}
+		return &AnalysisResult{
+			RootInfo:  RootInfo{Visits: 10, Winrate: 0.4 + 0.01*float64(calls), CurrentPlayer: "W"},
+			MoveInfos: []MoveInfo{{Move: "A1", Visits: 10, Winrate: 0.5, Prior: 0.8, PV: []string{"A1", "B2"}}},
+			Policy:    policy,
+		}, nil
+	}
+
+	result, err := analyzeSymmetries(context.Background(), analyze, &AnalysisRequest{Position: position, AverageSymmetries: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 8 || len(firstMoves) != 8 {
+		t.Errorf("Expected 8 distinct orientations, got %d calls and %v", calls, firstMoves)
+	}
+	if result.RootInfo.Visits != 80 || math.Abs(result.RootInfo.Winrate-0.445) > 1e-9 {
+		t.Errorf("Expected averaged root info, got %+v", result.RootInfo)
+	}
+
+	// A1 in each orientation is one of the four corners of the original
+	if len(result.MoveInfos) != 4 {
+		t.Fatalf("Expected the four corners, got %+v", result.MoveInfos)
+	}
+	corners := map[string]bool{"A1": true, "J1": true, "A9": true, "J9": true}
+	for i, mi := range result.MoveInfos {
+		if !corners[mi.Move] || mi.Visits != 20 || mi.Prior != 0.8 || mi.Order != i {
+			t.Errorf("Unexpected merged move: %+v", mi)
+		}
+		if pv1, _ := Symmetry(0).TransformMove(mi.PV[0], 9, 9); pv1 != mi.Move {
+			t.Errorf("Expected PV to start with %s, got %v", mi.Move, mi.PV)
+		}
+	}
+	for _, index := range []int{8, 72, 80} {
+		if p := result.Policy[index]; math.Abs(p-0.25) > 1e-9 {
+			t.Errorf("Expected corner policy 0.25 at %d, got %v", index, p)
+		}
+	}
+	// A9 was illegal in the identity orientation
+	if result.Policy[0] != -1 {
+		t.Errorf("Expected a point illegal in any orientation to stay illegal, got %v", result.Policy[0])
+	}
+	if result.Policy[40] != 0 {
+		t.Errorf("Expected no policy at the center, got %v", result.Policy[40])
+	}
+}
+
+func TestAnalyzeSymmetriesErrors(t *testing.T) {
+	position := &Position{Rules: "chinese", BoardXSize: 9, BoardYSize: 9}
+	var mu sync.Mutex
+	var calls int
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 3 {
+			return nil, errors.New("engine failure")
+		}
+		return &AnalysisResult{}, nil
+	}
+
+	if _, err := analyzeSymmetries(context.Background(), analyze, &AnalysisRequest{Position: position}); err == nil {
+		t.Error("Expected an error when one orientation fails")
+	}
+
+	_, err := analyzeSymmetries(context.Background(), analyze, &AnalysisRequest{Position: position, IncludeMovesOwnership: true})
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
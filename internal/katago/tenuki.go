@@ -0,0 +1,81 @@
+package katago
+
+import "strings"
+
+const (
+	// hotAreaRadius is the distance within which KataGo's top choices must
+	// lie from the best move for the position to have a single hot area.
+	hotAreaRadius = 3
+	// tenukiDistance is the minimum distance from every top choice at which
+	// a played move counts as a tenuki.
+	tenukiDistance = 6
+	// maxHotAreaMoves is the number of top choices that define a hot area.
+	maxHotAreaMoves = 3
+	// hotAreaVisitShare is the fraction of the best move's visits a move
+	// needs to count as a top choice.
+	hotAreaVisitShare = 0.1
+)
+
+// detectTenuki reports whether the played move ignores a hot area: KataGo's
+// top choices are concentrated in one part of the board and the played move
+// is far from all of them. It returns the top choices that define the area.
+// Passes are never treated as tenuki.
+func detectTenuki(result *AnalysisResult, played string, xSize, ySize int) ([]string, bool) {
+	if played == "" || strings.EqualFold(played, "pass") || len(result.MoveInfos) == 0 {
+		return nil, false
+	}
+	px, py, err := regionCorner(played, xSize, ySize)
+	if err != nil {
+		return nil, false
+	}
+
+	best := result.MoveInfos[0]
+	bx, by, err := regionCorner(best.Move, xSize, ySize)
+	if err != nil {
+		return nil, false
+	}
+
+	hotArea := []string{best.Move}
+	nearest := boardDistance(px, py, bx, by)
+	for _, mi := range result.MoveInfos[1:] {
+		if len(hotArea) == maxHotAreaMoves {
+			break
+		}
+		if float64(mi.Visits) < float64(best.Visits)*hotAreaVisitShare {
+			continue
+		}
+		x, y, err := regionCorner(mi.Move, xSize, ySize)
+		if err != nil {
+			// A serious candidate pass means no part of the board is urgent
+			return nil, false
+		}
+		if boardDistance(x, y, bx, by) > hotAreaRadius {
+			// Good moves in several places: there is no single hot area
+			return nil, false
+		}
+		hotArea = append(hotArea, mi.Move)
+		if d := boardDistance(px, py, x, y); d < nearest {
+			nearest = d
+		}
+	}
+
+	if nearest < tenukiDistance {
+		return nil, false
+	}
+	return hotArea, true
+}
+
+// boardDistance returns the Chebyshev distance between two points.
+func boardDistance(x0, y0, x1, y1 int) int {
+	dx, dy := x0-x1, y0-y1
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
@@ -0,0 +1,69 @@
+package katago
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+func TestDetectTenuki(t *testing.T) {
+	// A fight around R16: every top choice is local
+	local := &AnalysisResult{MoveInfos: []MoveInfo{
+		{Move: "R16", Visits: 500},
+		{Move: "Q17", Visits: 200},
+		{Move: "R14", Visits: 100},
+		{Move: "C3", Visits: 5},
+	}}
+
+	tests := []struct {
+		name    string
+		result  *AnalysisResult
+		played  string
+		want    bool
+		hotArea []string
+	}{
+		{"far from hot area", local, "D4", true, []string{"R16", "Q17", "R14"}},
+		{"answers locally", local, "Q14", false, nil},
+		{"near the hot area", local, "M16", false, nil},
+		{"pass", local, "", false, nil},
+		{"good moves in two areas", &AnalysisResult{MoveInfos: []MoveInfo{
+			{Move: "R16", Visits: 500},
+			{Move: "D4", Visits: 400},
+		}}, "K10", false, nil},
+		{"low-visit outlier ignored", &AnalysisResult{MoveInfos: []MoveInfo{
+			{Move: "R16", Visits: 500},
+			{Move: "D4", Visits: 10},
+		}}, "C3", true, []string{"R16"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hotArea, ok := detectTenuki(tt.result, tt.played, 19, 19)
+			if ok != tt.want {
+				t.Fatalf("detectTenuki() = %v, want %v", ok, tt.want)
+			}
+			if !reflect.DeepEqual(hotArea, tt.hotArea) {
+				t.Errorf("Expected hot area %v, got %v", tt.hotArea, hotArea)
+			}
+		})
+	}
+}
+
+func TestMarkTenuki(t *testing.T) {
+	result := &AnalysisResult{MoveInfos: []MoveInfo{{Move: "R16", Visits: 500}}}
+	game := &Position{BoardXSize: 19, BoardYSize: 19}
+	summary := &ReviewSummary{}
+
+	mistake := &Mistake{Color: "W", PlayedMove: "D4", WinrateDrop: 0.2}
+	markTenuki(i18n.English, summary, mistake, result, game)
+	if !mistake.Tenuki || summary.WhiteTenukis != 1 || summary.BlackTenukis != 0 {
+		t.Errorf("Expected white tenuki to be counted, got %+v / %+v", mistake, summary)
+	}
+
+	mistake = &Mistake{Color: "B", PlayedMove: "Q15", WinrateDrop: 0.2, Explanation: "unchanged"}
+	markTenuki(i18n.English, summary, mistake, result, game)
+	if mistake.Tenuki || mistake.Explanation != "unchanged" || summary.BlackTenukis != 0 {
+		t.Errorf("Did not expect a local move to be marked, got %+v", mistake)
+	}
+}
@@ -0,0 +1,233 @@
+package katago
+
+import (
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+// Go terms recognized by ClassifyMove.
+const (
+	TermTsuke     = "tsuke"
+	TermHane      = "hane"
+	TermShimari   = "shimari"
+	TermInvasion  = "invasion"
+	TermReduction = "reduction"
+)
+
+// termDefinitions holds a short English definition of each term.
+var termDefinitions = map[string]string{
+	TermTsuke:     "An attachment: a stone played in contact with an opponent's stone that has no friendly support nearby.",
+	TermHane:      "A stone played diagonally from one's own stone to bend around the head or side of an opponent's stone.",
+	TermShimari:   "A corner enclosure: a second stone in a corner that secures it against approaches and invasions.",
+	TermInvasion:  "A stone played low, on the third line or below, inside an area the opponent has staked out, aiming to live there.",
+	TermReduction: "A stone played high, on the fourth or fifth line, above the opponent's framework to shrink it from outside.",
+}
+
+const (
+	// termRadius is the distance within which stones count as near a move
+	// for invasions and reductions.
+	termRadius = 4
+	// shimariDistance is the largest distance between the stones of a
+	// corner enclosure; the large knight's move and two-space extension
+	// are the widest common ones.
+	shimariDistance = 3
+)
+
+// GoTerm is a piece of Go vocabulary describing a move.
+type GoTerm struct {
+	Term       string `json:"term"`
+	Definition string `json:"definition"`
+}
+
+// DescribeTerms pairs terms with their definitions in p's language.
+func DescribeTerms(p *i18n.Printer, terms []string) []GoTerm {
+	result := make([]GoTerm, 0, len(terms))
+	for _, term := range terms {
+		result = append(result, GoTerm{Term: term, Definition: p.T(termDefinitions[term])})
+	}
+	return result
+}
+
+// ClassifyMove names the standard shapes a move by color ("B" or "W") at a
+// GTP location makes on board, the position before the move. Contact moves
+// are a tsuke or a hane; other moves may be a shimari, an invasion or a
+// reduction. It returns nil for passes, occupied points and moves that
+// match no pattern.
+func ClassifyMove(board *Board, color, location string) []string {
+	x, y, err := regionCorner(strings.ToUpper(location), board.xSize, board.ySize)
+	if err != nil || board.points[y*board.xSize+x] != "" {
+		return nil
+	}
+	own := strings.ToLower(color)
+	enemy := strings.ToLower(opponent(color))
+	point := y*board.xSize + x
+
+	var touched []int
+	for _, n := range board.neighbors(point) {
+		switch board.points[n] {
+		case own:
+			// Extending from one's own stone is neither shape
+			return nil
+		case enemy:
+			touched = append(touched, n)
+		}
+	}
+	if len(touched) > 0 {
+		for _, d := range board.diagonals(point) {
+			if board.points[d] != own {
+				continue
+			}
+			for _, n := range touched {
+				if board.adjacent(d, n) {
+					return []string{TermHane}
+				}
+			}
+		}
+		if board.ownNear(point, own, 1) {
+			return nil
+		}
+		return []string{TermTsuke}
+	}
+
+	line := board.line(x, y)
+	switch {
+	case board.isShimari(x, y, own, enemy):
+		return []string{TermShimari}
+	case line <= 3 && board.isInvasion(x, y, own, enemy):
+		return []string{TermInvasion}
+	case line >= 4 && line <= 5 && board.isReduction(x, y, line, own, enemy):
+		return []string{TermReduction}
+	}
+	return nil
+}
+
+// isShimari reports whether a move in a corner joins a lone friendly
+// stone there, with no opponent stones in the corner.
+func (b *Board) isShimari(x, y int, own, enemy string) bool {
+	size := b.cornerSize()
+	if !b.inCorner(x, y, x, y, size) || b.line(x, y) < 3 || b.line(x, y) > 5 {
+		return false
+	}
+	partner := false
+	for p, stone := range b.points {
+		px, py := p%b.xSize, p/b.xSize
+		if stone == "" || !b.inCorner(x, y, px, py, size) {
+			continue
+		}
+		if stone == enemy {
+			return false
+		}
+		if partner {
+			// An enclosure has two stones
+			return false
+		}
+		if d := boardDistance(x, y, px, py); d < 2 || d > shimariDistance {
+			return false
+		}
+		partner = true
+	}
+	return partner
+}
+
+// isInvasion reports whether a low move lands among opponent stones with
+// no friendly stones nearby: between two of them, or at the 3-3 point
+// under a stone on the 4-4 point.
+func (b *Board) isInvasion(x, y int, own, enemy string) bool {
+	if b.ownNear(y*b.xSize+x, own, termRadius) {
+		return false
+	}
+	enemies := b.stonesNear(x, y, enemy, termRadius)
+	if len(enemies) >= 2 {
+		return true
+	}
+	if min(x, b.xSize-1-x) != 2 || min(y, b.ySize-1-y) != 2 {
+		return false
+	}
+	for _, p := range enemies {
+		px, py := p%b.xSize, p/b.xSize
+		if boardDistance(x, y, px, py) == 1 && b.line(px, py) == 4 && x != px && y != py {
+			return true
+		}
+	}
+	return false
+}
+
+// isReduction reports whether a high move sits above at least two
+// opponent stones on lower lines, with no friendly stones nearby.
+func (b *Board) isReduction(x, y, line int, own, enemy string) bool {
+	if b.ownNear(y*b.xSize+x, own, termRadius-1) {
+		return false
+	}
+	below := 0
+	for _, p := range b.stonesNear(x, y, enemy, termRadius) {
+		if b.line(p%b.xSize, p/b.xSize) < line {
+			below++
+		}
+	}
+	return below >= 2
+}
+
+// line returns the 1-based line of a point, counted from the nearest edge.
+func (b *Board) line(x, y int) int {
+	return min(x, y, b.xSize-1-x, b.ySize-1-y) + 1
+}
+
+// cornerSize returns how many lines from each edge count as a corner.
+func (b *Board) cornerSize() int {
+	return max(min(b.xSize, b.ySize)/3, 4)
+}
+
+// inCorner reports whether (px, py) lies in the same corner as (x, y),
+// and (x, y) is in a corner at all.
+func (b *Board) inCorner(x, y, px, py, size int) bool {
+	sameEdge := func(a, pa, n int) bool {
+		if a < size {
+			return pa < size
+		}
+		if a >= n-size {
+			return pa >= n-size
+		}
+		return false
+	}
+	return sameEdge(x, px, b.xSize) && sameEdge(y, py, b.ySize)
+}
+
+// stonesNear returns the points of color's stones within radius of (x, y).
+func (b *Board) stonesNear(x, y int, color string, radius int) []int {
+	var result []int
+	for p, stone := range b.points {
+		if stone == color && boardDistance(x, y, p%b.xSize, p/b.xSize) <= radius {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// ownNear reports whether color has a stone within radius of point.
+func (b *Board) ownNear(point int, color string, radius int) bool {
+	return len(b.stonesNear(point%b.xSize, point/b.xSize, color, radius)) > 0
+}
+
+// diagonals returns the points diagonally adjacent to point.
+func (b *Board) diagonals(point int) []int {
+	x, y := point%b.xSize, point/b.xSize
+	result := make([]int, 0, 4)
+	for _, d := range [][2]int{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+		nx, ny := x+d[0], y+d[1]
+		if nx >= 0 && nx < b.xSize && ny >= 0 && ny < b.ySize {
+			result = append(result, ny*b.xSize+nx)
+		}
+	}
+	return result
+}
+
+// adjacent reports whether two points are orthogonally adjacent.
+func (b *Board) adjacent(p, q int) bool {
+	for _, n := range b.neighbors(p) {
+		if n == q {
+			return true
+		}
+	}
+	return false
+}
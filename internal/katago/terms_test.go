@@ -0,0 +1,62 @@
+package katago
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+func TestClassifyMove(t *testing.T) {
+	tests := []struct {
+		name   string
+		stones [][2]string // color, location
+		color  string
+		move   string
+		want   []string
+	}{
+		{"tsuke", [][2]string{{"w", "Q16"}}, "B", "R16", []string{TermTsuke}},
+		{"hane", [][2]string{{"b", "Q16"}, {"w", "Q15"}}, "B", "R15", []string{TermHane}},
+		{"extension", [][2]string{{"b", "Q16"}, {"w", "R16"}}, "B", "Q15", nil},
+		{"contact away from own stones", [][2]string{{"b", "P15"}, {"w", "Q16"}}, "B", "R16", []string{TermTsuke}},
+		{"contact beside own stone", [][2]string{{"b", "S17"}, {"w", "Q16"}}, "B", "R16", nil},
+		{"knight's move shimari", [][2]string{{"b", "Q16"}}, "B", "R14", []string{TermShimari}},
+		{"approached corner", [][2]string{{"b", "Q16"}, {"w", "R17"}}, "B", "R14", nil},
+		{"3-3 invasion", [][2]string{{"w", "Q16"}}, "B", "R17", []string{TermInvasion}},
+		{"side invasion", [][2]string{{"w", "Q14"}, {"w", "Q8"}}, "B", "R11", []string{TermInvasion}},
+		{"reduction", [][2]string{{"w", "R14"}, {"w", "R8"}}, "B", "Q11", []string{TermReduction}},
+		{"lone stone", [][2]string{{"w", "D4"}}, "B", "K10", nil},
+		{"pass", [][2]string{{"w", "Q16"}}, "B", "pass", nil},
+		{"occupied", [][2]string{{"w", "Q16"}}, "B", "Q16", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := NewBoard(19, 19)
+			for _, stone := range tt.stones {
+				if err := board.Play(stone[0], stone[1]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := ClassifyMove(board, tt.color, tt.move); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ClassifyMove(%s) = %v, want %v", tt.move, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeTerms(t *testing.T) {
+	for term, definition := range termDefinitions {
+		if definition == "" {
+			t.Errorf("%s has no definition", term)
+		}
+	}
+
+	japanese, err := i18n.NewPrinter("ja")
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms := DescribeTerms(japanese, []string{TermHane})
+	if len(terms) != 1 || terms[0].Term != TermHane || terms[0].Definition == termDefinitions[TermHane] {
+		t.Errorf("Expected a translated hane definition, got %+v", terms)
+	}
+}
@@ -14,6 +14,16 @@ type TerritoryEstimate struct {
 	DamePoints     int           `json:"damePoints"`
 	ScoreEstimate  float64       `json:"scoreEstimate"`
 	ScoreString    string        `json:"scoreString"`
+	// Each player's points split into settled ones, pass-alive stones and
+	// territory that no sequence of moves can take, and contested ones,
+	// owned only by KataGo's estimate
+	SettledBlack   int `json:"settledBlack"`
+	SettledWhite   int `json:"settledWhite"`
+	ContestedBlack int `json:"contestedBlack"`
+	ContestedWhite int `json:"contestedWhite"`
+	// SekiPoints counts the stones in seki and the liberties they share,
+	// which are dame whatever the ownership
+	SekiPoints int `json:"sekiPoints"`
 }
 
 // TerritoryMap represents the ownership of each board point.
@@ -21,6 +31,10 @@ type TerritoryMap struct {
 	Territory  [][]string  `json:"territory"`  // "B", "W", or "?" for each point
 	Ownership  [][]float64 `json:"ownership"`  // -1.0 to 1.0 (-1 = white, 1 = black)
 	DeadStones []string    `json:"deadStones"` // List of dead stone groups
+	// Settled is "B" or "W" for each pass-alive point, "" elsewhere
+	Settled    [][]string  `json:"settled"`
+	SekiStones []string    `json:"sekiStones,omitempty"`
+	SekiGroups []SekiGroup `json:"sekiGroups,omitempty"`
 }
 
 // EstimateTerritory analyzes territory ownership for a position.
@@ -41,7 +55,13 @@ func (e *Engine) EstimateTerritory(ctx context.Context, position *Position, thre
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze position: %w", err)
 	}
+	return buildTerritoryEstimate(position, result, threshold)
+}
 
+// buildTerritoryEstimate estimates territory from an analysis of a
+// position that includes ownership. Pass-alive points count for their
+// player whatever the ownership.
+func buildTerritoryEstimate(position *Position, result *AnalysisResult, threshold float64) (*TerritoryEstimate, error) {
 	if len(result.Ownership) == 0 {
 		return nil, fmt.Errorf("no ownership data returned")
 	}
@@ -52,19 +72,29 @@ func (e *Engine) EstimateTerritory(ctx context.Context, position *Position, thre
 		return nil, fmt.Errorf("non-square boards not fully supported")
 	}
 
+	board, err := BoardFromPosition(position)
+	if err != nil {
+		return nil, err
+	}
+	passAlive := board.PassAlive()
+	seki, shared := board.sekiPoints(result.Ownership)
+
 	territoryMap := &TerritoryMap{
 		Territory: make([][]string, boardSize),
 		Ownership: make([][]float64, boardSize),
+		Settled:   make([][]string, boardSize),
 	}
 
 	blackTerritory := 0
 	whiteTerritory := 0
 	damePoints := 0
+	settled := map[string]int{}
 
 	// Convert ownership to territory
 	for y := 0; y < boardSize; y++ {
 		territoryMap.Territory[y] = make([]string, boardSize)
 		territoryMap.Ownership[y] = make([]float64, boardSize)
+		territoryMap.Settled[y] = make([]string, boardSize)
 
 		for x := 0; x < boardSize; x++ {
 			idx := y*boardSize + x
@@ -75,24 +105,41 @@ func (e *Engine) EstimateTerritory(ctx context.Context, position *Position, thre
 			ownership := result.Ownership[idx]
 			territoryMap.Ownership[y][x] = ownership
 
-			// Determine territory based on threshold
-			switch {
-			case ownership > threshold:
-				territoryMap.Territory[y][x] = "B"
+			// Determine territory based on threshold, unless it is settled
+			// or a liberty shared in seki
+			owner := territoryOwner(ownership, threshold)
+			if alive := strings.ToUpper(passAlive[idx]); alive != "" {
+				owner = alive
+				territoryMap.Settled[y][x] = alive
+				settled[alive]++
+			} else if shared[idx] {
+				owner = "?"
+			}
+			territoryMap.Territory[y][x] = owner
+			switch owner {
+			case "B":
 				blackTerritory++
-			case ownership < -threshold:
-				territoryMap.Territory[y][x] = "W"
+			case "W":
 				whiteTerritory++
 			default:
-				territoryMap.Territory[y][x] = "?"
 				damePoints++
 			}
 		}
 	}
 
-	// Identify dead stones (simplified - stones in opponent's strong territory)
-	deadStones := identifyDeadStones(position, territoryMap, threshold)
-	territoryMap.DeadStones = deadStones
+	// Identify dead stones: stones in the opponent's settled or strong
+	// territory, unless they are pass-alive or in seki
+	sekiPoints := 0
+	for point, inSeki := range seki {
+		if inSeki {
+			territoryMap.SekiStones = append(territoryMap.SekiStones, coordToString(point%boardSize, point/boardSize, boardSize))
+		}
+		if inSeki || shared[point] {
+			sekiPoints++
+		}
+	}
+	territoryMap.SekiGroups = board.sekiGroups(seki, shared)
+	territoryMap.DeadStones = identifyDeadStones(board, territoryMap, threshold, seki)
 
 	// Calculate score
 	komi := 6.5 // Default komi, should get from position.Rules
@@ -112,37 +159,42 @@ func (e *Engine) EstimateTerritory(ctx context.Context, position *Position, thre
 		DamePoints:     damePoints,
 		ScoreEstimate:  scoreEstimate,
 		ScoreString:    scoreString,
+		SettledBlack:   settled["B"],
+		SettledWhite:   settled["W"],
+		ContestedBlack: blackTerritory - settled["B"],
+		ContestedWhite: whiteTerritory - settled["W"],
+		SekiPoints:     sekiPoints,
 	}, nil
 }
 
-// identifyDeadStones finds stones that are likely dead.
-func identifyDeadStones(position *Position, territoryMap *TerritoryMap, threshold float64) []string {
+// territoryOwner returns "B" or "W" for a point owned by that player
+// beyond the threshold, or "?" for a point neither player owns.
+func territoryOwner(ownership, threshold float64) string {
+	switch {
+	case ownership > threshold:
+		return "B"
+	case ownership < -threshold:
+		return "W"
+	default:
+		return "?"
+	}
+}
+
+// identifyDeadStones finds stones that are likely dead: groups in the
+// opponent's settled territory, and groups neither settled nor in seki in
+// the opponent's strong territory.
+func identifyDeadStones(b *Board, territoryMap *TerritoryMap, threshold float64, seki []bool) []string {
 	deadStones := []string{}
-	boardSize := position.BoardXSize
+	boardSize := b.xSize
 
-	// Build current board state
+	// Build current board state, captures applied
 	board := make([][]string, boardSize)
 	for y := 0; y < boardSize; y++ {
 		board[y] = make([]string, boardSize)
 		for x := 0; x < boardSize; x++ {
 			board[y][x] = "."
-		}
-	}
-
-	// Apply initial stones
-	for _, stone := range position.InitialStones {
-		x, y := parseCoord(stone.Location, boardSize)
-		if x >= 0 && y >= 0 {
-			board[y][x] = stone.Color
-		}
-	}
-
-	// Apply moves
-	for _, move := range position.Moves {
-		if move.Location != "" && move.Location != "pass" { // Not a pass
-			x, y := parseCoord(move.Location, boardSize)
-			if x >= 0 && y >= 0 {
-				board[y][x] = move.Color
+			if stone := b.points[y*boardSize+x]; stone != "" {
+				board[y][x] = strings.ToUpper(stone)
 			}
 		}
 	}
@@ -158,7 +210,10 @@ func identifyDeadStones(position *Position, territoryMap *TerritoryMap, threshol
 			if board[y][x] != "." && !visited[y][x] {
 				// Check if this stone group is dead
 				group := findGroup(x, y, board, visited)
-				if isGroupDead(group, board[y][x], territoryMap, threshold) {
+				settled := territoryMap.Settled[y][x]
+				switch {
+				case settled == board[y][x] || seki[y*boardSize+x]:
+				case settled != "" || isGroupDead(group, board[y][x], territoryMap, threshold):
 					deadStones = append(deadStones, group...)
 				}
 			}
@@ -298,11 +353,23 @@ func GetTerritoryVisualization(estimate *TerritoryEstimate) string {
 	}
 	sb.WriteString("\n")
 
+	// Points in seki, marked apart from territory
+	inSeki := map[string]bool{}
+	for _, group := range estimate.Map.SekiGroups {
+		for _, coord := range append(append([]string{}, group.Stones...), group.Liberties...) {
+			inSeki[coord] = true
+		}
+	}
+
 	// Board with territory markers
 	for y := 0; y < boardSize; y++ {
 		row := boardSize - y
 		sb.WriteString(fmt.Sprintf("%2d ", row))
 		for x := 0; x < boardSize; x++ {
+			if inSeki[coordToString(x, y, boardSize)] {
+				sb.WriteString(" ×") // Seki
+				continue
+			}
 			switch estimate.Map.Territory[y][x] {
 			case "B":
 				sb.WriteString(" ●") // Black territory
@@ -330,6 +397,13 @@ func GetTerritoryVisualization(estimate *TerritoryEstimate) string {
 	sb.WriteString(fmt.Sprintf("Black territory: %d\n", estimate.BlackTerritory))
 	sb.WriteString(fmt.Sprintf("White territory: %d\n", estimate.WhiteTerritory))
 	sb.WriteString(fmt.Sprintf("Dame points: %d\n", estimate.DamePoints))
+	if estimate.Map.Settled != nil {
+		sb.WriteString(fmt.Sprintf("Settled (pass-alive): Black %d, White %d\n", estimate.SettledBlack, estimate.SettledWhite))
+		sb.WriteString(fmt.Sprintf("Contested: Black %d, White %d\n", estimate.ContestedBlack, estimate.ContestedWhite))
+	}
+	for _, group := range estimate.Map.SekiGroups {
+		sb.WriteString(fmt.Sprintf("Seki (×): %s, sharing %s\n", strings.Join(group.Stones, " "), strings.Join(group.Liberties, " ")))
+	}
 	sb.WriteString(fmt.Sprintf("Score: %s\n", estimate.ScoreString))
 
 	return sb.String()
@@ -0,0 +1,319 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+// The themes a review groups mistakes into.
+const (
+	ThemeDirection    = "direction"    // Playing in the wrong part of the board
+	ThemeLifeAndDeath = "lifeAndDeath" // Letting a group die, or an opponent's live
+	ThemeEndgame      = "endgame"      // Misjudging the size of endgame moves
+	ThemeOverplay     = "overplay"     // Playing into the opponent's strength and dying
+)
+
+// Game phases, from how far a game has gone and how much of it is settled.
+const (
+	phaseOpening    = "opening"
+	phaseMiddlegame = "middlegame"
+	phaseEndgame    = "endgame"
+)
+
+const (
+	// maxThemes is the number of themes a review reports.
+	maxThemes = 3
+	// maxThemedMistakes is the number of costliest mistakes sorted into
+	// themes, each of which costs two queries.
+	maxThemedMistakes = 30
+	// groupSwing is the change in a group's average ownership, from its
+	// owner's point of view, that counts as it dying or coming alive.
+	groupSwing = 1.0
+	// openingShare is the share of the board's points, in moves, within
+	// which a game is in the opening.
+	openingShare = 1.0 / 6
+	// endgameSettledShare is the share of the board's points settled from
+	// which a game is in the endgame.
+	endgameSettledShare = 0.7
+	// directionDistance is the least distance between the played and the
+	// best move for a mistake to be one of direction.
+	directionDistance = 6
+	// overplayPrior is the policy prior under which a move is one the
+	// network would rarely play, so that one played into the opponent's
+	// sphere and not living there is an overplay even if not yet dead.
+	overplayPrior = 0.02
+)
+
+// themeOrder is the order themes are tested in: what happened to the
+// stones first, then where and when the move was played.
+var themeOrder = []string{ThemeLifeAndDeath, ThemeOverplay, ThemeEndgame, ThemeDirection}
+
+// MistakeTheme is a kind of mistake a player keeps making, with the
+// mistakes of that kind.
+type MistakeTheme struct {
+	Theme       string  `json:"theme"` // ThemeDirection, ThemeLifeAndDeath, ThemeEndgame or ThemeOverplay
+	Mistakes    int     `json:"mistakes"`
+	Moves       []int   `json:"moves"`
+	WinrateLost float64 `json:"winrateLost"` // Summed over the mistakes
+	// Region and Phase are where and when most of the mistakes were made:
+	// "corner", "side" or "center", and "opening", "middlegame" or
+	// "endgame"
+	Region string `json:"region,omitempty"`
+	Phase  string `json:"phase"`
+	Advice string `json:"advice"`
+}
+
+// mistakeFeatures describe the position a mistake was made in and what
+// it did to the stones, from the ownership before and after it.
+type mistakeFeatures struct {
+	moveNumber  int
+	winrateDrop float64
+	region      string // "" for a pass
+	phase       string
+	distance    int  // Between the played and the best move, -1 if either is a pass
+	groupSwung  bool // A group of the mover's died, or one of the opponent's came alive
+	stoneDied   bool // The played stone, in the opponent's sphere, ended up theirs or, if unusual, not the mover's
+}
+
+// theme returns the theme of a mistake, or "" if it fits none.
+func (f mistakeFeatures) theme() string {
+	for _, theme := range themeOrder {
+		switch {
+		case theme == ThemeLifeAndDeath && f.groupSwung,
+			theme == ThemeOverplay && f.stoneDied,
+			theme == ThemeEndgame && f.phase == phaseEndgame,
+			theme == ThemeDirection && (f.distance >= directionDistance || f.phase == phaseOpening):
+			return theme
+		}
+	}
+	return ""
+}
+
+// mistakeThemes sorts the costliest mistakes of a review into themes,
+// tagging each with its theme, and returns the themes that cost the most
+// win rate, at most maxThemes. It analyzes the ownership of the positions
+// before and after each mistake with the given analysis function.
+func mistakeThemes(ctx context.Context, analyze func(context.Context, *AnalysisRequest) (*AnalysisResult, error),
+	p *i18n.Printer, game *Position, review *GameReview, visits int) []MistakeTheme {
+	order := make([]int, len(review.Mistakes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return review.Mistakes[order[a]].WinrateDrop > review.Mistakes[order[b]].WinrateDrop
+	})
+	if len(order) > maxThemedMistakes {
+		order = order[:maxThemedMistakes]
+	}
+
+	ownership := func(n int) []float64 {
+		position := *game
+		position.Moves = game.Moves[:n]
+		req := &AnalysisRequest{Position: &position, IncludeOwnership: true}
+		if visits > 0 {
+			req.MaxVisits = &visits
+		}
+		result, err := analyze(ctx, req)
+		if err != nil {
+			return nil
+		}
+		return result.Ownership
+	}
+
+	var features []mistakeFeatures
+	for _, i := range order {
+		if ctx.Err() != nil {
+			break
+		}
+		mistake := &review.Mistakes[i]
+		n := mistake.MoveNumber
+		board, err := BoardFromPosition(&Position{
+			BoardXSize:    game.BoardXSize,
+			BoardYSize:    game.BoardYSize,
+			InitialStones: game.InitialStones,
+			Moves:         game.Moves[:n-1],
+		})
+		if err != nil {
+			continue
+		}
+		before, after := ownership(n-1), ownership(n)
+		f, ok := featuresOf(board, mistake, before, after)
+		if !ok {
+			continue
+		}
+		mistake.Theme = f.theme()
+		features = append(features, f)
+	}
+	return clusterMistakes(p, features)
+}
+
+// featuresOf describes a mistake from the board before it and the
+// ownership, from Black's point of view, before and after it. It returns
+// false when either ownership map is missing.
+func featuresOf(board *Board, mistake *Mistake, before, after []float64) (mistakeFeatures, bool) {
+	size := board.xSize * board.ySize
+	if len(before) != size || len(after) != size {
+		return mistakeFeatures{}, false
+	}
+	f := mistakeFeatures{moveNumber: mistake.MoveNumber, winrateDrop: mistake.WinrateDrop, distance: -1}
+	sign := 1.0
+	if mistake.Color == "W" {
+		sign = -1
+	}
+
+	settled := 0
+	for _, o := range before {
+		if o > settledOwnership || o < -settledOwnership {
+			settled++
+		}
+	}
+	switch {
+	case float64(mistake.MoveNumber) <= float64(size)*openingShare:
+		f.phase = phaseOpening
+	case float64(settled) >= float64(size)*endgameSettledShare:
+		f.phase = phaseEndgame
+	default:
+		f.phase = phaseMiddlegame
+	}
+
+	px, py, err := regionCorner(strings.ToUpper(mistake.PlayedMove), board.xSize, board.ySize)
+	if err == nil {
+		f.region = getBoardRegion(px, py, board.xSize)
+		point := py*board.xSize + px
+		unusual := mistake.PolicyPlayed < overplayPrior
+		f.stoneDied = sign*before[point] < 0 &&
+			(sign*after[point] < -settledOwnership || (unusual && sign*after[point] < 0))
+		if bx, by, err := regionCorner(strings.ToUpper(mistake.BestMove), board.xSize, board.ySize); err == nil {
+			f.distance = boardDistance(px, py, bx, by)
+		}
+	}
+
+	// Groups of two stones or more whose fate changed
+	seen := make(map[int]bool)
+	for point, stone := range board.points {
+		if stone == "" || seen[point] {
+			continue
+		}
+		stones, _ := board.group(point)
+		owner := 1.0
+		if stone == "w" {
+			owner = -1
+		}
+		var shift float64
+		for _, s := range stones {
+			seen[s] = true
+			shift += owner * (after[s] - before[s])
+		}
+		shift /= float64(len(stones))
+		mover := owner == sign
+		if len(stones) >= 2 && ((mover && shift <= -groupSwing) || (!mover && shift >= groupSwing)) {
+			f.groupSwung = true
+		}
+	}
+	return f, true
+}
+
+// clusterMistakes groups mistakes by theme and returns the themes that
+// cost the most win rate, at most maxThemes, with advice in p's language.
+func clusterMistakes(p *i18n.Printer, features []mistakeFeatures) []MistakeTheme {
+	byTheme := map[string]*MistakeTheme{}
+	regions := map[string]map[string]int{}
+	phases := map[string]map[string]int{}
+	for _, f := range features {
+		name := f.theme()
+		if name == "" {
+			continue
+		}
+		theme, ok := byTheme[name]
+		if !ok {
+			theme = &MistakeTheme{Theme: name, Advice: themeAdvice(p, name)}
+			byTheme[name] = theme
+			regions[name], phases[name] = map[string]int{}, map[string]int{}
+		}
+		theme.Mistakes++
+		theme.Moves = append(theme.Moves, f.moveNumber)
+		theme.WinrateLost += f.winrateDrop
+		if f.region != "" {
+			regions[name][f.region]++
+		}
+		phases[name][f.phase]++
+	}
+
+	themes := make([]MistakeTheme, 0, len(byTheme))
+	for _, name := range themeOrder {
+		theme, ok := byTheme[name]
+		if !ok {
+			continue
+		}
+		sort.Ints(theme.Moves)
+		theme.WinrateLost = roundHundredth(theme.WinrateLost)
+		theme.Region = mostCommon(regions[name])
+		theme.Phase = mostCommon(phases[name])
+		themes = append(themes, *theme)
+	}
+	sort.SliceStable(themes, func(i, j int) bool { return themes[i].WinrateLost > themes[j].WinrateLost })
+	if len(themes) > maxThemes {
+		themes = themes[:maxThemes]
+	}
+	return themes
+}
+
+// mostCommon returns the value counted most often, the first in sorted
+// order on a tie, or "" if none was.
+func mostCommon(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	best := ""
+	for _, k := range keys {
+		if best == "" || counts[k] > counts[best] {
+			best = k
+		}
+	}
+	return best
+}
+
+// ThemeName names a mistake theme in p's language.
+func ThemeName(p *i18n.Printer, theme string) string {
+	switch theme {
+	case ThemeDirection:
+		return p.T("Direction of play")
+	case ThemeLifeAndDeath:
+		return p.T("Life and death")
+	case ThemeEndgame:
+		return p.T("Endgame counting")
+	case ThemeOverplay:
+		return p.T("Overplay")
+	}
+	return theme
+}
+
+// themeAdvice says how to work on a mistake theme, in p's language.
+func themeAdvice(p *i18n.Printer, theme string) string {
+	switch theme {
+	case ThemeDirection:
+		return p.T("Before answering locally, compare the largest open areas of the whole board and which way your stones face.")
+	case ThemeLifeAndDeath:
+		return p.T("Read out whether groups live before leaving or attacking them; life-and-death problems help most.")
+	case ThemeEndgame:
+		return p.T("Count what each boundary move is worth, and play sente moves and the largest gote moves first.")
+	case ThemeOverplay:
+		return p.T("These moves went into the opponent's strength and died; reduce from outside, or strengthen first.")
+	}
+	return ""
+}
+
+// MoveList lists the numbers of the theme's moves, e.g. "45, 88, 120".
+func (t MistakeTheme) MoveList() string {
+	moves := make([]string, len(t.Moves))
+	for i, n := range t.Moves {
+		moves[i] = fmt.Sprint(n)
+	}
+	return strings.Join(moves, ", ")
+}
@@ -0,0 +1,185 @@
+package katago
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+// themeBoard returns a 9x9 board with a black group on C3 and C4 and a
+// white stone on G7, and a function giving the index of a point.
+func themeBoard(t *testing.T) (*Board, func(string) int) {
+	t.Helper()
+	board, err := BoardFromPosition(&Position{BoardXSize: 9, BoardYSize: 9, Moves: []Move{
+		{Color: "B", Location: "C3"}, {Color: "W", Location: "G7"}, {Color: "B", Location: "C4"},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return board, func(coord string) int {
+		x, y, err := regionCorner(coord, 9, 9)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return y*9 + x
+	}
+}
+
+func TestMistakeFeaturesTheme(t *testing.T) {
+	tests := []struct {
+		name     string
+		features mistakeFeatures
+		want     string
+	}{
+		{"group died", mistakeFeatures{groupSwung: true, stoneDied: true, phase: phaseEndgame}, ThemeLifeAndDeath},
+		{"stone died", mistakeFeatures{stoneDied: true, phase: phaseEndgame}, ThemeOverplay},
+		{"endgame", mistakeFeatures{phase: phaseEndgame, distance: 8}, ThemeEndgame},
+		{"far from the best move", mistakeFeatures{phase: phaseMiddlegame, distance: directionDistance}, ThemeDirection},
+		{"opening", mistakeFeatures{phase: phaseOpening, distance: 1}, ThemeDirection},
+		{"local middlegame slip", mistakeFeatures{phase: phaseMiddlegame, distance: 2}, ""},
+		{"pass", mistakeFeatures{phase: phaseMiddlegame, distance: -1}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.features.theme(); got != tt.want {
+				t.Errorf("theme() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeaturesOf(t *testing.T) {
+	board, at := themeBoard(t)
+
+	// The black group dies after a black move far from it
+	before, after := make([]float64, 81), make([]float64, 81)
+	for _, coord := range []string{"C3", "C4"} {
+		before[at(coord)], after[at(coord)] = 0.8, -0.8
+	}
+	mistake := &Mistake{MoveNumber: 20, Color: "B", PlayedMove: "J9", BestMove: "D3", WinrateDrop: 0.3, PolicyPlayed: 0.1}
+	f, ok := featuresOf(board, mistake, before, after)
+	if !ok {
+		t.Fatal("Expected features")
+	}
+	want := mistakeFeatures{moveNumber: 20, winrateDrop: 0.3, region: "corner", phase: phaseMiddlegame, distance: 6, groupSwung: true}
+	if f != want {
+		t.Errorf("Expected %+v, got %+v", want, f)
+	}
+
+	// A white stone played in Black's sphere ends up Black's
+	before, after = make([]float64, 81), make([]float64, 81)
+	before[at("C5")], after[at("C5")] = 0.4, 0.9
+	mistake = &Mistake{MoveNumber: 20, Color: "W", PlayedMove: "C5", BestMove: "D6", PolicyPlayed: 0.1}
+	if f, _ := featuresOf(board, mistake, before, after); !f.stoneDied || f.groupSwung || f.region != "side" {
+		t.Errorf("Expected a dead stone on the side, got %+v", f)
+	}
+	// Only leaning Black's way, it died only if the network would rarely
+	// play it
+	after[at("C5")] = 0.3
+	if f, _ := featuresOf(board, mistake, before, after); f.stoneDied {
+		t.Errorf("Did not expect a usual move to count as dead, got %+v", f)
+	}
+	mistake.PolicyPlayed = 0.01
+	if f, _ := featuresOf(board, mistake, before, after); !f.stoneDied {
+		t.Errorf("Expected an unusual move to count as dead, got %+v", f)
+	}
+
+	// Early in the game, and with most of the board settled
+	mistake = &Mistake{MoveNumber: 5, Color: "B", PlayedMove: "E5", BestMove: "E6"}
+	if f, _ := featuresOf(board, mistake, make([]float64, 81), make([]float64, 81)); f.phase != phaseOpening {
+		t.Errorf("Expected the opening, got %+v", f)
+	}
+	settled := make([]float64, 81)
+	for i := range settled {
+		settled[i] = 0.9
+	}
+	mistake.MoveNumber = 60
+	if f, _ := featuresOf(board, mistake, settled, settled); f.phase != phaseEndgame || f.groupSwung {
+		t.Errorf("Expected the endgame, got %+v", f)
+	}
+
+	if _, ok := featuresOf(board, mistake, nil, settled); ok {
+		t.Error("Expected no features without the ownership before the move")
+	}
+}
+
+func TestClusterMistakes(t *testing.T) {
+	features := []mistakeFeatures{
+		{moveNumber: 90, winrateDrop: 0.1, region: "side", phase: phaseEndgame},
+		{moveNumber: 70, winrateDrop: 0.15, region: "side", phase: phaseEndgame},
+		{moveNumber: 40, winrateDrop: 0.4, region: "corner", phase: phaseMiddlegame, groupSwung: true},
+		{moveNumber: 8, winrateDrop: 0.05, region: "corner", phase: phaseOpening},
+		{moveNumber: 50, winrateDrop: 0.08, region: "center", phase: phaseMiddlegame, stoneDied: true},
+		{moveNumber: 55, winrateDrop: 0.5, phase: phaseMiddlegame, distance: -1},
+	}
+	themes := clusterMistakes(i18n.English, features)
+
+	var names []string
+	for _, theme := range themes {
+		names = append(names, theme.Theme)
+	}
+	if want := []string{ThemeLifeAndDeath, ThemeEndgame, ThemeOverplay}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Expected themes %v, got %v", want, names)
+	}
+	endgame := themes[1]
+	if endgame.Mistakes != 2 || !reflect.DeepEqual(endgame.Moves, []int{70, 90}) || endgame.WinrateLost != 0.25 ||
+		endgame.Region != "side" || endgame.Phase != phaseEndgame || endgame.Advice == "" {
+		t.Errorf("Unexpected endgame theme: %+v", endgame)
+	}
+	if got := endgame.MoveList(); got != "70, 90" {
+		t.Errorf("MoveList() = %q", got)
+	}
+
+	if themes := clusterMistakes(i18n.English, nil); len(themes) != 0 {
+		t.Errorf("Expected no themes, got %+v", themes)
+	}
+}
+
+func TestMistakeThemes(t *testing.T) {
+	game := &Position{BoardXSize: 9, BoardYSize: 9, Moves: []Move{
+		{Color: "B", Location: "C3"}, {Color: "W", Location: "G7"}, {Color: "B", Location: "C4"},
+		{Color: "W", Location: "C5"}, {Color: "B", Location: "J9"}, {Color: "W", Location: "E5"},
+	}}
+	_, at := themeBoard(t)
+
+	// The black group dies with move 5; the position after move 6 cannot
+	// be analyzed
+	var queried []int
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		if !req.IncludeOwnership || req.MaxVisits == nil || *req.MaxVisits != 50 {
+			t.Errorf("Unexpected request: %+v", req)
+		}
+		moves := len(req.Position.Moves)
+		queried = append(queried, moves)
+		if moves == 6 {
+			return nil, context.DeadlineExceeded
+		}
+		ownership := make([]float64, 81)
+		for _, coord := range []string{"C3", "C4"} {
+			ownership[at(coord)] = 0.8
+			if moves >= 5 {
+				ownership[at(coord)] = -0.8
+			}
+		}
+		return &AnalysisResult{Ownership: ownership}, nil
+	}
+	review := &GameReview{Mistakes: []Mistake{
+		{MoveNumber: 5, Color: "B", PlayedMove: "J9", BestMove: "D3", WinrateDrop: 0.3, PolicyPlayed: 0.1},
+		{MoveNumber: 6, Color: "W", PlayedMove: "E5", BestMove: "E6", WinrateDrop: 0.5},
+	}}
+
+	themes := mistakeThemes(context.Background(), analyze, i18n.English, game, review, 50)
+	if len(themes) != 1 || themes[0].Theme != ThemeLifeAndDeath || !reflect.DeepEqual(themes[0].Moves, []int{5}) {
+		t.Fatalf("Expected a life and death theme for move 5, got %+v", themes)
+	}
+	if review.Mistakes[0].Theme != ThemeLifeAndDeath || review.Mistakes[1].Theme != "" {
+		t.Errorf("Unexpected mistake themes: %+v", review.Mistakes)
+	}
+	// The costliest mistake is analyzed first
+	if !reflect.DeepEqual(queried, []int{5, 6, 4, 5}) {
+		t.Errorf("Expected the positions around moves 6 and 5, got %v", queried)
+	}
+}
@@ -0,0 +1,121 @@
+package katago
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+// maxReplies is the number of opponent replies reported for an explained
+// move.
+const maxReplies = 3
+
+// Reply is one of the opponent's strongest answers to a move.
+type Reply struct {
+	Move      string   `json:"move"`
+	Winrate   float64  `json:"winrate"`
+	ScoreLead float64  `json:"scoreLead"`
+	Visits    int      `json:"visits"`
+	Threats   []string `json:"threats,omitempty"` // What the reply threatens, e.g. a cut
+}
+
+// analyzeReplies analyzes the position after color plays move and
+// describes the opponent's strongest replies and what they threaten.
+func (e *Engine) analyzeReplies(ctx context.Context, p *i18n.Printer, position *Position, color, move string) ([]Reply, error) {
+	after := positionAfter(position, color, move)
+	board, err := BoardFromPosition(after)
+	if err != nil {
+		return nil, err
+	}
+	result, err := e.Analyze(ctx, &AnalysisRequest{
+		Position:              after,
+		IncludeOwnership:      true,
+		IncludeMovesOwnership: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	replier := opponent(color)
+	var replies []Reply
+	for _, mi := range result.MoveInfos {
+		if len(replies) == maxReplies {
+			break
+		}
+		replies = append(replies, Reply{
+			Move:      mi.Move,
+			Winrate:   mi.Winrate,
+			ScoreLead: mi.ScoreLead,
+			Visits:    mi.Visits,
+			Threats:   describeThreats(p, board, replier, mi.Move, result.Ownership, mi.Ownership),
+		})
+	}
+	return replies, nil
+}
+
+// describeThreats says what a move by color threatens on board: cutting,
+// capturing or putting opponent groups in atari, plus what its ownership
+// impact shows when before and after are known.
+func describeThreats(p *i18n.Printer, board *Board, color, move string, before, after []float64) []string {
+	x, y, err := regionCorner(strings.ToUpper(move), board.xSize, board.ySize)
+	if err != nil {
+		return nil
+	}
+	point := y*board.xSize + x
+	enemy := strings.ToLower(opponent(color))
+
+	// Opponent groups next to the move, by their top-left stone
+	groups := make(map[int]int) // top-left stone -> liberties
+	for _, n := range board.neighbors(point) {
+		if board.points[n] != enemy {
+			continue
+		}
+		stones, liberties := board.group(n)
+		groups[topLeft(stones)] = liberties
+	}
+	var names []string
+	var captured, atari []string
+	for first, liberties := range groups {
+		stones, _ := board.group(first)
+		name := board.groupName(first, len(stones))
+		names = append(names, name)
+		switch liberties {
+		case 1:
+			captured = append(captured, name)
+		case 2:
+			atari = append(atari, name)
+		}
+	}
+	sort.Strings(names)
+	sort.Strings(captured)
+	sort.Strings(atari)
+
+	var threats []string
+	if len(names) >= 2 {
+		threats = append(threats, p.Sprintf("Cuts %s from %s", names[0], strings.Join(names[1:], ", ")))
+	}
+	if len(captured) > 0 {
+		threats = append(threats, p.Sprintf("Captures %s", strings.Join(captured, ", ")))
+	}
+	if len(atari) > 0 && board.IsLegal(color, move) {
+		threats = append(threats, p.Sprintf("Puts %s in atari", strings.Join(atari, ", ")))
+	}
+	if impact := analyzeOwnershipImpact(board, color, move, before, after); impact != nil {
+		pros, _ := ownershipProsAndCons(p, impact)
+		threats = append(threats, pros...)
+	}
+	return threats
+}
+
+// topLeft returns the first of a group's points in board order.
+func topLeft(stones []int) int {
+	first := stones[0]
+	for _, s := range stones {
+		if s < first {
+			first = s
+		}
+	}
+	return first
+}
@@ -0,0 +1,50 @@
+package katago
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+func TestDescribeThreats(t *testing.T) {
+	tests := []struct {
+		name   string
+		stones [][2]string // color, location
+		move   string
+		want   []string
+	}{
+		{"cut", [][2]string{{"b", "D4"}, {"b", "E5"}}, "D5", []string{"Cuts D4 from E5"}},
+		{"atari", [][2]string{{"b", "E5"}, {"w", "E6"}, {"w", "D5"}}, "F5", []string{"Puts E5 in atari"}},
+		{"capture", [][2]string{{"b", "A1"}, {"w", "B1"}}, "A2", []string{"Captures A1"}},
+		{"quiet move", [][2]string{{"b", "E5"}}, "C3", nil},
+		{"pass", [][2]string{{"b", "E5"}}, "pass", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := NewBoard(9, 9)
+			for _, stone := range tt.stones {
+				if err := board.Play(stone[0], stone[1]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := describeThreats(i18n.English, board, "W", tt.move, nil, nil); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("describeThreats(%s) = %q, want %q", tt.move, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeThreatsOwnership(t *testing.T) {
+	board := NewBoard(9, 9)
+	if err := board.Play("b", "C3"); err != nil {
+		t.Fatal(err)
+	}
+	before := ownershipMap(t, map[string]float64{"C3": 0.9})
+	after := ownershipMap(t, map[string]float64{"C3": 0.4, "D3": -0.9})
+
+	got := describeThreats(i18n.English, board, "W", "D3", before, after)
+	if want := []string{"Attacks C3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,197 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+const (
+	defaultTimelineInterval  = 10
+	defaultTimelineVisits    = 100
+	defaultTimelineThreshold = 0.85
+	// defaultTimelineSwing is the change in territory balance, in points,
+	// between two analyzed positions that counts as a swing.
+	defaultTimelineSwing = 10
+	// maxTimelinePoints bounds the number of queries one timeline may send;
+	// longer games are sampled at a wider interval.
+	maxTimelinePoints = 50
+)
+
+// TimelineOptions controls a territory timeline.
+type TimelineOptions struct {
+	Interval  int     // Moves between analyzed positions (default: 10)
+	MaxVisits int     // Visits per position (default: 100)
+	Threshold float64 // Ownership above which a point is territory (default: 0.85)
+	Swing     int     // Balance change reported as a swing (default: 10)
+}
+
+// TimelinePoint is the territory of each player after a move.
+type TimelinePoint struct {
+	MoveNumber     int     `json:"moveNumber"`
+	BlackTerritory int     `json:"blackTerritory"`
+	WhiteTerritory int     `json:"whiteTerritory"`
+	DamePoints     int     `json:"damePoints"`
+	Balance        int     `json:"balance"`   // Black's territory minus White's
+	ScoreLead      float64 `json:"scoreLead"` // As KataGo reports it
+}
+
+// TerritorySwing is a range of moves over which the territory balance
+// moved sharply.
+type TerritorySwing struct {
+	FromMove    int    `json:"fromMove"`
+	ToMove      int    `json:"toMove"`
+	BlackChange int    `json:"blackChange"`
+	WhiteChange int    `json:"whiteChange"`
+	Swing       int    `json:"swing"`  // Change in balance, positive for Black
+	Favors      string `json:"favors"` // "B" or "W"
+}
+
+// TerritoryTimeline tracks each player's territory through a game.
+type TerritoryTimeline struct {
+	Interval int              `json:"interval"` // Moves between analyzed positions
+	Points   []TimelinePoint  `json:"points"`   // Ordered by move number
+	Swings   []TerritorySwing `json:"swings"`
+	// Partial is set when some positions could not be analyzed.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// TerritoryTimeline analyzes the ownership of a game's positions every
+// few moves in parallel, and reports each player's territory along with
+// the move ranges where it changed hands most.
+func (e *Engine) TerritoryTimeline(ctx context.Context, sgf string, opts *TimelineOptions) (*TerritoryTimeline, error) {
+	return territoryTimeline(ctx, e.Analyze, sgf, opts, func(moveNumber int, err error) {
+		e.logger.Error("Failed to analyze position for territory timeline", "move", moveNumber, "error", err)
+	})
+}
+
+// territoryTimeline builds a timeline with the given analysis function.
+// onError is called for each position that could not be analyzed.
+func territoryTimeline(ctx context.Context, analyze func(context.Context, *AnalysisRequest) (*AnalysisResult, error),
+	sgf string, opts *TimelineOptions, onError func(int, error)) (*TerritoryTimeline, error) {
+	game, err := NewSGFParser(sgf).Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+	if len(game.Moves) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "the game has no moves")
+	}
+	o := timelineDefaults(opts)
+	if o.Threshold <= 0 || o.Threshold > 1 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "threshold must be between 0 and 1")
+	}
+	interval := max(o.Interval, (len(game.Moves)+maxTimelinePoints-1)/maxTimelinePoints)
+	moveNumbers := timelineMoves(len(game.Moves), interval)
+
+	points := make([]*TimelinePoint, len(moveNumbers))
+	var wg sync.WaitGroup
+	for i, moveNumber := range moveNumbers {
+		wg.Add(1)
+		go func(i, moveNumber int) {
+			defer wg.Done()
+			position := *game
+			position.Moves = game.Moves[:moveNumber]
+			visits := o.MaxVisits
+			result, err := analyze(ctx, &AnalysisRequest{Position: &position, MaxVisits: &visits, IncludeOwnership: true})
+			if err == nil && len(result.Ownership) < game.BoardXSize*game.BoardYSize {
+				err = fmt.Errorf("no ownership data returned")
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					onError(moveNumber, err)
+				}
+				return
+			}
+			point := &TimelinePoint{MoveNumber: moveNumber, ScoreLead: roundTenth(result.RootInfo.ScoreLead)}
+			for _, ownership := range result.Ownership[:game.BoardXSize*game.BoardYSize] {
+				switch territoryOwner(ownership, o.Threshold) {
+				case "B":
+					point.BlackTerritory++
+				case "W":
+					point.WhiteTerritory++
+				default:
+					point.DamePoints++
+				}
+			}
+			point.Balance = point.BlackTerritory - point.WhiteTerritory
+			points[i] = point
+		}(i, moveNumber)
+	}
+	wg.Wait()
+
+	timeline := &TerritoryTimeline{Interval: interval}
+	for _, point := range points {
+		if point == nil {
+			timeline.Partial = true
+			continue
+		}
+		timeline.Points = append(timeline.Points, *point)
+	}
+	if len(timeline.Points) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, apperrors.Wrap(apperrors.CodeOf(err), err, "territory timeline stopped before any position was analyzed")
+		}
+		return nil, fmt.Errorf("no position could be analyzed")
+	}
+	timeline.Swings = territorySwings(timeline.Points, o.Swing)
+	return timeline, nil
+}
+
+// timelineDefaults fills unset options.
+func timelineDefaults(opts *TimelineOptions) TimelineOptions {
+	var o TimelineOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultTimelineInterval
+	}
+	if o.MaxVisits <= 0 {
+		o.MaxVisits = defaultTimelineVisits
+	}
+	if o.Threshold == 0 {
+		o.Threshold = defaultTimelineThreshold
+	}
+	if o.Swing <= 0 {
+		o.Swing = defaultTimelineSwing
+	}
+	return o
+}
+
+// timelineMoves lists the move numbers analyzed: every interval moves and
+// the final position.
+func timelineMoves(moves, interval int) []int {
+	var numbers []int
+	for n := interval; n < moves; n += interval {
+		numbers = append(numbers, n)
+	}
+	return append(numbers, moves)
+}
+
+// territorySwings finds consecutive points between which the territory
+// balance changed by at least minSwing points.
+func territorySwings(points []TimelinePoint, minSwing int) []TerritorySwing {
+	swings := []TerritorySwing{}
+	for i := 1; i < len(points); i++ {
+		prev, cur := points[i-1], points[i]
+		swing := cur.Balance - prev.Balance
+		if swing > -minSwing && swing < minSwing {
+			continue
+		}
+		favors := "B"
+		if swing < 0 {
+			favors = "W"
+		}
+		swings = append(swings, TerritorySwing{
+			FromMove:    prev.MoveNumber,
+			ToMove:      cur.MoveNumber,
+			BlackChange: cur.BlackTerritory - prev.BlackTerritory,
+			WhiteChange: cur.WhiteTerritory - prev.WhiteTerritory,
+			Swing:       swing,
+			Favors:      favors,
+		})
+	}
+	return swings
+}
@@ -0,0 +1,97 @@
+package katago
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testGame returns a 9x9 game of n moves filling the board column by
+// column.
+func testGame(n int) string {
+	var sb strings.Builder
+	sb.WriteString("(;GM[1]FF[4]SZ[9]KM[7]")
+	for i := 0; i < n; i++ {
+		color := "B"
+		if i%2 == 1 {
+			color = "W"
+		}
+		sb.WriteString(fmt.Sprintf(";%s[%c%c]", color, 'a'+i/9, 'a'+i%9))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+func TestTerritoryTimeline(t *testing.T) {
+	// Black owns a point per move; White owns 5 points, and 30 more from
+	// move 30 on. The position after move 40 cannot be analyzed.
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		moves := len(req.Position.Moves)
+		if moves == 40 {
+			return nil, errors.New("engine failure")
+		}
+		white := 5
+		if moves >= 30 {
+			white += 30
+		}
+		ownership := make([]float64, 81)
+		for i := 0; i < moves; i++ {
+			ownership[i] = 0.9
+		}
+		for i := 0; i < white; i++ {
+			ownership[80-i] = -0.9
+		}
+		return &AnalysisResult{RootInfo: RootInfo{ScoreLead: float64(moves - white)}, Ownership: ownership}, nil
+	}
+
+	var failed []int
+	timeline, err := territoryTimeline(context.Background(), analyze, testGame(45), &TimelineOptions{Swing: 15},
+		func(moveNumber int, err error) { failed = append(failed, moveNumber) })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !timeline.Partial || !reflect.DeepEqual(failed, []int{40}) {
+		t.Errorf("Expected a partial timeline missing move 40, got %+v (failed %v)", timeline, failed)
+	}
+	var moves []int
+	for _, p := range timeline.Points {
+		moves = append(moves, p.MoveNumber)
+	}
+	if !reflect.DeepEqual(moves, []int{10, 20, 30, 45}) {
+		t.Errorf("Expected points at moves 10, 20, 30 and 45, got %v", moves)
+	}
+	if p := timeline.Points[0]; p.BlackTerritory != 10 || p.WhiteTerritory != 5 || p.DamePoints != 66 || p.Balance != 5 {
+		t.Errorf("Unexpected counts at move 10: %+v", p)
+	}
+
+	// Move 20 to 30 gains Black 10 points but White 30
+	want := []TerritorySwing{
+		{FromMove: 20, ToMove: 30, BlackChange: 10, WhiteChange: 30, Swing: -20, Favors: "W"},
+		{FromMove: 30, ToMove: 45, BlackChange: 15, WhiteChange: 0, Swing: 15, Favors: "B"},
+	}
+	if !reflect.DeepEqual(timeline.Swings, want) {
+		t.Errorf("Expected swings %+v, got %+v", want, timeline.Swings)
+	}
+
+	if _, err := territoryTimeline(context.Background(), analyze, testGame(0), nil, nil); err == nil {
+		t.Error("Expected an error for a game without moves")
+	}
+	if _, err := territoryTimeline(context.Background(), analyze, testGame(5), &TimelineOptions{Threshold: 1.5}, nil); err == nil {
+		t.Error("Expected an error for a threshold above 1")
+	}
+}
+
+func TestTimelineMoves(t *testing.T) {
+	if got := timelineMoves(25, 10); !reflect.DeepEqual(got, []int{10, 20, 25}) {
+		t.Errorf("Expected moves 10, 20 and 25, got %v", got)
+	}
+	if got := timelineMoves(20, 10); !reflect.DeepEqual(got, []int{10, 20}) {
+		t.Errorf("Expected moves 10 and 20, got %v", got)
+	}
+	if got := timelineMoves(4, 10); !reflect.DeepEqual(got, []int{4}) {
+		t.Errorf("Expected the final position only, got %v", got)
+	}
+}
@@ -0,0 +1,154 @@
+package katago
+
+import (
+	"math"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+// shortOfTime is the time left, in seconds, under which a player is short
+// of time.
+const shortOfTime = 30
+
+// timePressureRanges are the ranges of time left a time pressure report
+// counts moves in, by their upper bound in seconds.
+var timePressureRanges = []struct {
+	name string
+	max  float64
+}{
+	{"under 30s", shortOfTime},
+	{"30s to 2m", 120},
+	{"2m to 10m", 600},
+	{"over 10m", math.Inf(1)},
+}
+
+// minShortOfTimeMoves is the number of moves a player must have made short
+// of time for their error rate there to be compared.
+const minShortOfTimeMoves = 5
+
+// TimeRange counts the judged moves made with a range of time left, and the
+// mistakes and blunders among them.
+type TimeRange struct {
+	Range         string `json:"range"` // e.g. "under 30s"
+	BlackMoves    int    `json:"blackMoves"`
+	WhiteMoves    int    `json:"whiteMoves"`
+	BlackMistakes int    `json:"blackMistakes"` // Not counting blunders
+	WhiteMistakes int    `json:"whiteMistakes"`
+	BlackBlunders int    `json:"blackBlunders"`
+	WhiteBlunders int    `json:"whiteBlunders"`
+}
+
+// TimePressure correlates a review's mistakes with the time the players had
+// left, as the SGF records it after each move.
+type TimePressure struct {
+	MainTime   float64     `json:"mainTime,omitempty"` // Seconds, from TM
+	Overtime   string      `json:"overtime,omitempty"` // From OT
+	TimedMoves int         `json:"timedMoves"`         // Judged moves with a recorded clock
+	Ranges     []TimeRange `json:"ranges"`
+	// Observations sum up how each player's errors followed the clock,
+	// e.g. that most of their blunders came with under 30 seconds left
+	Observations []string `json:"observations,omitempty"`
+}
+
+// timePressure correlates a review's mistakes with the clock recorded in a
+// game, counting the judged moves, and sets the time left on each mistake.
+// It returns nil when no judged move has a recorded clock.
+func timePressure(p *i18n.Printer, game *Position, review *GameReview, judged []int) *TimePressure {
+	for i := range review.Mistakes {
+		if left, ok := game.Clock[review.Mistakes[i].MoveNumber]; ok {
+			review.Mistakes[i].TimeLeft = &left
+		}
+	}
+
+	report := &TimePressure{MainTime: game.MainTime, Overtime: game.Overtime}
+	for _, r := range timePressureRanges {
+		report.Ranges = append(report.Ranges, TimeRange{Range: r.name})
+	}
+	rangeOf := func(left float64) *TimeRange {
+		for i, r := range timePressureRanges {
+			if left < r.max {
+				return &report.Ranges[i]
+			}
+		}
+		return &report.Ranges[len(report.Ranges)-1]
+	}
+	for _, n := range judged {
+		left, ok := game.Clock[n]
+		if !ok {
+			continue
+		}
+		report.TimedMoves++
+		if strings.EqualFold(game.Moves[n-1].Color, "b") {
+			rangeOf(left).BlackMoves++
+		} else {
+			rangeOf(left).WhiteMoves++
+		}
+	}
+	if report.TimedMoves == 0 {
+		return nil
+	}
+	for _, mistake := range review.Mistakes {
+		if mistake.TimeLeft == nil {
+			continue
+		}
+		r := rangeOf(*mistake.TimeLeft)
+		switch {
+		case mistake.Color == "B" && mistake.Category == "blunder":
+			r.BlackBlunders++
+		case mistake.Color == "B":
+			r.BlackMistakes++
+		case mistake.Category == "blunder":
+			r.WhiteBlunders++
+		default:
+			r.WhiteMistakes++
+		}
+	}
+
+	for _, color := range []string{"B", "W"} {
+		report.Observations = append(report.Observations, timeObservations(p, game, report.Ranges, color)...)
+	}
+	return report
+}
+
+// timeObservations sums up how a player's errors followed the clock: when
+// most of their blunders came short of time, and when they erred much
+// more often short of time than with more time.
+func timeObservations(p *i18n.Printer, game *Position, ranges []TimeRange, color string) []string {
+	name := game.Player(color)
+	if name == "" {
+		name = p.T("Black")
+		if color == "W" {
+			name = p.T("White")
+		}
+	}
+	var short, long struct{ moves, errors, blunders int }
+	for i, r := range ranges {
+		moves, mistakes, blunders := r.BlackMoves, r.BlackMistakes, r.BlackBlunders
+		if color == "W" {
+			moves, mistakes, blunders = r.WhiteMoves, r.WhiteMistakes, r.WhiteBlunders
+		}
+		counts := &long
+		if timePressureRanges[i].max <= shortOfTime {
+			counts = &short
+		}
+		counts.moves += moves
+		counts.errors += mistakes + blunders
+		counts.blunders += blunders
+	}
+
+	var observations []string
+	if blunders := short.blunders + long.blunders; blunders >= 2 && short.blunders*2 > blunders {
+		observations = append(observations, p.Sprintf("Most blunders by %s (%d of %d) came with under 30 seconds left",
+			name, short.blunders, blunders))
+	}
+	if short.moves >= minShortOfTimeMoves && long.moves > 0 && short.errors > 0 {
+		shortRate := float64(short.errors) / float64(short.moves) * 100
+		longRate := float64(long.errors) / float64(long.moves) * 100
+		if shortRate >= 2*longRate {
+			observations = append(observations, p.Sprintf("%s erred on %.0f%% of moves with under 30 seconds left, against %.0f%% with more time",
+				name, shortRate, longRate))
+		}
+	}
+	return observations
+}
@@ -0,0 +1,65 @@
+package katago
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
+)
+
+func TestTimePressure(t *testing.T) {
+	game := &Position{MainTime: 300, Overtime: "3x30 byo-yomi", Clock: map[int]float64{}}
+	game.PlayerBlack = "Lee"
+	var judged []int
+	for n := 1; n <= 20; n++ {
+		color := "b"
+		if n%2 == 0 {
+			color = "w"
+		}
+		game.Moves = append(game.Moves, Move{Color: color, Location: "D4"})
+		judged = append(judged, n)
+		// Moves 1-10 with minutes left, then 11-20 in byo-yomi
+		game.Clock[n] = 300 - float64(n)*25
+		if n > 10 {
+			game.Clock[n] = 20
+		}
+	}
+	delete(game.Clock, 2)
+	review := &GameReview{Mistakes: []Mistake{
+		{MoveNumber: 3, Color: "B", Category: "blunder"},
+		{MoveNumber: 13, Color: "B", Category: "blunder"},
+		{MoveNumber: 15, Color: "B", Category: "blunder"},
+		{MoveNumber: 17, Color: "B", Category: "mistake"},
+		{MoveNumber: 16, Color: "W", Category: "mistake"},
+	}}
+
+	report := timePressure(i18n.English, game, review, judged)
+	if report == nil || report.TimedMoves != 19 || report.MainTime != 300 {
+		t.Fatalf("Expected 19 timed moves, got %+v", report)
+	}
+	short, long := report.Ranges[0], report.Ranges[3]
+	if short.Range != "under 30s" || short.BlackMoves != 5 || short.BlackBlunders != 2 || short.BlackMistakes != 1 ||
+		short.WhiteMoves != 5 || short.WhiteMistakes != 1 {
+		t.Errorf("Unexpected moves under 30 seconds: %+v", short)
+	}
+	if report.Ranges[2].BlackBlunders != 1 || long.BlackMoves != 0 {
+		t.Errorf("Expected move 3's blunder with 2m to 10m left, got %+v", report.Ranges)
+	}
+	if *review.Mistakes[0].TimeLeft != 225 || *review.Mistakes[4].TimeLeft != 20 {
+		t.Errorf("Expected the time left on each mistake, got %+v", review.Mistakes)
+	}
+
+	want := []string{
+		"Most blunders by Lee (2 of 3) came with under 30 seconds left",
+		"Lee erred on 60% of moves with under 30 seconds left, against 20% with more time",
+		"White erred on 20% of moves with under 30 seconds left, against 0% with more time",
+	}
+	if got := strings.Join(report.Observations, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("Expected observations %q, got %q", want, report.Observations)
+	}
+
+	game.Clock = nil
+	if report := timePressure(i18n.English, game, review, judged); report != nil {
+		t.Errorf("Expected no report without a clock, got %+v", report)
+	}
+}
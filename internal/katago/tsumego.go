@@ -0,0 +1,257 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// Life-and-death results for a tsumego's target group.
+const (
+	StatusLives     = "lives"
+	StatusDies      = "dies"
+	StatusKo        = "ko"
+	StatusUnsettled = "unsettled"
+)
+
+const (
+	defaultTsumegoVisits     = 400
+	defaultTsumegoIterations = 4
+	// maxTsumegoIterations bounds the doubling of visits, so the last
+	// search has at most 32 times the starting visits.
+	maxTsumegoIterations = 6
+	// tsumegoDepth is how many moves of the search stay inside the region.
+	tsumegoDepth = 60
+	// tsumegoSettled is the average ownership of the target group,
+	// from its owner's point of view, beyond which it lives or dies.
+	tsumegoSettled = 0.5
+	// maxWrongAnswers is the number of refuted moves reported.
+	maxWrongAnswers = 3
+	// minAnswerVisits is the smallest share of the root's visits a
+	// candidate needs before its result is trusted.
+	minAnswerVisits = 0.02
+)
+
+// TsumegoOptions describes a local problem and how hard to search it.
+type TsumegoOptions struct {
+	Region        []string // Moves or regions to play in, as for allowMoves
+	Target        string   // A stone of the group whose fate is in question
+	MaxVisits     int      // Visits for the first search (default: 400)
+	MaxIterations int      // Searches, doubling visits, before giving up on a stable result (default: 4)
+}
+
+// TsumegoAnswer is a candidate move and the fate of the target group after it.
+type TsumegoAnswer struct {
+	Move      string  `json:"move"`
+	Status    string  `json:"status"`
+	Ownership float64 `json:"ownership"` // Target group's average ownership from its owner's view
+	Visits    int     `json:"visits"`
+	// Line is the move followed by KataGo's expected continuation; for a
+	// wrong answer it is the refutation.
+	Line []string `json:"line,omitempty"`
+}
+
+// TsumegoSolution is the solution of a local life-and-death problem.
+type TsumegoSolution struct {
+	Target      string `json:"target"`
+	TargetColor string `json:"targetColor"` // "B" or "W"
+	ToPlay      string `json:"toPlay"`      // "B" or "W"
+	// Status is the target group's fate with best play by both sides.
+	Status    string   `json:"status"`
+	Ownership float64  `json:"ownership"`
+	BestMove  string   `json:"bestMove"`
+	MainLine  []string `json:"mainLine,omitempty"`
+	// CorrectMoves are other moves reaching the same result.
+	CorrectMoves []TsumegoAnswer `json:"correctMoves,omitempty"`
+	// WrongMoves are moves that lead to a worse result for the player to
+	// move, with the line that refutes them.
+	WrongMoves []TsumegoAnswer `json:"wrongMoves,omitempty"`
+	Visits     int             `json:"visits"`     // Visits of the last search
+	Iterations int             `json:"iterations"` // Searches run
+	// Stable is set when the last two searches agreed on the best move
+	// and the status.
+	Stable bool `json:"stable"`
+}
+
+// SolveTsumego solves a local life-and-death problem. Both players are
+// confined to the region, and the search is repeated with twice the visits
+// until the best move and the target group's fate stop changing.
+func (e *Engine) SolveTsumego(ctx context.Context, position *Position, opts *TsumegoOptions) (*TsumegoSolution, error) {
+	return solveTsumego(ctx, e.Analyze, position, opts)
+}
+
+// solveTsumego solves a problem with the given analysis function.
+func solveTsumego(ctx context.Context, analyze func(context.Context, *AnalysisRequest) (*AnalysisResult, error),
+	position *Position, opts *TsumegoOptions) (*TsumegoSolution, error) {
+	if err := ValidatePosition(position); err != nil {
+		return nil, fmt.Errorf("invalid position: %w", err)
+	}
+	if opts == nil || len(opts.Region) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "a region is required")
+	}
+	board, err := BoardFromPosition(position)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "invalid position")
+	}
+	target := strings.ToUpper(strings.TrimSpace(opts.Target))
+	tx, ty, err := regionCorner(target, board.xSize, board.ySize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target: %w", err)
+	}
+	targetColor := board.points[ty*board.xSize+tx]
+	if targetColor == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "target %s is not a stone", target)
+	}
+	stones, _ := board.group(ty*board.xSize + tx)
+
+	avoid, err := ResolveMoveRestrictions(nil, opts.Region, board.xSize, board.ySize)
+	if err != nil {
+		return nil, err
+	}
+	visits, iterations := defaultTsumegoVisits, defaultTsumegoIterations
+	if opts.MaxVisits > 0 {
+		visits = opts.MaxVisits
+	}
+	if opts.MaxIterations > 0 {
+		iterations = opts.MaxIterations
+	}
+	if iterations > maxTsumegoIterations {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "at most %d iterations are allowed", maxTsumegoIterations)
+	}
+
+	solution := &TsumegoSolution{
+		Target:      target,
+		TargetColor: strings.ToUpper(targetColor),
+		ToPlay:      nextPlayer(position),
+	}
+	var result *AnalysisResult
+	for i := 0; i < iterations; i++ {
+		searchVisits := visits << i
+		search, err := analyze(ctx, &AnalysisRequest{
+			Position:              position,
+			MaxVisits:             &searchVisits,
+			IncludeOwnership:      true,
+			IncludeMovesOwnership: true,
+			AvoidMoves:            avoid,
+			RestrictionDepth:      tsumegoDepth,
+		})
+		if err != nil {
+			if solution.Iterations > 0 && ctx.Err() != nil {
+				// Report the last completed search
+				break
+			}
+			return nil, fmt.Errorf("failed to analyze problem: %w", err)
+		}
+		if len(search.MoveInfos) == 0 {
+			return nil, fmt.Errorf("analysis returned no moves")
+		}
+		result = search
+		best := result.MoveInfos[0]
+		ownership := groupOwnership(result.Ownership, stones, targetColor)
+		status := tsumegoStatus(board, solution.ToPlay, best.PV, ownership)
+		solution.Stable = solution.Iterations > 0 && best.Move == solution.BestMove && status == solution.Status
+		solution.Iterations++
+		solution.Visits = result.RootInfo.Visits
+		solution.BestMove = best.Move
+		solution.MainLine = best.PV
+		solution.Status = status
+		solution.Ownership = roundHundredth(ownership)
+		if solution.Stable {
+			break
+		}
+	}
+
+	// Sort the other candidates into correct and wrong answers
+	rank := statusRank(solution.Status, solution.ToPlay, solution.TargetColor)
+	minVisits := int(math.Max(10, minAnswerVisits*float64(result.RootInfo.Visits)))
+	for _, mi := range result.MoveInfos[1:] {
+		if mi.Visits < minVisits || mi.Ownership == nil {
+			continue
+		}
+		ownership := groupOwnership(mi.Ownership, stones, targetColor)
+		answer := TsumegoAnswer{
+			Move:      mi.Move,
+			Status:    tsumegoStatus(board, solution.ToPlay, mi.PV, ownership),
+			Ownership: roundHundredth(ownership),
+			Visits:    mi.Visits,
+			Line:      mi.PV,
+		}
+		switch r := statusRank(answer.Status, solution.ToPlay, solution.TargetColor); {
+		case r == rank:
+			solution.CorrectMoves = append(solution.CorrectMoves, answer)
+		case r < rank && len(solution.WrongMoves) < maxWrongAnswers:
+			solution.WrongMoves = append(solution.WrongMoves, answer)
+		}
+	}
+	return solution, nil
+}
+
+// groupOwnership averages ownership over a group's stones from the
+// point of view of its owner. Ownership is from Black's point of view.
+func groupOwnership(ownership []float64, stones []int, color string) float64 {
+	if len(stones) == 0 {
+		return 0
+	}
+	sign := 1.0
+	if color == "w" {
+		sign = -1
+	}
+	var sum float64
+	for _, s := range stones {
+		if s < len(ownership) {
+			sum += sign * ownership[s]
+		}
+	}
+	return sum / float64(len(stones))
+}
+
+// tsumegoStatus judges a group's fate from its ownership, calling an
+// unclear result a ko when the expected line contains one.
+func tsumegoStatus(board *Board, toPlay string, line []string, ownership float64) string {
+	switch {
+	case ownership >= tsumegoSettled:
+		return StatusLives
+	case ownership <= -tsumegoSettled:
+		return StatusDies
+	case lineHasKo(board, toPlay, line):
+		return StatusKo
+	}
+	return StatusUnsettled
+}
+
+// lineHasKo plays a line on a copy of board and reports whether any move
+// in it captures a single stone into a ko.
+func lineHasKo(board *Board, toPlay string, line []string) bool {
+	b := board.Clone()
+	color := toPlay
+	for _, move := range line {
+		if err := b.Play(color, move); err != nil {
+			return false
+		}
+		if b.koPoint >= 0 {
+			return true
+		}
+		color = opponent(color)
+	}
+	return false
+}
+
+// statusRank orders results by how good they are for the player to move:
+// higher is better.
+func statusRank(status, toPlay, targetColor string) int {
+	ranks := map[string]int{StatusDies: 0, StatusUnsettled: 1, StatusKo: 1, StatusLives: 2}
+	rank := ranks[status]
+	if !strings.EqualFold(toPlay, targetColor) {
+		// The player to move is attacking the group
+		rank = 2 - rank
+	}
+	return rank
+}
+
+// roundHundredth rounds to two decimal places.
+func roundHundredth(v float64) float64 {
+	return math.Round(v*100) / 100
+}
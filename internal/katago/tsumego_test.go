@@ -0,0 +1,133 @@
+package katago
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+// tsumegoPosition has a White group in the top-left corner of a 9x9
+// board, surrounded by Black, with Black to play.
+func tsumegoPosition() *Position {
+	stones := []Stone{
+		{Color: "w", Location: "A8"}, {Color: "w", Location: "B8"}, {Color: "w", Location: "C8"}, {Color: "w", Location: "C9"},
+		{Color: "b", Location: "A7"}, {Color: "b", Location: "B7"}, {Color: "b", Location: "C7"},
+		{Color: "b", Location: "D8"}, {Color: "b", Location: "D9"},
+	}
+	return &Position{Rules: "japanese", BoardXSize: 9, BoardYSize: 9, Komi: 0, InitialStones: stones}
+}
+
+// uniformOwnership returns an ownership map with every point at v.
+func uniformOwnership(v float64) []float64 {
+	ownership := make([]float64, 81)
+	for i := range ownership {
+		ownership[i] = v
+	}
+	return ownership
+}
+
+func TestSolveTsumego(t *testing.T) {
+	var requests []*AnalysisRequest
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		requests = append(requests, req)
+		visits := *req.MaxVisits
+		if len(requests) == 1 {
+			// The first search has not read the problem out
+			return &AnalysisResult{
+				RootInfo:  RootInfo{Visits: visits},
+				Ownership: uniformOwnership(0),
+				MoveInfos: []MoveInfo{{Move: "A9", Visits: visits, PV: []string{"A9"}}},
+			}, nil
+		}
+		return &AnalysisResult{
+			RootInfo:  RootInfo{Visits: visits},
+			Ownership: uniformOwnership(0.9), // White dies
+			MoveInfos: []MoveInfo{
+				{Move: "B9", Visits: visits / 2, PV: []string{"B9", "A9"}, Ownership: uniformOwnership(0.9)},
+				{Move: "A9", Visits: visits / 4, PV: []string{"A9", "B9"}, Ownership: uniformOwnership(-0.8)},
+				{Move: "pass", Visits: visits / 8, PV: []string{"pass", "B9"}, Ownership: uniformOwnership(0.7)},
+				{Move: "E1", Visits: 1, PV: []string{"E1"}, Ownership: uniformOwnership(-1)},
+			},
+		}, nil
+	}
+
+	solution, err := solveTsumego(context.Background(), analyze, tsumegoPosition(),
+		&TsumegoOptions{Region: []string{"A6-E9"}, Target: "b8", MaxVisits: 100})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(requests) != 3 || *requests[2].MaxVisits != 400 {
+		t.Errorf("Expected 3 searches ending at 400 visits, got %d", len(requests))
+	}
+	req := requests[0]
+	if req.RestrictionDepth != tsumegoDepth || len(req.AvoidMoves) != 81-20 || !req.IncludeMovesOwnership {
+		t.Errorf("Expected both players confined to the region, got %+v", req)
+	}
+	if !solution.Stable || solution.Iterations != 3 {
+		t.Errorf("Expected a stable result after 3 searches, got %+v", solution)
+	}
+	if solution.Status != StatusDies || solution.BestMove != "B9" || solution.TargetColor != "W" || solution.ToPlay != "B" {
+		t.Errorf("Expected B9 to kill the White group, got %+v", solution)
+	}
+	if solution.Ownership != -0.9 {
+		t.Errorf("Expected ownership -0.9 from White's view, got %v", solution.Ownership)
+	}
+	if len(solution.CorrectMoves) != 1 || solution.CorrectMoves[0].Move != "pass" {
+		t.Errorf("Expected pass to be also correct, got %+v", solution.CorrectMoves)
+	}
+	if len(solution.WrongMoves) != 1 || solution.WrongMoves[0].Move != "A9" || solution.WrongMoves[0].Status != StatusLives {
+		t.Errorf("Expected A9 to let White live, got %+v", solution.WrongMoves)
+	}
+
+	tests := []struct {
+		name string
+		opts *TsumegoOptions
+		code apperrors.Code
+	}{
+		{"no region", &TsumegoOptions{Target: "B8"}, apperrors.CodeInvalidArgument},
+		{"empty target", &TsumegoOptions{Region: []string{"top-left"}, Target: "E5"}, apperrors.CodeInvalidArgument},
+		{"target off the board", &TsumegoOptions{Region: []string{"top-left"}, Target: "Z1"}, apperrors.CodeBadCoordinate},
+		{"too many iterations", &TsumegoOptions{Region: []string{"top-left"}, Target: "B8", MaxIterations: 7}, apperrors.CodeInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := solveTsumego(context.Background(), analyze, tsumegoPosition(), tt.opts); apperrors.CodeOf(err) != tt.code {
+				t.Errorf("Expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestTsumegoStatus(t *testing.T) {
+	// Black can take White's E5 stone into a ko at F5
+	board, err := BoardFromPosition(&Position{BoardXSize: 9, BoardYSize: 9, InitialStones: []Stone{
+		{Color: "b", Location: "D5"}, {Color: "b", Location: "E4"}, {Color: "b", Location: "E6"},
+		{Color: "w", Location: "E5"}, {Color: "w", Location: "F4"}, {Color: "w", Location: "F6"}, {Color: "w", Location: "G5"},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tests := []struct {
+		ownership float64
+		line      []string
+		want      string
+	}{
+		{0.8, nil, StatusLives},
+		{-0.6, []string{"F5"}, StatusDies},
+		{0.1, []string{"A1", "F5"}, StatusUnsettled},
+		{0.1, []string{"F5", "A1"}, StatusKo},
+	}
+	for _, tt := range tests {
+		if got := tsumegoStatus(board, "B", tt.line, tt.ownership); got != tt.want {
+			t.Errorf("tsumegoStatus(%v, %v) = %s, want %s", tt.ownership, tt.line, got, tt.want)
+		}
+	}
+
+	if statusRank(StatusDies, "B", "W") <= statusRank(StatusKo, "B", "W") {
+		t.Error("Expected killing to rank above ko for the attacker")
+	}
+	if statusRank(StatusLives, "W", "W") <= statusRank(StatusKo, "W", "W") {
+		t.Error("Expected living to rank above ko for the defender")
+	}
+}
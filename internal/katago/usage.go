@@ -0,0 +1,114 @@
+package katago
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// usageKey is the context key for per-request resource accounting.
+type usageKey struct{}
+
+// Usage accumulates the engine resources consumed on behalf of a single
+// request. All methods are safe on a nil receiver.
+type Usage struct {
+	mu    sync.Mutex
+	stats UsageStats
+}
+
+// UsageStats is a snapshot of the resources recorded in a Usage.
+type UsageStats struct {
+	Visits        int // Visits of every result, including cached and shared ones
+	EngineVisits  int // Visits of the queries this request sent to KataGo
+	EngineQueries int
+	SharedQueries int
+	CacheHits     int
+	CacheMisses   int
+	EngineTime    time.Duration
+	QueueWait     time.Duration
+	ProcessID     int
+}
+
+// WithUsage returns a context that records engine resource usage into the
+// returned Usage.
+func WithUsage(ctx context.Context) (context.Context, *Usage) {
+	u := &Usage{}
+	return context.WithValue(ctx, usageKey{}, u), u
+}
+
+// UsageFromContext returns the Usage recorded in the context, or nil.
+func UsageFromContext(ctx context.Context) *Usage {
+	u, _ := ctx.Value(usageKey{}).(*Usage)
+	return u
+}
+
+// withoutUsage detaches a context from its request's resource accounting.
+func withoutUsage(ctx context.Context) context.Context {
+	if UsageFromContext(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, usageKey{}, (*Usage)(nil))
+}
+
+// Stats returns a snapshot of the recorded usage.
+func (u *Usage) Stats() UsageStats {
+	if u == nil {
+		return UsageStats{}
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.stats
+}
+
+// recordCacheHit records a result served from the cache.
+func (u *Usage) recordCacheHit(resp *Response) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.stats.CacheHits++
+	u.stats.Visits += resp.RootInfo.Visits
+}
+
+// recordCacheMiss records a cache lookup that required an engine query.
+func (u *Usage) recordCacheMiss() {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.stats.CacheMisses++
+}
+
+// recordQuery records a query sent to KataGo.
+func (u *Usage) recordQuery(resp *Response, queueWait, engineTime time.Duration, pid int) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.stats.EngineQueries++
+	u.stats.QueueWait += queueWait
+	u.stats.EngineTime += engineTime
+	if resp != nil {
+		u.stats.Visits += resp.RootInfo.Visits
+		u.stats.EngineVisits += resp.RootInfo.Visits
+	}
+	if pid != 0 {
+		u.stats.ProcessID = pid
+	}
+}
+
+// recordShared records a result taken from another caller's in-flight query.
+func (u *Usage) recordShared(resp *Response) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.stats.SharedQueries++
+	if resp != nil {
+		u.stats.Visits += resp.RootInfo.Visits
+	}
+}
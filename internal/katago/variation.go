@@ -0,0 +1,211 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+const (
+	defaultVariationDepth  = 6
+	maxVariationDepth      = 20
+	maxVariationBranches   = 3
+	defaultVariationVisits = 200
+	// maxVariationNodes bounds the number of queries one expansion may send.
+	maxVariationNodes = 60
+)
+
+// VariationOptions controls principal variation expansion.
+type VariationOptions struct {
+	Depth     int // Plies to explore after the candidate move (default: 6, max: 20)
+	Branches  int // Replies explored at each node (default: 1, max: 3)
+	MaxVisits int // Visits per analyzed position (default: 200)
+}
+
+// VariationNode is a move in an expanded variation with KataGo's evaluation
+// of the position after it.
+type VariationNode struct {
+	Move       string           `json:"move"`
+	Color      string           `json:"color"`
+	MoveNumber int              `json:"moveNumber"`
+	Winrate    float64          `json:"winrate"`
+	ScoreLead  float64          `json:"scoreLead"`
+	Visits     int              `json:"visits"`
+	PV         []string         `json:"pv,omitempty"` // KataGo's line from this position
+	Prior      float64          `json:"prior"`        // Policy prior of this move in its parent position
+	InOriginal bool             `json:"inOriginalPV"` // Whether the path to this node follows the original PV
+	Analyzed   bool             `json:"analyzed"`     // False when expansion stopped before querying this node
+	Children   []*VariationNode `json:"children,omitempty"`
+}
+
+// VariationTree is the result of expanding a candidate move's variation.
+type VariationTree struct {
+	Root       *VariationNode `json:"root"`
+	OriginalPV []string       `json:"originalPV"` // Single-shot PV from the starting position
+	Depth      int            `json:"depth"`
+	Nodes      int            `json:"nodes"` // Positions analyzed
+	// Partial is set when expansion stopped early because the context was
+	// done or the node budget ran out.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// ExpandVariation plays a candidate move and follows KataGo's principal
+// variation to the requested depth, re-analyzing every position along the
+// way rather than trusting the single-shot PV. With more than one branch,
+// the top replies at each node are expanded as well.
+func (e *Engine) ExpandVariation(ctx context.Context, position *Position, move string, opts *VariationOptions) (*VariationTree, error) {
+	depth, branches, visits, err := variationLimits(opts)
+	if err != nil {
+		return nil, err
+	}
+	move = strings.ToUpper(move)
+	if move == "PASS" {
+		move = "pass"
+	}
+	if !isValidMoveFormat(move, position.BoardXSize) {
+		return nil, apperrors.New(apperrors.CodeBadCoordinate, "invalid move: %s", move)
+	}
+
+	// The single-shot PV to verify
+	result, err := e.Analyze(ctx, &AnalysisRequest{Position: position, MaxVisits: &visits})
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze starting position: %w", err)
+	}
+	tree := &VariationTree{Depth: depth, Nodes: 1, OriginalPV: []string{move}}
+	root := &VariationNode{
+		Move:       move,
+		Color:      nextPlayer(position),
+		MoveNumber: len(position.Moves) + 1,
+		InOriginal: true,
+	}
+	for _, mi := range result.MoveInfos {
+		if mi.Move == move {
+			if len(mi.PV) > 0 {
+				tree.OriginalPV = mi.PV
+			}
+			root.Prior = mi.Prior
+			break
+		}
+	}
+	tree.Root = root
+
+	if err := e.expandNode(ctx, tree, position, root, depth, branches, visits); err != nil {
+		return nil, fmt.Errorf("failed to analyze candidate move: %w", err)
+	}
+	return tree, nil
+}
+
+// expandNode analyzes the position after node's move and recursively
+// expands the top replies. It returns an error only if node itself could
+// not be analyzed; failures deeper in the tree mark it partial.
+func (e *Engine) expandNode(ctx context.Context, tree *VariationTree, parent *Position, node *VariationNode, remaining, branches, visits int) error {
+	if err := ctx.Err(); err != nil {
+		tree.Partial = true
+		return err
+	}
+	if tree.Nodes >= maxVariationNodes {
+		tree.Partial = true
+		return nil
+	}
+
+	position := positionAfter(parent, node.Color, node.Move)
+	nodeVisits := visits
+	result, err := e.Analyze(ctx, &AnalysisRequest{Position: position, MaxVisits: &nodeVisits})
+	if err != nil {
+		if ctx.Err() == nil {
+			e.logger.Error("Failed to analyze variation position", "moveNumber", node.MoveNumber, "error", err)
+		}
+		tree.Partial = true
+		return err
+	}
+	tree.Nodes++
+
+	node.Analyzed = true
+	node.Winrate = result.RootInfo.Winrate
+	node.ScoreLead = result.RootInfo.ScoreLead
+	node.Visits = result.RootInfo.Visits
+	if len(result.MoveInfos) > 0 {
+		node.PV = result.MoveInfos[0].PV
+	}
+
+	if remaining <= 0 {
+		return nil
+	}
+
+	replyColor := opponent(node.Color)
+	for i := 0; i < branches && i < len(result.MoveInfos); i++ {
+		reply := result.MoveInfos[i]
+		child := &VariationNode{
+			Move:       reply.Move,
+			Color:      replyColor,
+			MoveNumber: node.MoveNumber + 1,
+			Prior:      reply.Prior,
+		}
+		ply := node.MoveNumber + 1 - tree.Root.MoveNumber
+		child.InOriginal = node.InOriginal && ply < len(tree.OriginalPV) && tree.OriginalPV[ply] == reply.Move
+		node.Children = append(node.Children, child)
+		_ = e.expandNode(ctx, tree, position, child, remaining-1, branches, visits)
+	}
+	return nil
+}
+
+// variationLimits applies defaults and bounds to the expansion options.
+func variationLimits(opts *VariationOptions) (depth, branches, visits int, err error) {
+	depth, branches, visits = defaultVariationDepth, 1, defaultVariationVisits
+	if opts == nil {
+		return depth, branches, visits, nil
+	}
+	if opts.Depth > 0 {
+		depth = opts.Depth
+	}
+	if opts.Branches > 0 {
+		branches = opts.Branches
+	}
+	if opts.MaxVisits > 0 {
+		visits = opts.MaxVisits
+	}
+	if depth > maxVariationDepth {
+		return 0, 0, 0, apperrors.New(apperrors.CodeInvalidArgument, "depth must be at most %d", maxVariationDepth)
+	}
+	if branches > maxVariationBranches {
+		return 0, 0, 0, apperrors.New(apperrors.CodeInvalidArgument, "branches must be at most %d", maxVariationBranches)
+	}
+	return depth, branches, visits, nil
+}
+
+// positionAfter returns a copy of position with a move appended.
+func positionAfter(position *Position, color, move string) *Position {
+	next := *position
+	next.Moves = make([]Move, len(position.Moves), len(position.Moves)+1)
+	copy(next.Moves, position.Moves)
+	location := move
+	if strings.EqualFold(move, "pass") {
+		location = ""
+	}
+	next.Moves = append(next.Moves, Move{Color: strings.ToLower(color), Location: location})
+	if next.InitialPlayer == "" {
+		next.InitialPlayer = next.Moves[0].Color
+	}
+	return &next
+}
+
+// nextPlayer returns the color to move in a position, "B" or "W".
+func nextPlayer(position *Position) string {
+	if n := len(position.Moves); n > 0 {
+		return opponent(strings.ToUpper(position.Moves[n-1].Color))
+	}
+	if strings.EqualFold(position.InitialPlayer, "w") {
+		return "W"
+	}
+	return "B"
+}
+
+// opponent returns the other color.
+func opponent(color string) string {
+	if strings.EqualFold(color, "B") {
+		return "W"
+	}
+	return "B"
+}
@@ -0,0 +1,113 @@
+package katago
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/cache"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// newSeededEngine returns a stopped engine whose cache answers queries for
+// the given positions, keyed by their move sequences.
+func newSeededEngine(t *testing.T, visits int, responses map[string]*Response) *Engine {
+	t.Helper()
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	cacheManager := cache.NewManager(&config.CacheConfig{Enabled: true, MaxItems: 100}, logger)
+	engine := NewEngine(&config.KataGoConfig{BinaryPath: "katago", MaxVisits: visits, MaxTime: 1.0}, logger, cacheManager)
+
+	for line, resp := range responses {
+		position := &Position{Rules: "chinese", BoardXSize: 19, BoardYSize: 19, Moves: []Move{}}
+		color := "B"
+		for _, move := range strings.Fields(line) {
+			position = positionAfter(position, color, move)
+			color = opponent(color)
+		}
+		query, err := buildAnalysisQuery(&AnalysisRequest{Position: position, MaxVisits: &visits})
+		if err != nil {
+			t.Fatalf("Failed to build query for %q: %v", line, err)
+		}
+		key, err := cacheManager.PositionKey(query)
+		if err != nil {
+			t.Fatalf("Failed to generate key: %v", err)
+		}
+		cacheManager.PutForVisits(key, resp, visits, 100)
+	}
+	return engine
+}
+
+func TestExpandVariation(t *testing.T) {
+	engine := newSeededEngine(t, 50, map[string]*Response{
+		"": {MoveInfos: []MoveInfo{
+			{Move: "Q16", Order: 0, PV: []string{"Q16"}},
+			{Move: "D4", Order: 1, Prior: 0.2, PV: []string{"D4", "Q16", "D16"}},
+		}},
+		"D4": {
+			RootInfo:  RootInfo{Visits: 50, Winrate: 0.55, ScoreLead: 2.0},
+			MoveInfos: []MoveInfo{{Move: "Q16", Prior: 0.3, PV: []string{"Q16", "C16"}}, {Move: "R4"}},
+		},
+		"D4 Q16": {
+			RootInfo:  RootInfo{Visits: 50, Winrate: 0.54, ScoreLead: 1.5},
+			MoveInfos: []MoveInfo{{Move: "C16", PV: []string{"C16"}}},
+		},
+		"D4 Q16 C16": {
+			RootInfo:  RootInfo{Visits: 50, Winrate: 0.57, ScoreLead: 2.5},
+			MoveInfos: []MoveInfo{{Move: "Q4"}},
+		},
+	})
+	start := &Position{Rules: "chinese", BoardXSize: 19, BoardYSize: 19, Moves: []Move{}}
+
+	tree, err := engine.ExpandVariation(context.Background(), start, "d4", &VariationOptions{Depth: 2, MaxVisits: 50})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tree.Partial || tree.Nodes != 4 {
+		t.Errorf("Expected complete tree of 4 positions, got partial=%v nodes=%d", tree.Partial, tree.Nodes)
+	}
+	if len(tree.OriginalPV) != 3 || tree.OriginalPV[2] != "D16" {
+		t.Errorf("Expected original PV D4 Q16 D16, got %v", tree.OriginalPV)
+	}
+
+	root := tree.Root
+	if root.Move != "D4" || root.Color != "B" || root.Winrate != 0.55 || root.Prior != 0.2 || !root.InOriginal {
+		t.Errorf("Unexpected root: %+v", root)
+	}
+	reply := root.Children[0]
+	if reply.Move != "Q16" || reply.Color != "W" || reply.MoveNumber != 2 || !reply.InOriginal {
+		t.Errorf("Unexpected reply: %+v", reply)
+	}
+	// Re-analysis deviates from the single-shot PV at the third move
+	third := reply.Children[0]
+	if third.Move != "C16" || third.InOriginal || third.ScoreLead != 2.5 {
+		t.Errorf("Unexpected third move: %+v", third)
+	}
+	if len(third.Children) != 0 {
+		t.Errorf("Expected expansion to stop at depth 2, got %d children", len(third.Children))
+	}
+
+	// Unseeded branches hit the stopped engine and mark the tree partial
+	tree, err = engine.ExpandVariation(context.Background(), start, "D4", &VariationOptions{Depth: 1, Branches: 2, MaxVisits: 50})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !tree.Partial || len(tree.Root.Children) != 2 || tree.Root.Children[1].Analyzed {
+		t.Errorf("Expected partial tree with unanalyzed R4, got partial=%v children=%d", tree.Partial, len(tree.Root.Children))
+	}
+}
+
+func TestExpandVariationValidation(t *testing.T) {
+	engine := newSeededEngine(t, 50, nil)
+	start := &Position{Rules: "chinese", BoardXSize: 19, BoardYSize: 19, Moves: []Move{}}
+
+	_, err := engine.ExpandVariation(context.Background(), start, "Z99", nil)
+	if apperrors.CodeOf(err) != apperrors.CodeBadCoordinate {
+		t.Errorf("Expected %s, got %v", apperrors.CodeBadCoordinate, err)
+	}
+	_, err = engine.ExpandVariation(context.Background(), start, "D4", &VariationOptions{Depth: maxVariationDepth + 1})
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
@@ -0,0 +1,180 @@
+package katago
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+const (
+	defaultWhatIfVisits = 200
+	// maxWhatIfMoves bounds the length of a hypothetical sequence.
+	maxWhatIfMoves = 50
+)
+
+// WhatIfOptions controls a what-if evaluation.
+type WhatIfOptions struct {
+	MaxVisits int // Visits for each of the two analyses (default: 200)
+}
+
+// WhatIfEval is KataGo's evaluation of one position in a what-if.
+type WhatIfEval struct {
+	Winrate       float64  `json:"winrate"`
+	ScoreLead     float64  `json:"scoreLead"`
+	Visits        int      `json:"visits"`
+	CurrentPlayer string   `json:"currentPlayer"`
+	BestMove      string   `json:"bestMove,omitempty"`
+	PV            []string `json:"pv,omitempty"`
+}
+
+// WhatIfResult compares a position with the position after a hypothetical
+// sequence of moves.
+type WhatIfResult struct {
+	Sequence []Move `json:"sequence"`
+	// BestLine is KataGo's principal variation from the original position.
+	BestLine []string `json:"bestLine,omitempty"`
+	// MatchingMoves is how many moves of the sequence follow BestLine.
+	MatchingMoves int        `json:"matchingMoves"`
+	Before        WhatIfEval `json:"before"`
+	After         WhatIfEval `json:"after"`
+	// WinrateChange and ScoreChange are After minus Before, as reported by
+	// KataGo.
+	WinrateChange float64        `json:"winrateChange"`
+	ScoreChange   float64        `json:"scoreChange"`
+	Captures      map[string]int `json:"captures,omitempty"` // Stones captured during the sequence, by color
+}
+
+// WhatIf plays a hypothetical sequence of moves, alternating colors from
+// the player to move, and compares KataGo's evaluation of the resulting
+// position with the original position and its best line.
+func (e *Engine) WhatIf(ctx context.Context, position *Position, moves []string, opts *WhatIfOptions) (*WhatIfResult, error) {
+	return whatIf(ctx, e.Analyze, position, moves, opts)
+}
+
+// whatIf evaluates a what-if with the given analysis function.
+func whatIf(ctx context.Context, analyze func(context.Context, *AnalysisRequest) (*AnalysisResult, error),
+	position *Position, moves []string, opts *WhatIfOptions) (*WhatIfResult, error) {
+	if err := ValidatePosition(position); err != nil {
+		return nil, fmt.Errorf("invalid position: %w", err)
+	}
+	final, sequence, captures, err := playSequence(position, moves)
+	if err != nil {
+		return nil, err
+	}
+	visits := defaultWhatIfVisits
+	if opts != nil && opts.MaxVisits > 0 {
+		visits = opts.MaxVisits
+	}
+
+	positions := []*Position{position, final}
+	results := make([]*AnalysisResult, len(positions))
+	errs := make([]error, len(positions))
+	var wg sync.WaitGroup
+	for i, pos := range positions {
+		wg.Add(1)
+		go func(i int, pos *Position) {
+			defer wg.Done()
+			posVisits := visits
+			results[i], errs[i] = analyze(ctx, &AnalysisRequest{Position: pos, MaxVisits: &posVisits})
+		}(i, pos)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			which := "original"
+			if i == 1 {
+				which = "resulting"
+			}
+			return nil, fmt.Errorf("failed to analyze %s position: %w", which, err)
+		}
+	}
+
+	result := &WhatIfResult{
+		Sequence: sequence,
+		Before:   whatIfEval(results[0]),
+		After:    whatIfEval(results[1]),
+		Captures: captures,
+	}
+	result.BestLine = result.Before.PV
+	for i, move := range sequence {
+		if i >= len(result.BestLine) || !strings.EqualFold(moveLocation(move), result.BestLine[i]) {
+			break
+		}
+		result.MatchingMoves++
+	}
+	result.WinrateChange = result.After.Winrate - result.Before.Winrate
+	result.ScoreChange = roundTenth(result.After.ScoreLead - result.Before.ScoreLead)
+	return result, nil
+}
+
+// playSequence plays moves on position, alternating colors from the player
+// to move. It returns the resulting position, the moves played and the
+// stones each color captured, or an error naming the first illegal move.
+func playSequence(position *Position, moves []string) (*Position, []Move, map[string]int, error) {
+	if len(moves) == 0 {
+		return nil, nil, nil, apperrors.New(apperrors.CodeInvalidArgument, "at least one move is required")
+	}
+	if len(moves) > maxWhatIfMoves {
+		return nil, nil, nil, apperrors.New(apperrors.CodeInvalidArgument,
+			"sequence has %d moves; at most %d are allowed", len(moves), maxWhatIfMoves)
+	}
+	board, err := BoardFromPosition(position)
+	if err != nil {
+		return nil, nil, nil, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "invalid position")
+	}
+	startB, startW := board.Captures("b"), board.Captures("w")
+
+	final := position
+	sequence := make([]Move, 0, len(moves))
+	color := nextPlayer(position)
+	for i, move := range moves {
+		move = strings.ToUpper(strings.TrimSpace(move))
+		if move == "" {
+			return nil, nil, nil, apperrors.New(apperrors.CodeBadCoordinate, "sequence move %d is empty", i+1)
+		}
+		if err := board.Play(color, move); err != nil {
+			return nil, nil, nil, fmt.Errorf("sequence move %d (%s %s): %w", i+1, color, move, err)
+		}
+		final = positionAfter(final, color, move)
+		sequence = append(sequence, final.Moves[len(final.Moves)-1])
+		color = opponent(color)
+	}
+
+	captures := make(map[string]int)
+	if n := board.Captures("b") - startB; n > 0 {
+		captures["B"] = n
+	}
+	if n := board.Captures("w") - startW; n > 0 {
+		captures["W"] = n
+	}
+	if len(captures) == 0 {
+		captures = nil
+	}
+	return final, sequence, captures, nil
+}
+
+// whatIfEval summarizes an analysis result.
+func whatIfEval(result *AnalysisResult) WhatIfEval {
+	eval := WhatIfEval{
+		Winrate:       result.RootInfo.Winrate,
+		ScoreLead:     result.RootInfo.ScoreLead,
+		Visits:        result.RootInfo.Visits,
+		CurrentPlayer: result.RootInfo.CurrentPlayer,
+	}
+	if len(result.MoveInfos) > 0 {
+		eval.BestMove = result.MoveInfos[0].Move
+		eval.PV = result.MoveInfos[0].PV
+	}
+	return eval
+}
+
+// moveLocation returns a move's GTP location, "pass" for a pass.
+func moveLocation(move Move) string {
+	if move.Location == "" {
+		return "pass"
+	}
+	return move.Location
+}
@@ -0,0 +1,97 @@
+package katago
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+)
+
+func TestPlaySequence(t *testing.T) {
+	// White's C1 stone has one liberty at D1
+	position := &Position{
+		Rules: "chinese", BoardXSize: 9, BoardYSize: 9, Komi: 7,
+		InitialStones: []Stone{{Color: "w", Location: "C1"}, {Color: "b", Location: "B1"}, {Color: "b", Location: "C2"}},
+	}
+	final, sequence, captures, err := playSequence(position, []string{"d1", "pass", "E5"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []Move{{Color: "b", Location: "D1"}, {Color: "w", Location: ""}, {Color: "b", Location: "E5"}}
+	if len(sequence) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, sequence)
+	}
+	for i := range want {
+		if sequence[i] != want[i] {
+			t.Errorf("Move %d: expected %v, got %v", i+1, want[i], sequence[i])
+		}
+	}
+	if len(final.Moves) != 3 || len(position.Moves) != 0 {
+		t.Errorf("Expected the sequence on a copy of the position, got %v and %v", final.Moves, position.Moves)
+	}
+	if captures["B"] != 1 || captures["W"] != 0 {
+		t.Errorf("Expected Black to capture one stone, got %v", captures)
+	}
+
+	tests := []struct {
+		name  string
+		moves []string
+		code  apperrors.Code
+	}{
+		{"empty sequence", nil, apperrors.CodeInvalidArgument},
+		{"occupied point", []string{"E5", "E5"}, apperrors.CodeInvalidArgument},
+		{"suicide", []string{"A2", "A1"}, apperrors.CodeInvalidArgument},
+		{"off the board", []string{"Z9"}, apperrors.CodeBadCoordinate},
+		{"blank move", []string{" "}, apperrors.CodeBadCoordinate},
+		{"too long", make([]string, maxWhatIfMoves+1), apperrors.CodeInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := playSequence(position, tt.moves); apperrors.CodeOf(err) != tt.code {
+				t.Errorf("Expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestWhatIf(t *testing.T) {
+	position := &Position{Rules: "chinese", BoardXSize: 9, BoardYSize: 9, Komi: 7,
+		Moves: []Move{{Color: "b", Location: "E5"}}}
+	analyze := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		if len(req.Position.Moves) == 1 {
+			return &AnalysisResult{
+				RootInfo:  RootInfo{Visits: *req.MaxVisits, Winrate: 0.6, ScoreLead: 3, CurrentPlayer: "W"},
+				MoveInfos: []MoveInfo{{Move: "C3", PV: []string{"C3", "G7", "C7"}}},
+			}, nil
+		}
+		return &AnalysisResult{
+			RootInfo:  RootInfo{Visits: *req.MaxVisits, Winrate: 0.7, ScoreLead: 5.5, CurrentPlayer: "B"},
+			MoveInfos: []MoveInfo{{Move: "G3", PV: []string{"G3"}}},
+		}, nil
+	}
+
+	result, err := whatIf(context.Background(), analyze, position, []string{"C3", "G7", "G3"}, &WhatIfOptions{MaxVisits: 50})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.MatchingMoves != 2 {
+		t.Errorf("Expected the first 2 moves to follow the best line, got %d", result.MatchingMoves)
+	}
+	if result.Before.Visits != 50 || result.Before.BestMove != "C3" || result.After.CurrentPlayer != "B" {
+		t.Errorf("Unexpected evaluations: %+v, %+v", result.Before, result.After)
+	}
+	if result.ScoreChange != 2.5 || result.WinrateChange < 0.099 || result.WinrateChange > 0.101 {
+		t.Errorf("Expected +10%% and +2.5 points, got %+v", result)
+	}
+
+	failing := func(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+		if len(req.Position.Moves) > 1 {
+			return nil, errors.New("engine failure")
+		}
+		return analyze(ctx, req)
+	}
+	if _, err := whatIf(context.Background(), failing, position, []string{"C3"}, nil); err == nil {
+		t.Error("Expected an error when the resulting position cannot be analyzed")
+	}
+}
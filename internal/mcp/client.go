@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// anonymousClient is the client ID of requests that identify no client.
+const anonymousClient = "anonymous"
+
+// clientIDKey is the context key of the client ID.
+type clientIDKey struct{}
+
+// WithClientID returns a context carrying the ID of the client making a
+// request, used for rate limiting, logs and metrics.
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, clientID)
+}
+
+// ClientIDFromContext returns the client ID carried by a context.
+func ClientIDFromContext(ctx context.Context) (string, bool) {
+	clientID, ok := ctx.Value(clientIDKey{}).(string)
+	return clientID, ok && clientID != ""
+}
+
+// namespaceKey is the context key of the client's namespace.
+type namespaceKey struct{}
+
+// WithNamespace returns a context carrying the namespace of the client
+// making a request.
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// NamespaceFromContext returns the namespace carried by a context.
+func NamespaceFromContext(ctx context.Context) (string, bool) {
+	namespace, ok := ctx.Value(namespaceKey{}).(string)
+	return namespace, ok && namespace != ""
+}
+
+// sessionClientID derives a client ID from the client information the MCP
+// session received in its initialize request: the client's name and
+// version, e.g. "claude-desktop/0.9.2". It returns "" when the session has
+// none.
+func sessionClientID(ctx context.Context) string {
+	session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithClientInfo)
+	if !ok {
+		return ""
+	}
+	info := session.GetClientInfo()
+	switch {
+	case info.Name == "":
+		return ""
+	case info.Version == "":
+		return info.Name
+	default:
+		return info.Name + "/" + info.Version
+	}
+}
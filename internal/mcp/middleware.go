@@ -2,152 +2,829 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"sync"
 	"time"
 
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/coords"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
 	"github.com/dmmcquay/katago-mcp/internal/logging"
 	"github.com/dmmcquay/katago-mcp/internal/metrics"
+	"github.com/dmmcquay/katago-mcp/internal/provenance"
+	"github.com/dmmcquay/katago-mcp/internal/quota"
 	"github.com/dmmcquay/katago-mcp/internal/ratelimit"
+	"github.com/dmmcquay/katago-mcp/internal/retry"
+	"github.com/dmmcquay/katago-mcp/internal/tracing"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// Middleware wraps MCP tool handlers with common functionality like rate limiting, metrics, and logging.
+// Middleware wraps MCP tool handlers with a chain of hooks providing
+// common functionality like rate limiting, metrics, and logging.
 type Middleware struct {
 	logger      logging.ContextLogger
 	metrics     *metrics.Collector
 	prometheus  *metrics.PrometheusCollector
 	rateLimiter *ratelimit.Limiter
+	quota       *quota.Tracker
+	timeouts    *config.TimeoutConfig
+	lanes       map[string]string // Tool to scheduling lane
+	clientID    string            // Fixed client ID; empty derives it per request
+	trustArg    bool              // Whether a clientID argument identifies the caller
+	namespaces  *config.NamespaceConfig
+	signer      *provenance.Signer
+	settings    SettingsFunc // Settings of the engine behind each signed result
+	notify      notifyFunc   // Sends a notification to the client making a call
+	boardSize   BoardSizeFunc
+
+	hooks []Hook
+	skip  map[string]map[string]bool // Tool to the hooks it opts out of
+}
+
+// Names of the built-in hooks, in the order they run.
+const (
+	HookIdentity  = "identity"  // The client ID and namespace, put in the context for later hooks
+	HookTracing   = "tracing"   // Request IDs and a span per call
+	HookUsage     = "usage"     // Engine resource accounting in the result's _meta
+	HookSign      = "sign"      // Signed provenance of analysis results in the result's _meta
+	HookProgress  = "progress"  // Progress notifications for calls that carry a progress token
+	HookLogging   = "logging"   // Request and outcome logs
+	HookMetrics   = "metrics"   // Call counts, durations and error codes
+	HookRateLimit = "ratelimit" // Per-client rate limits
+	HookQuota     = "quota"     // Per-client daily and monthly visit quotas
+	HookLane      = "lane"      // The scheduling lane of the tool's queries
+	HookTimeout   = "timeout"   // Per-tool deadlines
+	HookCoords    = "coords"    // Coordinates in the client's coordSystem, converted to and from GTP
+)
+
+// Hook is one component of the middleware chain. Wrap returns a handler
+// that does the hook's work around next, the rest of the chain; it is
+// called once for each tool as the tool is wrapped.
+type Hook struct {
+	Name string
+	Wrap func(toolName string, next ToolHandler) ToolHandler
 }
 
-// NewMiddleware creates a new middleware instance.
+// NewMiddleware creates a new middleware instance with the built-in hooks.
 func NewMiddleware(logger logging.ContextLogger, metricsCollector *metrics.Collector, rateLimiter *ratelimit.Limiter) *Middleware {
-	return &Middleware{
+	m := &Middleware{
 		logger:      logger,
 		metrics:     metricsCollector,
-		prometheus:  metrics.NewPrometheusCollector(),
+		prometheus:  metrics.NewPrometheusCollector(nil),
 		rateLimiter: rateLimiter,
+		notify:      notifyClient,
+		skip:        make(map[string]map[string]bool),
+	}
+	m.Use(
+		Hook{Name: HookIdentity, Wrap: m.identifyClient},
+		Hook{Name: HookTracing, Wrap: m.traceTool},
+		Hook{Name: HookUsage, Wrap: m.accountUsage},
+		Hook{Name: HookSign, Wrap: m.signResult},
+		Hook{Name: HookProgress, Wrap: m.reportProgress},
+		Hook{Name: HookLogging, Wrap: m.logTool},
+		Hook{Name: HookMetrics, Wrap: m.measureTool},
+		Hook{Name: HookRateLimit, Wrap: m.limitRate},
+		Hook{Name: HookQuota, Wrap: m.enforceQuota},
+		Hook{Name: HookLane, Wrap: m.assignLane},
+		Hook{Name: HookTimeout, Wrap: m.enforceTimeout},
+		Hook{Name: HookCoords, Wrap: m.convertCoords},
+	)
+	return m
+}
+
+// Use appends hooks to the chain. Hooks run in the order they are added,
+// the first outermost, so a hook added later sees the context set up by
+// earlier ones. Hooks must be added before tools are wrapped.
+func (m *Middleware) Use(hooks ...Hook) {
+	m.hooks = append(m.hooks, hooks...)
+}
+
+// UseBefore inserts a hook into the chain just before the named hook, so
+// that it runs outside it.
+func (m *Middleware) UseBefore(name string, hook Hook) error {
+	for i, h := range m.hooks {
+		if h.Name == name {
+			m.hooks = append(m.hooks[:i], append([]Hook{hook}, m.hooks[i:]...)...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no middleware hook named %s", name)
+}
+
+// Skip opts a tool out of the named hooks.
+func (m *Middleware) Skip(toolName string, hooks ...string) {
+	if m.skip[toolName] == nil {
+		m.skip[toolName] = make(map[string]bool)
+	}
+	for _, name := range hooks {
+		m.skip[toolName][name] = true
+	}
+}
+
+// Hooks returns the names of the hooks in the chain, outermost first.
+func (m *Middleware) Hooks() []string {
+	names := make([]string, len(m.hooks))
+	for i, h := range m.hooks {
+		names[i] = h.Name
+	}
+	return names
+}
+
+// SetClientID sets a fixed client ID for every request, such as a key
+// naming the deployment, instead of deriving one from each request.
+func (m *Middleware) SetClientID(clientID string) {
+	m.clientID = clientID
+}
+
+// SetTrustClientIDArgument sets whether the clientID argument of a call
+// identifies its client, for servers behind a gateway that sets it. By
+// default the argument is ignored.
+func (m *Middleware) SetTrustClientIDArgument(trust bool) {
+	m.trustArg = trust
+}
+
+// SetNamespaces sets how clients are grouped into namespaces and whether
+// namespaces share cached results.
+func (m *Middleware) SetNamespaces(namespaces *config.NamespaceConfig) {
+	m.namespaces = namespaces
+}
+
+// SettingsFunc returns the settings of the engine a tool call is routed to.
+type SettingsFunc func(toolName string, request mcp.CallToolRequest) (*katago.AnalysisSettings, error)
+
+// SetSigner sets the signer of analysis results and the source of the
+// engine settings recorded in their provenance.
+func (m *Middleware) SetSigner(signer *provenance.Signer, settings SettingsFunc) {
+	m.signer = signer
+	m.settings = settings
+}
+
+// BoardSizeFunc returns the board size of the game a tool call refers to,
+// or 0 if it cannot tell.
+type BoardSizeFunc func(ctx context.Context, request mcp.CallToolRequest) int
+
+// SetBoardSizes sets the source of the board size used to convert a call's
+// coordinates. Without one, boards are taken to be 19x19.
+func (m *Middleware) SetBoardSizes(boardSize BoardSizeFunc) {
+	m.boardSize = boardSize
+}
+
+// SetPrometheus sets the collector of the server's Prometheus metrics. Until
+// it is set, metrics are recorded in an unregistered collector.
+func (m *Middleware) SetPrometheus(collector *metrics.PrometheusCollector) {
+	m.prometheus = collector
+}
+
+// SetQuota sets the tracker of client visit quotas.
+func (m *Middleware) SetQuota(tracker *quota.Tracker) {
+	m.quota = tracker
+}
+
+// SetLanes sets the scheduling lane of each tool's engine queries. Tools
+// without one are interactive.
+func (m *Middleware) SetLanes(lanes map[string]string) {
+	m.lanes = lanes
+}
+
+// SetTimeouts sets per-tool deadlines enforced on the handler context.
+func (m *Middleware) SetTimeouts(timeouts *config.TimeoutConfig) {
+	m.timeouts = timeouts
+}
+
+// ResponseMetaKey is the key under which resource accounting is attached to
+// a tool result's _meta field.
+const ResponseMetaKey = "resources"
+
+// ProvenanceMetaKey is the key under which the signed provenance of an
+// analysis result is attached to its _meta field.
+const ProvenanceMetaKey = "provenance"
+
+// ErrorMetaKey is the key under which the code of a rate limited call, the
+// bucket that denied it and the seconds until it would be allowed are
+// attached to the _meta field of its error result.
+const ErrorMetaKey = "error"
+
+// ErrorMeta describes a denied call, so clients can back off without
+// parsing the message.
+type ErrorMeta struct {
+	Code              apperrors.Code `json:"code"`
+	Bucket            string         `json:"bucket,omitempty"`
+	RetryAfterSeconds int            `json:"retryAfterSeconds,omitempty"`
+}
+
+// deniedResult returns a call denied by the rate limits as an error result
+// carrying an ErrorMeta, since MCP error responses carry no structured data.
+// The message keeps the bucket and delay for people reading it. Other errors
+// are returned unchanged.
+func deniedResult(err error) (*mcp.CallToolResult, error) {
+	var denied *ratelimit.DeniedError
+	if !errors.As(err, &denied) {
+		return nil, err
 	}
+	result := mcp.NewToolResultError(err.Error())
+	result.Meta = map[string]any{ErrorMetaKey: ErrorMeta{
+		Code:              apperrors.CodeOf(err),
+		Bucket:            denied.Bucket,
+		RetryAfterSeconds: denied.RetryAfterSeconds(),
+	}}
+	return result, nil
 }
 
+// ResponseMeta describes the resources consumed while handling a tool call.
+type ResponseMeta struct {
+	VisitsUsed    int     `json:"visitsUsed"`
+	EngineQueries int     `json:"engineQueries"`
+	SharedQueries int     `json:"sharedQueries"`
+	CacheHits     int     `json:"cacheHits"`
+	CacheMisses   int     `json:"cacheMisses"`
+	EngineTimeMs  float64 `json:"engineTimeMs"`
+	QueueWaitMs   float64 `json:"queueWaitMs"`
+	TotalTimeMs   float64 `json:"totalTimeMs"`
+	ProcessID     int     `json:"processId,omitempty"`
+}
+
+// newResponseMeta builds response metadata from recorded engine usage.
+func newResponseMeta(stats katago.UsageStats, total time.Duration) ResponseMeta {
+	return ResponseMeta{
+		VisitsUsed:    stats.Visits,
+		EngineQueries: stats.EngineQueries,
+		SharedQueries: stats.SharedQueries,
+		CacheHits:     stats.CacheHits,
+		CacheMisses:   stats.CacheMisses,
+		EngineTimeMs:  durationMs(stats.EngineTime),
+		QueueWaitMs:   durationMs(stats.QueueWait),
+		TotalTimeMs:   durationMs(total),
+		ProcessID:     stats.ProcessID,
+	}
+}
+
+// durationMs converts a duration to fractional milliseconds.
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// attachResponseMeta adds resource accounting to a tool result.
+func attachResponseMeta(result *mcp.CallToolResult, meta ResponseMeta) {
+	if result == nil {
+		return
+	}
+	if result.Meta == nil {
+		result.Meta = make(map[string]any)
+	}
+	result.Meta[ResponseMetaKey] = meta
+}
+
+// Backoff bounds for retried tool calls.
+const (
+	retryInitialDelay = 100 * time.Millisecond
+	retryMaxDelay     = 5 * time.Second
+)
+
 // ToolHandler is the function signature for MCP tool handlers.
 type ToolHandler func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
 
 // WrapTool wraps a tool handler with middleware functionality.
 func (m *Middleware) WrapTool(toolName string, handler ToolHandler) ToolHandler {
+	instrumented := m.instrument(toolName, handler)
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := instrumented(ctx, request)
+		if err != nil {
+			return deniedResult(apperrors.ForClient(err))
+		}
+		return result, nil
+	}
+}
+
+// instrument wraps a handler in the hooks the tool has not opted out of.
+// Errors are returned without the client-facing code prefix so callers can
+// still classify them.
+func (m *Middleware) instrument(toolName string, handler ToolHandler) ToolHandler {
+	for i := len(m.hooks) - 1; i >= 0; i-- {
+		if !m.skip[toolName][m.hooks[i].Name] {
+			handler = m.hooks[i].Wrap(toolName, handler)
+		}
+	}
+	return handler
+}
+
+// identifyClient resolves the client making each call and puts its ID and
+// namespace in the context, so that every later hook and the handler agree
+// on them. Under strict isolation the namespace also scopes the cache.
+func (m *Middleware) identifyClient(toolName string, next ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		clientID := m.clientID
+		if clientID == "" && m.trustArg {
+			clientID = clientIDArgument(request)
+		}
+		if clientID == "" {
+			clientID = extractClientID(ctx, request)
+		}
+		ctx = WithClientID(ctx, clientID)
+		if m.namespaces != nil {
+			namespace := m.namespaces.Namespace(clientID)
+			ctx = WithNamespace(ctx, namespace)
+			if m.namespaces.Strict() {
+				ctx = katago.WithCacheNamespace(ctx, namespace)
+			}
+		}
+		return next(ctx, request)
+	}
+}
+
+// traceTool assigns request IDs up front, so the trace and handler logs
+// share them, and records each call as a span.
+func (m *Middleware) traceTool(toolName string, next ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = withRequestIDs(ctx)
+		ctx, span := tracing.StartSpan(ctx, "mcp.tool/"+toolName, attribute.String("mcp.tool", toolName))
+		defer span.End()
+		span.SetAttributes(attribute.String("mcp.client", extractClientID(ctx, request)))
+
+		result, err := next(ctx, request)
+		if err != nil {
+			span.SetAttributes(attribute.String("error.code", string(apperrors.CodeOf(err))))
+			tracing.RecordError(span, err)
+		}
+		return result, err
+	}
+}
+
+// accountUsage attaches the engine resources used by a call to its result.
+func (m *Middleware) accountUsage(toolName string, next ToolHandler) ToolHandler {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		start := time.Now()
+		ctx, usage := katago.WithUsage(ctx)
+		result, err := next(ctx, request)
+		attachResponseMeta(result, newResponseMeta(usage.Stats(), time.Since(start)))
+		return result, err
+	}
+}
 
-		// Extract client ID from context or request
-		clientID := extractClientID(ctx, request)
+// signResult attaches a signed provenance record to each result that
+// includes analysis, when a signer is set. It runs inside accountUsage so
+// the request's usage is in the context.
+func (m *Middleware) signResult(toolName string, next ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(ctx, request)
+		if m.signer == nil || m.settings == nil || err != nil || result == nil || result.IsError {
+			return result, err
+		}
+		stats := katago.UsageFromContext(ctx).Stats()
+		if stats.EngineQueries+stats.SharedQueries+stats.CacheHits == 0 {
+			return result, err
+		}
+		settings, settingsErr := m.settings(toolName, request)
+		if settingsErr != nil {
+			m.logger.Warn("Failed to describe settings for provenance", "tool", toolName, "error", settingsErr)
+			return result, err
+		}
 
-		// Log the request
+		record := provenance.Record{
+			Tool:          toolName,
+			Engine:        settings.Engine,
+			KataGoVersion: settings.KataGoVersion,
+			MaxVisits:     settings.MaxVisits,
+			VisitsUsed:    stats.Visits,
+		}
+		if settings.Model != nil {
+			record.Model = settings.Model.Name
+			record.ModelSHA256 = settings.Model.SHA256
+		}
+		m.signer.Sign(&record, resultTexts(result))
+		if result.Meta == nil {
+			result.Meta = make(map[string]any)
+		}
+		result.Meta[ProvenanceMetaKey] = record
+		return result, err
+	}
+}
+
+// resultTexts returns the text content of a tool result.
+func resultTexts(result *mcp.CallToolResult) []string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return texts
+}
+
+// progressInterval is the shortest time between progress notifications for
+// a call, other than the one reporting completion.
+const progressInterval = 250 * time.Millisecond
+
+// notifyFunc sends a notification to the client making a call.
+type notifyFunc func(ctx context.Context, method string, params map[string]any) error
+
+// notifyClient sends a notification through the MCP server handling a call.
+func notifyClient(ctx context.Context, method string, params map[string]any) error {
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return errors.New("no MCP server in context")
+	}
+	return srv.SendNotificationToClient(ctx, method, params)
+}
+
+// reportProgress sends the progress of long analyses, such as a game
+// review, as notifications/progress messages when the client asked for
+// them by sending a progress token in the request's _meta. The token is
+// the client's, as MCP requires; calls without one report nothing.
+// Notifications are sent at most every progressInterval and never go
+// backwards, so a retried call does not restart the client's progress bar.
+func (m *Middleware) reportProgress(toolName string, next ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+			return next(ctx, request)
+		}
+		token := request.Params.Meta.ProgressToken
+		var mu sync.Mutex
+		last, lastSent := -1.0, time.Time{}
+		failed := false
+		progressCtx := katago.WithProgress(ctx, func(progress, total float64, message string) {
+			mu.Lock()
+			defer mu.Unlock()
+			if progress <= last || (progress < total && time.Since(lastSent) < progressInterval) {
+				return
+			}
+			last, lastSent = progress, time.Now()
+			err := m.notify(ctx, "notifications/progress", map[string]any{
+				"progressToken": token,
+				"progress":      progress,
+				"total":         total,
+				"message":       message,
+			})
+			if err != nil && !failed {
+				failed = true
+				m.logger.Debug("Failed to send progress notification", "tool", toolName, "error", err)
+			}
+		})
+		return next(progressCtx, request)
+	}
+}
+
+// logTool logs each request and its outcome.
+func (m *Middleware) logTool(toolName string, next ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		clientID := extractClientID(ctx, request)
+		namespace, _ := NamespaceFromContext(ctx)
 		m.logger.Info("Tool request received",
 			"tool", toolName,
 			"client", clientID,
+			"namespace", namespace,
 			"arguments", request.Params.Arguments,
 		)
 
-		// Check rate limits
-		if m.rateLimiter != nil {
-			allowed, err := m.rateLimiter.Allow(clientID, toolName)
-			m.prometheus.RecordRateLimit(clientID, toolName, !allowed)
-			if !allowed {
-				m.logger.Warn("Rate limit exceeded",
-					"tool", toolName,
-					"client", clientID,
-					"error", err,
-				)
-				m.metrics.RecordToolCall(toolName, "rate_limited", time.Since(start))
-				m.prometheus.RecordToolCall(toolName, "rate_limited", time.Since(start).Seconds())
-				return nil, fmt.Errorf("rate limit exceeded for tool %s: %w", toolName, err)
-			}
-		}
-
-		// Call the actual handler
-		result, err := handler(ctx, request)
-
-		// Record metrics
+		result, err := next(ctx, request)
 		duration := time.Since(start)
-		status := "success"
-		if err != nil {
-			status = "error"
-			m.logger.Error("Tool request failed",
+		switch code := apperrors.CodeOf(err); {
+		case err == nil:
+			m.logger.Info("Tool request completed",
 				"tool", toolName,
 				"client", clientID,
-				"error", err,
 				"duration", duration,
 			)
-		} else {
-			m.logger.Info("Tool request completed",
+		case code == apperrors.CodeRateLimited:
+			m.logger.Warn("Rate limit exceeded",
 				"tool", toolName,
 				"client", clientID,
+				"error", err,
+			)
+		case code == apperrors.CodeQuotaExceeded:
+			m.logger.Warn("Quota exceeded",
+				"tool", toolName,
+				"client", clientID,
+				"error", err,
+			)
+		default:
+			m.logger.Error("Tool request failed",
+				"tool", toolName,
+				"client", clientID,
+				"code", code,
+				"error", err,
 				"duration", duration,
 			)
 		}
+		return result, err
+	}
+}
+
+// measureTool records the outcome and duration of each call.
+func (m *Middleware) measureTool(toolName string, next ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+		duration := time.Since(start)
+
+		status := "success"
+		if err != nil {
+			status = "error"
+			code := apperrors.CodeOf(err)
+			switch code {
+			case apperrors.CodeRateLimited:
+				status = "rate_limited"
+			case apperrors.CodeQuotaExceeded:
+				status = "quota_exceeded"
+			}
+			m.prometheus.RecordToolError(toolName, string(code))
+		}
 		m.metrics.RecordToolCall(toolName, status, duration)
 		m.prometheus.RecordToolCall(toolName, status, duration.Seconds())
+		m.prometheus.RecordClientToolCall(extractClientID(ctx, request), toolName)
+		return result, err
+	}
+}
+
+// limitRate rejects calls from clients over their rate limit.
+func (m *Middleware) limitRate(toolName string, next ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if m.rateLimiter == nil {
+			return next(ctx, request)
+		}
+		clientID := extractClientID(ctx, request)
+		allowed, err := m.rateLimiter.Allow(clientID, toolName)
+		m.prometheus.RecordRateLimit(clientID, toolName, !allowed)
+		if !allowed {
+			return nil, apperrors.Wrap(apperrors.CodeRateLimited, err, "rate limit exceeded for tool %s", toolName)
+		}
+		return next(ctx, request)
+	}
+}
+
+// enforceQuota rejects calls from clients that have used up their visit
+// quota, and charges each call's KataGo visits to its client. Results served
+// from the cache or shared with another caller's query are free.
+func (m *Middleware) enforceQuota(toolName string, next ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if m.quota == nil {
+			return next(ctx, request)
+		}
+		clientID := extractClientID(ctx, request)
+		if err := m.quota.Check(clientID); err != nil {
+			var exceeded *quota.ExceededError
+			if errors.As(err, &exceeded) {
+				m.prometheus.RecordQuotaExceeded(clientID, exceeded.Period)
+			}
+			return nil, apperrors.Wrap(apperrors.CodeQuotaExceeded, err, "quota exceeded for tool %s", toolName)
+		}
 
+		usage := katago.UsageFromContext(ctx)
+		if usage == nil {
+			ctx, usage = katago.WithUsage(ctx)
+		}
+		before := usage.Stats().EngineVisits
+		result, err := next(ctx, request)
+		if visits := usage.Stats().EngineVisits - before; visits > 0 {
+			m.quota.Charge(clientID, visits)
+			m.prometheus.RecordClientVisits(clientID, visits)
+		}
 		return result, err
 	}
 }
 
-// WrapToolWithRetry wraps a tool handler with retry logic in addition to standard middleware.
-func (m *Middleware) WrapToolWithRetry(toolName string, handler ToolHandler, maxRetries int) ToolHandler {
-	wrappedHandler := m.WrapTool(toolName, handler)
+// assignLane schedules the tool's engine queries in its lane.
+func (m *Middleware) assignLane(toolName string, next ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if lane, ok := m.lanes[toolName]; ok {
+			ctx = katago.WithLane(ctx, lane)
+		}
+		return next(ctx, request)
+	}
+}
 
+// enforceTimeout applies the tool's deadline to the handler context.
+func (m *Middleware) enforceTimeout(toolName string, next ToolHandler) ToolHandler {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		var lastErr error
-		for attempt := 0; attempt <= maxRetries; attempt++ {
-			if attempt > 0 {
-				// Exponential backoff between retries
-				// Safe conversion: attempt is always >= 1 and <= maxRetries (small number)
-				shiftAmount := attempt - 1
-				if shiftAmount > 10 { // Prevent overflow for large shift amounts
-					shiftAmount = 10
-				}
-				backoff := time.Duration(1<<uint(shiftAmount)) * 100 * time.Millisecond // #nosec G115 -- shiftAmount is bounded
-				m.logger.Debug("Retrying tool request",
-					"tool", toolName,
-					"attempt", attempt,
-					"backoff", backoff,
-				)
-				time.Sleep(backoff)
+		if m.timeouts != nil {
+			if timeout := m.timeouts.ToolTimeout(toolName); timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
 			}
+		}
+		return next(ctx, request)
+	}
+}
 
-			result, err := wrappedHandler(ctx, request)
-			if err == nil {
-				return result, nil
+// convertCoords lets a client use SGF or numeric coordinates by passing
+// coordSystem. Moves and regions in the arguments are converted to GTP
+// before the handler sees them, and the GTP vertices in its text result or
+// error are converted back. It runs innermost, so logs show the arguments
+// as sent and signatures cover the result as returned.
+func (m *Middleware) convertCoords(toolName string, next ToolHandler) ToolHandler {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok || args["coordSystem"] == nil {
+			return next(ctx, request)
+		}
+		name, _ := args["coordSystem"].(string)
+		system, err := coords.Parse(name)
+		if err != nil {
+			return nil, err
+		}
+		if system == coords.GTP {
+			return next(ctx, request)
+		}
+		size := 19
+		if m.boardSize != nil {
+			if n := m.boardSize(ctx, request); n > 0 {
+				size = n
 			}
+		}
 
-			// Don't retry rate limit errors
-			if strings.Contains(err.Error(), "rate limit exceeded") {
+		converted, err := convertCoordArgs(args, system, size)
+		if err != nil {
+			return nil, err
+		}
+		request.Params.Arguments = converted
+		result, err := next(ctx, request)
+		if err != nil {
+			if message := system.ConvertText(err.Error(), size); message != err.Error() && ctx.Err() == nil {
+				return nil, apperrors.New(apperrors.CodeOf(err), "%s", message)
+			}
+			return nil, err
+		}
+		if result != nil {
+			for i, content := range result.Content {
+				if text, ok := mcp.AsTextContent(content); ok {
+					text.Text = system.ConvertText(text.Text, size)
+					result.Content[i] = *text
+				}
+			}
+		}
+		return result, nil
+	}
+}
+
+// convertCoordArgs returns a copy of a call's arguments with the moves,
+// regions and position in a coordinate system converted to GTP.
+func convertCoordArgs(args map[string]interface{}, system coords.System, size int) (map[string]interface{}, error) {
+	converted := make(map[string]interface{}, len(args))
+	for key, val := range args {
+		converted[key] = val
+	}
+	for _, key := range []string{"move", "target"} {
+		if s, ok := args[key].(string); ok {
+			vertex, err := system.ToGTP(s, size)
+			if err != nil {
 				return nil, err
 			}
+			converted[key] = vertex
+		}
+	}
+	if list, ok := args["moves"].([]interface{}); ok {
+		moves := make([]interface{}, len(list))
+		for i, val := range list {
+			moves[i] = val
+			if s, ok := val.(string); ok {
+				vertex, err := system.ToGTP(s, size)
+				if err != nil {
+					return nil, err
+				}
+				moves[i] = vertex
+			}
+		}
+		converted["moves"] = moves
+	}
+	for _, key := range []string{"avoidMoves", "allowMoves", "region"} {
+		if list, ok := args[key].([]interface{}); ok {
+			regions := make([]interface{}, len(list))
+			for i, val := range list {
+				regions[i] = val
+				if s, ok := val.(string); ok {
+					regions[i] = system.RegionToGTP(s, size)
+				}
+			}
+			converted[key] = regions
+		}
+	}
+	if position, ok := args["position"].(map[string]interface{}); ok {
+		copied := make(map[string]interface{}, len(position))
+		for key, val := range position {
+			copied[key] = val
+		}
+		for _, key := range []string{"moves", "initialStones"} {
+			list, ok := position[key].([]interface{})
+			if !ok {
+				continue
+			}
+			stones := make([]interface{}, len(list))
+			for i, val := range list {
+				stones[i] = val
+				stone, ok := val.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				location, ok := stone["location"].(string)
+				if !ok || location == "" {
+					continue
+				}
+				vertex, err := system.ToGTP(location, size)
+				if err != nil {
+					return nil, err
+				}
+				if vertex == "pass" {
+					vertex = ""
+				}
+				copiedStone := make(map[string]interface{}, len(stone))
+				for k, v := range stone {
+					copiedStone[k] = v
+				}
+				copiedStone["location"] = vertex
+				stones[i] = copiedStone
+			}
+			copied[key] = stones
+		}
+		converted["position"] = copied
+	}
+	return converted, nil
+}
+
+// WrapToolWithRetry wraps a tool handler with retry logic in addition to standard middleware.
+// Only transient failures (engine unavailable, timeouts) are retried, with
+// jittered exponential backoff that stops early if the context is done.
+func (m *Middleware) WrapToolWithRetry(toolName string, handler ToolHandler, maxRetries int) ToolHandler {
+	instrumented := m.instrument(toolName, handler)
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var result *mcp.CallToolResult
+		retries := 0
+
+		retryManager := retry.NewManager(retry.Config{
+			MaxAttempts:  maxRetries + 1,
+			InitialDelay: retryInitialDelay,
+			MaxDelay:     retryMaxDelay,
+			Multiplier:   2.0,
+			Jitter:       0.1,
+			ShouldRetry:  apperrors.IsTransient,
+			OnRetry: func(attempt int, delay time.Duration, err error) {
+				retries = attempt
+				code := apperrors.CodeOf(err)
+				m.logger.Debug("Retrying tool request",
+					"tool", toolName,
+					"attempt", attempt,
+					"backoff", delay,
+					"code", code,
+				)
+				m.prometheus.RecordToolRetry(toolName, string(code))
+			},
+		})
 
-			lastErr = err
+		err := retryManager.Run(ctx, func(ctx context.Context) error {
+			var callErr error
+			result, callErr = instrumented(ctx, request)
+			return callErr
+		})
+		if err == nil {
+			return result, nil
 		}
+		if retries > 0 {
+			err = fmt.Errorf("tool %s failed after %d retries: %w", toolName, retries, err)
+		}
+		return deniedResult(apperrors.ForClient(err))
+	}
+}
 
-		return nil, fmt.Errorf("tool %s failed after %d retries: %w", toolName, maxRetries, lastErr)
+// withRequestIDs returns a context carrying correlation and request IDs,
+// reusing any that are already present.
+func withRequestIDs(ctx context.Context) context.Context {
+	if _, ok := logging.CorrelationIDFromContext(ctx); !ok {
+		ctx = logging.ContextWithCorrelationID(ctx, logging.GenerateCorrelationID())
+	}
+	if _, ok := logging.RequestIDFromContext(ctx); !ok {
+		ctx = logging.ContextWithRequestID(ctx, logging.GenerateRequestID())
 	}
+	return ctx
 }
 
-// extractClientID attempts to extract a client identifier from the context or request.
+// extractClientID identifies the client making a request: the ID in the
+// context, else the name and version the client gave when its MCP session
+// was initialized, else "anonymous". The clientID argument is only
+// consulted by the identity hook, when the server trusts it.
 func extractClientID(ctx context.Context, request mcp.CallToolRequest) string {
-	// First check context for client ID
+	if clientID, ok := ClientIDFromContext(ctx); ok {
+		return clientID
+	}
 	if clientID, ok := ctx.Value("clientID").(string); ok && clientID != "" {
 		return clientID
 	}
+	if clientID := sessionClientID(ctx); clientID != "" {
+		return clientID
+	}
+	return anonymousClient
+}
 
-	// Check if arguments contain a client ID
-	if request.Params.Arguments != nil {
-		if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
-			if clientID, ok := args["clientID"].(string); ok && clientID != "" {
-				return clientID
-			}
+// clientIDArgument returns the clientID argument of a call, or "".
+func clientIDArgument(request mcp.CallToolRequest) string {
+	if args, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if clientID, ok := args["clientID"].(string); ok {
+			return clientID
 		}
 	}
-
-	// Default to "anonymous"
-	return "anonymous"
+	return ""
 }
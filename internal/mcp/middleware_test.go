@@ -3,14 +3,21 @@ package mcp
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
 	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
 	"github.com/dmmcquay/katago-mcp/internal/logging"
 	"github.com/dmmcquay/katago-mcp/internal/metrics"
+	"github.com/dmmcquay/katago-mcp/internal/provenance"
+	"github.com/dmmcquay/katago-mcp/internal/quota"
 	"github.com/dmmcquay/katago-mcp/internal/ratelimit"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 func TestMiddleware(t *testing.T) {
@@ -57,6 +64,68 @@ func TestMiddleware(t *testing.T) {
 		}
 	})
 
+	t.Run("ResponseMeta", func(t *testing.T) {
+		middleware := NewMiddleware(logger, metricsCollector, nil)
+
+		handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			time.Sleep(5 * time.Millisecond)
+			return mcp.NewToolResultText("success"), nil
+		}
+		wrapped := middleware.WrapTool("testTool", handler)
+
+		result, err := wrapped(context.Background(), mcp.CallToolRequest{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		meta, ok := result.Meta[ResponseMetaKey].(ResponseMeta)
+		if !ok {
+			t.Fatalf("Expected response meta, got %v", result.Meta)
+		}
+		if meta.TotalTimeMs < 5 {
+			t.Errorf("Expected total time of at least 5ms, got %v", meta.TotalTimeMs)
+		}
+		if meta.EngineQueries != 0 {
+			t.Errorf("Expected no engine queries, got %d", meta.EngineQueries)
+		}
+	})
+
+	t.Run("ToolTimeout", func(t *testing.T) {
+		middleware := NewMiddleware(logger, metricsCollector, nil)
+		middleware.SetTimeouts(&config.TimeoutConfig{
+			DefaultSeconds: 60,
+			PerToolSeconds: map[string]int{"unbounded": 0},
+		})
+
+		var deadlines []bool
+		handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			_, ok := ctx.Deadline()
+			deadlines = append(deadlines, ok)
+			return mcp.NewToolResultText("success"), nil
+		}
+
+		_, _ = middleware.WrapTool("bounded", handler)(context.Background(), mcp.CallToolRequest{})
+		_, _ = middleware.WrapTool("unbounded", handler)(context.Background(), mcp.CallToolRequest{})
+
+		if len(deadlines) != 2 || !deadlines[0] || deadlines[1] {
+			t.Errorf("Expected deadline only for bounded tool, got %v", deadlines)
+		}
+	})
+
+	t.Run("ToolTimeoutExpires", func(t *testing.T) {
+		middleware := NewMiddleware(logger, metricsCollector, nil)
+		middleware.SetTimeouts(&config.TimeoutConfig{DefaultSeconds: 1})
+
+		handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			<-ctx.Done()
+			return nil, fmt.Errorf("analysis failed: %w", ctx.Err())
+		}
+
+		_, err := middleware.WrapTool("slowTool", handler)(context.Background(), mcp.CallToolRequest{})
+		if code := apperrors.CodeOf(err); code != apperrors.CodeTimeout {
+			t.Errorf("Expected code %s, got %s (%v)", apperrors.CodeTimeout, code, err)
+		}
+	})
+
 	t.Run("RateLimiting", func(t *testing.T) {
 		// Create a rate limiter with very low limits
 		cfg := &config.RateLimitConfig{
@@ -88,16 +157,26 @@ func TestMiddleware(t *testing.T) {
 			}
 		}
 
-		// Third call should be rate limited
+		// Third call should be rate limited, reported as an error result
 		result, err := wrapped(context.Background(), req)
-		if err == nil {
-			t.Error("Expected rate limit error, got nil")
+		if err != nil {
+			t.Fatalf("Expected a rate limited error result, got %v", err)
 		}
-		if result != nil {
-			t.Error("Expected nil result when rate limited")
+		if result == nil || !result.IsError {
+			t.Fatalf("Expected an error result when rate limited, got %+v", result)
 		}
-		if !contains(err.Error(), "rate limit exceeded") {
-			t.Errorf("Expected rate limit error, got: %v", err)
+		message := result.Content[0].(mcp.TextContent).Text
+		if !contains(message, "[RATE_LIMITED] rate limit exceeded") {
+			t.Errorf("Expected rate limit error, got: %s", message)
+		}
+		// Clients are told which bucket denied the call and when to retry,
+		// in the message and as structured fields
+		if !contains(message, "(bucket=global, retryAfterSeconds=1)") {
+			t.Errorf("Expected the bucket and retry delay in the message, got: %s", message)
+		}
+		meta, ok := result.Meta[ErrorMetaKey].(ErrorMeta)
+		if !ok || meta != (ErrorMeta{Code: apperrors.CodeRateLimited, Bucket: ratelimit.BucketGlobal, RetryAfterSeconds: 1}) {
+			t.Errorf("Expected the code, bucket and retry delay in _meta, got %+v", result.Meta)
 		}
 	})
 
@@ -116,14 +195,66 @@ func TestMiddleware(t *testing.T) {
 		}
 
 		result, err := wrapped(context.Background(), req)
-		if err != expectedErr {
+		if !errors.Is(err, expectedErr) {
 			t.Errorf("Expected %v, got %v", expectedErr, err)
 		}
+		if err == nil || err.Error() != "[INTERNAL] test error" {
+			t.Errorf("Expected error code prefix, got %v", err)
+		}
 		if result != nil {
 			t.Error("Expected nil result on error")
 		}
 	})
 
+	t.Run("Hooks", func(t *testing.T) {
+		middleware := NewMiddleware(logger, metricsCollector, nil)
+		middleware.SetTimeouts(&config.TimeoutConfig{DefaultSeconds: 60})
+
+		var order []string
+		record := func(name string) Hook {
+			return Hook{Name: name, Wrap: func(toolName string, next ToolHandler) ToolHandler {
+				return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+					order = append(order, name+":"+toolName)
+					return next(ctx, req)
+				}
+			}}
+		}
+		middleware.Use(record("audit"))
+		if err := middleware.UseBefore(HookRateLimit, record("auth")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := middleware.UseBefore("missing", record("quota")); err == nil {
+			t.Error("Expected an error inserting before an unknown hook")
+		}
+		want := []string{HookIdentity, HookTracing, HookUsage, HookSign, HookProgress, HookLogging, HookMetrics, "auth", HookRateLimit, HookQuota, HookLane, HookTimeout, HookCoords, "audit"}
+		if got := middleware.Hooks(); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Expected hooks %v, got %v", want, got)
+		}
+
+		// A tool can opt out of hooks, including built-in ones
+		middleware.Skip("internalTool", "audit", HookTimeout, HookUsage)
+		var deadline bool
+		handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			_, deadline = ctx.Deadline()
+			return mcp.NewToolResultText("success"), nil
+		}
+		result, err := middleware.WrapTool("internalTool", handler)(context.Background(), mcp.CallToolRequest{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if deadline || result.Meta != nil || fmt.Sprint(order) != "[auth:internalTool]" {
+			t.Errorf("Expected only auth to run, got deadline %v, meta %v and hooks %v", deadline, result.Meta, order)
+		}
+
+		order = nil
+		if _, err := middleware.WrapTool("testTool", handler)(context.Background(), mcp.CallToolRequest{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !deadline || fmt.Sprint(order) != "[auth:testTool audit:testTool]" {
+			t.Errorf("Expected every hook to run, got deadline %v and hooks %v", deadline, order)
+		}
+	})
+
 	t.Run("ClientIDExtraction", func(t *testing.T) {
 		middleware := NewMiddleware(logger, metricsCollector, nil)
 
@@ -161,7 +292,7 @@ func TestMiddleware(t *testing.T) {
 			handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 				callCount++
 				if callCount < 3 {
-					return nil, errors.New("temporary error")
+					return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
 				}
 				return mcp.NewToolResultText("success"), nil
 			}
@@ -185,7 +316,7 @@ func TestMiddleware(t *testing.T) {
 
 		t.Run("MaxRetriesExceeded", func(t *testing.T) {
 			handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-				return nil, errors.New("persistent error")
+				return nil, apperrors.New(apperrors.CodeTimeout, "query timeout")
 			}
 
 			wrapped := middleware.WrapToolWithRetry("testTool", handler, 2)
@@ -203,6 +334,49 @@ func TestMiddleware(t *testing.T) {
 			if !contains(err.Error(), "failed after 2 retries") {
 				t.Errorf("Expected retry exhaustion error, got: %v", err)
 			}
+			if code := apperrors.CodeOf(err); code != apperrors.CodeTimeout {
+				t.Errorf("Expected code %s, got %s", apperrors.CodeTimeout, code)
+			}
+		})
+
+		t.Run("NoRetryOnPermanentError", func(t *testing.T) {
+			callCount := 0
+			handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				callCount++
+				return nil, apperrors.New(apperrors.CodeInvalidSGF, "invalid SGF")
+			}
+
+			wrapped := middleware.WrapToolWithRetry("testTool", handler, 3)
+			_, err := wrapped(context.Background(), mcp.CallToolRequest{})
+			if err == nil || err.Error() != "[INVALID_SGF] invalid SGF" {
+				t.Errorf("Expected unretried SGF error, got %v", err)
+			}
+			if callCount != 1 {
+				t.Errorf("Expected 1 call (no retry), got %d", callCount)
+			}
+		})
+
+		t.Run("StopsOnContextCancel", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			callCount := 0
+			handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				callCount++
+				cancel()
+				return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
+			}
+
+			wrapped := middleware.WrapToolWithRetry("testTool", handler, 3)
+			start := time.Now()
+			_, err := wrapped(ctx, mcp.CallToolRequest{})
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected context canceled, got %v", err)
+			}
+			if callCount != 1 {
+				t.Errorf("Expected 1 call, got %d", callCount)
+			}
+			if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+				t.Errorf("Expected backoff to stop on cancel, took %v", elapsed)
+			}
 		})
 
 		t.Run("NoRetryOnRateLimit", func(t *testing.T) {
@@ -231,7 +405,7 @@ func TestMiddleware(t *testing.T) {
 
 			handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 				callTimes = append(callTimes, time.Now())
-				return nil, errors.New("error")
+				return nil, apperrors.New(apperrors.CodeEngineUnavailable, "engine not running")
 			}
 
 			wrapped := middleware.WrapToolWithRetry("testTool", handler, 2)
@@ -246,18 +420,18 @@ func TestMiddleware(t *testing.T) {
 				t.Errorf("Expected 3 calls, got %d", len(callTimes))
 			}
 
-			// First retry should have ~100ms backoff
+			// First retry should have ~100ms backoff (±10% jitter)
 			if len(callTimes) >= 2 {
 				firstBackoff := callTimes[1].Sub(callTimes[0])
-				if firstBackoff < 90*time.Millisecond || firstBackoff > 110*time.Millisecond {
+				if firstBackoff < 90*time.Millisecond || firstBackoff > 120*time.Millisecond {
 					t.Errorf("Expected ~100ms first backoff, got %v", firstBackoff)
 				}
 			}
 
-			// Second retry should have ~200ms backoff
+			// Second retry should have ~200ms backoff (±10% jitter)
 			if len(callTimes) >= 3 {
 				secondBackoff := callTimes[2].Sub(callTimes[1])
-				if secondBackoff < 190*time.Millisecond || secondBackoff > 210*time.Millisecond {
+				if secondBackoff < 180*time.Millisecond || secondBackoff > 230*time.Millisecond {
 					t.Errorf("Expected ~200ms second backoff, got %v", secondBackoff)
 				}
 			}
@@ -279,3 +453,349 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+// clientInfoSession is an MCP session that received client information in
+// its initialize request.
+type clientInfoSession struct {
+	info mcp.Implementation
+}
+
+func (s *clientInfoSession) Initialize()                                         {}
+func (s *clientInfoSession) Initialized() bool                                   { return true }
+func (s *clientInfoSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *clientInfoSession) SessionID() string                                   { return "session-1" }
+func (s *clientInfoSession) GetClientInfo() mcp.Implementation                   { return s.info }
+func (s *clientInfoSession) SetClientInfo(info mcp.Implementation)               { s.info = info }
+
+func TestClientIdentity(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	sessionCtx := mcpServer.WithContext(context.Background(),
+		&clientInfoSession{info: mcp.Implementation{Name: "claude-desktop", Version: "0.9.2"}})
+
+	var seen string
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		seen, _ = ClientIDFromContext(ctx)
+		return mcp.NewToolResultText("success"), nil
+	}
+	middleware := NewMiddleware(logger, metrics.NewCollector(), nil)
+	wrapped := middleware.WrapTool("testTool", handler)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		args map[string]interface{}
+		want string
+	}{
+		{"session client info", sessionCtx, nil, "claude-desktop/0.9.2"},
+		{"untrusted argument", sessionCtx, map[string]interface{}{"clientID": "arg-client"}, "claude-desktop/0.9.2"},
+		{"context", WithClientID(sessionCtx, "ctx-client"), nil, "ctx-client"},
+		{"no identity", context.Background(), nil, "anonymous"},
+	}
+	for _, tt := range tests {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.args}}
+		if _, err := wrapped(tt.ctx, req); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if seen != tt.want {
+			t.Errorf("%s: expected client %q, got %q", tt.name, tt.want, seen)
+		}
+	}
+
+	// A configured client ID overrides the session's
+	middleware = NewMiddleware(logger, metrics.NewCollector(), nil)
+	middleware.SetClientID("deployment-key")
+	if _, err := middleware.WrapTool("testTool", handler)(sessionCtx, mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if seen != "deployment-key" {
+		t.Errorf("Expected the configured client ID, got %q", seen)
+	}
+
+	// Behind a trusted gateway the argument identifies the client
+	middleware = NewMiddleware(logger, metrics.NewCollector(), nil)
+	middleware.SetTrustClientIDArgument(true)
+	wrapped = middleware.WrapTool("testTool", handler)
+	args := map[string]interface{}{"clientID": "arg-client"}
+	if _, err := wrapped(sessionCtx, mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if seen != "arg-client" {
+		t.Errorf("Expected the trusted argument, got %q", seen)
+	}
+	if _, err := wrapped(sessionCtx, mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if seen != "claude-desktop/0.9.2" {
+		t.Errorf("Expected the session's client without an argument, got %q", seen)
+	}
+}
+
+func TestClientNamespace(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	var namespace, cacheNamespace string
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		namespace, _ = NamespaceFromContext(ctx)
+		cacheNamespace, _ = katago.CacheNamespaceFromContext(ctx)
+		return mcp.NewToolResultText("success"), nil
+	}
+
+	for _, isolation := range []string{config.IsolationShared, config.IsolationStrict} {
+		middleware := NewMiddleware(logger, metrics.NewCollector(), nil)
+		middleware.SetNamespaces(&config.NamespaceConfig{
+			Isolation: isolation,
+			Clients:   map[string]string{"alice": "go-club"},
+		})
+		wrapped := middleware.WrapTool("testTool", handler)
+
+		for client, want := range map[string]string{"alice": "go-club", "bob": "bob"} {
+			if _, err := wrapped(WithClientID(context.Background(), client), mcp.CallToolRequest{}); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if namespace != want {
+				t.Errorf("Expected %s in namespace %q, got %q", client, want, namespace)
+			}
+			wantCache := ""
+			if isolation == config.IsolationStrict {
+				wantCache = want
+			}
+			if cacheNamespace != wantCache {
+				t.Errorf("%s isolation: expected cache namespace %q for %s, got %q", isolation, wantCache, client, cacheNamespace)
+			}
+		}
+	}
+}
+
+func TestSignResult(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	signer, err := provenance.NewSigner(&config.ProvenanceConfig{Enabled: true, Key: "0123456789abcdef"})
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{RootInfo: katago.RootInfo{Visits: 400}}, nil)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, err := engine.Analyze(ctx, &katago.AnalysisRequest{}); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText("Black leads by 2.5"), nil
+	}
+	middleware := NewMiddleware(logger, metrics.NewCollector(), nil)
+	middleware.SetSigner(signer, func(toolName string, req mcp.CallToolRequest) (*katago.AnalysisSettings, error) {
+		return &katago.AnalysisSettings{
+			Engine:        "default",
+			KataGoVersion: "1.15.3",
+			MaxVisits:     400,
+			Model:         &katago.ModelFile{Name: "kata1-b18c384nbt", SHA256: "abc123"},
+		}, nil
+	})
+
+	result, err := middleware.WrapTool("analyzePosition", handler)(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	record, ok := result.Meta[ProvenanceMetaKey].(provenance.Record)
+	if !ok {
+		t.Fatalf("Expected a provenance record, got %v", result.Meta)
+	}
+	if record.Tool != "analyzePosition" || record.Model != "kata1-b18c384nbt" || record.VisitsUsed != 400 {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+	if err := signer.Verify(&record, []string{"Black leads by 2.5"}); err != nil {
+		t.Errorf("Expected the record to verify, got %v", err)
+	}
+
+	// Results without analysis are not signed
+	plain := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("No sessions"), nil
+	}
+	result, err = middleware.WrapTool("listSessions", plain)(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := result.Meta[ProvenanceMetaKey]; ok {
+		t.Error("Expected no provenance for a result without analysis")
+	}
+}
+
+func TestQuota(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	tracker, err := quota.NewTracker(&config.QuotaConfig{
+		Enabled:     true,
+		QuotaLimits: config.QuotaLimits{DailyVisits: 150},
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to create tracker: %v", err)
+	}
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{RootInfo: katago.RootInfo{Visits: 100}}, nil)
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if _, err := engine.Analyze(ctx, &katago.AnalysisRequest{}); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText("success"), nil
+	}
+	middleware := NewMiddleware(logger, metrics.NewCollector(), nil)
+	middleware.SetQuota(tracker)
+	middleware.Skip("statusTool", HookQuota)
+	wrapped := middleware.WrapTool("testTool", handler)
+	ctx := WithClientID(context.Background(), "alice")
+
+	for i := 0; i < 2; i++ {
+		if _, err := wrapped(ctx, mcp.CallToolRequest{}); err != nil {
+			t.Fatalf("Call %d: expected to be within quota, got %v", i+1, err)
+		}
+	}
+	if used := tracker.Status("alice").Daily.Used; used != 200 {
+		t.Errorf("Expected 200 visits charged, got %d", used)
+	}
+
+	_, err = wrapped(ctx, mcp.CallToolRequest{})
+	if code := apperrors.CodeOf(err); code != apperrors.CodeQuotaExceeded {
+		t.Fatalf("Expected %s once the quota is used up, got %v", apperrors.CodeQuotaExceeded, err)
+	}
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) || exceeded.Period != quota.PeriodDay || exceeded.Limit != 150 {
+		t.Errorf("Expected the daily quota in the error, got %v", err)
+	}
+
+	// Other clients and tools that opt out are unaffected
+	if _, err := wrapped(WithClientID(context.Background(), "bob"), mcp.CallToolRequest{}); err != nil {
+		t.Errorf("Expected another client to be allowed, got %v", err)
+	}
+	if _, err := middleware.WrapTool("statusTool", handler)(ctx, mcp.CallToolRequest{}); err != nil {
+		t.Errorf("Expected a tool without the quota hook to be allowed, got %v", err)
+	}
+}
+
+func TestLanes(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	var lane string
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		lane = katago.LaneFromContext(ctx)
+		return mcp.NewToolResultText("success"), nil
+	}
+	middleware := NewMiddleware(logger, metrics.NewCollector(), nil)
+	middleware.SetLanes(map[string]string{"findMistakes": config.LaneBatch})
+
+	for tool, want := range map[string]string{"findMistakes": config.LaneBatch, "explainMove": config.LaneInteractive} {
+		if _, err := middleware.WrapTool(tool, handler)(context.Background(), mcp.CallToolRequest{}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if lane != want {
+			t.Errorf("Expected %s in the %s lane, got %s", tool, want, lane)
+		}
+	}
+}
+
+func TestReportProgress(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	middleware := NewMiddleware(logger, metrics.NewCollector(), nil)
+	var sent []map[string]any
+	middleware.notify = func(ctx context.Context, method string, params map[string]any) error {
+		if method != "notifications/progress" {
+			t.Errorf("Unexpected notification %s", method)
+		}
+		sent = append(sent, params)
+		return nil
+	}
+
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		report := katago.ProgressFromContext(ctx)
+		if report == nil {
+			return mcp.NewToolResultText("no progress"), nil
+		}
+		report(1, 3, "Analyzed 1 of 3 positions")
+		report(2, 3, "Analyzed 2 of 3 positions") // Within progressInterval of the first
+		report(1, 3, "Analyzed 1 of 3 positions") // A retry starting over
+		report(3, 3, "Analyzed 3 of 3 positions")
+		return mcp.NewToolResultText("done"), nil
+	}
+	wrapped := middleware.WrapTool("findMistakes", handler)
+
+	// Without a progress token the client hears nothing
+	if _, err := wrapped(context.Background(), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("Expected no notifications without a token, got %v", sent)
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Meta = &mcp.Meta{ProgressToken: "review-1"}
+	if _, err := wrapped(context.Background(), request); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sent) != 2 || sent[0]["progress"] != 1.0 || sent[1]["progress"] != 3.0 {
+		t.Fatalf("Expected the first and final progress, got %v", sent)
+	}
+	if sent[1]["progressToken"] != "review-1" || sent[1]["total"] != 3.0 || sent[1]["message"] != "Analyzed 3 of 3 positions" {
+		t.Errorf("Unexpected final notification: %v", sent[1])
+	}
+}
+
+func TestCoordSystem(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	middleware := NewMiddleware(logger, metrics.NewCollector(), nil)
+	middleware.SetBoardSizes(func(ctx context.Context, request mcp.CallToolRequest) int { return 9 })
+
+	var args map[string]interface{}
+	handler := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args = req.Params.Arguments.(map[string]interface{})
+		if args["move"] == "J1" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "illegal move J1: point is occupied")
+		}
+		return mcp.NewToolResultText("Best move E5, then C3 or pass"), nil
+	}
+	wrapped := middleware.WrapTool("whatIf", handler)
+	call := func(arguments map[string]interface{}) (string, error) {
+		result, err := wrapped(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: arguments}})
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	sent := map[string]interface{}{
+		"coordSystem": "sgf",
+		"move":        "ee",
+		"moves":       []interface{}{"cc", "tt"},
+		"allowMoves":  []interface{}{"aa-ee", "top-left"},
+		"position": map[string]interface{}{
+			"moves": []interface{}{map[string]interface{}{"color": "b", "location": "gg"}},
+		},
+	}
+	text, err := call(sent)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text != "Best move ee, then cg or pass" {
+		t.Errorf("Expected the result in SGF coordinates, got %q", text)
+	}
+	moves := args["position"].(map[string]interface{})["moves"].([]interface{})
+	if args["move"] != "E5" || fmt.Sprint(args["moves"]) != "[C7 pass]" || fmt.Sprint(args["allowMoves"]) != "[A9-E5 top-left]" ||
+		moves[0].(map[string]interface{})["location"] != "G3" {
+		t.Errorf("Expected GTP arguments, got %v", args)
+	}
+	if sent["move"] != "ee" {
+		t.Error("Expected the caller's arguments to be left alone")
+	}
+
+	if _, err := call(map[string]interface{}{"coordSystem": "xy", "move": "(8,8)"}); err == nil || !strings.Contains(err.Error(), "illegal move (8,8)") ||
+		apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected the error in xy coordinates, got %v", err)
+	}
+	if _, err := call(map[string]interface{}{"coordSystem": "xy", "move": "(9,9)"}); apperrors.CodeOf(err) != apperrors.CodeBadCoordinate {
+		t.Errorf("Expected %s for a point off the board, got %v", apperrors.CodeBadCoordinate, err)
+	}
+	if _, err := call(map[string]interface{}{"coordSystem": "ascii"}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for an unknown system, got %v", apperrors.CodeInvalidArgument, err)
+	}
+	if text, _ := call(map[string]interface{}{"move": "E5"}); text != "Best move E5, then C3 or pass" {
+		t.Errorf("Expected GTP by default, got %q", text)
+	}
+}
@@ -0,0 +1,402 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/session"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// SetSessions sets the manager for interactive study sessions.
+func (h *ToolsHandler) SetSessions(sessions *session.Manager) {
+	h.sessions = sessions
+}
+
+// registerSessionTools registers the study session tools.
+func (h *ToolsHandler) registerSessionTools(s *server.MCPServer) {
+	sessionID := mcp.WithString("sessionId",
+		mcp.Description("Session ID returned by loadGame"),
+		mcp.Required(),
+	)
+
+	tools := []struct {
+		tool    mcp.Tool
+		handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
+	}{
+		{mcp.NewTool("loadGame",
+			mcp.WithDescription("Load an SGF into a study session and return its sessionId, so later calls can navigate and analyze without resending the game"),
+			mcp.WithString("sgf",
+				mcp.Description("SGF content of the game"),
+				mcp.Required(),
+			),
+			mcp.WithNumber("moveNumber",
+				mcp.Description("Move to start at (default: the final position)"),
+			),
+			mcp.WithNumber("ttlSeconds",
+				mcp.Description("Idle time before the session expires (default and maximum: from config)"),
+			),
+			withCoordSystem(),
+		), h.HandleLoadGame},
+		{mcp.NewTool("closeGame",
+			mcp.WithDescription("Close a study session"),
+			sessionID,
+		), h.HandleCloseGame},
+		{mcp.NewTool("nextMove",
+			mcp.WithDescription("Step forward in a study session"),
+			sessionID,
+			mcp.WithNumber("count",
+				mcp.Description("Moves to step forward (default: 1)"),
+			),
+			withCoordSystem(),
+		), h.HandleNextMove},
+		{mcp.NewTool("prevMove",
+			mcp.WithDescription("Step back in a study session"),
+			sessionID,
+			mcp.WithNumber("count",
+				mcp.Description("Moves to step back (default: 1)"),
+			),
+			withCoordSystem(),
+		), h.HandlePrevMove},
+		{mcp.NewTool("gotoMove",
+			mcp.WithDescription("Jump to a move in a study session"),
+			sessionID,
+			mcp.WithNumber("moveNumber",
+				mcp.Description("Number of moves played at the target node (0 for the start)"),
+				mcp.Required(),
+			),
+			withCoordSystem(),
+		), h.HandleGotoMove},
+		{mcp.NewTool("playMove",
+			mcp.WithDescription("Play a move for the side to move in a study session. A move that differs from the game starts a variation."),
+			sessionID,
+			mcp.WithString("move",
+				mcp.Description("Move to play (e.g., 'D4', 'pass')"),
+				mcp.Required(),
+			),
+			withCoordSystem(),
+		), h.HandlePlayMove},
+		{mcp.NewTool("analyzeHere",
+			mcp.WithDescription("Analyze the current position of a study session"),
+			sessionID,
+			mcp.WithNumber("maxVisits",
+				mcp.Description("Maximum visits for analysis (overrides default)"),
+			),
+			mcp.WithBoolean("verbose",
+				mcp.Description("Include a table with LCB, utility, score stdev and prior, and PVs annotated with their visits"),
+			),
+			mcp.WithString("sortBy",
+				mcp.Description("Candidate move order: 'visits' or 'lcb' (default: from config)"),
+				mcp.Enum(katago.SortByVisits, katago.SortByLCB),
+			),
+			withSearchSettings(),
+			withAverageSymmetries(),
+			withProfile(),
+			withCoordSystem(),
+		), h.HandleAnalyzeHere},
+	}
+
+	for _, t := range tools {
+		handler := t.handler
+		if h.middleware != nil {
+			handler = h.middleware.WrapTool(t.tool.Name, handler)
+		}
+		s.AddTool(t.tool, handler)
+	}
+
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate("katago://sessions/{clientId}", "Study sessions",
+			mcp.WithTemplateDescription("Open study sessions of a client, most recently used first"),
+			mcp.WithTemplateMIMEType("application/json"),
+		),
+		h.HandleSessionsResource,
+	)
+}
+
+// HandleLoadGame handles the loadGame tool.
+func (h *ToolsHandler) HandleLoadGame(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "loadGame")
+
+	logger.Info("Handling loadGame request")
+
+	if h.sessions == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "study sessions are not enabled")
+	}
+
+	argsMap, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	sgf, ok := argsMap["sgf"].(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	game, err := katago.NewSGFParser(sgf).Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+
+	moveNumber := -1
+	if val, ok := argsMap["moveNumber"].(float64); ok {
+		moveNumber = int(val)
+	}
+
+	var ttl time.Duration
+	if val, ok := argsMap["ttlSeconds"].(float64); ok {
+		ttl = time.Duration(val * float64(time.Second))
+	}
+
+	sess, err := h.sessions.Load(extractClientID(ctx, request), game, moveNumber, ttl)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Game loaded", "session", sess.ID, "moves", len(game.Moves))
+
+	return mcp.NewToolResultText(formatSessionState(sess.State())), nil
+}
+
+// HandleCloseGame handles the closeGame tool.
+func (h *ToolsHandler) HandleCloseGame(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "closeGame")
+
+	logger.Info("Handling closeGame request")
+
+	if h.sessions == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "study sessions are not enabled")
+	}
+	argsMap, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+	id, ok := argsMap["sessionId"].(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sessionId'")
+	}
+	if err := h.sessions.Close(extractClientID(ctx, request), id); err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Closed study session %s", id)), nil
+}
+
+// HandleSessionsResource lists a client's open study sessions as JSON.
+func (h *ToolsHandler) HandleSessionsResource(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	if h.sessions == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "study sessions are not enabled")
+	}
+
+	var clientID string
+	switch val := request.Params.Arguments["clientId"].(type) {
+	case string:
+		clientID = val
+	case []string:
+		if len(val) > 0 {
+			clientID = val[0]
+		}
+	}
+	if clientID == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing client ID in %s", request.Params.URI)
+	}
+
+	data, err := json.MarshalIndent(h.sessions.List(clientID), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sessions: %w", err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
+
+// HandleNextMove handles the nextMove tool.
+func (h *ToolsHandler) HandleNextMove(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return h.navigateSession(ctx, request, "nextMove", func(sess *session.Session, argsMap map[string]interface{}) error {
+		return sess.Next(sessionCount(argsMap))
+	})
+}
+
+// HandlePrevMove handles the prevMove tool.
+func (h *ToolsHandler) HandlePrevMove(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return h.navigateSession(ctx, request, "prevMove", func(sess *session.Session, argsMap map[string]interface{}) error {
+		return sess.Prev(sessionCount(argsMap))
+	})
+}
+
+// HandleGotoMove handles the gotoMove tool.
+func (h *ToolsHandler) HandleGotoMove(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return h.navigateSession(ctx, request, "gotoMove", func(sess *session.Session, argsMap map[string]interface{}) error {
+		moveNumber, ok := argsMap["moveNumber"].(float64)
+		if !ok {
+			return apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'moveNumber'")
+		}
+		return sess.Goto(int(moveNumber))
+	})
+}
+
+// HandlePlayMove handles the playMove tool.
+func (h *ToolsHandler) HandlePlayMove(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return h.navigateSession(ctx, request, "playMove", func(sess *session.Session, argsMap map[string]interface{}) error {
+		move, ok := argsMap["move"].(string)
+		if !ok {
+			return apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'move'")
+		}
+		return sess.Play(move)
+	})
+}
+
+// HandleAnalyzeHere handles the analyzeHere tool.
+func (h *ToolsHandler) HandleAnalyzeHere(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "analyzeHere")
+
+	logger.Info("Handling analyzeHere request")
+
+	sess, argsMap, err := h.sessionFor(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := h.engineFor("analyzeHere", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	req := &katago.AnalysisRequest{Position: sess.Position()}
+	if maxVisits := h.maxVisits("analyzeHere", argsMap); maxVisits > 0 {
+		req.MaxVisits = &maxVisits
+	}
+	if maxTime := h.maxTime("analyzeHere", argsMap); maxTime > 0 {
+		req.MaxTime = &maxTime
+	}
+	req.Search, err = searchSettings(argsMap)
+	if err != nil {
+		return nil, err
+	}
+	req.AverageSymmetries, _ = argsMap["averageSymmetries"].(bool)
+	verbose, _ := argsMap["verbose"].(bool)
+	req.IncludePVVisits = verbose
+
+	sortBy := h.sortMoves
+	if val, ok := argsMap["sortBy"]; ok {
+		sortBy, _ = val.(string)
+		if sortBy != katago.SortByVisits && sortBy != katago.SortByLCB {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "sortBy must be 'visits' or 'lcb'")
+		}
+	}
+
+	result, err := engine.Analyze(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("analysis failed: %w", err)
+	}
+	result.MoveInfos = katago.SortMoveInfos(result.MoveInfos, sortBy)
+
+	state := sess.State()
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Analysis at move %d", state.MoveNumber))
+	if state.LastMove != "" {
+		sb.WriteString(fmt.Sprintf(" (%s %s)", state.LastColor, state.LastMove))
+	}
+	sb.WriteString(fmt.Sprintf(", %s to play\n\n", state.ToPlay))
+	sb.WriteString(katago.FormatAnalysisResult(result, verbose, req.Position.BoardXSize))
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// navigateSession runs a navigation step on the requested session and
+// returns the resulting state.
+func (h *ToolsHandler) navigateSession(ctx context.Context, request mcp.CallToolRequest, tool string,
+	step func(*session.Session, map[string]interface{}) error) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", tool)
+
+	logger.Info("Handling " + tool + " request")
+
+	sess, argsMap, err := h.sessionFor(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := step(sess, argsMap); err != nil {
+		return nil, err
+	}
+
+	state := sess.State()
+	logger.Debug("Session moved", "session", sess.ID, "moveNumber", state.MoveNumber)
+	return mcp.NewToolResultText(formatSessionState(state)), nil
+}
+
+// sessionFor returns the calling client's session named by the request's
+// sessionId argument.
+func (h *ToolsHandler) sessionFor(ctx context.Context, request mcp.CallToolRequest) (*session.Session, map[string]interface{}, error) {
+	if h.sessions == nil {
+		return nil, nil, apperrors.New(apperrors.CodeInvalidArgument, "study sessions are not enabled")
+	}
+	argsMap, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return nil, nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+	id, ok := argsMap["sessionId"].(string)
+	if !ok {
+		return nil, nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sessionId'")
+	}
+	sess, err := h.sessions.Get(extractClientID(ctx, request), id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sess, argsMap, nil
+}
+
+// sessionCount reads the optional count argument, defaulting to 1.
+func sessionCount(argsMap map[string]interface{}) int {
+	if val, ok := argsMap["count"].(float64); ok && val >= 1 {
+		return int(val)
+	}
+	return 1
+}
+
+// formatSessionState formats a session's current node with its board.
+func formatSessionState(state *session.State) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Study Session %s\n\n", state.SessionID))
+	if state.PlayerBlack != "" || state.PlayerWhite != "" {
+		sb.WriteString(fmt.Sprintf("Black: %s, White: %s\n", state.PlayerBlack, state.PlayerWhite))
+	}
+	sb.WriteString(fmt.Sprintf("Move %d of %d", state.MoveNumber, state.TotalMoves))
+	if state.LastMove != "" {
+		sb.WriteString(fmt.Sprintf(": %s %s", state.LastColor, state.LastMove))
+	}
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("To play: %s\n", state.ToPlay))
+	sb.WriteString(fmt.Sprintf("Captures: Black %d, White %d\n", state.BlackCaptures, state.WhiteCaptures))
+	if state.Variation {
+		sb.WriteString(fmt.Sprintf("Variation: leaves the game (%d moves) at move %d\n", state.GameMoves, state.DivergesAt))
+	}
+	sb.WriteString("\n```\n")
+	sb.WriteString(state.Board)
+	sb.WriteString("```\n")
+	return sb.String()
+}
@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/session"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestStudySessionTools(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{
+		MoveInfos: []katago.MoveInfo{{Move: "C7", Visits: 100, Winrate: 0.5}},
+		RootInfo:  katago.RootInfo{Visits: 100, Winrate: 0.5},
+	}, nil)
+	handler := NewToolsHandler(engine, logger)
+	handler.SetSessions(session.NewManager(&config.SessionConfig{}, logger))
+
+	call := func(handle func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]interface{}) (string, error) {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+		result, err := handle(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(handler.HandleLoadGame, map[string]interface{}{
+		"sgf":        "(;GM[1]FF[4]SZ[9]PB[Alice]PW[Bob];B[ee];W[cc];B[gg])",
+		"moveNumber": 1.0,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "Move 1 of 3: B E5") {
+		t.Errorf("Expected move 1, got %q", text)
+	}
+	id := strings.TrimSpace(strings.SplitN(strings.TrimPrefix(text, "# Study Session "), "\n", 2)[0])
+
+	text, err = call(handler.HandleNextMove, map[string]interface{}{"sessionId": id, "count": 2.0})
+	if err != nil || !strings.Contains(text, "Move 3 of 3: B G3") {
+		t.Errorf("Expected move 3, got %q (%v)", text, err)
+	}
+	text, err = call(handler.HandleGotoMove, map[string]interface{}{"sessionId": id, "moveNumber": 2.0})
+	if err != nil || !strings.Contains(text, "To play: B") {
+		t.Errorf("Expected move 2 with B to play, got %q (%v)", text, err)
+	}
+	text, err = call(handler.HandlePlayMove, map[string]interface{}{"sessionId": id, "move": "D7"})
+	if err != nil || !strings.Contains(text, "Variation: leaves the game (3 moves) at move 3") {
+		t.Errorf("Expected a variation, got %q (%v)", text, err)
+	}
+	text, err = call(handler.HandlePrevMove, map[string]interface{}{"sessionId": id})
+	if err != nil || !strings.Contains(text, "Move 2 of 3: W C7") {
+		t.Errorf("Expected move 2, got %q (%v)", text, err)
+	}
+
+	text, err = call(handler.HandleAnalyzeHere, map[string]interface{}{"sessionId": id})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(text, "# Analysis at move 2 (W C7), B to play") {
+		t.Errorf("Unexpected analysis header: %q", text)
+	}
+	if got := len(engine.GetLastAnalyzeRequest().Position.Moves); got != 2 {
+		t.Errorf("Expected the session position to be analyzed, got %d moves", got)
+	}
+
+	_, err = call(handler.HandleNextMove, map[string]interface{}{"sessionId": "game_missing"})
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for an unknown session, got %v", apperrors.CodeInvalidArgument, err)
+	}
+
+	// Sessions outlive the engine process
+	if err := engine.Stop(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := call(handler.HandleAnalyzeHere, map[string]interface{}{"sessionId": id}); err != nil {
+		t.Errorf("Expected analysis after an engine restart, got %v", err)
+	}
+}
+
+func TestStudySessionIsolation(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	handler := NewToolsHandler(katago.NewMockEngine(), logger)
+	handler.SetSessions(session.NewManager(&config.SessionConfig{}, logger))
+
+	req := func(args map[string]interface{}) mcp.CallToolRequest {
+		return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+	}
+	alice := WithClientID(context.Background(), "alice")
+	result, err := handler.HandleLoadGame(alice, req(map[string]interface{}{
+		"sgf": "(;GM[1]FF[4]SZ[9];B[ee];W[cc])",
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	id := strings.TrimSpace(strings.SplitN(strings.TrimPrefix(text, "# Study Session "), "\n", 2)[0])
+
+	_, err = handler.HandlePrevMove(WithClientID(context.Background(), "bob"), req(map[string]interface{}{"sessionId": id}))
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected another client's session to be unknown, got %v", err)
+	}
+
+	read := func(clientID string) []session.Info {
+		t.Helper()
+		request := mcp.ReadResourceRequest{}
+		request.Params.URI = "katago://sessions/" + clientID
+		request.Params.Arguments = map[string]any{"clientId": []string{clientID}}
+		contents, err := handler.HandleSessionsResource(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var infos []session.Info
+		if err := json.Unmarshal([]byte(contents[0].(mcp.TextResourceContents).Text), &infos); err != nil {
+			t.Fatalf("Failed to decode sessions: %v", err)
+		}
+		return infos
+	}
+	if infos := read("alice"); len(infos) != 1 || infos[0].SessionID != id || infos[0].MoveNumber != 2 {
+		t.Errorf("Expected alice's session, got %+v", infos)
+	}
+	if infos := read("bob"); len(infos) != 0 {
+		t.Errorf("Expected no sessions for bob, got %+v", infos)
+	}
+
+	if _, err := handler.HandleCloseGame(alice, req(map[string]interface{}{"sessionId": id})); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if infos := read("alice"); len(infos) != 0 {
+		t.Errorf("Expected no sessions after close, got %+v", infos)
+	}
+}
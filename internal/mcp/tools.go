@@ -1,14 +1,28 @@
 package mcp
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/cache"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/coords"
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
 	"github.com/dmmcquay/katago-mcp/internal/katago"
 	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/monitor"
+	"github.com/dmmcquay/katago-mcp/internal/quota"
+	"github.com/dmmcquay/katago-mcp/internal/report"
+	"github.com/dmmcquay/katago-mcp/internal/session"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -16,34 +30,281 @@ import (
 // ToolsHandler manages MCP tools for KataGo.
 type ToolsHandler struct {
 	engine     katago.EngineInterface
+	engines    map[string]katago.EngineInterface
+	routes     map[string]string
 	logger     logging.ContextLogger
 	middleware *Middleware
+	cache      *cache.Manager
+	monitor    *monitor.Monitor
+	sessions   *session.Manager
+	quota      *quota.Tracker
+	namespaces *config.NamespaceConfig
+	sortMoves  string
+	language   string
+	engineCfgs map[string]*config.KataGoConfig
+	cacheCfg   *config.CacheConfig
+	reviewCfg  *config.ReviewConfig
+	toolCfgs   map[string]config.ToolConfig
 }
 
 // NewToolsHandler creates a new tools handler.
 func NewToolsHandler(engine katago.EngineInterface, logger logging.ContextLogger) *ToolsHandler {
 	return &ToolsHandler{
-		engine: engine,
-		logger: logger,
+		engine:  engine,
+		engines: map[string]katago.EngineInterface{config.DefaultEngineName: engine},
+		logger:  logger,
 	}
 }
 
+// SetEngines sets the named engines available to tools and the tool to
+// engine routing. The engine named "default" replaces the handler's engine.
+func (h *ToolsHandler) SetEngines(engines map[string]katago.EngineInterface, routes map[string]string) {
+	h.engines = engines
+	h.routes = routes
+	if engine, ok := engines[config.DefaultEngineName]; ok {
+		h.engine = engine
+	}
+}
+
+// engineFor selects the engine for a tool call: the engine named by the
+// request's profile argument, else the tool's routed engine, else the
+// default engine.
+func (h *ToolsHandler) engineFor(tool string, request mcp.CallToolRequest) (katago.EngineInterface, error) {
+	name, err := h.engineNameFor(tool, request)
+	if err != nil {
+		return nil, err
+	}
+	if engine, ok := h.engines[name]; ok {
+		return engine, nil
+	}
+	return h.engine, nil
+}
+
+// engineNameFor returns the name of the engine engineFor selects.
+func (h *ToolsHandler) engineNameFor(tool string, request mcp.CallToolRequest) (string, error) {
+	if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if val, ok := argsMap["profile"]; ok {
+			profile, ok := val.(string)
+			if !ok {
+				return "", apperrors.New(apperrors.CodeInvalidArgument, "profile must be a string")
+			}
+			if _, err := h.profileEngine(profile); err != nil {
+				return "", err
+			}
+			return profile, nil
+		}
+	}
+	if _, ok := h.engines[h.routes[tool]]; ok {
+		return h.routes[tool], nil
+	}
+	return config.DefaultEngineName, nil
+}
+
+// profileEngine returns the named engine.
+func (h *ToolsHandler) profileEngine(profile string) (katago.EngineInterface, error) {
+	engine, ok := h.engines[profile]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "unknown engine profile: %s", profile)
+	}
+	return engine, nil
+}
+
+// withProfile adds the optional profile argument to a tool.
+func withProfile() mcp.ToolOption {
+	return mcp.WithString("profile",
+		mcp.Description("Named engine to use (default: the tool's configured engine)"),
+	)
+}
+
+// withLanguage adds the optional output language argument to a tool.
+func withLanguage() mcp.ToolOption {
+	return mcp.WithString("language",
+		mcp.Description(fmt.Sprintf("Language for explanations: %s (default: from config)",
+			strings.Join(i18n.Languages(), ", "))),
+	)
+}
+
+// withCoordSystem adds the optional coordinate system argument to a tool.
+// The middleware converts moves in the arguments and result.
+func withCoordSystem() mcp.ToolOption {
+	return mcp.WithString("coordSystem",
+		mcp.Description("Coordinates of moves in the arguments and result: 'gtp' (D4), 'sgf' (dd, from the top left) or 'xy' ((3,15), zero-based column and row from the top left) (default: gtp)"),
+		mcp.Enum(coords.Systems()...),
+	)
+}
+
+// printerFor returns the printer for the request's language argument, or
+// the configured language.
+func (h *ToolsHandler) printerFor(argsMap map[string]interface{}) (*i18n.Printer, error) {
+	lang := h.language
+	if val, ok := argsMap["language"]; ok {
+		s, ok := val.(string)
+		if !ok {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "language must be a string")
+		}
+		lang = s
+	}
+	return i18n.NewPrinter(lang)
+}
+
+// withSearchSettings adds the search diversity arguments to a tool.
+func withSearchSettings() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber("wideRootNoise",
+			mcp.Description("Extra exploration of less likely moves at the root, 0 to 1 (default: from config)"),
+			mcp.Min(0),
+			mcp.Max(config.MaxWideRootNoise),
+		)(t)
+		mcp.WithNumber("rootPolicyTemperature",
+			mcp.Description("Root policy temperature; above 1 spreads visits over more moves (default: from config)"),
+			mcp.Min(config.MinRootPolicyTemperature),
+			mcp.Max(config.MaxRootPolicyTemperature),
+		)(t)
+		mcp.WithNumber("analysisPVLen",
+			mcp.Description("Maximum moves in each principal variation (default: from config)"),
+			mcp.Min(1),
+			mcp.Max(config.MaxAnalysisPVLen),
+		)(t)
+	}
+}
+
+// withAverageSymmetries adds the symmetry averaging argument to a tool.
+func withAverageSymmetries() mcp.ToolOption {
+	return mcp.WithBoolean("averageSymmetries",
+		mcp.Description("Analyze under all 8 board symmetries (4 on rectangular boards) and average the results, removing orientation bias in openings. Costs one search per symmetry."),
+	)
+}
+
+// searchSettings reads the search diversity arguments.
+func searchSettings(argsMap map[string]interface{}) (config.SearchConfig, error) {
+	var search config.SearchConfig
+	if val, ok := argsMap["wideRootNoise"].(float64); ok {
+		search.WideRootNoise = &val
+	}
+	if val, ok := argsMap["rootPolicyTemperature"].(float64); ok {
+		search.RootPolicyTemperature = &val
+	}
+	if val, ok := argsMap["analysisPVLen"].(float64); ok {
+		pvLen := int(val)
+		search.AnalysisPVLen = &pvLen
+	}
+	if err := search.Validate(); err != nil {
+		return search, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "invalid search settings")
+	}
+	return search, nil
+}
+
 // SetMiddleware sets the middleware for the tools handler.
 func (h *ToolsHandler) SetMiddleware(middleware *Middleware) {
 	h.middleware = middleware
 }
 
+// SetCache sets the cache manager used by the cache administration tools.
+func (h *ToolsHandler) SetCache(cacheManager *cache.Manager) {
+	h.cache = cacheManager
+}
+
+// SetNamespaces sets the namespace settings deciding which clients may use
+// the admin tools, and what clearCache clears for the others.
+func (h *ToolsHandler) SetNamespaces(namespaces *config.NamespaceConfig) {
+	h.namespaces = namespaces
+}
+
+// admin reports whether the client making a call may use the admin tools.
+func (h *ToolsHandler) admin(ctx context.Context, request mcp.CallToolRequest) bool {
+	return h.namespaces == nil || h.namespaces.Admin(extractClientID(ctx, request))
+}
+
+// requireAdmin rejects a call to an admin tool from any other client. The
+// admin tools show the raw queries of every namespace.
+func (h *ToolsHandler) requireAdmin(ctx context.Context, request mcp.CallToolRequest, tool string) error {
+	if h.admin(ctx, request) {
+		return nil
+	}
+	return apperrors.New(apperrors.CodePermissionDenied, "%s is only available to admin clients", tool)
+}
+
+// SetOutput sets analysis output options.
+func (h *ToolsHandler) SetOutput(output *config.OutputConfig) {
+	h.sortMoves = output.SortMovesBy
+	h.language = output.Language
+}
+
+// SetConfig sets the configuration reported by getAnalysisSettings, the
+// review settings used by findMistakes and each tool's default search.
+func (h *ToolsHandler) SetConfig(cfg *config.Config) {
+	h.engineCfgs = cfg.EngineConfigs()
+	h.cacheCfg = &cfg.Cache
+	h.reviewCfg = &cfg.Review
+	h.toolCfgs = cfg.Tools
+}
+
+// maxVisits returns a tool call's maxVisits argument, or the visits
+// configured for the tool when it sets none. 0 leaves them to the tool.
+func (h *ToolsHandler) maxVisits(tool string, argsMap map[string]interface{}) int {
+	switch v := argsMap["maxVisits"].(type) {
+	case float64:
+		if v > 0 {
+			return int(v)
+		}
+	case int:
+		if v > 0 {
+			return v
+		}
+	}
+	return h.toolCfgs[tool].MaxVisits
+}
+
+// maxTime returns a tool call's maxTime argument, or the time configured
+// for the tool when it sets none. 0 leaves it to the engine.
+func (h *ToolsHandler) maxTime(tool string, argsMap map[string]interface{}) float64 {
+	switch v := argsMap["maxTime"].(type) {
+	case float64:
+		if v > 0 {
+			return v
+		}
+	case int:
+		if v > 0 {
+			return float64(v)
+		}
+	}
+	return h.toolCfgs[tool].MaxTime
+}
+
+// SetQuota sets the tracker of client visit quotas reported by getQuota.
+func (h *ToolsHandler) SetQuota(tracker *quota.Tracker) {
+	h.quota = tracker
+}
+
+// SetMonitor sets the resource monitor reported by getEngineStatus.
+func (h *ToolsHandler) SetMonitor(resourceMonitor *monitor.Monitor) {
+	h.monitor = resourceMonitor
+}
+
 // RegisterTools registers all tools with the MCP server.
 func (h *ToolsHandler) RegisterTools(s *server.MCPServer) {
 	// Register analyzePosition tool
 	analyzePositionTool := mcp.NewTool("analyzePosition",
-		mcp.WithDescription("Analyze a Go position using KataGo. Provide either SGF content or a position object."),
+		mcp.WithDescription("Analyze a Go position using KataGo. Provide SGF content, a position object or a board pasted as text."),
 		mcp.WithString("sgf",
 			mcp.Description("SGF content to analyze"),
 		),
 		mcp.WithObject("position",
 			mcp.Description("Position object with rules, board size, moves, etc."),
 		),
+		mcp.WithString("board",
+			mcp.Description("Board as text: a diagram with one row per line using '.' for empty points, 'X' for Black and 'O' for White (GTP showboard and Sensei's Library diagrams work too), or a GTP stone list such as 'B D4 W Q16'"),
+		),
+		mcp.WithNumber("boardSize",
+			mcp.Description("Board size for board input (default: the diagram's size when it shows the whole board, otherwise 19)"),
+		),
+		mcp.WithString("toPlay",
+			mcp.Description("Player to move for board input: 'B' or 'W' (default: B)"),
+			mcp.Enum("B", "W"),
+		),
+		mcp.WithNumber("komi",
+			mcp.Description("Komi for board input (default: 7.5)"),
+		),
 		mcp.WithNumber("moveNumber",
 			mcp.Description("Move number to analyze (for SGF input). If not specified, analyzes the final position."),
 		),
@@ -59,9 +320,41 @@ func (h *ToolsHandler) RegisterTools(s *server.MCPServer) {
 		mcp.WithBoolean("includeOwnership",
 			mcp.Description("Include ownership map"),
 		),
+		mcp.WithBoolean("includeMovesOwnership",
+			mcp.Description("Include the ownership map after each candidate move, which can be several megabytes of JSON"),
+		),
+		mcp.WithBoolean("includePVVisits",
+			mcp.Description("Include the visits of each move of every PV, showing how far KataGo's lines can be trusted (default: on when verbose)"),
+		),
 		mcp.WithBoolean("verbose",
-			mcp.Description("Include more detailed output"),
+			mcp.Description("Include more detailed output: a table with LCB, utility, score stdev and prior, PVs annotated with their visits, and raw root values"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text unless policy or ownership is requested)"),
+			mcp.Enum("text", "json"),
+		),
+		mcp.WithString("sortBy",
+			mcp.Description("Candidate move order: 'visits' or 'lcb' (default: from config)"),
+			mcp.Enum(katago.SortByVisits, katago.SortByLCB),
 		),
+		mcp.WithString("humanProfile",
+			mcp.Description("Human SL profile to condition on, e.g. 'rank_5k' (requires a human model)"),
+		),
+		mcp.WithArray("avoidMoves",
+			mcp.Description("Moves or regions not to consider for the next move: coordinates ('D4'), rectangles ('C3-F6') or named areas ('top-left', 'bottom', 'center')"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray("allowMoves",
+			mcp.Description("Only consider the next move within these moves or regions, using the same syntax as avoidMoves"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithBoolean("policyOnly",
+			mcp.Description("Skip search and return only the raw policy and value estimate as compact JSON, for fast bulk screening. Other analysis and output options are ignored."),
+		),
+		withSearchSettings(),
+		withAverageSymmetries(),
+		withProfile(),
+		withCoordSystem(),
 	)
 	handler := h.HandleAnalyzePosition
 	if h.middleware != nil {
@@ -72,6 +365,7 @@ func (h *ToolsHandler) RegisterTools(s *server.MCPServer) {
 	// Register getEngineStatus tool
 	getEngineStatusTool := mcp.NewTool("getEngineStatus",
 		mcp.WithDescription("Get the status of the KataGo engine"),
+		withProfile(),
 	)
 	statusHandler := h.HandleGetEngineStatus
 	if h.middleware != nil {
@@ -79,9 +373,38 @@ func (h *ToolsHandler) RegisterTools(s *server.MCPServer) {
 	}
 	s.AddTool(getEngineStatusTool, statusHandler)
 
+	// Register getAnalysisSettings tool
+	getAnalysisSettingsTool := mcp.NewTool("getAnalysisSettings",
+		mcp.WithDescription("Get the configuration an engine analyzes with: KataGo version, model name and hash, default rules, visits, time and threads, search settings, cache settings and KataGo's config file. Record it with a review to reproduce it."),
+		mcp.WithString("format",
+			mcp.Description("Output format: text or json (default: text)"),
+		),
+		withProfile(),
+	)
+	settingsHandler := h.HandleGetAnalysisSettings
+	if h.middleware != nil {
+		settingsHandler = h.middleware.WrapTool("getAnalysisSettings", settingsHandler)
+	}
+	s.AddTool(getAnalysisSettingsTool, settingsHandler)
+
+	// Register getQuota tool, which stays available once a quota is used up
+	getQuotaTool := mcp.NewTool("getQuota",
+		mcp.WithDescription("Get the KataGo visits the calling client has used today and this month, its quota limits and when they reset"),
+		mcp.WithString("format",
+			mcp.Description("Output format: text or json (default: text)"),
+		),
+	)
+	quotaHandler := h.HandleGetQuota
+	if h.middleware != nil {
+		h.middleware.Skip("getQuota", HookQuota)
+		quotaHandler = h.middleware.WrapTool("getQuota", quotaHandler)
+	}
+	s.AddTool(getQuotaTool, quotaHandler)
+
 	// Register startEngine tool
 	startEngineTool := mcp.NewTool("startEngine",
 		mcp.WithDescription("Start the KataGo engine if not already running"),
+		withProfile(),
 	)
 	startHandler := h.HandleStartEngine
 	if h.middleware != nil {
@@ -92,6 +415,7 @@ func (h *ToolsHandler) RegisterTools(s *server.MCPServer) {
 	// Register stopEngine tool
 	stopEngineTool := mcp.NewTool("stopEngine",
 		mcp.WithDescription("Stop the KataGo engine"),
+		withProfile(),
 	)
 	stopHandler := h.HandleStopEngine
 	if h.middleware != nil {
@@ -118,6 +442,38 @@ func (h *ToolsHandler) RegisterTools(s *server.MCPServer) {
 		mcp.WithNumber("maxVisits",
 			mcp.Description("Maximum visits per position (default: from config)"),
 		),
+		mcp.WithNumber("decidedWinrate",
+			mcp.Description("Stop judging moves once the game is decided: Black's win rate stays at or above this, or at or below one minus it (0.5-1, default: off)"),
+		),
+		mcp.WithNumber("decidedMoves",
+			mcp.Description("Consecutive positions at decidedWinrate that decide the game (default: 10)"),
+		),
+		mcp.WithBoolean("verifyVariations",
+			mcp.Description("Also analyze the end of each variation recorded in the SGF (default: false)"),
+		),
+		mcp.WithBoolean("themes",
+			mcp.Description("Also sort the costliest mistakes into themes (direction of play, life and death, endgame counting, overplay) and list the top 3 to work on, with two more queries per mistake (default: false)"),
+		),
+		mcp.WithString("exportReport",
+			mcp.Description("Also return a standalone report with board diagrams, a winrate graph and a mistake table, or (lizzie) the SGF with the review and KataGo's analysis of every position for Lizzie, LizzieYzy or Sabaki"),
+			mcp.Enum("markdown", "html", "lizzie"),
+		),
+		mcp.WithString("minSeverity",
+			mcp.Description("Least severe mistakes listed: 'mistake' lists mistakes and blunders, 'blunder' only blunders (default: mistake)"),
+			mcp.Enum("mistake", "blunder"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Largest number of mistakes listed, for long games (default: all, or 5 when summarizing)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Mistakes to skip before the first listed, to page through them; later pages list only mistakes (default: 0)"),
+		),
+		mcp.WithBoolean("summarize",
+			mcp.Description("List only the summary and the costliest mistakes, up to limit; use getMoveDetails for any move in full (default: false)"),
+		),
+		withLanguage(),
+		withProfile(),
+		withCoordSystem(),
 	)
 	mistakesHandler := h.HandleFindMistakes
 	if h.middleware != nil {
@@ -125,6 +481,68 @@ func (h *ToolsHandler) RegisterTools(s *server.MCPServer) {
 	}
 	s.AddTool(findMistakesTool, mistakesHandler)
 
+	// Register getMoveDetails tool
+	moveDetailsTool := mcp.NewTool("getMoveDetails",
+		mcp.WithDescription("Examine one move of a game as findMistakes judges it: its category, the win rate it lost, its rank among KataGo's candidates, and the candidates with their variations. Pass the same thresholds and maxVisits as findMistakes to reuse its cached analysis."),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content of the game"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("moveNumber",
+			mcp.Description("Move to examine, counted from 1"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("blunderThreshold",
+			mcp.Description("Win rate drop threshold for blunders (default: 0.15)"),
+		),
+		mcp.WithNumber("mistakeThreshold",
+			mcp.Description("Win rate drop threshold for mistakes (default: 0.05)"),
+		),
+		mcp.WithNumber("inaccuracyThreshold",
+			mcp.Description("Win rate drop threshold for inaccuracies (default: 0.02)"),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Maximum visits for the position (default: as findMistakes)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	moveDetailsHandler := h.HandleGetMoveDetails
+	if h.middleware != nil {
+		moveDetailsHandler = h.middleware.WrapTool("getMoveDetails", moveDetailsHandler)
+	}
+	s.AddTool(moveDetailsTool, moveDetailsHandler)
+
+	// Register keyMoments tool
+	keyMomentsTool := mcp.NewTool("keyMoments",
+		mcp.WithDescription("Pick the most pivotal moves of a game - the largest win rate and score swings, the move that decided the game, and best moves found despite a low policy prior - with a brief annotation for each. Faster than findMistakes."),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content of the game"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("count",
+			mcp.Description("Number of moments to return (default: 8, max: 20)"),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Maximum visits per position (default: 50)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	keyMomentsHandler := h.HandleKeyMoments
+	if h.middleware != nil {
+		keyMomentsHandler = h.middleware.WrapTool("keyMoments", keyMomentsHandler)
+	}
+	s.AddTool(keyMomentsTool, keyMomentsHandler)
+
 	// Register evaluateTerritory tool
 	evaluateTerritoryTool := mcp.NewTool("evaluateTerritory",
 		mcp.WithDescription("Evaluate territory ownership and control"),
@@ -138,6 +556,8 @@ func (h *ToolsHandler) RegisterTools(s *server.MCPServer) {
 		mcp.WithBoolean("includeEstimates",
 			mcp.Description("Include detailed point estimates"),
 		),
+		withProfile(),
+		withCoordSystem(),
 	)
 	territoryHandler := h.HandleEvaluateTerritory
 	if h.middleware != nil {
@@ -145,6 +565,98 @@ func (h *ToolsHandler) RegisterTools(s *server.MCPServer) {
 	}
 	s.AddTool(evaluateTerritoryTool, territoryHandler)
 
+	// Register estimateScoreDistribution tool
+	scoreDistributionTool := mcp.NewTool("estimateScoreDistribution",
+		mcp.WithDescription("Estimate how uncertain the final score of a position is: the expected result with its standard deviation (e.g. B+3.5 ± 6.2), percentile bands, and the points whose owner is most uncertain"),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content of the position"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("moveNumber",
+			mcp.Description("Move number to analyze. If not specified, uses the final position."),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Maximum visits for analysis (overrides default)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	scoreDistributionHandler := h.HandleEstimateScoreDistribution
+	if h.middleware != nil {
+		scoreDistributionHandler = h.middleware.WrapTool("estimateScoreDistribution", scoreDistributionHandler)
+	}
+	s.AddTool(scoreDistributionTool, scoreDistributionHandler)
+
+	// Register countingQuiz tool
+	countingQuizTool := mcp.NewTool("countingQuiz",
+		mcp.WithDescription("Practice counting: without a guess, shows the position and asks for the result, hiding KataGo's estimate; with a guess, reveals KataGo's count and compares it with the guess region by region"),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content of the position"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("moveNumber",
+			mcp.Description("Move number to count. If not specified, uses the final position."),
+		),
+		mcp.WithString("guess",
+			mcp.Description("The guessed result, komi included, e.g. 'B+3.5', 'W+0.5' or '0'. Omit to be asked."),
+		),
+		mcp.WithObject("regionGuesses",
+			mcp.Description("Black's guessed lead in points, stones included and negative for White, in any of the quarters 'top-left', 'top-right', 'bottom-left' and 'bottom-right'"),
+		),
+		mcp.WithNumber("threshold",
+			mcp.Description("Ownership above which a point counts for a player (0.0-1.0, default: 0.5)"),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Maximum visits for analysis (overrides default)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format of the answer: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+	)
+	countingQuizHandler := h.HandleCountingQuiz
+	if h.middleware != nil {
+		countingQuizHandler = h.middleware.WrapTool("countingQuiz", countingQuizHandler)
+	}
+	s.AddTool(countingQuizTool, countingQuizHandler)
+
+	// Register territoryTimeline tool
+	territoryTimelineTool := mcp.NewTool("territoryTimeline",
+		mcp.WithDescription("Track each player's territory through a game by analyzing ownership every few moves, and highlight the move ranges where large territorial swings happened"),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content of the game"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("interval",
+			mcp.Description("Moves between analyzed positions (default: 10). Long games are sampled at a wider interval so at most 50 positions are analyzed."),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Maximum visits per position (default: 100)"),
+		),
+		mcp.WithNumber("threshold",
+			mcp.Description("Ownership above which a point counts as a player's territory (0.0-1.0, default: 0.85)"),
+		),
+		mcp.WithNumber("swing",
+			mcp.Description("Change in territory balance, in points, between analyzed positions that counts as a swing (default: 10)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	timelineHandler := h.HandleTerritoryTimeline
+	if h.middleware != nil {
+		timelineHandler = h.middleware.WrapTool("territoryTimeline", timelineHandler)
+	}
+	s.AddTool(territoryTimelineTool, timelineHandler)
+
 	// Register explainMove tool
 	explainMoveTool := mcp.NewTool("explainMove",
 		mcp.WithDescription("Get explanations for why a move is good or bad"),
@@ -159,60 +671,486 @@ func (h *ToolsHandler) RegisterTools(s *server.MCPServer) {
 		mcp.WithNumber("maxVisits",
 			mcp.Description("Maximum visits for analysis"),
 		),
+		mcp.WithNumber("maxTime",
+			mcp.Description("Maximum time in seconds for analysis"),
+		),
+		withLanguage(),
+		withProfile(),
+		withCoordSystem(),
 	)
 	explainHandler := h.HandleExplainMove
 	if h.middleware != nil {
 		explainHandler = h.middleware.WrapTool("explainMove", explainHandler)
 	}
 	s.AddTool(explainMoveTool, explainHandler)
-}
-
-// HandleAnalyzePosition handles the analyzePosition tool.
-func (h *ToolsHandler) HandleAnalyzePosition(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Generate correlation ID for this request
-	ctx = logging.ContextWithCorrelationID(ctx, logging.GenerateCorrelationID())
-	ctx = logging.ContextWithRequestID(ctx, logging.GenerateRequestID())
-	logger := h.logger.WithContext(ctx).WithField("tool", "analyzePosition")
-
-	logger.Info("Handling analyzePosition request")
 
-	// Ensure engine is running
-	if !h.engine.IsRunning() {
-		logger.Debug("Starting KataGo engine")
-		if err := h.engine.Start(ctx); err != nil {
-			logger.Error("Failed to start engine: %v", err)
-			return nil, fmt.Errorf("failed to start engine: %w", err)
-		}
-		// Give engine a moment to initialize
-		// In a real implementation, we might want to wait for a ready signal
+	// Register suggestHumanMove tool
+	suggestHumanMoveTool := mcp.NewTool("suggestHumanMove",
+		mcp.WithDescription("Suggest the moves a human player of a given rank would likely play, compared with KataGo's best move"),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content of the position"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("moveNumber",
+			mcp.Description("Move number to analyze. If not specified, analyzes the final position."),
+		),
+		mcp.WithString("humanProfile",
+			mcp.Description("Human SL profile, e.g. 'rank_5k', 'rank_3d', 'preaz_1d', 'proyear_1990' (default: from config)"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	humanMoveHandler := h.HandleSuggestHumanMove
+	if h.middleware != nil {
+		humanMoveHandler = h.middleware.WrapTool("suggestHumanMove", humanMoveHandler)
 	}
+	s.AddTool(suggestHumanMoveTool, humanMoveHandler)
 
-	args := request.Params.Arguments
-	if args == nil {
-		return nil, fmt.Errorf("missing arguments")
+	// Register estimateRank tool
+	estimateRankTool := mcp.NewTool("estimateRank",
+		mcp.WithDescription("Estimate a player's rank from the point loss of their moves in one or more games"),
+		mcp.WithArray("sgfs",
+			mcp.Description("SGF content of each game to analyze"),
+			mcp.Items(map[string]any{"type": "string"}),
+			mcp.Required(),
+		),
+		mcp.WithString("player",
+			mcp.Description("Player name as it appears in each game's PB or PW property"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Maximum visits per analyzed move (default: 100)"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	rankHandler := h.HandleEstimateRank
+	if h.middleware != nil {
+		rankHandler = h.middleware.WrapTool("estimateRank", rankHandler)
 	}
+	s.AddTool(estimateRankTool, rankHandler)
 
-	// Parse arguments
-	argsMap, ok := args.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("invalid arguments format")
+	// Register expandVariation tool
+	expandVariationTool := mcp.NewTool("expandVariation",
+		mcp.WithDescription("Play a candidate move and re-analyze each position along its principal variation, returning a tree of evaluations"),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content of the position"),
+			mcp.Required(),
+		),
+		mcp.WithString("move",
+			mcp.Description("Candidate move to expand (e.g., 'D4', 'Q16', 'pass')"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("moveNumber",
+			mcp.Description("Move number to start from. If not specified, uses the final position."),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Moves to explore after the candidate move (default: 6, max: 20)"),
+		),
+		mcp.WithNumber("branches",
+			mcp.Description("Replies to explore at each step (default: 1, max: 3)"),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Maximum visits per analyzed position (default: 200)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	variationHandler := h.HandleExpandVariation
+	if h.middleware != nil {
+		variationHandler = h.middleware.WrapTool("expandVariation", variationHandler)
 	}
+	s.AddTool(expandVariationTool, variationHandler)
 
-	// Create analysis request
-	req := &katago.AnalysisRequest{}
+	// Register sweepKomi tool
+	sweepKomiTool := mcp.NewTool("sweepKomi",
+		mcp.WithDescription("Analyze a position at several komi values in parallel and report the komi at which the win rate crosses 50%"),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content of the position"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("moveNumber",
+			mcp.Description("Move number to analyze. If not specified, uses the final position."),
+		),
+		mcp.WithNumber("minKomi",
+			mcp.Description("Lowest komi to analyze (default: the game's komi minus 10)"),
+		),
+		mcp.WithNumber("maxKomi",
+			mcp.Description("Highest komi to analyze (default: the game's komi plus 10)"),
+		),
+		mcp.WithNumber("step",
+			mcp.Description("Komi increment, a multiple of 0.5 (default: 2). At most 41 values are analyzed."),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Maximum visits per komi value (default: 200)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	komiHandler := h.HandleSweepKomi
+	if h.middleware != nil {
+		komiHandler = h.middleware.WrapTool("sweepKomi", komiHandler)
+	}
+	s.AddTool(sweepKomiTool, komiHandler)
 
-	// Handle SGF input
-	if sgfVal, ok := argsMap["sgf"]; ok {
-		sgf, ok := sgfVal.(string)
-		if !ok {
-			return nil, fmt.Errorf("sgf must be a string")
+	// Register whatIf tool
+	whatIfTool := mcp.NewTool("whatIf",
+		mcp.WithDescription("Play a hypothetical sequence of moves for both sides, analyze the resulting position and compare it with the original position and KataGo's best line"),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content of the position"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("moveNumber",
+			mcp.Description("Move number to start from. If not specified, uses the final position."),
+		),
+		mcp.WithArray("moves",
+			mcp.Description("Moves to play, alternating colors starting with the player to move (e.g. ['Q16', 'D4', 'pass']). At most 50 moves."),
+			mcp.Items(map[string]any{"type": "string"}),
+			mcp.Required(),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Maximum visits for each analysis (default: 200)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	whatIfHandler := h.HandleWhatIf
+	if h.middleware != nil {
+		whatIfHandler = h.middleware.WrapTool("whatIf", whatIfHandler)
+	}
+	s.AddTool(whatIfTool, whatIfHandler)
+
+	// Register compareAnalyses tool
+	compareAnalysesTool := mcp.NewTool("compareAnalyses",
+		mcp.WithDescription("Analyze the same position at two settings, such as 100 and 5000 visits or two engine profiles, and report how the evaluation and top moves differ, to judge how many visits are enough"),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content of the position"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("moveNumber",
+			mcp.Description("Move number to analyze. If not specified, uses the final position."),
+		),
+		mcp.WithNumber("maxVisitsA",
+			mcp.Description("Visits for the first analysis (default: the engine's)"),
+		),
+		mcp.WithNumber("maxVisitsB",
+			mcp.Description("Visits for the second analysis (default: the engine's)"),
+		),
+		mcp.WithString("profileA",
+			mcp.Description("Named engine for the first analysis (default: the tool's configured engine)"),
+		),
+		mcp.WithString("profileB",
+			mcp.Description("Named engine for the second analysis (default: the tool's configured engine)"),
+		),
+		mcp.WithNumber("topMoves",
+			mcp.Description("Top moves compared from each analysis, at most 20 (default: 5)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withCoordSystem(),
+	)
+	compareHandler := h.HandleCompareAnalyses
+	if h.middleware != nil {
+		compareHandler = h.middleware.WrapTool("compareAnalyses", compareHandler)
+	}
+	s.AddTool(compareAnalysesTool, compareHandler)
+
+	// Register solveTsumego tool
+	solveTsumegoTool := mcp.NewTool("solveTsumego",
+		mcp.WithDescription("Solve a local life-and-death problem: confine both players to a region, search until the result is stable, and report whether the target group lives, dies or becomes ko, with refutations of wrong answers"),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content of the problem"),
+		),
+		mcp.WithString("board",
+			mcp.Description("The problem as a text diagram or GTP stone list, as for analyzePosition"),
+		),
+		mcp.WithNumber("boardSize",
+			mcp.Description("Board size for board input (default: the diagram's size when it shows the whole board, otherwise 19)"),
+		),
+		mcp.WithString("toPlay",
+			mcp.Description("Player to move for board input: 'B' or 'W' (default: B)"),
+			mcp.Enum("B", "W"),
+		),
+		mcp.WithNumber("moveNumber",
+			mcp.Description("Move number of the problem (for SGF input). If not specified, uses the final position."),
+		),
+		mcp.WithArray("region",
+			mcp.Description("Moves or regions both players are confined to, as for allowMoves (e.g. ['A14-G19'] or ['top-left'])"),
+			mcp.Items(map[string]any{"type": "string"}),
+			mcp.Required(),
+		),
+		mcp.WithString("target",
+			mcp.Description("A stone of the group whose life or death is in question (e.g. 'B18')"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Visits for the first search; each further search doubles them (default: 400)"),
+		),
+		mcp.WithNumber("maxIterations",
+			mcp.Description("Searches to run before giving up on a stable result, at most 6 (default: 4)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	tsumegoHandler := h.HandleSolveTsumego
+	if h.middleware != nil {
+		tsumegoHandler = h.middleware.WrapTool("solveTsumego", tsumegoHandler)
+	}
+	s.AddTool(solveTsumegoTool, tsumegoHandler)
+
+	// Register generateProblems tool
+	generateProblemsTool := mcp.NewTool("generateProblems",
+		mcp.WithDescription("Turn a player's blunders into training problems: review their games, keep the positions where one move was clearly best, and export them as an SGF collection with the solution and the move played as variations"),
+		mcp.WithArray("sgfs",
+			mcp.Description("SGF content of each game to scan"),
+			mcp.Items(map[string]any{"type": "string"}),
+			mcp.Required(),
+		),
+		mcp.WithString("player",
+			mcp.Description("Player name as it appears in each game's PB or PW property"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("maxProblems",
+			mcp.Description("Largest number of problems, keeping the costliest mistakes, at most 100 (default: 20)"),
+		),
+		mcp.WithNumber("minGap",
+			mcp.Description("Win rate by which the best move must beat the second best, 0-1 (default: 0.1)"),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Visits to confirm each problem (default: 400)"),
+		),
+		mcp.WithBoolean("includeMistakes",
+			mcp.Description("Also consider mistakes, not only blunders (default: false)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	problemsHandler := h.HandleGenerateProblems
+	if h.middleware != nil {
+		problemsHandler = h.middleware.WrapTool("generateProblems", problemsHandler)
+	}
+	s.AddTool(generateProblemsTool, problemsHandler)
+
+	// Register openingBook tool
+	openingBookTool := mcp.NewTool("openingBook",
+		mcp.WithDescription("Build a player's opening tree from their games, with how often they played each line, how those games ended and KataGo's evaluation of their habitual moves, and report the habitual lines that lose points"),
+		mcp.WithArray("sgfs",
+			mcp.Description("SGF content of each game"),
+			mcp.Items(map[string]any{"type": "string"}),
+			mcp.Required(),
+		),
+		mcp.WithString("player",
+			mcp.Description("Player name as it appears in each game's PB or PW property"),
+			mcp.Required(),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description("Moves from the start of each game put in the tree, at most 40 (default: 12)"),
+		),
+		mcp.WithNumber("minGames",
+			mcp.Description("Games in which the player must have made a move for it to be habitual and evaluated (default: 2)"),
+		),
+		mcp.WithNumber("minLoss",
+			mcp.Description("Points a habitual move must lose to be reported (default: 2)"),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Visits per evaluated position (default: 200)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	openingBookHandler := h.HandleOpeningBook
+	if h.middleware != nil {
+		openingBookHandler = h.middleware.WrapTool("openingBook", openingBookHandler)
+	}
+	s.AddTool(openingBookTool, openingBookHandler)
+
+	// Register reviewArchive tool
+	reviewArchiveTool := mcp.NewTool("reviewArchive",
+		mcp.WithDescription("Review every game in a zip, tar or tar.gz archive of SGF files and return an aggregate report with per-player statistics plus a summary of each game. Games that fail to parse or review are listed rather than failing the archive."),
+		mcp.WithString("archive",
+			mcp.Description(fmt.Sprintf("Base64-encoded zip, tar or tar.gz archive; only .sgf files are read, at most %d games of %d KiB each", katago.MaxArchiveGames, katago.MaxArchiveGameBytes>>10)),
+			mcp.Required(),
+		),
+		mcp.WithNumber("blunderThreshold",
+			mcp.Description("Win rate drop threshold for blunders (default: 0.15)"),
+		),
+		mcp.WithNumber("mistakeThreshold",
+			mcp.Description("Win rate drop threshold for mistakes (default: 0.05)"),
+		),
+		mcp.WithNumber("inaccuracyThreshold",
+			mcp.Description("Win rate drop threshold for inaccuracies (default: 0.02)"),
+		),
+		mcp.WithNumber("maxVisits",
+			mcp.Description("Maximum visits per position (default: from config)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+		withCoordSystem(),
+	)
+	reviewArchiveHandler := h.HandleReviewArchive
+	if h.middleware != nil {
+		reviewArchiveHandler = h.middleware.WrapTool("reviewArchive", reviewArchiveHandler)
+	}
+	s.AddTool(reviewArchiveTool, reviewArchiveHandler)
+
+	// Register validateSGF tool
+	validateSGFTool := mcp.NewTool("validateSGF",
+		mcp.WithDescription("Check an SGF without analyzing it: parse errors, illegal moves (occupied points, suicide, ko), off-board coordinates, unsupported properties, board size, variation count and suggestions to normalize it. Use when an SGF fails to parse or analyzes unexpectedly."),
+		mcp.WithString("sgf",
+			mcp.Description("SGF content to check"),
+			mcp.Required(),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withCoordSystem(),
+	)
+	validateSGFHandler := h.HandleValidateSGF
+	if h.middleware != nil {
+		validateSGFHandler = h.middleware.WrapTool("validateSGF", validateSGFHandler)
+	}
+	s.AddTool(validateSGFTool, validateSGFHandler)
+
+	// Register clearCache tool
+	clearCacheTool := mcp.NewTool("clearCache",
+		mcp.WithDescription("Clear all cached analysis results (admin). Under strict namespace isolation other clients clear only their own namespace's results"),
+	)
+	clearCacheHandler := h.HandleClearCache
+	if h.middleware != nil {
+		clearCacheHandler = h.middleware.WrapTool("clearCache", clearCacheHandler)
+	}
+	s.AddTool(clearCacheTool, clearCacheHandler)
+
+	// Register engineRecording tool
+	engineRecordingTool := mcp.NewTool("engineRecording",
+		mcp.WithDescription("Dump the engine flight recorder: the raw queries sent to KataGo and its responses, as JSON Lines that katago-mock can replay (admin)"),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of most recent lines to return (default: all recorded)"),
+		),
+		withProfile(),
+	)
+	recordingHandler := h.HandleEngineRecording
+	if h.middleware != nil {
+		recordingHandler = h.middleware.WrapTool("engineRecording", recordingHandler)
+	}
+	s.AddTool(engineRecordingTool, recordingHandler)
+
+	// Register getDiagnostics tool
+	getDiagnosticsTool := mcp.NewTool("getDiagnostics",
+		mcp.WithDescription("Fetch the diagnostics bundle collected the last time KataGo crashed or stopped responding: its last stderr lines and queries, engine config, models, and OS and GPU details (admin)"),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+	)
+	diagnosticsHandler := h.HandleGetDiagnostics
+	if h.middleware != nil {
+		diagnosticsHandler = h.middleware.WrapTool("getDiagnostics", diagnosticsHandler)
+	}
+	s.AddTool(getDiagnosticsTool, diagnosticsHandler)
+
+	// Register getSlowQueries tool
+	getSlowQueriesTool := mcp.NewTool("getSlowQueries",
+		mcp.WithDescription("List the engine queries that took longer than the slow query threshold, with their position as SGF, settings and timing (admin)"),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of most recent queries to return (default: all logged)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: 'text' or 'json' (default: text)"),
+			mcp.Enum("text", "json"),
+		),
+		withProfile(),
+	)
+	slowQueriesHandler := h.HandleGetSlowQueries
+	if h.middleware != nil {
+		slowQueriesHandler = h.middleware.WrapTool("getSlowQueries", slowQueriesHandler)
+	}
+	s.AddTool(getSlowQueriesTool, slowQueriesHandler)
+
+	h.registerSessionTools(s)
+}
+
+// HandleAnalyzePosition handles the analyzePosition tool.
+func (h *ToolsHandler) HandleAnalyzePosition(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "analyzePosition")
+
+	logger.Info("Handling analyzePosition request")
+
+	engine, err := h.engineFor("analyzePosition", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+		// Give engine a moment to initialize
+		// In a real implementation, we might want to wait for a ready signal
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	// Parse arguments
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Create analysis request
+	req := &katago.AnalysisRequest{}
+
+	// Handle SGF input
+	if sgfVal, ok := argsMap["sgf"]; ok {
+		sgf, ok := sgfVal.(string)
+		if !ok {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
 		}
 
 		// Parse SGF to get position
 		parser := katago.NewSGFParser(sgf)
 		position, err := parser.Parse()
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse SGF: %w", err)
+			return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
 		}
 
 		// Handle move number
@@ -242,39 +1180,39 @@ func (h *ToolsHandler) HandleAnalyzePosition(ctx context.Context, request mcp.Ca
 
 		var position katago.Position
 		if err := json.Unmarshal(posData, &position); err != nil {
-			return nil, fmt.Errorf("failed to parse position: %w", err)
+			return nil, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "failed to parse position")
 		}
 
 		req.Position = &position
+	} else if boardVal, ok := argsMap["board"]; ok {
+		position, err := boardPosition(boardVal, argsMap)
+		if err != nil {
+			return nil, err
+		}
+		req.Position = position
 	} else {
-		return nil, fmt.Errorf("must provide either 'sgf' or 'position' parameter")
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "must provide 'sgf', 'position' or 'board' parameter")
 	}
 
-	// Handle optional parameters
-	if maxVisitsVal, ok := argsMap["maxVisits"]; ok {
-		maxVisits := 0
-		switch v := maxVisitsVal.(type) {
-		case float64:
-			maxVisits = int(v)
-		case int:
-			maxVisits = v
+	// Policy-only mode evaluates the root without search or formatting
+	if policyOnly, _ := argsMap["policyOnly"].(bool); policyOnly {
+		result, err := engine.Analyze(ctx, katago.PolicyOnlyRequest(req.Position))
+		if err != nil {
+			return nil, fmt.Errorf("analysis failed: %w", err)
 		}
-		if maxVisits > 0 {
-			req.MaxVisits = &maxVisits
+		evalJSON, err := json.Marshal(katago.NewPolicyEvaluation(result, req.Position.BoardXSize, req.Position.BoardYSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
 		}
+		return mcp.NewToolResultText(string(evalJSON)), nil
 	}
 
-	if maxTimeVal, ok := argsMap["maxTime"]; ok {
-		maxTime := 0.0
-		switch v := maxTimeVal.(type) {
-		case float64:
-			maxTime = v
-		case int:
-			maxTime = float64(v)
-		}
-		if maxTime > 0 {
-			req.MaxTime = &maxTime
-		}
+	// Handle optional parameters
+	if maxVisits := h.maxVisits("analyzePosition", argsMap); maxVisits > 0 {
+		req.MaxVisits = &maxVisits
+	}
+	if maxTime := h.maxTime("analyzePosition", argsMap); maxTime > 0 {
+		req.MaxTime = &maxTime
 	}
 
 	if includePolicyVal, ok := argsMap["includePolicy"]; ok {
@@ -288,6 +1226,38 @@ func (h *ToolsHandler) HandleAnalyzePosition(ctx context.Context, request mcp.Ca
 			req.IncludeOwnership = includeOwnership
 		}
 	}
+	if includeMovesOwnership, ok := argsMap["includeMovesOwnership"].(bool); ok {
+		req.IncludeMovesOwnership = includeMovesOwnership
+	}
+
+	if humanProfileVal, ok := argsMap["humanProfile"]; ok {
+		humanProfile, ok := humanProfileVal.(string)
+		if !ok {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "humanProfile must be a string")
+		}
+		req.HumanProfile = humanProfile
+	}
+
+	req.Search, err = searchSettings(argsMap)
+	if err != nil {
+		return nil, err
+	}
+	req.AverageSymmetries, _ = argsMap["averageSymmetries"].(bool)
+
+	avoid, err := stringList(argsMap, "avoidMoves")
+	if err != nil {
+		return nil, err
+	}
+	allow, err := stringList(argsMap, "allowMoves")
+	if err != nil {
+		return nil, err
+	}
+	if len(avoid) > 0 || len(allow) > 0 {
+		req.AvoidMoves, err = katago.ResolveMoveRestrictions(avoid, allow, req.Position.BoardXSize, req.Position.BoardYSize)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	verbose := false
 	if verboseVal, ok := argsMap["verbose"]; ok {
@@ -296,14 +1266,38 @@ func (h *ToolsHandler) HandleAnalyzePosition(ctx context.Context, request mcp.Ca
 		}
 	}
 
+	req.IncludePVVisits = verbose
+	if includePVVisitsVal, ok := argsMap["includePVVisits"]; ok {
+		if includePVVisits, ok := includePVVisitsVal.(bool); ok {
+			req.IncludePVVisits = includePVVisits
+		}
+	}
+
+	format := ""
+	if formatVal, ok := argsMap["format"]; ok {
+		format, _ = formatVal.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
+	}
+
+	sortBy := h.sortMoves
+	if sortByVal, ok := argsMap["sortBy"]; ok {
+		sortBy, _ = sortByVal.(string)
+		if sortBy != katago.SortByVisits && sortBy != katago.SortByLCB {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "sortBy must be 'visits' or 'lcb'")
+		}
+	}
+
 	// Perform analysis
-	result, err := h.engine.Analyze(ctx, req)
+	result, err := engine.Analyze(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("analysis failed: %w", err)
 	}
+	result.MoveInfos = katago.SortMoveInfos(result.MoveInfos, sortBy)
 
 	// Format result
-	if verbose || (!req.IncludePolicy && !req.IncludeOwnership) {
+	if format == "text" || (format == "" && (verbose || (!req.IncludePolicy && !req.IncludeOwnership && !req.IncludeMovesOwnership))) {
 		// Return formatted text for simple cases
 		boardSize := 19 // Default
 		if req.Position != nil {
@@ -314,386 +1308,3626 @@ func (h *ToolsHandler) HandleAnalyzePosition(ctx context.Context, request mcp.Ca
 	}
 
 	// Return JSON for complex cases
-	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	return analysisJSONResult(result)
+}
+
+// largeResultBytes is the size of JSON above which an analysis is returned
+// in parts rather than as one document.
+const largeResultBytes = 1 << 20
+
+// analysisJSONResult returns an analysis as indented JSON or, when large,
+// as compact JSON in several content blocks: the analysis without its
+// movesOwnership, then each candidate's ownership as {"move", "ownership"}
+// in move order, so that clients can process it piece by piece.
+func analysisJSONResult(result *katago.AnalysisResult) (*mcp.CallToolResult, error) {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format result: %w", err)
+	}
+	switch {
+	case len(resultJSON) <= largeResultBytes:
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, resultJSON, "", "  "); err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(indented.String()), nil
+	case len(result.MovesOwnership) == 0:
+		// Nothing to split off, but compact JSON is still much smaller
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+
+	head := *result
+	head.MovesOwnership = nil
+	headJSON, err := json.Marshal(&head)
 	if err != nil {
 		return nil, fmt.Errorf("failed to format result: %w", err)
 	}
+	content := []mcp.Content{mcp.NewTextContent(string(headJSON))}
 
-	return mcp.NewToolResultText(string(resultJSON)), nil
+	moves := make([]string, 0, len(result.MovesOwnership))
+	for move := range result.MovesOwnership {
+		moves = append(moves, move)
+	}
+	sort.Strings(moves)
+	for _, move := range moves {
+		partJSON, err := json.Marshal(struct {
+			Move      string      `json:"move"`
+			Ownership [][]float64 `json:"ownership"`
+		}{move, result.MovesOwnership[move]})
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		content = append(content, mcp.NewTextContent(string(partJSON)))
+	}
+	return &mcp.CallToolResult{Content: content}, nil
 }
 
 // HandleGetEngineStatus handles the getEngineStatus tool.
 func (h *ToolsHandler) HandleGetEngineStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Generate correlation ID for this request
-	ctx = logging.ContextWithCorrelationID(ctx, logging.GenerateCorrelationID())
-	ctx = logging.ContextWithRequestID(ctx, logging.GenerateRequestID())
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
 	logger := h.logger.WithContext(ctx).WithField("tool", "getEngineStatus")
 
 	logger.Info("Handling getEngineStatus request")
 
+	engine, err := h.engineFor("getEngineStatus", request)
+	if err != nil {
+		return nil, err
+	}
+
 	status := "stopped"
-	if h.engine.IsRunning() {
+	if engine.IsRunning() {
 		status = "running"
 	}
 
-	logger.Debug("Engine status checked", "status", status)
-	info := fmt.Sprintf("KataGo engine status: %s", status)
-	return mcp.NewToolResultText(info), nil
-}
+	logger.Debug("Engine status checked", "status", status)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("KataGo engine status: %s", status))
+	if pid := engine.ProcessID(); pid != 0 {
+		sb.WriteString(fmt.Sprintf("\nProcess ID: %d", pid))
+	}
+	if progress := engine.StartupProgress(); progress.Phase != "" {
+		if progress.Ready() {
+			sb.WriteString(fmt.Sprintf("\nStartup: ready after %.1fs", progress.ElapsedSeconds))
+		} else {
+			sb.WriteString(fmt.Sprintf("\nStartup: %s, %d%% (%.0fs elapsed)", progress.Phase, progress.Percent, progress.ElapsedSeconds))
+			if progress.Message != "" {
+				sb.WriteString(fmt.Sprintf("\nLast startup message: %s", progress.Message))
+			}
+		}
+		if progress.Backend != "" {
+			sb.WriteString(fmt.Sprintf("\nBackend: %s", progress.Backend))
+		}
+		if progress.GPU != "" {
+			sb.WriteString(fmt.Sprintf("\nGPU device: %s", progress.GPU))
+		}
+	}
+	if caps := engine.Capabilities(); caps.Known() {
+		sb.WriteString(fmt.Sprintf("\nKataGo version: %s", caps.Version))
+		if len(caps.Features) > 0 {
+			sb.WriteString(fmt.Sprintf("\nProtocol features: %s", strings.Join(caps.Features, ", ")))
+		}
+		if len(caps.Unsupported) > 0 {
+			unsupported := make([]string, len(caps.Unsupported))
+			for i, feature := range caps.Unsupported {
+				unsupported[i] = fmt.Sprintf("%s (requires %s)", feature, katago.RequiredVersion(feature))
+			}
+			sb.WriteString(fmt.Sprintf("\nUnsupported features: %s", strings.Join(unsupported, ", ")))
+		}
+	} else if engine.IsRunning() {
+		sb.WriteString("\nKataGo version: not yet negotiated")
+	}
+
+	// The resource monitor samples the default engine's process
+	if h.monitor != nil && engine == h.engine {
+		if sample, ok := h.monitor.Latest(); ok && sample.PID != 0 {
+			sb.WriteString(fmt.Sprintf("\nCPU: %.1f%%", sample.CPUPercent))
+			sb.WriteString(fmt.Sprintf("\nMemory (RSS): %.1f MiB", float64(sample.RSSBytes)/(1024*1024)))
+			if sample.GPUAvailable {
+				sb.WriteString(fmt.Sprintf("\nGPU: %.1f%%", sample.GPUPercent))
+				sb.WriteString(fmt.Sprintf("\nGPU memory: %.1f MiB", float64(sample.GPUMemoryBytes)/(1024*1024)))
+			}
+			sb.WriteString(fmt.Sprintf("\nSampled at: %s", sample.Timestamp.Format(time.RFC3339)))
+		}
+		if err := h.monitor.Check(ctx); err != nil {
+			sb.WriteString(fmt.Sprintf("\nWarning: %v", err))
+		}
+	}
+
+	if len(h.engines) > 1 {
+		names := make([]string, 0, len(h.engines))
+		for name := range h.engines {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		sb.WriteString("\n\nEngines:")
+		for _, name := range names {
+			state := "stopped"
+			if h.engines[name].IsRunning() {
+				state = "running"
+			}
+			if caps := h.engines[name].Capabilities(); caps.Known() {
+				state += fmt.Sprintf(" (KataGo %s)", caps.Version)
+			}
+			sb.WriteString(fmt.Sprintf("\n- %s: %s", name, state))
+		}
+	}
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// AnalysisSettings describes the settings of the engine a tool call is
+// routed to.
+func (h *ToolsHandler) AnalysisSettings(tool string, request mcp.CallToolRequest) (*katago.AnalysisSettings, error) {
+	name, err := h.engineNameFor(tool, request)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := h.engineFor(tool, request)
+	if err != nil {
+		return nil, err
+	}
+	cfg, ok := h.engineCfgs[name]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInternal, "no configuration for engine %s", name)
+	}
+
+	settings, err := katago.DescribeSettings(name, cfg, engine.Capabilities(), h.cacheCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe settings: %w", err)
+	}
+	return settings, nil
+}
+
+// BoardSize returns the board size of the game in a tool call's sgf, sgfs,
+// position or board argument, or of the session it names, so the
+// middleware can convert its coordinates. It returns 0 if there is none.
+func (h *ToolsHandler) BoardSize(ctx context.Context, request mcp.CallToolRequest) int {
+	argsMap, ok := request.Params.Arguments.(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	sgf, _ := argsMap["sgf"].(string)
+	if sgfs, ok := argsMap["sgfs"].([]interface{}); ok && len(sgfs) > 0 {
+		// Games are assumed to share the first one's size
+		sgf, _ = sgfs[0].(string)
+	}
+	if sgf != "" {
+		if position, err := katago.NewSGFParser(sgf).Parse(); err == nil {
+			return position.BoardXSize
+		}
+		return 0
+	}
+	if position, ok := argsMap["position"].(map[string]interface{}); ok {
+		if size, ok := position["boardXSize"].(float64); ok {
+			return int(size)
+		}
+		return 19
+	}
+	if board, ok := argsMap["board"].(string); ok {
+		size := 0
+		if val, ok := argsMap["boardSize"].(float64); ok {
+			size = int(val)
+		}
+		if position, err := katago.ParseBoard(board, size); err == nil {
+			return position.BoardXSize
+		}
+		return 0
+	}
+	if _, ok := argsMap["sessionId"]; ok {
+		if sess, _, err := h.sessionFor(ctx, request); err == nil {
+			return sess.Position().BoardXSize
+		}
+	}
+	return 0
+}
+
+// HandleGetAnalysisSettings handles the getAnalysisSettings tool.
+func (h *ToolsHandler) HandleGetAnalysisSettings(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "getAnalysisSettings")
+
+	logger.Info("Handling getAnalysisSettings request")
+
+	format := "text"
+	if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if val, ok := argsMap["format"]; ok {
+			if format, ok = val.(string); !ok || (format != "text" && format != "json") {
+				return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be text or json")
+			}
+		}
+	}
+
+	settings, err := h.AnalysisSettings("getAnalysisSettings", request)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(settings, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatAnalysisSettings(settings)), nil
+}
+
+// HandleGetQuota handles the getQuota tool.
+func (h *ToolsHandler) HandleGetQuota(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "getQuota")
+
+	logger.Info("Handling getQuota request")
+
+	format := "text"
+	if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if val, ok := argsMap["format"]; ok {
+			if format, ok = val.(string); !ok || (format != "text" && format != "json") {
+				return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be text or json")
+			}
+		}
+	}
+
+	status := h.quota.Status(extractClientID(ctx, request))
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatQuota(status)), nil
+}
+
+// formatQuota renders a client's quota usage as text.
+func formatQuota(status quota.Status) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Quota for client %s\n", status.Client))
+	if !status.Enabled {
+		sb.WriteString("Quotas are not enabled on this server\n")
+		return sb.String()
+	}
+	for _, period := range []struct {
+		label  string
+		status quota.PeriodStatus
+	}{{"Today", status.Daily}, {"This month", status.Monthly}} {
+		if period.status.Limit == 0 {
+			sb.WriteString(fmt.Sprintf("%s: %d visits used (unlimited)\n", period.label, period.status.Used))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s: %d of %d visits used, %d remaining (resets %s)\n",
+			period.label, period.status.Used, period.status.Limit, period.status.Remaining,
+			period.status.ResetsAt.Format(time.RFC3339)))
+	}
+	return sb.String()
+}
+
+// formatAnalysisSettings renders an engine's settings as text.
+func formatAnalysisSettings(settings *katago.AnalysisSettings) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Analysis settings of engine %s\n", settings.Engine))
+	if settings.KataGoVersion != "" {
+		version := settings.KataGoVersion
+		if settings.GitHash != "" {
+			version += fmt.Sprintf(" (%s)", settings.GitHash)
+		}
+		sb.WriteString(fmt.Sprintf("KataGo version: %s\n", version))
+	} else {
+		sb.WriteString("KataGo version: not yet negotiated (start the engine to report it)\n")
+	}
+	for _, model := range []struct {
+		label string
+		file  *katago.ModelFile
+	}{{"Model", settings.Model}, {"Human model", settings.HumanModel}} {
+		if model.file == nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s: %s\n", model.label, model.file.Name))
+		sb.WriteString(fmt.Sprintf("  Path: %s\n", model.file.Path))
+		sb.WriteString(fmt.Sprintf("  SHA-256: %s\n", model.file.SHA256))
+	}
+	if settings.HumanProfile != "" {
+		sb.WriteString(fmt.Sprintf("Default human profile: %s\n", settings.HumanProfile))
+	}
+	sb.WriteString(fmt.Sprintf("Default rules: %s\n", settings.DefaultRules))
+	sb.WriteString(fmt.Sprintf("Max visits: %d\n", settings.MaxVisits))
+	sb.WriteString(fmt.Sprintf("Max time: %gs\n", settings.MaxTime))
+	sb.WriteString(fmt.Sprintf("Search threads: %d\n", settings.NumSearchThreads))
+
+	var search []string
+	if settings.Search.WideRootNoise != nil {
+		search = append(search, fmt.Sprintf("wideRootNoise=%g", *settings.Search.WideRootNoise))
+	}
+	if settings.Search.RootPolicyTemperature != nil {
+		search = append(search, fmt.Sprintf("rootPolicyTemperature=%g", *settings.Search.RootPolicyTemperature))
+	}
+	if settings.Search.AnalysisPVLen != nil {
+		search = append(search, fmt.Sprintf("analysisPVLen=%d", *settings.Search.AnalysisPVLen))
+	}
+	if len(search) > 0 {
+		sb.WriteString(fmt.Sprintf("Search settings: %s\n", strings.Join(search, ", ")))
+	}
+
+	if settings.Cache.Enabled {
+		sb.WriteString(fmt.Sprintf("Cache: enabled, up to %d items and %d bytes, TTL %ds",
+			settings.Cache.MaxItems, settings.Cache.MaxSizeBytes, settings.Cache.TTLSeconds))
+		if settings.Cache.Redis != "" {
+			sb.WriteString(fmt.Sprintf(", shared through Redis at %s", settings.Cache.Redis))
+		}
+		sb.WriteString("\n")
+	} else {
+		sb.WriteString("Cache: disabled\n")
+	}
+
+	if file := settings.ConfigFile; file != nil {
+		sb.WriteString(fmt.Sprintf("KataGo config file: %s\n", file.Path))
+		sb.WriteString(fmt.Sprintf("  SHA-256: %s\n", file.SHA256))
+		sb.WriteString("\n")
+		sb.WriteString(file.Contents)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// HandleStartEngine handles the startEngine tool.
+func (h *ToolsHandler) HandleStartEngine(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "startEngine")
+
+	logger.Info("Handling startEngine request")
+
+	engine, err := h.engineFor("startEngine", request)
+	if err != nil {
+		return nil, err
+	}
+
+	if engine.IsRunning() {
+		logger.Debug("Engine already running")
+		return mcp.NewToolResultText("KataGo engine is already running"), nil
+	}
+
+	logger.Info("Starting KataGo engine")
+	if err := engine.Start(ctx); err != nil {
+		logger.Error("Failed to start engine: %v", err)
+		return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+	}
+
+	logger.Info("KataGo engine started successfully")
+	return mcp.NewToolResultText("KataGo engine started successfully"), nil
+}
+
+// HandleStopEngine handles the stopEngine tool.
+func (h *ToolsHandler) HandleStopEngine(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "stopEngine")
+
+	logger.Info("Handling stopEngine request")
+
+	engine, err := h.engineFor("stopEngine", request)
+	if err != nil {
+		return nil, err
+	}
+
+	if !engine.IsRunning() {
+		logger.Debug("Engine not running")
+		return mcp.NewToolResultText("KataGo engine is not running"), nil
+	}
+
+	logger.Info("Stopping KataGo engine")
+	if err := engine.Stop(); err != nil {
+		logger.Error("Failed to stop engine: %v", err)
+		return nil, fmt.Errorf("failed to stop engine: %w", err)
+	}
+
+	logger.Info("KataGo engine stopped successfully")
+	return mcp.NewToolResultText("KataGo engine stopped successfully"), nil
+}
+
+// HandleFindMistakes handles the findMistakes tool.
+func (h *ToolsHandler) HandleFindMistakes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "findMistakes")
+
+	logger.Info("Handling findMistakes request")
+
+	engine, err := h.engineFor("findMistakes", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Get SGF content
+	sgfVal, ok := argsMap["sgf"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	sgf, ok := sgfVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+	}
+
+	thresholds := h.mistakeThresholds(argsMap)
+
+	// Early exit for decided games
+	if val, ok := argsMap["decidedWinrate"].(float64); ok {
+		if val <= 0.5 || val >= 1 {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "decidedWinrate must be between 0.5 and 1")
+		}
+		thresholds.DecidedWinrate = val
+		thresholds.DecidedMoves = katago.DefaultDecidedMoves
+	}
+	if val, ok := argsMap["decidedMoves"].(float64); ok {
+		if val < 1 {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "decidedMoves must be at least 1")
+		}
+		thresholds.DecidedMoves = int(val)
+	}
+	thresholds.VerifyVariations, _ = argsMap["verifyVariations"].(bool)
+	thresholds.Themes, _ = argsMap["themes"].(bool)
+
+	printer, err := h.printerFor(argsMap)
+	if err != nil {
+		return nil, err
+	}
+	ctx = i18n.WithPrinter(ctx, printer)
+
+	page, err := parseMistakePage(argsMap)
+	if err != nil {
+		return nil, err
+	}
+
+	var reportFormat string
+	if val, ok := argsMap["exportReport"]; ok {
+		name, ok := val.(string)
+		if !ok {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "exportReport must be a string")
+		}
+		if reportFormat, err = report.ParseFormat(name); err != nil {
+			return nil, err
+		}
+	}
+
+	// Review the game
+	logger.Info("Reviewing game", "thresholds", thresholds)
+	review, err := engine.ReviewGame(ctx, sgf, thresholds)
+	if err != nil {
+		logger.Error("Failed to review game: %v", err)
+		return nil, fmt.Errorf("failed to review game: %w", err)
+	}
+	logger.Info("Game review completed",
+		"totalMoves", review.Summary.TotalMoves,
+		"analyzedMoves", review.Summary.AnalyzedMoves,
+		"partial", review.Summary.Partial,
+		"mistakes", len(review.Mistakes))
+
+	text, err := page.format(printer, review)
+	if err != nil {
+		return nil, err
+	}
+	if reportFormat == "" {
+		return mcp.NewToolResultText(text), nil
+	}
+	game, err := katago.NewSGFParser(sgf).Parse()
+	if err != nil {
+		return nil, err
+	}
+	content, err := report.Generate(reportFormat, sgf, game, review)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResultResource(text, mcp.TextResourceContents{
+		URI:      "katago-mcp://reports/review" + report.Extension(reportFormat),
+		MIMEType: report.MIMEType(reportFormat),
+		Text:     content,
+	}), nil
+}
+
+// mistakeThresholds returns the mistake thresholds of a findMistakes or
+// getMoveDetails call, so both judge moves alike.
+func (h *ToolsHandler) mistakeThresholds(argsMap map[string]interface{}) *katago.MistakeThresholds {
+	thresholds := katago.DefaultMistakeThresholds()
+	if h.reviewCfg != nil {
+		thresholds.Concurrency = h.reviewCfg.Concurrency
+	}
+
+	if val, ok := argsMap["blunderThreshold"]; ok {
+		if threshold, ok := val.(float64); ok {
+			thresholds.Blunder = threshold
+		}
+	}
+	if val, ok := argsMap["mistakeThreshold"]; ok {
+		if threshold, ok := val.(float64); ok {
+			thresholds.Mistake = threshold
+		}
+	}
+
+	if val, ok := argsMap["inaccuracyThreshold"]; ok {
+		if threshold, ok := val.(float64); ok {
+			thresholds.Inaccuracy = threshold
+		}
+	}
+
+	if visits := h.maxVisits("findMistakes", argsMap); visits > 0 {
+		thresholds.MinimumVisits = visits
+	}
+	return thresholds
+}
+
+// defaultSummaryMistakes is the number of mistakes a summarized review
+// lists unless the call sets a limit.
+const defaultSummaryMistakes = 5
+
+// mistakePage selects the mistakes a findMistakes call lists, so reviews
+// of long games fit in a client's messages.
+type mistakePage struct {
+	blundersOnly bool
+	offset       int
+	limit        int  // 0 lists every mistake from offset
+	summarize    bool // Only the summary and the costliest mistakes
+}
+
+// parseMistakePage reads the minSeverity, limit, offset and summarize
+// arguments of a findMistakes call.
+func parseMistakePage(argsMap map[string]interface{}) (*mistakePage, error) {
+	page := &mistakePage{}
+	if val, ok := argsMap["minSeverity"]; ok {
+		switch val {
+		case "mistake":
+		case "blunder":
+			page.blundersOnly = true
+		default:
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "minSeverity must be 'mistake' or 'blunder'")
+		}
+	}
+	if val, ok := argsMap["limit"]; ok {
+		limit, ok := val.(float64)
+		if !ok || limit < 1 {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "limit must be at least 1")
+		}
+		page.limit = int(limit)
+	}
+	if val, ok := argsMap["offset"]; ok {
+		offset, ok := val.(float64)
+		if !ok || offset < 0 {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "offset must not be negative")
+		}
+		page.offset = int(offset)
+	}
+	page.summarize, _ = argsMap["summarize"].(bool)
+	if page.summarize && page.limit == 0 {
+		page.limit = defaultSummaryMistakes
+	}
+	return page, nil
+}
+
+// paged reports whether the page lists anything less than the whole
+// review.
+func (pg *mistakePage) paged() bool {
+	return pg.blundersOnly || pg.offset > 0 || pg.limit > 0 || pg.summarize
+}
+
+// format formats the page of a review as markdown in p's language,
+// followed by which mistakes it lists and how to see the others.
+func (pg *mistakePage) format(p *i18n.Printer, review *katago.GameReview) (string, error) {
+	if !pg.paged() {
+		return formatGameReview(p, review), nil
+	}
+
+	var mistakes []katago.Mistake
+	for _, m := range review.Mistakes {
+		if !pg.blundersOnly || m.Category == "blunder" {
+			mistakes = append(mistakes, m)
+		}
+	}
+	total := len(mistakes)
+	if pg.offset > 0 && pg.offset >= total {
+		return "", apperrors.New(apperrors.CodeInvalidArgument, "offset %d is past the last of %d mistakes", pg.offset, total)
+	}
+	if pg.summarize {
+		sort.SliceStable(mistakes, func(i, j int) bool { return mistakes[i].WinrateDrop > mistakes[j].WinrateDrop })
+	}
+	mistakes = mistakes[pg.offset:]
+	if pg.limit > 0 && len(mistakes) > pg.limit {
+		mistakes = mistakes[:pg.limit]
+	}
+
+	listed := *review
+	listed.Mistakes = mistakes
+	if pg.summarize || pg.offset > 0 {
+		listed.GoodMoves, listed.Findings, listed.Notes = nil, nil, nil
+	}
+	if pg.offset > 0 {
+		listed.Themes = nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(formatGameReview(p, &listed))
+	sb.WriteString("\n---\n")
+	if pg.blundersOnly {
+		sb.WriteString(p.Sprintf("Listed %d of %d blunders, starting at number %d.", len(mistakes), total, pg.offset+1))
+	} else {
+		sb.WriteString(p.Sprintf("Listed %d of %d mistakes, starting at number %d.", len(mistakes), total, pg.offset+1))
+	}
+	if pg.summarize {
+		sb.WriteString(" " + p.T("The costliest are listed first."))
+	}
+	if next := pg.offset + len(mistakes); next < total {
+		sb.WriteString(" " + p.Sprintf("Call findMistakes with offset %d for the next ones.", next))
+	}
+	sb.WriteString(" " + p.T("Call getMoveDetails with a move number for KataGo's candidates at that move.") + "\n")
+	return sb.String(), nil
+}
+
+// formatGameReview formats a game review as markdown in p's language.
+// Mistakes that tenuki from a hot area are listed in their own section.
+func formatGameReview(p *i18n.Printer, review *katago.GameReview) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", p.T("Game Review")))
+	if review.Game != nil {
+		sb.WriteString(formatGameInfo(p, review.Game) + "\n\n")
+	}
+
+	// Summary
+	sb.WriteString(fmt.Sprintf("## %s\n", p.T("Summary")))
+	sb.WriteString(fmt.Sprintf("- %s: %d\n", p.T("Total moves"), review.Summary.TotalMoves))
+	if review.Summary.Partial {
+		sb.WriteString("- " + p.Sprintf("**Partial review**: deadline reached after %d of %d moves",
+			review.Summary.AnalyzedMoves, review.Summary.TotalMoves) + "\n")
+	}
+	if review.Summary.DecidedAtMove > 0 {
+		sb.WriteString("- " + p.Sprintf("**Decided** after move %d: later moves were analyzed at minimal visits and not judged",
+			review.Summary.DecidedAtMove) + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("- %s: %.1f%%\n", p.T("Black accuracy"), review.Summary.BlackAccuracy))
+	sb.WriteString(fmt.Sprintf("- %s: %.1f%%\n", p.T("White accuracy"), review.Summary.WhiteAccuracy))
+	sb.WriteString(fmt.Sprintf("- %s: %.1f%%/%.1f%%\n", p.T("Black top-1/top-3 agreement"),
+		review.Summary.BlackTop1Agreement, review.Summary.BlackTop3Agreement))
+	sb.WriteString(fmt.Sprintf("- %s: %.1f%%/%.1f%%\n", p.T("White top-1/top-3 agreement"),
+		review.Summary.WhiteTop1Agreement, review.Summary.WhiteTop3Agreement))
+	sb.WriteString(fmt.Sprintf("- %s: %.1f%%/%.1f%%\n", p.T("Average policy of moves played (Black/White)"),
+		review.Summary.BlackAveragePolicy*100, review.Summary.WhiteAveragePolicy*100))
+	sb.WriteString(fmt.Sprintf("- %s: %d/%d\n", p.T("Black mistakes/blunders"),
+		review.Summary.BlackMistakes, review.Summary.BlackBlunders))
+	sb.WriteString(fmt.Sprintf("- %s: %d/%d\n", p.T("White mistakes/blunders"),
+		review.Summary.WhiteMistakes, review.Summary.WhiteBlunders))
+	if review.Summary.BlackTenukis > 0 || review.Summary.WhiteTenukis > 0 {
+		sb.WriteString(fmt.Sprintf("- %s: %d/%d\n", p.T("Tenuki from hot areas (Black/White)"),
+			review.Summary.BlackTenukis, review.Summary.WhiteTenukis))
+	}
+	if len(review.Mistakes) > 0 {
+		sb.WriteString(fmt.Sprintf("- %s: %d/%d\n", p.T("Black mistakes in hard/easy positions"),
+			review.Summary.BlackHardMistakes, review.Summary.BlackEasyMistakes))
+		sb.WriteString(fmt.Sprintf("- %s: %d/%d\n", p.T("White mistakes in hard/easy positions"),
+			review.Summary.WhiteHardMistakes, review.Summary.WhiteEasyMistakes))
+	}
+
+	if review.Summary.EstimatedLevel != "" {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", p.T("Estimated level"), p.T(review.Summary.EstimatedLevel)))
+	}
+	if check := review.Summary.Result; check != nil {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", p.T("Recorded result"), check.Recorded))
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", p.T("KataGo's estimate"), check.Estimated))
+		if check.Disputed() {
+			sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T("Result disputed"), check.Explanation))
+		} else if check.Explanation != "" {
+			sb.WriteString(fmt.Sprintf("- %s\n", check.Explanation))
+		}
+	}
+
+	var mistakes, tenukis []*katago.Mistake
+	for i := range review.Mistakes {
+		if review.Mistakes[i].Tenuki {
+			tenukis = append(tenukis, &review.Mistakes[i])
+		} else {
+			mistakes = append(mistakes, &review.Mistakes[i])
+		}
+	}
+
+	// Mistakes
+	if len(mistakes) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", p.T("Mistakes Found")))
+		for _, mistake := range mistakes {
+			sb.WriteString("### " + p.Sprintf("Move %d (%s)", mistake.MoveNumber, moverName(p, mistake.Player, mistake.Color)) + "\n")
+			sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T("Category"), p.T(mistake.Category)))
+			sb.WriteString(fmt.Sprintf("- **%s**: %s (%s)\n", p.T("Played"),
+				mistake.PlayedMove, p.Sprintf("%.1f%% WR", mistake.PlayedWR*100)))
+			sb.WriteString(fmt.Sprintf("- **%s**: %s (%s)\n", p.T("Better"),
+				mistake.BestMove, p.Sprintf("%.1f%% WR", mistake.BestWR*100)))
+			sb.WriteString(fmt.Sprintf("- **%s**: %.1f%%\n", p.T("Win rate drop"), mistake.WinrateDrop*100))
+			difficulty := p.T("easy")
+			if mistake.Hard() {
+				difficulty = p.T("hard")
+			}
+			sb.WriteString(fmt.Sprintf("- **%s**: %.2f (%s)\n", p.T("Difficulty"), mistake.Difficulty, difficulty))
+			if mistake.Theme != "" {
+				sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T("Theme"), katago.ThemeName(p, mistake.Theme)))
+			}
+			if mistake.TimeLeft != nil {
+				sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T("Time left"), formatClock(*mistake.TimeLeft)))
+			}
+			sb.WriteString(fmt.Sprintf("- %s\n\n", mistake.Explanation))
+		}
+	} else if len(tenukis) == 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n", p.T("No significant mistakes found!")))
+	}
+
+	// Tenuki from hot areas
+	if len(tenukis) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", p.T("Tenuki From Hot Areas")))
+		sb.WriteString(p.T("These moves were played elsewhere while KataGo's top choices were all in one urgent area.") + "\n\n")
+		for _, mistake := range tenukis {
+			sb.WriteString("### " + p.Sprintf("Move %d (%s)", mistake.MoveNumber, moverName(p, mistake.Player, mistake.Color)) + "\n")
+			sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T("Category"), p.T(mistake.Category)))
+			sb.WriteString(fmt.Sprintf("- **%s**: %s (%s)\n", p.T("Played"),
+				mistake.PlayedMove, p.Sprintf("%.1f%% WR", mistake.PlayedWR*100)))
+			sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T("Hot area"),
+				p.Sprintf("%s (best: %s, %.1f%% WR)", strings.Join(mistake.HotArea, ", "), mistake.BestMove, mistake.BestWR*100)))
+			sb.WriteString(fmt.Sprintf("- **%s**: %.1f%%\n\n", p.T("Win rate drop"), mistake.WinrateDrop*100))
+		}
+	}
+
+	// The kinds of mistake that cost the most
+	if len(review.Themes) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", p.T("Themes to Work On")))
+		for i, theme := range review.Themes {
+			sb.WriteString(fmt.Sprintf("%d. **%s**: %s\n", i+1, katago.ThemeName(p, theme.Theme),
+				p.Sprintf("%d mistakes (moves %s), %.1f%% win rate lost", theme.Mistakes, theme.MoveList(), theme.WinrateLost*100)))
+			sb.WriteString(fmt.Sprintf("   %s\n", theme.Advice))
+		}
+	}
+
+	// Mistakes by the time left on the clock
+	if tp := review.TimePressure; tp != nil {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", p.T("Time Pressure")))
+		if tp.MainTime > 0 || tp.Overtime != "" {
+			control := formatClock(tp.MainTime)
+			if tp.Overtime != "" {
+				control += " + " + tp.Overtime
+			}
+			sb.WriteString(fmt.Sprintf("%s: %s\n\n", p.T("Time control"), control))
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", p.T("Time left"), p.T("Black moves"),
+			p.T("Black mistakes/blunders"), p.T("White moves"), p.T("White mistakes/blunders")))
+		sb.WriteString("|---|---|---|---|---|\n")
+		for _, r := range tp.Ranges {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %d/%d | %d | %d/%d |\n", p.T(r.Range),
+				r.BlackMoves, r.BlackMistakes, r.BlackBlunders, r.WhiteMoves, r.WhiteMistakes, r.WhiteBlunders))
+		}
+		for _, o := range tp.Observations {
+			sb.WriteString(fmt.Sprintf("\n- %s", o))
+		}
+		if len(tp.Observations) > 0 {
+			sb.WriteString("\n")
+		}
+	}
+
+	// Best moves
+	if len(review.GoodMoves) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", p.T("Best Moves")))
+		for _, good := range review.GoodMoves {
+			sb.WriteString("### " + p.Sprintf("Move %d (%s)", good.MoveNumber, moverName(p, good.Player, good.Color)) + "\n")
+			sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T("Category"), p.T(good.Category)))
+			sb.WriteString(fmt.Sprintf("- **%s**: %s (%s)\n", p.T("Played"),
+				good.PlayedMove, p.Sprintf("%.1f%% WR", good.PlayedWR*100)))
+			sb.WriteString(fmt.Sprintf("- %s\n\n", good.Explanation))
+		}
+	}
+
+	// What the analyzer plugins found
+	if len(review.Findings) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", p.T("Analyzer Findings")))
+		for _, f := range review.Findings {
+			sb.WriteString("### " + p.Sprintf("Move %d (%s)", f.MoveNumber, moverName(p, "", f.Color)) + "\n")
+			sb.WriteString(fmt.Sprintf("- **%s**: %s (%s)\n", p.T("Category"), f.Category, f.Analyzer))
+			if f.Explanation != "" {
+				sb.WriteString(fmt.Sprintf("- %s\n", f.Explanation))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	// Positions recreated by ko fights
+	if len(review.Repetitions) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", p.T("Repeated Positions")))
+		sb.WriteString(p.T("These moves recreated an earlier position, as in a triple ko. A position repeated with the same player to move was analyzed once.") + "\n\n")
+		for _, r := range review.Repetitions {
+			if r.RepeatsMove == 0 {
+				sb.WriteString("- " + p.Sprintf("Move %d (%s) repeats the starting position", r.MoveNumber, moverName(p, "", r.Color)) + "\n")
+			} else {
+				sb.WriteString("- " + p.Sprintf("Move %d (%s) repeats the position after move %d", r.MoveNumber, moverName(p, "", r.Color), r.RepeatsMove) + "\n")
+			}
+		}
+	}
+
+	// Comments and variations already in the SGF
+	if len(review.Notes) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", p.T("Existing Annotations")))
+		for _, note := range review.Notes {
+			sb.WriteString("### " + p.Sprintf("Move %d (%s)", note.MoveNumber, moverName(p, "", note.Color)) + "\n")
+			if note.Comment != "" {
+				sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T("Comment"), strings.Join(strings.Fields(note.Comment), " ")))
+			}
+			if note.PlayedMove != "" {
+				sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T("Played"), note.PlayedMove))
+			}
+			opinion := note.Opinion
+			if len(note.Variation) > 0 {
+				sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T("Variation"), strings.Join(note.Variation, " ")))
+				if opinion != "" {
+					opinion = p.Sprintf("The SGF suggests %s", note.Variation[0]) + "; " + opinion
+				}
+			}
+			if opinion != "" {
+				sb.WriteString(fmt.Sprintf("- %s\n", opinion))
+			}
+			if note.VariationWinrate != nil && note.VariationScoreLead != nil {
+				sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T("After the variation"),
+					p.Sprintf("Black win rate %.1f%%, score lead %+.1f", *note.VariationWinrate*100, *note.VariationScoreLead)))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// formatGameInfo renders a game's players, result, event and date in p's
+// language.
+func formatGameInfo(p *i18n.Printer, info *katago.GameInfo) string {
+	black, white := info.Player("B"), info.Player("W")
+	if black == "" {
+		black = "?"
+	}
+	if white == "" {
+		white = "?"
+	}
+	s := p.Sprintf("Black: %s vs White: %s", black, white)
+	if info.Result != "" {
+		s += ", " + p.Sprintf("result %s", info.Result)
+	}
+	var when []string
+	for _, v := range []string{info.Event, info.Date} {
+		if v != "" {
+			when = append(when, v)
+		}
+	}
+	if len(when) > 0 {
+		s += " (" + strings.Join(when, ", ") + ")"
+	}
+	return s
+}
+
+// formatClock renders seconds on a clock as m:ss, or h:mm:ss from an hour.
+func formatClock(seconds float64) string {
+	total := int(seconds)
+	if total >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", total/3600, total%3600/60, total%60)
+	}
+	return fmt.Sprintf("%d:%02d", total/60, total%60)
+}
+
+// moverName names who played a move: the player and their color when the
+// SGF names them, else the color.
+func moverName(p *i18n.Printer, player, color string) string {
+	if player != "" {
+		return fmt.Sprintf("%s, %s", player, p.T(color))
+	}
+	return p.T(color)
+}
+
+// HandleGetMoveDetails handles the getMoveDetails tool. It sends the
+// query findMistakes sends for the move, so a move from a review is
+// answered from the cache.
+func (h *ToolsHandler) HandleGetMoveDetails(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "getMoveDetails")
+
+	logger.Info("Handling getMoveDetails request")
+
+	engine, err := h.engineFor("getMoveDetails", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	sgf, ok := argsMap["sgf"].(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	game, err := katago.NewSGFParser(sgf).Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+	moveNumber, ok := argsMap["moveNumber"].(float64)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'moveNumber'")
+	}
+	n := int(moveNumber)
+	if n < 1 || n > len(game.Moves) {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "moveNumber must be between 1 and %d", len(game.Moves))
+	}
+
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
+	}
+
+	thresholds := h.mistakeThresholds(argsMap)
+	logger.Info("Examining move", "move", n)
+	result, err := engine.Analyze(ctx, katago.ReviewRequest(game, n, thresholds))
+	if err != nil {
+		logger.Error("Failed to analyze move: %v", err)
+		return nil, fmt.Errorf("failed to analyze move %d: %w", n, err)
+	}
+	detail, err := katago.DescribeMove(game, n, result, thresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(detail, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatMoveDetail(detail, game.GameInfo.Player(detail.Color))), nil
+}
+
+// formatMoveDetail formats a move detail as markdown. player names who
+// played the move, if the SGF does.
+func formatMoveDetail(detail *katago.MoveDetail, player string) string {
+	color := "Black"
+	if detail.Color == "W" {
+		color = "White"
+	}
+	if player != "" {
+		color = player + ", " + color
+	}
+	played := detail.PlayedMove
+	if played == "" {
+		played = "pass"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Move %d (%s): %s\n\n", detail.MoveNumber, color, played))
+	if detail.Category != "" {
+		sb.WriteString(fmt.Sprintf("- **Category**: %s\n", detail.Category))
+	} else {
+		sb.WriteString("- **Category**: no mistake\n")
+	}
+	sb.WriteString(fmt.Sprintf("- **Win rate drop**: %.1f%%\n", detail.WinrateDrop*100))
+	sb.WriteString(fmt.Sprintf("- **Before the move**: win rate %.1f%%, score lead %+.1f (%d visits)\n",
+		detail.Before.Winrate*100, detail.Before.ScoreLead, detail.Before.Visits))
+	if detail.Played != nil {
+		sb.WriteString(fmt.Sprintf("- **Played**: %s, KataGo's choice #%d (%.1f%% WR, policy %.1f%%)\n",
+			played, detail.Rank, detail.Played.Winrate*100, detail.Prior*100))
+	} else {
+		sb.WriteString(fmt.Sprintf("- **Played**: %s, not among KataGo's choices (policy %.1f%%)\n", played, detail.Prior*100))
+	}
+
+	sb.WriteString("\n## KataGo's Candidates\n\n")
+	sb.WriteString("| Move | Win rate | Score lead | Visits | Policy | Variation |\n")
+	sb.WriteString("|------|----------|------------|--------|--------|-----------|\n")
+	for _, c := range detail.Candidates {
+		sb.WriteString(fmt.Sprintf("| %s | %.1f%% | %+.1f | %d | %.1f%% | %s |\n",
+			c.Move, c.Winrate*100, c.ScoreLead, c.Visits, c.Prior*100, strings.Join(c.PV, " ")))
+	}
+	return sb.String()
+}
+
+// HandleKeyMoments handles the keyMoments tool.
+func (h *ToolsHandler) HandleKeyMoments(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "keyMoments")
+
+	logger.Info("Handling keyMoments request")
+
+	engine, err := h.engineFor("keyMoments", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Get SGF content
+	sgfVal, ok := argsMap["sgf"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	sgf, ok := sgfVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+	}
+
+	opts := &katago.KeyMomentOptions{}
+	if val, ok := argsMap["count"].(float64); ok {
+		opts.Count = int(val)
+	}
+	opts.MaxVisits = h.maxVisits("keyMoments", argsMap)
+
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
+	}
+
+	moments, err := engine.KeyMoments(ctx, sgf, opts)
+	if err != nil {
+		logger.Error("Failed to find key moments: %v", err)
+		return nil, fmt.Errorf("failed to find key moments: %w", err)
+	}
+	logger.Debug("Key moments found", "moments", len(moments.Moments), "partial", moments.Partial)
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(moments, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatKeyMoments(moments)), nil
+}
+
+// formatKeyMoments formats key moments as a list in move order.
+func formatKeyMoments(moments *katago.KeyMoments) string {
+	var sb strings.Builder
+	sb.WriteString("# Key Moments\n\n")
+	sb.WriteString(fmt.Sprintf("%d of %d moves analyzed.\n", moments.MovesAnalyzed, moments.TotalMoves))
+	if moments.Partial {
+		sb.WriteString("**Partial result**: some positions could not be analyzed\n")
+	}
+	if len(moments.Moments) == 0 {
+		sb.WriteString("\nNo pivotal moves found.\n")
+		return sb.String()
+	}
+	sb.WriteString("\n")
+	for _, m := range moments.Moments {
+		sb.WriteString(fmt.Sprintf("- **Move %d** (%s %s, %s): %s\n",
+			m.MoveNumber, colorName(m.Color), m.Move, m.Kind, m.Annotation))
+	}
+	return sb.String()
+}
+
+// HandleEvaluateTerritory handles the evaluateTerritory tool.
+func (h *ToolsHandler) HandleEvaluateTerritory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "evaluateTerritory")
+
+	logger.Info("Handling evaluateTerritory request")
+
+	engine, err := h.engineFor("evaluateTerritory", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Get SGF content
+	sgfVal, ok := argsMap["sgf"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	sgf, ok := sgfVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+	}
+
+	// Parse SGF
+	parser := katago.NewSGFParser(sgf)
+	position, err := parser.Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+
+	// Get threshold
+	threshold := 0.85
+	if val, ok := argsMap["threshold"]; ok {
+		if t, ok := val.(float64); ok && t > 0 && t <= 1 {
+			threshold = t
+		}
+	}
+
+	// Estimate territory
+	logger.Info("Estimating territory", "threshold", threshold)
+	estimate, err := engine.EstimateTerritory(ctx, position, threshold)
+	if err != nil {
+		logger.Error("Failed to estimate territory: %v", err)
+		return nil, fmt.Errorf("failed to estimate territory: %w", err)
+	}
+	logger.Debug("Territory estimation completed")
+
+	// Check if detailed estimates requested
+	includeEstimates := false
+	if val, ok := argsMap["includeEstimates"]; ok {
+		if b, ok := val.(bool); ok {
+			includeEstimates = b
+		}
+	}
+
+	// Format result
+	if includeEstimates {
+		// Return JSON with full details
+		resultJSON, err := json.MarshalIndent(estimate, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+
+	// Return visualization
+	viz := katago.GetTerritoryVisualization(estimate)
+	return mcp.NewToolResultText(viz), nil
+}
+
+// HandleEstimateScoreDistribution handles the estimateScoreDistribution tool.
+func (h *ToolsHandler) HandleEstimateScoreDistribution(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "estimateScoreDistribution")
+
+	logger.Info("Handling estimateScoreDistribution request")
+
+	engine, err := h.engineFor("estimateScoreDistribution", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Get SGF content
+	sgfVal, ok := argsMap["sgf"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	sgf, ok := sgfVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+	}
+
+	// Parse SGF
+	parser := katago.NewSGFParser(sgf)
+	position, err := parser.Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+
+	// Handle move number
+	if val, ok := argsMap["moveNumber"]; ok {
+		if moveNum, ok := val.(float64); ok && int(moveNum) > 0 && int(moveNum) < len(position.Moves) {
+			position.Moves = position.Moves[:int(moveNum)]
+		}
+	}
+
+	req := &katago.AnalysisRequest{Position: position, IncludeScoreDistribution: true}
+	if maxVisits := h.maxVisits("estimateScoreDistribution", argsMap); maxVisits > 0 {
+		req.MaxVisits = &maxVisits
+	}
+	if maxTime := h.maxTime("estimateScoreDistribution", argsMap); maxTime > 0 {
+		req.MaxTime = &maxTime
+	}
+
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
+	}
+
+	result, err := engine.Analyze(ctx, req)
+	if err != nil {
+		logger.Error("Failed to estimate score distribution: %v", err)
+		return nil, fmt.Errorf("analysis failed: %w", err)
+	}
+	distribution := katago.NewScoreDistribution(result, position.BoardXSize, position.BoardYSize)
+	logger.Debug("Score distribution estimated", "stdev", distribution.ScoreStdev, "contested", distribution.ContestedPoints)
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(distribution, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatScoreDistribution(distribution)), nil
+}
+
+// formatScoreDistribution formats a score distribution as markdown.
+func formatScoreDistribution(d *katago.ScoreDistribution) string {
+	var sb strings.Builder
+	sb.WriteString("# Score Distribution\n\n")
+	sb.WriteString(fmt.Sprintf("**Expected result**: %s\n", d.Estimate))
+	sb.WriteString(fmt.Sprintf("Black win rate %.1f%%, %s to play, %d visits\n\n", d.BlackWinrate*100, d.CurrentPlayer, d.Visits))
+
+	sb.WriteString("| Percentile | Result |\n")
+	sb.WriteString("|------------|--------|\n")
+	for _, p := range d.Percentiles {
+		sb.WriteString(fmt.Sprintf("| %d%% | %s |\n", p.Percentile, p.Result))
+	}
+	sb.WriteString("\nPercentiles assume the final score is normally distributed around KataGo's estimate.\n")
+
+	if d.ContestedPoints > 0 {
+		sb.WriteString(fmt.Sprintf("\n## Contested Points\n%d points have an uncertain owner. Most uncertain:\n", d.ContestedPoints))
+		for _, p := range d.MostUncertain {
+			sb.WriteString(fmt.Sprintf("- %s: ownership %+.2f ± %.2f\n", p.Point, p.Ownership, p.Stdev))
+		}
+	}
+	return sb.String()
+}
+
+// defaultCountingThreshold is the ownership above which a counting quiz
+// counts a point for a player. Unlike a territory estimate, a count gives
+// every point an owner unless it is evenly split.
+const defaultCountingThreshold = 0.5
+
+// HandleCountingQuiz handles the countingQuiz tool. The position is only
+// analyzed once a guess is made, so the quiz reveals nothing before.
+func (h *ToolsHandler) HandleCountingQuiz(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "countingQuiz")
+
+	logger.Info("Handling countingQuiz request")
+
+	engine, err := h.engineFor("countingQuiz", request)
+	if err != nil {
+		return nil, err
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	sgf, ok := argsMap["sgf"].(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	position, err := katago.NewSGFParser(sgf).Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+	if val, ok := argsMap["moveNumber"]; ok {
+		if moveNum, ok := val.(float64); ok && int(moveNum) > 0 && int(moveNum) < len(position.Moves) {
+			position.Moves = position.Moves[:int(moveNum)]
+		}
+	}
+	board, err := katago.BoardFromPosition(position)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "invalid position")
+	}
+
+	guessVal, ok := argsMap["guess"]
+	if !ok {
+		return mcp.NewToolResultText(formatCountingQuestion(position, board)), nil
+	}
+	guess, err := parseCountingGuess(guessVal)
+	if err != nil {
+		return nil, err
+	}
+	var regionGuesses map[string]float64
+	if val, ok := argsMap["regionGuesses"]; ok {
+		guesses, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "regionGuesses must be an object")
+		}
+		regionGuesses = make(map[string]float64, len(guesses))
+		for region, val := range guesses {
+			lead, ok := val.(float64)
+			if !ok {
+				return nil, apperrors.New(apperrors.CodeInvalidArgument, "regionGuesses[%q] must be a number", region)
+			}
+			regionGuesses[region] = lead
+		}
+	}
+	threshold := defaultCountingThreshold
+	if val, ok := argsMap["threshold"]; ok {
+		if t, ok := val.(float64); ok && t > 0 && t <= 1 {
+			threshold = t
+		}
+	}
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	req := &katago.AnalysisRequest{Position: position, IncludeOwnership: true}
+	if maxVisits := h.maxVisits("countingQuiz", argsMap); maxVisits > 0 {
+		req.MaxVisits = &maxVisits
+	}
+	if maxTime := h.maxTime("countingQuiz", argsMap); maxTime > 0 {
+		req.MaxTime = &maxTime
+	}
+	result, err := engine.Analyze(ctx, req)
+	if err != nil {
+		logger.Error("Failed to count position: %v", err)
+		return nil, fmt.Errorf("analysis failed: %w", err)
+	}
+	answer, err := katago.GradeCounting(position, result, threshold, guess, regionGuesses)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "failed to grade the count")
+	}
+	logger.Debug("Counting quiz graded", "guess", answer.GuessResult, "result", answer.Result)
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(answer, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatCountingAnswer(answer, threshold)), nil
+}
+
+// parseCountingGuess parses a guessed result, such as "B+3.5", into
+// Black's lead.
+func parseCountingGuess(val interface{}) (float64, error) {
+	s, _ := val.(string)
+	result, ok := katago.ParseResult(s)
+	if !ok || (result.Winner != "" && result.Margin == 0) {
+		return 0, apperrors.New(apperrors.CodeInvalidArgument, "guess must be a result such as 'B+3.5', 'W+0.5' or '0'")
+	}
+	if result.Winner == "W" {
+		return -result.Margin, nil
+	}
+	return result.Margin, nil
+}
+
+// formatCountingQuestion shows the position to count, without KataGo's
+// estimate.
+func formatCountingQuestion(position *katago.Position, board *katago.Board) string {
+	toPlay := "Black"
+	if n := len(position.Moves); n > 0 && strings.EqualFold(position.Moves[n-1].Color, "B") ||
+		n == 0 && strings.EqualFold(position.InitialPlayer, "W") {
+		toPlay = "White"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Counting Quiz\n\n")
+	sb.WriteString("```\n" + board.String() + "```\n\n")
+	sb.WriteString(fmt.Sprintf("Move %d, %s to play, komi %.1f.\n\n", len(position.Moves), toPlay, position.Komi))
+	sb.WriteString("Count the position and call countingQuiz again with your `guess` of the result, komi included, such as \"B+3.5\" or \"W+0.5\". ")
+	sb.WriteString(fmt.Sprintf("To check your count region by region, add `regionGuesses` with Black's lead in points, stones included and negative for White, in any of %s. ",
+		strings.Join(katago.CountingRegions, ", ")))
+	sb.WriteString("On odd-sized boards the center lines count with the top and left quarters.\n")
+	return sb.String()
+}
+
+// formatCountingAnswer formats KataGo's count and how the guess compares,
+// as markdown.
+func formatCountingAnswer(answer *katago.CountingAnswer, threshold float64) string {
+	var sb strings.Builder
+	sb.WriteString("# Counting Quiz: Answer\n\n")
+	sb.WriteString(fmt.Sprintf("- **Your count**: %s\n", answer.GuessResult))
+	sb.WriteString(fmt.Sprintf("- **KataGo's count**: %s (komi %.1f included)\n", answer.Result, answer.Komi))
+	switch {
+	case answer.Correct:
+		sb.WriteString(fmt.Sprintf("\nCorrect: your count is within %.1f points of KataGo's.\n", math.Abs(answer.Error)))
+	case answer.Error > 0:
+		sb.WriteString(fmt.Sprintf("\nYour count favors Black by %.1f points too many.\n", answer.Error))
+	default:
+		sb.WriteString(fmt.Sprintf("\nYour count favors White by %.1f points too many.\n", -answer.Error))
+	}
+	if answer.Guess*answer.ScoreLead < 0 {
+		sb.WriteString("You picked the wrong winner.\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\nKataGo gives Black %d points and White %d, stones included; %d points are undecided (ownership within ±%.2f).\n\n",
+		answer.BlackPoints, answer.WhitePoints, answer.DamePoints, threshold))
+	sb.WriteString("| Region | Black | White | Black's lead | Your guess | Off by |\n")
+	sb.WriteString("|--------|-------|-------|--------------|------------|--------|\n")
+	var worst *katago.RegionCount
+	for i, r := range answer.Regions {
+		guess, off := "-", "-"
+		if r.Guess != nil {
+			guess, off = fmt.Sprintf("%+.1f", *r.Guess), fmt.Sprintf("%+.1f", *r.Error)
+			if worst == nil || math.Abs(*r.Error) > math.Abs(*worst.Error) {
+				worst = &answer.Regions[i]
+			}
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %+d | %s | %s |\n", r.Region, r.Black, r.White, r.Lead, guess, off))
+	}
+	if worst != nil && math.Abs(*worst.Error) > 0 {
+		sb.WriteString(fmt.Sprintf("\nYour largest miss is the %s: you counted %+.1f for Black, KataGo %+d.\n", worst.Region, *worst.Guess, worst.Lead))
+	}
+
+	var dead []string
+	for _, r := range answer.Regions {
+		dead = append(dead, r.DeadStones...)
+	}
+	if len(dead) > 0 {
+		sb.WriteString(fmt.Sprintf("\nDead stones, counted for the other player: %s\n", strings.Join(dead, " ")))
+	}
+	sb.WriteString("\nRegion leads add up to Black's lead on the board before komi. KataGo's count also weighs the undecided points, so the two need not match exactly.\n")
+	return sb.String()
+}
+
+// HandleTerritoryTimeline handles the territoryTimeline tool.
+func (h *ToolsHandler) HandleTerritoryTimeline(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "territoryTimeline")
+
+	logger.Info("Handling territoryTimeline request")
+
+	engine, err := h.engineFor("territoryTimeline", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Get SGF content
+	sgfVal, ok := argsMap["sgf"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	sgf, ok := sgfVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+	}
+
+	opts := &katago.TimelineOptions{}
+	if val, ok := argsMap["interval"].(float64); ok {
+		opts.Interval = int(val)
+	}
+	opts.MaxVisits = h.maxVisits("territoryTimeline", argsMap)
+	if val, ok := argsMap["threshold"].(float64); ok {
+		opts.Threshold = val
+	}
+	if val, ok := argsMap["swing"].(float64); ok {
+		opts.Swing = int(val)
+	}
+
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
+	}
+
+	timeline, err := engine.TerritoryTimeline(ctx, sgf, opts)
+	if err != nil {
+		logger.Error("Failed to build territory timeline: %v", err)
+		return nil, fmt.Errorf("failed to build territory timeline: %w", err)
+	}
+	logger.Debug("Territory timeline completed", "points", len(timeline.Points), "swings", len(timeline.Swings), "partial", timeline.Partial)
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(timeline, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatTerritoryTimeline(timeline)), nil
+}
+
+// formatTerritoryTimeline formats a territory timeline as a markdown table
+// followed by its swings.
+func formatTerritoryTimeline(timeline *katago.TerritoryTimeline) string {
+	var sb strings.Builder
+	sb.WriteString("# Territory Timeline\n\n")
+	sb.WriteString(fmt.Sprintf("Positions analyzed every %d moves.\n", timeline.Interval))
+	if timeline.Partial {
+		sb.WriteString("**Partial timeline**: some positions could not be analyzed\n")
+	}
+
+	swingEnds := make(map[int]bool, len(timeline.Swings))
+	for _, swing := range timeline.Swings {
+		swingEnds[swing.ToMove] = true
+	}
+	sb.WriteString("\n| Move | Black | White | Dame | Balance | Score lead |\n")
+	sb.WriteString("|------|-------|-------|------|---------|------------|\n")
+	for _, p := range timeline.Points {
+		marker := ""
+		if swingEnds[p.MoveNumber] {
+			marker = " *"
+		}
+		sb.WriteString(fmt.Sprintf("| %d%s | %d | %d | %d | %+d | %+.1f |\n",
+			p.MoveNumber, marker, p.BlackTerritory, p.WhiteTerritory, p.DamePoints, p.Balance, p.ScoreLead))
+	}
+
+	if len(timeline.Swings) == 0 {
+		sb.WriteString("\nNo large territorial swings.\n")
+		return sb.String()
+	}
+	sb.WriteString("\n## Swings\nMoves marked * in the table end a swing.\n\n")
+	for _, swing := range timeline.Swings {
+		points := swing.Swing
+		if points < 0 {
+			points = -points
+		}
+		sb.WriteString(fmt.Sprintf("- Moves %d-%d: %d points to %s (Black %+d, White %+d)\n",
+			swing.FromMove, swing.ToMove, points, colorName(swing.Favors), swing.BlackChange, swing.WhiteChange))
+	}
+	return sb.String()
+}
+
+// HandleExplainMove handles the explainMove tool.
+func (h *ToolsHandler) HandleExplainMove(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "explainMove")
+
+	logger.Info("Handling explainMove request")
+
+	engine, err := h.engineFor("explainMove", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Get SGF content
+	sgfVal, ok := argsMap["sgf"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	sgf, ok := sgfVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+	}
+
+	// Get move to explain
+	moveVal, ok := argsMap["move"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'move'")
+	}
+	move, ok := moveVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "move must be a string")
+	}
+
+	// Parse SGF
+	parser := katago.NewSGFParser(sgf)
+	position, err := parser.Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+
+	p, err := h.printerFor(argsMap)
+	if err != nil {
+		return nil, err
+	}
+	ctx = i18n.WithPrinter(ctx, p)
+
+	// Get explanation
+	logger.Info("Explaining move", "move", move)
+	opts := &katago.ExplainOptions{
+		MaxVisits: h.maxVisits("explainMove", argsMap),
+		MaxTime:   h.maxTime("explainMove", argsMap),
+	}
+	explanation, err := engine.ExplainMove(ctx, position, move, opts)
+	if err != nil {
+		logger.Error("Failed to explain move: %v", err)
+		return nil, fmt.Errorf("failed to explain move: %w", err)
+	}
+	logger.Debug("Move explanation completed", "winrate", explanation.Winrate)
+
+	return mcp.NewToolResultText(formatMoveExplanation(p, move, explanation)), nil
+}
+
+// formatMoveExplanation formats a move explanation as markdown in p's
+// language.
+func formatMoveExplanation(p *i18n.Printer, move string, explanation *katago.MoveExplanation) string {
+	var sb strings.Builder
+	sb.WriteString("# " + p.Sprintf("Move Explanation: %s", move) + "\n\n")
+	sb.WriteString(fmt.Sprintf("%s\n\n", explanation.Explanation))
+
+	// Stats
+	sb.WriteString(fmt.Sprintf("## %s\n", p.T("Statistics")))
+	sb.WriteString(fmt.Sprintf("- %s: %.1f%%\n", p.T("Win rate"), explanation.Winrate*100))
+	sb.WriteString(fmt.Sprintf("- %s: %s\n", p.T("Score lead"), p.Sprintf("%.1f points", explanation.ScoreLead)))
+	sb.WriteString(fmt.Sprintf("- %s: %d\n\n", p.T("Engine visits"), explanation.Visits))
+
+	// Strategic info, translated here since the engine leaves it in English
+	sb.WriteString(fmt.Sprintf("## %s\n", p.T("Strategic Analysis")))
+	sb.WriteString(fmt.Sprintf("- %s: %s\n", p.T("Board region"), p.T(explanation.Strategic.BoardRegion)))
+	sb.WriteString(fmt.Sprintf("- %s: %s\n", p.T("Urgency"), p.T(explanation.Strategic.Urgency)))
+	if explanation.Ownership != nil {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", p.T("Ownership change"),
+			p.Sprintf("%.1f points", explanation.Ownership.ScoreChange)))
+	}
+	if len(explanation.Strategic.Purpose) > 0 {
+		purposes := make([]string, len(explanation.Strategic.Purpose))
+		for i, purpose := range explanation.Strategic.Purpose {
+			purposes[i] = p.T(purpose)
+		}
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", p.T("Purpose"), strings.Join(purposes, ", ")))
+	}
+
+	// Pros and cons
+	if len(explanation.Pros) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n", p.T("Pros")))
+		for _, pro := range explanation.Pros {
+			sb.WriteString(fmt.Sprintf("- %s\n", pro))
+		}
+	}
+
+	if len(explanation.Cons) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n", p.T("Cons")))
+		for _, con := range explanation.Cons {
+			sb.WriteString(fmt.Sprintf("- %s\n", con))
+		}
+	}
+
+	// Go terms
+	if len(explanation.Terms) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n", p.T("Go Terms")))
+		for _, term := range explanation.Terms {
+			sb.WriteString(fmt.Sprintf("- **%s**: %s\n", p.T(term.Term), term.Definition))
+		}
+	}
+
+	// Opponent's replies
+	if len(explanation.Replies) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n", p.T("Opponent's Best Replies")))
+		sb.WriteString(p.Sprintf("Expect %s next.", explanation.Replies[0].Move) + "\n")
+		for _, reply := range explanation.Replies {
+			sb.WriteString(fmt.Sprintf("- **%s** (%s)", reply.Move, p.Sprintf("%.1f%% WR", reply.Winrate*100)))
+			if len(reply.Threats) > 0 {
+				sb.WriteString(": " + strings.Join(reply.Threats, "; "))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	// Alternatives
+	if len(explanation.Alternatives) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## %s\n", p.T("Better Alternatives")))
+		for _, alt := range explanation.Alternatives {
+			sb.WriteString(fmt.Sprintf("- **%s** (%s): %s\n",
+				alt.Move, p.Sprintf("%.1f%% WR", alt.Winrate*100), alt.Reasoning))
+		}
+	}
+
+	return sb.String()
+}
+
+// HandleClearCache handles the clearCache tool.
+func (h *ToolsHandler) HandleClearCache(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "clearCache")
+
+	logger.Info("Handling clearCache request")
+
+	if h.cache == nil || !h.cache.IsEnabled() {
+		logger.Debug("Cache not enabled")
+		return mcp.NewToolResultText("Cache is not enabled"), nil
+	}
+
+	if !h.admin(ctx, request) {
+		// Under strict isolation a client may clear its own namespace's
+		// results, which no one else can read anyway
+		namespace, ok := katago.CacheNamespaceFromContext(ctx)
+		if !ok {
+			return nil, h.requireAdmin(ctx, request, "clearCache")
+		}
+		removed, err := h.cache.ClearPrefix(katago.CacheNamespacePrefix(namespace))
+		if err != nil {
+			logger.Error("Failed to clear shared cache", "namespace", namespace, "error", err)
+			return nil, err
+		}
+		logger.Info("Namespace cache cleared", "namespace", namespace, "items", removed)
+		return mcp.NewToolResultText(fmt.Sprintf("Cleared %d cached entries of namespace %s", removed, namespace)), nil
+	}
+
+	before := h.cache.Stats()
+	if err := h.cache.Clear(); err != nil {
+		logger.Error("Failed to clear shared cache", "error", err)
+		return nil, err
+	}
+
+	logger.Info("Cache cleared", "items", before.Items, "sizeBytes", before.Size)
+	return mcp.NewToolResultText(fmt.Sprintf("Cleared %d cached entries (%d bytes)", before.Items, before.Size)), nil
+}
+
+// HandleEngineRecording handles the engineRecording tool.
+func (h *ToolsHandler) HandleEngineRecording(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "engineRecording")
+
+	logger.Info("Handling engineRecording request")
+
+	if err := h.requireAdmin(ctx, request, "engineRecording"); err != nil {
+		return nil, err
+	}
+	engine, err := h.engineFor("engineRecording", request)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := 0
+	if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if val, ok := argsMap["limit"]; ok {
+			v, ok := val.(float64)
+			if !ok || v < 1 {
+				return nil, apperrors.New(apperrors.CodeInvalidArgument, "limit must be a positive number")
+			}
+			limit = int(v)
+		}
+	}
+
+	lines := engine.Recording()
+	if lines == nil {
+		logger.Debug("Engine recording not enabled")
+		return mcp.NewToolResultText("Engine recording is not enabled"), nil
+	}
+	if limit > 0 && len(lines) > limit {
+		lines = lines[len(lines)-limit:]
+	}
+
+	var sb strings.Builder
+	if err := katago.WriteRecording(&sb, lines); err != nil {
+		return nil, fmt.Errorf("failed to format recording: %w", err)
+	}
+	logger.Info("Engine recording dumped", "lines", len(lines))
+	return mcp.NewToolResultText(sb.String()), nil
+}
+
+// HandleGetDiagnostics handles the getDiagnostics tool.
+func (h *ToolsHandler) HandleGetDiagnostics(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "getDiagnostics")
+
+	logger.Info("Handling getDiagnostics request")
+
+	if err := h.requireAdmin(ctx, request, "getDiagnostics"); err != nil {
+		return nil, err
+	}
+	engine, err := h.engineFor("getDiagnostics", request)
+	if err != nil {
+		return nil, err
+	}
+
+	format := "text"
+	if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if val, ok := argsMap["format"]; ok {
+			format, _ = val.(string)
+			if format != "text" && format != "json" {
+				return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+			}
+		}
+	}
+
+	bundle := engine.Diagnostics()
+	if bundle == nil {
+		logger.Debug("No diagnostics collected")
+		return mcp.NewToolResultText("No engine crash has been diagnosed since the server started"), nil
+	}
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatDiagnostics(bundle)), nil
+}
+
+// formatDiagnostics formats a diagnostics bundle as markdown.
+func formatDiagnostics(bundle *katago.DiagnosticsBundle) string {
+	var sb strings.Builder
+	sb.WriteString("# Engine Diagnostics\n\n")
+	sb.WriteString(fmt.Sprintf("- **Engine**: %s\n", bundle.Engine))
+	sb.WriteString(fmt.Sprintf("- **Collected**: %s\n", bundle.Time.Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("- **Reason**: %s\n", bundle.Reason))
+	switch {
+	case bundle.Dir != "":
+		sb.WriteString(fmt.Sprintf("- **Written to**: %s\n", bundle.Dir))
+	case bundle.WriteError != "":
+		sb.WriteString(fmt.Sprintf("- **Not written**: %s\n", bundle.WriteError))
+	}
+
+	sb.WriteString("\n## Engine\n\n")
+	sb.WriteString(fmt.Sprintf("- **Binary**: %s\n", bundle.Config.BinaryPath))
+	if s := bundle.Settings; s != nil {
+		if s.KataGoVersion != "" {
+			sb.WriteString(fmt.Sprintf("- **KataGo**: %s\n", s.KataGoVersion))
+		}
+		for _, model := range []*katago.ModelFile{s.Model, s.HumanModel} {
+			if model != nil {
+				sb.WriteString(fmt.Sprintf("- **Model**: %s (%d bytes, sha256 %s)\n", model.Path, model.SizeBytes, model.SHA256))
+			}
+		}
+		if s.ConfigFile != nil {
+			sb.WriteString(fmt.Sprintf("- **Config**: %s\n", s.ConfigFile.Path))
+		}
+	} else if bundle.SettingsError != "" {
+		sb.WriteString(fmt.Sprintf("- **Settings unavailable**: %s\n", bundle.SettingsError))
+	}
+	if bundle.Startup.Phase != "" {
+		sb.WriteString(fmt.Sprintf("- **Startup**: %s, %d%%", bundle.Startup.Phase, bundle.Startup.Percent))
+		if bundle.Startup.Backend != "" || bundle.Startup.GPU != "" {
+			sb.WriteString(fmt.Sprintf(" (%s %s)", bundle.Startup.Backend, bundle.Startup.GPU))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("- **Queries outstanding**: %d pending, %d queued\n", bundle.Load.Pending, bundle.Load.Queued))
+
+	sys := bundle.System
+	sb.WriteString("\n## System\n\n")
+	sb.WriteString(fmt.Sprintf("- **OS**: %s/%s, %d CPUs, %s\n", sys.OS, sys.Arch, sys.CPUs, sys.GoVersion))
+	if sys.Hostname != "" {
+		sb.WriteString(fmt.Sprintf("- **Host**: %s\n", sys.Hostname))
+	}
+	for _, gpu := range sys.GPUs {
+		sb.WriteString(fmt.Sprintf("- **GPU**: %s\n", gpu))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n## Last stderr lines (%d)\n\n```\n", len(bundle.Stderr)))
+	for _, line := range bundle.Stderr {
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("```\n")
+
+	sb.WriteString(fmt.Sprintf("\n## Last queries (%d)\n\n```jsonl\n", len(bundle.Queries)))
+	if err := katago.WriteRecording(&sb, bundle.Queries); err != nil {
+		sb.WriteString(fmt.Sprintf("failed to format queries: %v\n", err))
+	}
+	sb.WriteString("```\n")
+	return sb.String()
+}
+
+// HandleGetSlowQueries handles the getSlowQueries tool.
+func (h *ToolsHandler) HandleGetSlowQueries(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "getSlowQueries")
+
+	logger.Info("Handling getSlowQueries request")
+
+	if err := h.requireAdmin(ctx, request, "getSlowQueries"); err != nil {
+		return nil, err
+	}
+	engine, err := h.engineFor("getSlowQueries", request)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, format := 0, "text"
+	if argsMap, ok := request.Params.Arguments.(map[string]interface{}); ok {
+		if val, ok := argsMap["limit"]; ok {
+			v, ok := val.(float64)
+			if !ok || v < 1 {
+				return nil, apperrors.New(apperrors.CodeInvalidArgument, "limit must be a positive number")
+			}
+			limit = int(v)
+		}
+		if val, ok := argsMap["format"]; ok {
+			format, _ = val.(string)
+			if format != "text" && format != "json" {
+				return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+			}
+		}
+	}
+
+	queries := engine.SlowQueries()
+	if queries == nil {
+		logger.Debug("Slow query log not enabled")
+		return mcp.NewToolResultText("The slow query log is not enabled"), nil
+	}
+	if limit > 0 && len(queries) > limit {
+		queries = queries[len(queries)-limit:]
+	}
+	logger.Info("Slow queries listed", "queries", len(queries))
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(queries, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatSlowQueries(queries)), nil
+}
+
+// formatSlowQueries formats slow queries as markdown, most recent first.
+func formatSlowQueries(queries []katago.SlowQuery) string {
+	var sb strings.Builder
+	sb.WriteString("# Slow Queries\n\n")
+	if len(queries) == 0 {
+		sb.WriteString("No query has been slow since the server started\n")
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("%d slow queries, most recent first.\n", len(queries)))
+	for i := len(queries) - 1; i >= 0; i-- {
+		q := queries[i]
+		sb.WriteString(fmt.Sprintf("\n## %s at %s: %.1fs, %s\n\n", q.ID, q.Time.Format(time.RFC3339), q.TotalSeconds, q.Outcome))
+		sb.WriteString(fmt.Sprintf("- **Lane**: %s\n", q.Lane))
+		sb.WriteString(fmt.Sprintf("- **Timing**: %.3fs queued, %.3fs in KataGo\n", q.QueueSeconds, q.EngineSeconds))
+		if q.Visits > 0 {
+			sb.WriteString(fmt.Sprintf("- **Visits**: %d\n", q.Visits))
+		}
+		if len(q.Settings) > 0 {
+			keys := make([]string, 0, len(q.Settings))
+			for key := range q.Settings {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			settings := make([]string, len(keys))
+			for j, key := range keys {
+				value, _ := json.Marshal(q.Settings[key])
+				settings[j] = key + "=" + string(value)
+			}
+			sb.WriteString(fmt.Sprintf("- **Settings**: %s\n", strings.Join(settings, ", ")))
+		}
+		if q.Position != "" {
+			sb.WriteString(fmt.Sprintf("- **Position**: `%s`\n", q.Position))
+		}
+	}
+	return sb.String()
+}
+
+// HandleSuggestHumanMove handles the suggestHumanMove tool.
+func (h *ToolsHandler) HandleSuggestHumanMove(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "suggestHumanMove")
+
+	logger.Info("Handling suggestHumanMove request")
+
+	engine, err := h.engineFor("suggestHumanMove", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Get SGF content
+	sgfVal, ok := argsMap["sgf"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	sgf, ok := sgfVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+	}
+
+	// Parse SGF
+	parser := katago.NewSGFParser(sgf)
+	position, err := parser.Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+
+	// Handle move number
+	if val, ok := argsMap["moveNumber"]; ok {
+		if moveNum, ok := val.(float64); ok && int(moveNum) > 0 && int(moveNum) < len(position.Moves) {
+			position.Moves = position.Moves[:int(moveNum)]
+		}
+	}
+
+	// Get human profile, falling back to the engine's default
+	humanProfile := ""
+	if val, ok := argsMap["humanProfile"]; ok {
+		humanProfile, ok = val.(string)
+		if !ok {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "humanProfile must be a string")
+		}
+	}
+
+	logger.Info("Suggesting human move", "humanProfile", humanProfile)
+	suggestion, err := engine.SuggestHumanMove(ctx, position, humanProfile)
+	if err != nil {
+		logger.Error("Failed to suggest human move: %v", err)
+		return nil, fmt.Errorf("failed to suggest human move: %w", err)
+	}
+	logger.Debug("Human move suggestion completed", "moves", len(suggestion.Moves))
+
+	return mcp.NewToolResultText(formatHumanMoveSuggestion(suggestion)), nil
+}
+
+// formatHumanMoveSuggestion formats a human move suggestion as markdown.
+func formatHumanMoveSuggestion(suggestion *katago.HumanMoveSuggestion) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Human Move Suggestion (%s)\n\n", suggestion.HumanProfile))
+	if suggestion.CurrentPlayer != "" {
+		sb.WriteString(fmt.Sprintf("Player to move: %s\n\n", suggestion.CurrentPlayer))
+	}
+
+	sb.WriteString("## Likely Human Moves\n")
+	if len(suggestion.Moves) == 0 {
+		sb.WriteString("No human policy was returned by the engine.\n")
+	}
+	for i, move := range suggestion.Moves {
+		sb.WriteString(fmt.Sprintf("%d. **%s** (%.1f%% likely)", i+1, move.Move, move.Probability*100))
+		if move.Analyzed {
+			sb.WriteString(fmt.Sprintf(" - win rate %.1f%%, score %+.1f", move.Winrate*100, move.ScoreLead))
+			if move.PointLoss > 0 {
+				sb.WriteString(fmt.Sprintf(", loses %.1f points", move.PointLoss))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	if suggestion.BestMove != "" {
+		sb.WriteString("\n## AI Best Move\n")
+		sb.WriteString(fmt.Sprintf("**%s** - win rate %.1f%%, score %+.1f\n",
+			suggestion.BestMove, suggestion.BestWinrate*100, suggestion.BestScoreLead))
+	}
+
+	return sb.String()
+}
+
+// HandleEstimateRank handles the estimateRank tool.
+func (h *ToolsHandler) HandleEstimateRank(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "estimateRank")
+
+	logger.Info("Handling estimateRank request")
+
+	engine, err := h.engineFor("estimateRank", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Get games
+	sgfsVal, ok := argsMap["sgfs"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgfs'")
+	}
+	sgfList, ok := sgfsVal.([]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgfs must be an array of strings")
+	}
+	sgfs := make([]string, len(sgfList))
+	for i, val := range sgfList {
+		sgf, ok := val.(string)
+		if !ok {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgfs[%d] must be a string", i)
+		}
+		sgfs[i] = sgf
+	}
+
+	// Get player
+	playerVal, ok := argsMap["player"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'player'")
+	}
+	player, ok := playerVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "player must be a string")
+	}
+
+	opts := &katago.RankOptions{MaxVisits: h.maxVisits("estimateRank", argsMap)}
+
+	logger.Info("Estimating rank", "player", player, "games", len(sgfs))
+	estimate, err := engine.EstimateRank(ctx, sgfs, player, opts)
+	if err != nil {
+		logger.Error("Failed to estimate rank: %v", err)
+		return nil, fmt.Errorf("failed to estimate rank: %w", err)
+	}
+	logger.Debug("Rank estimate completed", "rank", estimate.Rank, "moves", estimate.MovesAnalyzed)
+
+	return mcp.NewToolResultText(formatRankEstimate(estimate)), nil
+}
+
+// formatRankEstimate formats a rank estimate as markdown.
+func formatRankEstimate(estimate *katago.RankEstimate) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Rank Estimate: %s\n\n", estimate.Player))
+	sb.WriteString(fmt.Sprintf("**Estimated rank**: %s (95%% interval: %s to %s)\n", estimate.Rank, estimate.RankLow, estimate.RankHigh))
+	if estimate.Partial {
+		sb.WriteString("**Partial estimate**: the time limit was reached before all moves were analyzed\n")
+	}
+
+	sb.WriteString("\n## Statistics\n")
+	sb.WriteString(fmt.Sprintf("- Games: %d\n", estimate.Games))
+	sb.WriteString(fmt.Sprintf("- Moves analyzed: %d\n", estimate.MovesAnalyzed))
+	sb.WriteString(fmt.Sprintf("- Average point loss: %.2f\n", estimate.AvgPointLoss))
+	sb.WriteString(fmt.Sprintf("- Median point loss: %.2f\n", estimate.MedianLoss))
+	sb.WriteString(fmt.Sprintf("- Accurate moves (<1 point): %.1f%%\n", estimate.AccuracyRate*100))
+	sb.WriteString(fmt.Sprintf("- Blunders (5+ points): %.1f%%\n", estimate.BlunderRate*100))
+
+	if len(estimate.PerGame) > 1 {
+		sb.WriteString("\n## Per Game\n")
+		for _, game := range estimate.PerGame {
+			sb.WriteString(fmt.Sprintf("- Game %d (%s", game.Game, game.Color))
+			if game.Opponent != "" {
+				sb.WriteString(fmt.Sprintf(" vs %s", game.Opponent))
+			}
+			if game.MovesAnalyzed == 0 {
+				sb.WriteString("): not analyzed\n")
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("): %s, %.2f average loss over %d moves\n",
+				game.Rank, game.AvgPointLoss, game.MovesAnalyzed))
+		}
+	}
+
+	if len(estimate.Evidence) > 0 {
+		sb.WriteString("\n## Costliest Moves\n")
+		for _, ev := range estimate.Evidence {
+			sb.WriteString(fmt.Sprintf("- Game %d, move %d (%s): %s instead of %s loses %.1f points\n",
+				ev.Game, ev.MoveNumber, ev.Color, ev.PlayedMove, ev.BestMove, ev.PointLoss))
+		}
+	}
+
+	return sb.String()
+}
+
+// HandleExpandVariation handles the expandVariation tool.
+func (h *ToolsHandler) HandleExpandVariation(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "expandVariation")
+
+	logger.Info("Handling expandVariation request")
+
+	engine, err := h.engineFor("expandVariation", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Get SGF content
+	sgfVal, ok := argsMap["sgf"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	sgf, ok := sgfVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+	}
+
+	// Get candidate move
+	moveVal, ok := argsMap["move"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'move'")
+	}
+	move, ok := moveVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "move must be a string")
+	}
+
+	// Parse SGF
+	parser := katago.NewSGFParser(sgf)
+	position, err := parser.Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+
+	// Handle move number
+	if val, ok := argsMap["moveNumber"]; ok {
+		if moveNum, ok := val.(float64); ok && int(moveNum) > 0 && int(moveNum) < len(position.Moves) {
+			position.Moves = position.Moves[:int(moveNum)]
+		}
+	}
+
+	opts := &katago.VariationOptions{}
+	if val, ok := argsMap["depth"].(float64); ok {
+		opts.Depth = int(val)
+	}
+	if val, ok := argsMap["branches"].(float64); ok {
+		opts.Branches = int(val)
+	}
+	opts.MaxVisits = h.maxVisits("expandVariation", argsMap)
+
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
+	}
+
+	logger.Info("Expanding variation", "move", move, "depth", opts.Depth, "branches", opts.Branches)
+	tree, err := engine.ExpandVariation(ctx, position, move, opts)
+	if err != nil {
+		logger.Error("Failed to expand variation: %v", err)
+		return nil, fmt.Errorf("failed to expand variation: %w", err)
+	}
+	logger.Debug("Variation expansion completed", "nodes", tree.Nodes, "partial", tree.Partial)
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(tree, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatVariationTree(tree)), nil
+}
+
+// formatVariationTree formats an expanded variation as an indented markdown list.
+func formatVariationTree(tree *katago.VariationTree) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Variation: %s (depth %d)\n\n", tree.Root.Move, tree.Depth))
+	sb.WriteString(fmt.Sprintf("Original PV: %s\n", strings.Join(tree.OriginalPV, " ")))
+	sb.WriteString(fmt.Sprintf("Positions analyzed: %d\n", tree.Nodes))
+	if tree.Partial {
+		sb.WriteString("**Partial expansion**: some positions could not be analyzed before the limit was reached\n")
+	}
+	sb.WriteString("\nMoves marked * follow the original PV.\n\n")
+
+	var writeNode func(node *katago.VariationNode, indent int)
+	writeNode = func(node *katago.VariationNode, indent int) {
+		sb.WriteString(strings.Repeat("  ", indent))
+		marker := ""
+		if node.InOriginal {
+			marker = "*"
+		}
+		sb.WriteString(fmt.Sprintf("- %d. %s %s%s", node.MoveNumber, node.Color, node.Move, marker))
+		if node.Analyzed {
+			sb.WriteString(fmt.Sprintf(": win %.1f%%, score %+.1f (%d visits)", node.Winrate*100, node.ScoreLead, node.Visits))
+		} else {
+			sb.WriteString(": not analyzed")
+		}
+		sb.WriteString("\n")
+		for _, child := range node.Children {
+			writeNode(child, indent+1)
+		}
+	}
+	writeNode(tree.Root, 0)
+
+	return sb.String()
+}
+
+// HandleSweepKomi handles the sweepKomi tool.
+func (h *ToolsHandler) HandleSweepKomi(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "sweepKomi")
+
+	logger.Info("Handling sweepKomi request")
+
+	engine, err := h.engineFor("sweepKomi", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Get SGF content
+	sgfVal, ok := argsMap["sgf"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	sgf, ok := sgfVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+	}
+
+	// Parse SGF
+	parser := katago.NewSGFParser(sgf)
+	position, err := parser.Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+
+	// Handle move number
+	if val, ok := argsMap["moveNumber"]; ok {
+		if moveNum, ok := val.(float64); ok && int(moveNum) > 0 && int(moveNum) < len(position.Moves) {
+			position.Moves = position.Moves[:int(moveNum)]
+		}
+	}
+
+	opts := &katago.KomiSweepOptions{}
+	if val, ok := argsMap["minKomi"].(float64); ok {
+		opts.MinKomi = &val
+	}
+	if val, ok := argsMap["maxKomi"].(float64); ok {
+		opts.MaxKomi = &val
+	}
+	if val, ok := argsMap["step"].(float64); ok {
+		opts.Step = val
+	}
+	opts.MaxVisits = h.maxVisits("sweepKomi", argsMap)
+
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
+	}
+
+	logger.Info("Sweeping komi", "moves", len(position.Moves))
+	sweep, err := engine.SweepKomi(ctx, position, opts)
+	if err != nil {
+		logger.Error("Failed to sweep komi: %v", err)
+		return nil, fmt.Errorf("failed to sweep komi: %w", err)
+	}
+	logger.Debug("Komi sweep completed", "points", len(sweep.Points), "partial", sweep.Partial)
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(sweep, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatKomiSweep(sweep)), nil
+}
+
+// formatKomiSweep formats a komi sweep as a markdown table.
+func formatKomiSweep(sweep *katago.KomiSweep) string {
+	var sb strings.Builder
+	sb.WriteString("# Komi Sweep\n\n")
+
+	if sweep.FairKomi != nil {
+		sb.WriteString(fmt.Sprintf("**Fair komi: %.1f** (win rate crosses 50%%)\n", *sweep.FairKomi))
+	} else {
+		first, last := sweep.Points[0], sweep.Points[len(sweep.Points)-1]
+		sb.WriteString(fmt.Sprintf("The win rate does not cross 50%% between komi %.1f and %.1f; the fair komi lies outside the sweep\n",
+			first.Komi, last.Komi))
+	}
+	if sweep.Partial {
+		sb.WriteString("**Partial sweep**: some komi values could not be analyzed\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("\nPosition evaluated with %s to play.\n\n", sweep.CurrentPlayer))
+	sb.WriteString("| Komi | Win rate | Score lead | Visits |\n")
+	sb.WriteString("|------|----------|------------|--------|\n")
+	for _, p := range sweep.Points {
+		sb.WriteString(fmt.Sprintf("| %.1f | %.1f%% | %+.1f | %d |\n", p.Komi, p.Winrate*100, p.ScoreLead, p.Visits))
+	}
+	return sb.String()
+}
+
+// HandleWhatIf handles the whatIf tool.
+func (h *ToolsHandler) HandleWhatIf(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "whatIf")
+
+	logger.Info("Handling whatIf request")
+
+	engine, err := h.engineFor("whatIf", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Get SGF content
+	sgfVal, ok := argsMap["sgf"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	sgf, ok := sgfVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+	}
+
+	moves, err := stringList(argsMap, "moves")
+	if err != nil {
+		return nil, err
+	}
+	if len(moves) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'moves'")
+	}
+
+	// Parse SGF
+	parser := katago.NewSGFParser(sgf)
+	position, err := parser.Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+
+	// Handle move number
+	if val, ok := argsMap["moveNumber"]; ok {
+		if moveNum, ok := val.(float64); ok && int(moveNum) > 0 && int(moveNum) < len(position.Moves) {
+			position.Moves = position.Moves[:int(moveNum)]
+		}
+	}
+
+	opts := &katago.WhatIfOptions{}
+	opts.MaxVisits = h.maxVisits("whatIf", argsMap)
+
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
+	}
+
+	logger.Info("Evaluating sequence", "moves", len(position.Moves), "sequence", len(moves))
+	result, err := engine.WhatIf(ctx, position, moves, opts)
+	if err != nil {
+		logger.Error("Failed to evaluate sequence: %v", err)
+		return nil, fmt.Errorf("failed to evaluate sequence: %w", err)
+	}
+	logger.Debug("What-if completed", "scoreChange", result.ScoreChange, "matchingMoves", result.MatchingMoves)
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatWhatIf(result)), nil
+}
+
+// formatWhatIf formats a what-if comparison as markdown.
+func formatWhatIf(result *katago.WhatIfResult) string {
+	var sb strings.Builder
+	sb.WriteString("# What If\n\n")
+
+	played := make([]string, len(result.Sequence))
+	for i, move := range result.Sequence {
+		played[i] = moveName(move)
+	}
+	sb.WriteString(fmt.Sprintf("**Sequence**: %s\n", strings.Join(played, ", ")))
+	if len(result.BestLine) > 0 {
+		sb.WriteString(fmt.Sprintf("**Best line**: %s\n", strings.Join(result.BestLine, " ")))
+		switch {
+		case result.MatchingMoves == len(result.Sequence):
+			sb.WriteString("The sequence follows the best line.\n")
+		case result.MatchingMoves == 0:
+			sb.WriteString(fmt.Sprintf("The sequence leaves the best line at its first move (best: %s).\n", result.BestLine[0]))
+		case result.MatchingMoves < len(result.BestLine):
+			sb.WriteString(fmt.Sprintf("The sequence follows the best line for %d moves, then plays %s instead of %s.\n",
+				result.MatchingMoves, moveName(result.Sequence[result.MatchingMoves]), result.BestLine[result.MatchingMoves]))
+		default:
+			sb.WriteString(fmt.Sprintf("The sequence follows the best line for its %d moves, then continues past it.\n", result.MatchingMoves))
+		}
+	}
+	for _, color := range []string{"B", "W"} {
+		if n := result.Captures[color]; n > 0 {
+			sb.WriteString(fmt.Sprintf("%s captures %d stone(s).\n", color, n))
+		}
+	}
+
+	sb.WriteString("\n| Position | To play | Win rate | Score lead | Best move | Visits |\n")
+	sb.WriteString("|----------|---------|----------|------------|-----------|--------|\n")
+	for _, row := range []struct {
+		name string
+		eval katago.WhatIfEval
+	}{{"Original", result.Before}, {"After sequence", result.After}} {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %.1f%% | %+.1f | %s | %d |\n", row.name, row.eval.CurrentPlayer,
+			row.eval.Winrate*100, row.eval.ScoreLead, row.eval.BestMove, row.eval.Visits))
+	}
+	sb.WriteString(fmt.Sprintf("\n**Change**: %+.1f%% win rate, %+.1f points\n", result.WinrateChange*100, result.ScoreChange))
+	return sb.String()
+}
+
+// moveName formats a played move as "B D4" or "W pass".
+func moveName(move katago.Move) string {
+	location := move.Location
+	if location == "" {
+		location = "pass"
+	}
+	return fmt.Sprintf("%s %s", strings.ToUpper(move.Color), location)
+}
+
+// HandleCompareAnalyses handles the compareAnalyses tool.
+func (h *ToolsHandler) HandleCompareAnalyses(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "compareAnalyses")
+
+	logger.Info("Handling compareAnalyses request")
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	// Resolve the engine and visits of each setting
+	settings := make([]katago.AnalysisSetting, 2)
+	for i, side := range []string{"A", "B"} {
+		engine, err := h.engineFor("compareAnalyses", request)
+		if err != nil {
+			return nil, err
+		}
+		profile := ""
+		if val, ok := argsMap["profile"+side]; ok {
+			if profile, ok = val.(string); !ok {
+				return nil, apperrors.New(apperrors.CodeInvalidArgument, "profile%s must be a string", side)
+			}
+			if engine, err = h.profileEngine(profile); err != nil {
+				return nil, err
+			}
+		}
+		visits := 0
+		if val, ok := argsMap["maxVisits"+side]; ok {
+			v, ok := val.(float64)
+			if !ok || v < 1 {
+				return nil, apperrors.New(apperrors.CodeInvalidArgument, "maxVisits%s must be a positive number", side)
+			}
+			visits = int(v)
+		}
+
+		// Ensure engine is running
+		if !engine.IsRunning() {
+			logger.Debug("Starting KataGo engine", "profile", profile)
+			if err := engine.Start(ctx); err != nil {
+				logger.Error("Failed to start engine: %v", err)
+				return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+			}
+		}
+		settings[i] = katago.AnalysisSetting{Label: settingLabel(profile, visits), MaxVisits: visits, Analyze: engine.Analyze}
+	}
+
+	// Get SGF content
+	sgfVal, ok := argsMap["sgf"]
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
+	}
+	sgf, ok := sgfVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+	}
+
+	// Parse SGF
+	parser := katago.NewSGFParser(sgf)
+	position, err := parser.Parse()
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+	}
+
+	// Handle move number
+	if val, ok := argsMap["moveNumber"]; ok {
+		if moveNum, ok := val.(float64); ok && int(moveNum) > 0 && int(moveNum) < len(position.Moves) {
+			position.Moves = position.Moves[:int(moveNum)]
+		}
+	}
+
+	opts := &katago.CompareOptions{}
+	if val, ok := argsMap["topMoves"].(float64); ok {
+		opts.TopMoves = int(val)
+	}
+
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
+	}
+
+	logger.Info("Comparing analyses", "moves", len(position.Moves), "a", settings[0].Label, "b", settings[1].Label)
+	comparison, err := katago.CompareAnalyses(ctx, position, settings[0], settings[1], opts)
+	if err != nil {
+		logger.Error("Failed to compare analyses: %v", err)
+		return nil, fmt.Errorf("failed to compare analyses: %w", err)
+	}
+	logger.Debug("Comparison completed", "agree", comparison.Agree, "overlap", comparison.TopMoveOverlap)
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(comparison, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatAnalysisComparison(comparison)), nil
+}
+
+// settingLabel names a compared setting by its engine profile and visits.
+func settingLabel(profile string, visits int) string {
+	if profile == "" {
+		profile = "configured engine"
+	}
+	if visits == 0 {
+		return profile + ", engine visits"
+	}
+	return fmt.Sprintf("%s, %d visits", profile, visits)
+}
+
+// formatAnalysisComparison formats an analysis comparison as markdown.
+func formatAnalysisComparison(comparison *katago.AnalysisComparison) string {
+	var sb strings.Builder
+	sb.WriteString("# Analysis Comparison\n\n")
+	sb.WriteString(fmt.Sprintf("Position evaluated with %s to play.\n\n", comparison.CurrentPlayer))
+
+	sb.WriteString("| Setting | Visits | Time | Win rate | Score lead | Best move |\n")
+	sb.WriteString("|---------|--------|------|----------|------------|-----------|\n")
+	for _, row := range []struct {
+		name string
+		side katago.ComparisonSide
+	}{{"A", comparison.A}, {"B", comparison.B}} {
+		sb.WriteString(fmt.Sprintf("| %s: %s | %d | %.2fs | %.1f%% | %+.1f | %s |\n", row.name, row.side.Label,
+			row.side.Visits, row.side.Seconds, row.side.Winrate*100, row.side.ScoreLead, row.side.BestMove))
+	}
+	sb.WriteString(fmt.Sprintf("\n**Difference (B - A)**: %+.1f%% win rate, %+.1f points\n",
+		comparison.WinrateDiff*100, comparison.ScoreLeadDiff))
+
+	switch {
+	case comparison.Agree:
+		sb.WriteString("The settings agree: same best move, win rates within 2% and score leads within a point.\n")
+	case comparison.SameBestMove:
+		sb.WriteString("The settings pick the same best move but evaluate the position differently.\n")
+	default:
+		sb.WriteString(fmt.Sprintf("The settings disagree on the best move: %s against %s.\n",
+			comparison.A.BestMove, comparison.B.BestMove))
+	}
+	sb.WriteString(fmt.Sprintf("%d of the top moves are shared.\n", comparison.TopMoveOverlap))
+
+	sb.WriteString("\n| Move | Rank A | Rank B | Win rate A | Win rate B |\n")
+	sb.WriteString("|------|--------|--------|------------|------------|\n")
+	for _, move := range comparison.Moves {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", move.Move,
+			rankText(move.RankA), rankText(move.RankB), winrateText(move.WinrateA), winrateText(move.WinrateB)))
+	}
+	return sb.String()
+}
+
+// rankText formats a compared move's rank, "-" when it is not ranked.
+func rankText(rank int) string {
+	if rank == 0 {
+		return "-"
+	}
+	return strconv.Itoa(rank)
+}
+
+// winrateText formats a compared move's win rate, "-" when not searched.
+func winrateText(winrate *float64) string {
+	if winrate == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", *winrate*100)
+}
+
+// HandleSolveTsumego handles the solveTsumego tool.
+func (h *ToolsHandler) HandleSolveTsumego(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "solveTsumego")
+
+	logger.Info("Handling solveTsumego request")
+
+	engine, err := h.engineFor("solveTsumego", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
+
+	var position *katago.Position
+	if sgfVal, ok := argsMap["sgf"]; ok {
+		sgf, ok := sgfVal.(string)
+		if !ok {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "sgf must be a string")
+		}
+		position, err = katago.NewSGFParser(sgf).Parse()
+		if err != nil {
+			return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "failed to parse SGF")
+		}
+		if val, ok := argsMap["moveNumber"]; ok {
+			if moveNum, ok := val.(float64); ok && int(moveNum) > 0 && int(moveNum) < len(position.Moves) {
+				position.Moves = position.Moves[:int(moveNum)]
+			}
+		}
+	} else if boardVal, ok := argsMap["board"]; ok {
+		position, err = boardPosition(boardVal, argsMap)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "must provide 'sgf' or 'board' parameter")
+	}
+
+	opts := &katago.TsumegoOptions{}
+	opts.Region, err = stringList(argsMap, "region")
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Region) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'region'")
+	}
+	target, ok := argsMap["target"].(string)
+	if !ok || target == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'target'")
+	}
+	opts.Target = target
+	opts.MaxVisits = h.maxVisits("solveTsumego", argsMap)
+	if val, ok := argsMap["maxIterations"].(float64); ok {
+		opts.MaxIterations = int(val)
+	}
+
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
+	}
+
+	logger.Info("Solving tsumego", "target", target, "region", opts.Region)
+	solution, err := engine.SolveTsumego(ctx, position, opts)
+	if err != nil {
+		logger.Error("Failed to solve tsumego: %v", err)
+		return nil, fmt.Errorf("failed to solve tsumego: %w", err)
+	}
+	logger.Debug("Tsumego solved", "status", solution.Status, "iterations", solution.Iterations, "stable", solution.Stable)
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(solution, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatTsumegoSolution(solution)), nil
+}
+
+// formatTsumegoSolution formats a tsumego solution as markdown.
+func formatTsumegoSolution(solution *katago.TsumegoSolution) string {
+	var sb strings.Builder
+	sb.WriteString("# Tsumego Solution\n\n")
+
+	sb.WriteString(fmt.Sprintf("**%s to play.** The %s group at %s **%s** (ownership %+.2f).\n",
+		solution.ToPlay, colorName(solution.TargetColor), solution.Target, statusPhrase(solution.Status), solution.Ownership))
+	sb.WriteString(fmt.Sprintf("\n**Best move**: %s\n", solution.BestMove))
+	if len(solution.MainLine) > 0 {
+		sb.WriteString(fmt.Sprintf("**Main line**: %s\n", strings.Join(solution.MainLine, " ")))
+	}
+	if len(solution.CorrectMoves) > 0 {
+		moves := make([]string, len(solution.CorrectMoves))
+		for i, answer := range solution.CorrectMoves {
+			moves[i] = answer.Move
+		}
+		sb.WriteString(fmt.Sprintf("**Also correct**: %s\n", strings.Join(moves, ", ")))
+	}
+
+	if len(solution.WrongMoves) > 0 {
+		sb.WriteString("\n## Wrong Answers\n\n")
+		for _, answer := range solution.WrongMoves {
+			refutation := "-"
+			if len(answer.Line) > 1 {
+				refutation = strings.Join(answer.Line[1:], " ")
+			}
+			sb.WriteString(fmt.Sprintf("- **%s**: the group %s; refuted by %s\n", answer.Move, statusPhrase(answer.Status), refutation))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("\nSearched %d time(s), %d visits in the last search", solution.Iterations, solution.Visits))
+	if !solution.Stable {
+		sb.WriteString("; **the result did not stabilize**, so try more visits or iterations")
+	}
+	sb.WriteString(".\n")
+	return sb.String()
+}
+
+// HandleGenerateProblems handles the generateProblems tool.
+func (h *ToolsHandler) HandleGenerateProblems(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "generateProblems")
+
+	logger.Info("Handling generateProblems request")
+
+	engine, err := h.engineFor("generateProblems", request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
+		}
+	}
+
+	args := request.Params.Arguments
+	if args == nil {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
+	}
+
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
+	}
 
-// HandleStartEngine handles the startEngine tool.
-func (h *ToolsHandler) HandleStartEngine(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Generate correlation ID for this request
-	ctx = logging.ContextWithCorrelationID(ctx, logging.GenerateCorrelationID())
-	ctx = logging.ContextWithRequestID(ctx, logging.GenerateRequestID())
-	logger := h.logger.WithContext(ctx).WithField("tool", "startEngine")
+	sgfs, err := stringList(argsMap, "sgfs")
+	if err != nil {
+		return nil, err
+	}
+	if len(sgfs) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgfs'")
+	}
+	player, ok := argsMap["player"].(string)
+	if !ok || player == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'player'")
+	}
 
-	logger.Info("Handling startEngine request")
+	opts := &katago.ProblemOptions{}
+	if val, ok := argsMap["maxProblems"].(float64); ok {
+		opts.MaxProblems = int(val)
+	}
+	if val, ok := argsMap["minGap"]; ok {
+		gap, ok := val.(float64)
+		if !ok || gap < 0 || gap > 1 {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "minGap must be a number between 0 and 1")
+		}
+		opts.MinGap = gap
+	}
+	opts.MaxVisits = h.maxVisits("generateProblems", argsMap)
+	if val, ok := argsMap["includeMistakes"].(bool); ok {
+		opts.IncludeMistakes = val
+	}
 
-	if h.engine.IsRunning() {
-		logger.Debug("Engine already running")
-		return mcp.NewToolResultText("KataGo engine is already running"), nil
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
 	}
 
-	logger.Info("Starting KataGo engine")
-	if err := h.engine.Start(ctx); err != nil {
-		logger.Error("Failed to start engine: %v", err)
-		return nil, fmt.Errorf("failed to start engine: %w", err)
+	logger.Info("Generating problems", "player", player, "games", len(sgfs))
+	set, err := engine.GenerateProblems(ctx, sgfs, player, opts)
+	if err != nil {
+		logger.Error("Failed to generate problems: %v", err)
+		return nil, fmt.Errorf("failed to generate problems: %w", err)
 	}
+	logger.Debug("Problems generated", "problems", len(set.Problems), "unclear", set.Unclear, "partial", set.Partial)
 
-	logger.Info("KataGo engine started successfully")
-	return mcp.NewToolResultText("KataGo engine started successfully"), nil
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(set, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatProblemSet(set)), nil
 }
 
-// HandleStopEngine handles the stopEngine tool.
-func (h *ToolsHandler) HandleStopEngine(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Generate correlation ID for this request
-	ctx = logging.ContextWithCorrelationID(ctx, logging.GenerateCorrelationID())
-	ctx = logging.ContextWithRequestID(ctx, logging.GenerateRequestID())
-	logger := h.logger.WithContext(ctx).WithField("tool", "stopEngine")
-
-	logger.Info("Handling stopEngine request")
-
-	if !h.engine.IsRunning() {
-		logger.Debug("Engine not running")
-		return mcp.NewToolResultText("KataGo engine is not running"), nil
+// formatProblemSet formats a problem set as markdown, followed by its SGF.
+func formatProblemSet(set *katago.ProblemSet) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Problems for %s\n\n", set.Player))
+	sb.WriteString(fmt.Sprintf("**%d problem(s)** from %d game(s)", len(set.Problems), set.Games))
+	if set.Unclear > 0 {
+		sb.WriteString(fmt.Sprintf("; %d mistake(s) skipped for lacking one clear answer", set.Unclear))
 	}
-
-	logger.Info("Stopping KataGo engine")
-	if err := h.engine.Stop(); err != nil {
-		logger.Error("Failed to stop engine: %v", err)
-		return nil, fmt.Errorf("failed to stop engine: %w", err)
+	sb.WriteString(".\n")
+	if set.Partial {
+		sb.WriteString("**Partial set**: the time limit was reached before all games were reviewed\n")
+	}
+	if len(set.Problems) == 0 {
+		return sb.String()
 	}
 
-	logger.Info("KataGo engine stopped successfully")
-	return mcp.NewToolResultText("KataGo engine stopped successfully"), nil
+	sb.WriteString("\n## Problems\n\n")
+	for i, problem := range set.Problems {
+		played := problem.PlayedMove
+		if played == "" {
+			played = "pass"
+		}
+		sb.WriteString(fmt.Sprintf("%d. Game %d, move %d, %s to play: %s, not %s (%s, -%.1f%% win rate)\n",
+			i+1, problem.Game, problem.MoveNumber, colorName(problem.Color), problem.BestMove, played,
+			problem.Category, problem.WinrateDrop*100))
+	}
+	sb.WriteString("\n## SGF\n\n```sgf\n")
+	sb.WriteString(set.SGF)
+	sb.WriteString("```\n")
+	return sb.String()
 }
 
-// HandleFindMistakes handles the findMistakes tool.
-func (h *ToolsHandler) HandleFindMistakes(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Generate correlation ID for this request
-	ctx = logging.ContextWithCorrelationID(ctx, logging.GenerateCorrelationID())
-	ctx = logging.ContextWithRequestID(ctx, logging.GenerateRequestID())
-	logger := h.logger.WithContext(ctx).WithField("tool", "findMistakes")
+// HandleOpeningBook handles the openingBook tool.
+func (h *ToolsHandler) HandleOpeningBook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "openingBook")
 
-	logger.Info("Handling findMistakes request")
+	logger.Info("Handling openingBook request")
+
+	engine, err := h.engineFor("openingBook", request)
+	if err != nil {
+		return nil, err
+	}
 
 	// Ensure engine is running
-	if !h.engine.IsRunning() {
+	if !engine.IsRunning() {
 		logger.Debug("Starting KataGo engine")
-		if err := h.engine.Start(ctx); err != nil {
+		if err := engine.Start(ctx); err != nil {
 			logger.Error("Failed to start engine: %v", err)
-			return nil, fmt.Errorf("failed to start engine: %w", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
 		}
 	}
 
 	args := request.Params.Arguments
 	if args == nil {
-		return nil, fmt.Errorf("missing arguments")
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
 	}
 
 	argsMap, ok := args.(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("invalid arguments format")
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
 	}
 
-	// Get SGF content
-	sgfVal, ok := argsMap["sgf"]
-	if !ok {
-		return nil, fmt.Errorf("missing required parameter 'sgf'")
+	sgfs, err := stringList(argsMap, "sgfs")
+	if err != nil {
+		return nil, err
 	}
-	sgf, ok := sgfVal.(string)
-	if !ok {
-		return nil, fmt.Errorf("sgf must be a string")
+	if len(sgfs) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgfs'")
+	}
+	player, ok := argsMap["player"].(string)
+	if !ok || player == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'player'")
 	}
 
-	// Parse thresholds
-	thresholds := katago.DefaultMistakeThresholds()
-
-	if val, ok := argsMap["blunderThreshold"]; ok {
-		if threshold, ok := val.(float64); ok {
-			thresholds.Blunder = threshold
-		}
+	opts := &katago.OpeningOptions{MaxVisits: h.maxVisits("openingBook", argsMap)}
+	if val, ok := argsMap["depth"].(float64); ok {
+		opts.Depth = int(val)
 	}
-	if val, ok := argsMap["mistakeThreshold"]; ok {
-		if threshold, ok := val.(float64); ok {
-			thresholds.Mistake = threshold
-		}
+	if val, ok := argsMap["minGames"].(float64); ok {
+		opts.MinGames = int(val)
 	}
-
-	if val, ok := argsMap["inaccuracyThreshold"]; ok {
-		if threshold, ok := val.(float64); ok {
-			thresholds.Inaccuracy = threshold
+	if val, ok := argsMap["minLoss"]; ok {
+		loss, ok := val.(float64)
+		if !ok || loss < 0 {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "minLoss must be a number of points, not negative")
 		}
+		opts.MinLoss = loss
 	}
 
-	if val, ok := argsMap["maxVisits"]; ok {
-		if visits, ok := val.(float64); ok {
-			thresholds.MinimumVisits = int(visits)
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
 		}
 	}
 
-	// Review the game
-	logger.Info("Reviewing game", "thresholds", thresholds)
-	review, err := h.engine.ReviewGame(ctx, sgf, thresholds)
+	logger.Info("Building opening book", "player", player, "games", len(sgfs))
+	book, err := engine.OpeningBook(ctx, sgfs, player, opts)
 	if err != nil {
-		logger.Error("Failed to review game: %v", err)
-		return nil, fmt.Errorf("failed to review game: %w", err)
+		logger.Error("Failed to build opening book: %v", err)
+		return nil, fmt.Errorf("failed to build opening book: %w", err)
 	}
-	logger.Info("Game review completed",
-		"totalMoves", review.Summary.TotalMoves,
-		"mistakes", len(review.Mistakes))
+	logger.Debug("Opening book built", "positions", book.Positions, "losingLines", len(book.LosingLines), "partial", book.Partial)
 
-	// Format the result
-	var sb strings.Builder
-	sb.WriteString("# Game Review\n\n")
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(book, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
+	}
+	return mcp.NewToolResultText(formatOpeningBook(book)), nil
+}
 
-	// Summary
-	sb.WriteString("## Summary\n")
-	sb.WriteString(fmt.Sprintf("- Total moves: %d\n", review.Summary.TotalMoves))
-	sb.WriteString(fmt.Sprintf("- Black accuracy: %.1f%%\n", review.Summary.BlackAccuracy))
-	sb.WriteString(fmt.Sprintf("- White accuracy: %.1f%%\n", review.Summary.WhiteAccuracy))
-	sb.WriteString(fmt.Sprintf("- Black mistakes/blunders: %d/%d\n",
-		review.Summary.BlackMistakes, review.Summary.BlackBlunders))
-	sb.WriteString(fmt.Sprintf("- White mistakes/blunders: %d/%d\n",
-		review.Summary.WhiteMistakes, review.Summary.WhiteBlunders))
+// formatOpeningBook formats an opening book as markdown: the habitual
+// lines that lose points, then each tree down to the branches played in
+// at least the book's minimum of games.
+func formatOpeningBook(book *katago.OpeningBook) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Opening Book for %s\n\n", book.Player))
+	sb.WriteString(fmt.Sprintf("%d game(s) on %dx%d, %d moves deep", book.Games, book.BoardSize, book.BoardSize, book.Depth))
+	if book.Skipped > 0 {
+		sb.WriteString(fmt.Sprintf("; %d game(s) skipped", book.Skipped))
+	}
+	sb.WriteString(fmt.Sprintf(". %d habitual position(s) evaluated.\n", book.Positions))
+	if book.Partial {
+		sb.WriteString("**Partial book**: the time limit was reached before every habitual move was evaluated\n")
+	}
 
-	if review.Summary.EstimatedLevel != "" {
-		sb.WriteString(fmt.Sprintf("- Estimated level: %s\n", review.Summary.EstimatedLevel))
+	sb.WriteString("\n## Habitual Lines Losing Points\n\n")
+	if len(book.LosingLines) == 0 {
+		sb.WriteString(fmt.Sprintf("None of the moves played in %d or more games loses points.\n", book.MinGames))
+	}
+	for i, line := range book.LosingLines {
+		sb.WriteString(fmt.Sprintf("%d. As %s: %s (move %d, %d games, %d-%d): loses %.1f points; KataGo prefers %s\n",
+			i+1, colorName(line.Color), strings.Join(line.Line, " "), line.MoveNumber, line.Games, line.Wins, line.Losses,
+			line.PointLoss, line.BestMove))
 	}
 
-	// Mistakes
-	if len(review.Mistakes) > 0 {
-		sb.WriteString("\n## Mistakes Found\n\n")
-		for i := range review.Mistakes {
-			mistake := &review.Mistakes[i]
-			sb.WriteString(fmt.Sprintf("### Move %d (%s)\n", mistake.MoveNumber, mistake.Color))
-			sb.WriteString(fmt.Sprintf("- **Category**: %s\n", mistake.Category))
-			sb.WriteString(fmt.Sprintf("- **Played**: %s (%.1f%% WR)\n",
-				mistake.PlayedMove, mistake.PlayedWR*100))
-			sb.WriteString(fmt.Sprintf("- **Better**: %s (%.1f%% WR)\n",
-				mistake.BestMove, mistake.BestWR*100))
-			sb.WriteString(fmt.Sprintf("- **Win rate drop**: %.1f%%\n", mistake.WinrateDrop*100))
-			sb.WriteString(fmt.Sprintf("- %s\n\n", mistake.Explanation))
+	for _, tree := range []struct {
+		color string
+		nodes []*katago.OpeningNode
+	}{{"B", book.AsBlack}, {"W", book.AsWhite}} {
+		games, habitual := 0, false
+		for _, node := range tree.nodes {
+			games += node.Games
+			habitual = habitual || node.Games >= book.MinGames
 		}
-	} else {
-		sb.WriteString("\n## No significant mistakes found!\n")
+		if games == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n## As %s (%d game(s))\n\n", colorName(tree.color), games))
+		if !habitual {
+			sb.WriteString(fmt.Sprintf("No first move was played in %d or more games.\n", book.MinGames))
+		}
+		writeOpeningNodes(&sb, tree.nodes, book.MinGames, 0)
 	}
+	return sb.String()
+}
 
-	return mcp.NewToolResultText(sb.String()), nil
+// writeOpeningNodes writes the branches of an opening tree played in at
+// least minGames games, as a nested list.
+func writeOpeningNodes(sb *strings.Builder, nodes []*katago.OpeningNode, minGames, indent int) {
+	for _, node := range nodes {
+		if node.Games < minGames {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s- %d. %s: %d games, %d-%d", strings.Repeat("  ", indent),
+			node.MoveNumber, node.Move, node.Games, node.Wins, node.Losses))
+		if node.Evaluated {
+			sb.WriteString(fmt.Sprintf(", -%.1f", node.PointLoss))
+			if node.BestMove != node.Move {
+				sb.WriteString(fmt.Sprintf(" (KataGo: %s)", node.BestMove))
+			}
+		}
+		sb.WriteString("\n")
+		writeOpeningNodes(sb, node.Children, minGames, indent+1)
+	}
 }
 
-// HandleEvaluateTerritory handles the evaluateTerritory tool.
-func (h *ToolsHandler) HandleEvaluateTerritory(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Generate correlation ID for this request
-	ctx = logging.ContextWithCorrelationID(ctx, logging.GenerateCorrelationID())
-	ctx = logging.ContextWithRequestID(ctx, logging.GenerateRequestID())
-	logger := h.logger.WithContext(ctx).WithField("tool", "evaluateTerritory")
+// HandleReviewArchive handles the reviewArchive tool.
+func (h *ToolsHandler) HandleReviewArchive(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "reviewArchive")
 
-	logger.Info("Handling evaluateTerritory request")
+	logger.Info("Handling reviewArchive request")
 
-	// Ensure engine is running
-	if !h.engine.IsRunning() {
-		logger.Debug("Starting KataGo engine")
-		if err := h.engine.Start(ctx); err != nil {
-			logger.Error("Failed to start engine: %v", err)
-			return nil, fmt.Errorf("failed to start engine: %w", err)
-		}
+	engine, err := h.engineFor("reviewArchive", request)
+	if err != nil {
+		return nil, err
 	}
 
 	args := request.Params.Arguments
 	if args == nil {
-		return nil, fmt.Errorf("missing arguments")
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
 	}
 
 	argsMap, ok := args.(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("invalid arguments format")
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
 	}
 
-	// Get SGF content
-	sgfVal, ok := argsMap["sgf"]
-	if !ok {
-		return nil, fmt.Errorf("missing required parameter 'sgf'")
+	encoded, ok := argsMap["archive"].(string)
+	if !ok || encoded == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'archive'")
 	}
-	sgf, ok := sgfVal.(string)
-	if !ok {
-		return nil, fmt.Errorf("sgf must be a string")
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidArgument, err, "archive must be base64 encoded")
 	}
-
-	// Parse SGF
-	parser := katago.NewSGFParser(sgf)
-	position, err := parser.Parse()
+	games, err := katago.ReadSGFArchive(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse SGF: %w", err)
+		return nil, err
 	}
 
-	// Get threshold
-	threshold := 0.85
-	if val, ok := argsMap["threshold"]; ok {
-		if t, ok := val.(float64); ok && t > 0 && t <= 1 {
-			threshold = t
-		}
+	thresholds := katago.DefaultMistakeThresholds()
+	if h.reviewCfg != nil {
+		thresholds.Concurrency = h.reviewCfg.Concurrency
+	}
+	if val, ok := argsMap["blunderThreshold"].(float64); ok {
+		thresholds.Blunder = val
+	}
+	if val, ok := argsMap["mistakeThreshold"].(float64); ok {
+		thresholds.Mistake = val
+	}
+	if val, ok := argsMap["inaccuracyThreshold"].(float64); ok {
+		thresholds.Inaccuracy = val
+	}
+	if visits := h.maxVisits("reviewArchive", argsMap); visits > 0 {
+		thresholds.MinimumVisits = visits
 	}
 
-	// Estimate territory
-	logger.Info("Estimating territory", "threshold", threshold)
-	estimate, err := h.engine.EstimateTerritory(ctx, position, threshold)
-	if err != nil {
-		logger.Error("Failed to estimate territory: %v", err)
-		return nil, fmt.Errorf("failed to estimate territory: %w", err)
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
 	}
-	logger.Debug("Territory estimation completed")
 
-	// Check if detailed estimates requested
-	includeEstimates := false
-	if val, ok := argsMap["includeEstimates"]; ok {
-		if b, ok := val.(bool); ok {
-			includeEstimates = b
+	// Ensure engine is running
+	if !engine.IsRunning() {
+		logger.Debug("Starting KataGo engine")
+		if err := engine.Start(ctx); err != nil {
+			logger.Error("Failed to start engine: %v", err)
+			return nil, apperrors.Wrap(apperrors.CodeEngineUnavailable, err, "failed to start engine")
 		}
 	}
 
-	// Format result
-	if includeEstimates {
-		// Return JSON with full details
-		resultJSON, err := json.MarshalIndent(estimate, "", "  ")
+	logger.Info("Reviewing archive", "games", len(games), "bytes", len(data))
+	archive := katago.ReviewArchive(ctx, engine.ReviewGame, games, thresholds)
+	logger.Debug("Archive reviewed", "reviewed", archive.Reviewed, "failed", archive.Failed, "partial", archive.Partial)
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(archive, "", "  ")
 		if err != nil {
 			return nil, fmt.Errorf("failed to format result: %w", err)
 		}
 		return mcp.NewToolResultText(string(resultJSON)), nil
 	}
-
-	// Return visualization
-	viz := katago.GetTerritoryVisualization(estimate)
-	return mcp.NewToolResultText(viz), nil
+	return mcp.NewToolResultText(formatArchiveReview(archive)), nil
 }
 
-// HandleExplainMove handles the explainMove tool.
-func (h *ToolsHandler) HandleExplainMove(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	// Generate correlation ID for this request
-	ctx = logging.ContextWithCorrelationID(ctx, logging.GenerateCorrelationID())
-	ctx = logging.ContextWithRequestID(ctx, logging.GenerateRequestID())
-	logger := h.logger.WithContext(ctx).WithField("tool", "explainMove")
+// formatArchiveReview formats an archive review as markdown: the totals,
+// a table of players and a line per game.
+func formatArchiveReview(archive *katago.ArchiveReview) string {
+	var sb strings.Builder
+	sb.WriteString("# Archive Review\n\n")
+	sb.WriteString(fmt.Sprintf("**%d of %d game(s) reviewed**", archive.Reviewed, archive.Games))
+	if archive.Failed > 0 {
+		sb.WriteString(fmt.Sprintf(", %d failed", archive.Failed))
+	}
+	if archive.Skipped > 0 {
+		sb.WriteString(fmt.Sprintf(", %d skipped", archive.Skipped))
+	}
+	sb.WriteString(fmt.Sprintf(": %d moves, %d mistake(s), %d blunder(s).\n", archive.Moves, archive.Mistakes, archive.Blunders))
+	if archive.Partial {
+		sb.WriteString("**Partial review**: the time limit was reached before all games were reviewed\n")
+	}
 
-	logger.Info("Handling explainMove request")
+	if len(archive.Players) > 0 {
+		sb.WriteString("\n## Players\n\n")
+		sb.WriteString("| Player | Games | Mistakes | Blunders | Accuracy |\n")
+		sb.WriteString("|--------|-------|----------|----------|----------|\n")
+		for _, p := range archive.Players {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %.1f%% |\n", p.Name, p.Games, p.Mistakes, p.Blunders, p.Accuracy))
+		}
+	}
 
-	// Ensure engine is running
-	if !h.engine.IsRunning() {
-		logger.Debug("Starting KataGo engine")
-		if err := h.engine.Start(ctx); err != nil {
-			logger.Error("Failed to start engine: %v", err)
-			return nil, fmt.Errorf("failed to start engine: %w", err)
+	sb.WriteString("\n## Games\n\n")
+	for i, r := range archive.Results {
+		sb.WriteString(fmt.Sprintf("%d. %s", i+1, r.Name))
+		if r.PlayerBlack != "" || r.PlayerWhite != "" {
+			black, white := r.PlayerBlack, r.PlayerWhite
+			if black == "" {
+				black = "?"
+			}
+			if white == "" {
+				white = "?"
+			}
+			sb.WriteString(fmt.Sprintf(" (%s vs %s", black, white))
+			if r.Result != "" {
+				sb.WriteString(", " + r.Result)
+			}
+			sb.WriteString(")")
+		}
+		switch {
+		case r.Error != "":
+			sb.WriteString(": failed: " + r.Error)
+		case r.Skipped:
+			sb.WriteString(": skipped: a position with no moves")
+		case r.Summary == nil:
+			sb.WriteString(": not reviewed")
+		default:
+			s := r.Summary
+			sb.WriteString(fmt.Sprintf(": Black %d mistake(s), %d blunder(s), %.1f%%; White %d mistake(s), %d blunder(s), %.1f%%",
+				s.BlackMistakes, s.BlackBlunders, s.BlackAccuracy, s.WhiteMistakes, s.WhiteBlunders, s.WhiteAccuracy))
 		}
+		sb.WriteString("\n")
 	}
+	return sb.String()
+}
+
+// HandleValidateSGF handles the validateSGF tool. It does not need the
+// engine.
+func (h *ToolsHandler) HandleValidateSGF(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Reuse or generate correlation IDs for this request
+	ctx = withRequestIDs(ctx)
+	logger := h.logger.WithContext(ctx).WithField("tool", "validateSGF")
+
+	logger.Info("Handling validateSGF request")
 
 	args := request.Params.Arguments
 	if args == nil {
-		return nil, fmt.Errorf("missing arguments")
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing arguments")
 	}
 
 	argsMap, ok := args.(map[string]interface{})
 	if !ok {
-		return nil, fmt.Errorf("invalid arguments format")
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "invalid arguments format")
 	}
 
-	// Get SGF content
-	sgfVal, ok := argsMap["sgf"]
-	if !ok {
-		return nil, fmt.Errorf("missing required parameter 'sgf'")
-	}
-	sgf, ok := sgfVal.(string)
+	sgf, ok := argsMap["sgf"].(string)
 	if !ok {
-		return nil, fmt.Errorf("sgf must be a string")
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "missing required parameter 'sgf'")
 	}
 
-	// Get move to explain
-	moveVal, ok := argsMap["move"]
-	if !ok {
-		return nil, fmt.Errorf("missing required parameter 'move'")
+	format := "text"
+	if val, ok := argsMap["format"]; ok {
+		format, _ = val.(string)
+		if format != "text" && format != "json" {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "format must be 'text' or 'json'")
+		}
 	}
-	move, ok := moveVal.(string)
-	if !ok {
-		return nil, fmt.Errorf("move must be a string")
+
+	diagnostics := katago.ValidateSGF(sgf)
+	logger.Debug("SGF validated", "valid", diagnostics.Valid, "issues", len(diagnostics.Issues))
+
+	if format == "json" {
+		resultJSON, err := json.MarshalIndent(diagnostics, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format result: %w", err)
+		}
+		return mcp.NewToolResultText(string(resultJSON)), nil
 	}
+	return mcp.NewToolResultText(formatSGFDiagnostics(diagnostics)), nil
+}
 
-	// Parse SGF
-	parser := katago.NewSGFParser(sgf)
-	position, err := parser.Parse()
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse SGF: %w", err)
+// formatSGFDiagnostics formats SGF diagnostics as markdown.
+func formatSGFDiagnostics(d *katago.SGFDiagnostics) string {
+	var sb strings.Builder
+	sb.WriteString("# SGF Validation\n\n")
+	if d.Valid {
+		sb.WriteString("**Valid**: the SGF can be analyzed")
+	} else {
+		sb.WriteString(fmt.Sprintf("**Invalid**: %d error(s)", d.Errors()))
+	}
+	if warnings := len(d.Issues) - d.Errors(); warnings > 0 {
+		sb.WriteString(fmt.Sprintf(", %d warning(s)", warnings))
+	}
+	sb.WriteString("\n")
+	if d.BoardSize > 0 {
+		sb.WriteString(fmt.Sprintf("\n- **Board**: %dx%d\n", d.BoardSize, d.BoardSize))
+		sb.WriteString(fmt.Sprintf("- **Moves**: %d\n", d.Moves))
+		sb.WriteString(fmt.Sprintf("- **Variations**: %d\n", d.Variations))
+		sb.WriteString(fmt.Sprintf("- **Rules**: %s, komi %.1f\n", d.Rules, d.Komi))
 	}
 
-	// Get explanation
-	logger.Info("Explaining move", "move", move)
-	explanation, err := h.engine.ExplainMove(ctx, position, move)
-	if err != nil {
-		logger.Error("Failed to explain move: %v", err)
-		return nil, fmt.Errorf("failed to explain move: %w", err)
+	if len(d.Issues) > 0 {
+		sb.WriteString("\n## Issues\n\n")
+		for _, issue := range d.Issues {
+			var where []string
+			if issue.MoveNumber > 0 {
+				where = append(where, fmt.Sprintf("move %d", issue.MoveNumber))
+			}
+			if issue.Property != "" {
+				where = append(where, issue.Property)
+			}
+			sb.WriteString(fmt.Sprintf("- **%s**", issue.Severity))
+			if len(where) > 0 {
+				sb.WriteString(" (" + strings.Join(where, ", ") + ")")
+			}
+			sb.WriteString(": " + issue.Message + "\n")
+		}
 	}
-	logger.Debug("Move explanation completed", "winrate", explanation.Winrate)
+	if len(d.Suggestions) > 0 {
+		sb.WriteString("\n## Suggestions\n\n")
+		for _, suggestion := range d.Suggestions {
+			sb.WriteString("- " + suggestion + "\n")
+		}
+	}
+	return sb.String()
+}
 
-	// Format result
-	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("# Move Explanation: %s\n\n", move))
-	sb.WriteString(fmt.Sprintf("%s\n\n", explanation.Explanation))
+// statusPhrase words a tsumego status as a verb phrase.
+func statusPhrase(status string) string {
+	switch status {
+	case katago.StatusKo:
+		return "is in ko"
+	case katago.StatusUnsettled:
+		return "is unsettled"
+	}
+	return status
+}
 
-	// Stats
-	sb.WriteString("## Statistics\n")
-	sb.WriteString(fmt.Sprintf("- Win rate: %.1f%%\n", explanation.Winrate*100))
-	sb.WriteString(fmt.Sprintf("- Score lead: %.1f points\n", explanation.ScoreLead))
-	sb.WriteString(fmt.Sprintf("- Engine visits: %d\n\n", explanation.Visits))
-
-	// Strategic info
-	sb.WriteString("## Strategic Analysis\n")
-	sb.WriteString(fmt.Sprintf("- Board region: %s\n", explanation.Strategic.BoardRegion))
-	sb.WriteString(fmt.Sprintf("- Urgency: %s\n", explanation.Strategic.Urgency))
-	if len(explanation.Strategic.Purpose) > 0 {
-		sb.WriteString(fmt.Sprintf("- Purpose: %s\n", strings.Join(explanation.Strategic.Purpose, ", ")))
+// colorName names a color, "B" or "W".
+func colorName(color string) string {
+	if strings.EqualFold(color, "W") {
+		return "White"
 	}
+	return "Black"
+}
 
-	// Pros and cons
-	if len(explanation.Pros) > 0 {
-		sb.WriteString("\n## Pros\n")
-		for _, pro := range explanation.Pros {
-			sb.WriteString(fmt.Sprintf("- %s\n", pro))
+// boardPosition parses the board argument, applying the boardSize, toPlay
+// and komi arguments.
+func boardPosition(boardVal interface{}, argsMap map[string]interface{}) (*katago.Position, error) {
+	board, ok := boardVal.(string)
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "board must be a string")
+	}
+	size := 0
+	if val, ok := argsMap["boardSize"].(float64); ok {
+		size = int(val)
+	}
+	position, err := katago.ParseBoard(board, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse board: %w", err)
+	}
+	if val, ok := argsMap["toPlay"]; ok {
+		toPlay, _ := val.(string)
+		switch strings.ToUpper(toPlay) {
+		case "B":
+			position.InitialPlayer = "b"
+		case "W":
+			position.InitialPlayer = "w"
+		default:
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "toPlay must be 'B' or 'W'")
 		}
 	}
-
-	if len(explanation.Cons) > 0 {
-		sb.WriteString("\n## Cons\n")
-		for _, con := range explanation.Cons {
-			sb.WriteString(fmt.Sprintf("- %s\n", con))
+	if val, ok := argsMap["komi"]; ok {
+		komi, ok := val.(float64)
+		if !ok {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "komi must be a number")
 		}
+		position.Komi = komi
 	}
+	return position, nil
+}
 
-	// Alternatives
-	if len(explanation.Alternatives) > 0 {
-		sb.WriteString("\n## Better Alternatives\n")
-		for _, alt := range explanation.Alternatives {
-			sb.WriteString(fmt.Sprintf("- **%s** (%.1f%% WR): %s\n",
-				alt.Move, alt.Winrate*100, alt.Reasoning))
+// stringList reads an optional array-of-strings argument.
+func stringList(argsMap map[string]interface{}, name string) ([]string, error) {
+	val, ok := argsMap[name]
+	if !ok || val == nil {
+		return nil, nil
+	}
+	list, ok := val.([]interface{})
+	if !ok {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "%s must be an array of strings", name)
+	}
+	values := make([]string, len(list))
+	for i, item := range list {
+		str, ok := item.(string)
+		if !ok {
+			return nil, apperrors.New(apperrors.CodeInvalidArgument, "%s[%d] must be a string", name, i)
 		}
+		values[i] = str
 	}
-
-	return mcp.NewToolResultText(sb.String()), nil
+	return values, nil
 }
@@ -1,13 +1,24 @@
 package mcp
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/cache"
 	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/i18n"
 	"github.com/dmmcquay/katago-mcp/internal/katago"
 	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/monitor"
+	"github.com/dmmcquay/katago-mcp/internal/quota"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
@@ -81,6 +92,1822 @@ func TestEngineStatusTool(t *testing.T) {
 	}
 }
 
+func TestEngineStatusToolWithMonitor(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetProcessID(os.Getpid())
+
+	resourceMonitor := monitor.New(&config.MonitorConfig{Enabled: true, IntervalSeconds: 1}, logger, engine.ProcessID)
+	resourceMonitor.Sample()
+
+	handler := NewToolsHandler(engine, logger)
+	handler.SetMonitor(resourceMonitor)
+
+	result, err := handler.HandleGetEngineStatus(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, fmt.Sprintf("Process ID: %d", os.Getpid())) {
+		t.Errorf("Expected process ID in status, got %q", text)
+	}
+	if !strings.Contains(text, "Memory (RSS):") {
+		t.Errorf("Expected memory usage in status, got %q", text)
+	}
+}
+
+func TestEngineStatusToolCapabilities(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	result, err := handler.HandleGetEngineStatus(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, "not yet negotiated") {
+		t.Errorf("Expected unknown version in status, got %q", text)
+	}
+
+	caps, err := katago.CapabilitiesFor("1.13.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetCapabilities(caps)
+	result, err = handler.HandleGetEngineStatus(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"KataGo version: 1.13.2", "rawErrors", "Unsupported features: humanSL (requires 1.15.0)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in status, got %q", want, text)
+		}
+	}
+}
+
+func TestEngineStatusToolStartupProgress(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetStartupProgress(katago.StartupProgress{
+		Phase:          katago.PhaseTuning,
+		Percent:        50,
+		Backend:        "opencl",
+		GPU:            "NVIDIA GeForce RTX 3080",
+		Message:        "Tuning 10/20 matMul",
+		ElapsedSeconds: 42,
+	})
+	handler := NewToolsHandler(engine, logger)
+
+	result, err := handler.HandleGetEngineStatus(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"Startup: tuning, 50% (42s elapsed)", "Tuning 10/20", "Backend: opencl", "GPU device: NVIDIA GeForce RTX 3080"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in status, got %q", want, text)
+		}
+	}
+}
+
+func TestGetAnalysisSettingsTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	configPath := t.TempDir() + "/analysis.cfg"
+	if err := os.WriteFile(configPath, []byte("maxVisits = 500\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	defaultEngine := katago.NewMockEngine()
+	caps, err := katago.CapabilitiesFor("1.15.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultEngine.SetCapabilities(caps)
+	fastEngine := katago.NewMockEngine()
+	handler := NewToolsHandler(defaultEngine, logger)
+	handler.SetEngines(map[string]katago.EngineInterface{
+		config.DefaultEngineName: defaultEngine,
+		"fast":                   fastEngine,
+	}, nil)
+	handler.SetConfig(&config.Config{
+		KataGo:  config.KataGoConfig{ConfigPath: configPath, NumThreads: 4, MaxVisits: 1000, MaxTime: 10},
+		Engines: []config.EngineConfig{{Name: "fast", KataGoConfig: config.KataGoConfig{NumThreads: 2, MaxVisits: 100, MaxTime: 1}}},
+	})
+
+	call := func(args map[string]interface{}) (string, error) {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "getAnalysisSettings", Arguments: args}}
+		result, err := handler.HandleGetAnalysisSettings(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Analysis settings of engine default",
+		"KataGo version: 1.15.3",
+		"Default rules: chinese",
+		"Max visits: 500",
+		"Cache: disabled",
+		"KataGo config file: " + configPath,
+		"maxVisits = 500",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in settings, got %q", want, text)
+		}
+	}
+
+	text, err = call(map[string]interface{}{"profile": "fast", "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var settings katago.AnalysisSettings
+	if err := json.Unmarshal([]byte(text), &settings); err != nil {
+		t.Fatalf("Expected JSON, got %q", text)
+	}
+	if settings.Engine != "fast" || settings.MaxVisits != 100 || settings.KataGoVersion != "" {
+		t.Errorf("Expected the fast engine's settings, got %+v", settings)
+	}
+
+	if _, err := call(map[string]interface{}{"profile": "slow"}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected an invalid argument error for an unknown profile, got %v", err)
+	}
+	if _, err := call(map[string]interface{}{"format": "xml"}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected an invalid argument error for an unknown format, got %v", err)
+	}
+}
+
+func TestGetQuotaTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	handler := NewToolsHandler(katago.NewMockEngine(), logger)
+	ctx := WithClientID(context.Background(), "alice")
+	call := func(args map[string]interface{}) (string, error) {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "getQuota", Arguments: args}}
+		result, err := handler.HandleGetQuota(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "Quotas are not enabled") {
+		t.Errorf("Expected quotas to be reported disabled, got %q", text)
+	}
+
+	tracker, err := quota.NewTracker(&config.QuotaConfig{
+		Enabled:     true,
+		QuotaLimits: config.QuotaLimits{DailyVisits: 1000},
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to create tracker: %v", err)
+	}
+	tracker.Charge("alice", 400)
+	handler.SetQuota(tracker)
+
+	text, err = call(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{"Quota for client alice", "Today: 400 of 1000 visits used, 600 remaining", "This month: 400 visits used (unlimited)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in quota, got %q", want, text)
+		}
+	}
+
+	text, err = call(map[string]interface{}{"format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var status quota.Status
+	if err := json.Unmarshal([]byte(text), &status); err != nil {
+		t.Fatalf("Expected JSON, got %q", text)
+	}
+	if status.Client != "alice" || status.Daily.Remaining != 600 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+}
+
+func TestToolErrorCodes(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want apperrors.Code
+	}{
+		{"missing sgf", map[string]interface{}{}, apperrors.CodeInvalidArgument},
+		{"invalid sgf", map[string]interface{}{"sgf": "not an sgf"}, apperrors.CodeInvalidSGF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{
+				Params: mcp.CallToolParams{
+					Name:      "evaluateTerritory",
+					Arguments: tt.args,
+				},
+			}
+			_, err := handler.HandleEvaluateTerritory(context.Background(), req)
+			if code := apperrors.CodeOf(err); code != tt.want {
+				t.Errorf("Expected code %s, got %s (%v)", tt.want, code, err)
+			}
+		})
+	}
+}
+
+func TestEngineRouting(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	defaultEngine := katago.NewMockEngine()
+	defaultEngine.SetRunning(true)
+	fastEngine := katago.NewMockEngine()
+	fastEngine.SetRunning(true)
+
+	handler := NewToolsHandler(katago.NewMockEngine(), logger)
+	handler.SetEngines(map[string]katago.EngineInterface{
+		config.DefaultEngineName: defaultEngine,
+		"fast":                   fastEngine,
+	}, map[string]string{"explainMove": "fast"})
+
+	request := func(args map[string]interface{}) mcp.CallToolRequest {
+		return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+	}
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]interface{}
+		want katago.EngineInterface
+	}{
+		{"unrouted tool", "analyzePosition", nil, defaultEngine},
+		{"routed tool", "explainMove", nil, fastEngine},
+		{"profile overrides default", "analyzePosition", map[string]interface{}{"profile": "fast"}, fastEngine},
+		{"profile overrides route", "explainMove", map[string]interface{}{"profile": "default"}, defaultEngine},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := handler.engineFor(tt.tool, request(tt.args))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if engine != tt.want {
+				t.Error("Selected the wrong engine")
+			}
+		})
+	}
+
+	_, err := handler.engineFor("analyzePosition", request(map[string]interface{}{"profile": "huge"}))
+	if code := apperrors.CodeOf(err); code != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for unknown profile, got %s (%v)", apperrors.CodeInvalidArgument, code, err)
+	}
+
+	// Management tools act on the selected engine only
+	if _, err := handler.HandleStopEngine(context.Background(), request(map[string]interface{}{"profile": "fast"})); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fastEngine.IsRunning() || !defaultEngine.IsRunning() {
+		t.Error("Expected only the fast engine to stop")
+	}
+
+	result, err := handler.HandleGetEngineStatus(context.Background(), request(nil))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "- default: running") || !strings.Contains(text, "- fast: stopped") {
+		t.Errorf("Expected per-engine status, got %q", text)
+	}
+}
+
+func TestSuggestHumanMoveTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "suggestHumanMove",
+			Arguments: map[string]interface{}{
+				"sgf":          "(;GM[1]FF[4]SZ[19]KM[7.5];B[dd];W[pp])",
+				"humanProfile": "rank_5k",
+			},
+		},
+	}
+	result, err := handler.HandleSuggestHumanMove(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"rank_5k", "**D4** (40.0% likely)", "loses 0.3 points", "## AI Best Move", "**Q16**"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+
+	req.Params.Arguments = map[string]interface{}{
+		"sgf":          "(;GM[1]FF[4]SZ[19]KM[7.5];B[dd])",
+		"humanProfile": "grandmaster",
+	}
+	_, err = handler.HandleSuggestHumanMove(context.Background(), req)
+	if code := apperrors.CodeOf(err); code != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for invalid profile, got %s (%v)", apperrors.CodeInvalidArgument, code, err)
+	}
+}
+
+func TestEstimateRankTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "estimateRank",
+			Arguments: map[string]interface{}{
+				"sgfs": []interface{}{
+					"(;GM[1]SZ[19]PB[Alice]PW[Bob];B[dd];W[pp])",
+					"(;GM[1]SZ[19]PB[Carol]PW[Alice];B[dd];W[pp])",
+				},
+				"player": "Alice",
+			},
+		},
+	}
+	result, err := handler.HandleEstimateRank(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"**Estimated rank**: 5k (95% interval: 8k to 3k)", "Game 2 (W)", "C3 instead of R4 loses 12.5 points"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want apperrors.Code
+	}{
+		{"missing games", map[string]interface{}{"player": "Alice"}, apperrors.CodeInvalidArgument},
+		{"games not strings", map[string]interface{}{"sgfs": []interface{}{1}, "player": "Alice"}, apperrors.CodeInvalidArgument},
+		{"unknown player", map[string]interface{}{"sgfs": []interface{}{"(;GM[1]PB[A]PW[B];B[dd])"}, "player": "Zed"}, apperrors.CodeInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req.Params.Arguments = tt.args
+			_, err := handler.HandleEstimateRank(context.Background(), req)
+			if code := apperrors.CodeOf(err); code != tt.want {
+				t.Errorf("Expected code %s, got %s (%v)", tt.want, code, err)
+			}
+		})
+	}
+}
+
+func TestAnalyzePositionMoveRestrictions(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{
+		MoveInfos: []katago.MoveInfo{{Move: "Q16", Visits: 100}},
+	}, nil)
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) error {
+		args["sgf"] = "(;GM[1]FF[4]SZ[19];B[dd])"
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "analyzePosition", Arguments: args}}
+		_, err := handler.HandleAnalyzePosition(context.Background(), req)
+		return err
+	}
+
+	// Only consider the top-right quadrant, except the 4-4 point
+	err := call(map[string]interface{}{
+		"allowMoves": []interface{}{"top-right"},
+		"avoidMoves": []interface{}{"Q16"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	avoid := engine.GetLastAnalyzeRequest().AvoidMoves
+	if len(avoid) != 361-100+1 {
+		t.Errorf("Expected %d avoided points, got %d", 361-100+1, len(avoid))
+	}
+	for _, move := range avoid {
+		if move == "R17" {
+			t.Error("Did not expect a top-right point other than Q16 to be avoided")
+		}
+	}
+
+	if err := call(map[string]interface{}{"avoidMoves": []interface{}{"Z1"}}); apperrors.CodeOf(err) != apperrors.CodeBadCoordinate {
+		t.Errorf("Expected %s, got %v", apperrors.CodeBadCoordinate, err)
+	}
+	if err := call(map[string]interface{}{"avoidMoves": "D4"}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestAnalyzePositionSearchSettings(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{
+		MoveInfos: []katago.MoveInfo{{Move: "Q16", Visits: 100}},
+	}, nil)
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) error {
+		args["sgf"] = "(;GM[1]FF[4]SZ[19];B[dd])"
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "analyzePosition", Arguments: args}}
+		_, err := handler.HandleAnalyzePosition(context.Background(), req)
+		return err
+	}
+
+	if err := call(map[string]interface{}{"wideRootNoise": 0.3, "analysisPVLen": 25.0}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	search := engine.GetLastAnalyzeRequest().Search
+	if search.WideRootNoise == nil || *search.WideRootNoise != 0.3 || search.AnalysisPVLen == nil || *search.AnalysisPVLen != 25 {
+		t.Errorf("Expected search settings to be passed through, got %+v", search)
+	}
+	if search.RootPolicyTemperature != nil {
+		t.Errorf("Expected rootPolicyTemperature to be left to config, got %v", *search.RootPolicyTemperature)
+	}
+
+	if err := call(map[string]interface{}{"rootPolicyTemperature": 0.0}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s, got %v", apperrors.CodeInvalidArgument, err)
+	}
+
+	if err := call(map[string]interface{}{"averageSymmetries": true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !engine.GetLastAnalyzeRequest().AverageSymmetries {
+		t.Error("Expected symmetry averaging to be requested")
+	}
+}
+
+func TestToolSearchDefaults(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{
+		MoveInfos: []katago.MoveInfo{{Move: "Q16", Visits: 100}},
+	}, nil)
+	handler := NewToolsHandler(engine, logger)
+	handler.SetConfig(&config.Config{Tools: map[string]config.ToolConfig{
+		"analyzePosition": {MaxVisits: 250, MaxTime: 2.5},
+	}})
+
+	call := func(args map[string]interface{}) *katago.AnalysisRequest {
+		args["sgf"] = "(;GM[1]FF[4]SZ[19];B[dd])"
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "analyzePosition", Arguments: args}}
+		if _, err := handler.HandleAnalyzePosition(context.Background(), req); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		return engine.GetLastAnalyzeRequest()
+	}
+
+	req := call(map[string]interface{}{})
+	if req.MaxVisits == nil || *req.MaxVisits != 250 || req.MaxTime == nil || *req.MaxTime != 2.5 {
+		t.Errorf("Expected the tool's defaults, got %v visits, %v seconds", req.MaxVisits, req.MaxTime)
+	}
+	req = call(map[string]interface{}{"maxVisits": 40.0, "maxTime": 1.0})
+	if *req.MaxVisits != 40 || *req.MaxTime != 1 {
+		t.Errorf("Expected the request's settings to win, got %d visits, %g seconds", *req.MaxVisits, *req.MaxTime)
+	}
+
+	// Tools without configured defaults leave the search to the engine
+	handler.SetConfig(&config.Config{})
+	if req := call(map[string]interface{}{}); req.MaxVisits != nil || req.MaxTime != nil {
+		t.Errorf("Expected the engine's defaults, got %v visits, %v seconds", req.MaxVisits, req.MaxTime)
+	}
+}
+
+func TestAnalyzePositionBoard(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{
+		MoveInfos: []katago.MoveInfo{{Move: "C3", Visits: 100}},
+	}, nil)
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) error {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "analyzePosition", Arguments: args}}
+		_, err := handler.HandleAnalyzePosition(context.Background(), req)
+		return err
+	}
+
+	err := call(map[string]interface{}{
+		"board":  ". . . . .\n. X O . .\n. . X . .\n. . . . .\n. . . . .",
+		"toPlay": "W",
+		"komi":   0.5,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	position := engine.GetLastAnalyzeRequest().Position
+	if position.BoardXSize != 5 || len(position.InitialStones) != 3 || position.InitialPlayer != "w" || position.Komi != 0.5 {
+		t.Errorf("Unexpected position: %+v", position)
+	}
+
+	if err := call(map[string]interface{}{"board": "B D4 W Q16"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	position = engine.GetLastAnalyzeRequest().Position
+	if position.BoardXSize != 19 || len(position.InitialStones) != 2 || position.InitialPlayer != "" {
+		t.Errorf("Unexpected position: %+v", position)
+	}
+
+	if err := call(map[string]interface{}{"board": "B T20", "boardSize": 19.0}); apperrors.CodeOf(err) != apperrors.CodeBadCoordinate {
+		t.Errorf("Expected %s, got %v", apperrors.CodeBadCoordinate, err)
+	}
+	if err := call(map[string]interface{}{"board": ". X\n. .", "toPlay": "black"}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestAnalyzePositionPolicyOnly(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	policy := make([]float64, 82)
+	policy[40] = 0.7 // E5
+	policy[81] = 0.01
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{
+		RootInfo: katago.RootInfo{Visits: 1, Winrate: 0.55, ScoreLead: 1.5, CurrentPlayer: "B"},
+		Policy:   policy,
+	}, nil)
+	handler := NewToolsHandler(engine, logger)
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "analyzePosition", Arguments: map[string]interface{}{
+		"sgf":        "(;GM[1]FF[4]SZ[9])",
+		"policyOnly": true,
+		"maxVisits":  500.0,
+	}}}
+	result, err := handler.HandleAnalyzePosition(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	last := engine.GetLastAnalyzeRequest()
+	if last.MaxVisits == nil || *last.MaxVisits != 1 || !last.IncludePolicy {
+		t.Errorf("Expected a single-visit policy request, got %+v", last)
+	}
+
+	var eval katago.PolicyEvaluation
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &eval); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if eval.Winrate != 0.55 || len(eval.TopMoves) != 2 || eval.TopMoves[0].Move != "E5" || eval.TopMoves[1].Move != "pass" {
+		t.Errorf("Unexpected evaluation: %+v", eval)
+	}
+}
+
+func TestAnalysisJSONResult(t *testing.T) {
+	result := &katago.AnalysisResult{RootInfo: katago.RootInfo{Visits: 100, CurrentPlayer: "B"}}
+	small, err := analysisJSONResult(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(small.Content) != 1 || !strings.Contains(small.Content[0].(mcp.TextContent).Text, "\n  \"rootInfo\"") {
+		t.Errorf("Expected a small result as one indented document, got %+v", small.Content)
+	}
+
+	// Ownership after each of 200 candidates is over a megabyte of JSON
+	result.MovesOwnership = make(map[string][][]float64)
+	for i := 0; i < 200; i++ {
+		ownership := make([][]float64, 19)
+		for row := range ownership {
+			ownership[row] = make([]float64, 19)
+			for col := range ownership[row] {
+				ownership[row][col] = -0.12345678901234
+			}
+		}
+		result.MovesOwnership[fmt.Sprintf("M%03d", i)] = ownership
+	}
+	large, err := analysisJSONResult(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(large.Content) != 201 {
+		t.Fatalf("Expected the analysis and 200 ownership parts, got %d blocks", len(large.Content))
+	}
+	var head katago.AnalysisResult
+	if err := json.Unmarshal([]byte(large.Content[0].(mcp.TextContent).Text), &head); err != nil || head.RootInfo.Visits != 100 || head.MovesOwnership != nil {
+		t.Errorf("Expected the analysis without movesOwnership first, got %+v, %v", head, err)
+	}
+	var part struct {
+		Move      string      `json:"move"`
+		Ownership [][]float64 `json:"ownership"`
+	}
+	if err := json.Unmarshal([]byte(large.Content[1].(mcp.TextContent).Text), &part); err != nil || part.Move != "M000" || len(part.Ownership) != 19 {
+		t.Errorf("Expected M000's ownership second, got %s, %v", part.Move, err)
+	}
+}
+
+func TestSweepKomiTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) (string, error) {
+		args["sgf"] = "(;GM[1]FF[4]SZ[19]KM[6.5];B[pd];W[dp])"
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "sweepKomi", Arguments: args}}
+		result, err := handler.HandleSweepKomi(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{"minKomi": 0.0, "maxKomi": 10.0, "step": 1.0})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "**Fair komi: 6.5**") {
+		t.Errorf("Expected fair komi 6.5, got %q", text)
+	}
+	if !strings.Contains(text, "| 0.0 | 76.0% | +6.5 | 200 |") {
+		t.Errorf("Expected a row for komi 0, got %q", text)
+	}
+
+	text, err = call(map[string]interface{}{"minKomi": 10.0, "maxKomi": 20.0, "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var sweep katago.KomiSweep
+	if err := json.Unmarshal([]byte(text), &sweep); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if sweep.FairKomi != nil || len(sweep.Points) != 6 {
+		t.Errorf("Expected 6 points without a crossing, got %+v", sweep)
+	}
+
+	if _, err := call(map[string]interface{}{"step": 0.3}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestEstimateScoreDistributionTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{
+		RootInfo:       katago.RootInfo{CurrentPlayer: "B", Visits: 200, Winrate: 0.3, ScoreLead: -2.5, ScoreStdev: 4},
+		Ownership:      make([]float64, 81),
+		OwnershipStdev: append([]float64{0.7}, make([]float64, 80)...),
+	}, nil)
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) (string, error) {
+		args["sgf"] = "(;GM[1]FF[4]SZ[9]KM[7];B[ee];W[cc])"
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "estimateScoreDistribution", Arguments: args}}
+		result, err := handler.HandleEstimateScoreDistribution(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{"maxVisits": 200.0})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{"**Expected result**: W+2.5 ± 4.0", "| 95% | B+4.1 |", "1 points have an uncertain owner", "- A9: ownership +0.00 ± 0.70"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+	if req := engine.GetLastAnalyzeRequest(); !req.IncludeScoreDistribution || *req.MaxVisits != 200 {
+		t.Errorf("Expected a score distribution request with 200 visits, got %+v", req)
+	}
+
+	text, err = call(map[string]interface{}{"format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var distribution katago.ScoreDistribution
+	if err := json.Unmarshal([]byte(text), &distribution); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if distribution.ScoreLead != -2.5 || len(distribution.Percentiles) != 5 || distribution.ContestedPoints != 1 {
+		t.Errorf("Unexpected distribution: %+v", distribution)
+	}
+
+	if _, err := call(map[string]interface{}{"format": "xml"}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestTerritoryTimelineTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	var sgf strings.Builder
+	sgf.WriteString("(;GM[1]FF[4]SZ[9]KM[7]")
+	for i := 0; i < 40; i++ {
+		color := "B"
+		if i%2 == 1 {
+			color = "W"
+		}
+		sgf.WriteString(fmt.Sprintf(";%s[%c%c]", color, 'a'+i/9, 'a'+i%9))
+	}
+	sgf.WriteString(")")
+
+	call := func(args map[string]interface{}) (string, error) {
+		args["sgf"] = sgf.String()
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "territoryTimeline", Arguments: args}}
+		result, err := handler.HandleTerritoryTimeline(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{"every 10 moves", "| 10 | 10 | 5 | 66 | +5 | +5.0 |", "| 30 * |", "- Moves 20-30: 15 points to White (Black +10, White +25)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in timeline, got %q", want, text)
+		}
+	}
+
+	text, err = call(map[string]interface{}{"interval": 20.0, "swing": 30.0, "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var timeline katago.TerritoryTimeline
+	if err := json.Unmarshal([]byte(text), &timeline); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if timeline.Interval != 20 || len(timeline.Points) != 2 || len(timeline.Swings) != 0 {
+		t.Errorf("Unexpected timeline: %+v", timeline)
+	}
+
+	if _, err := call(map[string]interface{}{"threshold": 2.0}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestKeyMomentsTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) (string, error) {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "keyMoments", Arguments: args}}
+		result, err := handler.HandleKeyMoments(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	// The mock engine hands White the game with Black's fifth move, and
+	// KataGo's best move with a low prior is Black's first
+	sgf := "(;GM[1]FF[4]SZ[19]KM[6.5];B[pd];W[dp];B[pp];W[dd];B[fq];W[cn];B[jp];W[qf];B[nc];W[rd])"
+	text, err := call(map[string]interface{}{"sgf": sgf})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"10 of 10 moves analyzed",
+		"- **Move 1** (Black Q16, brilliant): Found KataGo's best move despite a 2.0% policy prior",
+		"- **Move 9** (Black O17, decisive): Decided the game: Black's win rate fell from 50.0% to 10.0% and never recovered; Q16 was best",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in key moments, got %q", want, text)
+		}
+	}
+
+	text, err = call(map[string]interface{}{"sgf": sgf, "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var moments katago.KeyMoments
+	if err := json.Unmarshal([]byte(text), &moments); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if len(moments.Moments) != 2 || moments.Moments[1].Kind != katago.MomentDecisive || moments.Moments[1].ScoreChange != -30.5 {
+		t.Errorf("Unexpected key moments: %+v", moments)
+	}
+
+	if _, err := call(map[string]interface{}{"sgf": "(;GM[1]FF[4]SZ[19])"}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for a game without moves, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestWhatIfTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) (string, error) {
+		args["sgf"] = "(;GM[1]FF[4]SZ[19]KM[6.5];B[dd];W[pp])"
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "whatIf", Arguments: args}}
+		result, err := handler.HandleWhatIf(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{"moves": []interface{}{"Q16", "D4"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"**Sequence**: B Q16, W D4",
+		"The sequence follows the best line.",
+		"| Original | B | 52.5% | +0.5 | Q16 | 200 |",
+		"**Change**: -10.0% win rate, -2.0 points",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+
+	text, err = call(map[string]interface{}{"moves": []interface{}{"Q16", "C3", "pass"}, "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var result katago.WhatIfResult
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if result.MatchingMoves != 1 || len(result.Sequence) != 3 || result.After.CurrentPlayer != "W" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if _, err := call(map[string]interface{}{"moves": []interface{}{"D16"}}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for an occupied point, got %v", apperrors.CodeInvalidArgument, err)
+	}
+	if _, err := call(map[string]interface{}{}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s without moves, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestCompareAnalysesTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	defaultEngine := katago.NewMockEngine()
+	defaultEngine.SetRunning(true)
+	defaultEngine.SetAnalyzeResponse(&katago.AnalysisResult{
+		RootInfo:  katago.RootInfo{Visits: 100, Winrate: 0.45, ScoreLead: -1.0, CurrentPlayer: "B"},
+		MoveInfos: []katago.MoveInfo{{Move: "Q16", Winrate: 0.45}, {Move: "D16", Winrate: 0.44}},
+	}, nil)
+	bigEngine := katago.NewMockEngine()
+	bigEngine.SetRunning(true)
+	bigEngine.SetAnalyzeResponse(&katago.AnalysisResult{
+		RootInfo:  katago.RootInfo{Visits: 5000, Winrate: 0.5, ScoreLead: 0.2, CurrentPlayer: "B"},
+		MoveInfos: []katago.MoveInfo{{Move: "R16", Winrate: 0.5}, {Move: "Q16", Winrate: 0.47}},
+	}, nil)
+	handler := NewToolsHandler(defaultEngine, logger)
+	handler.SetEngines(map[string]katago.EngineInterface{
+		config.DefaultEngineName: defaultEngine,
+		"big":                    bigEngine,
+	}, nil)
+
+	call := func(args map[string]interface{}) (string, error) {
+		args["sgf"] = "(;GM[1]FF[4]SZ[19]KM[6.5];B[dd];W[pp])"
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "compareAnalyses", Arguments: args}}
+		result, err := handler.HandleCompareAnalyses(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{"maxVisitsA": 100.0, "profileB": "big", "maxVisitsB": 5000.0})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"| A: configured engine, 100 visits | 100 |",
+		"| B: big, 5000 visits | 5000 |",
+		"**Difference (B - A)**: +5.0% win rate, +1.2 points",
+		"The settings disagree on the best move: Q16 against R16.",
+		"| Q16 | 1 | 2 | 45.0% | 47.0% |",
+		"| D16 | 2 | - | 44.0% | - |",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+	if req := bigEngine.GetLastAnalyzeRequest(); req == nil || *req.MaxVisits != 5000 {
+		t.Errorf("Expected the big engine analyzed at 5000 visits, got %+v", req)
+	}
+
+	text, err = call(map[string]interface{}{"topMoves": 1.0, "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var comparison katago.AnalysisComparison
+	if err := json.Unmarshal([]byte(text), &comparison); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if !comparison.Agree || len(comparison.Moves) != 1 || comparison.A.Label != "configured engine, engine visits" {
+		t.Errorf("Expected the same engine to agree with itself, got %+v", comparison)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"profileA": "huge"},
+		{"maxVisitsB": 0.0},
+		{"topMoves": 50.0},
+	} {
+		if _, err := call(args); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+			t.Errorf("Expected %s for %v, got %v", apperrors.CodeInvalidArgument, args, err)
+		}
+	}
+}
+
+func TestSolveTsumegoTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) (string, error) {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "solveTsumego", Arguments: args}}
+		result, err := handler.HandleSolveTsumego(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	board := "$$ ---------\n$$ | . . O X .\n$$ | O O O X .\n$$ | X X X X .\n$$ | . . . . ."
+	text, err := call(map[string]interface{}{"board": board, "boardSize": 9.0, "region": []interface{}{"A6-E9"}, "target": "B8"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"**B to play.** The White group at B8 **dies** (ownership -1.00).",
+		"**Best move**: A9",
+		"**Main line**: A9 B9",
+		"- **B9**: the group lives; refuted by A9",
+		"Searched 2 time(s), 800 visits in the last search.",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+
+	text, err = call(map[string]interface{}{"board": board, "boardSize": 9.0, "toPlay": "W",
+		"region": []interface{}{"A6-E9"}, "target": "B8", "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var solution katago.TsumegoSolution
+	if err := json.Unmarshal([]byte(text), &solution); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if solution.Status != katago.StatusLives || solution.ToPlay != "W" {
+		t.Errorf("Expected White to live with White to play, got %+v", solution)
+	}
+
+	if _, err := call(map[string]interface{}{"board": board, "target": "B8"}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s without a region, got %v", apperrors.CodeInvalidArgument, err)
+	}
+	if _, err := call(map[string]interface{}{"region": []interface{}{"A6-E9"}, "target": "B8"}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s without a problem, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestGenerateProblemsTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) (string, error) {
+		args["sgfs"] = []interface{}{"(;GM[1]FF[4]SZ[19]KM[6.5]PB[Alice]PW[Bob];B[pd];W[dp];B[cc];W[dd])"}
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "generateProblems", Arguments: args}}
+		result, err := handler.HandleGenerateProblems(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{"player": "Alice"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"# Problems for Alice",
+		"**1 problem(s)** from 1 game(s).",
+		"1. Game 1, move 3, Black to play: Q4, not C17 (blunder, -25.0% win rate)",
+		"```sgf\n(;GM[1]FF[4]CA[UTF-8]SZ[19]KM[6.5]RU[Chinese]GN[Problem 1]AB[pd]AW[dp]PL[B]",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+
+	text, err = call(map[string]interface{}{"player": "Bob", "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var set katago.ProblemSet
+	if err := json.Unmarshal([]byte(text), &set); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if len(set.Problems) != 1 || set.Problems[0].Color != "W" || set.Problems[0].BestMove != "Q4" {
+		t.Errorf("Unexpected problem set: %+v", set)
+	}
+
+	if _, err := call(map[string]interface{}{}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s without a player, got %v", apperrors.CodeInvalidArgument, err)
+	}
+	if _, err := call(map[string]interface{}{"player": "Alice", "minGap": 2.0}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for a gap above 1, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestOpeningBookTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) (string, error) {
+		// Alice answers Q16 with R14 twice, in different corners
+		args["sgfs"] = []interface{}{
+			"(;GM[1]SZ[19]PB[Bob]PW[Alice]RE[W+R];B[pd];W[qf])",
+			"(;GM[1]SZ[19]PB[Bob]PW[Alice]RE[B+3.5];B[dp];W[cn])",
+			"(;GM[1]SZ[19]PB[Alice]PW[Bob];B[pd];W[dp])",
+		}
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "openingBook", Arguments: args}}
+		result, err := handler.HandleOpeningBook(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{"player": "Alice"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"# Opening Book for Alice",
+		"3 game(s) on 19x19, 12 moves deep. 1 habitual position(s) evaluated.",
+		"1. As White: Q16 O17 (move 2, 2 games, 1-1): loses 3.0 points; KataGo prefers Q16",
+		"## As Black (1 game(s))\n\nNo first move was played in 2 or more games.\n",
+		"## As White (2 game(s))\n\n- 1. Q16: 2 games, 1-1\n  - 2. O17: 2 games, 1-1, -3.0 (KataGo: Q16)\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+
+	text, err = call(map[string]interface{}{"player": "Alice", "minGames": 1.0, "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var book katago.OpeningBook
+	if err := json.Unmarshal([]byte(text), &book); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if book.Positions != 2 || len(book.AsBlack) != 1 || len(book.LosingLines) != 1 {
+		t.Errorf("Unexpected book: %+v", book)
+	}
+
+	if _, err := call(map[string]interface{}{}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s without a player, got %v", apperrors.CodeInvalidArgument, err)
+	}
+	if _, err := call(map[string]interface{}{"player": "Alice", "depth": 50.0}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for too deep a book, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestValidateSGFTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	// The tool does not need the engine to be running
+	handler := NewToolsHandler(katago.NewMockEngine(), logger)
+
+	call := func(args map[string]interface{}) (string, error) {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "validateSGF", Arguments: args}}
+		result, err := handler.HandleValidateSGF(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{"sgf": "(;GM[1]SZ[9]KM[7];B[ee];W[ee])"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"**Invalid**: 1 error(s)",
+		"- **Board**: 9x9\n- **Moves**: 2\n- **Variations**: 0\n- **Rules**: chinese, komi 7.0",
+		"- **error** (move 2, W): illegal move E5: point is occupied",
+		"## Suggestions\n\n- Add RU",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+
+	text, err = call(map[string]interface{}{"sgf": "(;GM[1]SZ[9]KM[7]RU[Japanese];B[ee])", "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var diagnostics katago.SGFDiagnostics
+	if err := json.Unmarshal([]byte(text), &diagnostics); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if !diagnostics.Valid || diagnostics.Moves != 1 || len(diagnostics.Issues) != 0 {
+		t.Errorf("Unexpected diagnostics: %+v", diagnostics)
+	}
+
+	if _, err := call(map[string]interface{}{}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s without an SGF, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestReviewArchiveTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, sgf := range map[string]string{
+		"a.sgf":      "(;GM[1]SZ[19]PB[Alice]PW[Bob]RE[W+3.5];B[pd])",
+		"readme.txt": "not a game",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(sgf))
+	}
+	zw.Close()
+	encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	call := func(args map[string]interface{}) (string, error) {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "reviewArchive", Arguments: args}}
+		result, err := handler.HandleReviewArchive(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{"archive": encoded})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"**1 of 1 game(s) reviewed**",
+		"| Alice | 1 | 0 | 0 | 90.0% |",
+		"1. a.sgf (Alice vs Bob, W+3.5): Black 0 mistake(s), 0 blunder(s), 90.0%",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+
+	text, err = call(map[string]interface{}{"archive": encoded, "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var archive katago.ArchiveReview
+	if err := json.Unmarshal([]byte(text), &archive); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if archive.Reviewed != 1 || len(archive.Players) != 2 || archive.Results[0].Summary == nil {
+		t.Errorf("Unexpected archive review: %+v", archive)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{},
+		{"archive": "not base64!"},
+		{"archive": base64.StdEncoding.EncodeToString([]byte("(;B[pd])"))},
+	} {
+		if _, err := call(args); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+			t.Errorf("%v: expected %s, got %v", args, apperrors.CodeInvalidArgument, err)
+		}
+	}
+}
+
+func TestFormatGameReviewTenuki(t *testing.T) {
+	review := &katago.GameReview{
+		Mistakes: []katago.Mistake{
+			{MoveNumber: 12, Color: "B", PlayedMove: "C3", BestMove: "D5", Category: "mistake", WinrateDrop: 0.06},
+			{MoveNumber: 31, Color: "W", PlayedMove: "D4", BestMove: "R16", Category: "blunder", WinrateDrop: 0.2,
+				Tenuki: true, HotArea: []string{"R16", "Q17"}},
+		},
+		Summary: katago.ReviewSummary{TotalMoves: 40, WhiteTenukis: 1},
+	}
+
+	text := formatGameReview(i18n.English, review)
+	mistakes := strings.Index(text, "## Mistakes Found")
+	tenukis := strings.Index(text, "## Tenuki From Hot Areas")
+	if mistakes < 0 || tenukis < mistakes {
+		t.Fatalf("Expected separate mistake and tenuki sections, got %q", text)
+	}
+	if strings.Contains(text[mistakes:tenukis], "Move 31") || !strings.Contains(text[tenukis:], "Move 31 (W)") {
+		t.Errorf("Expected move 31 only in the tenuki section, got %q", text)
+	}
+	for _, want := range []string{"Tenuki from hot areas (Black/White): 0/1", "**Hot area**: R16, Q17 (best: R16"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+}
+
+func TestFormatGameReviewDifficulty(t *testing.T) {
+	review := &katago.GameReview{
+		Mistakes: []katago.Mistake{
+			{MoveNumber: 12, Color: "B", PlayedMove: "C3", BestMove: "D5", Category: "mistake", WinrateDrop: 0.06, Difficulty: 0.72},
+			{MoveNumber: 31, Color: "W", PlayedMove: "D4", BestMove: "R16", Category: "blunder", WinrateDrop: 0.2, Difficulty: 0.1},
+		},
+		Summary: katago.ReviewSummary{TotalMoves: 40, BlackHardMistakes: 1, WhiteEasyMistakes: 1},
+	}
+
+	text := formatGameReview(i18n.English, review)
+	for _, want := range []string{
+		"Black mistakes in hard/easy positions: 1/0",
+		"White mistakes in hard/easy positions: 0/1",
+		"**Difficulty**: 0.72 (hard)",
+		"**Difficulty**: 0.10 (easy)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+}
+
+func TestFormatGameReviewThemes(t *testing.T) {
+	review := &katago.GameReview{
+		Mistakes: []katago.Mistake{
+			{MoveNumber: 45, Color: "B", PlayedMove: "F3", BestMove: "D4", Category: "blunder", WinrateDrop: 0.2, Theme: katago.ThemeLifeAndDeath},
+		},
+		Themes: []katago.MistakeTheme{
+			{Theme: katago.ThemeLifeAndDeath, Mistakes: 2, Moves: []int{45, 88}, WinrateLost: 0.31, Advice: "Read it out."},
+			{Theme: katago.ThemeEndgame, Mistakes: 1, Moves: []int{201}, WinrateLost: 0.06, Advice: "Count."},
+		},
+		Summary: katago.ReviewSummary{TotalMoves: 250},
+	}
+
+	text := formatGameReview(i18n.English, review)
+	for _, want := range []string{
+		"- **Theme**: Life and death\n",
+		"## Themes to Work On\n\n1. **Life and death**: 2 mistakes (moves 45, 88), 31.0% win rate lost\n   Read it out.\n" +
+			"2. **Endgame counting**: 1 mistakes (moves 201), 6.0% win rate lost\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+}
+
+func TestFormatGameReviewGoodMoves(t *testing.T) {
+	review := &katago.GameReview{
+		GoodMoves: []katago.GoodMove{
+			{MoveNumber: 37, Color: "B", PlayedMove: "R4", Category: "brilliant", PlayedWR: 0.612,
+				Explanation: "A brilliant find: KataGo's best move despite a 1.8% policy prior"},
+		},
+		Summary: katago.ReviewSummary{TotalMoves: 40},
+	}
+	katago.AttributeReview(review, katago.GameInfo{PlayerBlack: "Lee", BlackRank: "3d"})
+
+	text := formatGameReview(i18n.English, review)
+	for _, want := range []string{
+		"## No significant mistakes found!",
+		"## Best Moves\n\n### Move 37 (Lee 3d, B)\n- **Category**: brilliant\n- **Played**: R4 (61.2% WR)\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+}
+
+func TestFormatGameReviewPlayers(t *testing.T) {
+	review := &katago.GameReview{
+		Mistakes: []katago.Mistake{
+			{MoveNumber: 12, Color: "B", PlayedMove: "C3", BestMove: "D5", Category: "mistake", WinrateDrop: 0.06},
+		},
+		Summary: katago.ReviewSummary{TotalMoves: 40},
+	}
+	katago.AttributeReview(review, katago.GameInfo{PlayerBlack: "Lee", BlackRank: "3d", PlayerWhite: "Kim", Result: "W+R"})
+	review.Summary.Result, _ = katago.CheckResult(i18n.English, "W+R", &katago.AnalysisResult{
+		RootInfo: katago.RootInfo{Winrate: 0.71, ScoreLead: 3.4},
+	})
+
+	text := formatGameReview(i18n.English, review)
+	for _, want := range []string{
+		"Black: Lee 3d vs White: Kim, result W+R",
+		"### Move 12 (Lee 3d, B)",
+		"- Recorded result: W+R\n- KataGo's estimate: B+3.4\n",
+		"- **Result disputed**: The player who resigned had a 71.0% win rate in the final position",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+	japanese, err := i18n.NewPrinter("ja")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := formatGameReview(japanese, review); !strings.Contains(text, "黒: Lee 3d 対 白: Kim, 結果 W+R") {
+		t.Errorf("Expected the players in Japanese, got %q", text)
+	}
+}
+
+func TestFindMistakesExportReport(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{
+		"sgf":          "(;GM[1]SZ[9]PB[Alice]PW[Bob];B[ee];W[cc])",
+		"exportReport": "html",
+	}
+	result, err := handler.HandleFindMistakes(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected review text and report, got %d items", len(result.Content))
+	}
+	resource, ok := mcp.AsEmbeddedResource(result.Content[1])
+	if !ok {
+		t.Fatalf("Expected an embedded report, got %T", result.Content[1])
+	}
+	contents, ok := mcp.AsTextResourceContents(resource.Resource)
+	if !ok || contents.MIMEType != "text/html" || !strings.Contains(contents.Text, "Alice (B) vs Bob (W)") {
+		t.Errorf("Unexpected report: %+v", resource.Resource)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"sgf": "(;GM[1]SZ[9];B[ee])", "exportReport": "pdf"}
+	_, err = handler.HandleFindMistakes(context.Background(), request)
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected invalid argument for unsupported format, got %v", err)
+	}
+}
+
+func TestFindMistakesDecidedGame(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	for _, args := range []map[string]interface{}{
+		{"decidedWinrate": 0.4},
+		{"decidedWinrate": 1.0},
+		{"decidedWinrate": 0.95, "decidedMoves": 0.0},
+	} {
+		args["sgf"] = "(;GM[1]SZ[9];B[ee])"
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "findMistakes", Arguments: args}}
+		if _, err := handler.HandleFindMistakes(context.Background(), request); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+			t.Errorf("Expected %s for %v, got %v", apperrors.CodeInvalidArgument, args, err)
+		}
+	}
+
+	review := &katago.GameReview{Summary: katago.ReviewSummary{TotalMoves: 180, DecidedAtMove: 92}}
+	want := "- **Decided** after move 92: later moves were analyzed at minimal visits and not judged\n"
+	if text := formatGameReview(i18n.English, review); !strings.Contains(text, want) {
+		t.Errorf("Expected %q in output, got %q", want, text)
+	}
+
+	review.Repetitions = []katago.Repetition{{MoveNumber: 126, Color: "W", RepeatsMove: 120}}
+	want = "- Move 126 (W) repeats the position after move 120\n"
+	if text := formatGameReview(i18n.English, review); !strings.Contains(text, "## Repeated Positions") || !strings.Contains(text, want) {
+		t.Errorf("Expected %q in output, got %q", want, text)
+	}
+}
+
+func TestFormatGameReviewTimePressure(t *testing.T) {
+	left := 25.0
+	review := &katago.GameReview{
+		Summary: katago.ReviewSummary{TotalMoves: 200},
+		Mistakes: []katago.Mistake{
+			{MoveNumber: 180, Color: "B", PlayedMove: "D4", BestMove: "Q16", Category: "blunder", WinrateDrop: 0.3, TimeLeft: &left},
+		},
+		TimePressure: &katago.TimePressure{
+			MainTime:   3600,
+			Overtime:   "5x30 byo-yomi",
+			TimedMoves: 200,
+			Ranges: []katago.TimeRange{
+				{Range: "under 30s", BlackMoves: 20, WhiteMoves: 22, BlackBlunders: 1},
+				{Range: "over 10m", BlackMoves: 80, WhiteMoves: 78, WhiteMistakes: 2},
+			},
+			Observations: []string{"Most blunders by Lee (1 of 1) came with under 30 seconds left"},
+		},
+	}
+	text := formatGameReview(i18n.English, review)
+	for _, want := range []string{
+		"- **Time left**: 0:25\n",
+		"## Time Pressure\n\nTime control: 1:00:00 + 5x30 byo-yomi\n",
+		"| under 30s | 20 | 0/1 | 22 | 0/0 |\n",
+		"| over 10m | 80 | 0/0 | 78 | 2/0 |\n",
+		"- Most blunders by Lee (1 of 1) came with under 30 seconds left\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+}
+
+func TestFindMistakesPaging(t *testing.T) {
+	review := &katago.GameReview{
+		Summary: katago.ReviewSummary{TotalMoves: 200},
+		Mistakes: []katago.Mistake{
+			{MoveNumber: 12, Color: "B", PlayedMove: "D4", BestMove: "Q16", Category: "mistake", WinrateDrop: 0.12},
+			{MoveNumber: 45, Color: "W", PlayedMove: "C3", BestMove: "R3", Category: "blunder", WinrateDrop: 0.25},
+			{MoveNumber: 80, Color: "B", PlayedMove: "K10", BestMove: "O17", Category: "blunder", WinrateDrop: 0.40},
+		},
+		GoodMoves: []katago.GoodMove{{MoveNumber: 7, Color: "W", PlayedMove: "F17", Category: "brilliant"}},
+	}
+	format := func(args map[string]interface{}) (string, error) {
+		page, err := parseMistakePage(args)
+		if err != nil {
+			return "", err
+		}
+		return page.format(i18n.English, review)
+	}
+
+	// Without paging arguments the review is unchanged
+	if text, err := format(map[string]interface{}{}); err != nil || text != formatGameReview(i18n.English, review) {
+		t.Errorf("Expected the whole review, got %q (%v)", text, err)
+	}
+
+	tests := []struct {
+		args    map[string]interface{}
+		moves   []string // Listed move headings, in order
+		footer  string
+		noGoods bool
+	}{
+		{
+			args:   map[string]interface{}{"limit": 2.0},
+			moves:  []string{"Move 12", "Move 45"},
+			footer: "Listed 2 of 3 mistakes, starting at number 1. Call findMistakes with offset 2 for the next ones.",
+		},
+		{
+			args:    map[string]interface{}{"limit": 2.0, "offset": 2.0},
+			moves:   []string{"Move 80"},
+			footer:  "Listed 1 of 3 mistakes, starting at number 3. Call getMoveDetails",
+			noGoods: true,
+		},
+		{
+			args:   map[string]interface{}{"minSeverity": "blunder"},
+			moves:  []string{"Move 45", "Move 80"},
+			footer: "Listed 2 of 2 blunders, starting at number 1. Call getMoveDetails",
+		},
+		{
+			args:    map[string]interface{}{"summarize": true, "limit": 2.0},
+			moves:   []string{"Move 80", "Move 45"},
+			footer:  "Listed 2 of 3 mistakes, starting at number 1. The costliest are listed first. Call findMistakes with offset 2",
+			noGoods: true,
+		},
+	}
+	for _, tt := range tests {
+		text, err := format(tt.args)
+		if err != nil {
+			t.Errorf("Unexpected error for %v: %v", tt.args, err)
+			continue
+		}
+		last := -1
+		for _, move := range []string{"Move 12", "Move 45", "Move 80"} {
+			i := strings.Index(text, "### "+move+" ")
+			listed := false
+			for _, want := range tt.moves {
+				listed = listed || want == move
+			}
+			if listed != (i >= 0) {
+				t.Errorf("For %v, expected %s listed: %v, got %q", tt.args, move, listed, text)
+			}
+		}
+		for _, move := range tt.moves {
+			i := strings.Index(text, "### "+move+" ")
+			if i < last {
+				t.Errorf("For %v, expected %s after the previous move, got %q", tt.args, move, text)
+			}
+			last = i
+		}
+		if !strings.Contains(text, tt.footer) {
+			t.Errorf("For %v, expected %q, got %q", tt.args, tt.footer, text)
+		}
+		if hasGoods := strings.Contains(text, "F17"); hasGoods == tt.noGoods {
+			t.Errorf("For %v, expected best moves listed: %v, got %q", tt.args, !tt.noGoods, text)
+		}
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"offset": 3.0},
+		{"offset": -1.0},
+		{"limit": 0.0},
+		{"minSeverity": "inaccuracy"},
+	} {
+		if _, err := format(args); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+			t.Errorf("Expected %s for %v, got %v", apperrors.CodeInvalidArgument, args, err)
+		}
+	}
+}
+
+func TestGetMoveDetails(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{
+		RootInfo: katago.RootInfo{Winrate: 0.4, ScoreLead: -2, Visits: 200},
+		MoveInfos: []katago.MoveInfo{
+			{Move: "G3", Winrate: 0.45, ScoreLead: -1, Visits: 120, Prior: 0.3, PV: []string{"G3", "C3"}},
+			{Move: "E5", Winrate: 0.42, ScoreLead: -1.5, Visits: 50, Prior: 0.2},
+			{Move: "C7", Winrate: 0.2, ScoreLead: -8, Visits: 10, Prior: 0.05},
+		},
+	}, nil)
+	handler := NewToolsHandler(engine, logger)
+	sgf := "(;GM[1]SZ[9]PB[Alice]PW[Bob];B[ee];W[cc])"
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"sgf": sgf, "moveNumber": 2.0}
+	result, err := handler.HandleGetMoveDetails(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{
+		"# Move 2 (Bob, White): C7",
+		"- **Category**: blunder",
+		"- **Played**: C7, KataGo's choice #3 (20.0% WR, policy 5.0%)",
+		"| G3 | 45.0% | -1.0 | 120 | 30.0% | G3 C3 |",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+
+	// The move is analyzed as findMistakes analyzes it, from the position
+	// before it
+	req := engine.GetLastAnalyzeRequest()
+	if len(req.Position.Moves) != 1 || !req.IncludePolicy {
+		t.Errorf("Expected the position before move 2 with policy, got %+v", req)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"sgf": sgf, "moveNumber": 1.0, "format": "json"}
+	result, err = handler.HandleGetMoveDetails(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var detail katago.MoveDetail
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &detail); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if detail.PlayedMove != "E5" || detail.Rank != 2 || detail.Category != "inaccuracy" || len(detail.Candidates) != 3 {
+		t.Errorf("Unexpected detail: %+v", detail)
+	}
+
+	for _, n := range []float64{0, 3} {
+		request.Params.Arguments = map[string]interface{}{"sgf": sgf, "moveNumber": n}
+		if _, err := handler.HandleGetMoveDetails(context.Background(), request); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+			t.Errorf("Expected %s for move %v, got %v", apperrors.CodeInvalidArgument, n, err)
+		}
+	}
+}
+
+func TestCountingQuiz(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	// Black owns the left of the 5x5 board and White the right
+	ownership := make([]float64, 25)
+	for i := range ownership {
+		ownership[i] = -0.9
+		if i%5 < 3 {
+			ownership[i] = 0.9
+		}
+	}
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{RootInfo: katago.RootInfo{ScoreLead: 3.5}, Ownership: ownership}, nil)
+	handler := NewToolsHandler(engine, logger)
+	sgf := "(;GM[1]SZ[5]KM[0.5];B[bb];W[dd])"
+	call := func(args map[string]interface{}) (string, error) {
+		args["sgf"] = sgf
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "countingQuiz", Arguments: args}}
+		result, err := handler.HandleCountingQuiz(context.Background(), request)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	// Without a guess, the position is shown but not analyzed
+	text, err := call(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(text, "Move 2, Black to play, komi 0.5.") || strings.Contains(text, "KataGo") {
+		t.Errorf("Expected the question without the answer, got %q", text)
+	}
+	if engine.GetLastAnalyzeRequest() != nil {
+		t.Error("Expected the position not to be analyzed before a guess")
+	}
+
+	text, err = call(map[string]interface{}{"guess": "W+2", "regionGuesses": map[string]interface{}{"top-left": 6.0}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"- **KataGo's count**: B+3.5 (komi 0.5 included)",
+		"Your count favors White by 5.5 points too many.",
+		"You picked the wrong winner.",
+		"| top-left | 9 | 0 | +9 | +6.0 | -3.0 |",
+		"| top-right | 0 | 6 | -6 | - | - |",
+		"Your largest miss is the top-left",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+	if req := engine.GetLastAnalyzeRequest(); req == nil || !req.IncludeOwnership {
+		t.Errorf("Expected an ownership analysis, got %+v", req)
+	}
+
+	text, err = call(map[string]interface{}{"guess": "B+4", "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var answer katago.CountingAnswer
+	if err := json.Unmarshal([]byte(text), &answer); err != nil {
+		t.Fatalf("Expected JSON output: %v", err)
+	}
+	if !answer.Correct || answer.BlackPoints != 15 || len(answer.Regions) != 4 {
+		t.Errorf("Unexpected answer: %+v", answer)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"guess": "B+R"},
+		{"guess": "Black wins"},
+		{"guess": "B+1", "regionGuesses": map[string]interface{}{"middle": 1.0}},
+		{"guess": "B+1", "regionGuesses": map[string]interface{}{"top-left": "lots"}},
+	} {
+		if _, err := call(args); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+			t.Errorf("Expected %s for %v, got %v", apperrors.CodeInvalidArgument, args, err)
+		}
+	}
+}
+
+func TestReviewLanguage(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	text := func(result *mcp.CallToolResult) string {
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"sgf": "(;GM[1]SZ[9];B[ee])", "language": "ja"}
+	result, err := handler.HandleFindMistakes(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := text(result); !strings.HasPrefix(got, "# 対局の検討\n") || !strings.Contains(got, "- 黒の正確度: 90.0%") {
+		t.Errorf("Expected a Japanese review, got %q", got)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"sgf": "(;GM[1]SZ[9];B[ee])", "move": "C3", "language": "ko-KR"}
+	result, err = handler.HandleExplainMove(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := text(result); !strings.Contains(got, "# 착수 해설: C3") || !strings.Contains(got, "- 집 차이: 0.5집") {
+		t.Errorf("Expected a Korean explanation, got %q", got)
+	}
+
+	// The configured language applies when a request does not choose one
+	handler.SetOutput(&config.OutputConfig{SortMovesBy: "visits", Language: "zh"})
+	request.Params.Arguments = map[string]interface{}{"sgf": "(;GM[1]SZ[9];B[ee])"}
+	result, err = handler.HandleFindMistakes(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := text(result); !strings.HasPrefix(got, "# 对局复盘\n") {
+		t.Errorf("Expected the configured language, got %q", got)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"sgf": "(;GM[1]SZ[9];B[ee])", "language": "xx"}
+	_, err = handler.HandleFindMistakes(context.Background(), request)
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected invalid argument for unsupported language, got %v", err)
+	}
+}
+
+func TestExplainMoveTerms(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]interface{}{"sgf": "(;GM[1]FF[4]SZ[19];B[pd];W[pe])", "move": "R15"}
+	result, err := handler.HandleExplainMove(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "## Go Terms\n- **hane**: A stone played diagonally") {
+		t.Errorf("Expected the move tagged as a hane, got %q", text)
+	}
+
+	request.Params.Arguments = map[string]interface{}{"sgf": "(;GM[1]FF[4]SZ[19];B[pd];W[pe])", "move": "K10"}
+	result, err = handler.HandleExplainMove(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; strings.Contains(text, "Go Terms") {
+		t.Errorf("Expected no terms for a lone move, got %q", text)
+	}
+}
+
+func TestFormatMoveExplanationReplies(t *testing.T) {
+	explanation := &katago.MoveExplanation{
+		Move:        "E5",
+		Explanation: "E5 is KataGo's top choice",
+		Replies: []katago.Reply{
+			{Move: "D5", Winrate: 0.4, Threats: []string{"Cuts D4 from E5", "Attacks E5"}},
+			{Move: "C3", Winrate: 0.38},
+		},
+	}
+	text := formatMoveExplanation(i18n.English, "E5", explanation)
+	want := "## Opponent's Best Replies\nExpect D5 next.\n- **D5** (40.0% WR): Cuts D4 from E5; Attacks E5\n- **C3** (38.0% WR)\n"
+	if !strings.Contains(text, want) {
+		t.Errorf("Expected %q in output, got %q", want, text)
+	}
+}
+
+func TestExpandVariationTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	handler := NewToolsHandler(engine, logger)
+
+	args := map[string]interface{}{
+		"sgf":  "(;GM[1]FF[4]SZ[19];B[dd])",
+		"move": "D4",
+	}
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "expandVariation", Arguments: args}}
+
+	result, err := handler.HandleExpandVariation(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	for _, want := range []string{"Original PV: D4 R4", "- 2. W D4*: win 55.0%", "  - 3. B Q16: win 54.0%"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+
+	args["format"] = "json"
+	result, err = handler.HandleExpandVariation(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var tree katago.VariationTree
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &tree); err != nil {
+		t.Fatalf("Expected JSON tree: %v", err)
+	}
+	if tree.Root.Move != "D4" || len(tree.Root.Children) != 1 {
+		t.Errorf("Unexpected tree: %+v", tree.Root)
+	}
+
+	args["depth"] = 50.0
+	_, err = handler.HandleExpandVariation(context.Background(), req)
+	if code := apperrors.CodeOf(err); code != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for excessive depth, got %s (%v)", apperrors.CodeInvalidArgument, code, err)
+	}
+}
+
 func TestStartStopEngineTool(t *testing.T) {
 	cfg := &config.KataGoConfig{
 		BinaryPath: "mock-katago",
@@ -223,6 +2050,72 @@ func TestAnalyzePositionArguments(t *testing.T) {
 	}
 }
 
+func TestAnalyzePositionOutputOptions(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{
+		MoveInfos: []katago.MoveInfo{
+			{Move: "D4", Visits: 500, LCB: 0.45, Utility: 0.1},
+			{Move: "Q16", Visits: 300, LCB: 0.49, Utility: 0.2},
+		},
+	}, nil)
+
+	handler := NewToolsHandler(engine, logger)
+	handler.SetOutput(&config.OutputConfig{SortMovesBy: katago.SortByLCB})
+
+	call := func(args map[string]interface{}) (string, error) {
+		args["sgf"] = "(;GM[1]FF[4]SZ[19];B[dd])"
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "analyzePosition", Arguments: args}}
+		result, err := handler.HandleAnalyzePosition(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	// JSON mode includes LCB and utility, sorted by the configured order
+	text, err := call(map[string]interface{}{"format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var result katago.AnalysisResult
+	if err := json.Unmarshal([]byte(text), &result); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", text, err)
+	}
+	if result.MoveInfos[0].Move != "Q16" || result.MoveInfos[0].LCB != 0.49 {
+		t.Errorf("Expected Q16 first by LCB, got %+v", result.MoveInfos)
+	}
+	if !strings.Contains(text, `"utility": 0.2`) {
+		t.Errorf("Expected utility in JSON output, got %q", text)
+	}
+
+	// The request can override the order, and ask for text with policy included
+	text, err = call(map[string]interface{}{"format": "text", "sortBy": "visits", "includePolicy": true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Index(text, "D4") > strings.Index(text, "Q16") {
+		t.Errorf("Expected D4 first by visits, got %q", text)
+	}
+
+	// Asking for ownership after each move selects JSON
+	text, err = call(map[string]interface{}{"includeMovesOwnership": true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !engine.GetLastAnalyzeRequest().IncludeMovesOwnership || !strings.HasPrefix(text, "{") {
+		t.Errorf("Expected a JSON analysis with ownership after each move, got %q", text)
+	}
+
+	for _, args := range []map[string]interface{}{{"format": "xml"}, {"sortBy": "prior"}} {
+		_, err := call(args)
+		if code := apperrors.CodeOf(err); code != apperrors.CodeInvalidArgument {
+			t.Errorf("Expected %s for %v, got %s (%v)", apperrors.CodeInvalidArgument, args, code, err)
+		}
+	}
+}
+
 func TestPositionObjectParsing(t *testing.T) {
 	// Test that position objects are correctly parsed
 	positionData := map[string]interface{}{
@@ -267,3 +2160,319 @@ func TestPositionObjectParsing(t *testing.T) {
 		t.Errorf("Unexpected first move: %+v", position.Moves[0])
 	}
 }
+
+func TestEngineRecordingTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) (string, error) {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "engineRecording", Arguments: args}}
+		result, err := handler.HandleEngineRecording(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text != "Engine recording is not enabled" {
+		t.Errorf("Unexpected result: %s", text)
+	}
+
+	engine.SetRecording([]katago.RecordedLine{
+		{Direction: katago.DirectionQuery, Data: json.RawMessage(`{"id":"q1","action":"query_version"}`)},
+		{Direction: katago.DirectionResponse, Data: json.RawMessage(`{"id":"q1","version":"1.15.3"}`)},
+	})
+	text, err = call(map[string]interface{}{"limit": 1.0})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lines, err := katago.ReadRecording(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("Expected a recording: %v", err)
+	}
+	if len(lines) != 1 || lines[0].Direction != katago.DirectionResponse {
+		t.Errorf("Expected the last line, got %+v", lines)
+	}
+
+	if _, err := call(map[string]interface{}{"limit": 0.0}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for a zero limit, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestGetDiagnosticsTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) (string, error) {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "getDiagnostics", Arguments: args}}
+		result, err := handler.HandleGetDiagnostics(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text != "No engine crash has been diagnosed since the server started" {
+		t.Errorf("Unexpected result: %s", text)
+	}
+
+	engine.SetRecording([]katago.RecordedLine{
+		{Direction: katago.DirectionQuery, Data: json.RawMessage(`{"id":"q1","action":"query_version"}`)},
+	})
+	engine.CollectDiagnostics("health check failed: timeout")
+	text, err = call(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"- **Reason**: health check failed: timeout",
+		"## Last queries (1)\n\n```jsonl\n{",
+		`"action":"query_version"`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+
+	text, err = call(map[string]interface{}{"format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var bundle katago.DiagnosticsBundle
+	if err := json.Unmarshal([]byte(text), &bundle); err != nil || bundle.Reason != "health check failed: timeout" {
+		t.Errorf("Unexpected JSON bundle: %+v (%v)", bundle, err)
+	}
+}
+
+func TestGetSlowQueriesTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	handler := NewToolsHandler(engine, logger)
+
+	call := func(args map[string]interface{}) (string, error) {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "getSlowQueries", Arguments: args}}
+		result, err := handler.HandleGetSlowQueries(context.Background(), req)
+		if err != nil {
+			return "", err
+		}
+		return result.Content[0].(mcp.TextContent).Text, nil
+	}
+
+	text, err := call(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text != "The slow query log is not enabled" {
+		t.Errorf("Unexpected result: %s", text)
+	}
+
+	engine.SetSlowQueries([]katago.SlowQuery{
+		{ID: "q1", Lane: "interactive", Outcome: katago.SlowQueryOK, TotalSeconds: 4},
+		{
+			ID: "q2", Lane: "batch", Outcome: katago.SlowQueryTimeout, Position: "(;GM[1]FF[4]SZ[9];B[ee])",
+			Settings:     map[string]interface{}{"maxVisits": 1000.0, "includeOwnership": true},
+			QueueSeconds: 1.5, EngineSeconds: 30, TotalSeconds: 31.5,
+		},
+	})
+	text, err = call(map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"2 slow queries, most recent first.",
+		"## q2 at ",
+		": 31.5s, timeout",
+		"- **Timing**: 1.500s queued, 30.000s in KataGo",
+		"- **Settings**: includeOwnership=true, maxVisits=1000",
+		"- **Position**: `(;GM[1]FF[4]SZ[9];B[ee])`",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+	if strings.Index(text, "## q2") > strings.Index(text, "## q1") {
+		t.Errorf("Expected the most recent query first: %s", text)
+	}
+
+	text, err = call(map[string]interface{}{"limit": 1.0, "format": "json"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var queries []katago.SlowQuery
+	if err := json.Unmarshal([]byte(text), &queries); err != nil || len(queries) != 1 || queries[0].ID != "q2" {
+		t.Errorf("Expected the last query as JSON, got %+v (%v)", queries, err)
+	}
+
+	engine.SetSlowQueries([]katago.SlowQuery{})
+	if text, _ := call(map[string]interface{}{}); !strings.Contains(text, "No query has been slow") {
+		t.Errorf("Unexpected result: %s", text)
+	}
+	if _, err := call(map[string]interface{}{"format": "xml"}); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected %s for an unknown format, got %v", apperrors.CodeInvalidArgument, err)
+	}
+}
+
+func TestClearCacheTool(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	handler := NewToolsHandler(engine, logger)
+
+	ctx := context.Background()
+	req := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name: "clearCache",
+		},
+	}
+
+	// Without a cache manager the tool reports that caching is disabled
+	result, err := handler.HandleClearCache(ctx, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "Cache is not enabled" {
+		t.Errorf("Unexpected result: %s", text)
+	}
+
+	cacheManager := cache.NewManager(&config.CacheConfig{
+		Enabled:  true,
+		MaxItems: 10,
+	}, logger)
+	cacheManager.Put("key1", "value1", 10)
+	cacheManager.Put("key2", "value2", 20)
+	handler.SetCache(cacheManager)
+
+	result, err = handler.HandleClearCache(ctx, req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "Cleared 2 cached entries (30 bytes)" {
+		t.Errorf("Unexpected result: %s", text)
+	}
+	if cacheManager.Stats().Items != 0 {
+		t.Errorf("Expected empty cache, got %d items", cacheManager.Stats().Items)
+	}
+}
+
+func TestAdminToolsUnderStrictIsolation(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	handler := NewToolsHandler(katago.NewMockEngine(), logger)
+	handler.SetNamespaces(&config.NamespaceConfig{Isolation: config.IsolationStrict, Admins: []string{"ops"}})
+	cacheManager := cache.NewManager(&config.CacheConfig{Enabled: true, MaxItems: 10}, logger)
+	handler.SetCache(cacheManager)
+
+	caller := func(clientID string) context.Context {
+		ctx := WithClientID(context.Background(), clientID)
+		return katago.WithCacheNamespace(ctx, clientID)
+	}
+	cacheManager.Put(katago.CacheNamespacePrefix("alice")+"key", "alice's analysis", 10)
+	cacheManager.Put(katago.CacheNamespacePrefix("bob")+"key", "bob's analysis", 10)
+
+	// A tenant clears only its own namespace
+	result, err := handler.HandleClearCache(caller("alice"), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; text != "Cleared 1 cached entries of namespace alice" {
+		t.Errorf("Unexpected result: %s", text)
+	}
+	if _, ok := cacheManager.Get(katago.CacheNamespacePrefix("bob") + "key"); !ok {
+		t.Error("Expected another namespace's results to survive")
+	}
+
+	// Nor can it read the engine's raw queries
+	for name, tool := range map[string]ToolHandler{
+		"engineRecording": handler.HandleEngineRecording,
+		"getDiagnostics":  handler.HandleGetDiagnostics,
+		"getSlowQueries":  handler.HandleGetSlowQueries,
+	} {
+		if _, err := tool(caller("alice"), mcp.CallToolRequest{}); apperrors.CodeOf(err) != apperrors.CodePermissionDenied {
+			t.Errorf("%s: expected %s for a tenant, got %v", name, apperrors.CodePermissionDenied, err)
+		}
+		if _, err := tool(caller("ops"), mcp.CallToolRequest{}); err != nil {
+			t.Errorf("%s: unexpected error for an admin: %v", name, err)
+		}
+	}
+
+	// An admin clears everything
+	if _, err := handler.HandleClearCache(caller("ops"), mcp.CallToolRequest{}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cacheManager.Stats().Items != 0 {
+		t.Errorf("Expected empty cache, got %d items", cacheManager.Stats().Items)
+	}
+
+	// With shared isolation and admins listed, other clients may not clear
+	handler.SetNamespaces(&config.NamespaceConfig{Isolation: config.IsolationShared, Admins: []string{"ops"}})
+	if _, err := handler.HandleClearCache(WithClientID(context.Background(), "alice"), mcp.CallToolRequest{}); apperrors.CodeOf(err) != apperrors.CodePermissionDenied {
+		t.Errorf("Expected %s for a non-admin, got %v", apperrors.CodePermissionDenied, err)
+	}
+}
+
+func TestFormatGameReviewNotes(t *testing.T) {
+	winrate, lead := 0.56, 2.5
+	review := &katago.GameReview{
+		Summary: katago.ReviewSummary{TotalMoves: 40},
+		Notes: []katago.SGFNote{
+			{MoveNumber: 23, Color: "B", PlayedMove: "C3", Comment: "Too slow,\nplay elsewhere",
+				BestMove: "Q4", Opinion: "KataGo prefers Q4 by 2.0 points (5.0% win rate)"},
+			{MoveNumber: 23, Color: "B", PlayedMove: "C3", Comment: "Take the corner", Variation: []string{"R4", "Q3"},
+				BestMove: "Q4", Opinion: "KataGo prefers Q4 by 1.2 points (3.1% win rate)",
+				VariationWinrate: &winrate, VariationScoreLead: &lead},
+		},
+	}
+
+	text := formatGameReview(i18n.English, review)
+	for _, want := range []string{
+		"## Existing Annotations\n\n### Move 23 (B)\n- **Comment**: Too slow, play elsewhere\n- **Played**: C3\n" +
+			"- KataGo prefers Q4 by 2.0 points (5.0% win rate)\n",
+		"- **Variation**: R4 Q3\n- The SGF suggests R4; KataGo prefers Q4 by 1.2 points (3.1% win rate)\n" +
+			"- **After the variation**: Black win rate 56.0%, score lead +2.5\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in output, got %q", want, text)
+		}
+	}
+}
+
+func TestFormatGameReviewFindings(t *testing.T) {
+	review := &katago.GameReview{
+		Summary:  katago.ReviewSummary{TotalMoves: 40},
+		Findings: []katago.Finding{{Analyzer: "shapes", MoveNumber: 12, Color: "W", Move: "D5", Category: "empty triangle", Explanation: "Three stones, no liberties gained"}},
+	}
+	want := "## Analyzer Findings\n\n### Move 12 (W)\n- **Category**: empty triangle (shapes)\n- Three stones, no liberties gained\n"
+	if text := formatGameReview(i18n.English, review); !strings.Contains(text, want) {
+		t.Errorf("Expected %q in output, got %q", want, text)
+	}
+}
+
+func TestBoardSize(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	handler := NewToolsHandler(katago.NewMockEngine(), logger)
+
+	for _, tt := range []struct {
+		args map[string]interface{}
+		want int
+	}{
+		{map[string]interface{}{"sgf": "(;GM[1]SZ[13];B[dd])"}, 13},
+		{map[string]interface{}{"sgfs": []interface{}{"(;SZ[9])", "(;SZ[19])"}}, 9},
+		{map[string]interface{}{"position": map[string]interface{}{"boardXSize": 9.0}}, 9},
+		{map[string]interface{}{"board": ". . .\n. X .\n. . O"}, 3},
+		{map[string]interface{}{"sgf": "not an SGF"}, 0},
+		{map[string]interface{}{"sessionId": "missing"}, 0},
+	} {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.args}}
+		if got := handler.BoardSize(context.Background(), req); got != tt.want {
+			t.Errorf("%v: expected %d, got %d", tt.args, tt.want, got)
+		}
+	}
+}
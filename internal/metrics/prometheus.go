@@ -1,33 +1,51 @@
 package metrics
 
 import (
+	"net/http"
 	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-var (
-	prometheusOnce     sync.Once
-	prometheusInstance *PrometheusCollector
-)
+// OtherClient labels per-client metrics of clients not named in
+// SetClients.
+const OtherClient = "other"
 
 // PrometheusCollector provides Prometheus metrics for the KataGo MCP server.
+// Each server creates its own and passes it to the components it records
+// metrics for.
 type PrometheusCollector struct {
+	gatherer prometheus.Gatherer
+
+	// Clients labeled by name in per-client metrics
+	clientsMu sync.RWMutex
+	clients   map[string]bool
+
 	// MCP Tool metrics
 	toolCallsTotal   *prometheus.CounterVec
 	toolErrorsTotal  *prometheus.CounterVec
 	toolDurationSecs *prometheus.HistogramVec
+	toolRetriesTotal *prometheus.CounterVec
+	clientCallsTotal *prometheus.CounterVec
 
 	// Rate limit metrics
 	rateLimitHitsTotal   *prometheus.CounterVec
 	rateLimitChecksTotal prometheus.Counter
 
+	// Quota metrics
+	clientVisitsTotal  *prometheus.CounterVec
+	quotaExceededTotal *prometheus.CounterVec
+
 	// KataGo engine metrics
 	engineStatus        *prometheus.GaugeVec
-	engineRestartsTotal prometheus.Counter
+	engineRestartsTotal *prometheus.CounterVec
 	engineHealthChecks  *prometheus.CounterVec
 	engineQueryDuration *prometheus.HistogramVec
+	engineLaneWait      *prometheus.HistogramVec
+	engineSchemaIssues  *prometheus.CounterVec
+	engineOrphans       *prometheus.CounterVec
 
 	// HTTP metrics
 	httpRequestsTotal   *prometheus.CounterVec
@@ -42,186 +60,419 @@ type PrometheusCollector struct {
 	cacheMissesTotal prometheus.Counter
 	cacheSize        prometheus.Gauge
 	cacheItems       prometheus.Gauge
+	// Bytes sent to and read from the shared cache backend, and the totals
+	// last reported so the counters advance by the difference
+	cacheBackendRawBytes    prometheus.Counter
+	cacheBackendStoredBytes prometheus.Counter
+	cacheBackendMu          sync.Mutex
+	cacheBackendLastRaw     float64
+	cacheBackendLastStored  float64
+
+	// Engine process resource metrics
+	engineCPUPercent     prometheus.Gauge
+	engineRSSBytes       prometheus.Gauge
+	engineGPUPercent     prometheus.Gauge
+	engineGPUMemoryBytes prometheus.Gauge
+
+	// Study session metrics
+	sessionsOpen         prometheus.Gauge
+	sessionsCreatedTotal prometheus.Counter
+	sessionsClosedTotal  *prometheus.CounterVec
 }
 
-// NewPrometheusCollector creates a new Prometheus metrics collector (singleton).
-func NewPrometheusCollector() *PrometheusCollector {
-	prometheusOnce.Do(func() {
-		prometheusInstance = &PrometheusCollector{
-			// MCP Tool metrics
-			toolCallsTotal: promauto.NewCounterVec(
-				prometheus.CounterOpts{
-					Name: "katago_mcp_tool_calls_total",
-					Help: "Total number of MCP tool calls",
-				},
-				[]string{"tool", "status"},
-			),
-			toolErrorsTotal: promauto.NewCounterVec(
-				prometheus.CounterOpts{
-					Name: "katago_mcp_tool_errors_total",
-					Help: "Total number of MCP tool errors",
-				},
-				[]string{"tool", "error_type"},
-			),
-			toolDurationSecs: promauto.NewHistogramVec(
-				prometheus.HistogramOpts{
-					Name:    "katago_mcp_tool_duration_seconds",
-					Help:    "Duration of MCP tool calls in seconds",
-					Buckets: prometheus.DefBuckets,
-				},
-				[]string{"tool"},
-			),
-
-			// Rate limit metrics
-			rateLimitHitsTotal: promauto.NewCounterVec(
-				prometheus.CounterOpts{
-					Name: "katago_mcp_rate_limit_hits_total",
-					Help: "Total number of rate limit hits",
-				},
-				[]string{"client", "tool"},
-			),
-			rateLimitChecksTotal: promauto.NewCounter(
-				prometheus.CounterOpts{
-					Name: "katago_mcp_rate_limit_checks_total",
-					Help: "Total number of rate limit checks",
-				},
-			),
-
-			// KataGo engine metrics
-			engineStatus: promauto.NewGaugeVec(
-				prometheus.GaugeOpts{
-					Name: "katago_engine_status",
-					Help: "Status of the KataGo engine (1=running, 0=stopped)",
-				},
-				[]string{"version"},
-			),
-			engineRestartsTotal: promauto.NewCounter(
-				prometheus.CounterOpts{
-					Name: "katago_engine_restarts_total",
-					Help: "Total number of KataGo engine restarts",
-				},
-			),
-			engineHealthChecks: promauto.NewCounterVec(
-				prometheus.CounterOpts{
-					Name: "katago_engine_health_checks_total",
-					Help: "Total number of KataGo engine health checks",
-				},
-				[]string{"status"},
-			),
-			engineQueryDuration: promauto.NewHistogramVec(
-				prometheus.HistogramOpts{
-					Name:    "katago_engine_query_duration_seconds",
-					Help:    "Duration of KataGo engine queries in seconds",
-					Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10},
-				},
-				[]string{"query_type"},
-			),
-
-			// HTTP metrics
-			httpRequestsTotal: promauto.NewCounterVec(
-				prometheus.CounterOpts{
-					Name: "katago_mcp_http_requests_total",
-					Help: "Total number of HTTP requests",
-				},
-				[]string{"method", "path", "status"},
-			),
-			httpRequestDuration: promauto.NewHistogramVec(
-				prometheus.HistogramOpts{
-					Name:    "katago_mcp_http_request_duration_seconds",
-					Help:    "Duration of HTTP requests in seconds",
-					Buckets: prometheus.DefBuckets,
-				},
-				[]string{"method", "path"},
-			),
-
-			// Resource metrics
-			activeClients: promauto.NewGauge(
-				prometheus.GaugeOpts{
-					Name: "katago_mcp_active_clients",
-					Help: "Number of active MCP clients",
-				},
-			),
-			activeConnections: promauto.NewGauge(
-				prometheus.GaugeOpts{
-					Name: "katago_mcp_active_connections",
-					Help: "Number of active connections",
-				},
-			),
-
-			// Cache metrics
-			cacheHitsTotal: promauto.NewCounter(
-				prometheus.CounterOpts{
-					Name: "katago_mcp_cache_hits_total",
-					Help: "Total number of cache hits",
-				},
-			),
-			cacheMissesTotal: promauto.NewCounter(
-				prometheus.CounterOpts{
-					Name: "katago_mcp_cache_misses_total",
-					Help: "Total number of cache misses",
-				},
-			),
-			cacheSize: promauto.NewGauge(
-				prometheus.GaugeOpts{
-					Name: "katago_mcp_cache_size_bytes",
-					Help: "Current cache size in bytes",
-				},
-			),
-			cacheItems: promauto.NewGauge(
-				prometheus.GaugeOpts{
-					Name: "katago_mcp_cache_items",
-					Help: "Current number of items in cache",
-				},
-			),
-		}
-	})
-	return prometheusInstance
+// NewPrometheusCollector creates a Prometheus metrics collector whose
+// metrics are registered with registerer, such as
+// prometheus.DefaultRegisterer or a registry of the collector's own. With a
+// nil registerer the metrics are not registered anywhere, which suits
+// tests. A registerer takes only one collector: registering a second panics.
+func NewPrometheusCollector(registerer prometheus.Registerer) *PrometheusCollector {
+	factory := promauto.With(registerer)
+	gatherer, ok := registerer.(prometheus.Gatherer)
+	if !ok {
+		gatherer = prometheus.NewRegistry()
+	}
+	return &PrometheusCollector{
+		gatherer: gatherer,
+
+		// MCP Tool metrics
+		toolCallsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_tool_calls_total",
+				Help: "Total number of MCP tool calls",
+			},
+			[]string{"tool", "status"},
+		),
+		toolErrorsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_tool_errors_total",
+				Help: "Total number of MCP tool errors",
+			},
+			[]string{"tool", "error_type"},
+		),
+		toolDurationSecs: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "katago_mcp_tool_duration_seconds",
+				Help:    "Duration of MCP tool calls in seconds",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"tool"},
+		),
+
+		toolRetriesTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_tool_retries_total",
+				Help: "Total number of retried MCP tool calls",
+			},
+			[]string{"tool", "error_type"},
+		),
+		clientCallsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_client_tool_calls_total",
+				Help: "Total number of MCP tool calls by client",
+			},
+			[]string{"client", "tool"},
+		),
+
+		// Rate limit metrics
+		rateLimitHitsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_rate_limit_hits_total",
+				Help: "Total number of rate limit hits",
+			},
+			[]string{"client", "tool"},
+		),
+		rateLimitChecksTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_rate_limit_checks_total",
+				Help: "Total number of rate limit checks",
+			},
+		),
+
+		// Quota metrics
+		clientVisitsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_client_visits_total",
+				Help: "Total number of KataGo visits charged to each client's quota",
+			},
+			[]string{"client"},
+		),
+		quotaExceededTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_quota_exceeded_total",
+				Help: "Total number of tool calls rejected because the client's quota was used up",
+			},
+			[]string{"client", "period"},
+		),
+
+		// KataGo engine metrics
+		engineStatus: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "katago_engine_status",
+				Help: "Status of the KataGo engine (1=running, 0=stopped)",
+			},
+			[]string{"engine", "version"},
+		),
+		engineRestartsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_engine_restarts_total",
+				Help: "Total number of KataGo engine restarts",
+			},
+			[]string{"engine"},
+		),
+		engineHealthChecks: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_engine_health_checks_total",
+				Help: "Total number of KataGo engine health checks",
+			},
+			[]string{"engine", "status"},
+		),
+		engineQueryDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "katago_engine_query_duration_seconds",
+				Help:    "Duration of KataGo engine queries in seconds",
+				Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+			},
+			[]string{"engine", "query_type"},
+		),
+		engineLaneWait: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "katago_engine_lane_wait_seconds",
+				Help:    "Time KataGo queries waited for a slot, by scheduling lane",
+				Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 2.5, 5, 10, 30},
+			},
+			[]string{"engine", "lane"},
+		),
+		engineSchemaIssues: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_engine_response_schema_issues_total",
+				Help: "Total number of KataGo response fields that are unknown or malformed",
+			},
+			[]string{"engine", "issue", "field"},
+		),
+		engineOrphans: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_engine_orphan_responses_total",
+				Help: "Total number of KataGo responses that reached no waiting query",
+			},
+			[]string{"engine", "reason"},
+		),
+
+		// HTTP metrics
+		httpRequestsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_http_requests_total",
+				Help: "Total number of HTTP requests",
+			},
+			[]string{"method", "path", "status"},
+		),
+		httpRequestDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "katago_mcp_http_request_duration_seconds",
+				Help:    "Duration of HTTP requests in seconds",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"method", "path"},
+		),
+
+		// Resource metrics
+		activeClients: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "katago_mcp_active_clients",
+				Help: "Number of active MCP clients",
+			},
+		),
+		activeConnections: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "katago_mcp_active_connections",
+				Help: "Number of active connections",
+			},
+		),
+
+		// Cache metrics
+		cacheHitsTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_cache_hits_total",
+				Help: "Total number of cache hits",
+			},
+		),
+		cacheMissesTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_cache_misses_total",
+				Help: "Total number of cache misses",
+			},
+		),
+		cacheSize: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "katago_mcp_cache_size_bytes",
+				Help: "Current cache size in bytes",
+			},
+		),
+		cacheItems: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "katago_mcp_cache_items",
+				Help: "Current number of items in cache",
+			},
+		),
+		cacheBackendRawBytes: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_cache_backend_uncompressed_bytes_total",
+				Help: "Total bytes of cache entries read from and written to the shared backend, before compression",
+			},
+		),
+		cacheBackendStoredBytes: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_cache_backend_compressed_bytes_total",
+				Help: "Total bytes of cache entries read from and written to the shared backend, as transferred",
+			},
+		),
+
+		// Engine process resource metrics
+		engineCPUPercent: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "katago_mcp_engine_cpu_percent",
+				Help: "CPU utilization of the KataGo process in percent of one core",
+			},
+		),
+		engineRSSBytes: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "katago_mcp_engine_rss_bytes",
+				Help: "Resident memory of the KataGo process in bytes",
+			},
+		),
+		engineGPUPercent: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "katago_mcp_engine_gpu_utilization_percent",
+				Help: "GPU utilization while KataGo is running in percent",
+			},
+		),
+		engineGPUMemoryBytes: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "katago_mcp_engine_gpu_memory_bytes",
+				Help: "GPU memory used by the KataGo process in bytes",
+			},
+		),
+
+		// Study session metrics
+		sessionsOpen: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "katago_mcp_sessions_open",
+				Help: "Current number of open study sessions",
+			},
+		),
+		sessionsCreatedTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_sessions_created_total",
+				Help: "Total number of study sessions created",
+			},
+		),
+		sessionsClosedTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "katago_mcp_sessions_closed_total",
+				Help: "Total number of study sessions closed",
+			},
+			[]string{"reason"},
+		),
+	}
 }
 
 // RecordToolCall records a tool call metric.
 func (p *PrometheusCollector) RecordToolCall(tool, status string, durationSecs float64) {
 	p.toolCallsTotal.WithLabelValues(tool, status).Inc()
 	p.toolDurationSecs.WithLabelValues(tool).Observe(durationSecs)
+}
+
+// SetClients sets the client IDs labeled by name in per-client metrics.
+// Client IDs are chosen by callers, so every other client is labeled
+// OtherClient to keep the number of series bounded.
+func (p *PrometheusCollector) SetClients(clients []string) {
+	set := make(map[string]bool, len(clients))
+	for _, client := range clients {
+		set[client] = true
+	}
+	p.clientsMu.Lock()
+	p.clients = set
+	p.clientsMu.Unlock()
+}
 
-	if status == "error" {
-		p.toolErrorsTotal.WithLabelValues(tool, "general").Inc()
+// clientLabel returns the label value of a client in per-client metrics.
+func (p *PrometheusCollector) clientLabel(client string) string {
+	p.clientsMu.RLock()
+	defer p.clientsMu.RUnlock()
+	if p.clients[client] {
+		return client
 	}
+	return OtherClient
+}
+
+// RecordClientToolCall records a tool call by a client.
+func (p *PrometheusCollector) RecordClientToolCall(client, tool string) {
+	p.clientCallsTotal.WithLabelValues(p.clientLabel(client), tool).Inc()
+}
+
+// RecordToolError records a tool error labeled with its error code.
+func (p *PrometheusCollector) RecordToolError(tool, errorType string) {
+	p.toolErrorsTotal.WithLabelValues(tool, errorType).Inc()
+}
+
+// RecordToolRetry records a retried tool call labeled with the error code
+// that triggered the retry.
+func (p *PrometheusCollector) RecordToolRetry(tool, errorType string) {
+	p.toolRetriesTotal.WithLabelValues(tool, errorType).Inc()
 }
 
 // RecordRateLimit records a rate limit event.
 func (p *PrometheusCollector) RecordRateLimit(client, tool string, hit bool) {
 	p.rateLimitChecksTotal.Inc()
 	if hit {
-		p.rateLimitHitsTotal.WithLabelValues(client, tool).Inc()
+		p.rateLimitHitsTotal.WithLabelValues(p.clientLabel(client), tool).Inc()
 	}
 }
 
+// RecordClientVisits records KataGo visits charged to a client's quota.
+func (p *PrometheusCollector) RecordClientVisits(client string, visits int) {
+	p.clientVisitsTotal.WithLabelValues(p.clientLabel(client)).Add(float64(visits))
+}
+
+// RecordQuotaExceeded records a call rejected because the client used up
+// its quota for the period.
+func (p *PrometheusCollector) RecordQuotaExceeded(client, period string) {
+	p.quotaExceededTotal.WithLabelValues(p.clientLabel(client), period).Inc()
+}
+
+// Handler serves the collector's metrics, and everything else registered
+// with its registerer. It serves nothing when the registerer cannot be
+// gathered from, such as a nil or wrapped one.
+func (p *PrometheusCollector) Handler() http.Handler {
+	return promhttp.HandlerFor(p.gatherer, promhttp.HandlerOpts{})
+}
+
+// EngineMetrics records the metrics of one KataGo engine, labeled with its
+// name so that the engines of a pool are told apart.
+type EngineMetrics struct {
+	p      *PrometheusCollector
+	engine string
+}
+
+// Engine returns the metrics of the named engine.
+func (p *PrometheusCollector) Engine(name string) *EngineMetrics {
+	return &EngineMetrics{p: p, engine: name}
+}
+
 // RecordEngineStatus records the current engine status.
-func (p *PrometheusCollector) RecordEngineStatus(running bool, version string) {
+func (m *EngineMetrics) RecordEngineStatus(running bool, version string) {
 	value := 0.0
 	if running {
 		value = 1.0
 	}
-	p.engineStatus.WithLabelValues(version).Set(value)
+	m.p.engineStatus.WithLabelValues(m.engine, version).Set(value)
 }
 
 // RecordEngineRestart records an engine restart.
-func (p *PrometheusCollector) RecordEngineRestart() {
-	p.engineRestartsTotal.Inc()
+func (m *EngineMetrics) RecordEngineRestart() {
+	m.p.engineRestartsTotal.WithLabelValues(m.engine).Inc()
 }
 
 // RecordEngineHealthCheck records a health check result.
-func (p *PrometheusCollector) RecordEngineHealthCheck(success bool) {
+func (m *EngineMetrics) RecordEngineHealthCheck(success bool) {
 	status := "success"
 	if !success {
 		status = "failure"
 	}
-	p.engineHealthChecks.WithLabelValues(status).Inc()
+	m.p.engineHealthChecks.WithLabelValues(m.engine, status).Inc()
 }
 
 // RecordEngineQuery records an engine query duration.
-func (p *PrometheusCollector) RecordEngineQuery(queryType string, durationSecs float64) {
-	p.engineQueryDuration.WithLabelValues(queryType).Observe(durationSecs)
+func (m *EngineMetrics) RecordEngineQuery(queryType string, durationSecs float64) {
+	m.p.engineQueryDuration.WithLabelValues(m.engine, queryType).Observe(durationSecs)
+}
+
+// RecordLaneWait records how long a query waited for a slot in its
+// scheduling lane.
+func (m *EngineMetrics) RecordLaneWait(lane string, waitSecs float64) {
+	m.p.engineLaneWait.WithLabelValues(m.engine, lane).Observe(waitSecs)
+}
+
+// RecordResponseSchemaIssue records a KataGo response field that differs
+// from the expected format.
+func (m *EngineMetrics) RecordResponseSchemaIssue(issue, field string) {
+	m.p.engineSchemaIssues.WithLabelValues(m.engine, issue, field).Inc()
+}
+
+// RecordOrphanResponse records a KataGo response that reached no waiting
+// query, labeled with why.
+func (m *EngineMetrics) RecordOrphanResponse(reason string) {
+	m.p.engineOrphans.WithLabelValues(m.engine, reason).Inc()
+}
+
+// RecordCacheHit records a cache hit, which is not labeled with the engine
+// since the engines share the cache.
+func (m *EngineMetrics) RecordCacheHit() {
+	m.p.RecordCacheHit()
+}
+
+// RecordCacheMiss records a cache miss.
+func (m *EngineMetrics) RecordCacheMiss() {
+	m.p.RecordCacheMiss()
 }
 
 // RecordHTTPRequest records an HTTP request.
@@ -255,3 +506,41 @@ func (p *PrometheusCollector) SetCacheStats(items, sizeBytes float64) {
 	p.cacheItems.Set(items)
 	p.cacheSize.Set(sizeBytes)
 }
+
+// SetCacheBackendBytes sets the running totals of bytes exchanged with the
+// shared cache backend, before compression and as transferred.
+func (p *PrometheusCollector) SetCacheBackendBytes(rawBytes, storedBytes float64) {
+	p.cacheBackendMu.Lock()
+	defer p.cacheBackendMu.Unlock()
+	if rawBytes > p.cacheBackendLastRaw {
+		p.cacheBackendRawBytes.Add(rawBytes - p.cacheBackendLastRaw)
+		p.cacheBackendLastRaw = rawBytes
+	}
+	if storedBytes > p.cacheBackendLastStored {
+		p.cacheBackendStoredBytes.Add(storedBytes - p.cacheBackendLastStored)
+		p.cacheBackendLastStored = storedBytes
+	}
+}
+
+// SetEngineResources sets the current resource usage of the KataGo process.
+func (p *PrometheusCollector) SetEngineResources(cpuPercent, rssBytes, gpuPercent, gpuMemoryBytes float64) {
+	p.engineCPUPercent.Set(cpuPercent)
+	p.engineRSSBytes.Set(rssBytes)
+	p.engineGPUPercent.Set(gpuPercent)
+	p.engineGPUMemoryBytes.Set(gpuMemoryBytes)
+}
+
+// SetOpenSessions sets the current number of open study sessions.
+func (p *PrometheusCollector) SetOpenSessions(count float64) {
+	p.sessionsOpen.Set(count)
+}
+
+// RecordSessionCreated records a new study session.
+func (p *PrometheusCollector) RecordSessionCreated() {
+	p.sessionsCreatedTotal.Inc()
+}
+
+// RecordSessionClosed records a closed study session and why it closed.
+func (p *PrometheusCollector) RecordSessionClosed(reason string) {
+	p.sessionsClosedTotal.WithLabelValues(reason).Inc()
+}
@@ -1,29 +1,46 @@
 package metrics
 
 import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestPrometheusCollector(t *testing.T) {
-	collector := NewPrometheusCollector()
+	collector := NewPrometheusCollector(prometheus.NewRegistry())
 
 	// Test tool metrics
 	collector.RecordToolCall("analyzePosition", "success", 0.5)
 	collector.RecordToolCall("analyzePosition", "error", 0.1)
 	collector.RecordToolCall("findMistakes", "success", 2.5)
+	collector.RecordClientToolCall("claude-desktop/0.9.2", "analyzePosition")
+	collector.RecordToolError("analyzePosition", "TIMEOUT")
 
 	// Test rate limit metrics
 	collector.RecordRateLimit("client1", "analyzePosition", false)
 	collector.RecordRateLimit("client1", "analyzePosition", true)
 	collector.RecordRateLimit("client2", "findMistakes", true)
 
+	// Test quota metrics
+	collector.RecordClientVisits("client1", 500)
+	collector.RecordQuotaExceeded("client1", "day")
+
 	// Test engine metrics
-	collector.RecordEngineStatus(true, "1.14.0")
-	collector.RecordEngineHealthCheck(true)
-	collector.RecordEngineHealthCheck(false)
-	collector.RecordEngineQuery("query", 1.5)
-	collector.RecordEngineRestart()
+	engine := collector.Engine("default")
+	engine.RecordEngineStatus(true, "1.14.0")
+	engine.RecordEngineHealthCheck(true)
+	engine.RecordEngineHealthCheck(false)
+	engine.RecordEngineQuery("query", 1.5)
+	engine.RecordLaneWait("interactive", 0.02)
+	engine.RecordEngineRestart()
+	engine.RecordResponseSchemaIssue("unknown_field", "rootInfo.newField")
+	engine.RecordOrphanResponse("late")
+	engine.RecordCacheHit()
 
 	// Test HTTP metrics
 	collector.RecordHTTPRequest("GET", "/health", "200", 0.01)
@@ -39,3 +56,79 @@ func TestPrometheusCollector(t *testing.T) {
 	// If we get here without panic, the test passes
 	// In a real test, we would query the metrics and verify values
 }
+
+func TestPrometheusCollectorInstances(t *testing.T) {
+	scrape := func(c *PrometheusCollector) string {
+		rec := httptest.NewRecorder()
+		c.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		body, _ := io.ReadAll(rec.Body)
+		return string(body)
+	}
+
+	// Two servers in one process keep their metrics apart
+	first := NewPrometheusCollector(prometheus.NewRegistry())
+	second := NewPrometheusCollector(prometheus.NewRegistry())
+	first.Engine("fast").RecordEngineRestart()
+	first.Engine("strong").RecordEngineQuery("analysis", 2)
+	second.RecordToolCall("analyzePosition", "success", 0.5)
+
+	metrics := scrape(first)
+	if !strings.Contains(metrics, `katago_engine_restarts_total{engine="fast"} 1`) ||
+		!strings.Contains(metrics, `katago_engine_query_duration_seconds_count{engine="strong",query_type="analysis"} 1`) {
+		t.Errorf("Expected the engines' metrics labeled with their names, got:\n%s", metrics)
+	}
+	if strings.Contains(metrics, "katago_mcp_tool_calls_total") {
+		t.Errorf("Expected the other server's tool calls not to be shared, got:\n%s", metrics)
+	}
+	if !strings.Contains(scrape(second), `katago_mcp_tool_calls_total{status="success",tool="analyzePosition"} 1`) {
+		t.Errorf("Expected the second server's tool call, got:\n%s", scrape(second))
+	}
+
+	// Without a registerer nothing is registered or served
+	unregistered := NewPrometheusCollector(nil)
+	unregistered.RecordToolCall("analyzePosition", "success", 0.5)
+	if metrics := scrape(unregistered); metrics != "" {
+		t.Errorf("Expected no metrics served, got:\n%s", metrics)
+	}
+}
+
+func TestPrometheusCollectorClientLabels(t *testing.T) {
+	collector := NewPrometheusCollector(prometheus.NewRegistry())
+	collector.SetClients([]string{"team-a"})
+
+	// Unlisted clients share one label however many there are
+	collector.RecordClientToolCall("team-a", "analyzePosition")
+	for i := 0; i < 50; i++ {
+		collector.RecordClientToolCall(fmt.Sprintf("rotated-%d", i), "analyzePosition")
+		collector.RecordClientVisits(fmt.Sprintf("rotated-%d", i), 10)
+	}
+
+	rec := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	metrics := rec.Body.String()
+	if !strings.Contains(metrics, `katago_mcp_client_tool_calls_total{client="team-a",tool="analyzePosition"} 1`) ||
+		!strings.Contains(metrics, `katago_mcp_client_tool_calls_total{client="other",tool="analyzePosition"} 50`) ||
+		!strings.Contains(metrics, `katago_mcp_client_visits_total{client="other"} 500`) {
+		t.Errorf("Expected listed clients by name and the rest as other, got:\n%s", metrics)
+	}
+	if strings.Contains(metrics, "rotated-") {
+		t.Errorf("Expected no unlisted client labels, got:\n%s", metrics)
+	}
+}
+
+func TestPrometheusCollectorCacheBackendBytes(t *testing.T) {
+	collector := NewPrometheusCollector(prometheus.NewRegistry())
+
+	// Reported totals advance the counters by the difference
+	collector.SetCacheBackendBytes(4000, 500)
+	collector.SetCacheBackendBytes(4000, 500)
+	collector.SetCacheBackendBytes(10000, 1200)
+
+	rec := httptest.NewRecorder()
+	collector.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	metrics := rec.Body.String()
+	if !strings.Contains(metrics, "katago_mcp_cache_backend_uncompressed_bytes_total 10000") ||
+		!strings.Contains(metrics, "katago_mcp_cache_backend_compressed_bytes_total 1200") {
+		t.Errorf("Expected the cache backend byte totals, got:\n%s", metrics)
+	}
+}
@@ -0,0 +1,205 @@
+// Package monitor samples CPU, memory, and GPU usage of the KataGo process.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/health"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// Sample is a point-in-time measurement of the KataGo process.
+type Sample struct {
+	PID            int       `json:"pid"`
+	Timestamp      time.Time `json:"timestamp"`
+	CPUPercent     float64   `json:"cpuPercent"`
+	RSSBytes       int64     `json:"rssBytes"`
+	GPUAvailable   bool      `json:"gpuAvailable"`
+	GPUPercent     float64   `json:"gpuPercent,omitempty"`
+	GPUMemoryBytes int64     `json:"gpuMemoryBytes,omitempty"`
+}
+
+// procStats is raw process accounting read from the operating system.
+type procStats struct {
+	cpuTime  time.Duration
+	rssBytes int64
+}
+
+// gpuStats is GPU usage attributed to a process.
+type gpuStats struct {
+	utilPercent float64
+	memoryBytes int64
+}
+
+// Monitor periodically samples resource usage of the KataGo process.
+type Monitor struct {
+	config *config.MonitorConfig
+	logger logging.ContextLogger
+	pid    func() int
+
+	// Samplers, replaceable in tests
+	readProc func(pid int) (procStats, error)
+	queryGPU func(ctx context.Context, pid int) (gpuStats, error)
+
+	mu       sync.RWMutex
+	latest   Sample
+	lastPID  int
+	lastCPU  time.Duration
+	lastTime time.Time
+	reported bool
+
+	done chan struct{}
+	once sync.Once
+}
+
+// New creates a resource monitor for the process whose ID is returned by pid.
+func New(cfg *config.MonitorConfig, logger logging.ContextLogger, pid func() int) *Monitor {
+	m := &Monitor{
+		config:   cfg,
+		logger:   logger,
+		pid:      pid,
+		readProc: readProcStats,
+		done:     make(chan struct{}),
+	}
+	if cfg.GPU && nvidiaSMIAvailable() {
+		m.queryGPU = queryNvidiaSMI
+	}
+	return m
+}
+
+// Start samples resource usage every configured interval, passing each
+// sample to report, until Stop is called. It is a no-op when disabled.
+func (m *Monitor) Start(report func(Sample)) {
+	if !m.config.Enabled {
+		return
+	}
+
+	interval := time.Duration(m.config.IntervalSeconds) * time.Second
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			sample := m.Sample()
+			if report != nil {
+				report(sample)
+			}
+
+			select {
+			case <-m.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the sampling goroutine. It is safe to call more than once.
+func (m *Monitor) Stop() {
+	m.once.Do(func() { close(m.done) })
+}
+
+// Sample takes a new measurement and records it as the latest sample.
+func (m *Monitor) Sample() Sample {
+	now := time.Now()
+	sample := Sample{PID: m.pid(), Timestamp: now}
+	if sample.PID == 0 {
+		m.mu.Lock()
+		m.latest = sample
+		m.reported = true
+		m.mu.Unlock()
+		return sample
+	}
+
+	stats, err := m.readProc(sample.PID)
+	if err != nil {
+		m.logger.Debug("Failed to read process stats", "pid", sample.PID, "error", err)
+	}
+
+	var gpu gpuStats
+	if m.queryGPU != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		gpu, err = m.queryGPU(ctx, sample.PID)
+		cancel()
+		if err != nil {
+			m.logger.Debug("Failed to query GPU stats", "error", err)
+		} else {
+			sample.GPUAvailable = true
+			sample.GPUPercent = gpu.utilPercent
+			sample.GPUMemoryBytes = gpu.memoryBytes
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sample.RSSBytes = stats.rssBytes
+	// CPU utilization needs two samples from the same process
+	if m.lastPID == sample.PID && !m.lastTime.IsZero() && stats.cpuTime >= m.lastCPU {
+		if elapsed := now.Sub(m.lastTime); elapsed > 0 {
+			sample.CPUPercent = float64(stats.cpuTime-m.lastCPU) / float64(elapsed) * 100
+		}
+	}
+	m.lastPID = sample.PID
+	m.lastCPU = stats.cpuTime
+	m.lastTime = now
+	m.latest = sample
+	m.reported = true
+
+	return sample
+}
+
+// Latest returns the most recent sample and whether one has been taken.
+func (m *Monitor) Latest() (Sample, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.latest, m.reported
+}
+
+// GetStatus returns the latest sample as a map for health responses.
+func (m *Monitor) GetStatus() map[string]interface{} {
+	sample, ok := m.Latest()
+	status := map[string]interface{}{
+		"enabled": m.config.Enabled,
+	}
+	if !ok {
+		return status
+	}
+
+	status["pid"] = sample.PID
+	status["sampledAt"] = sample.Timestamp
+	status["cpuPercent"] = sample.CPUPercent
+	status["rssBytes"] = sample.RSSBytes
+	status["gpuAvailable"] = sample.GPUAvailable
+	if sample.GPUAvailable {
+		status["gpuPercent"] = sample.GPUPercent
+		status["gpuMemoryBytes"] = sample.GPUMemoryBytes
+	}
+	return status
+}
+
+// Check reports the engine as degraded when the latest sample exceeds a
+// configured threshold.
+func (m *Monitor) Check(ctx context.Context) error {
+	sample, ok := m.Latest()
+	if !ok || sample.PID == 0 {
+		return nil
+	}
+
+	cfg := m.config
+	switch {
+	case cfg.MaxCPUPercent > 0 && sample.CPUPercent > cfg.MaxCPUPercent:
+		return fmt.Errorf("%w: CPU usage %.1f%% exceeds %.1f%%", health.ErrDegraded, sample.CPUPercent, cfg.MaxCPUPercent)
+	case cfg.MaxRSSBytes > 0 && sample.RSSBytes > cfg.MaxRSSBytes:
+		return fmt.Errorf("%w: memory usage %d bytes exceeds %d bytes", health.ErrDegraded, sample.RSSBytes, cfg.MaxRSSBytes)
+	case sample.GPUAvailable && cfg.MaxGPUPercent > 0 && sample.GPUPercent > cfg.MaxGPUPercent:
+		return fmt.Errorf("%w: GPU usage %.1f%% exceeds %.1f%%", health.ErrDegraded, sample.GPUPercent, cfg.MaxGPUPercent)
+	case sample.GPUAvailable && cfg.MaxGPUMemoryBytes > 0 && sample.GPUMemoryBytes > cfg.MaxGPUMemoryBytes:
+		return fmt.Errorf("%w: GPU memory %d bytes exceeds %d bytes", health.ErrDegraded, sample.GPUMemoryBytes, cfg.MaxGPUMemoryBytes)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/health"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+func newTestMonitor(cfg *config.MonitorConfig, pid int) *Monitor {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	m := New(cfg, logger, func() int { return pid })
+	m.queryGPU = nil
+	return m
+}
+
+func TestSampleComputesCPUPercent(t *testing.T) {
+	m := newTestMonitor(&config.MonitorConfig{Enabled: true, IntervalSeconds: 1}, 42)
+
+	cpu := time.Duration(0)
+	m.readProc = func(pid int) (procStats, error) {
+		return procStats{cpuTime: cpu, rssBytes: 1 << 20}, nil
+	}
+
+	first := m.Sample()
+	if first.CPUPercent != 0 {
+		t.Errorf("Expected 0%% CPU on first sample, got %v", first.CPUPercent)
+	}
+
+	// Pretend the process used 50ms of CPU since the last sample
+	m.lastTime = m.lastTime.Add(-100 * time.Millisecond)
+	cpu = 50 * time.Millisecond
+	second := m.Sample()
+	if second.CPUPercent <= 0 || second.CPUPercent > 50 {
+		t.Errorf("Expected CPU between 0%% and 50%%, got %v", second.CPUPercent)
+	}
+	if second.RSSBytes != 1<<20 {
+		t.Errorf("Expected RSS of 1MiB, got %d", second.RSSBytes)
+	}
+}
+
+func TestCheckThresholds(t *testing.T) {
+	cfg := &config.MonitorConfig{Enabled: true, IntervalSeconds: 1, MaxRSSBytes: 1000}
+	m := newTestMonitor(cfg, 42)
+
+	if err := m.Check(context.Background()); err != nil {
+		t.Errorf("Expected no error before sampling, got %v", err)
+	}
+
+	m.readProc = func(pid int) (procStats, error) {
+		return procStats{rssBytes: 500}, nil
+	}
+	m.Sample()
+	if err := m.Check(context.Background()); err != nil {
+		t.Errorf("Expected no error under threshold, got %v", err)
+	}
+
+	m.readProc = func(pid int) (procStats, error) {
+		return procStats{rssBytes: 2000}, nil
+	}
+	m.Sample()
+	err := m.Check(context.Background())
+	if !errors.Is(err, health.ErrDegraded) {
+		t.Errorf("Expected degraded error over threshold, got %v", err)
+	}
+}
+
+func TestSampleWithoutProcess(t *testing.T) {
+	m := newTestMonitor(&config.MonitorConfig{Enabled: true, IntervalSeconds: 1}, 0)
+	m.readProc = func(pid int) (procStats, error) {
+		t.Fatal("readProc should not be called without a process")
+		return procStats{}, nil
+	}
+
+	sample := m.Sample()
+	if sample.PID != 0 {
+		t.Errorf("Expected PID 0, got %d", sample.PID)
+	}
+	if status := m.GetStatus(); status["pid"] != 0 {
+		t.Errorf("Expected status pid 0, got %v", status["pid"])
+	}
+}
+
+func TestReadProcStatsSelf(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("/proc not available")
+	}
+
+	stats, err := readProcStats(os.Getpid())
+	if err != nil {
+		t.Fatalf("Failed to read own process stats: %v", err)
+	}
+	if stats.rssBytes <= 0 {
+		t.Errorf("Expected positive RSS, got %d", stats.rssBytes)
+	}
+}
+
+func TestParseProcStat(t *testing.T) {
+	// Command names may contain spaces and parentheses
+	data := "1234 (kata go (x)) S 1 1234 1234 0 -1 4194304 100 0 0 0 250 50 0 0 20 0 8 0 100 0 0"
+	cpuTime, err := parseProcStat(data)
+	if err != nil {
+		t.Fatalf("Failed to parse stat: %v", err)
+	}
+	if cpuTime != 3*time.Second {
+		t.Errorf("Expected 3s CPU time, got %v", cpuTime)
+	}
+
+	if _, err := parseProcStat("garbage"); err == nil {
+		t.Error("Expected error for malformed stat")
+	}
+}
+
+func TestParseNvidiaSMI(t *testing.T) {
+	util, err := parseGPUUtilization([]byte("40\n60\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse utilization: %v", err)
+	}
+	if util != 50 {
+		t.Errorf("Expected 50%% average utilization, got %v", util)
+	}
+
+	mem := parseGPUProcessMemory([]byte("1234, 512\n999, 100\n1234, 256\n"), 1234)
+	if mem != 768*1024*1024 {
+		t.Errorf("Expected 768MiB, got %d", mem)
+	}
+}
@@ -0,0 +1,160 @@
+package monitor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicksPerSecond is the USER_HZ value used by /proc on Linux.
+const clockTicksPerSecond = 100
+
+// readProcStats reads CPU time and resident memory for a process from /proc.
+func readProcStats(pid int) (procStats, error) {
+	procDir := filepath.Join("/proc", strconv.Itoa(pid))
+
+	statData, err := os.ReadFile(filepath.Join(procDir, "stat")) // #nosec G304 -- path is built from a numeric PID
+	if err != nil {
+		return procStats{}, fmt.Errorf("failed to read process stat: %w", err)
+	}
+	cpuTime, err := parseProcStat(string(statData))
+	if err != nil {
+		return procStats{}, err
+	}
+
+	statusData, err := os.ReadFile(filepath.Join(procDir, "status")) // #nosec G304 -- path is built from a numeric PID
+	if err != nil {
+		return procStats{}, fmt.Errorf("failed to read process status: %w", err)
+	}
+
+	return procStats{
+		cpuTime:  cpuTime,
+		rssBytes: parseProcStatusRSS(string(statusData)),
+	}, nil
+}
+
+// parseProcStat returns the user plus system CPU time from /proc/<pid>/stat.
+func parseProcStat(data string) (time.Duration, error) {
+	// The command name may contain spaces, so fields are counted after it
+	end := strings.LastIndex(data, ")")
+	if end < 0 {
+		return 0, fmt.Errorf("malformed process stat")
+	}
+	fields := strings.Fields(data[end+1:])
+	// utime and stime are fields 14 and 15; fields[0] is field 3 (state)
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("malformed process stat: %d fields", len(fields))
+	}
+
+	utime, err := strconv.ParseInt(fields[11], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid utime: %w", err)
+	}
+	stime, err := strconv.ParseInt(fields[12], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid stime: %w", err)
+	}
+
+	return time.Duration(utime+stime) * time.Second / clockTicksPerSecond, nil
+}
+
+// parseProcStatusRSS returns VmRSS in bytes from /proc/<pid>/status.
+func parseProcStatusRSS(data string) int64 {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "VmRSS:") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "VmRSS:"))
+		if len(fields) == 0 {
+			return 0
+		}
+		kb, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return kb * 1024
+	}
+	return 0
+}
+
+// nvidiaSMIAvailable reports whether nvidia-smi is on the PATH.
+func nvidiaSMIAvailable() bool {
+	_, err := exec.LookPath("nvidia-smi")
+	return err == nil
+}
+
+// queryNvidiaSMI returns overall GPU utilization and the GPU memory used by
+// a process.
+func queryNvidiaSMI(ctx context.Context, pid int) (gpuStats, error) {
+	utilOut, err := exec.CommandContext(ctx, "nvidia-smi",
+		"--query-gpu=utilization.gpu", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return gpuStats{}, fmt.Errorf("failed to query GPU utilization: %w", err)
+	}
+	appsOut, err := exec.CommandContext(ctx, "nvidia-smi",
+		"--query-compute-apps=pid,used_memory", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return gpuStats{}, fmt.Errorf("failed to query GPU processes: %w", err)
+	}
+
+	util, err := parseGPUUtilization(utilOut)
+	if err != nil {
+		return gpuStats{}, err
+	}
+	return gpuStats{
+		utilPercent: util,
+		memoryBytes: parseGPUProcessMemory(appsOut, pid),
+	}, nil
+}
+
+// parseGPUUtilization averages utilization across all GPUs.
+func parseGPUUtilization(out []byte) (float64, error) {
+	var total float64
+	var count int
+	for _, line := range bytes.Split(bytes.TrimSpace(out), []byte("\n")) {
+		value := strings.TrimSpace(string(line))
+		if value == "" {
+			continue
+		}
+		util, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid GPU utilization %q: %w", value, err)
+		}
+		total += util
+		count++
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("no GPUs reported")
+	}
+	return total / float64(count), nil
+}
+
+// parseGPUProcessMemory sums the GPU memory (reported in MiB) used by pid.
+func parseGPUProcessMemory(out []byte, pid int) int64 {
+	var total int64
+	for _, line := range bytes.Split(bytes.TrimSpace(out), []byte("\n")) {
+		parts := strings.Split(string(line), ",")
+		if len(parts) != 2 {
+			continue
+		}
+		linePID, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil || linePID != pid {
+			continue
+		}
+		mib, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil {
+			continue
+		}
+		total += mib * 1024 * 1024
+	}
+	return total
+}
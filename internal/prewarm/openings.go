@@ -0,0 +1,78 @@
+package prewarm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+// DefaultOpenings are the openings analyzed when none are configured:
+// popular fuseki on a 19x19 board, in KataGo coordinates from Black's
+// first move.
+var DefaultOpenings = []string{
+	"Q16 D4 Q4 D16 C17 C16 D17 E17", // Four star points, early 3-3 invasion
+	"Q16 D4 Q4 D16 C3 D3 C4 C5",     // Four star points, 3-3 invasion under the other star point
+	"Q16 D4 R4 D16 R10 O17",         // Chinese opening
+	"Q16 D16 R4 D4 O3 C10",          // Mini-Chinese opening
+	"Q16 D4 Q4 D16 Q10 F3",          // Sanrensei
+	"R16 D4 Q3 D16 C6 F3",           // Two komoku with an early approach
+	"Q16 D4 Q3 D16 C6 F3 D9",        // Star point and komoku, approach and pincer
+	"R16 D16 Q3 C4 E3 D6",           // Shusaku-style komoku
+	"Q16 D4 R4 D16 F17 C14 O3",      // Kobayashi-style komoku and extension
+	"Q16 D4 Q4 C16 E17 R6",          // Star points against a komoku
+}
+
+// positions returns every position along the given openings, from the
+// empty board, without duplicates. Positions are shaped like those parsed
+// from an SGF, so they share cache entries with analyzePosition queries
+// about the same game.
+func positions(openings []string, rules string, komi float64) ([]*katago.Position, error) {
+	seen := make(map[string]bool)
+	var all []*katago.Position
+	for i, opening := range openings {
+		var moves []katago.Move
+		for j, location := range strings.Fields(opening) {
+			color := "b"
+			if j%2 == 1 {
+				color = "w"
+			}
+			moves = append(moves, katago.Move{Color: color, Location: strings.ToUpper(location)})
+		}
+		full := newPosition(moves, rules, komi)
+		if err := katago.ValidatePosition(full); err != nil {
+			return nil, fmt.Errorf("prewarm opening %d: %w", i+1, err)
+		}
+		if _, err := katago.BoardFromPosition(full); err != nil {
+			return nil, fmt.Errorf("prewarm opening %d: %w", i+1, err)
+		}
+
+		key := ""
+		for n := 0; n <= len(moves); n++ {
+			if n > 0 {
+				key += " " + moves[n-1].Location
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			all = append(all, newPosition(moves[:n], rules, komi))
+		}
+	}
+	return all, nil
+}
+
+// newPosition returns a 19x19 position after the given moves.
+func newPosition(moves []katago.Move, rules string, komi float64) *katago.Position {
+	position := &katago.Position{
+		Rules:      rules,
+		BoardXSize: 19,
+		BoardYSize: 19,
+		Komi:       komi,
+		Moves:      append([]katago.Move{}, moves...),
+	}
+	if len(moves) > 0 {
+		position.InitialPlayer = moves[0].Color
+	}
+	return position
+}
@@ -0,0 +1,195 @@
+// Package prewarm analyzes common opening positions while the engine is
+// idle, so the cache answers interactive queries about them at once.
+package prewarm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// pollInterval is the time between checks of whether the engine is idle.
+var pollInterval = time.Second
+
+// Prewarmer analyzes a library of opening positions in the background
+// lane, one query at a time and only while the engine has nothing else to
+// do. Positions already cached are answered by the cache, so later passes
+// only analyze entries that were evicted or expired.
+type Prewarmer struct {
+	config *config.PrewarmConfig
+	engine katago.EngineInterface
+	logger logging.ContextLogger
+
+	positions []*katago.Position
+
+	mu       sync.Mutex
+	passes   int
+	warmed   int // Positions analyzed in the current or last pass
+	errors   int
+	lastErr  string
+	lastPass time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// New creates a prewarmer that analyzes positions with engine.
+func New(cfg *config.PrewarmConfig, engine katago.EngineInterface, logger logging.ContextLogger) *Prewarmer {
+	return &Prewarmer{
+		config: cfg,
+		engine: engine,
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+}
+
+// Enabled reports whether prewarming is configured.
+func (p *Prewarmer) Enabled() bool {
+	return p.config.Enabled
+}
+
+// Start passes over the opening library every configured interval until
+// Stop is called. It fails if an opening is not a legal sequence of moves,
+// and is a no-op when prewarming is disabled.
+func (p *Prewarmer) Start() error {
+	if !p.Enabled() {
+		return nil
+	}
+	openings := p.config.Openings
+	if len(openings) == 0 {
+		openings = DefaultOpenings
+	}
+	positions, err := positions(openings, p.config.Rules, p.config.Komi)
+	if err != nil {
+		return err
+	}
+	p.positions = positions
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	interval := time.Duration(p.config.IntervalMinutes) * time.Minute
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			p.Pass(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop cancels the pass in progress and waits for the prewarmer to exit.
+// It is safe to call more than once.
+func (p *Prewarmer) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.once.Do(p.cancel)
+	<-p.done
+}
+
+// Pass analyzes each position of the library once the engine is idle, and
+// returns how many it analyzed.
+func (p *Prewarmer) Pass(ctx context.Context) int {
+	p.mu.Lock()
+	p.warmed = 0
+	p.mu.Unlock()
+
+	ctx = katago.WithLane(ctx, config.LaneBackground)
+	warmed := 0
+	for _, position := range p.positions {
+		if !p.waitIdle(ctx) {
+			break
+		}
+		req := &katago.AnalysisRequest{Position: position}
+		if p.config.MaxVisits > 0 {
+			visits := p.config.MaxVisits
+			req.MaxVisits = &visits
+		}
+		_, err := p.engine.Analyze(ctx, req)
+		if ctx.Err() != nil {
+			break
+		}
+
+		p.mu.Lock()
+		if err != nil {
+			p.errors++
+			p.lastErr = fmt.Sprintf("%d moves: %v", len(position.Moves), err)
+			p.logger.Warn("Failed to prewarm opening position", "moves", len(position.Moves), "error", err)
+		} else {
+			warmed++
+			p.warmed = warmed
+		}
+		p.mu.Unlock()
+	}
+
+	if ctx.Err() == nil {
+		p.mu.Lock()
+		p.passes++
+		p.lastPass = time.Now()
+		p.mu.Unlock()
+		p.logger.Info("Prewarmed opening positions", "positions", warmed, "total", len(p.positions))
+	}
+	return warmed
+}
+
+// waitIdle waits until the engine is running and has had no pending or
+// queued queries for the configured idle time. It returns false if ctx
+// ends first.
+func (p *Prewarmer) waitIdle(ctx context.Context) bool {
+	idle := time.Duration(p.config.IdleSeconds) * time.Second
+	var idleSince time.Time
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		load := p.engine.Load()
+		if p.engine.IsRunning() && load.Pending == 0 && load.Queued == 0 {
+			if idleSince.IsZero() {
+				idleSince = time.Now()
+			}
+			if time.Since(idleSince) >= idle {
+				return ctx.Err() == nil
+			}
+		} else {
+			idleSince = time.Time{}
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetStatus returns prewarming progress for the health endpoint stats.
+func (p *Prewarmer) GetStatus() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status := map[string]interface{}{
+		"enabled":   p.Enabled(),
+		"positions": len(p.positions),
+		"warmed":    p.warmed,
+		"passes":    p.passes,
+		"failed":    p.errors,
+	}
+	if !p.lastPass.IsZero() {
+		status["lastPass"] = p.lastPass.Format(time.RFC3339)
+	}
+	if p.lastErr != "" {
+		status["lastError"] = p.lastErr
+	}
+	return status
+}
@@ -0,0 +1,142 @@
+package prewarm
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// recordingEngine records the positions and lanes of the queries it is
+// sent.
+type recordingEngine struct {
+	*katago.MockEngine
+	mu    sync.Mutex
+	moves []int
+	lanes []string
+}
+
+func (e *recordingEngine) Analyze(ctx context.Context, req *katago.AnalysisRequest) (*katago.AnalysisResult, error) {
+	e.mu.Lock()
+	e.moves = append(e.moves, len(req.Position.Moves))
+	e.lanes = append(e.lanes, katago.LaneFromContext(ctx))
+	e.mu.Unlock()
+	return e.MockEngine.Analyze(ctx, req)
+}
+
+func (e *recordingEngine) queries() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.moves)
+}
+
+func newTestPrewarmer(t *testing.T, openings ...string) (*Prewarmer, *recordingEngine) {
+	t.Helper()
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := &recordingEngine{MockEngine: katago.NewMockEngine()}
+	engine.SetRunning(true)
+	engine.SetAnalyzeResponse(&katago.AnalysisResult{}, nil)
+	cfg := &config.PrewarmConfig{Enabled: true, Openings: openings, Rules: "chinese", Komi: 7.5, IntervalMinutes: 60}
+	return New(cfg, engine, logger), engine
+}
+
+func TestPositions(t *testing.T) {
+	all, err := positions(DefaultOpenings, "chinese", 7.5)
+	if err != nil {
+		t.Fatalf("Expected the built-in openings to be legal, got %v", err)
+	}
+	if len(all[0].Moves) != 0 || all[0].InitialPlayer != "" {
+		t.Errorf("Expected the empty board first, got %+v", all[0])
+	}
+	if all[1].InitialPlayer != "b" || all[1].Komi != 7.5 || all[1].Rules != "chinese" {
+		t.Errorf("Expected positions shaped like a parsed SGF, got %+v", all[1])
+	}
+
+	// Openings sharing moves share their positions
+	shared, err := positions([]string{"Q16 D4 Q4", "q16 d4 r4"}, "chinese", 7.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shared) != 5 {
+		t.Errorf("Expected 5 distinct positions, got %d", len(shared))
+	}
+
+	for _, opening := range []string{"Q16 Z99", "Q16 Q16"} {
+		if _, err := positions([]string{opening}, "chinese", 7.5); err == nil {
+			t.Errorf("Expected %q to be rejected", opening)
+		}
+	}
+}
+
+func TestPass(t *testing.T) {
+	p, engine := newTestPrewarmer(t, "Q16 D4", "Q16 Q4")
+	if err := p.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer p.Stop()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for engine.queries() < 4 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	p.Stop()
+
+	engine.mu.Lock()
+	defer engine.mu.Unlock()
+	if len(engine.moves) != 4 {
+		t.Fatalf("Expected 4 positions analyzed, got %v", engine.moves)
+	}
+	for _, lane := range engine.lanes {
+		if lane != config.LaneBackground {
+			t.Errorf("Expected queries in the background lane, got %s", lane)
+		}
+	}
+	if status := p.GetStatus(); status["passes"] != 1 || status["warmed"] != 4 {
+		t.Errorf("Unexpected status: %v", status)
+	}
+}
+
+func TestPassWaitsForIdleEngine(t *testing.T) {
+	saved := pollInterval
+	pollInterval = 5 * time.Millisecond
+	defer func() { pollInterval = saved }()
+
+	p, engine := newTestPrewarmer(t, "Q16")
+	if err := p.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	p.Stop()
+	engine.SetLoad(katago.EngineLoad{Pending: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	before := engine.queries()
+	if n := p.Pass(ctx); n != 0 || engine.queries() != before {
+		t.Errorf("Expected no queries while the engine is busy, sent %d", engine.queries()-before)
+	}
+
+	engine.SetLoad(katago.EngineLoad{})
+	if n := p.Pass(context.Background()); n != 2 {
+		t.Errorf("Expected both positions analyzed once the engine is idle, got %d", n)
+	}
+}
+
+func TestStartDisabledAndInvalid(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+
+	disabled := New(&config.PrewarmConfig{}, engine, logger)
+	if err := disabled.Start(); err != nil {
+		t.Errorf("Expected disabled prewarmer to start, got %v", err)
+	}
+	disabled.Stop()
+
+	invalid := New(&config.PrewarmConfig{Enabled: true, Openings: []string{"Q16 Q16"}, Rules: "chinese", IntervalMinutes: 1}, engine, logger)
+	if err := invalid.Start(); err == nil {
+		t.Error("Expected an illegal opening to be rejected")
+	}
+}
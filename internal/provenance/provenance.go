@@ -0,0 +1,126 @@
+// Package provenance signs tool results with the engine settings they were
+// analyzed with, so that a platform sharing the server's key can verify a
+// review came from this server as claimed.
+package provenance
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+)
+
+// Version is the format of the signed payload.
+const Version = 1
+
+// minKeyBytes is the shortest HMAC key accepted.
+const minKeyBytes = 16
+
+// Record describes how a tool result was produced. Signature is the
+// HMAC-SHA256 of the record's payload, which covers every other field.
+type Record struct {
+	Version       int    `json:"version"`
+	Tool          string `json:"tool"`
+	Engine        string `json:"engine"`
+	KataGoVersion string `json:"katagoVersion,omitempty"`
+	Model         string `json:"model,omitempty"`
+	ModelSHA256   string `json:"modelSha256,omitempty"`
+	MaxVisits     int    `json:"maxVisits"`  // The engine's visits per query unless a query asks for others
+	VisitsUsed    int    `json:"visitsUsed"` // Visits of every analysis behind the result
+	Timestamp     string `json:"timestamp"`  // RFC 3339, in UTC
+	ContentSHA256 string `json:"contentSha256"`
+	Signature     string `json:"signature"`
+}
+
+// Payload returns the bytes a record's signature covers: a header line
+// followed by one key=value line per field, in a fixed order.
+func (r *Record) Payload() []byte {
+	var sb strings.Builder
+	sb.WriteString("katago-mcp-provenance/" + strconv.Itoa(r.Version) + "\n")
+	for _, field := range [][2]string{
+		{"tool", r.Tool},
+		{"engine", r.Engine},
+		{"katagoVersion", r.KataGoVersion},
+		{"model", r.Model},
+		{"modelSha256", r.ModelSHA256},
+		{"maxVisits", strconv.Itoa(r.MaxVisits)},
+		{"visitsUsed", strconv.Itoa(r.VisitsUsed)},
+		{"timestamp", r.Timestamp},
+		{"contentSha256", r.ContentSHA256},
+	} {
+		sb.WriteString(field[0] + "=" + field[1] + "\n")
+	}
+	return []byte(sb.String())
+}
+
+// ContentHash returns the hex SHA-256 of a result's text content, its
+// texts joined with newlines.
+func ContentHash(texts []string) string {
+	hash := sha256.Sum256([]byte(strings.Join(texts, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+// Signer signs and verifies records with the server's key.
+type Signer struct {
+	key []byte
+	now func() time.Time
+}
+
+// NewSigner creates a signer with the configured key. It returns nil when
+// signing is disabled.
+func NewSigner(cfg *config.ProvenanceConfig) (*Signer, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+	key := []byte(cfg.Key)
+	if len(key) == 0 && cfg.KeyFile != "" {
+		data, err := os.ReadFile(cfg.KeyFile) // #nosec G304 -- keyFile is user-specified configuration
+		if err != nil {
+			return nil, fmt.Errorf("failed to read provenance key: %w", err)
+		}
+		key = []byte(strings.TrimSpace(string(data)))
+	}
+	if len(key) < minKeyBytes {
+		return nil, fmt.Errorf("provenance key must be at least %d bytes", minKeyBytes)
+	}
+	return &Signer{key: key, now: time.Now}, nil
+}
+
+// Sign stamps a record with the current time and the hash of the result's
+// texts, and signs it.
+func (s *Signer) Sign(r *Record, texts []string) {
+	r.Version = Version
+	r.Timestamp = s.now().UTC().Format(time.RFC3339)
+	r.ContentSHA256 = ContentHash(texts)
+	r.Signature = hex.EncodeToString(s.mac(r))
+}
+
+// Verify checks that a record was signed with the signer's key and that
+// texts are the content it was signed for.
+func (s *Signer) Verify(r *Record, texts []string) error {
+	if r.Version != Version {
+		return fmt.Errorf("unsupported provenance version: %d", r.Version)
+	}
+	signature, err := hex.DecodeString(r.Signature)
+	if err != nil || !hmac.Equal(signature, s.mac(r)) {
+		return errors.New("signature does not match the record")
+	}
+	if ContentHash(texts) != r.ContentSHA256 {
+		return errors.New("content does not match the signed hash")
+	}
+	return nil
+}
+
+// mac returns the HMAC-SHA256 of a record's payload.
+func (s *Signer) mac(r *Record) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(r.Payload())
+	return mac.Sum(nil)
+}
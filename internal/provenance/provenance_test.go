@@ -0,0 +1,69 @@
+package provenance
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+)
+
+func newTestSigner(t *testing.T, key string) *Signer {
+	t.Helper()
+	signer, err := NewSigner(&config.ProvenanceConfig{Enabled: true, Key: key})
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	signer.now = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.FixedZone("", 3600)) }
+	return signer
+}
+
+func TestSignAndVerify(t *testing.T) {
+	signer := newTestSigner(t, "0123456789abcdef0123")
+	texts := []string{"Black is ahead by 3.5 points"}
+	record := Record{Tool: "findMistakes", Engine: "default", KataGoVersion: "1.15.3", MaxVisits: 500, VisitsUsed: 12000}
+	signer.Sign(&record, texts)
+
+	if record.Version != Version || record.Timestamp != "2026-10-16T11:00:00Z" || record.Signature == "" {
+		t.Fatalf("Unexpected signed record: %+v", record)
+	}
+	if err := signer.Verify(&record, texts); err != nil {
+		t.Errorf("Expected the record to verify, got %v", err)
+	}
+
+	tampered := record
+	tampered.VisitsUsed = 100000
+	if err := signer.Verify(&tampered, texts); err == nil {
+		t.Error("Expected a changed visit count to fail verification")
+	}
+	if err := signer.Verify(&record, []string{"White is ahead by 3.5 points"}); err == nil {
+		t.Error("Expected changed content to fail verification")
+	}
+	if err := newTestSigner(t, "another key of enough bytes").Verify(&record, texts); err == nil {
+		t.Error("Expected another key to fail verification")
+	}
+}
+
+func TestNewSigner(t *testing.T) {
+	if signer, err := NewSigner(&config.ProvenanceConfig{Key: "0123456789abcdef"}); signer != nil || err != nil {
+		t.Errorf("Expected no signer when disabled, got %v, %v", signer, err)
+	}
+	if _, err := NewSigner(&config.ProvenanceConfig{Enabled: true, Key: "short"}); err == nil {
+		t.Error("Expected a short key to be rejected")
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte("0123456789abcdef\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fromFile, err := NewSigner(&config.ProvenanceConfig{Enabled: true, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("Failed to read key file: %v", err)
+	}
+	record := Record{Tool: "analyzePosition"}
+	fromFile.Sign(&record, nil)
+	if err := newTestSigner(t, "0123456789abcdef").Verify(&record, nil); err != nil {
+		t.Errorf("Expected the key file's trailing newline to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+// Package quota tracks the KataGo visits each client uses per day and per
+// month against configured budgets, for servers shared by many clients.
+package quota
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/fsutil"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// Quota periods.
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// stateVersion is the format version of the saved usage file.
+const stateVersion = 1
+
+// ExceededError reports a client that has used up its visits for a period.
+type ExceededError struct {
+	Client   string
+	Period   string // PeriodDay or PeriodMonth
+	Used     int64
+	Limit    int64
+	ResetsAt time.Time
+}
+
+// Error implements the error interface.
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s visit quota of %d exceeded for client %s (%d used); resets at %s",
+		periodAdjective(e.Period), e.Limit, e.Client, e.Used, e.ResetsAt.Format(time.RFC3339))
+}
+
+// periodAdjective returns "daily" or "monthly".
+func periodAdjective(period string) string {
+	if period == PeriodMonth {
+		return "monthly"
+	}
+	return "daily"
+}
+
+// PeriodStatus is a client's usage in one period.
+type PeriodStatus struct {
+	Used      int64     `json:"used"`
+	Limit     int64     `json:"limit"`               // 0 = unlimited
+	Remaining int64     `json:"remaining,omitempty"` // Unset when unlimited
+	ResetsAt  time.Time `json:"resetsAt"`
+}
+
+// Status is a client's usage against its quotas.
+type Status struct {
+	Client  string       `json:"client"`
+	Enabled bool         `json:"enabled"`
+	Daily   PeriodStatus `json:"daily"`
+	Monthly PeriodStatus `json:"monthly"`
+}
+
+// clientUsage is the visits a client has used in the current periods.
+type clientUsage struct {
+	Day           string `json:"day"` // UTC date, e.g. "2026-10-16"
+	DailyVisits   int64  `json:"dailyVisits"`
+	Month         string `json:"month"` // UTC month, e.g. "2026-10"
+	MonthlyVisits int64  `json:"monthlyVisits"`
+}
+
+// state is the saved form of a tracker's usage.
+type state struct {
+	Version int                     `json:"version"`
+	Clients map[string]*clientUsage `json:"clients"`
+}
+
+// Tracker accounts the visits clients use and rejects calls from clients
+// over their quota. Usage is saved to the configured state file, so it
+// survives restarts. A nil Tracker enforces no quotas.
+type Tracker struct {
+	logger logging.ContextLogger
+	config *config.QuotaConfig
+	now    func() time.Time
+
+	mu    sync.Mutex
+	usage map[string]*clientUsage
+	dirty bool // Usage has changed since it was last saved
+}
+
+// NewTracker creates a tracker, loading saved usage from the state file if
+// there is one. It returns nil when quotas are disabled.
+func NewTracker(cfg *config.QuotaConfig, logger logging.ContextLogger) (*Tracker, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+	t := &Tracker{
+		logger: logger,
+		config: cfg,
+		now:    time.Now,
+		usage:  make(map[string]*clientUsage),
+	}
+	if err := t.load(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Check returns an *ExceededError if the client has used up its visits
+// for the day or month. A call is allowed while any visits remain, so the
+// call that crosses a limit may overshoot it.
+func (t *Tracker) Check(clientID string) error {
+	if t == nil {
+		return nil
+	}
+	now := t.now().UTC()
+	limits := t.config.Limits(clientID)
+
+	t.mu.Lock()
+	usage := t.current(clientID, now)
+	t.mu.Unlock()
+
+	if limits.DailyVisits > 0 && usage.DailyVisits >= limits.DailyVisits {
+		return &ExceededError{
+			Client:   clientID,
+			Period:   PeriodDay,
+			Used:     usage.DailyVisits,
+			Limit:    limits.DailyVisits,
+			ResetsAt: nextDay(now),
+		}
+	}
+	if limits.MonthlyVisits > 0 && usage.MonthlyVisits >= limits.MonthlyVisits {
+		return &ExceededError{
+			Client:   clientID,
+			Period:   PeriodMonth,
+			Used:     usage.MonthlyVisits,
+			Limit:    limits.MonthlyVisits,
+			ResetsAt: nextMonth(now),
+		}
+	}
+	return nil
+}
+
+// Charge adds the visits a call used to the client's usage.
+func (t *Tracker) Charge(clientID string, visits int) {
+	if t == nil || visits <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	usage := t.current(clientID, t.now().UTC())
+	usage.DailyVisits += int64(visits)
+	usage.MonthlyVisits += int64(visits)
+	t.usage[clientID] = &usage
+	t.dirty = true
+}
+
+// Status reports a client's usage against its quotas.
+func (t *Tracker) Status(clientID string) Status {
+	if t == nil {
+		return Status{Client: clientID}
+	}
+	now := t.now().UTC()
+	limits := t.config.Limits(clientID)
+
+	t.mu.Lock()
+	usage := t.current(clientID, now)
+	t.mu.Unlock()
+
+	return Status{
+		Client:  clientID,
+		Enabled: true,
+		Daily:   periodStatus(usage.DailyVisits, limits.DailyVisits, nextDay(now)),
+		Monthly: periodStatus(usage.MonthlyVisits, limits.MonthlyVisits, nextMonth(now)),
+	}
+}
+
+// periodStatus describes the usage of one period.
+func periodStatus(used, limit int64, resetsAt time.Time) PeriodStatus {
+	status := PeriodStatus{Used: used, Limit: limit, ResetsAt: resetsAt}
+	if limit > 0 {
+		status.Remaining = max(limit-used, 0)
+	}
+	return status
+}
+
+// current returns a copy of the client's usage with periods that have
+// ended reset. The caller must hold t.mu.
+func (t *Tracker) current(clientID string, now time.Time) clientUsage {
+	var usage clientUsage
+	if saved, ok := t.usage[clientID]; ok {
+		usage = *saved
+	}
+	if day := now.Format(time.DateOnly); usage.Day != day {
+		usage.Day = day
+		usage.DailyVisits = 0
+	}
+	if month := now.Format("2006-01"); usage.Month != month {
+		usage.Month = month
+		usage.MonthlyVisits = 0
+	}
+	return usage
+}
+
+// nextDay returns the start of the UTC day after now.
+func nextDay(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+// nextMonth returns the start of the UTC month after now.
+func nextMonth(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// GetStatus returns quota statistics for monitoring.
+func (t *Tracker) GetStatus() map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{"enabled": false}
+	}
+	now := t.now().UTC()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	active := 0
+	for clientID := range t.usage {
+		if t.current(clientID, now).MonthlyVisits > 0 {
+			active++
+		}
+	}
+	return map[string]interface{}{
+		"enabled":       true,
+		"dailyVisits":   t.config.DailyVisits,
+		"monthlyVisits": t.config.MonthlyVisits,
+		"clients":       active,
+		"persistent":    t.config.StatePath != "",
+	}
+}
+
+// load reads saved usage from the state file. A missing file is not an
+// error.
+func (t *Tracker) load() error {
+	if t.config.StatePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(t.config.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read quota state: %w", err)
+	}
+	var saved state
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse quota state %s: %w", t.config.StatePath, err)
+	}
+	if saved.Version != stateVersion {
+		return fmt.Errorf("unsupported quota state version %d in %s", saved.Version, t.config.StatePath)
+	}
+	for clientID, usage := range saved.Clients {
+		if usage != nil {
+			t.usage[clientID] = usage
+		}
+	}
+	return nil
+}
+
+// Save writes usage to the state file if it has changed since it was last
+// saved. Usage of periods that have ended is dropped.
+func (t *Tracker) Save() error {
+	if t == nil || t.config.StatePath == "" {
+		return nil
+	}
+	now := t.now().UTC()
+	t.mu.Lock()
+	if !t.dirty {
+		t.mu.Unlock()
+		return nil
+	}
+	saved := state{Version: stateVersion, Clients: make(map[string]*clientUsage, len(t.usage))}
+	for clientID := range t.usage {
+		usage := t.current(clientID, now)
+		if usage.MonthlyVisits > 0 {
+			saved.Clients[clientID] = &usage
+		}
+	}
+	t.dirty = false
+	t.mu.Unlock()
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err == nil {
+		err = fsutil.WriteFileAtomic(t.config.StatePath, data)
+	}
+	if err != nil {
+		t.mu.Lock()
+		t.dirty = true
+		t.mu.Unlock()
+		return fmt.Errorf("failed to save quota state: %w", err)
+	}
+	return nil
+}
+
+// StartSaver saves usage every interval until the returned stop function
+// is called, which saves it one last time. It is a no-op without a state
+// file.
+func (t *Tracker) StartSaver(interval time.Duration) (stop func()) {
+	if t == nil || t.config.StatePath == "" || interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := t.Save(); err != nil {
+					t.logger.Warn("Failed to save quota usage", "error", err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+			if err := t.Save(); err != nil {
+				t.logger.Warn("Failed to save quota usage", "error", err)
+			}
+		})
+	}
+}
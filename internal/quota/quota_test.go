@@ -0,0 +1,130 @@
+package quota
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+func newTestTracker(t *testing.T, cfg *config.QuotaConfig, now *time.Time) *Tracker {
+	t.Helper()
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	tracker, err := NewTracker(cfg, logger)
+	if err != nil {
+		t.Fatalf("Failed to create tracker: %v", err)
+	}
+	tracker.now = func() time.Time { return *now }
+	return tracker
+}
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC)
+	cfg := &config.QuotaConfig{
+		Enabled:     true,
+		QuotaLimits: config.QuotaLimits{DailyVisits: 1000, MonthlyVisits: 2500},
+		PerClient:   map[string]config.QuotaLimits{"club": {}},
+	}
+	tracker := newTestTracker(t, cfg, &now)
+
+	t.Run("Disabled", func(t *testing.T) {
+		disabled, err := NewTracker(&config.QuotaConfig{}, nil)
+		if disabled != nil || err != nil {
+			t.Fatalf("Expected no tracker when disabled, got %v, %v", disabled, err)
+		}
+		if err := disabled.Check("anyone"); err != nil {
+			t.Errorf("Expected a nil tracker to allow calls, got %v", err)
+		}
+		disabled.Charge("anyone", 100)
+	})
+
+	t.Run("Daily", func(t *testing.T) {
+		tracker.Charge("alice", 600)
+		if err := tracker.Check("alice"); err != nil {
+			t.Fatalf("Expected calls under the quota to be allowed, got %v", err)
+		}
+		// The call that crosses the limit is allowed; the next is not
+		tracker.Charge("alice", 600)
+		err := tracker.Check("alice")
+		var exceeded *ExceededError
+		if !errors.As(err, &exceeded) {
+			t.Fatalf("Expected an ExceededError, got %v", err)
+		}
+		if exceeded.Period != PeriodDay || exceeded.Used != 1200 || exceeded.Limit != 1000 {
+			t.Errorf("Unexpected error: %+v", exceeded)
+		}
+		if want := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC); !exceeded.ResetsAt.Equal(want) {
+			t.Errorf("Expected reset at %v, got %v", want, exceeded.ResetsAt)
+		}
+
+		if err := tracker.Check("bob"); err != nil {
+			t.Errorf("Expected other clients to be unaffected, got %v", err)
+		}
+	})
+
+	t.Run("Monthly", func(t *testing.T) {
+		now = now.Add(3 * time.Hour) // The next day
+		if err := tracker.Check("alice"); err != nil {
+			t.Fatalf("Expected the daily quota to reset, got %v", err)
+		}
+		tracker.Charge("alice", 1400)
+		now = now.Add(24 * time.Hour)
+		err := tracker.Check("alice")
+		var exceeded *ExceededError
+		if !errors.As(err, &exceeded) || exceeded.Period != PeriodMonth {
+			t.Fatalf("Expected the monthly quota to be exceeded, got %v", err)
+		}
+		if want := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC); !exceeded.ResetsAt.Equal(want) {
+			t.Errorf("Expected reset at %v, got %v", want, exceeded.ResetsAt)
+		}
+
+		status := tracker.Status("alice")
+		if status.Daily.Used != 0 || status.Monthly.Used != 2600 || status.Monthly.Remaining != 0 {
+			t.Errorf("Unexpected status: %+v", status)
+		}
+	})
+
+	t.Run("PerClient", func(t *testing.T) {
+		tracker.Charge("club", 10000)
+		if err := tracker.Check("club"); err != nil {
+			t.Errorf("Expected a client without limits to be allowed, got %v", err)
+		}
+		status := tracker.Status("club")
+		if status.Daily.Limit != 0 || status.Daily.Remaining != 0 || status.Daily.Used != 10000 {
+			t.Errorf("Unexpected unlimited status: %+v", status)
+		}
+	})
+}
+
+func TestTrackerPersistence(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cfg := &config.QuotaConfig{
+		Enabled:     true,
+		StatePath:   filepath.Join(t.TempDir(), "quota.json"),
+		QuotaLimits: config.QuotaLimits{DailyVisits: 1000},
+	}
+
+	tracker := newTestTracker(t, cfg, &now)
+	tracker.Charge("alice", 1500)
+	stop := tracker.StartSaver(time.Hour)
+	stop()
+
+	restarted := newTestTracker(t, cfg, &now)
+	if err := restarted.Check("alice"); err == nil {
+		t.Error("Expected usage to survive a restart")
+	}
+	if got := restarted.Status("alice").Daily.Used; got != 1500 {
+		t.Errorf("Expected 1500 visits restored, got %d", got)
+	}
+
+	if err := os.WriteFile(cfg.StatePath, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTracker(cfg, nil); err == nil {
+		t.Error("Expected an error for a corrupt state file")
+	}
+}
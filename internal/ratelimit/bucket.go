@@ -31,8 +31,12 @@ func (b *TokenBucket) Allow(n int) bool {
 }
 
 // AllowN attempts to consume n tokens at a specific time.
-// This method is useful for testing.
+// This method is useful for testing. A negative n is never allowed, so
+// that it cannot be used to add tokens.
 func (b *TokenBucket) AllowN(n int, now time.Time) bool {
+	if n < 0 {
+		return false
+	}
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -48,6 +52,43 @@ func (b *TokenBucket) AllowN(n int, now time.Time) bool {
 	return false
 }
 
+// allowAll consumes n tokens from every bucket, or from none if any of
+// them is short, holding all of their locks so that concurrent callers
+// never see a partial admission. It returns -1 if the tokens were
+// consumed; otherwise the index of the bucket that will take longest to
+// refill, and how long until every bucket has n tokens. Callers must pass
+// buckets in the same order, so that their locks are always taken in it.
+func allowAll(buckets []*TokenBucket, n int, now time.Time) (int, time.Duration) {
+	if n < 0 {
+		return 0, 0
+	}
+	for _, b := range buckets {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+	}
+	short, wait := -1, time.Duration(0)
+	for i, b := range buckets {
+		b.refill(now)
+		if b.tokens >= float64(n) {
+			continue
+		}
+		var bucketWait time.Duration
+		if b.refillRate > 0 {
+			bucketWait = time.Duration((float64(n) - b.tokens) / b.refillRate * float64(time.Second))
+		}
+		if short < 0 || bucketWait > wait {
+			short, wait = i, bucketWait
+		}
+	}
+	if short >= 0 {
+		return short, wait
+	}
+	for _, b := range buckets {
+		b.tokens -= float64(n)
+	}
+	return -1, 0
+}
+
 // Wait blocks until n tokens are available or the context expires.
 func (b *TokenBucket) Wait(n int) time.Duration {
 	b.mu.Lock()
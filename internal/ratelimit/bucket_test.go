@@ -108,6 +108,42 @@ func TestTokenBucket(t *testing.T) {
 		}
 	})
 
+	t.Run("NegativeNotAllowed", func(t *testing.T) {
+		bucket := NewTokenBucket(5, 1.0)
+		now := time.Now()
+		bucket.AllowN(3, now)
+		if bucket.AllowN(-1, now) {
+			t.Error("Expected AllowN(-1) to return false")
+		}
+		if bucket.tokens != 2.0 {
+			t.Errorf("Expected 2 tokens left, got %f", bucket.tokens)
+		}
+	})
+
+	t.Run("AllowAll", func(t *testing.T) {
+		now := time.Now()
+		a, b, c := NewTokenBucket(5, 1.0), NewTokenBucket(1, 1.0), NewTokenBucket(5, 1.0)
+		a.lastRefill, b.lastRefill, c.lastRefill = now, now, now
+		if empty, _ := allowAll([]*TokenBucket{a, b, c}, 1, now); empty != -1 {
+			t.Fatalf("Expected the tokens to be consumed, got bucket %d empty", empty)
+		}
+		// The second bucket is empty, so nothing is taken from the others
+		if empty, wait := allowAll([]*TokenBucket{a, b, c}, 1, now); empty != 1 || wait != time.Second {
+			t.Errorf("Expected bucket 1 empty for 1s, got %d for %v", empty, wait)
+		}
+		if a.tokens != 4.0 || b.tokens != 0.0 || c.tokens != 4.0 {
+			t.Errorf("Expected 4, 0 and 4 tokens, got %f, %f and %f", a.tokens, b.tokens, c.tokens)
+		}
+
+		// The bucket that refills last is the one reported
+		d := NewTokenBucket(1, 0.25)
+		d.lastRefill = now
+		d.AllowN(1, now)
+		if empty, wait := allowAll([]*TokenBucket{a, b, d}, 1, now); empty != 2 || wait != 4*time.Second {
+			t.Errorf("Expected bucket 2 empty for 4s, got %d for %v", empty, wait)
+		}
+	})
+
 	t.Run("Concurrent", func(t *testing.T) {
 		bucket := NewTokenBucket(100, 10.0)
 		var allowed int32
@@ -2,6 +2,7 @@ package ratelimit
 
 import (
 	"fmt"
+	"math"
 	"sync"
 	"time"
 
@@ -60,55 +61,91 @@ func NewLimiter(cfg *config.RateLimitConfig, logger logging.ContextLogger) *Limi
 	return limiter
 }
 
-// Allow checks if a request is allowed under the rate limits.
+// The buckets a request can be denied by.
+const (
+	BucketGlobal     = "global"
+	BucketTool       = "tool"
+	BucketClient     = "client"
+	BucketClientTool = "clientTool"
+)
+
+// DeniedError reports a request denied by the rate limits, with the bucket
+// that denied it and how long until every bucket the request needs has a
+// token again.
+type DeniedError struct {
+	Bucket     string // BucketGlobal, BucketTool, BucketClient or BucketClientTool
+	Tool       string
+	RetryAfter time.Duration
+	message    string
+}
+
+// Error implements the error interface. The bucket and the seconds to wait
+// are given for people and GTP clients reading the message, e.g. "client
+// rate limit exceeded (bucket=client, retryAfterSeconds=3)".
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("%s (bucket=%s, retryAfterSeconds=%d)", e.message, e.Bucket, e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds returns RetryAfter in whole seconds, rounded up and at
+// least 1, as an HTTP Retry-After header would give it.
+func (e *DeniedError) RetryAfterSeconds() int {
+	seconds := int(math.Ceil(e.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// admission is a bucket a request must take a token from, with how to
+// report its being empty.
+type admission struct {
+	bucket  *TokenBucket
+	name    string // e.g. BucketGlobal
+	warning string
+	message string
+}
+
+// Allow checks if a request is allowed under the rate limits. A request
+// takes a token from the global bucket, the tool's bucket and the client's
+// buckets together, or from none of them when any is empty. A denied
+// request gets a *DeniedError.
 func (l *Limiter) Allow(clientID, toolName string) (bool, error) {
 	if l == nil {
 		return true, nil // No rate limiting configured
 	}
 
-	// Check global limit first
-	if !l.globalBucket.Allow(1) {
-		l.logger.Warn("Global rate limit exceeded",
-			"client", clientID,
-			"tool", toolName,
-		)
-		return false, fmt.Errorf("global rate limit exceeded")
-	}
-
-	// Check tool-specific limit if configured
+	// The buckets are always listed from the global bucket to the client's
+	// tool bucket, the order allowAll locks them in
+	admissions := []admission{{l.globalBucket, BucketGlobal, "Global rate limit exceeded", "global rate limit exceeded"}}
 	l.mu.RLock()
 	toolBucket, hasToolLimit := l.toolBuckets[toolName]
 	l.mu.RUnlock()
+	if hasToolLimit {
+		admissions = append(admissions, admission{toolBucket, BucketTool, "Tool rate limit exceeded",
+			fmt.Sprintf("rate limit exceeded for tool %s", toolName)})
+	}
+	if clientID != "" {
+		admissions = append(admissions, l.clientAdmissions(clientID, toolName)...)
+	}
 
-	if hasToolLimit && !toolBucket.Allow(1) {
-		// Return the token to global bucket since we're rejecting
-		l.globalBucket.Allow(-1) // Add token back
-
-		l.logger.Warn("Tool rate limit exceeded",
+	buckets := make([]*TokenBucket, len(admissions))
+	for i, a := range admissions {
+		buckets[i] = a.bucket
+	}
+	if empty, wait := allowAll(buckets, 1, time.Now()); empty >= 0 {
+		l.logger.Warn(admissions[empty].warning,
 			"client", clientID,
 			"tool", toolName,
+			"retryAfter", wait,
 		)
-		return false, fmt.Errorf("rate limit exceeded for tool %s", toolName)
+		return false, &DeniedError{Bucket: admissions[empty].name, Tool: toolName, RetryAfter: wait, message: admissions[empty].message}
 	}
-
-	// Check client-specific limits
-	if clientID != "" {
-		allowed, err := l.checkClientLimit(clientID, toolName)
-		if !allowed {
-			// Return tokens since we're rejecting
-			l.globalBucket.Allow(-1)
-			if hasToolLimit {
-				toolBucket.Allow(-1)
-			}
-			return false, err
-		}
-	}
-
 	return true, nil
 }
 
-// checkClientLimit checks per-client rate limits.
-func (l *Limiter) checkClientLimit(clientID, toolName string) (bool, error) {
+// clientAdmissions returns the per-client buckets a request must take a
+// token from, creating them for a new client.
+func (l *Limiter) clientAdmissions(clientID, toolName string) []admission {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -126,16 +163,9 @@ func (l *Limiter) checkClientLimit(clientID, toolName string) (bool, error) {
 
 	client.lastSeen = time.Now()
 
-	// Check client's global limit
-	if !client.globalBucket.Allow(1) {
-		l.logger.Warn("Client rate limit exceeded",
-			"client", clientID,
-			"tool", toolName,
-		)
-		return false, fmt.Errorf("client rate limit exceeded")
-	}
+	admissions := []admission{{client.globalBucket, BucketClient, "Client rate limit exceeded", "client rate limit exceeded"}}
 
-	// Check client's per-tool limit if configured
+	// The client's per-tool limit if configured
 	if limit, hasLimit := l.config.PerToolLimits[toolName]; hasLimit {
 		toolBucket, exists := client.toolBuckets[toolName]
 		if !exists {
@@ -147,20 +177,11 @@ func (l *Limiter) checkClientLimit(clientID, toolName string) (bool, error) {
 			toolBucket = NewTokenBucket(burstSize, toolTokensPerSecond)
 			client.toolBuckets[toolName] = toolBucket
 		}
-
-		if !toolBucket.Allow(1) {
-			// Return token to client's global bucket
-			client.globalBucket.Allow(-1)
-
-			l.logger.Warn("Client tool rate limit exceeded",
-				"client", clientID,
-				"tool", toolName,
-			)
-			return false, fmt.Errorf("client rate limit exceeded for tool %s", toolName)
-		}
+		admissions = append(admissions, admission{toolBucket, BucketClientTool, "Client tool rate limit exceeded",
+			fmt.Sprintf("client rate limit exceeded for tool %s", toolName)})
 	}
 
-	return true, nil
+	return admissions
 }
 
 // Wait returns the duration to wait before the request would be allowed.
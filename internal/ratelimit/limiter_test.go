@@ -1,6 +1,7 @@
 package ratelimit
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -262,6 +263,87 @@ func TestLimiter(t *testing.T) {
 		}
 	})
 
+	t.Run("RejectionTakesNoTokens", func(t *testing.T) {
+		cfg := &config.RateLimitConfig{
+			Enabled:        true,
+			RequestsPerMin: 60,
+			BurstSize:      10,
+			PerToolLimits:  map[string]int{"analyzePosition": 12}, // Burst of 2
+		}
+		limiter := NewLimiter(cfg, logger)
+
+		// Empty client1's bucket for the tool, then refill the shared ones
+		limiter.Allow("client1", "analyzePosition")
+		limiter.Allow("client1", "analyzePosition")
+		limiter.globalBucket.Reset()
+		limiter.toolBuckets["analyzePosition"].Reset()
+		limiter.Allow("client2", "action")
+
+		allowed, err := limiter.Allow("client1", "analyzePosition")
+		var denied *DeniedError
+		if allowed || !errors.As(err, &denied) || denied.Bucket != BucketClientTool {
+			t.Fatalf("Expected the client's tool limit to be exceeded, got %v, %v", allowed, err)
+		}
+		// The client's tool bucket refills at 0.2 tokens a second
+		if denied.RetryAfter < 4*time.Second || denied.RetryAfter > 5*time.Second {
+			t.Errorf("Expected to retry after about 5s, got %v", denied.RetryAfter)
+		}
+		if want := "client rate limit exceeded for tool analyzePosition (bucket=clientTool, retryAfterSeconds=5)"; err.Error() != want {
+			t.Errorf("Expected %q, got %q", want, err.Error())
+		}
+		if tokens := limiter.globalBucket.Tokens(); tokens < 9 || tokens >= 9.5 {
+			t.Errorf("Expected 9 global tokens left, got %f", tokens)
+		}
+		if tokens := limiter.toolBuckets["analyzePosition"].Tokens(); tokens < 2 {
+			t.Errorf("Expected the tool bucket full, got %f", tokens)
+		}
+		if tokens := limiter.clientLimits["client1"].globalBucket.Tokens(); tokens < 8 || tokens >= 8.5 {
+			t.Errorf("Expected 8 tokens left for client1, got %f", tokens)
+		}
+	})
+
+	t.Run("ConcurrentAdmissionIsAtomic", func(t *testing.T) {
+		cfg := &config.RateLimitConfig{
+			Enabled:        true,
+			RequestsPerMin: 60,
+			BurstSize:      10,
+			PerToolLimits:  map[string]int{"analyzePosition": 12}, // Burst of 2
+		}
+		limiter := NewLimiter(cfg, logger)
+
+		// Bursts at the limited tool must take global tokens only for the
+		// calls they let through, leaving the rest for other tools
+		var analyzed, other int32
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(clientID int) {
+				defer wg.Done()
+				if ok, _ := limiter.Allow(fmt.Sprintf("client%d", clientID), "analyzePosition"); ok {
+					atomic.AddInt32(&analyzed, 1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(clientID int) {
+				defer wg.Done()
+				if ok, _ := limiter.Allow(fmt.Sprintf("client%d", clientID), "action"); ok {
+					atomic.AddInt32(&other, 1)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		if analyzed != 2 {
+			t.Errorf("Expected 2 calls to the limited tool allowed, got %d", analyzed)
+		}
+		if analyzed+other != 10 {
+			t.Errorf("Expected the global burst of 10 allowed in all, got %d", analyzed+other)
+		}
+	})
+
 	t.Run("ClientCleanup", func(t *testing.T) {
 		cfg := &config.RateLimitConfig{
 			Enabled:        true,
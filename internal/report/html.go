@@ -0,0 +1,208 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+// Sizes of the SVG drawings, in pixels.
+const (
+	graphWidth  = 720
+	graphHeight = 200
+	graphMargin = 30
+	boardCell   = 22
+)
+
+// reportCSS styles the HTML report without external resources.
+const reportCSS = `body{font-family:system-ui,sans-serif;max-width:60rem;margin:2rem auto;padding:0 1rem;color:#222}
+table{border-collapse:collapse;margin:1rem 0}th,td{border:1px solid #ccc;padding:.3rem .6rem;text-align:left}
+th{background:#f4f4f4}td.num{text-align:right}.blunder{color:#b00020;font-weight:bold}.mistake{color:#b35c00}
+.positions{display:flex;flex-wrap:wrap;gap:1.5rem}figure{margin:0}figcaption{max-width:28rem;font-size:.9rem}
+svg text{font-size:10px;fill:#555}`
+
+// HTML renders a review as a standalone HTML page, drawing the winrate
+// graph and board diagrams as inline SVG.
+func HTML(game *katago.Position, review *katago.GameReview) string {
+	var sb strings.Builder
+	heading := html.EscapeString(title(game))
+	sb.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString(fmt.Sprintf("<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n", heading, reportCSS))
+	sb.WriteString(fmt.Sprintf("<h1>%s</h1>\n", heading))
+
+	s := review.Summary
+	sb.WriteString("<h2>Summary</h2>\n")
+	if game.GameInfo.Known() {
+		sb.WriteString(fmt.Sprintf("<p>%s</p>\n", html.EscapeString(game.GameInfo.String())))
+	}
+	sb.WriteString(fmt.Sprintf("<p>Board %dx%d, komi %g, %s rules. %d moves.",
+		game.BoardXSize, game.BoardYSize, game.Komi, html.EscapeString(game.Rules), s.TotalMoves))
+	if s.Partial {
+		sb.WriteString(fmt.Sprintf(" <strong>Partial review:</strong> %d of %d moves analyzed.", s.AnalyzedMoves, s.TotalMoves))
+	}
+	if s.DecidedAtMove > 0 {
+		sb.WriteString(fmt.Sprintf(" <strong>Decided</strong> after move %d; later moves not judged.", s.DecidedAtMove))
+	}
+	if s.EstimatedLevel != "" {
+		sb.WriteString(fmt.Sprintf(" Estimated level: %s.", html.EscapeString(s.EstimatedLevel)))
+	}
+	if s.Result != nil {
+		sb.WriteString(" " + html.EscapeString(resultSummary(s.Result)))
+	}
+	sb.WriteString("</p>\n<table>\n<tr><th></th><th>Black</th><th>White</th></tr>\n")
+	sb.WriteString(fmt.Sprintf("<tr><td>Accuracy</td><td class=\"num\">%.1f%%</td><td class=\"num\">%.1f%%</td></tr>\n",
+		s.BlackAccuracy, s.WhiteAccuracy))
+	sb.WriteString(fmt.Sprintf("<tr><td>Mistakes</td><td class=\"num\">%d</td><td class=\"num\">%d</td></tr>\n",
+		s.BlackMistakes, s.WhiteMistakes))
+	sb.WriteString(fmt.Sprintf("<tr><td>Blunders</td><td class=\"num\">%d</td><td class=\"num\">%d</td></tr>\n",
+		s.BlackBlunders, s.WhiteBlunders))
+	sb.WriteString(fmt.Sprintf("<tr><td>Top-1 agreement</td><td class=\"num\">%.1f%%</td><td class=\"num\">%.1f%%</td></tr>\n",
+		s.BlackTop1Agreement, s.WhiteTop1Agreement))
+	sb.WriteString(fmt.Sprintf("<tr><td>Top-3 agreement</td><td class=\"num\">%.1f%%</td><td class=\"num\">%.1f%%</td></tr>\n",
+		s.BlackTop3Agreement, s.WhiteTop3Agreement))
+	sb.WriteString(fmt.Sprintf("<tr><td>Average policy</td><td class=\"num\">%.1f%%</td><td class=\"num\">%.1f%%</td></tr>\n</table>\n",
+		s.BlackAveragePolicy*100, s.WhiteAveragePolicy*100))
+
+	if len(review.Winrates) > 0 {
+		sb.WriteString("<h2>Winrate Graph</h2>\n<p>Black's win rate before each move. Red lines mark mistakes.</p>\n")
+		sb.WriteString(svgGraph(review))
+	}
+
+	sb.WriteString("<h2>Mistakes</h2>\n")
+	if len(review.Mistakes) == 0 {
+		sb.WriteString("<p>No significant mistakes found.</p>\n")
+	} else {
+		sb.WriteString("<table>\n<tr><th>Move</th><th>Player</th><th>Category</th><th>Played</th><th>Better</th><th>Win rate drop</th></tr>\n")
+		for _, m := range review.Mistakes {
+			category := html.EscapeString(m.Category)
+			if m.Tenuki {
+				category += " (tenuki)"
+			}
+			sb.WriteString(fmt.Sprintf("<tr><td class=\"num\">%d</td><td>%s</td><td class=\"%s\">%s</td><td>%s</td><td>%s</td><td class=\"num\">%.1f%%</td></tr>\n",
+				m.MoveNumber, html.EscapeString(playerName(&m)), html.EscapeString(m.Category), category,
+				html.EscapeString(moveName(m.PlayedMove)), html.EscapeString(m.BestMove), m.WinrateDrop*100))
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	if len(review.Findings) > 0 {
+		sb.WriteString("<h2>Analyzer Findings</h2>\n")
+		sb.WriteString("<table>\n<tr><th>Move</th><th>Player</th><th>Analyzer</th><th>Finding</th><th>Explanation</th></tr>\n")
+		for _, f := range review.Findings {
+			sb.WriteString(fmt.Sprintf("<tr><td class=\"num\">%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				f.MoveNumber, html.EscapeString(f.Color), html.EscapeString(f.Analyzer),
+				html.EscapeString(f.Category), html.EscapeString(f.Explanation)))
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	if ds := diagrams(game, review); len(ds) > 0 {
+		sb.WriteString("<h2>Key Positions</h2>\n<div class=\"positions\">\n")
+		for _, d := range ds {
+			m := d.mistake
+			sb.WriteString("<figure>\n")
+			sb.WriteString(svgBoard(d.board, game.BoardXSize, game.BoardYSize, m.PlayedMove, m.BestMove))
+			sb.WriteString(fmt.Sprintf("<figcaption><strong>Move %d: %s %s.</strong> 1 = played %s (%.1f%% WR), a = better %s (%.1f%% WR). %s</figcaption>\n",
+				m.MoveNumber, html.EscapeString(playerName(m)), html.EscapeString(m.Category),
+				html.EscapeString(moveName(m.PlayedMove)), m.PlayedWR*100, html.EscapeString(m.BestMove), m.BestWR*100,
+				html.EscapeString(m.Explanation)))
+			sb.WriteString("</figure>\n")
+		}
+		sb.WriteString("</div>\n")
+	}
+
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+// svgGraph draws the winrate through the game with mistakes marked.
+func svgGraph(review *katago.GameReview) string {
+	moves := review.Summary.TotalMoves
+	if last := review.Winrates[len(review.Winrates)-1].MoveNumber; last > moves {
+		moves = last
+	}
+	if moves < 1 {
+		moves = 1
+	}
+	plotWidth := float64(graphWidth - 2*graphMargin)
+	plotHeight := float64(graphHeight - 2*graphMargin)
+	x := func(move int) float64 { return graphMargin + plotWidth*float64(move)/float64(moves) }
+	y := func(winrate float64) float64 { return graphMargin + plotHeight*(1-winrate) }
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" role=\"img\" aria-label=\"Winrate graph\">\n",
+		graphWidth, graphHeight, graphWidth, graphHeight))
+	sb.WriteString(fmt.Sprintf("<rect x=\"%d\" y=\"%d\" width=\"%.0f\" height=\"%.0f\" fill=\"#fafafa\" stroke=\"#ccc\"/>\n",
+		graphMargin, graphMargin, plotWidth, plotHeight))
+	sb.WriteString(fmt.Sprintf("<line x1=\"%d\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#999\" stroke-dasharray=\"4 3\"/>\n",
+		graphMargin, y(0.5), x(moves), y(0.5)))
+	sb.WriteString(fmt.Sprintf("<text x=\"2\" y=\"%.1f\">100%%</text><text x=\"8\" y=\"%.1f\">50%%</text><text x=\"14\" y=\"%.1f\">0%%</text>\n",
+		y(1)+4, y(0.5)+4, y(0)+4))
+	sb.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\">0</text><text x=\"%.1f\" y=\"%d\">%d</text>\n",
+		graphMargin, graphHeight-10, x(moves)-12, graphHeight-10, moves))
+	for _, m := range review.Mistakes {
+		color := "#e0a060"
+		if m.Category == "blunder" {
+			color = "#d03030"
+		}
+		sb.WriteString(fmt.Sprintf("<line x1=\"%.1f\" y1=\"%d\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"%s\"><title>Move %d: %s</title></line>\n",
+			x(m.MoveNumber), graphMargin, x(m.MoveNumber), y(0), color, m.MoveNumber, html.EscapeString(m.Category)))
+	}
+	points := make([]string, 0, len(review.Winrates))
+	for _, p := range review.Winrates {
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x(p.MoveNumber), y(p.Winrate)))
+	}
+	sb.WriteString(fmt.Sprintf("<polyline points=\"%s\" fill=\"none\" stroke=\"#2060c0\" stroke-width=\"2\"/>\n",
+		strings.Join(points, " ")))
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+// svgBoard draws a board with the played move marked 1 and the better
+// move marked a.
+func svgBoard(board *katago.Board, xSize, ySize int, played, best string) string {
+	size := func(n int) int { return (n + 1) * boardCell }
+	px := func(i int) int { return (i + 1) * boardCell }
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		size(xSize), size(ySize), size(xSize), size(ySize)))
+	sb.WriteString(fmt.Sprintf("<rect width=\"%d\" height=\"%d\" fill=\"#dcb35c\"/>\n", size(xSize), size(ySize)))
+	for x := 0; x < xSize; x++ {
+		sb.WriteString(fmt.Sprintf("<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#333\"/>", px(x), px(0), px(x), px(ySize-1)))
+		sb.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%c</text>\n", px(x), boardCell/2+3, katago.ColumnLetter(x)))
+	}
+	for y := 0; y < ySize; y++ {
+		sb.WriteString(fmt.Sprintf("<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#333\"/>", px(0), px(y), px(xSize-1), px(y)))
+		sb.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%d</text>\n", boardCell/2, px(y)+3, ySize-y))
+	}
+
+	radius := boardCell/2 - 1
+	for y := 0; y < ySize; y++ {
+		for x := 0; x < xSize; x++ {
+			loc := location(x, y, ySize)
+			switch board.Stone(loc) {
+			case "b":
+				sb.WriteString(fmt.Sprintf("<circle cx=\"%d\" cy=\"%d\" r=\"%d\" fill=\"#111\"/>\n", px(x), px(y), radius))
+			case "w":
+				sb.WriteString(fmt.Sprintf("<circle cx=\"%d\" cy=\"%d\" r=\"%d\" fill=\"#fff\" stroke=\"#333\"/>\n", px(x), px(y), radius))
+			}
+			var mark, color string
+			switch {
+			case strings.EqualFold(loc, played):
+				mark, color = "1", "#d03030"
+			case strings.EqualFold(loc, best):
+				mark, color = "a", "#208040"
+			default:
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("<circle cx=\"%d\" cy=\"%d\" r=\"%d\" fill=\"#fff\" stroke=\"%s\" stroke-width=\"2\"/>", px(x), px(y), radius, color))
+			sb.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" text-anchor=\"middle\" style=\"font-size:12px;font-weight:bold;fill:%s\">%s</text>\n",
+				px(x), px(y)+4, color, mark))
+		}
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
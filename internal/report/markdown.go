@@ -0,0 +1,152 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+// sparkLevels draws the winrate graph in text, from 0% to 100%.
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// sparkWidth is the number of moves per line of the text graph.
+const sparkWidth = 50
+
+// Markdown renders a review as a Markdown document. Board diagrams and the
+// winrate graph are drawn in code blocks so they need no images.
+func Markdown(game *katago.Position, review *katago.GameReview) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", title(game)))
+
+	s := review.Summary
+	sb.WriteString("## Summary\n\n")
+	if game.GameInfo.Known() {
+		sb.WriteString(fmt.Sprintf("- Game: %s\n", game.GameInfo))
+	}
+	sb.WriteString(fmt.Sprintf("- Board: %dx%d, komi %g, %s rules\n", game.BoardXSize, game.BoardYSize, game.Komi, game.Rules))
+	sb.WriteString(fmt.Sprintf("- Total moves: %d\n", s.TotalMoves))
+	if s.Partial {
+		sb.WriteString(fmt.Sprintf("- **Partial review**: %d of %d moves analyzed\n", s.AnalyzedMoves, s.TotalMoves))
+	}
+	if s.DecidedAtMove > 0 {
+		sb.WriteString(fmt.Sprintf("- **Decided** after move %d; later moves not judged\n", s.DecidedAtMove))
+	}
+	sb.WriteString("\n| | Black | White |\n|---|---|---|\n")
+	sb.WriteString(fmt.Sprintf("| Accuracy | %.1f%% | %.1f%% |\n", s.BlackAccuracy, s.WhiteAccuracy))
+	sb.WriteString(fmt.Sprintf("| Mistakes | %d | %d |\n", s.BlackMistakes, s.WhiteMistakes))
+	sb.WriteString(fmt.Sprintf("| Blunders | %d | %d |\n", s.BlackBlunders, s.WhiteBlunders))
+	sb.WriteString(fmt.Sprintf("| Top-1 agreement | %.1f%% | %.1f%% |\n", s.BlackTop1Agreement, s.WhiteTop1Agreement))
+	sb.WriteString(fmt.Sprintf("| Top-3 agreement | %.1f%% | %.1f%% |\n", s.BlackTop3Agreement, s.WhiteTop3Agreement))
+	sb.WriteString(fmt.Sprintf("| Average policy | %.1f%% | %.1f%% |\n", s.BlackAveragePolicy*100, s.WhiteAveragePolicy*100))
+	if s.EstimatedLevel != "" {
+		sb.WriteString(fmt.Sprintf("\nEstimated level: %s\n", s.EstimatedLevel))
+	}
+	if s.Result != nil {
+		sb.WriteString(fmt.Sprintf("\n%s\n", resultSummary(s.Result)))
+	}
+
+	if len(review.Winrates) > 0 {
+		sb.WriteString("\n## Winrate Graph\n\n")
+		sb.WriteString("Black's win rate before each move, from 0% (▁) to 100% (█).\n\n```\n")
+		sb.WriteString(sparkline(review.Winrates))
+		sb.WriteString("```\n")
+	}
+
+	sb.WriteString("\n## Mistakes\n\n")
+	if len(review.Mistakes) == 0 {
+		sb.WriteString("No significant mistakes found.\n")
+	} else {
+		sb.WriteString("| Move | Player | Category | Played | Better | Win rate drop |\n")
+		sb.WriteString("|---:|---|---|---|---|---:|\n")
+		for _, m := range review.Mistakes {
+			category := m.Category
+			if m.Tenuki {
+				category += " (tenuki)"
+			}
+			sb.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s | %.1f%% |\n",
+				m.MoveNumber, playerName(&m), category, moveName(m.PlayedMove), m.BestMove, m.WinrateDrop*100))
+		}
+	}
+
+	if len(review.Findings) > 0 {
+		sb.WriteString("\n## Analyzer Findings\n\n")
+		sb.WriteString("| Move | Player | Analyzer | Finding | Explanation |\n")
+		sb.WriteString("|---:|---|---|---|---|\n")
+		for _, f := range review.Findings {
+			sb.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %s |\n",
+				f.MoveNumber, f.Color, f.Analyzer, f.Category, f.Explanation))
+		}
+	}
+
+	if ds := diagrams(game, review); len(ds) > 0 {
+		sb.WriteString("\n## Key Positions\n")
+		for _, d := range ds {
+			m := d.mistake
+			sb.WriteString(fmt.Sprintf("\n### Move %d: %s %s\n\n", m.MoveNumber, playerName(m), m.Category))
+			sb.WriteString("```\n")
+			sb.WriteString(textDiagram(d.board, game.BoardXSize, game.BoardYSize, m.PlayedMove, m.BestMove))
+			sb.WriteString("```\n\n")
+			sb.WriteString(fmt.Sprintf("1 = played %s (%.1f%% WR), a = better %s (%.1f%% WR). %s\n",
+				moveName(m.PlayedMove), m.PlayedWR*100, m.BestMove, m.BestWR*100, m.Explanation))
+		}
+	}
+	return sb.String()
+}
+
+// sparkline draws win rates as rows of block characters, labeled with the
+// first move of each row.
+func sparkline(points []katago.WinratePoint) string {
+	var sb strings.Builder
+	for start := 0; start < len(points); start += sparkWidth {
+		end := start + sparkWidth
+		if end > len(points) {
+			end = len(points)
+		}
+		sb.WriteString(fmt.Sprintf("%4d ", points[start].MoveNumber))
+		for _, p := range points[start:end] {
+			level := int(p.Winrate * float64(len(sparkLevels)))
+			if level < 0 {
+				level = 0
+			}
+			if level >= len(sparkLevels) {
+				level = len(sparkLevels) - 1
+			}
+			sb.WriteRune(sparkLevels[level])
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// textDiagram draws a board with the played move marked 1 and the better
+// move marked a.
+func textDiagram(board *katago.Board, xSize, ySize int, played, best string) string {
+	var sb strings.Builder
+	header := "   "
+	for x := 0; x < xSize; x++ {
+		header += fmt.Sprintf(" %c", katago.ColumnLetter(x))
+	}
+	sb.WriteString(header + "\n")
+	for y := 0; y < ySize; y++ {
+		sb.WriteString(fmt.Sprintf("%2d ", ySize-y))
+		for x := 0; x < xSize; x++ {
+			loc := location(x, y, ySize)
+			switch {
+			case strings.EqualFold(loc, played):
+				sb.WriteString(" 1")
+			case strings.EqualFold(loc, best):
+				sb.WriteString(" a")
+			case board.Stone(loc) == "b":
+				sb.WriteString(" ●")
+			case board.Stone(loc) == "w":
+				sb.WriteString(" ○")
+			default:
+				sb.WriteString(" ·")
+			}
+		}
+		sb.WriteString(fmt.Sprintf(" %d\n", ySize-y))
+	}
+	sb.WriteString(header + "\n")
+	return sb.String()
+}
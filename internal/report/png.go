@@ -0,0 +1,113 @@
+package report
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+// Colors of the PNG winrate graph, matching the HTML report's.
+var (
+	pngBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	pngPlot       = color.RGBA{0xfa, 0xfa, 0xfa, 0xff}
+	pngBorder     = color.RGBA{0xcc, 0xcc, 0xcc, 0xff}
+	pngEven       = color.RGBA{0x99, 0x99, 0x99, 0xff}
+	pngMistake    = color.RGBA{0xe0, 0xa0, 0x60, 0xff}
+	pngBlunder    = color.RGBA{0xd0, 0x30, 0x30, 0xff}
+	pngWinrate    = color.RGBA{0x20, 0x60, 0xc0, 0xff}
+)
+
+// GraphPNG draws a review's winrate graph as a PNG image, for chat
+// services that do not display SVG: Black's win rate from 0% at the bottom
+// to 100% at the top, with a dashed line at 50% and a vertical line at
+// each mistake. It has no text, so callers label it themselves. Reviews
+// without win rates get an empty plot.
+func GraphPNG(review *katago.GameReview) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, graphWidth, graphHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(pngBackground), image.Point{}, draw.Src)
+	plot := image.Rect(graphMargin, graphMargin, graphWidth-graphMargin, graphHeight-graphMargin)
+	draw.Draw(img, plot, image.NewUniform(pngPlot), image.Point{}, draw.Src)
+
+	moves := review.Summary.TotalMoves
+	if n := len(review.Winrates); n > 0 && review.Winrates[n-1].MoveNumber > moves {
+		moves = review.Winrates[n-1].MoveNumber
+	}
+	if moves < 1 {
+		moves = 1
+	}
+	x := func(move int) int { return plot.Min.X + plot.Dx()*move/moves }
+	y := func(winrate float64) int { return plot.Min.Y + int(float64(plot.Dy())*(1-winrate)) }
+
+	for px := plot.Min.X; px < plot.Max.X; px++ {
+		if (px-plot.Min.X)%7 < 4 {
+			img.Set(px, y(0.5), pngEven)
+		}
+	}
+	for _, m := range review.Mistakes {
+		c := pngMistake
+		if m.Category == "blunder" {
+			c = pngBlunder
+		}
+		drawLine(img, x(m.MoveNumber), plot.Min.Y, x(m.MoveNumber), plot.Max.Y, c)
+	}
+	for i := 1; i < len(review.Winrates); i++ {
+		from, to := review.Winrates[i-1], review.Winrates[i]
+		x0, y0, x1, y1 := x(from.MoveNumber), y(from.Winrate), x(to.MoveNumber), y(to.Winrate)
+		drawLine(img, x0, y0, x1, y1, pngWinrate)
+		drawLine(img, x0, y0+1, x1, y1+1, pngWinrate)
+	}
+	drawRect(img, plot, pngBorder)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawLine draws a one pixel line with Bresenham's algorithm.
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// drawRect draws the outline of r.
+func drawRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	drawLine(img, r.Min.X, r.Min.Y, r.Max.X-1, r.Min.Y, c)
+	drawLine(img, r.Min.X, r.Max.Y-1, r.Max.X-1, r.Max.Y-1, c)
+	drawLine(img, r.Min.X, r.Min.Y, r.Min.X, r.Max.Y-1, c)
+	drawLine(img, r.Max.X-1, r.Min.Y, r.Max.X-1, r.Max.Y-1, c)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,170 @@
+// Package report renders game reviews as standalone Markdown or HTML
+// documents with board diagrams, a winrate graph and a mistake table, or
+// as SGF files that desktop GUIs such as Lizzie and Sabaki can browse.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+// Report formats.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatLizzie   = "lizzie" // SGF with Lizzie and Sabaki analysis properties
+)
+
+// maxDiagrams limits board diagrams to the costliest mistakes.
+const maxDiagrams = 10
+
+// ParseFormat returns the report format named by s, accepting "md",
+// "htm" and "sgf" as well as the format names.
+func ParseFormat(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	case "lizzie", "sgf":
+		return FormatLizzie, nil
+	default:
+		return "", apperrors.New(apperrors.CodeInvalidArgument, "report format must be 'markdown', 'html' or 'lizzie': %s", s)
+	}
+}
+
+// Extension returns the usual file extension for a format.
+func Extension(format string) string {
+	switch format {
+	case FormatHTML:
+		return ".html"
+	case FormatLizzie:
+		return ".sgf"
+	}
+	return ".md"
+}
+
+// MIMEType returns the media type of a format.
+func MIMEType(format string) string {
+	switch format {
+	case FormatHTML:
+		return "text/html"
+	case FormatLizzie:
+		return "application/x-go-sgf"
+	}
+	return "text/markdown"
+}
+
+// Generate renders a review of game, parsed from sgf, in the given format.
+func Generate(format, sgf string, game *katago.Position, review *katago.GameReview) (string, error) {
+	switch format {
+	case FormatMarkdown:
+		return Markdown(game, review), nil
+	case FormatHTML:
+		return HTML(game, review), nil
+	case FormatLizzie:
+		return katago.LizzieSGF(sgf, review)
+	default:
+		return "", apperrors.New(apperrors.CodeInvalidArgument, "unknown report format: %s", format)
+	}
+}
+
+// diagram is the position before a mistake.
+type diagram struct {
+	mistake *katago.Mistake
+	board   *katago.Board
+}
+
+// diagrams returns the positions before the costliest mistakes, in move
+// order. Positions that cannot be rebuilt are left out.
+func diagrams(game *katago.Position, review *katago.GameReview) []diagram {
+	mistakes := make([]*katago.Mistake, 0, len(review.Mistakes))
+	for i := range review.Mistakes {
+		mistakes = append(mistakes, &review.Mistakes[i])
+	}
+	sort.SliceStable(mistakes, func(i, j int) bool {
+		return mistakes[i].WinrateDrop > mistakes[j].WinrateDrop
+	})
+	if len(mistakes) > maxDiagrams {
+		mistakes = mistakes[:maxDiagrams]
+	}
+	sort.SliceStable(mistakes, func(i, j int) bool {
+		return mistakes[i].MoveNumber < mistakes[j].MoveNumber
+	})
+
+	var result []diagram
+	for _, mistake := range mistakes {
+		if mistake.MoveNumber < 1 || mistake.MoveNumber > len(game.Moves) {
+			continue
+		}
+		before := *game
+		before.Moves = game.Moves[:mistake.MoveNumber-1]
+		board, err := katago.BoardFromPosition(&before)
+		if err != nil {
+			continue
+		}
+		result = append(result, diagram{mistake: mistake, board: board})
+	}
+	return result
+}
+
+// title names the game by its players when they are known.
+func title(game *katago.Position) string {
+	if game.PlayerBlack != "" || game.PlayerWhite != "" {
+		black, white := game.PlayerBlack, game.PlayerWhite
+		if black == "" {
+			black = "Black"
+		}
+		if white == "" {
+			white = "White"
+		}
+		return fmt.Sprintf("Game Review: %s (B) vs %s (W)", black, white)
+	}
+	return "Game Review"
+}
+
+// colorName spells out a mistake's color.
+func colorName(color string) string {
+	if strings.EqualFold(color, "B") {
+		return "Black"
+	}
+	return "White"
+}
+
+// resultSummary compares the recorded result with KataGo's estimate, e.g.
+// "Recorded result W+R, KataGo's estimate B+3.4. The player who resigned
+// had a 71.0% win rate in the final position."
+func resultSummary(check *katago.ResultCheck) string {
+	s := fmt.Sprintf("Recorded result %s, KataGo's estimate %s.", check.Recorded, check.Estimated)
+	if check.Explanation != "" {
+		s += " " + check.Explanation + "."
+	}
+	return s
+}
+
+// playerName names who played a mistake, e.g. "Lee 3d (Black)", or spells
+// out its color when the SGF does not name the player.
+func playerName(m *katago.Mistake) string {
+	if m.Player != "" {
+		return fmt.Sprintf("%s (%s)", m.Player, colorName(m.Color))
+	}
+	return colorName(m.Color)
+}
+
+// moveName returns a move's location, naming passes.
+func moveName(move string) string {
+	if move == "" {
+		return "pass"
+	}
+	return move
+}
+
+// location returns the GTP location of a board point, with row 0 at the
+// top.
+func location(x, y, ySize int) string {
+	return fmt.Sprintf("%c%d", katago.ColumnLetter(x), ySize-y)
+}
@@ -0,0 +1,141 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+// testSGF is the game of testReview.
+const testSGF = "(;GM[1]SZ[9]KM[7]PB[Alice <A>]PW[Bob];B[ee];W[cc];B[gg];W[aa])"
+
+func testReview(t *testing.T) (*katago.Position, *katago.GameReview) {
+	t.Helper()
+	game, err := katago.NewSGFParser(testSGF).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := &katago.GameReview{
+		Mistakes: []katago.Mistake{
+			{MoveNumber: 4, Color: "W", PlayedMove: "A9", BestMove: "F4", Category: "blunder",
+				WinrateDrop: 0.25, PlayedWR: 0.2, BestWR: 0.45, Explanation: "This move loses 25.0% win rate"},
+		},
+		Summary: katago.ReviewSummary{TotalMoves: 4, AnalyzedMoves: 4, BlackAccuracy: 100, WhiteAccuracy: 50, WhiteBlunders: 1},
+		Winrates: []katago.WinratePoint{
+			{MoveNumber: 0, Winrate: 0.5}, {MoveNumber: 1, Winrate: 0.55},
+			{MoveNumber: 2, Winrate: 0.52}, {MoveNumber: 3, Winrate: 0.56},
+		},
+	}
+	return game, review
+}
+
+func TestMarkdown(t *testing.T) {
+	game, review := testReview(t)
+	md := Markdown(game, review)
+	for _, want := range []string{
+		"# Game Review: Alice <A> (B) vs Bob (W)",
+		"| Accuracy | 100.0% | 50.0% |",
+		"   0 ▅▅▅▅\n",
+		"| 4 | White | blunder | A9 | F4 | 25.0% |",
+		"### Move 4: White blunder",
+		" 9  1 · · · · · · · · 9",
+		" 4  · · · · · a · · · 4",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected %q in report:\n%s", want, md)
+		}
+	}
+
+	// Mistakes are attributed to the players the SGF names
+	katago.AttributeReview(review, game.GameInfo)
+	md = Markdown(game, review)
+	for _, want := range []string{"- Game: Black: Alice <A> vs White: Bob\n", "| 4 | Bob (White) | blunder |", "### Move 4: Bob (White) blunder"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected %q in report:\n%s", want, md)
+		}
+	}
+
+	review.Findings = []katago.Finding{{Analyzer: "shapes", MoveNumber: 2, Color: "W", Move: "C7", Category: "empty triangle"}}
+	if md = Markdown(game, review); !strings.Contains(md, "## Analyzer Findings") || !strings.Contains(md, "| 2 | W | shapes | empty triangle |  |") {
+		t.Errorf("Expected the analyzer's finding in report:\n%s", md)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	game, review := testReview(t)
+	review.Findings = []katago.Finding{{Analyzer: "shapes", MoveNumber: 2, Color: "W", Move: "C7", Category: "empty <triangle>"}}
+	page := HTML(game, review)
+	for _, want := range []string{"<title>Game Review: Alice &lt;A&gt; (B) vs Bob (W)</title>", "<polyline", "<td class=\"blunder\">blunder</td>", "Move 4: White blunder", "<td>empty &lt;triangle&gt;</td>"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Expected %q in report", want)
+		}
+	}
+	// The page is well-formed markup apart from the doctype
+	decoder := xml.NewDecoder(strings.NewReader(strings.TrimPrefix(page, "<!DOCTYPE html>\n")))
+	decoder.Strict = false
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if err.Error() != "EOF" {
+				t.Errorf("Malformed HTML: %v", err)
+			}
+			break
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	game, review := testReview(t)
+	review.Mistakes = nil
+	review.Winrates = nil
+	for _, name := range []string{"md", "HTML"} {
+		format, err := ParseFormat(name)
+		if err != nil {
+			t.Fatalf("ParseFormat(%q) failed: %v", name, err)
+		}
+		out, err := Generate(format, testSGF, game, review)
+		if err != nil || !strings.Contains(out, "No significant mistakes found.") {
+			t.Errorf("Unexpected %s report (%v):\n%s", format, err, out)
+		}
+	}
+	format, err := ParseFormat("sgf")
+	if err != nil || format != FormatLizzie {
+		t.Fatalf("Expected sgf to name the lizzie format, got %q, %v", format, err)
+	}
+	review.Analyses = []*katago.AnalysisResult{{RootInfo: katago.RootInfo{Visits: 100, Winrate: 0.5, CurrentPlayer: "B"}}}
+	out, err := Generate(format, testSGF, game, review)
+	if err != nil || !strings.HasPrefix(out, "(;GM[1]") || !strings.Contains(out, "LZ[KataGo 50.0 100") {
+		t.Errorf("Unexpected lizzie export (%v):\n%s", err, out)
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	if Extension(FormatHTML) != ".html" || MIMEType(FormatMarkdown) != "text/markdown" || Extension(FormatLizzie) != ".sgf" {
+		t.Error("Unexpected format metadata")
+	}
+}
+
+func TestGraphPNG(t *testing.T) {
+	_, review := testReview(t)
+	data, err := GraphPNG(review)
+	if err != nil {
+		t.Fatalf("GraphPNG failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Invalid PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != graphWidth || b.Dy() != graphHeight {
+		t.Errorf("Expected a %dx%d image, got %v", graphWidth, graphHeight, b)
+	}
+	// The blunder at move 4 of 4 is drawn at the right edge of the plot
+	if r, g, b, _ := img.At(graphWidth-graphMargin, graphHeight/2+20).RGBA(); r>>8 != 0xd0 || g>>8 != 0x30 || b>>8 != 0x30 {
+		t.Errorf("Expected the blunder marked, got %x %x %x", r>>8, g>>8, b>>8)
+	}
+	if _, err := GraphPNG(&katago.GameReview{}); err != nil {
+		t.Errorf("Expected an empty graph for a review without win rates, got %v", err)
+	}
+}
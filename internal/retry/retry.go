@@ -20,6 +20,11 @@ type Config struct {
 	Multiplier float64
 	// Jitter adds randomness to retry delays (0-1).
 	Jitter float64
+	// ShouldRetry reports whether an error may be retried. If nil, all
+	// errors are retried.
+	ShouldRetry func(error) bool
+	// OnRetry, if set, is called before waiting to retry a failed attempt.
+	OnRetry func(attempt int, delay time.Duration, err error)
 }
 
 // DefaultConfig returns a default retry configuration.
@@ -68,6 +73,11 @@ func (m *Manager) Run(ctx context.Context, fn func(context.Context) error) error
 		lastErr = err
 		attempt++
 
+		// Check if the error is worth retrying
+		if m.config.ShouldRetry != nil && !m.config.ShouldRetry(err) {
+			return lastErr
+		}
+
 		// Check if we've exceeded max attempts
 		if m.config.MaxAttempts > 0 && attempt >= m.config.MaxAttempts {
 			return lastErr
@@ -75,6 +85,9 @@ func (m *Manager) Run(ctx context.Context, fn func(context.Context) error) error
 
 		// Calculate next delay
 		delay := m.calculateDelay(attempt)
+		if m.config.OnRetry != nil {
+			m.config.OnRetry(attempt, delay, err)
+		}
 
 		// Wait for the delay or context cancellation
 		select {
@@ -92,6 +92,42 @@ func TestRetryManager(t *testing.T) {
 		}
 	})
 
+	t.Run("stops on non-retryable error", func(t *testing.T) {
+		permanentErr := errors.New("permanent")
+		var retried []int
+		config := Config{
+			MaxAttempts:  5,
+			InitialDelay: time.Millisecond,
+			MaxDelay:     10 * time.Millisecond,
+			Multiplier:   2.0,
+			ShouldRetry: func(err error) bool {
+				return err != permanentErr
+			},
+			OnRetry: func(attempt int, delay time.Duration, err error) {
+				retried = append(retried, attempt)
+			},
+		}
+		manager := NewManager(config)
+
+		var attempts atomic.Int32
+		err := manager.Run(context.Background(), func(ctx context.Context) error {
+			if attempts.Add(1) < 3 {
+				return errors.New("transient")
+			}
+			return permanentErr
+		})
+
+		if err != permanentErr {
+			t.Errorf("Expected permanent error, got %v", err)
+		}
+		if attempts.Load() != 3 {
+			t.Errorf("Expected 3 attempts, got %d", attempts.Load())
+		}
+		if len(retried) != 2 || retried[0] != 1 || retried[1] != 2 {
+			t.Errorf("Expected OnRetry for attempts 1 and 2, got %v", retried)
+		}
+	})
+
 	t.Run("context cancellation", func(t *testing.T) {
 		config := Config{
 			MaxAttempts:  0, // Infinite
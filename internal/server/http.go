@@ -5,10 +5,11 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/dmmcquay/katago-mcp/internal/config"
 	"github.com/dmmcquay/katago-mcp/internal/health"
 	"github.com/dmmcquay/katago-mcp/internal/logging"
 	"github.com/dmmcquay/katago-mcp/internal/metrics"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // HTTPServer provides HTTP endpoints for health checks and metrics.
@@ -20,9 +21,26 @@ type HTTPServer struct {
 }
 
 // NewHTTPServer creates a new HTTP server for health checks and metrics.
+// Metrics are served unauthenticated at /metrics.
 func NewHTTPServer(addr string, logger logging.ContextLogger, checker *health.Checker) *HTTPServer {
-	prometheus := metrics.NewPrometheusCollector()
+	return NewHTTPServerWithConfig(addr, logger, checker, &config.MetricsConfig{
+		Enabled: true,
+		Path:    "/metrics",
+	})
+}
+
+// NewHTTPServerWithConfig creates a new HTTP server for health checks and
+// metrics, serving metrics according to the given configuration. Its
+// metrics are only those of its own HTTP requests; use
+// NewHTTPServerWithMetrics to serve the server's.
+func NewHTTPServerWithConfig(addr string, logger logging.ContextLogger, checker *health.Checker, metricsCfg *config.MetricsConfig) *HTTPServer {
+	return NewHTTPServerWithMetrics(addr, logger, checker, metricsCfg, metrics.NewPrometheusCollector(prometheus.NewRegistry()))
+}
 
+// NewHTTPServerWithMetrics creates a new HTTP server for health checks and
+// metrics, recording its requests with collector and serving what is
+// registered with the collector's registerer.
+func NewHTTPServerWithMetrics(addr string, logger logging.ContextLogger, checker *health.Checker, metricsCfg *config.MetricsConfig, collector *metrics.PrometheusCollector) *HTTPServer {
 	mux := http.NewServeMux()
 
 	// Register health endpoints
@@ -30,10 +48,22 @@ func NewHTTPServer(addr string, logger logging.ContextLogger, checker *health.Ch
 	mux.HandleFunc("/ready", checker.ReadinessHandler())
 
 	// Register metrics endpoint
-	mux.Handle("/metrics", promhttp.Handler())
+	if metricsCfg != nil && metricsCfg.Enabled {
+		path := metricsCfg.Path
+		if path == "" {
+			path = "/metrics"
+		}
+
+		metricsHandler := collector.Handler()
+		if metricsCfg.Username != "" {
+			metricsHandler = BasicAuthMiddleware(metricsCfg.Username, metricsCfg.Password)(metricsHandler)
+		}
+		mux.Handle(path, metricsHandler)
+		logger.Debug("Metrics endpoint enabled", "path", path, "basicAuth", metricsCfg.Username != "")
+	}
 
 	// Apply middleware
-	handler := PrometheusMiddleware(prometheus)(mux)
+	handler := PrometheusMiddleware(collector)(mux)
 
 	return &HTTPServer{
 		server: &http.Server{
@@ -45,7 +75,7 @@ func NewHTTPServer(addr string, logger logging.ContextLogger, checker *health.Ch
 		},
 		logger:     logger,
 		checker:    checker,
-		prometheus: prometheus,
+		prometheus: collector,
 	}
 }
 
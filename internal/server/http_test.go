@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/dmmcquay/katago-mcp/internal/config"
 	"github.com/dmmcquay/katago-mcp/internal/health"
 	"github.com/dmmcquay/katago-mcp/internal/logging"
 )
@@ -112,3 +114,62 @@ func TestHealthEndpoints(t *testing.T) {
 	defer cancel()
 	_ = server.Stop(ctx)
 }
+
+func TestMetricsEndpointConfig(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test", "debug"))
+	checker := health.NewChecker(logger, "1.0.0", "abc123")
+
+	tests := []struct {
+		name       string
+		cfg        *config.MetricsConfig
+		user, pass string
+		wantStatus int
+	}{
+		{
+			name:       "enabled without auth",
+			cfg:        &config.MetricsConfig{Enabled: true, Path: "/metrics"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "disabled",
+			cfg:        &config.MetricsConfig{Enabled: false, Path: "/metrics"},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "basic auth missing credentials",
+			cfg:        &config.MetricsConfig{Enabled: true, Path: "/metrics", Username: "prom", Password: "secret"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "basic auth wrong password",
+			cfg:        &config.MetricsConfig{Enabled: true, Path: "/metrics", Username: "prom", Password: "secret"},
+			user:       "prom",
+			pass:       "wrong",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "basic auth valid credentials",
+			cfg:        &config.MetricsConfig{Enabled: true, Path: "/metrics", Username: "prom", Password: "secret"},
+			user:       "prom",
+			pass:       "secret",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewHTTPServerWithConfig(":0", logger, checker, tt.cfg)
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			rec := httptest.NewRecorder()
+			server.server.Handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+		})
+	}
+}
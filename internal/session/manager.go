@@ -0,0 +1,297 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/metrics"
+)
+
+// Reasons a session is closed, as reported in metrics.
+const (
+	CloseReasonClosed      = "closed"
+	CloseReasonExpired     = "expired"
+	CloseReasonClientLimit = "client_limit"
+	CloseReasonServerLimit = "server_limit"
+)
+
+// Info summarizes an open session.
+type Info struct {
+	SessionID   string     `json:"sessionId"`
+	MoveNumber  int        `json:"moveNumber"`
+	TotalMoves  int        `json:"totalMoves"`
+	Variation   bool       `json:"variation"`
+	PlayerBlack string     `json:"playerBlack,omitempty"`
+	PlayerWhite string     `json:"playerWhite,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	LastUsed    time.Time  `json:"lastUsed"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"` // Unset when the session never expires
+}
+
+// Manager holds the open sessions of all clients. Each session belongs to
+// the client that loaded it and is invisible to other clients. Sessions are
+// independent of the engine, so they survive engine restarts.
+type Manager struct {
+	mu                   sync.Mutex
+	sessions             map[string]*Session
+	maxSessions          int
+	maxSessionsPerClient int
+	idleTimeout          time.Duration
+	logger               logging.ContextLogger
+	prometheus           *metrics.PrometheusCollector
+	now                  func() time.Time
+}
+
+// NewManager creates a session manager.
+func NewManager(cfg *config.SessionConfig, logger logging.ContextLogger) *Manager {
+	return &Manager{
+		sessions:             make(map[string]*Session),
+		maxSessions:          cfg.MaxSessions,
+		maxSessionsPerClient: cfg.MaxSessionsPerClient,
+		idleTimeout:          time.Duration(cfg.IdleTimeoutSeconds) * time.Second,
+		logger:               logger,
+		prometheus:           metrics.NewPrometheusCollector(nil),
+		now:                  time.Now,
+	}
+}
+
+// SetPrometheus sets the collector of the server's Prometheus metrics. Until
+// it is set, metrics are recorded in an unregistered collector.
+func (m *Manager) SetPrometheus(collector *metrics.PrometheusCollector) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prometheus = collector
+}
+
+// Load starts a session for a client's game, positioned after moveNumber
+// moves. A moveNumber outside the game positions the session at the final
+// move. ttl is the session's idle timeout; zero or anything above the
+// configured idle timeout uses the configured value. If the client or the
+// server is at its session limit, the least recently used session of the
+// client, or of the server, is closed.
+func (m *Manager) Load(owner string, game *katago.Position, moveNumber int, ttl time.Duration) (*Session, error) {
+	if err := katago.ValidatePosition(game); err != nil {
+		return nil, err
+	}
+	if _, err := katago.BoardFromPosition(game); err != nil {
+		return nil, apperrors.Wrap(apperrors.CodeInvalidSGF, err, "game contains an illegal move")
+	}
+	if ttl < 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "session ttl must not be negative")
+	}
+	if ttl == 0 || (m.idleTimeout > 0 && ttl > m.idleTimeout) {
+		ttl = m.idleTimeout
+	}
+
+	session := &Session{
+		ID:    newSessionID(),
+		owner: owner,
+		ttl:   ttl,
+		game:  game,
+		line:  append([]katago.Move(nil), game.Moves...),
+	}
+	if moveNumber < 0 || moveNumber > len(game.Moves) {
+		moveNumber = len(game.Moves)
+	}
+	if err := session.gotoMove(moveNumber); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expireLocked()
+	if m.maxSessionsPerClient > 0 && len(m.ownedLocked(owner)) >= m.maxSessionsPerClient {
+		m.closeLocked(oldest(m.ownedLocked(owner)), CloseReasonClientLimit)
+	}
+	if m.maxSessions > 0 && len(m.sessions) >= m.maxSessions {
+		all := make([]*Session, 0, len(m.sessions))
+		for _, s := range m.sessions {
+			all = append(all, s)
+		}
+		m.closeLocked(oldest(all), CloseReasonServerLimit)
+	}
+
+	session.created = m.now()
+	session.lastUsed = session.created
+	m.sessions[session.ID] = session
+	m.prometheus.RecordSessionCreated()
+	m.prometheus.SetOpenSessions(float64(len(m.sessions)))
+	m.logger.Info("Study session loaded", "session", session.ID, "client", owner, "moves", len(game.Moves))
+	return session, nil
+}
+
+// Get returns a client's open session and marks it used. Sessions of other
+// clients are reported as unknown.
+func (m *Manager) Get(owner, id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expireLocked()
+	session, ok := m.sessions[id]
+	if !ok || session.owner != owner {
+		return nil, apperrors.New(apperrors.CodeInvalidArgument, "unknown or expired session: %s", id)
+	}
+	session.lastUsed = m.now()
+	return session, nil
+}
+
+// Close closes a client's session.
+func (m *Manager) Close(owner, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok || session.owner != owner {
+		return apperrors.New(apperrors.CodeInvalidArgument, "unknown or expired session: %s", id)
+	}
+	m.closeLocked(session, CloseReasonClosed)
+	return nil
+}
+
+// List returns a client's open sessions, most recently used first.
+func (m *Manager) List(owner string) []Info {
+	m.mu.Lock()
+	m.expireLocked()
+	owned := m.ownedLocked(owner)
+	infos := make([]Info, len(owned))
+	for i, session := range owned {
+		infos[i] = Info{
+			SessionID: session.ID,
+			CreatedAt: session.created,
+			LastUsed:  session.lastUsed,
+		}
+		if session.ttl > 0 {
+			expiresAt := session.lastUsed.Add(session.ttl)
+			infos[i].ExpiresAt = &expiresAt
+		}
+	}
+	m.mu.Unlock()
+
+	for i, session := range owned {
+		state := session.State()
+		infos[i].MoveNumber = state.MoveNumber
+		infos[i].TotalMoves = state.TotalMoves
+		infos[i].Variation = state.Variation
+		infos[i].PlayerBlack = state.PlayerBlack
+		infos[i].PlayerWhite = state.PlayerWhite
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].LastUsed.After(infos[j].LastUsed)
+	})
+	return infos
+}
+
+// Count returns the number of open sessions.
+func (m *Manager) Count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expireLocked()
+	return len(m.sessions)
+}
+
+// GetStatus returns session statistics for health responses.
+func (m *Manager) GetStatus() map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expireLocked()
+	clients := make(map[string]bool)
+	for _, session := range m.sessions {
+		clients[session.owner] = true
+	}
+	return map[string]interface{}{
+		"open":    len(m.sessions),
+		"clients": len(clients),
+		"max":     m.maxSessions,
+	}
+}
+
+// StartExpiry closes idle sessions every interval until the returned stop
+// function is called, so expired sessions are released even when no
+// requests arrive. It is a no-op when sessions never expire.
+func (m *Manager) StartExpiry(interval time.Duration) (stop func()) {
+	if m.idleTimeout <= 0 || interval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	var once sync.Once
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				m.mu.Lock()
+				m.expireLocked()
+				m.mu.Unlock()
+			}
+		}
+	}()
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// expireLocked closes sessions idle longer than their TTL.
+func (m *Manager) expireLocked() {
+	now := m.now()
+	for _, session := range m.sessions {
+		if session.ttl > 0 && now.Sub(session.lastUsed) > session.ttl {
+			m.closeLocked(session, CloseReasonExpired)
+		}
+	}
+}
+
+// closeLocked removes a session and records why.
+func (m *Manager) closeLocked(session *Session, reason string) {
+	if session == nil {
+		return
+	}
+	delete(m.sessions, session.ID)
+	m.prometheus.RecordSessionClosed(reason)
+	m.prometheus.SetOpenSessions(float64(len(m.sessions)))
+	m.logger.Debug("Study session closed", "session", session.ID, "client", session.owner, "reason", reason)
+}
+
+// ownedLocked returns a client's sessions.
+func (m *Manager) ownedLocked(owner string) []*Session {
+	var owned []*Session
+	for _, session := range m.sessions {
+		if session.owner == owner {
+			owned = append(owned, session)
+		}
+	}
+	return owned
+}
+
+// oldest returns the least recently used session.
+func oldest(sessions []*Session) *Session {
+	var result *Session
+	for _, session := range sessions {
+		if result == nil || session.lastUsed.Before(result.lastUsed) {
+			result = session
+		}
+	}
+	return result
+}
+
+// newSessionID generates a random session ID.
+func newSessionID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// Fallback to timestamp-based ID
+		return fmt.Sprintf("game_%d", time.Now().UnixNano())
+	}
+	return "game_" + hex.EncodeToString(b)
+}
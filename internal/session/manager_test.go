@@ -0,0 +1,138 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+func TestManagerLimits(t *testing.T) {
+	m := newTestManager(&config.SessionConfig{MaxSessions: 2, IdleTimeoutSeconds: 60})
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	first := loadTestGame(t, m, "alice", -1)
+	now = now.Add(time.Second)
+	second := loadTestGame(t, m, "bob", -1)
+	now = now.Add(time.Second)
+	if _, err := m.Get("alice", first.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The least recently used session is evicted, whoever owns it
+	now = now.Add(time.Second)
+	loadTestGame(t, m, "alice", -1)
+	if _, err := m.Get("bob", second.ID); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected second session to be evicted, got %v", err)
+	}
+	if m.Count() != 2 {
+		t.Errorf("Expected 2 sessions, got %d", m.Count())
+	}
+
+	// Idle sessions expire
+	now = now.Add(2 * time.Minute)
+	if m.Count() != 0 {
+		t.Errorf("Expected all sessions to expire, got %d", m.Count())
+	}
+}
+
+func TestClientIsolation(t *testing.T) {
+	m := newTestManager(&config.SessionConfig{MaxSessionsPerClient: 2})
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	first := loadTestGame(t, m, "alice", -1)
+	now = now.Add(time.Second)
+	second := loadTestGame(t, m, "alice", -1)
+	now = now.Add(time.Second)
+	other := loadTestGame(t, m, "bob", -1)
+
+	// Sessions are invisible to other clients
+	if _, err := m.Get("bob", first.ID); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected another client's session to be unknown, got %v", err)
+	}
+	if err := m.Close("bob", first.ID); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected closing another client's session to fail, got %v", err)
+	}
+
+	// The per-client limit evicts only the client's own sessions
+	now = now.Add(time.Second)
+	third := loadTestGame(t, m, "alice", -1)
+	if _, err := m.Get("alice", first.ID); err == nil {
+		t.Error("Expected alice's oldest session to be evicted")
+	}
+	if _, err := m.Get("bob", other.ID); err != nil {
+		t.Errorf("Expected bob's session to remain, got %v", err)
+	}
+
+	infos := m.List("alice")
+	if len(infos) != 2 || infos[0].SessionID != third.ID || infos[1].SessionID != second.ID {
+		t.Fatalf("Expected alice's sessions most recent first, got %+v", infos)
+	}
+	if infos[0].MoveNumber != 4 || infos[0].PlayerBlack != "Alice" {
+		t.Errorf("Unexpected session info: %+v", infos[0])
+	}
+	if infos[0].ExpiresAt != nil {
+		t.Errorf("Expected no expiry without an idle timeout, got %v", *infos[0].ExpiresAt)
+	}
+
+	if err := m.Close("alice", third.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := len(m.List("alice")); got != 1 {
+		t.Errorf("Expected 1 session after close, got %d", got)
+	}
+	if got := len(m.List("carol")); got != 0 {
+		t.Errorf("Expected no sessions for a new client, got %d", got)
+	}
+}
+
+func TestSessionTTL(t *testing.T) {
+	m := newTestManager(&config.SessionConfig{IdleTimeoutSeconds: 600})
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	game, err := katago.NewSGFParser("(;GM[1]FF[4]SZ[9];B[ee])").Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse SGF: %v", err)
+	}
+	short, err := m.Load("alice", game, -1, time.Minute)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// A TTL above the configured idle timeout is capped
+	long, err := m.Load("alice", game, -1, time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := m.Load("alice", game, -1, -time.Second); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected error for negative TTL, got %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := m.Get("alice", short.ID); err == nil {
+		t.Error("Expected short-lived session to expire")
+	}
+	if _, err := m.Get("alice", long.ID); err != nil {
+		t.Errorf("Expected session to remain, got %v", err)
+	}
+
+	now = now.Add(11 * time.Minute)
+	if _, err := m.Get("alice", long.ID); err == nil {
+		t.Error("Expected session to expire at the configured idle timeout")
+	}
+}
+
+func TestLoadIllegalGame(t *testing.T) {
+	m := newTestManager(&config.SessionConfig{})
+	game, err := katago.NewSGFParser("(;GM[1]FF[4]SZ[9];B[ee];W[ee])").Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse SGF: %v", err)
+	}
+	if _, err := m.Load("alice", game, -1, 0); apperrors.CodeOf(err) != apperrors.CodeInvalidSGF {
+		t.Errorf("Expected %s, got %v", apperrors.CodeInvalidSGF, err)
+	}
+}
@@ -0,0 +1,195 @@
+// Package session keeps interactive study sessions: a game loaded once and
+// navigated move by move without resending the SGF on every call.
+package session
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+// Session is a loaded game and the current node within it. Moves played
+// with Play replace the rest of the line, creating a variation.
+type Session struct {
+	ID string
+
+	// Owned by the Manager
+	owner    string
+	ttl      time.Duration
+	created  time.Time
+	lastUsed time.Time
+
+	mu      sync.Mutex
+	game    *katago.Position // As loaded, including the game's own moves
+	line    []katago.Move    // Current line: the game or a variation of it
+	current int              // Moves of line played on the board
+	board   *katago.Board
+}
+
+// State describes a session's current node.
+type State struct {
+	SessionID  string `json:"sessionId"`
+	MoveNumber int    `json:"moveNumber"` // Moves played to reach this node
+	TotalMoves int    `json:"totalMoves"` // Moves in the current line
+	GameMoves  int    `json:"gameMoves"`  // Moves in the loaded game
+	LastMove   string `json:"lastMove,omitempty"`
+	LastColor  string `json:"lastColor,omitempty"`
+	ToPlay     string `json:"toPlay"`
+	// Variation is set when the current line has left the loaded game;
+	// DivergesAt is the first move that differs.
+	Variation     bool   `json:"variation"`
+	DivergesAt    int    `json:"divergesAt,omitempty"`
+	BlackCaptures int    `json:"blackCaptures"`
+	WhiteCaptures int    `json:"whiteCaptures"`
+	PlayerBlack   string `json:"playerBlack,omitempty"`
+	PlayerWhite   string `json:"playerWhite,omitempty"`
+	Board         string `json:"board"`
+}
+
+// Next advances up to count moves along the current line.
+func (s *Session) Next(count int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current >= len(s.line) {
+		return apperrors.New(apperrors.CodeInvalidArgument, "already at the last move (%d)", len(s.line))
+	}
+	return s.gotoMove(min(s.current+count, len(s.line)))
+}
+
+// Prev steps back up to count moves.
+func (s *Session) Prev(count int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current == 0 {
+		return apperrors.New(apperrors.CodeInvalidArgument, "already at the start of the game")
+	}
+	return s.gotoMove(max(s.current-count, 0))
+}
+
+// Goto moves to the node after moveNumber moves of the current line.
+func (s *Session) Goto(moveNumber int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if moveNumber < 0 || moveNumber > len(s.line) {
+		return apperrors.New(apperrors.CodeInvalidArgument, "move number must be between 0 and %d", len(s.line))
+	}
+	return s.gotoMove(moveNumber)
+}
+
+// Play plays a move for the side to move. If it matches the next move of
+// the current line the session simply advances; otherwise the rest of the
+// line is replaced by the new move.
+func (s *Session) Play(location string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	location = strings.ToUpper(strings.TrimSpace(location))
+	if location == "PASS" {
+		location = ""
+	}
+	color := strings.ToLower(s.toPlay())
+	move := katago.Move{Color: color, Location: location}
+
+	if s.current < len(s.line) && s.line[s.current] == move {
+		return s.gotoMove(s.current + 1)
+	}
+	if err := s.board.Play(color, location); err != nil {
+		return err
+	}
+	s.line = append(s.line[:s.current:s.current], move)
+	s.current++
+	if s.onGameLine() {
+		// Back on the game: restore its continuation
+		s.line = append([]katago.Move(nil), s.game.Moves...)
+	}
+	return nil
+}
+
+// onGameLine reports whether the current line is a prefix of the game.
+func (s *Session) onGameLine() bool {
+	if len(s.line) > len(s.game.Moves) {
+		return false
+	}
+	for i := range s.line {
+		if s.line[i] != s.game.Moves[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Position returns the position at the current node.
+func (s *Session) Position() *katago.Position {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	position := *s.game
+	position.Moves = append([]katago.Move(nil), s.line[:s.current]...)
+	if position.InitialPlayer == "" && len(position.Moves) > 0 {
+		position.InitialPlayer = position.Moves[0].Color
+	}
+	return &position
+}
+
+// State describes the current node.
+func (s *Session) State() *State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := &State{
+		SessionID:     s.ID,
+		MoveNumber:    s.current,
+		TotalMoves:    len(s.line),
+		GameMoves:     len(s.game.Moves),
+		ToPlay:        s.toPlay(),
+		BlackCaptures: s.board.Captures("b"),
+		WhiteCaptures: s.board.Captures("w"),
+		PlayerBlack:   s.game.PlayerBlack,
+		PlayerWhite:   s.game.PlayerWhite,
+		Board:         s.board.String(),
+	}
+	if s.current > 0 {
+		last := s.line[s.current-1]
+		state.LastColor = strings.ToUpper(last.Color)
+		state.LastMove = last.Location
+		if state.LastMove == "" {
+			state.LastMove = "pass"
+		}
+	}
+	for i := range s.line {
+		if i >= len(s.game.Moves) || s.line[i] != s.game.Moves[i] {
+			state.Variation = true
+			state.DivergesAt = i + 1
+			break
+		}
+	}
+	return state
+}
+
+// gotoMove rebuilds the board after moveNumber moves of the current line.
+func (s *Session) gotoMove(moveNumber int) error {
+	position := *s.game
+	position.Moves = s.line[:moveNumber]
+	board, err := katago.BoardFromPosition(&position)
+	if err != nil {
+		return err
+	}
+	s.board = board
+	s.current = moveNumber
+	return nil
+}
+
+// toPlay returns the color to move at the current node, "B" or "W".
+func (s *Session) toPlay() string {
+	if s.current > 0 {
+		if strings.EqualFold(s.line[s.current-1].Color, "b") {
+			return "W"
+		}
+		return "B"
+	}
+	if strings.EqualFold(s.game.InitialPlayer, "w") {
+		return "W"
+	}
+	return "B"
+}
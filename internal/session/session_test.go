@@ -0,0 +1,99 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+func newTestManager(cfg *config.SessionConfig) *Manager {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	return NewManager(cfg, logger)
+}
+
+func loadTestGame(t *testing.T, m *Manager, owner string, moveNumber int) *Session {
+	t.Helper()
+	game, err := katago.NewSGFParser("(;GM[1]FF[4]SZ[9]PB[Alice]PW[Bob];B[ee];W[cc];B[gg];W[cg])").Parse()
+	if err != nil {
+		t.Fatalf("Failed to parse SGF: %v", err)
+	}
+	sess, err := m.Load(owner, game, moveNumber, 0)
+	if err != nil {
+		t.Fatalf("Failed to load game: %v", err)
+	}
+	return sess
+}
+
+func TestNavigation(t *testing.T) {
+	m := newTestManager(&config.SessionConfig{})
+	sess := loadTestGame(t, m, "alice", -1)
+
+	state := sess.State()
+	if state.MoveNumber != 4 || state.TotalMoves != 4 || state.LastMove != "C3" || state.ToPlay != "B" {
+		t.Errorf("Expected final position with B to play, got %+v", state)
+	}
+	if state.PlayerBlack != "Alice" || state.Variation {
+		t.Errorf("Unexpected state: %+v", state)
+	}
+
+	if err := sess.Prev(3); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if state := sess.State(); state.MoveNumber != 1 || state.LastMove != "E5" || state.ToPlay != "W" {
+		t.Errorf("Expected move 1, got %+v", state)
+	}
+	if err := sess.Prev(5); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := sess.Prev(1); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected error stepping back from the start, got %v", err)
+	}
+	if err := sess.Next(2); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := len(sess.Position().Moves); got != 2 {
+		t.Errorf("Expected 2 moves in position, got %d", got)
+	}
+	if err := sess.Goto(10); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected error for move beyond the game, got %v", err)
+	}
+}
+
+func TestPlayVariation(t *testing.T) {
+	m := newTestManager(&config.SessionConfig{})
+	sess := loadTestGame(t, m, "alice", 2)
+
+	// Playing the game move just advances
+	if err := sess.Play("g3"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if state := sess.State(); state.MoveNumber != 3 || state.TotalMoves != 4 || state.Variation {
+		t.Errorf("Expected to follow the game, got %+v", state)
+	}
+
+	// A different move starts a variation
+	if err := sess.Play("D7"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	state := sess.State()
+	if !state.Variation || state.DivergesAt != 4 || state.TotalMoves != 4 || state.LastColor != "W" {
+		t.Errorf("Expected a variation from move 4, got %+v", state)
+	}
+	if err := sess.Play("E5"); apperrors.CodeOf(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("Expected illegal move error, got %v", err)
+	}
+
+	// Returning to the game move restores the game's continuation
+	if err := sess.Goto(3); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := sess.Play("C3"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if state := sess.State(); state.Variation || state.TotalMoves != 4 {
+		t.Errorf("Expected to be back on the game, got %+v", state)
+	}
+}
@@ -0,0 +1,342 @@
+// Package slack bridges Slack channels to game reviews: games posted as SGF
+// files or links to OGS are reviewed, and each is answered in its thread
+// with a summary and the winrate graph.
+package slack
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+const (
+	defaultAPIURL = "https://slack.com/api"
+	defaultOGSURL = "https://online-go.com"
+
+	// EventsPath is where Slack's Events API requests are served.
+	EventsPath = "/slack/events"
+
+	// maxEventBytes and maxSGFBytes bound what is read from Slack and OGS.
+	maxEventBytes = 1 << 20
+	maxSGFBytes   = 1 << 20
+	// maxRequestAge is how old a signed request may be, against replays.
+	maxRequestAge = 5 * time.Minute
+	// queueSize is the number of games waiting for review before more are
+	// turned away.
+	queueSize = 16
+)
+
+// ogsGameLink matches a link to a game on OGS and captures its ID.
+var ogsGameLink = regexp.MustCompile(`https?://online-go\.com/game/(?:view/)?(\d+)`)
+
+// Bridge receives Slack messages and reviews the games posted in them, one
+// at a time.
+type Bridge struct {
+	config *config.SlackConfig
+	engine katago.EngineInterface
+	logger logging.ContextLogger
+	client *http.Client
+	apiURL string
+	ogsURL string
+
+	server   *http.Server
+	listener net.Listener
+	jobs     chan job
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	reviewed int
+	failed   int
+	dropped  int
+	lastErr  string
+}
+
+// job is a game posted in a channel.
+type job struct {
+	channel string
+	thread  string // Timestamp of the message to answer in the thread of
+	name    string // File name or link, for replies
+	fetch   func(ctx context.Context) (string, error)
+}
+
+// New creates a bridge that reviews games with engine.
+func New(cfg *config.SlackConfig, engine katago.EngineInterface, logger logging.ContextLogger) *Bridge {
+	return &Bridge{
+		config: cfg,
+		engine: engine,
+		logger: logger,
+		client: &http.Client{Timeout: 30 * time.Second},
+		apiURL: defaultAPIURL,
+		ogsURL: defaultOGSURL,
+		jobs:   make(chan job, queueSize),
+	}
+}
+
+// Enabled reports whether a listen address is configured.
+func (b *Bridge) Enabled() bool {
+	return b.config.Addr != ""
+}
+
+// Start listens for Slack's events on the configured address and reviews
+// the games they bring until Stop is called. It is a no-op when no address
+// is configured.
+func (b *Bridge) Start() error {
+	if !b.Enabled() {
+		return nil
+	}
+	listener, err := net.Listen("tcp", b.config.Addr)
+	if err != nil {
+		return fmt.Errorf("slack listener: %w", err)
+	}
+	b.listener = listener
+	mux := http.NewServeMux()
+	mux.Handle(EventsPath, b.Handler())
+	b.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.wg.Add(2)
+	go func() {
+		defer b.wg.Done()
+		if err := b.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			b.logger.Error("Slack listener failed", "error", err)
+		}
+	}()
+	go func() {
+		defer b.wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case j := <-b.jobs:
+				b.review(ctx, j)
+			}
+		}
+	}()
+	return nil
+}
+
+// Addr returns the address the bridge listens on, or nil before Start.
+func (b *Bridge) Addr() net.Addr {
+	if b.listener == nil {
+		return nil
+	}
+	return b.listener.Addr()
+}
+
+// Stop stops receiving events, cancels the review in progress and waits
+// for the bridge to exit. Games still queued are dropped.
+func (b *Bridge) Stop(ctx context.Context) error {
+	if b.cancel == nil {
+		return nil
+	}
+	err := b.server.Shutdown(ctx)
+	b.cancel()
+	b.wg.Wait()
+	return err
+}
+
+// GetStatus returns review counts for health responses.
+func (b *Bridge) GetStatus() map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := map[string]interface{}{
+		"enabled":  b.Enabled(),
+		"queued":   len(b.jobs),
+		"reviewed": b.reviewed,
+		"failed":   b.failed,
+		"dropped":  b.dropped,
+	}
+	if b.lastErr != "" {
+		status["lastError"] = b.lastErr
+	}
+	return status
+}
+
+// envelope is a request from Slack's Events API.
+type envelope struct {
+	Type      string       `json:"type"` // "url_verification" or "event_callback"
+	Challenge string       `json:"challenge"`
+	Event     messageEvent `json:"event"`
+}
+
+// messageEvent is a message posted in a channel.
+type messageEvent struct {
+	Type     string      `json:"type"`
+	Subtype  string      `json:"subtype"`
+	Channel  string      `json:"channel"`
+	BotID    string      `json:"bot_id"`
+	Text     string      `json:"text"`
+	TS       string      `json:"ts"`
+	ThreadTS string      `json:"thread_ts"`
+	Files    []slackFile `json:"files"`
+}
+
+// slackFile is a file shared in a message.
+type slackFile struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"url_private_download"`
+}
+
+// Handler returns the handler of Slack's Events API requests. Requests
+// must carry a valid signature; games are queued for review and the
+// request answered at once, as Slack expects within three seconds.
+func (b *Bridge) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxEventBytes))
+		if err != nil {
+			http.Error(w, "failed to read request", http.StatusBadRequest)
+			return
+		}
+		if err := verify(b.config.SigningSecret, r.Header, body, time.Now()); err != nil {
+			b.logger.Warn("Rejected Slack request", "error", err)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		var req envelope
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "invalid event", http.StatusBadRequest)
+			return
+		}
+		switch {
+		case req.Type == "url_verification":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = io.WriteString(w, req.Challenge)
+			return
+		case r.Header.Get("X-Slack-Retry-Num") != "":
+			// Already queued when Slack first sent it
+		case req.Type == "event_callback":
+			b.queue(req.Event)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// verify checks Slack's signature of a request: "v0=" and the hex
+// HMAC-SHA256 of "v0:", the timestamp, ":" and the body.
+func verify(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return fmt.Errorf("request timestamp is %s old", age.Round(time.Second))
+	}
+	if !hmac.Equal([]byte(header.Get("X-Slack-Signature")), []byte(sign(secret, timestamp, body))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// sign returns Slack's signature of a request body sent at timestamp.
+func sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// queue queues the games of a message for review: its SGF files and the
+// OGS games it links to. Messages from bots, including the bridge's own,
+// and from channels not configured are ignored.
+func (b *Bridge) queue(event messageEvent) {
+	if event.Type != "message" || event.BotID != "" || (event.Subtype != "" && event.Subtype != "file_share") {
+		return
+	}
+	if !b.watches(event.Channel) {
+		return
+	}
+	thread := event.ThreadTS
+	if thread == "" {
+		thread = event.TS
+	}
+
+	var jobs []job
+	for _, f := range event.Files {
+		if !strings.HasSuffix(strings.ToLower(f.Name), ".sgf") || f.DownloadURL == "" {
+			continue
+		}
+		url := f.DownloadURL
+		jobs = append(jobs, job{channel: event.Channel, thread: thread, name: f.Name,
+			fetch: func(ctx context.Context) (string, error) { return b.download(ctx, url, true) }})
+	}
+	for _, match := range ogsGameLink.FindAllStringSubmatch(event.Text, -1) {
+		url := b.ogsURL + "/api/v1/games/" + match[1] + "/sgf"
+		jobs = append(jobs, job{channel: event.Channel, thread: thread, name: "OGS game " + match[1],
+			fetch: func(ctx context.Context) (string, error) { return b.download(ctx, url, false) }})
+	}
+
+	for _, j := range jobs {
+		select {
+		case b.jobs <- j:
+			b.logger.Info("Queued game from Slack", "channel", j.channel, "game", j.name)
+		default:
+			b.mu.Lock()
+			b.dropped++
+			b.mu.Unlock()
+			b.logger.Warn("Slack review queue full, dropping game", "channel", j.channel, "game", j.name)
+		}
+	}
+}
+
+// watches reports whether games posted in a channel are reviewed.
+func (b *Bridge) watches(channel string) bool {
+	if len(b.config.Channels) == 0 {
+		return true
+	}
+	for _, c := range b.config.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// download fetches an SGF, with the bot token for Slack's private files.
+func (b *Bridge) download(ctx context.Context, url string, private bool) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	if private {
+		req.Header.Set("Authorization", "Bearer "+b.config.BotToken)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download failed: %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSGFBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxSGFBytes {
+		return "", fmt.Errorf("SGF is larger than %d bytes", maxSGFBytes)
+	}
+	return string(data), nil
+}
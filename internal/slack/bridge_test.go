@@ -0,0 +1,212 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+const (
+	testSecret = "signing-secret"
+	testToken  = "xoxb-test"
+	testGame   = "(;GM[1]SZ[9]KM[7]PB[Alice]PW[Bob];B[ee];W[cc];B[gg])"
+)
+
+// reviewEngine reviews every game the same way: one blunder by White.
+type reviewEngine struct {
+	*katago.MockEngine
+}
+
+func (reviewEngine) ReviewGame(ctx context.Context, sgf string, thresholds *katago.MistakeThresholds) (*katago.GameReview, error) {
+	return &katago.GameReview{
+		Summary: katago.ReviewSummary{TotalMoves: 3, BlackAccuracy: 100, WhiteAccuracy: 50, WhiteBlunders: 1},
+		Mistakes: []katago.Mistake{
+			{MoveNumber: 2, Color: "W", PlayedMove: "C7", BestMove: "G3", Category: "blunder", WinrateDrop: 0.3},
+		},
+		Winrates: []katago.WinratePoint{{MoveNumber: 0, Winrate: 0.5}, {MoveNumber: 1, Winrate: 0.55}, {MoveNumber: 2, Winrate: 0.85}},
+	}, nil
+}
+
+// fakeSlack stands in for Slack's Web API, file downloads and OGS,
+// reporting each API call made.
+func fakeSlack(t *testing.T) (*httptest.Server, chan url.Values) {
+	calls := make(chan url.Values, 16)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files/round1.sgf":
+			if r.Header.Get("Authorization") != "Bearer "+testToken {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = io.WriteString(w, testGame)
+		case "/api/v1/games/123/sgf":
+			_, _ = io.WriteString(w, testGame)
+		case "/upload":
+			data, _ := io.ReadAll(r.Body)
+			if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+				t.Errorf("Expected a PNG upload, got %d bytes", len(data))
+			}
+		default:
+			_ = r.ParseForm()
+			form := r.PostForm
+			form.Set("method", strings.TrimPrefix(r.URL.Path, "/"))
+			calls <- form
+			_, _ = fmt.Fprintf(w, `{"ok": true, "upload_url": %q, "file_id": "F1"}`, server.URL+"/upload")
+		}
+	}))
+	return server, calls
+}
+
+func startBridge(t *testing.T, cfg *config.SlackConfig, apiURL string) *Bridge {
+	t.Helper()
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	b := New(cfg, reviewEngine{engine}, logging.NewLoggerAdapter(logging.NewLogger("test: ", "error")))
+	b.apiURL, b.ogsURL = apiURL, apiURL
+	if err := b.Start(); err != nil {
+		t.Fatalf("Failed to start bridge: %v", err)
+	}
+	t.Cleanup(func() { _ = b.Stop(context.Background()) })
+	return b
+}
+
+// sendEvent posts a signed event to the bridge.
+func sendEvent(t *testing.T, b *Bridge, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, "http://"+b.Addr().String()+EventsPath, strings.NewReader(body))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", sign(testSecret, timestamp, []byte(body)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to send event: %v", err)
+	}
+	_ = resp.Body.Close()
+	return resp
+}
+
+func nextCall(t *testing.T, calls chan url.Values) url.Values {
+	t.Helper()
+	select {
+	case call := <-calls:
+		return call
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a Slack API call")
+		return nil
+	}
+}
+
+func TestBridgeReviewsPostedGames(t *testing.T) {
+	api, calls := fakeSlack(t)
+	defer api.Close()
+	b := startBridge(t, &config.SlackConfig{Addr: "127.0.0.1:0", BotToken: testToken, SigningSecret: testSecret, Channels: []string{"C1"}}, api.URL)
+
+	event, _ := json.Marshal(map[string]interface{}{
+		"type": "event_callback",
+		"event": map[string]interface{}{
+			"type": "message", "subtype": "file_share", "channel": "C1", "ts": "100.1",
+			"text":  "Please review, and <https://online-go.com/game/123>",
+			"files": []map[string]string{{"name": "round1.sgf", "url_private_download": api.URL + "/files/round1.sgf"}},
+		},
+	})
+	if resp := sendEvent(t, b, string(event)); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the event accepted, got %s", resp.Status)
+	}
+
+	// The file is answered with a summary and the winrate graph, then the
+	// linked game likewise
+	post := nextCall(t, calls)
+	if post.Get("method") != "chat.postMessage" || post.Get("channel") != "C1" || post.Get("thread_ts") != "100.1" ||
+		!strings.Contains(post.Get("text"), "*Review of round1.sgf*") ||
+		!strings.Contains(post.Get("text"), "• Move 2, W C7: blunder, 30.0% win rate lost; G3 was better") {
+		t.Errorf("Unexpected reply: %v", post)
+	}
+	if call := nextCall(t, calls); call.Get("method") != "files.getUploadURLExternal" || call.Get("filename") != "winrate.png" {
+		t.Errorf("Expected an upload URL requested, got %v", call)
+	}
+	if call := nextCall(t, calls); call.Get("method") != "files.completeUploadExternal" ||
+		call.Get("channel_id") != "C1" || !strings.Contains(call.Get("files"), `"id":"F1"`) {
+		t.Errorf("Expected the upload shared in the thread, got %v", call)
+	}
+	if post := nextCall(t, calls); !strings.Contains(post.Get("text"), "*Review of OGS game 123*") {
+		t.Errorf("Expected the linked game reviewed, got %v", post)
+	}
+	nextCall(t, calls)
+	nextCall(t, calls)
+
+	// Messages in other channels and from bots are ignored
+	for _, event := range []string{
+		`{"type": "event_callback", "event": {"type": "message", "channel": "C2", "ts": "1", "text": "https://online-go.com/game/123"}}`,
+		`{"type": "event_callback", "event": {"type": "message", "channel": "C1", "bot_id": "B1", "ts": "1", "text": "https://online-go.com/game/123"}}`,
+	} {
+		sendEvent(t, b, event)
+	}
+	select {
+	case call := <-calls:
+		t.Errorf("Expected no reply, got %v", call)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if status := b.GetStatus(); status["reviewed"] != 2 || status["failed"] != 0 {
+		t.Errorf("Unexpected status: %v", status)
+	}
+}
+
+func TestBridgeReportsFailures(t *testing.T) {
+	api, calls := fakeSlack(t)
+	defer api.Close()
+	b := startBridge(t, &config.SlackConfig{Addr: "127.0.0.1:0", BotToken: "wrong", SigningSecret: testSecret}, api.URL)
+
+	sendEvent(t, b, fmt.Sprintf(`{"type": "event_callback", "event": {"type": "message", "subtype": "file_share", "channel": "C1", "ts": "5.5", "thread_ts": "1.1",
+		"files": [{"name": "round1.sgf", "url_private_download": %q}]}}`, api.URL+"/files/round1.sgf"))
+	post := nextCall(t, calls)
+	if post.Get("thread_ts") != "1.1" || post.Get("text") != "Could not review round1.sgf: download failed: 403 Forbidden" {
+		t.Errorf("Expected the failure reported in the thread, got %v", post)
+	}
+	if status := b.GetStatus(); status["failed"] != 1 {
+		t.Errorf("Unexpected status: %v", status)
+	}
+}
+
+func TestBridgeVerifiesRequests(t *testing.T) {
+	b := New(&config.SlackConfig{SigningSecret: testSecret}, katago.NewMockEngine(), logging.NewLoggerAdapter(logging.NewLogger("test: ", "error")))
+	handler := b.Handler()
+	body := `{"type": "url_verification", "challenge": "abc123"}`
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	for _, tt := range []struct {
+		timestamp, signature string
+		want                 int
+	}{
+		{now, sign(testSecret, now, []byte(body)), http.StatusOK},
+		{now, sign("other", now, []byte(body)), http.StatusUnauthorized},
+		{stale, sign(testSecret, stale, []byte(body)), http.StatusUnauthorized},
+		{"", "", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodPost, EventsPath, strings.NewReader(body))
+		req.Header.Set("X-Slack-Request-Timestamp", tt.timestamp)
+		req.Header.Set("X-Slack-Signature", tt.signature)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("Expected %d for timestamp %q, got %d", tt.want, tt.timestamp, rec.Code)
+		}
+		if tt.want == http.StatusOK && rec.Body.String() != "abc123" {
+			t.Errorf("Expected the challenge echoed, got %q", rec.Body.String())
+		}
+	}
+}
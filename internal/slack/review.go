@@ -0,0 +1,211 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/report"
+)
+
+// summaryMistakes is the number of costliest mistakes listed in a reply.
+const summaryMistakes = 3
+
+// review reviews a queued game and answers in its thread with the summary
+// and winrate graph, or why it could not be reviewed.
+func (b *Bridge) review(ctx context.Context, j job) {
+	b.logger.Info("Reviewing game from Slack", "channel", j.channel, "game", j.name)
+	game, review, err := b.reviewGame(ctx, j)
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		b.recordFailure(j, err)
+		if postErr := b.postMessage(ctx, j, fmt.Sprintf("Could not review %s: %v", j.name, err)); postErr != nil {
+			b.logger.Warn("Failed to post to Slack", "channel", j.channel, "error", postErr)
+		}
+		return
+	}
+
+	if err := b.postMessage(ctx, j, summary(j.name, game, review)); err != nil {
+		b.recordFailure(j, err)
+		return
+	}
+	if len(review.Winrates) > 0 {
+		graph, err := report.GraphPNG(review)
+		if err == nil {
+			err = b.uploadFile(ctx, j, "winrate.png", "Black's win rate; red lines mark blunders, orange mistakes", graph)
+		}
+		if err != nil {
+			b.logger.Warn("Failed to post winrate graph to Slack", "channel", j.channel, "error", err)
+		}
+	}
+	b.mu.Lock()
+	b.reviewed++
+	b.mu.Unlock()
+}
+
+// reviewGame fetches and reviews a game.
+func (b *Bridge) reviewGame(ctx context.Context, j job) (*katago.Position, *katago.GameReview, error) {
+	sgf, err := j.fetch(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	game, err := katago.NewSGFParser(sgf).Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(game.Moves) == 0 {
+		return nil, nil, fmt.Errorf("the game has no moves")
+	}
+	if !b.engine.IsRunning() {
+		return nil, nil, fmt.Errorf("KataGo is not running")
+	}
+
+	thresholds := katago.DefaultMistakeThresholds()
+	if b.config.MaxVisits > 0 {
+		thresholds.MinimumVisits = b.config.MaxVisits
+	}
+	review, err := b.engine.ReviewGame(katago.WithLane(ctx, config.LaneBatch), sgf, thresholds)
+	if err != nil {
+		return nil, nil, err
+	}
+	return game, review, nil
+}
+
+// recordFailure counts a game that could not be reviewed or answered.
+func (b *Bridge) recordFailure(j job, err error) {
+	b.mu.Lock()
+	b.failed++
+	b.lastErr = fmt.Sprintf("%s: %v", j.name, err)
+	b.mu.Unlock()
+	b.logger.Warn("Failed to review game from Slack", "channel", j.channel, "game", j.name, "error", err)
+}
+
+// summary describes a review in Slack's markup: the players' accuracy and
+// errors, and the costliest mistakes.
+func summary(name string, game *katago.Position, review *katago.GameReview) string {
+	s := review.Summary
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("*Review of %s*", name))
+	if game.GameInfo.Known() {
+		sb.WriteString(fmt.Sprintf(": %s", game.GameInfo))
+	}
+	sb.WriteString("\n")
+	if s.Partial {
+		sb.WriteString(fmt.Sprintf("_Partial review: %d of %d moves analyzed_\n", s.AnalyzedMoves, s.TotalMoves))
+	}
+	sb.WriteString(fmt.Sprintf("Black: %.1f%% accuracy, %d mistakes, %d blunders\n", s.BlackAccuracy, s.BlackMistakes, s.BlackBlunders))
+	sb.WriteString(fmt.Sprintf("White: %.1f%% accuracy, %d mistakes, %d blunders\n", s.WhiteAccuracy, s.WhiteMistakes, s.WhiteBlunders))
+	if s.EstimatedLevel != "" {
+		sb.WriteString(fmt.Sprintf("Estimated level: %s\n", s.EstimatedLevel))
+	}
+
+	mistakes := append([]katago.Mistake(nil), review.Mistakes...)
+	sort.SliceStable(mistakes, func(i, j int) bool { return mistakes[i].WinrateDrop > mistakes[j].WinrateDrop })
+	if len(mistakes) > summaryMistakes {
+		mistakes = mistakes[:summaryMistakes]
+	}
+	if len(mistakes) == 0 {
+		sb.WriteString("No significant mistakes found.\n")
+	} else {
+		sb.WriteString("Costliest mistakes:\n")
+		for _, m := range mistakes {
+			played := m.PlayedMove
+			if played == "" {
+				played = "pass"
+			}
+			sb.WriteString(fmt.Sprintf("• Move %d, %s %s: %s, %.1f%% win rate lost; %s was better\n",
+				m.MoveNumber, m.Color, played, m.Category, m.WinrateDrop*100, m.BestMove))
+		}
+	}
+	return sb.String()
+}
+
+// apiResponse is the envelope of every Slack Web API response.
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	// Set by files.getUploadURLExternal
+	UploadURL string `json:"upload_url"`
+	FileID    string `json:"file_id"`
+}
+
+// call calls a Slack Web API method with form arguments.
+func (b *Bridge) call(ctx context.Context, method string, args url.Values) (*apiResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.apiURL+"/"+method, strings.NewReader(args.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+b.config.BotToken)
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var result apiResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxEventBytes)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("%s: invalid response (%s): %w", method, resp.Status, err)
+	}
+	if !result.OK {
+		return nil, fmt.Errorf("%s: %s", method, result.Error)
+	}
+	return &result, nil
+}
+
+// postMessage answers in a job's thread.
+func (b *Bridge) postMessage(ctx context.Context, j job, text string) error {
+	_, err := b.call(ctx, "chat.postMessage", url.Values{
+		"channel":   {j.channel},
+		"thread_ts": {j.thread},
+		"text":      {text},
+	})
+	return err
+}
+
+// uploadFile shares a file in a job's thread through Slack's external
+// upload flow: reserve an upload URL, send the bytes, then complete the
+// upload into the channel.
+func (b *Bridge) uploadFile(ctx context.Context, j job, filename, title string, data []byte) error {
+	upload, err := b.call(ctx, "files.getUploadURLExternal", url.Values{
+		"filename": {filename},
+		"length":   {strconv.Itoa(len(data))},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, upload.UploadURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("file upload failed: %s", resp.Status)
+	}
+
+	files, err := json.Marshal([]map[string]string{{"id": upload.FileID, "title": title}})
+	if err != nil {
+		return err
+	}
+	_, err = b.call(ctx, "files.completeUploadExternal", url.Values{
+		"files":      {string(files)},
+		"channel_id": {j.channel},
+		"thread_ts":  {j.thread},
+	})
+	return err
+}
@@ -0,0 +1,105 @@
+// Package tracing provides OpenTelemetry tracing for MCP tool calls and
+// KataGo engine queries.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies spans created by this server.
+const tracerName = "github.com/dmmcquay/katago-mcp"
+
+// Attribute keys for correlation with structured logs.
+const (
+	CorrelationIDKey = attribute.Key("katago_mcp.correlation_id")
+	RequestIDKey     = attribute.Key("katago_mcp.request_id")
+)
+
+// Setup installs a global tracer provider exporting spans over OTLP/HTTP.
+// When tracing is disabled the global no-op provider is left in place.
+// The returned function flushes and shuts down the provider.
+func Setup(ctx context.Context, cfg *config.TracingConfig, serviceName, version string) (func(context.Context) error, error) {
+	if cfg == nil || !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	opts := []otlptracehttp.Option{}
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a span using the global tracer provider. Correlation and
+// request IDs in the context are recorded as span attributes.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+	AnnotateSpan(ctx)
+	return ctx, span
+}
+
+// AnnotateSpan records correlation and request IDs from the context on the
+// current span. Call it after adding IDs to a context with an active span.
+func AnnotateSpan(ctx context.Context) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	if id, ok := logging.CorrelationIDFromContext(ctx); ok {
+		span.SetAttributes(CorrelationIDKey.String(id))
+	}
+	if id, ok := logging.RequestIDFromContext(ctx); ok {
+		span.SetAttributes(RequestIDKey.String(id))
+	}
+}
+
+// RecordError marks the span as failed when err is non-nil.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
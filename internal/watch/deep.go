@@ -0,0 +1,227 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+// Unflagged is the verdict of a move that a review did not flag.
+const Unflagged = "unflagged"
+
+// errGameChanged reports a game modified during its deep review.
+var errGameChanged = errors.New("game changed during its deep review")
+
+// VerdictChange is a move whose verdict a deep review changed, such as a
+// mistake that deeper analysis vindicates.
+type VerdictChange struct {
+	MoveNumber int    `json:"moveNumber"`
+	Color      string `json:"color"`
+	Move       string `json:"move"`
+	Before     string `json:"before"` // "blunder", "mistake", "inaccuracy" or Unflagged
+	After      string `json:"after"`
+}
+
+// deepenScheduled deepens the next game if now is within the deep review
+// window, stopping the review when the window closes.
+func (w *Watcher) deepenScheduled(ctx context.Context, now time.Time) {
+	if !inWindow(now, w.deepStart, w.deepEnd) {
+		return
+	}
+	ctx, cancel := context.WithDeadline(ctx, windowEnd(now, w.deepEnd))
+	defer cancel()
+	w.DeepenNext(ctx)
+}
+
+// inWindow reports whether t falls within a daily window given in minutes
+// after midnight, which spans midnight when end is before start.
+func inWindow(t time.Time, start, end int) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// windowEnd returns the first time after t that is end minutes after
+// midnight.
+func windowEnd(t time.Time, end int) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	at := midnight.Add(time.Duration(end) * time.Minute)
+	if !at.After(t) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
+
+// DeepenNext reviews the first game, by name, whose review is up to date
+// but below the deep visits again at those visits, and reports whether it
+// did. Games that fail are not retried until they change.
+func (w *Watcher) DeepenNext(ctx context.Context) bool {
+	if !w.engine.IsRunning() {
+		return false
+	}
+	path, previous := w.nextToDeepen()
+	if path == "" {
+		return false
+	}
+
+	w.mu.Lock()
+	w.current = filepath.Base(path)
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.current = ""
+		w.mu.Unlock()
+	}()
+
+	state := w.stateOf(path)
+	start := time.Now()
+	w.logger.Info("Deepening watched game", "file", path, "visits", w.config.Deep.MaxVisits)
+	result, err := w.deepenFile(ctx, path, state, previous)
+	if ctx.Err() != nil {
+		// The window closed or the watcher stopped; try again next window
+		return false
+	}
+	if errors.Is(err, errGameChanged) {
+		// The next scan reviews the new version
+		return false
+	}
+	w.notify(path, result, true, err)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.errors++
+		w.lastErr = fmt.Sprintf("%s: %v", filepath.Base(path), err)
+		w.failed[path] = state
+		w.logger.Warn("Failed to deepen watched game", "file", path, "error", err)
+		return false
+	}
+	w.deepened++
+	w.changed += len(result.Changes)
+	if len(result.Changes) > 0 {
+		w.logger.Info("Deep review changed verdicts", "file", path, "changes", len(result.Changes))
+	}
+	w.logger.Info("Watched game deepened", "file", path, "duration", time.Since(start).String())
+	return true
+}
+
+// nextToDeepen returns the first game with an up-to-date review at fewer
+// than the deep visits, and that review.
+func (w *Watcher) nextToDeepen() (string, *Review) {
+	entries, err := os.ReadDir(w.config.Dir)
+	if err != nil {
+		w.logger.Warn("Failed to read watch directory", "dir", w.config.Dir, "error", err)
+		return "", nil
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !isGame(name) {
+			continue
+		}
+		path := filepath.Join(w.config.Dir, name)
+		state := w.stateOf(path)
+		w.mu.Lock()
+		failed, ok := w.failed[path]
+		w.mu.Unlock()
+		if (ok && failed == state) || !upToDate(path, state.modTime) {
+			continue
+		}
+		data, err := os.ReadFile(outputBase(path) + ReviewSuffix) // #nosec G304 -- files in the configured watch directory
+		if err != nil {
+			continue
+		}
+		var review Review
+		if err := json.Unmarshal(data, &review); err != nil || review.GameReview == nil {
+			continue
+		}
+		if review.DeepVisits < w.config.Deep.MaxVisits {
+			return path, &review
+		}
+	}
+	return "", nil
+}
+
+// deepenFile reviews a game again at the deep visits and replaces its
+// results, returning the new review with the verdicts that changed from
+// previous. The results are discarded if the game changed meanwhile.
+func (w *Watcher) deepenFile(ctx context.Context, path string, state fileState, previous *Review) (*Review, error) {
+	ctx = katago.WithLane(ctx, config.LaneBackground)
+
+	data, err := os.ReadFile(path) // #nosec G304 -- files in the configured watch directory
+	if err != nil {
+		return nil, err
+	}
+	sgf := string(data)
+	thresholds := katago.DefaultMistakeThresholds()
+	thresholds.Concurrency = w.concurrency
+	thresholds.MinimumVisits = w.config.Deep.MaxVisits
+	review, err := w.engine.ReviewGame(ctx, sgf, thresholds)
+	if err != nil {
+		return nil, err
+	}
+	if review.Summary.Partial {
+		return nil, apperrors.New(apperrors.CodeTimeout, "deep review stopped after %d of %d moves",
+			review.Summary.AnalyzedMoves, review.Summary.TotalMoves)
+	}
+	if w.stateOf(path) != state {
+		return nil, errGameChanged
+	}
+
+	result := &Review{
+		Source:      previous.Source,
+		ReviewedAt:  time.Now().UTC(),
+		PlayerBlack: previous.PlayerBlack,
+		PlayerWhite: previous.PlayerWhite,
+		DeepVisits:  w.config.Deep.MaxVisits,
+		Changes:     verdictChanges(previous.GameReview, review),
+		GameReview:  review,
+	}
+	return result, writeResults(path, sgf, result)
+}
+
+// verdictChanges lists, by move number, the moves that before and after
+// flag differently.
+func verdictChanges(before, after *katago.GameReview) []VerdictChange {
+	flagged := func(review *katago.GameReview) map[int]katago.Mistake {
+		moves := make(map[int]katago.Mistake, len(review.Mistakes))
+		for _, m := range review.Mistakes {
+			moves[m.MoveNumber] = m
+		}
+		return moves
+	}
+	old, deep := flagged(before), flagged(after)
+
+	var changes []VerdictChange
+	seen := make(map[int]bool)
+	for _, moves := range []map[int]katago.Mistake{old, deep} {
+		for number, m := range moves {
+			if seen[number] {
+				continue
+			}
+			seen[number] = true
+			change := VerdictChange{MoveNumber: number, Color: m.Color, Move: m.PlayedMove, Before: Unflagged, After: Unflagged}
+			if o, ok := old[number]; ok {
+				change.Before = o.Category
+			}
+			if d, ok := deep[number]; ok {
+				change.After = d.Category
+			}
+			if change.Before != change.After {
+				changes = append(changes, change)
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].MoveNumber < changes[j].MoveNumber })
+	return changes
+}
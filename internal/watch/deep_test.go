@@ -0,0 +1,110 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+)
+
+func TestDeepenNext(t *testing.T) {
+	w, _, dir := newTestWatcher(t)
+	w.config.Deep.Enabled = true
+	w.config.Deep.MaxVisits = 1000
+	writeGame(t, filepath.Join(dir, "game.sgf"), testGame)
+
+	ctx := context.Background()
+	if w.DeepenNext(ctx) {
+		t.Fatal("Expected a game without a review not to be deepened")
+	}
+	w.Scan(ctx)
+	if n := w.Scan(ctx); n != 1 {
+		t.Fatalf("Expected one game reviewed, got %d", n)
+	}
+
+	// Pretend the first review flagged White's move, which the deep review
+	// of the mock engine does not
+	reviewPath := filepath.Join(dir, "game"+ReviewSuffix)
+	review := readReview(t, reviewPath)
+	review.Mistakes = []katago.Mistake{{MoveNumber: 2, Color: "W", PlayedMove: "C7", Category: "mistake"}}
+	data, err := json.Marshal(review)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeGame(t, reviewPath, string(data))
+
+	if !w.DeepenNext(ctx) {
+		t.Fatal("Expected the game to be deepened")
+	}
+	deep := readReview(t, reviewPath)
+	want := VerdictChange{MoveNumber: 2, Color: "W", Move: "C7", Before: "mistake", After: Unflagged}
+	if deep.DeepVisits != 1000 || len(deep.Changes) != 1 || deep.Changes[0] != want || deep.PlayerBlack != "Alice" {
+		t.Errorf("Unexpected deep review: %+v", deep)
+	}
+
+	if w.DeepenNext(ctx) {
+		t.Error("Expected a deepened game not to be deepened again")
+	}
+	if n := w.Scan(ctx); n != 0 {
+		t.Errorf("Expected the deep review to keep the game up to date, reviewed %d", n)
+	}
+	status := w.GetStatus()["deep"].(map[string]interface{})
+	if status["deepened"] != 1 || status["changedVerdicts"] != 1 {
+		t.Errorf("Unexpected deep status: %v", status)
+	}
+
+	// Raising the visits deepens it again
+	w.config.Deep.MaxVisits = 2000
+	if !w.DeepenNext(ctx) {
+		t.Error("Expected the game to be deepened at more visits")
+	}
+}
+
+func readReview(t *testing.T, path string) *Review {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var review Review
+	if err := json.Unmarshal(data, &review); err != nil {
+		t.Fatalf("Invalid review file: %v", err)
+	}
+	return &review
+}
+
+func TestWindow(t *testing.T) {
+	at := func(clock string) time.Time {
+		parsed, err := time.Parse("15:04", clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(2025, 3, 1, parsed.Hour(), parsed.Minute(), 0, 0, time.Local)
+	}
+	tests := []struct {
+		start, end int
+		now        string
+		in         bool
+		until      string
+	}{
+		{60, 360, "00:59", false, "06:00"},
+		{60, 360, "01:00", true, "06:00"},
+		{60, 360, "06:00", false, "06:00"},
+		{1380, 300, "23:30", true, "05:00"},
+		{1380, 300, "04:59", true, "05:00"},
+		{1380, 300, "12:00", false, "05:00"},
+	}
+	for _, tt := range tests {
+		now := at(tt.now)
+		if got := inWindow(now, tt.start, tt.end); got != tt.in {
+			t.Errorf("%d-%d at %s: expected in window %v", tt.start, tt.end, tt.now, tt.in)
+		}
+		if got := windowEnd(now, tt.end); !got.After(now) || got.Format("15:04") != tt.until || got.Sub(now) > 24*time.Hour {
+			t.Errorf("%d-%d at %s: unexpected window end %s", tt.start, tt.end, tt.now, got)
+		}
+	}
+}
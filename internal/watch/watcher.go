@@ -0,0 +1,397 @@
+// Package watch reviews SGF files dropped into a directory, writing the
+// results alongside each game.
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/apperrors"
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/fsutil"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/webhook"
+)
+
+// Suffixes of the files written next to a reviewed game.
+const (
+	ReviewSuffix    = ".review.json"
+	AnnotatedSuffix = ".annotated.sgf"
+)
+
+// Review is the content of a .review.json file.
+type Review struct {
+	Source      string    `json:"source"`
+	ReviewedAt  time.Time `json:"reviewedAt"`
+	PlayerBlack string    `json:"playerBlack,omitempty"`
+	PlayerWhite string    `json:"playerWhite,omitempty"`
+	// DeepVisits is set once the game has had a deep review, and Changes
+	// lists the moves whose verdict it changed
+	DeepVisits int             `json:"deepVisits,omitempty"`
+	Changes    []VerdictChange `json:"changes,omitempty"`
+	*katago.GameReview
+}
+
+// fileState identifies a version of a file.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher scans a directory for new or changed SGF files and reviews
+// them one at a time.
+type Watcher struct {
+	config *config.WatchConfig
+	engine katago.EngineInterface
+	logger logging.ContextLogger
+	// Positions of a game analyzed at once
+	concurrency int
+	// notifier is told when reviews end, nil without webhooks
+	notifier *webhook.Notifier
+
+	mu sync.Mutex
+	// Games seen on the previous scan, reviewed once they stop changing
+	pending map[string]fileState
+	// Games whose review failed, not retried until they change
+	failed   map[string]fileState
+	current  string
+	reviewed int
+	errors   int
+	lastErr  string
+	// Deep review window in minutes after local midnight, and its results
+	deepStart, deepEnd int
+	deepened           int
+	changed            int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// New creates a watcher that reviews games with engine.
+func New(cfg *config.WatchConfig, engine katago.EngineInterface, logger logging.ContextLogger) *Watcher {
+	return &Watcher{
+		config:  cfg,
+		engine:  engine,
+		logger:  logger,
+		pending: make(map[string]fileState),
+		failed:  make(map[string]fileState),
+		done:    make(chan struct{}),
+	}
+}
+
+// SetReviewConcurrency sets the number of positions of a game analyzed at
+// once. Must be called before Start.
+func (w *Watcher) SetReviewConcurrency(n int) {
+	w.concurrency = n
+}
+
+// SetNotifier sets the webhooks notified as reviews finish or fail. Must
+// be called before Start.
+func (w *Watcher) SetNotifier(notifier *webhook.Notifier) {
+	w.notifier = notifier
+}
+
+// Enabled reports whether a directory is configured.
+func (w *Watcher) Enabled() bool {
+	return w.config.Dir != ""
+}
+
+// Start scans the directory every configured interval until Stop is
+// called, deepening one reviewed game after each scan during the deep
+// review window. It is a no-op when no directory is configured.
+func (w *Watcher) Start() error {
+	if !w.Enabled() {
+		return nil
+	}
+	info, err := os.Stat(w.config.Dir)
+	if err != nil {
+		return fmt.Errorf("watch directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("watch directory %s is not a directory", w.config.Dir)
+	}
+	if w.config.Deep.Enabled {
+		if w.deepStart, w.deepEnd, err = w.config.Deep.Window(); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	interval := time.Duration(w.config.IntervalSeconds) * time.Second
+	go func() {
+		defer close(w.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			w.Scan(ctx)
+			if w.config.Deep.Enabled {
+				w.deepenScheduled(ctx, time.Now())
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop cancels any review in progress and waits for the watcher to exit.
+// It is safe to call more than once.
+func (w *Watcher) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.once.Do(w.cancel)
+	<-w.done
+}
+
+// Scan reviews every game that has not changed since the previous scan
+// and has no up-to-date review, and returns how many it reviewed. New or
+// changed games are reviewed on the next scan, so files still being
+// copied in are not read half-written.
+func (w *Watcher) Scan(ctx context.Context) int {
+	entries, err := os.ReadDir(w.config.Dir)
+	if err != nil {
+		w.logger.Warn("Failed to read watch directory", "dir", w.config.Dir, "error", err)
+		return 0
+	}
+
+	var ready []string
+	seen := make(map[string]fileState)
+	w.mu.Lock()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !isGame(name) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+		path := filepath.Join(w.config.Dir, name)
+		if failed, ok := w.failed[path]; ok && failed == state {
+			continue
+		}
+		if upToDate(path, state.modTime) {
+			continue
+		}
+		if previous, ok := w.pending[path]; ok && previous == state {
+			ready = append(ready, path)
+			continue
+		}
+		seen[path] = state
+	}
+	w.mu.Unlock()
+
+	sort.Strings(ready)
+	reviewed := 0
+	for i, path := range ready {
+		if ctx.Err() != nil {
+			break
+		}
+		if !w.engine.IsRunning() {
+			// Leave the rest for a later scan rather than failing them
+			w.logger.Debug("Engine not running, postponing watched reviews", "pending", len(ready)-i)
+			for _, rest := range ready[i:] {
+				seen[rest] = w.stateOf(rest)
+			}
+			break
+		}
+		if w.review(ctx, path) {
+			reviewed++
+		}
+	}
+
+	w.mu.Lock()
+	w.pending = seen
+	w.mu.Unlock()
+	return reviewed
+}
+
+// stateOf returns the current state of a file.
+func (w *Watcher) stateOf(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{size: info.Size(), modTime: info.ModTime()}
+}
+
+// review reviews one game and writes its results, reporting whether it
+// succeeded.
+func (w *Watcher) review(ctx context.Context, path string) bool {
+	w.mu.Lock()
+	w.current = filepath.Base(path)
+	w.mu.Unlock()
+	defer func() {
+		w.mu.Lock()
+		w.current = ""
+		w.mu.Unlock()
+	}()
+
+	state := w.stateOf(path)
+	start := time.Now()
+	w.logger.Info("Reviewing watched game", "file", path)
+	result, err := w.reviewFile(ctx, path)
+	if ctx.Err() != nil {
+		// Interrupted by shutdown; the game is reviewed again next time
+		return false
+	}
+	w.notify(path, result, false, err)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		w.errors++
+		w.lastErr = fmt.Sprintf("%s: %v", filepath.Base(path), err)
+		w.failed[path] = state
+		w.logger.Warn("Failed to review watched game", "file", path, "error", err)
+		return false
+	}
+	delete(w.failed, path)
+	w.reviewed++
+	w.logger.Info("Watched game reviewed", "file", path, "duration", time.Since(start).String())
+	return true
+}
+
+// reviewFile writes the review and annotated game for an SGF file, and
+// returns the review. The review is written last, since its presence marks
+// the game as done.
+func (w *Watcher) reviewFile(ctx context.Context, path string) (*Review, error) {
+	// Nobody is waiting on the review, so it yields KataGo to tool calls
+	ctx = katago.WithLane(ctx, config.LaneBackground)
+
+	data, err := os.ReadFile(path) // #nosec G304 -- files in the configured watch directory
+	if err != nil {
+		return nil, err
+	}
+	sgf := string(data)
+	game, err := katago.NewSGFParser(sgf).Parse()
+	if err != nil {
+		return nil, err
+	}
+	if len(game.Moves) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidSGF, "game has no moves")
+	}
+
+	thresholds := katago.DefaultMistakeThresholds()
+	thresholds.Concurrency = w.concurrency
+	if w.config.MaxVisits > 0 {
+		thresholds.MinimumVisits = w.config.MaxVisits
+	}
+	review, err := w.engine.ReviewGame(ctx, sgf, thresholds)
+	if err != nil {
+		return nil, err
+	}
+	if review.Summary.Partial {
+		return nil, apperrors.New(apperrors.CodeTimeout, "review stopped after %d of %d moves",
+			review.Summary.AnalyzedMoves, review.Summary.TotalMoves)
+	}
+
+	result := &Review{
+		Source:      filepath.Base(path),
+		ReviewedAt:  time.Now().UTC(),
+		PlayerBlack: game.PlayerBlack,
+		PlayerWhite: game.PlayerWhite,
+		GameReview:  review,
+	}
+	return result, writeResults(path, sgf, result)
+}
+
+// notify tells the webhooks that the review of a game, deep or not, ended
+// with result or err.
+func (w *Watcher) notify(path string, result *Review, deep bool, err error) {
+	if w.notifier == nil {
+		return
+	}
+	event := webhook.Event{Source: filepath.Base(path), Deep: deep}
+	if err != nil {
+		event.Event = config.EventReviewFailed
+		event.Error = err.Error()
+	} else {
+		event.Event = config.EventReviewCompleted
+		event.ReviewFile = filepath.Base(outputBase(path) + ReviewSuffix)
+		event.PlayerBlack, event.PlayerWhite = result.PlayerBlack, result.PlayerWhite
+		event.Summary = &result.Summary
+		event.ChangedVerdicts = len(result.Changes)
+	}
+	w.notifier.Notify(event)
+}
+
+// writeResults writes the annotated game and then the review of a game.
+func writeResults(path, sgf string, result *Review) error {
+	annotated, err := katago.AnnotateSGF(sgf, result.GameReview)
+	if err != nil {
+		return err
+	}
+	base := outputBase(path)
+	if err := fsutil.WriteFileAtomic(base+AnnotatedSuffix, []byte(annotated)); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(base+ReviewSuffix, append(data, '\n'))
+}
+
+// GetStatus returns watcher statistics for health responses.
+func (w *Watcher) GetStatus() map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status := map[string]interface{}{
+		"enabled":  w.Enabled(),
+		"dir":      w.config.Dir,
+		"reviewed": w.reviewed,
+		"failed":   w.errors,
+		"pending":  len(w.pending),
+	}
+	if w.current != "" {
+		status["current"] = w.current
+	}
+	if w.lastErr != "" {
+		status["lastError"] = w.lastErr
+	}
+	if w.config.Deep.Enabled {
+		status["deep"] = map[string]interface{}{
+			"window":          w.config.Deep.Start + "-" + w.config.Deep.End,
+			"deepened":        w.deepened,
+			"changedVerdicts": w.changed,
+		}
+	}
+	return status
+}
+
+// isGame reports whether a file name is an SGF game to review, skipping
+// hidden files and the watcher's own output.
+func isGame(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".sgf") && !strings.HasSuffix(lower, AnnotatedSuffix) &&
+		!strings.HasPrefix(name, ".")
+}
+
+// outputBase returns the path of a game without its extension.
+func outputBase(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
+
+// upToDate reports whether a game has a review at least as new as itself.
+func upToDate(path string, modTime time.Time) bool {
+	info, err := os.Stat(outputBase(path) + ReviewSuffix)
+	return err == nil && !info.ModTime().Before(modTime)
+}
@@ -0,0 +1,213 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/webhook"
+)
+
+const testGame = "(;GM[1]SZ[9]KM[7]PB[Alice]PW[Bob];B[ee];W[cc];B[gg])"
+
+func newTestWatcher(t *testing.T) (*Watcher, *katago.MockEngine, string) {
+	t.Helper()
+	dir := t.TempDir()
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+	engine.SetRunning(true)
+	return New(&config.WatchConfig{Dir: dir, IntervalSeconds: 1}, engine, logger), engine, dir
+}
+
+func writeGame(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScanReviewsSettledGames(t *testing.T) {
+	w, _, dir := newTestWatcher(t)
+	game := filepath.Join(dir, "round1.sgf")
+	writeGame(t, game, testGame)
+	writeGame(t, filepath.Join(dir, ".partial.sgf"), testGame)
+	writeGame(t, filepath.Join(dir, "notes.txt"), "not a game")
+
+	ctx := context.Background()
+	if n := w.Scan(ctx); n != 0 {
+		t.Fatalf("Expected a new game to wait for the next scan, reviewed %d", n)
+	}
+	if n := w.Scan(ctx); n != 1 {
+		t.Fatalf("Expected one game reviewed, got %d", n)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "round1"+ReviewSuffix))
+	if err != nil {
+		t.Fatalf("Expected review file: %v", err)
+	}
+	var review Review
+	if err := json.Unmarshal(data, &review); err != nil {
+		t.Fatalf("Invalid review file: %v", err)
+	}
+	if review.Source != "round1.sgf" || review.PlayerBlack != "Alice" || review.GameReview == nil {
+		t.Errorf("Unexpected review: %s", data)
+	}
+	annotated, err := os.ReadFile(filepath.Join(dir, "round1"+AnnotatedSuffix))
+	if err != nil || !strings.Contains(string(annotated), "KataGo review") {
+		t.Errorf("Expected annotated game, got %q (%v)", annotated, err)
+	}
+
+	// Reviewed games, hidden files and the watcher's own output are skipped
+	w.Scan(ctx)
+	if n := w.Scan(ctx); n != 0 {
+		t.Errorf("Expected nothing left to review, reviewed %d", n)
+	}
+	if status := w.GetStatus(); status["reviewed"] != 1 || status["failed"] != 0 {
+		t.Errorf("Unexpected status: %v", status)
+	}
+
+	// A changed game is reviewed again
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(game, later, later); err != nil {
+		t.Fatal(err)
+	}
+	w.Scan(ctx)
+	if n := w.Scan(ctx); n != 1 {
+		t.Errorf("Expected changed game to be reviewed again, reviewed %d", n)
+	}
+}
+
+func TestScanRecordsFailures(t *testing.T) {
+	w, _, dir := newTestWatcher(t)
+	game := filepath.Join(dir, "broken.sgf")
+	writeGame(t, game, "(;GM[1]C[unclosed")
+
+	ctx := context.Background()
+	w.Scan(ctx)
+	if n := w.Scan(ctx); n != 0 {
+		t.Fatalf("Expected broken game not to be reviewed, reviewed %d", n)
+	}
+	status := w.GetStatus()
+	if status["failed"] != 1 || !strings.Contains(status["lastError"].(string), "broken.sgf") {
+		t.Errorf("Unexpected status: %v", status)
+	}
+
+	// A failed game is not retried until it changes
+	w.Scan(ctx)
+	w.Scan(ctx)
+	if status := w.GetStatus(); status["failed"] != 1 {
+		t.Errorf("Expected failed game not to be retried, got %v", status)
+	}
+
+	writeGame(t, game, testGame)
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(game, later, later); err != nil {
+		t.Fatal(err)
+	}
+	w.Scan(ctx)
+	if n := w.Scan(ctx); n != 1 {
+		t.Errorf("Expected fixed game to be reviewed, reviewed %d", n)
+	}
+}
+
+func TestScanNotifiesWebhooks(t *testing.T) {
+	events := make(chan webhook.Event, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event webhook.Event
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &event)
+		events <- event
+	}))
+	defer server.Close()
+
+	w, _, dir := newTestWatcher(t)
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "error"))
+	notifier := webhook.New([]config.WebhookConfig{{URL: server.URL, TimeoutSeconds: 5}}, logger)
+	w.SetNotifier(notifier)
+	writeGame(t, filepath.Join(dir, "a.sgf"), testGame)
+	writeGame(t, filepath.Join(dir, "b.sgf"), "(;GM[1]C[unclosed")
+
+	ctx := context.Background()
+	w.Scan(ctx)
+	w.Scan(ctx)
+	if err := notifier.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	close(events)
+	bySource := make(map[string]webhook.Event)
+	for event := range events {
+		bySource[event.Source] = event
+	}
+	if e := bySource["a.sgf"]; e.Event != config.EventReviewCompleted || e.ReviewFile != "a"+ReviewSuffix ||
+		e.PlayerBlack != "Alice" || e.Summary == nil {
+		t.Errorf("Unexpected completion event: %+v", e)
+	}
+	if e := bySource["b.sgf"]; e.Event != config.EventReviewFailed || e.Error == "" || e.Summary != nil {
+		t.Errorf("Unexpected failure event: %+v", e)
+	}
+}
+
+func TestScanWaitsForEngine(t *testing.T) {
+	w, engine, dir := newTestWatcher(t)
+	writeGame(t, filepath.Join(dir, "game.sgf"), testGame)
+	engine.SetRunning(false)
+
+	ctx := context.Background()
+	w.Scan(ctx)
+	if n := w.Scan(ctx); n != 0 {
+		t.Fatalf("Expected no review while the engine is down, reviewed %d", n)
+	}
+	if status := w.GetStatus(); status["failed"] != 0 {
+		t.Errorf("Expected postponed game not to count as failed, got %v", status)
+	}
+
+	engine.SetRunning(true)
+	if n := w.Scan(ctx); n != 1 {
+		t.Errorf("Expected game to be reviewed once the engine is back, reviewed %d", n)
+	}
+}
+
+func TestStartAndStop(t *testing.T) {
+	w, _, dir := newTestWatcher(t)
+	writeGame(t, filepath.Join(dir, "game.sgf"), testGame)
+	if err := w.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer w.Stop()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(filepath.Join(dir, "game"+ReviewSuffix)); err == nil {
+			w.Stop()
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("Expected watcher to review the game")
+}
+
+func TestStartErrors(t *testing.T) {
+	logger := logging.NewLoggerAdapter(logging.NewLogger("test: ", "debug"))
+	engine := katago.NewMockEngine()
+
+	disabled := New(&config.WatchConfig{}, engine, logger)
+	if err := disabled.Start(); err != nil {
+		t.Errorf("Expected disabled watcher to start, got %v", err)
+	}
+	disabled.Stop()
+
+	missing := New(&config.WatchConfig{Dir: filepath.Join(t.TempDir(), "missing"), IntervalSeconds: 1}, engine, logger)
+	if err := missing.Start(); err == nil {
+		t.Error("Expected error for a missing directory")
+	}
+}
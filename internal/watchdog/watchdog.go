@@ -0,0 +1,138 @@
+// Package watchdog shuts the server down when the MCP client that started
+// it goes away. A client that closes the server's stdin is noticed when
+// the stdio transport returns; one that dies without closing it, such as
+// when the pipe is shared with another process, is noticed when the
+// server is reparented.
+package watchdog
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// Watchdog calls its exit function once, the first time the MCP client is
+// found gone.
+type Watchdog struct {
+	config   *config.WatchdogConfig
+	logger   logging.ContextLogger
+	onExit   func(reason string)
+	getppid  func() int
+	interval time.Duration
+
+	parent int
+	stop   chan struct{}
+	done   chan struct{}
+
+	mu     sync.Mutex
+	exited bool
+	reason string
+}
+
+// New creates a watchdog that calls onExit with the reason when the MCP
+// client goes away. onExit runs on the watchdog's goroutine, so it must
+// not wait for Stop.
+func New(cfg *config.WatchdogConfig, logger logging.ContextLogger, onExit func(reason string)) *Watchdog {
+	return &Watchdog{
+		config:   cfg,
+		logger:   logger,
+		onExit:   onExit,
+		getppid:  os.Getppid,
+		interval: time.Duration(cfg.IntervalSeconds) * time.Second,
+	}
+}
+
+// Enabled reports whether the server shuts down when its client goes away.
+func (w *Watchdog) Enabled() bool {
+	return w.config.Enabled
+}
+
+// Start watches the parent process until Stop is called. It is a no-op when
+// the watchdog is disabled, or when the parent is already init, as for a
+// server started by a service manager or container runtime, since there is
+// no client process to outlive.
+func (w *Watchdog) Start() {
+	if !w.Enabled() {
+		return
+	}
+	w.parent = w.getppid()
+	if w.parent <= 1 {
+		w.logger.Debug("No parent process to watch", "ppid", w.parent)
+		return
+	}
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+	go w.run()
+}
+
+// run checks the parent process every interval. Once it exits, the server
+// is reparented and its parent PID changes.
+func (w *Watchdog) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if ppid := w.getppid(); ppid != w.parent {
+				w.logger.Info("Parent process exited", "parent", w.parent, "ppid", ppid)
+				w.exit("parent process exited")
+				return
+			}
+		}
+	}
+}
+
+// StdioClosed reports that the stdio transport returned because the client
+// closed stdin. It returns whether the server is shutting down because of
+// it; when the watchdog is disabled the server keeps running.
+func (w *Watchdog) StdioClosed() bool {
+	if !w.Enabled() {
+		return false
+	}
+	w.exit("stdin closed")
+	return true
+}
+
+// exit calls onExit the first time the client is found gone.
+func (w *Watchdog) exit(reason string) {
+	w.mu.Lock()
+	if w.exited {
+		w.mu.Unlock()
+		return
+	}
+	w.exited = true
+	w.reason = reason
+	w.mu.Unlock()
+	w.onExit(reason)
+}
+
+// Stop stops watching the parent process and waits for the check in
+// progress to finish.
+func (w *Watchdog) Stop() {
+	if w.stop == nil {
+		return
+	}
+	close(w.stop)
+	<-w.done
+	w.stop = nil
+}
+
+// GetStatus returns the watchdog's state for health responses.
+func (w *Watchdog) GetStatus() map[string]interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status := map[string]interface{}{
+		"enabled":   w.Enabled(),
+		"parentPid": w.parent,
+	}
+	if w.exited {
+		status["exitReason"] = w.reason
+	}
+	return status
+}
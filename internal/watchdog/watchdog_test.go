@@ -0,0 +1,94 @@
+package watchdog
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// newTestWatchdog returns a watchdog whose parent PID is read from ppid,
+// sending each exit reason on the returned channel.
+func newTestWatchdog(enabled bool, ppid *atomic.Int32) (*Watchdog, chan string) {
+	exits := make(chan string, 2)
+	w := New(&config.WatchdogConfig{Enabled: enabled, IntervalSeconds: 1},
+		logging.NewLoggerAdapter(logging.NewLogger("test: ", "error")),
+		func(reason string) { exits <- reason })
+	w.getppid = func() int { return int(ppid.Load()) }
+	w.interval = time.Millisecond
+	return w, exits
+}
+
+func TestParentExit(t *testing.T) {
+	var ppid atomic.Int32
+	ppid.Store(4242)
+	w, exits := newTestWatchdog(true, &ppid)
+	w.Start()
+	defer w.Stop()
+
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case reason := <-exits:
+		t.Fatalf("Expected no exit while the parent runs, got %q", reason)
+	default:
+	}
+
+	ppid.Store(1)
+	select {
+	case reason := <-exits:
+		if reason != "parent process exited" {
+			t.Errorf("Unexpected reason: %q", reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the parent's exit to be noticed")
+	}
+
+	// The client is only reported gone once
+	if !w.StdioClosed() {
+		t.Error("Expected the server to be shutting down")
+	}
+	select {
+	case reason := <-exits:
+		t.Errorf("Expected a single exit, got another: %q", reason)
+	default:
+	}
+	if status := w.GetStatus(); status["parentPid"] != 4242 || status["exitReason"] != "parent process exited" {
+		t.Errorf("Unexpected status: %v", status)
+	}
+}
+
+func TestStdioClosed(t *testing.T) {
+	var ppid atomic.Int32
+	ppid.Store(1)
+	w, exits := newTestWatchdog(true, &ppid)
+	w.Start() // Started by init: nothing to watch
+	defer w.Stop()
+
+	if !w.StdioClosed() {
+		t.Fatal("Expected the server to shut down when stdin closes")
+	}
+	if reason := <-exits; reason != "stdin closed" {
+		t.Errorf("Unexpected reason: %q", reason)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	var ppid atomic.Int32
+	ppid.Store(4242)
+	w, exits := newTestWatchdog(false, &ppid)
+	w.Start()
+	defer w.Stop()
+
+	ppid.Store(1)
+	if w.StdioClosed() {
+		t.Error("Expected the server to keep running when stdin closes")
+	}
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case reason := <-exits:
+		t.Errorf("Expected no exit, got %q", reason)
+	default:
+	}
+}
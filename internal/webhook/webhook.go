@@ -0,0 +1,232 @@
+// Package webhook notifies configured URLs when background reviews finish
+// or fail.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/retry"
+)
+
+// Headers of a notification.
+const (
+	EventHeader     = "X-KataGo-Event"
+	DeliveryHeader  = "X-KataGo-Delivery" // Unique per event, the same across retries
+	SignatureHeader = "X-KataGo-Signature"
+)
+
+// maxAttempts is the number of times an event is sent to a webhook that
+// fails to accept it.
+const maxAttempts = 4
+
+// Event is the JSON body of a notification.
+type Event struct {
+	Event       string    `json:"event"` // config.EventReviewCompleted or config.EventReviewFailed
+	Time        time.Time `json:"time"`
+	Source      string    `json:"source"`               // File name of the game
+	ReviewFile  string    `json:"reviewFile,omitempty"` // File name of the review, when it completed
+	Deep        bool      `json:"deep,omitempty"`       // A deep re-review of a reviewed game
+	PlayerBlack string    `json:"playerBlack,omitempty"`
+	PlayerWhite string    `json:"playerWhite,omitempty"`
+	// Summary of a completed review, with the moves whose verdict a deep
+	// review changed
+	Summary         *katago.ReviewSummary `json:"summary,omitempty"`
+	ChangedVerdicts int                   `json:"changedVerdicts,omitempty"`
+	Error           string                `json:"error,omitempty"` // Why a review failed
+}
+
+// Notifier sends events to webhooks in the background, retrying failed
+// deliveries with backoff.
+type Notifier struct {
+	hooks  []config.WebhookConfig
+	client *http.Client
+	logger logging.ContextLogger
+	retry  retry.Config
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	delivered int
+	failed    int
+	lastErr   string
+}
+
+// New creates a notifier for the configured webhooks.
+func New(hooks []config.WebhookConfig, logger logging.ContextLogger) *Notifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Notifier{
+		hooks:  hooks,
+		client: &http.Client{},
+		logger: logger,
+		retry: retry.Config{
+			MaxAttempts:  maxAttempts,
+			InitialDelay: 2 * time.Second,
+			MaxDelay:     30 * time.Second,
+			Multiplier:   2.0,
+			Jitter:       0.1,
+			ShouldRetry:  retryable,
+		},
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Enabled reports whether any webhook is configured.
+func (n *Notifier) Enabled() bool {
+	return len(n.hooks) > 0
+}
+
+// Notify sends an event to every webhook subscribed to it without waiting
+// for the deliveries.
+func (n *Notifier) Notify(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		n.logger.Error("Failed to encode webhook event", "event", event.Event, "error", err)
+		return
+	}
+	delivery := deliveryID()
+	for i := range n.hooks {
+		hook := &n.hooks[i]
+		if !hook.Wants(event.Event) {
+			continue
+		}
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			n.deliver(hook, event.Event, delivery, body)
+		}()
+	}
+}
+
+// Close waits for outstanding deliveries until ctx is done, then abandons
+// the rest.
+func (n *Notifier) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		n.cancel()
+		return nil
+	case <-ctx.Done():
+		n.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// GetStatus returns delivery statistics for health responses.
+func (n *Notifier) GetStatus() map[string]interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	status := map[string]interface{}{
+		"webhooks":  len(n.hooks),
+		"delivered": n.delivered,
+		"failed":    n.failed,
+	}
+	if n.lastErr != "" {
+		status["lastError"] = n.lastErr
+	}
+	return status
+}
+
+// deliver sends an event to a webhook, retrying until it is accepted or
+// the attempts run out.
+func (n *Notifier) deliver(hook *config.WebhookConfig, event, delivery string, body []byte) {
+	err := retry.NewManager(n.retry).Run(n.ctx, func(ctx context.Context) error {
+		return n.post(ctx, hook, event, delivery, body)
+	})
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err != nil {
+		n.failed++
+		n.lastErr = err.Error()
+		n.logger.Warn("Failed to deliver webhook", "event", event, "delivery", delivery, "error", err)
+		return
+	}
+	n.delivered++
+	n.logger.Debug("Webhook delivered", "event", event, "delivery", delivery)
+}
+
+// statusError is a response from a webhook that did not accept an event.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("webhook answered %d %s", e.code, http.StatusText(e.code))
+}
+
+// retryable reports whether a failed delivery may succeed later: network
+// errors, rate limiting and server errors.
+func retryable(err error) bool {
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.code == http.StatusTooManyRequests || status.code >= 500
+	}
+	return true
+}
+
+// post makes one delivery attempt.
+func (n *Notifier) post(ctx context.Context, hook *config.WebhookConfig, event, delivery string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(hook.TimeoutSeconds)*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, delivery)
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign([]byte(hook.Secret), body))
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &statusError{code: resp.StatusCode}
+	}
+	return nil
+}
+
+// Sign returns the signature header of a body: "sha256=" and the hex
+// HMAC-SHA256 of the body with the secret.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliveryID returns a random identifier for an event.
+func deliveryID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	"github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+)
+
+// receiver records the notifications a test webhook accepts, failing the
+// first failures attempts with status.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	failures int
+	attempts int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	if r.attempts <= r.failures {
+		w.WriteHeader(r.status)
+		return
+	}
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+}
+
+func newTestNotifier(hooks []config.WebhookConfig) *Notifier {
+	n := New(hooks, logging.NewLoggerAdapter(logging.NewLogger("test: ", "error")))
+	n.retry.InitialDelay = time.Millisecond
+	n.retry.MaxDelay = time.Millisecond
+	return n
+}
+
+func TestNotify(t *testing.T) {
+	signed, failing, completedOnly := &receiver{}, &receiver{status: http.StatusServiceUnavailable, failures: 2}, &receiver{}
+	servers := []*httptest.Server{httptest.NewServer(signed), httptest.NewServer(failing), httptest.NewServer(completedOnly)}
+	for _, s := range servers {
+		defer s.Close()
+	}
+	n := newTestNotifier([]config.WebhookConfig{
+		{URL: servers[0].URL, Secret: "s3cret", TimeoutSeconds: 5},
+		{URL: servers[1].URL, TimeoutSeconds: 5},
+		{URL: servers[2].URL, Events: []string{config.EventReviewCompleted}, TimeoutSeconds: 5},
+	})
+
+	n.Notify(Event{Event: config.EventReviewCompleted, Source: "round1.sgf", Summary: &katago.ReviewSummary{TotalMoves: 120, BlackBlunders: 2}})
+	n.Notify(Event{Event: config.EventReviewFailed, Source: "broken.sgf", Error: "invalid SGF"})
+	if err := n.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if len(signed.bodies) != 2 {
+		t.Fatalf("Expected both events, got %d", len(signed.bodies))
+	}
+	for i, body := range signed.bodies {
+		if got := signed.headers[i].Get(SignatureHeader); got != Sign([]byte("s3cret"), body) {
+			t.Errorf("Expected the body signed, got %q", got)
+		}
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil || event.Time.IsZero() {
+			t.Errorf("Invalid event %s: %v", body, err)
+		}
+		if event.Event != signed.headers[i].Get(EventHeader) {
+			t.Errorf("Expected the event header to match %s, got %q", event.Event, signed.headers[i].Get(EventHeader))
+		}
+		if event.Event == config.EventReviewCompleted && (event.Summary == nil || event.Summary.BlackBlunders != 2) {
+			t.Errorf("Expected the review summary, got %s", body)
+		}
+	}
+
+	// Server errors are retried with the same delivery ID
+	if failing.attempts != 4 || len(failing.bodies) != 2 || failing.headers[0].Get(SignatureHeader) != "" {
+		t.Errorf("Expected both events delivered after two retries, unsigned; got %d attempts, %d events", failing.attempts, len(failing.bodies))
+	}
+	if len(completedOnly.bodies) != 1 {
+		t.Errorf("Expected only the completed event, got %d", len(completedOnly.bodies))
+	}
+	if status := n.GetStatus(); status["delivered"] != 5 || status["failed"] != 0 {
+		t.Errorf("Unexpected status: %v", status)
+	}
+}
+
+func TestNotifyGivesUp(t *testing.T) {
+	rejecting := &receiver{status: http.StatusBadRequest, failures: 10}
+	server := httptest.NewServer(rejecting)
+	defer server.Close()
+	n := newTestNotifier([]config.WebhookConfig{{URL: server.URL, TimeoutSeconds: 5}})
+
+	n.Notify(Event{Event: config.EventReviewFailed, Source: "broken.sgf"})
+	if err := n.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if rejecting.attempts != 1 {
+		t.Errorf("Expected a client error not to be retried, got %d attempts", rejecting.attempts)
+	}
+	if status := n.GetStatus(); status["failed"] != 1 || status["lastError"] != "webhook answered 400 Bad Request" {
+		t.Errorf("Unexpected status: %v", status)
+	}
+}
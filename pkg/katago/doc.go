@@ -0,0 +1,28 @@
+// Package katago is the public Go API of katago-mcp: the SGF parser, a
+// client for KataGo's analysis engine, game reviews and their formatters,
+// for programs that want them without running the MCP server.
+//
+//	engine := katago.NewEngine(katago.Config{
+//		BinaryPath: "/usr/local/bin/katago",
+//		ModelPath:  "/opt/katago/model.bin.gz",
+//		ConfigPath: "/opt/katago/analysis.cfg",
+//	})
+//	if err := engine.Start(ctx); err != nil {
+//		return err
+//	}
+//	defer engine.Stop()
+//	review, err := engine.ReviewGame(ctx, sgf, katago.DefaultMistakeThresholds())
+//	if err != nil {
+//		return err
+//	}
+//	game, _ := katago.ParseSGF(sgf)
+//	fmt.Print(katago.FormatReviewMarkdown(game, review))
+//
+// # Stability
+//
+// The package is not yet stable. Its data types are aliases of the ones
+// the server uses, so they change whenever the server's do, including in
+// minor releases: fields are added and, more rarely, renamed or removed.
+// Construct them with field names and pin the module version you build
+// against. Everything under internal/ may change at any time.
+package katago
@@ -0,0 +1,166 @@
+package katago
+
+import (
+	"context"
+	"io"
+
+	"github.com/dmmcquay/katago-mcp/internal/config"
+	core "github.com/dmmcquay/katago-mcp/internal/katago"
+	"github.com/dmmcquay/katago-mcp/internal/logging"
+	"github.com/dmmcquay/katago-mcp/internal/report"
+)
+
+// Positions and games, as read from SGF.
+type (
+	Position  = core.Position
+	Move      = core.Move
+	Stone     = core.Stone
+	GameInfo  = core.GameInfo
+	Variation = core.Variation
+)
+
+// Analyses of a single position.
+type (
+	AnalysisRequest = core.AnalysisRequest
+	AnalysisResult  = core.AnalysisResult
+	MoveInfo        = core.MoveInfo
+	RootInfo        = core.RootInfo
+	// SearchSettings are KataGo search parameters; unset fields use the
+	// engine's defaults.
+	SearchSettings = config.SearchConfig
+)
+
+// Reviews of whole games.
+type (
+	GameReview        = core.GameReview
+	ReviewSummary     = core.ReviewSummary
+	Mistake           = core.Mistake
+	GoodMove          = core.GoodMove
+	WinratePoint      = core.WinratePoint
+	SGFNote           = core.SGFNote
+	MistakeThresholds = core.MistakeThresholds
+)
+
+// Extensions run in reviews alongside the mistake detector.
+type (
+	Analyzer    = core.Analyzer
+	MoveContext = core.MoveContext
+	Finding     = core.Finding
+)
+
+// Config describes how to run KataGo.
+type Config struct {
+	BinaryPath     string  // KataGo executable
+	ModelPath      string  // Neural network
+	ConfigPath     string  // KataGo analysis config
+	HumanModelPath string  // Optional human SL model, for HumanProfile
+	NumThreads     int     // Search threads (0 = KataGo's config)
+	MaxVisits      int     // Default visits per position (0 = KataGo's config)
+	MaxTime        float64 // Default seconds per position (0 = no limit)
+
+	// LogWriter receives the engine's log, including KataGo's stderr
+	// (default: discarded). LogLevel is "debug", "info", "warn" or "error"
+	// (default: "info").
+	LogWriter io.Writer
+	LogLevel  string
+}
+
+// Engine is a KataGo analysis engine. It is safe for concurrent use once
+// started.
+type Engine struct {
+	engine *core.Engine
+}
+
+// NewEngine creates an engine; Start runs KataGo.
+func NewEngine(cfg Config) *Engine {
+	w := cfg.LogWriter
+	if w == nil {
+		w = io.Discard
+	}
+	level := cfg.LogLevel
+	if level == "" {
+		level = "info"
+	}
+	logger := logging.NewLoggerAdapter(logging.NewLoggerWithWriter(w, "katago: ", level))
+	return &Engine{engine: core.NewEngine(&config.KataGoConfig{
+		BinaryPath:     cfg.BinaryPath,
+		ModelPath:      cfg.ModelPath,
+		ConfigPath:     cfg.ConfigPath,
+		HumanModelPath: cfg.HumanModelPath,
+		NumThreads:     cfg.NumThreads,
+		MaxVisits:      cfg.MaxVisits,
+		MaxTime:        cfg.MaxTime,
+	}, logger, nil)}
+}
+
+// SetAnalyzers sets analyzers run on every judged move of ReviewGame, whose
+// findings are added to the review. Must be called before Start.
+func (e *Engine) SetAnalyzers(analyzers ...Analyzer) {
+	e.engine.SetAnalyzers(analyzers)
+}
+
+// Start runs KataGo and waits until it answers queries.
+func (e *Engine) Start(ctx context.Context) error {
+	return e.engine.Start(ctx)
+}
+
+// Stop quits KataGo.
+func (e *Engine) Stop() error {
+	return e.engine.Stop()
+}
+
+// IsRunning reports whether KataGo is running.
+func (e *Engine) IsRunning() bool {
+	return e.engine.IsRunning()
+}
+
+// Analyze analyzes a position.
+func (e *Engine) Analyze(ctx context.Context, req *AnalysisRequest) (*AnalysisResult, error) {
+	return e.engine.Analyze(ctx, req)
+}
+
+// AnalyzeSGF analyzes the position of an SGF after moveNumber moves, or
+// its final position if moveNumber is 0.
+func (e *Engine) AnalyzeSGF(ctx context.Context, sgf string, moveNumber int) (*AnalysisResult, error) {
+	return e.engine.AnalyzeSGF(ctx, sgf, moveNumber)
+}
+
+// ReviewGame analyzes every move of an SGF and finds its mistakes. Nil
+// thresholds use DefaultMistakeThresholds.
+func (e *Engine) ReviewGame(ctx context.Context, sgf string, thresholds *MistakeThresholds) (*GameReview, error) {
+	return e.engine.ReviewGame(ctx, sgf, thresholds)
+}
+
+// ParseSGF reads the main line of an SGF game, with its comments and
+// variations.
+func ParseSGF(sgf string) (*Position, error) {
+	return core.NewSGFParser(sgf).Parse()
+}
+
+// DefaultMistakeThresholds returns the thresholds the server reviews with.
+func DefaultMistakeThresholds() *MistakeThresholds {
+	return core.DefaultMistakeThresholds()
+}
+
+// FormatAnalysis renders an analysis as text, with a detailed move table
+// if verbose.
+func FormatAnalysis(result *AnalysisResult, verbose bool, boardSize int) string {
+	return core.FormatAnalysisResult(result, verbose, boardSize)
+}
+
+// FormatReviewMarkdown renders a review of game as a Markdown report.
+func FormatReviewMarkdown(game *Position, review *GameReview) string {
+	return report.Markdown(game, review)
+}
+
+// FormatReviewHTML renders a review of game as a standalone HTML page, with
+// the winrate graph and board diagrams drawn as inline SVG.
+func FormatReviewHTML(game *Position, review *GameReview) string {
+	return report.HTML(game, review)
+}
+
+// AnnotateSGF returns the SGF with a review written into it as comments and
+// bad move marks on its mistakes.
+func AnnotateSGF(sgf string, review *GameReview) (string, error) {
+	return core.AnnotateSGF(sgf, review)
+}
@@ -0,0 +1,80 @@
+package katago_test
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dmmcquay/katago-mcp/pkg/katago"
+)
+
+const game = "(;GM[1]FF[4]SZ[19]KM[6.5]PB[Alice]PW[Bob];B[pd];W[dp];B[pq];W[dd])"
+
+func TestParseAndFormat(t *testing.T) {
+	position, err := katago.ParseSGF(game)
+	if err != nil {
+		t.Fatalf("ParseSGF failed: %v", err)
+	}
+	if position.BoardXSize != 19 || len(position.Moves) != 4 || position.PlayerBlack != "Alice" {
+		t.Errorf("Unexpected position: %+v", position)
+	}
+	if _, err := katago.ParseSGF("not an sgf"); err == nil {
+		t.Error("Expected an error for invalid SGF")
+	}
+
+	review := &katago.GameReview{
+		Mistakes: []katago.Mistake{{MoveNumber: 2, Color: "W", PlayedMove: "D4", BestMove: "Q4", WinrateDrop: 0.2, Category: "blunder"}},
+		Summary:  katago.ReviewSummary{TotalMoves: 4, AnalyzedMoves: 4, WhiteBlunders: 1},
+	}
+	if md := katago.FormatReviewMarkdown(position, review); !strings.Contains(md, "Alice") {
+		t.Errorf("Expected the players in the Markdown report, got %q", md)
+	}
+	if html := katago.FormatReviewHTML(position, review); !strings.Contains(html, "<svg") {
+		t.Errorf("Expected an HTML report with a graph, got %q", html)
+	}
+	if annotated, err := katago.AnnotateSGF(game, review); err != nil || !strings.Contains(annotated, "BM[2]") {
+		t.Errorf("Expected the blunder marked, got %q, %v", annotated, err)
+	}
+	text := katago.FormatAnalysis(&katago.AnalysisResult{
+		RootInfo:  katago.RootInfo{Visits: 100, Winrate: 0.5, CurrentPlayer: "B"},
+		MoveInfos: []katago.MoveInfo{{Move: "Q16", Visits: 100, Winrate: 0.5}},
+	}, false, 19)
+	if !strings.Contains(text, "Q16") {
+		t.Errorf("Expected the candidate move in the analysis, got %q", text)
+	}
+}
+
+// TestEngine runs the public engine against katago-mock, which answers
+// without a model.
+func TestEngine(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping katago-mock build in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not available to build katago-mock")
+	}
+	binary := filepath.Join(t.TempDir(), "katago-mock")
+	cmd := exec.Command(goTool, "build", "-o", binary, "github.com/dmmcquay/katago-mcp/cmd/katago-mock") // #nosec G204 -- fixed arguments
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build katago-mock: %v\n%s", err, output)
+	}
+
+	engine := katago.NewEngine(katago.Config{BinaryPath: binary, MaxVisits: 50, MaxTime: 5})
+	ctx := context.Background()
+	if err := engine.Start(ctx); err != nil {
+		t.Fatalf("Failed to start engine: %v", err)
+	}
+	defer func() { _ = engine.Stop() }()
+
+	result, err := engine.AnalyzeSGF(ctx, game, 0)
+	if err != nil || len(result.MoveInfos) == 0 {
+		t.Fatalf("Expected an analysis, got %+v, %v", result, err)
+	}
+	review, err := engine.ReviewGame(ctx, game, nil)
+	if err != nil || review.Summary.TotalMoves != 4 {
+		t.Fatalf("Expected a review of the four moves, got %+v, %v", review, err)
+	}
+}